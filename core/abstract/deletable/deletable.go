@@ -26,7 +26,9 @@ func NewDeletable(db *sqlx.DB, tableName table_name.TableName, ownerGetter ownab
 		return deletable{}, core_err.Rethrow("opening sql db for Deletable", err)
 	}
 	// service
-	deleter := service.NewDeleter(ownerGetter, sqlDB.Delete)
+	deleter := service.NewDeleter(ownerGetter, func(targetId string) error {
+		return sqlDB.Delete(db, targetId)
+	})
 	forceDeleter := service.NewForceDeleter(sqlDB.Delete)
 
 	return deletable{
@@ -5,12 +5,16 @@ import (
 	"github.com/k0marov/go-socnet/core/general/client_errors"
 	"github.com/k0marov/go-socnet/core/general/core_err"
 	"github.com/k0marov/go-socnet/core/general/core_values"
+	"github.com/k0marov/go-socnet/core/general/unit_of_work"
 )
 
 type StoreDeleter func(targetId string) error
+type StoreForceDeleter func(ex unit_of_work.Executor, targetId string) error
 
 type Deleter func(targetId string, caller core_values.UserId) error
-type ForceDeleter func(targetId string) error
+
+// ForceDeleter deletes the target without checking its owner, as a part of the unit of work deleting it
+type ForceDeleter func(ex unit_of_work.Executor, targetId string) error
 
 func NewDeleter(getOwner ownable.OwnerGetter, delete StoreDeleter) Deleter {
 	return func(targetId string, caller core_values.UserId) error {
@@ -29,6 +33,6 @@ func NewDeleter(getOwner ownable.OwnerGetter, delete StoreDeleter) Deleter {
 	}
 }
 
-func NewForceDeleter(delete StoreDeleter) ForceDeleter {
+func NewForceDeleter(delete StoreForceDeleter) ForceDeleter {
 	return ForceDeleter(delete)
 }
@@ -4,6 +4,7 @@ import (
 	"github.com/jmoiron/sqlx"
 	"github.com/k0marov/go-socnet/core/abstract/table_name"
 	"github.com/k0marov/go-socnet/core/general/core_err"
+	"github.com/k0marov/go-socnet/core/general/unit_of_work"
 )
 
 type SqlDB struct {
@@ -19,10 +20,10 @@ func NewSqlDB(db *sqlx.DB, tableName table_name.TableName) (*SqlDB, error) {
 	return &SqlDB{sql: db, safeTargetTable: targetTable}, nil
 }
 
-func (db *SqlDB) Delete(targetId string) error {
-	_, err := db.sql.Exec(`
+func (db *SqlDB) Delete(ex unit_of_work.Executor, targetId string) error {
+	_, err := ex.Exec(ex.Rebind(`
 		DELETE FROM `+db.safeTargetTable+` WHERE id = ?
-    `, targetId)
+    `), targetId)
 	if err != nil {
 		return core_err.Rethrow("deleting a Deletable target", err)
 	}
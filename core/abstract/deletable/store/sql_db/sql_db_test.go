@@ -12,30 +12,30 @@ import (
 var targetTblName = table_name.NewTableName("Target")
 
 func TestSqlDB_ErrorHandling(t *testing.T) {
-	db := OpenSqliteDB(t)
+	db := OpenTestDB(t)
 	sqlDB := setupSqlDB(t, db)
 	db.Close() // this will make all calls to db throw
 	t.Run("Delete", func(t *testing.T) {
-		err := sqlDB.Delete(RandomId())
+		err := sqlDB.Delete(db, RandomId())
 		AssertSomeError(t, err)
 	})
 }
 
 func TestSqlDB_Injection(t *testing.T) {
-	db := OpenSqliteDB(t)
+	db := OpenTestDB(t)
 	_, err := sql_db.NewSqlDB(db, table_name.NewTableName("'; DROP TABLE Students; --"))
 	AssertSomeError(t, err)
 }
 
 func TestSqlDB(t *testing.T) {
-	db := OpenSqliteDB(t)
+	db := OpenTestDB(t)
 	sqlDB := setupSqlDB(t, db)
 
 	targetTable, err := targetTblName.Value()
 	AssertNoError(t, err)
 
 	targetId := createTargetEntity(t, db)
-	err = sqlDB.Delete(targetId)
+	err = sqlDB.Delete(db, targetId)
 	AssertNoError(t, err)
 
 	row := db.QueryRow(`
@@ -67,9 +67,9 @@ func createTargetEntity(t testing.TB, db *sqlx.DB) (id string) {
 	targetTable, err := targetTblName.Value()
 	AssertNoError(t, err)
 	id = RandomId()
-	_, err = db.Exec(`
+	_, err = db.Exec(db.Rebind(`
 		INSERT INTO `+targetTable+`(id) VALUES (?)
-    `, id)
+    `), id)
 	AssertNoError(t, err)
 	return
 }
@@ -9,19 +9,45 @@ import (
 )
 
 type (
-	LikeToggler          = service.LikeToggler
-	LikeChecker          = service.LikeChecker
-	LikesCountGetter     = service.LikesCountGetter
-	UserLikesCountGetter = service.UserLikesCountGetter
-	UserLikesGetter      = service.UserLikesGetter
+	LikeToggler               = service.LikeToggler
+	Liker                     = service.Liker
+	Unliker                   = service.Unliker
+	LikeChecker               = service.LikeChecker
+	LikeBatchChecker          = service.LikeBatchChecker
+	LikesCountGetter          = service.LikesCountGetter
+	LikesCountBatchGetter     = service.LikesCountBatchGetter
+	UserLikesCountGetter      = service.UserLikesCountGetter
+	UserLikesCountBatchGetter = service.UserLikesCountBatchGetter
+	UserLikesGetter           = service.UserLikesGetter
+	UserLikesPageGetter       = service.UserLikesPageGetter
+	LikersPageGetter          = service.LikersPageGetter
+	CountsRebuilder           = service.CountsRebuilder
+	ReactionSetter            = service.ReactionSetter
+	ReactionGetter            = service.ReactionGetter
+	ReactionBatchGetter       = service.ReactionBatchGetter
+	ReactionCountsGetter      = service.ReactionCountsGetter
+	ReactionCountsBatchGetter = service.ReactionCountsBatchGetter
 )
 
 type likeable struct {
-	ToggleLike        LikeToggler
-	IsLiked           LikeChecker
-	GetLikesCount     LikesCountGetter
-	GetUserLikesCount UserLikesCountGetter
-	GetUserLikes      UserLikesGetter
+	ToggleLike             LikeToggler
+	Like                   Liker
+	Unlike                 Unliker
+	IsLiked                LikeChecker
+	IsLikedBatch           LikeBatchChecker
+	GetLikesCount          LikesCountGetter
+	GetLikesCountBatch     LikesCountBatchGetter
+	GetUserLikesCount      UserLikesCountGetter
+	GetUserLikesCountBatch UserLikesCountBatchGetter
+	GetUserLikes           UserLikesGetter
+	GetUserLikesPage       UserLikesPageGetter
+	GetLikers              LikersPageGetter
+	RebuildCounts          CountsRebuilder
+	SetReaction            ReactionSetter
+	GetReaction            ReactionGetter
+	GetReactionsBatch      ReactionBatchGetter
+	GetReactionCounts      ReactionCountsGetter
+	GetReactionCountsBatch ReactionCountsBatchGetter
 }
 
 func NewLikeable(db *sqlx.DB, targetTableName table_name.TableName) (likeable, error) {
@@ -32,15 +58,41 @@ func NewLikeable(db *sqlx.DB, targetTableName table_name.TableName) (likeable, e
 	}
 	// service
 	toggleLike := service.NewLikeToggler(store.IsLiked, store.Like, store.Unlike)
+	like := service.NewLiker(store.Like)
+	unlike := service.NewUnliker(store.Unlike)
 	isLiked := service.NewLikeChecker(store.IsLiked)
+	isLikedBatch := service.NewLikeBatchChecker(store.IsLikedBatch)
 	getLikesCount := service.NewLikesCountGetter(store.GetLikesCount)
+	getLikesCountBatch := service.NewLikesCountBatchGetter(store.GetLikesCountBatch)
 	getUserLikesCount := service.NewUserLikesCountGetter(store.GetUserLikesCount)
+	getUserLikesCountBatch := service.NewUserLikesCountBatchGetter(store.GetUserLikesCountBatch)
 	getUserLikes := service.NewUserLikesGetter(store.GetUserLikes)
+	getUserLikesPage := service.NewUserLikesPageGetter(store.GetUserLikesPage)
+	getLikers := service.NewLikersPageGetter(store.GetLikersPage)
+	rebuildCounts := service.NewCountsRebuilder(store.RebuildCounts)
+	setReaction := service.NewReactionSetter(store.SetReaction)
+	getReaction := service.NewReactionGetter(store.GetReaction)
+	getReactionsBatch := service.NewReactionBatchGetter(store.GetReactionsBatch)
+	getReactionCounts := service.NewReactionCountsGetter(store.GetReactionCountsBatch)
+	getReactionCountsBatch := service.NewReactionCountsBatchGetter(store.GetReactionCountsBatch)
 	return likeable{
-		ToggleLike:        toggleLike,
-		IsLiked:           isLiked,
-		GetLikesCount:     getLikesCount,
-		GetUserLikesCount: getUserLikesCount,
-		GetUserLikes:      getUserLikes,
+		ToggleLike:             toggleLike,
+		Like:                   like,
+		Unlike:                 unlike,
+		IsLiked:                isLiked,
+		IsLikedBatch:           isLikedBatch,
+		GetLikesCount:          getLikesCount,
+		GetLikesCountBatch:     getLikesCountBatch,
+		GetUserLikesCount:      getUserLikesCount,
+		GetUserLikesCountBatch: getUserLikesCountBatch,
+		GetUserLikes:           getUserLikes,
+		GetUserLikesPage:       getUserLikesPage,
+		GetLikers:              getLikers,
+		RebuildCounts:          rebuildCounts,
+		SetReaction:            setReaction,
+		GetReaction:            getReaction,
+		GetReactionsBatch:      getReactionsBatch,
+		GetReactionCounts:      getReactionCounts,
+		GetReactionCountsBatch: getReactionCountsBatch,
 	}, nil
 }
@@ -0,0 +1,101 @@
+package likeable_test
+
+import (
+	"context"
+	"github.com/k0marov/go-socnet/core/abstract/likeable"
+	. "github.com/k0marov/go-socnet/core/helpers/test_helpers"
+	"sync"
+	"testing"
+
+	profiles_db "github.com/k0marov/go-socnet/features/profiles/store/sql_db"
+)
+
+func TestLikeable_Concurrency(t *testing.T) {
+	db := OpenTestFileDB(t)
+	profilesDB, err := profiles_db.NewSqlDB(db)
+	AssertNoError(t, err)
+	likeableProfile, err := likeable.NewLikeable(db, profilesDB.TableName)
+	AssertNoError(t, err)
+
+	target := RandomProfileModel()
+	AssertNoError(t, profilesDB.CreateProfile(target))
+	const likers, togglesPerLiker, goroutinesPerLiker = 5, 10, 4
+	var likerIds []string
+	for i := 0; i < likers; i++ {
+		liker := RandomProfileModel()
+		AssertNoError(t, profilesDB.CreateProfile(liker))
+		likerIds = append(likerIds, liker.Id)
+	}
+
+	t.Run("concurrent toggles never duplicate likes or skew the counters", func(t *testing.T) {
+		var wg sync.WaitGroup
+		errs := make(chan error, likers*goroutinesPerLiker*togglesPerLiker)
+		for _, liker := range likerIds {
+			for g := 0; g < goroutinesPerLiker; g++ {
+				wg.Add(1)
+				go func(liker string) {
+					defer wg.Done()
+					for i := 0; i < togglesPerLiker; i++ {
+						_, err := likeableProfile.ToggleLike(target.Id, liker)
+						errs <- err
+					}
+				}(liker)
+			}
+		}
+		wg.Wait()
+		close(errs)
+		for err := range errs {
+			AssertNoError(t, err)
+		}
+
+		var rows int
+		AssertNoError(t, db.Get(&rows, db.Rebind(`SELECT COUNT(*) FROM LikeableProfile WHERE target_id = ?`), target.Id))
+		Assert(t, rows <= likers, true, "there is at most 1 like from every liker")
+		likes, err := likeableProfile.GetLikesCount(target.Id)
+		AssertNoError(t, err)
+		Assert(t, likes, rows, "stored likes count")
+		outOfSync, err := likeableProfile.RebuildCounts(context.Background())
+		AssertNoError(t, err)
+		Assert(t, outOfSync, 0, "number of out of sync counters")
+	})
+	t.Run("concurrent likes are idempotent", func(t *testing.T) {
+		likedBefore, err := likeableProfile.GetLikesCount(target.Id)
+		AssertNoError(t, err)
+		var wg sync.WaitGroup
+		errs := make(chan error, likers*goroutinesPerLiker)
+		inserted := make(chan bool, likers*goroutinesPerLiker)
+		for _, liker := range likerIds {
+			for g := 0; g < goroutinesPerLiker; g++ {
+				wg.Add(1)
+				go func(liker string) {
+					defer wg.Done()
+					isInserted, err := likeableProfile.Like(target.Id, liker)
+					errs <- err
+					inserted <- isInserted
+				}(liker)
+			}
+		}
+		wg.Wait()
+		close(errs)
+		close(inserted)
+		for err := range errs {
+			AssertNoError(t, err)
+		}
+		insertedCount := 0
+		for isInserted := range inserted {
+			if isInserted {
+				insertedCount++
+			}
+		}
+		Assert(t, insertedCount, likers-likedBefore, "number of likes reported as inserted")
+
+		likes, err := likeableProfile.GetLikesCount(target.Id)
+		AssertNoError(t, err)
+		Assert(t, likes, likers, "number of likes")
+		for _, liker := range likerIds {
+			userLikes, err := likeableProfile.GetUserLikesCount(liker)
+			AssertNoError(t, err)
+			Assert(t, userLikes, 1, "number of profiles liked by liker")
+		}
+	})
+}
@@ -1,63 +1,169 @@
 package service
 
 import (
+	"context"
+	"github.com/k0marov/go-socnet/core/abstract/likeable/values"
+	"github.com/k0marov/go-socnet/core/general/client_errors"
 	"github.com/k0marov/go-socnet/core/general/core_err"
 	"github.com/k0marov/go-socnet/core/general/core_values"
+	"github.com/k0marov/go-socnet/core/general/pagination"
+	"time"
 )
 
 // TODO: add checks for core_err.ErrNotFound to all services
 
 type (
-	StoreLikeChecker          func(targetId string, fromUser core_values.UserId) (bool, error)
-	StoreLike                 func(targetId string, fromUser core_values.UserId) error
-	StoreUnlike               func(targetId string, fromUser core_values.UserId) error
-	StoreLikesCountGetter     func(targetId string) (int, error)
-	StoreUserLikesCountGetter func(id core_values.UserId) (int, error)
-	StoreUserLikesGetter      func(id core_values.UserId) ([]string, error)
+	StoreLikeChecker               func(targetId string, fromUser core_values.UserId) (bool, error)
+	StoreLike                      func(targetId string, fromUser core_values.UserId, createdAt time.Time) (inserted bool, err error)
+	StoreUnlike                    func(targetId string, fromUser core_values.UserId) error
+	StoreLikesCountGetter          func(targetId string) (int, error)
+	StoreLikesCountBatchGetter     func(targetIds []string) (map[string]int, error)
+	StoreLikeBatchChecker          func(targetIds []string, fromUser core_values.UserId) (map[string]bool, error)
+	StoreUserLikesCountGetter      func(id core_values.UserId) (int, error)
+	StoreUserLikesCountBatchGetter func(ids []core_values.UserId) (map[core_values.UserId]int, error)
+	StoreUserLikesGetter           func(id core_values.UserId) ([]string, error)
+	StoreUserLikesPageGetter       func(id core_values.UserId, page pagination.Page) ([]values.Like, error)
+	StoreCountsRebuilder           func(ctx context.Context) (outOfSync int, err error)
+	StoreLikersPageGetter          func(targetId string, page pagination.Page) ([]values.Like, error)
+	StoreReactionSetter            func(targetId string, fromUser core_values.UserId, reaction values.Reaction, createdAt time.Time) (inserted bool, err error)
+	StoreReactionGetter            func(targetId string, fromUser core_values.UserId) (values.Reaction, error)
+	StoreReactionBatchGetter       func(targetIds []string, fromUser core_values.UserId) (map[string]values.Reaction, error)
+	StoreReactionCountsBatchGetter func(targetIds []string) (map[string]values.ReactionCounts, error)
 )
 
 type (
-	LikeToggler          func(target string, liker core_values.UserId) error
-	LikesCountGetter     func(targetId string) (int, error)
-	UserLikesCountGetter func(core_values.UserId) (int, error)
-	UserLikesGetter      func(core_values.UserId) ([]string, error)
-	LikeChecker          func(targetId string, fromUser core_values.UserId) (bool, error)
+	// LikeToggler likes or unlikes a target, returning whether it was liked by this call.
+	// If the same user liked the target concurrently, no like is inserted and it returns false.
+	LikeToggler func(target string, liker core_values.UserId) (isLiked bool, err error)
+	// Liker likes a target, returning whether the like was inserted, i.e. the target wasn't liked before
+	Liker                     func(target string, liker core_values.UserId) (inserted bool, err error)
+	Unliker                   func(target string, liker core_values.UserId) error
+	LikesCountGetter          func(targetId string) (int, error)
+	LikesCountBatchGetter     func(targetIds []string) (map[string]int, error)
+	UserLikesCountGetter      func(core_values.UserId) (int, error)
+	UserLikesCountBatchGetter func([]core_values.UserId) (map[core_values.UserId]int, error)
+	UserLikesGetter           func(core_values.UserId) ([]string, error)
+	// UserLikesPageGetter returns a page of likes made by a user, newest first
+	UserLikesPageGetter func(core_values.UserId, pagination.Page) ([]values.Like, error)
+	LikeChecker         func(targetId string, fromUser core_values.UserId) (bool, error)
+	LikeBatchChecker    func(targetIds []string, fromUser core_values.UserId) (map[string]bool, error)
+	// LikersPageGetter returns a page of likes of a target, newest first
+	LikersPageGetter func(targetId string, page pagination.Page) ([]values.Like, error)
+	// CountsRebuilder recomputes the stored likes counters and returns how many of them were out of sync
+	CountsRebuilder func(ctx context.Context) (outOfSync int, err error)
+	// ReactionSetter likes a target with the given reaction or changes the reaction of an existing like,
+	// returning whether a new like was inserted
+	ReactionSetter func(target string, liker core_values.UserId, reaction values.Reaction) (inserted bool, err error)
+	// ReactionGetter returns the reaction of a user to a target or an empty Reaction if the target is not liked
+	ReactionGetter      func(targetId string, fromUser core_values.UserId) (values.Reaction, error)
+	ReactionBatchGetter func(targetIds []string, fromUser core_values.UserId) (map[string]values.Reaction, error)
+	// ReactionCountsGetter returns the number of reactions of every kind received by a target
+	ReactionCountsGetter      func(targetId string) (values.ReactionCounts, error)
+	ReactionCountsBatchGetter func(targetIds []string) (map[string]values.ReactionCounts, error)
 )
 
 func NewLikeToggler(checkLiked StoreLikeChecker, like StoreLike, unlike StoreUnlike) LikeToggler {
-	return func(target string, fromUser core_values.UserId) error {
+	return func(target string, fromUser core_values.UserId) (bool, error) {
 		isLiked, err := checkLiked(target, fromUser)
 		if err != nil {
-			return core_err.Rethrow("checking if the target Likeable is liked", err)
+			return false, core_err.Rethrow("checking if the target Likeable is liked", err)
 		}
 
 		if isLiked {
 			err = unlike(target, fromUser)
 			if err != nil {
-				return core_err.Rethrow("unliking a Likeable in service", err)
-			}
-		} else {
-			err = like(target, fromUser)
-			if err != nil {
-				return core_err.Rethrow("liking a Likeable in service", err)
+				return false, core_err.Rethrow("unliking a Likeable in service", err)
 			}
+			return false, nil
 		}
+		inserted, err := like(target, fromUser, time.Now())
+		if err != nil {
+			return false, core_err.Rethrow("liking a Likeable in service", err)
+		}
+		return inserted, nil
+	}
+}
+
+// NewLiker returns an idempotent Liker, liking an already liked target does nothing
+func NewLiker(like StoreLike) Liker {
+	return func(target string, liker core_values.UserId) (bool, error) {
+		return like(target, liker, time.Now())
+	}
+}
 
-		return nil
+// NewUnliker returns an idempotent Unliker, unliking a target which is not liked does nothing
+func NewUnliker(unlike StoreUnlike) Unliker {
+	return Unliker(unlike)
+}
+
+func NewReactionSetter(setReaction StoreReactionSetter) ReactionSetter {
+	return func(target string, liker core_values.UserId, reaction values.Reaction) (bool, error) {
+		if !reaction.IsValid() {
+			return false, client_errors.InvalidReaction
+		}
+		inserted, err := setReaction(target, liker, reaction, time.Now())
+		if err != nil {
+			return false, core_err.Rethrow("setting a reaction in store", err)
+		}
+		return inserted, nil
 	}
 }
 
+func NewReactionGetter(getReaction StoreReactionGetter) ReactionGetter {
+	return ReactionGetter(getReaction)
+}
+
+func NewReactionBatchGetter(getReactions StoreReactionBatchGetter) ReactionBatchGetter {
+	return ReactionBatchGetter(getReactions)
+}
+
+func NewReactionCountsGetter(getCounts StoreReactionCountsBatchGetter) ReactionCountsGetter {
+	return func(targetId string) (values.ReactionCounts, error) {
+		counts, err := getCounts([]string{targetId})
+		if err != nil {
+			return values.ReactionCounts{}, core_err.Rethrow("getting reaction counts of target", err)
+		}
+		return counts[targetId], nil
+	}
+}
+
+func NewReactionCountsBatchGetter(getCounts StoreReactionCountsBatchGetter) ReactionCountsBatchGetter {
+	return ReactionCountsBatchGetter(getCounts)
+}
+
 func NewLikesCountGetter(getLikesCount StoreLikesCountGetter) LikesCountGetter {
 	return LikesCountGetter(getLikesCount)
 }
 
+func NewLikesCountBatchGetter(getLikesCounts StoreLikesCountBatchGetter) LikesCountBatchGetter {
+	return LikesCountBatchGetter(getLikesCounts)
+}
+
 func NewUserLikesCountGetter(getUserLikesCount StoreUserLikesCountGetter) UserLikesCountGetter {
 	return UserLikesCountGetter(getUserLikesCount)
 }
+func NewUserLikesCountBatchGetter(getUserLikesCounts StoreUserLikesCountBatchGetter) UserLikesCountBatchGetter {
+	return UserLikesCountBatchGetter(getUserLikesCounts)
+}
 func NewUserLikesGetter(getUserLikes StoreUserLikesGetter) UserLikesGetter {
 	return UserLikesGetter(getUserLikes)
 }
+func NewUserLikesPageGetter(getUserLikesPage StoreUserLikesPageGetter) UserLikesPageGetter {
+	return UserLikesPageGetter(getUserLikesPage)
+}
+
+func NewLikersPageGetter(getLikers StoreLikersPageGetter) LikersPageGetter {
+	return LikersPageGetter(getLikers)
+}
 
 func NewLikeChecker(checkLiked StoreLikeChecker) LikeChecker {
 	return LikeChecker(checkLiked)
 }
+
+func NewLikeBatchChecker(checkLiked StoreLikeBatchChecker) LikeBatchChecker {
+	return LikeBatchChecker(checkLiked)
+}
+
+func NewCountsRebuilder(rebuild StoreCountsRebuilder) CountsRebuilder {
+	return CountsRebuilder(rebuild)
+}
@@ -2,9 +2,12 @@ package service_test
 
 import (
 	"github.com/k0marov/go-socnet/core/abstract/likeable/service"
+	"github.com/k0marov/go-socnet/core/abstract/likeable/values"
+	"github.com/k0marov/go-socnet/core/general/client_errors"
 	"github.com/k0marov/go-socnet/core/general/core_values"
 	. "github.com/k0marov/go-socnet/core/helpers/test_helpers"
 	"testing"
+	"time"
 )
 
 func TestLikeToggler(t *testing.T) {
@@ -15,20 +18,29 @@ func TestLikeToggler(t *testing.T) {
 			return false, nil
 		}
 		t.Run("happy case", func(t *testing.T) {
-			like := func(targetId string, liker core_values.UserId) error {
-				if targetId == target && liker == caller {
-					return nil
+			like := func(targetId string, liker core_values.UserId, createdAt time.Time) (bool, error) {
+				if targetId == target && liker == caller && TimeAlmostNow(createdAt) {
+					return true, nil
 				}
 				panic("unexpected args")
 			}
-			err := service.NewLikeToggler(checkLiked, like, nil)(target, caller)
+			isLiked, err := service.NewLikeToggler(checkLiked, like, nil)(target, caller)
 			AssertNoError(t, err)
+			Assert(t, isLiked, true, "target is liked after toggling")
+		})
+		t.Run("the target was liked concurrently - the like is not inserted", func(t *testing.T) {
+			like := func(string, core_values.UserId, time.Time) (bool, error) {
+				return false, nil
+			}
+			isLiked, err := service.NewLikeToggler(checkLiked, like, nil)(target, caller)
+			AssertNoError(t, err)
+			Assert(t, isLiked, false, "target is liked by this toggle")
 		})
 		t.Run("error case - liking throws", func(t *testing.T) {
-			like := func(string, core_values.UserId) error {
-				return RandomError()
+			like := func(string, core_values.UserId, time.Time) (bool, error) {
+				return false, RandomError()
 			}
-			err := service.NewLikeToggler(checkLiked, like, nil)(target, caller)
+			_, err := service.NewLikeToggler(checkLiked, like, nil)(target, caller)
 			AssertSomeError(t, err)
 		})
 	})
@@ -43,14 +55,15 @@ func TestLikeToggler(t *testing.T) {
 				}
 				panic("unexpected args")
 			}
-			err := service.NewLikeToggler(checkLiked, nil, unlike)(target, caller)
+			isLiked, err := service.NewLikeToggler(checkLiked, nil, unlike)(target, caller)
 			AssertNoError(t, err)
+			Assert(t, isLiked, false, "target is liked after toggling")
 		})
 		t.Run("error case - unliking throws", func(t *testing.T) {
 			unlike := func(string, core_values.UserId) error {
 				return RandomError()
 			}
-			err := service.NewLikeToggler(checkLiked, nil, unlike)(target, caller)
+			_, err := service.NewLikeToggler(checkLiked, nil, unlike)(target, caller)
 			AssertSomeError(t, err)
 		})
 	})
@@ -61,7 +74,83 @@ func TestLikeToggler(t *testing.T) {
 			}
 			panic("unexpected args")
 		}
-		err := service.NewLikeToggler(likeChecker, nil, nil)(target, caller)
+		_, err := service.NewLikeToggler(likeChecker, nil, nil)(target, caller)
+		AssertSomeError(t, err)
+	})
+}
+
+func TestLiker(t *testing.T) {
+	target := RandomId()
+	caller := RandomId()
+	t.Run("happy case", func(t *testing.T) {
+		wasInserted := RandomBool()
+		like := func(targetId string, liker core_values.UserId, createdAt time.Time) (bool, error) {
+			if targetId == target && liker == caller && TimeAlmostNow(createdAt) {
+				return wasInserted, nil
+			}
+			panic("unexpected args")
+		}
+		inserted, err := service.NewLiker(like)(target, caller)
+		AssertNoError(t, err)
+		Assert(t, inserted, wasInserted, "whether the like was inserted")
+	})
+	t.Run("error case - liking throws", func(t *testing.T) {
+		like := func(string, core_values.UserId, time.Time) (bool, error) {
+			return false, RandomError()
+		}
+		_, err := service.NewLiker(like)(target, caller)
 		AssertSomeError(t, err)
 	})
 }
+
+func TestReactionSetter(t *testing.T) {
+	target := RandomId()
+	caller := RandomId()
+	reaction := RandomReaction()
+	t.Run("error case - reaction is invalid", func(t *testing.T) {
+		_, err := service.NewReactionSetter(nil)(target, caller, "wow")
+		AssertError(t, err, client_errors.InvalidReaction)
+	})
+	t.Run("error case - setting the reaction throws", func(t *testing.T) {
+		setReaction := func(string, core_values.UserId, values.Reaction, time.Time) (bool, error) {
+			return false, RandomError()
+		}
+		_, err := service.NewReactionSetter(setReaction)(target, caller, reaction)
+		AssertSomeError(t, err)
+	})
+	t.Run("happy case", func(t *testing.T) {
+		wasInserted := RandomBool()
+		setReaction := func(targetId string, liker core_values.UserId, gotReaction values.Reaction, createdAt time.Time) (bool, error) {
+			if targetId == target && liker == caller && gotReaction == reaction && TimeAlmostNow(createdAt) {
+				return wasInserted, nil
+			}
+			panic("unexpected args")
+		}
+		inserted, err := service.NewReactionSetter(setReaction)(target, caller, reaction)
+		AssertNoError(t, err)
+		Assert(t, inserted, wasInserted, "whether a new like was inserted")
+	})
+}
+
+func TestReactionCountsGetter(t *testing.T) {
+	target := RandomId()
+	t.Run("error case - getting the counts throws", func(t *testing.T) {
+		getCounts := func([]string) (map[string]values.ReactionCounts, error) {
+			return nil, RandomError()
+		}
+		_, err := service.NewReactionCountsGetter(getCounts)(target)
+		AssertSomeError(t, err)
+	})
+	t.Run("happy case", func(t *testing.T) {
+		counts := RandomReactionCounts()
+		getCounts := func(targets []string) (map[string]values.ReactionCounts, error) {
+			if len(targets) == 1 && targets[0] == target {
+				return map[string]values.ReactionCounts{target: counts}, nil
+			}
+			panic("unexpected args")
+		}
+		gotCounts, err := service.NewReactionCountsGetter(getCounts)(target)
+		AssertNoError(t, err)
+		Assert(t, gotCounts, counts, "returned counts")
+	})
+}
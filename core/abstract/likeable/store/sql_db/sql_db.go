@@ -1,18 +1,38 @@
 package sql_db
 
 import (
+	"context"
+	"database/sql"
 	"fmt"
 	"github.com/jmoiron/sqlx"
+	"github.com/k0marov/go-socnet/core/abstract/likeable/values"
 	"github.com/k0marov/go-socnet/core/abstract/table_name"
 	"github.com/k0marov/go-socnet/core/general/core_err"
 	"github.com/k0marov/go-socnet/core/general/core_values"
+	"github.com/k0marov/go-socnet/core/general/pagination"
+	"sort"
+	"strings"
+	"time"
 )
 
+// Kinds of counters stored in the Likeable<Target>Count table
+const (
+	targetCounter         = "target"    // number of likes received by a target
+	likerCounter          = "liker"     // number of targets liked by a user
+	reactionCounterPrefix = "reaction:" // number of reactions of some kind received by a target
+)
+
+func reactionCounter(reaction values.Reaction) string {
+	return reactionCounterPrefix + string(reaction)
+}
+
 type SqlDB struct {
 	sql               *sqlx.DB
 	safeLikeableTable string
+	safeCountTable    string
 }
 
+// NewSqlDB expects the Likeable<Target> and Likeable<Target>Count tables to be already created by a migration in core/general/migrations
 func NewSqlDB(db *sqlx.DB, targetTable table_name.TableName) (*SqlDB, error) {
 	targetName, err := targetTable.Value()
 	if err != nil {
@@ -23,102 +43,409 @@ func NewSqlDB(db *sqlx.DB, targetTable table_name.TableName) (*SqlDB, error) {
 	if err != nil {
 		return nil, core_err.Rethrow("generating likeable table name", err)
 	}
-
-	err = initSQL(db, targetName, likeableName)
+	countName, err := table_name.NewTableName(likeableName + "Count").Value()
 	if err != nil {
-		return nil, fmt.Errorf("while initializing sql for likeable %s: %w", targetName, err)
+		return nil, core_err.Rethrow("generating likes count table name", err)
 	}
 	return &SqlDB{
 		sql:               db,
 		safeLikeableTable: likeableName,
+		safeCountTable:    countName,
 	}, nil
 }
 
-func initSQL(db *sqlx.DB, verifiedTarget, verifiedLikeable string) error {
-	_, err := db.Exec(`
-		CREATE TABLE IF NOT EXISTS ` + verifiedLikeable + `(
-			target_id INT NOT NULL, 
-			liker_id INT NOT NULL, 
-			FOREIGN KEY(target_id) REFERENCES ` + verifiedTarget + `(id), 
-			FOREIGN KEY(liker_Id) REFERENCES Profile(id)
-		)
-    `)
+func (db *SqlDB) IsLiked(target string, liker core_values.UserId) (bool, error) {
+	row := db.sql.QueryRow(db.sql.Rebind(`
+		SELECT EXISTS(SELECT 1 FROM `+db.safeLikeableTable+` WHERE target_id = ? AND liker_id = ?)
+	`), target, liker)
+	var isLiked bool
+	err := row.Scan(&isLiked)
 	if err != nil {
-		return fmt.Errorf("while creating table %s: %w", verifiedLikeable, err)
+		return false, fmt.Errorf("while SELECTing is %s liked: %w", db.safeLikeableTable, err)
 	}
-	verifiedIndex := verifiedLikeable + "Index"
-	_, err = db.Exec(`
-		CREATE INDEX IF NOT EXISTS `+verifiedIndex+` ON `+verifiedLikeable+` (target_id, liker_id)
-    `, verifiedIndex)
+	return isLiked, nil
+}
+
+// IsLikedBatch returns whether each of the targets is liked by liker
+func (db *SqlDB) IsLikedBatch(targets []string, liker core_values.UserId) (map[string]bool, error) {
+	isLiked := map[string]bool{}
+	if len(targets) == 0 {
+		return isLiked, nil
+	}
+	query, args, err := sqlx.In(`
+		SELECT target_id FROM `+db.safeLikeableTable+` WHERE liker_id = ? AND target_id IN (?)
+	`, liker, targets)
 	if err != nil {
-		return fmt.Errorf("while creating index %s: %w", verifiedIndex, err)
+		return nil, core_err.Rethrow("building the query for liked targets", err)
 	}
-	return nil
+	var liked []string
+	err = db.sql.Select(&liked, db.sql.Rebind(query), args...)
+	if err != nil {
+		return nil, fmt.Errorf("while SELECTing which %s are liked: %w", db.safeLikeableTable, err)
+	}
+	for _, target := range targets {
+		isLiked[target] = false
+	}
+	for _, target := range liked {
+		isLiked[target] = true
+	}
+	return isLiked, nil
 }
 
-func (db *SqlDB) IsLiked(target string, liker core_values.UserId) (bool, error) {
-	row := db.sql.QueryRow(`
-		SELECT EXISTS(SELECT 1 FROM `+db.safeLikeableTable+` WHERE target_id = ? AND liker_id = ?)
-	`, target, liker)
-	isLiked := 0
-	err := row.Scan(&isLiked)
+// Like inserts a plain like and increments the counters of the target and the liker in the same transaction.
+// Liking an already liked target does nothing, even if the target has another reaction.
+// It returns whether the like was inserted, i.e. the target wasn't liked before.
+func (db *SqlDB) Like(target string, liker core_values.UserId, createdAt time.Time) (inserted bool, err error) {
+	tx, err := db.sql.Beginx()
 	if err != nil {
-		return false, fmt.Errorf("while SELECTing is %s liked: %w", db.safeLikeableTable, err)
+		return false, core_err.Rethrow("starting a transaction", err)
+	}
+	defer tx.Rollback()
+	inserted, err = db.insertReaction(tx, target, liker, values.ReactionLike, createdAt)
+	if err != nil {
+		return false, err
+	}
+	if !inserted {
+		return false, nil
+	}
+	err = tx.Commit()
+	if err != nil {
+		return false, core_err.Rethrow("committing a like", err)
 	}
-	return isLiked == 1, nil
+	return true, nil
 }
 
-func (db *SqlDB) Like(target string, liker core_values.UserId) error {
-	_, err := db.sql.Exec(`
-		INSERT INTO `+db.safeLikeableTable+`(target_id, liker_id) VALUES(?, ?)
-    `, target, liker)
+// SetReaction inserts a like with the given reaction or changes the reaction of an existing like,
+// updating the counters in the same transaction. Setting the current reaction again does nothing.
+// It returns whether a new like was inserted, i.e. the target wasn't liked before.
+func (db *SqlDB) SetReaction(target string, liker core_values.UserId, reaction values.Reaction, createdAt time.Time) (inserted bool, err error) {
+	tx, err := db.sql.Beginx()
 	if err != nil {
-		return fmt.Errorf("while INSERTing a new %s: %w", db.safeLikeableTable, err)
+		return false, core_err.Rethrow("starting a transaction", err)
 	}
-	return nil
+	defer tx.Rollback()
+	inserted, err = db.insertReaction(tx, target, liker, reaction, createdAt)
+	if err != nil {
+		return false, err
+	}
+	if !inserted {
+		var oldReaction values.Reaction
+		err = tx.Get(&oldReaction, tx.Rebind(`
+			SELECT reaction FROM `+db.safeLikeableTable+` WHERE target_id = ? AND liker_id = ?
+		`), target, liker)
+		if err != nil {
+			return false, core_err.Rethrow("SELECTing the current reaction", err)
+		}
+		if oldReaction == reaction {
+			return false, nil
+		}
+		res, err := tx.Exec(tx.Rebind(`
+			UPDATE `+db.safeLikeableTable+` SET reaction = ? WHERE target_id = ? AND liker_id = ? AND reaction = ?
+		`), reaction, target, liker, oldReaction)
+		if err != nil {
+			return false, core_err.Rethrow("UPDATEing the reaction", err)
+		}
+		updated, err := res.RowsAffected()
+		if err != nil {
+			return false, core_err.Rethrow("getting the number of updated reactions", err)
+		}
+		if updated == 0 { // the reaction was concurrently changed or removed
+			return false, nil
+		}
+		err = db.addToCounters(tx, []counterDelta{
+			{reactionCounter(oldReaction), target, -1},
+			{reactionCounter(reaction), target, 1},
+		})
+		if err != nil {
+			return false, err
+		}
+	}
+	err = tx.Commit()
+	if err != nil {
+		return false, core_err.Rethrow("committing a reaction", err)
+	}
+	return inserted, nil
 }
 
+// insertReaction inserts a like with the given reaction and increments the counters if it didn't exist
+func (db *SqlDB) insertReaction(tx *sqlx.Tx, target string, liker core_values.UserId, reaction values.Reaction, createdAt time.Time) (bool, error) {
+	res, err := tx.Exec(tx.Rebind(`
+		INSERT INTO `+db.safeLikeableTable+`(target_id, liker_id, reaction, created_at) VALUES(?, ?, ?, ?)
+		ON CONFLICT(target_id, liker_id) DO NOTHING
+    `), target, liker, reaction, createdAt.Unix())
+	if err != nil {
+		return false, fmt.Errorf("while INSERTing a new %s: %w", db.safeLikeableTable, err)
+	}
+	inserted, err := res.RowsAffected()
+	if err != nil {
+		return false, core_err.Rethrow("getting the number of inserted likes", err)
+	}
+	if inserted == 0 {
+		return false, nil
+	}
+	return true, db.addToCounters(tx, likeCounters(target, liker, reaction, 1))
+}
+
+// Unlike deletes a like of any reaction and decrements the counters of the target and the unliker in the same transaction.
+// Unliking a target which is not liked does nothing.
 func (db *SqlDB) Unlike(target string, unliker core_values.UserId) error {
-	_, err := db.sql.Exec(`
-		DELETE FROM `+db.safeLikeableTable+` WHERE target_id = ? AND liker_id = ?
-	`, target, unliker)
+	tx, err := db.sql.Beginx()
 	if err != nil {
-		return core_err.Rethrow("DELETEing a PostLike", err)
+		return core_err.Rethrow("starting a transaction", err)
+	}
+	defer tx.Rollback()
+	var reaction values.Reaction
+	err = tx.Get(&reaction, tx.Rebind(`
+		DELETE FROM `+db.safeLikeableTable+` WHERE target_id = ? AND liker_id = ? RETURNING reaction
+	`), target, unliker)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return core_err.Rethrow("DELETEing a like", err)
+	}
+	err = db.addToCounters(tx, likeCounters(target, unliker, reaction, -1))
+	if err != nil {
+		return err
+	}
+	err = tx.Commit()
+	if err != nil {
+		return core_err.Rethrow("committing an unlike", err)
+	}
+	return nil
+}
+
+type counterDelta struct {
+	counter, id string
+	delta       int
+}
+
+func likeCounters(target string, liker core_values.UserId, reaction values.Reaction, delta int) []counterDelta {
+	return []counterDelta{
+		{targetCounter, target, delta},
+		{likerCounter, liker, delta},
+		{reactionCounter(reaction), target, delta},
+	}
+}
+
+// addToCounters always updates the counters in the same order, so that concurrent transactions don't deadlock
+func (db *SqlDB) addToCounters(tx *sqlx.Tx, counters []counterDelta) error {
+	sort.Slice(counters, func(i, j int) bool {
+		if counters[i].counter != counters[j].counter {
+			return counters[i].counter < counters[j].counter
+		}
+		return counters[i].id < counters[j].id
+	})
+	for _, c := range counters {
+		_, err := tx.Exec(tx.Rebind(`
+			INSERT INTO `+db.safeCountTable+`(counter, id, likes) VALUES (?, ?, ?)
+			ON CONFLICT(counter, id) DO UPDATE SET likes = `+db.safeCountTable+`.likes + excluded.likes
+		`), c.counter, c.id, c.delta)
+		if err != nil {
+			return core_err.Rethrow("updating the "+c.counter+" likes counter", err)
+		}
 	}
 	return nil
 }
 
+// GetReaction returns the reaction of liker to target or an empty Reaction if target is not liked
+func (db *SqlDB) GetReaction(target string, liker core_values.UserId) (values.Reaction, error) {
+	var reaction values.Reaction
+	err := db.sql.Get(&reaction, db.sql.Rebind(`
+		SELECT reaction FROM `+db.safeLikeableTable+` WHERE target_id = ? AND liker_id = ?
+	`), target, liker)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", core_err.Rethrow("SELECTing the reaction of user", err)
+	}
+	return reaction, nil
+}
+
+// GetReactionsBatch returns the reaction of liker to each of the targets, it is empty for the targets which are not liked
+func (db *SqlDB) GetReactionsBatch(targets []string, liker core_values.UserId) (map[string]values.Reaction, error) {
+	reactions := map[string]values.Reaction{}
+	if len(targets) == 0 {
+		return reactions, nil
+	}
+	query, args, err := sqlx.In(`
+		SELECT target_id, reaction FROM `+db.safeLikeableTable+` WHERE liker_id = ? AND target_id IN (?)
+	`, liker, targets)
+	if err != nil {
+		return nil, core_err.Rethrow("building the query for reactions of user", err)
+	}
+	var rows []struct {
+		Target   string          `db:"target_id"`
+		Reaction values.Reaction `db:"reaction"`
+	}
+	err = db.sql.Select(&rows, db.sql.Rebind(query), args...)
+	if err != nil {
+		return nil, core_err.Rethrow("SELECTing reactions of user", err)
+	}
+	for _, target := range targets {
+		reactions[target] = ""
+	}
+	for _, row := range rows {
+		reactions[row.Target] = row.Reaction
+	}
+	return reactions, nil
+}
+
+// GetReactionCountsBatch returns the number of reactions of every kind received by each of the targets
+func (db *SqlDB) GetReactionCountsBatch(targets []string) (map[string]values.ReactionCounts, error) {
+	counts := map[string]values.ReactionCounts{}
+	if len(targets) == 0 {
+		return counts, nil
+	}
+	counters := make([]string, len(values.Reactions))
+	for i, reaction := range values.Reactions {
+		counters[i] = reactionCounter(reaction)
+	}
+	query, args, err := sqlx.In(`
+		SELECT counter, id, likes FROM `+db.safeCountTable+` WHERE counter IN (?) AND id IN (?)
+	`, counters, targets)
+	if err != nil {
+		return nil, core_err.Rethrow("building the query for reaction counts", err)
+	}
+	var rows []struct {
+		Counter string `db:"counter"`
+		Id      string `db:"id"`
+		Likes   int    `db:"likes"`
+	}
+	err = db.sql.Select(&rows, db.sql.Rebind(query), args...)
+	if err != nil {
+		return nil, core_err.Rethrow("SELECTing reaction counts", err)
+	}
+	for _, target := range targets {
+		counts[target] = values.NewReactionCounts()
+	}
+	for _, row := range rows {
+		reaction := values.Reaction(strings.TrimPrefix(row.Counter, reactionCounterPrefix))
+		counts[row.Id][reaction] = row.Likes
+	}
+	return counts, nil
+}
+
 func (db *SqlDB) GetLikesCount(target string) (int, error) {
-	row := db.sql.QueryRow(`
-		SELECT COUNT(*) FROM `+db.safeLikeableTable+` WHERE target_id = ?
-    `, target)
-	var likes int
-	err := row.Scan(&likes)
+	likes, err := db.getCount(targetCounter, target)
 	if err != nil {
-		return 0, core_err.Rethrow("scanning the likes count", err)
+		return 0, core_err.Rethrow("getting the likes count", err)
 	}
 	return likes, nil
 }
 
+// GetLikesCountBatch returns the likes count of each of the targets
+func (db *SqlDB) GetLikesCountBatch(targets []string) (map[string]int, error) {
+	return db.getCountBatch(targetCounter, targets)
+}
+
 func (db *SqlDB) GetUserLikesCount(user core_values.UserId) (int, error) {
-	row := db.sql.QueryRow(`
-		SELECT COUNT(*) FROM `+db.safeLikeableTable+` WHERE liker_id = ?
-    `, user)
-	var userLikes int
-	err := row.Scan(&userLikes)
+	userLikes, err := db.getCount(likerCounter, user)
 	if err != nil {
-		return 0, core_err.Rethrow("scanning the user likes count", err)
+		return 0, core_err.Rethrow("getting the user likes count", err)
 	}
 	return userLikes, nil
 }
 
+// GetUserLikesCountBatch returns the number of targets liked by each of the users
+func (db *SqlDB) GetUserLikesCountBatch(users []core_values.UserId) (map[core_values.UserId]int, error) {
+	return db.getCountBatch(likerCounter, users)
+}
+
+func (db *SqlDB) getCount(counter string, id string) (int, error) {
+	row := db.sql.QueryRow(db.sql.Rebind(`
+		SELECT COALESCE(MAX(likes), 0) FROM `+db.safeCountTable+` WHERE counter = ? AND id = ?
+	`), counter, id)
+	var count int
+	err := row.Scan(&count)
+	if err != nil {
+		return 0, core_err.Rethrow("scanning the "+counter+" likes counter", err)
+	}
+	return count, nil
+}
+
+func (db *SqlDB) getCountBatch(counter string, ids []string) (map[string]int, error) {
+	counts := map[string]int{}
+	if len(ids) == 0 {
+		return counts, nil
+	}
+	query, args, err := sqlx.In(`
+		SELECT id, likes FROM `+db.safeCountTable+` WHERE counter = ? AND id IN (?)
+	`, counter, ids)
+	if err != nil {
+		return nil, core_err.Rethrow("building the query for likes counts", err)
+	}
+	var rows []struct {
+		Id    string `db:"id"`
+		Likes int    `db:"likes"`
+	}
+	err = db.sql.Select(&rows, db.sql.Rebind(query), args...)
+	if err != nil {
+		return nil, core_err.Rethrow("SELECTing likes counts", err)
+	}
+	for _, id := range ids {
+		counts[id] = 0
+	}
+	for _, row := range rows {
+		counts[row.Id] = row.Likes
+	}
+	return counts, nil
+}
+
+// RebuildCounts recomputes all likes counters from the likes themselves, so the counters of deleted targets are dropped.
+// It returns the number of counters which were out of sync before the rebuild.
+func (db *SqlDB) RebuildCounts(ctx context.Context) (int, error) {
+	tx, err := db.sql.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, core_err.Rethrow("starting a transaction", err)
+	}
+	defer tx.Rollback()
+	actual := `
+		SELECT ? AS counter, CAST(target_id AS VARCHAR(255)) AS id, COUNT(*) AS likes FROM ` + db.safeLikeableTable + ` GROUP BY target_id
+		UNION ALL
+		SELECT ? AS counter, CAST(liker_id AS VARCHAR(255)) AS id, COUNT(*) AS likes FROM ` + db.safeLikeableTable + ` GROUP BY liker_id
+		UNION ALL
+		SELECT '` + reactionCounterPrefix + `' || reaction AS counter, CAST(target_id AS VARCHAR(255)) AS id, COUNT(*) AS likes FROM ` + db.safeLikeableTable + ` GROUP BY target_id, reaction
+	`
+	actualCounts := `SELECT counter, id, likes FROM (` + actual + `) actual`
+	storedCounts := `SELECT counter, id, likes FROM ` + db.safeCountTable + ` WHERE likes <> 0`
+	var outOfSync int
+	err = tx.GetContext(ctx, &outOfSync, tx.Rebind(`
+		SELECT COUNT(*) FROM (
+			SELECT counter, id FROM (`+actualCounts+` EXCEPT `+storedCounts+`) missing
+			UNION
+			SELECT counter, id FROM (`+storedCounts+` EXCEPT `+actualCounts+`) stale
+		) out_of_sync
+	`), targetCounter, likerCounter, targetCounter, likerCounter)
+	if err != nil {
+		return 0, core_err.Rethrow("counting the out of sync likes counters", err)
+	}
+	_, err = tx.ExecContext(ctx, `DELETE FROM `+db.safeCountTable)
+	if err != nil {
+		return 0, core_err.Rethrow("DELETEing the old likes counters", err)
+	}
+	_, err = tx.ExecContext(ctx, tx.Rebind(`
+		INSERT INTO `+db.safeCountTable+`(counter, id, likes) `+actual,
+	), targetCounter, likerCounter)
+	if err != nil {
+		return 0, core_err.Rethrow("INSERTing the rebuilt likes counters", err)
+	}
+	err = tx.Commit()
+	if err != nil {
+		return 0, core_err.Rethrow("committing the rebuilt likes counters", err)
+	}
+	return outOfSync, nil
+}
+
 func (db *SqlDB) GetUserLikes(user core_values.UserId) (targetIds []string, err error) {
-	rows, err := db.sql.Query(`
+	rows, err := db.sql.Query(db.sql.Rebind(`
 		SELECT target_id FROM `+db.safeLikeableTable+` WHERE liker_id = ? 
-    `, user)
+    `), user)
 	if err != nil {
 		return []string{}, core_err.Rethrow("SELECTing the target ids that are liked by user", err)
 	}
+	defer rows.Close()
 	for rows.Next() {
 		var targetId string
 		err := rows.Scan(&targetId)
@@ -129,3 +456,37 @@ func (db *SqlDB) GetUserLikes(user core_values.UserId) (targetIds []string, err
 	}
 	return targetIds, nil
 }
+
+// GetUserLikesPage returns a page of likes made by user, newest first
+func (db *SqlDB) GetUserLikesPage(user core_values.UserId, page pagination.Page) ([]values.Like, error) {
+	cond, condArgs := page.Condition("created_at", "id")
+	args := append([]any{user}, condArgs...)
+	likes := []values.Like{}
+	err := db.sql.Select(&likes, db.sql.Rebind(`
+		SELECT id, target_id, liker_id, created_at FROM `+db.safeLikeableTable+`
+		WHERE liker_id = ? AND `+cond+`
+		ORDER BY created_at DESC, id DESC
+		LIMIT ?
+    `), append(args, page.Limit)...)
+	if err != nil {
+		return []values.Like{}, core_err.Rethrow("SELECTing a page of likes made by user", err)
+	}
+	return likes, nil
+}
+
+// GetLikersPage returns a page of likes of target, newest first
+func (db *SqlDB) GetLikersPage(target string, page pagination.Page) ([]values.Like, error) {
+	cond, condArgs := page.Condition("created_at", "id")
+	args := append([]any{target}, condArgs...)
+	likes := []values.Like{}
+	err := db.sql.Select(&likes, db.sql.Rebind(`
+		SELECT id, target_id, liker_id, created_at FROM `+db.safeLikeableTable+`
+		WHERE target_id = ? AND `+cond+`
+		ORDER BY created_at DESC, id DESC
+		LIMIT ?
+    `), append(args, page.Limit)...)
+	if err != nil {
+		return []values.Like{}, core_err.Rethrow("SELECTing a page of likers of target", err)
+	}
+	return likes, nil
+}
@@ -1,12 +1,18 @@
 package sql_db_test
 
 import (
+	"context"
 	"github.com/jmoiron/sqlx"
 	"github.com/k0marov/go-socnet/core/abstract/likeable/store/sql_db"
+	"github.com/k0marov/go-socnet/core/abstract/likeable/values"
 	"github.com/k0marov/go-socnet/core/abstract/table_name"
+	"github.com/k0marov/go-socnet/core/general/pagination"
 	. "github.com/k0marov/go-socnet/core/helpers/test_helpers"
+	"strconv"
 	"testing"
+	"time"
 
+	profile_models "github.com/k0marov/go-socnet/features/profiles/domain/models"
 	profiles_db "github.com/k0marov/go-socnet/features/profiles/store/sql_db"
 	_ "github.com/mattn/go-sqlite3"
 )
@@ -14,15 +20,19 @@ import (
 var targetTblName = table_name.NewTableName("Target")
 
 func TestSqlDB_ErrorHandling(t *testing.T) {
-	db := OpenSqliteDB(t)
+	db := OpenTestDB(t)
 	sqlDB := setupSqlDB(t, db)
 	db.Close() // this will make all calls to db throw
 	t.Run("IsLiked", func(t *testing.T) {
 		_, err := sqlDB.IsLiked(RandomId(), RandomId())
 		AssertSomeError(t, err)
 	})
+	t.Run("IsLikedBatch", func(t *testing.T) {
+		_, err := sqlDB.IsLikedBatch([]string{RandomId()}, RandomId())
+		AssertSomeError(t, err)
+	})
 	t.Run("Like", func(t *testing.T) {
-		err := sqlDB.Like(RandomId(), RandomId())
+		_, err := sqlDB.Like(RandomId(), RandomId(), time.Now())
 		AssertSomeError(t, err)
 	})
 	t.Run("Unlike", func(t *testing.T) {
@@ -33,24 +43,60 @@ func TestSqlDB_ErrorHandling(t *testing.T) {
 		_, err := sqlDB.GetLikesCount(RandomId())
 		AssertSomeError(t, err)
 	})
+	t.Run("GetLikesCountBatch", func(t *testing.T) {
+		_, err := sqlDB.GetLikesCountBatch([]string{RandomId()})
+		AssertSomeError(t, err)
+	})
 	t.Run("GetUserLikesCount", func(t *testing.T) {
 		_, err := sqlDB.GetUserLikesCount(RandomId())
 		AssertSomeError(t, err)
 	})
+	t.Run("GetUserLikesCountBatch", func(t *testing.T) {
+		_, err := sqlDB.GetUserLikesCountBatch([]string{RandomId()})
+		AssertSomeError(t, err)
+	})
 	t.Run("GetUserLikes", func(t *testing.T) {
 		_, err := sqlDB.GetUserLikes(RandomId())
 		AssertSomeError(t, err)
 	})
+	t.Run("GetUserLikesPage", func(t *testing.T) {
+		_, err := sqlDB.GetUserLikesPage(RandomId(), pagination.Page{Limit: 10})
+		AssertSomeError(t, err)
+	})
+	t.Run("GetLikersPage", func(t *testing.T) {
+		_, err := sqlDB.GetLikersPage(RandomId(), pagination.Page{Limit: 10})
+		AssertSomeError(t, err)
+	})
+	t.Run("RebuildCounts", func(t *testing.T) {
+		_, err := sqlDB.RebuildCounts(context.Background())
+		AssertSomeError(t, err)
+	})
+	t.Run("SetReaction", func(t *testing.T) {
+		_, err := sqlDB.SetReaction(RandomId(), RandomId(), values.ReactionLove, time.Now())
+		AssertSomeError(t, err)
+	})
+	t.Run("GetReaction", func(t *testing.T) {
+		_, err := sqlDB.GetReaction(RandomId(), RandomId())
+		AssertSomeError(t, err)
+	})
+	t.Run("GetReactionsBatch", func(t *testing.T) {
+		_, err := sqlDB.GetReactionsBatch([]string{RandomId()}, RandomId())
+		AssertSomeError(t, err)
+	})
+	t.Run("GetReactionCountsBatch", func(t *testing.T) {
+		_, err := sqlDB.GetReactionCountsBatch([]string{RandomId()})
+		AssertSomeError(t, err)
+	})
 }
 
 func TestSqlDB_Injection(t *testing.T) {
-	db := OpenSqliteDB(t)
+	db := OpenTestDB(t)
 	_, err := sql_db.NewSqlDB(db, table_name.NewTableName("'; DROP TABLE Students; --"))
 	AssertSomeError(t, err)
 }
 
 func TestSqlDB(t *testing.T) {
-	db := OpenSqliteDB(t)
+	db := OpenTestDB(t)
 	sqlDB := setupSqlDB(t, db)
 	profilesDB, err := profiles_db.NewSqlDB(db)
 	AssertNoError(t, err)
@@ -72,8 +118,9 @@ func TestSqlDB(t *testing.T) {
 		// assert target is not liked from profile
 		assertLikedValue(t, false)
 		// like it
-		err := sqlDB.Like(targetId, profile.Id)
+		inserted, err := sqlDB.Like(targetId, profile.Id, time.Now())
 		AssertNoError(t, err)
+		Assert(t, inserted, true, "like is inserted")
 		// assert it is liked
 		assertLikedValue(t, true)
 		// unlike it
@@ -83,14 +130,37 @@ func TestSqlDB(t *testing.T) {
 		assertLikedValue(t, false)
 
 	})
+	t.Run("liking and unliking are idempotent", func(t *testing.T) {
+		targetId := createTargetEntity(t, db)
+		profile := RandomProfileModel()
+		profilesDB.CreateProfile(profile)
+		assertCounts := func(t testing.TB, want int) {
+			t.Helper()
+			likes, err := sqlDB.GetLikesCount(targetId)
+			AssertNoError(t, err)
+			Assert(t, likes, want, "number of likes")
+			userLikes, err := sqlDB.GetUserLikesCount(profile.Id)
+			AssertNoError(t, err)
+			Assert(t, userLikes, want, "number of targets liked by user")
+		}
+
+		inserted, err := sqlDB.Like(targetId, profile.Id, time.Now())
+		AssertNoError(t, err)
+		Assert(t, inserted, true, "the first like is inserted")
+		inserted, err = sqlDB.Like(targetId, profile.Id, time.Now())
+		AssertNoError(t, err)
+		Assert(t, inserted, false, "the repeated like is inserted")
+		assertCounts(t, 1)
+		AssertNoError(t, sqlDB.Unlike(targetId, profile.Id))
+		AssertNoError(t, sqlDB.Unlike(targetId, profile.Id))
+		assertCounts(t, 0)
+	})
 	t.Run("liking 1 target from many profiles", func(t *testing.T) {
 		targetId := createTargetEntity(t, db)
 		const count = 100
-		for i := 0; i < count; i++ {
-			profile := RandomProfileModel()
+		for i, profile := range randomDistinctProfiles(count) {
 			profilesDB.CreateProfile(profile)
-			err := sqlDB.Like(targetId, profile.Id)
-			AssertNoError(t, err)
+			like(t, sqlDB, targetId, profile.Id)
 
 			likes, err := sqlDB.GetLikesCount(targetId)
 			AssertNoError(t, err)
@@ -106,8 +176,7 @@ func TestSqlDB(t *testing.T) {
 		for i := 0; i < count; i++ {
 			target := createTargetEntity(t, db)
 			targets = append(targets, target)
-			err := sqlDB.Like(target, profile.Id)
-			AssertNoError(t, err)
+			like(t, sqlDB, target, profile.Id)
 
 			userLikesCount, err := sqlDB.GetUserLikesCount(profile.Id)
 			AssertNoError(t, err)
@@ -118,6 +187,205 @@ func TestSqlDB(t *testing.T) {
 			Assert(t, userLikes, targets, "targets liked by user")
 		}
 	})
+	t.Run("getting likes of many targets at once", func(t *testing.T) {
+		liker1, liker2 := RandomProfileModel(), RandomProfileModel()
+		profilesDB.CreateProfile(liker1)
+		profilesDB.CreateProfile(liker2)
+		target1, target2, notLiked := createTargetEntity(t, db), createTargetEntity(t, db), createTargetEntity(t, db)
+		like(t, sqlDB, target1, liker1.Id)
+		like(t, sqlDB, target1, liker2.Id)
+		like(t, sqlDB, target2, liker1.Id)
+
+		targets := []string{target1, target2, notLiked}
+		counts, err := sqlDB.GetLikesCountBatch(targets)
+		AssertNoError(t, err)
+		Assert(t, counts, map[string]int{target1: 2, target2: 1, notLiked: 0}, "likes counts")
+
+		isLiked, err := sqlDB.IsLikedBatch(targets, liker2.Id)
+		AssertNoError(t, err)
+		Assert(t, isLiked, map[string]bool{target1: true, target2: false, notLiked: false}, "which targets are liked")
+
+		noLikes := RandomProfileModel()
+		userLikes, err := sqlDB.GetUserLikesCountBatch([]string{liker1.Id, liker2.Id, noLikes.Id})
+		AssertNoError(t, err)
+		Assert(t, userLikes, map[string]int{liker1.Id: 2, liker2.Id: 1, noLikes.Id: 0}, "user likes counts")
+
+		counts, err = sqlDB.GetLikesCountBatch(nil)
+		AssertNoError(t, err)
+		Assert(t, len(counts), 0, "number of likes counts for no targets")
+	})
+	t.Run("rebuilding the likes counters", func(t *testing.T) {
+		liker1, liker2 := RandomProfileModel(), RandomProfileModel()
+		profilesDB.CreateProfile(liker1)
+		profilesDB.CreateProfile(liker2)
+		target1, target2, notLiked := createTargetEntity(t, db), createTargetEntity(t, db), createTargetEntity(t, db)
+		like(t, sqlDB, target1, liker1.Id)
+		like(t, sqlDB, target1, liker2.Id)
+		like(t, sqlDB, target2, liker1.Id)
+		AssertNoError(t, sqlDB.Unlike(target2, liker2.Id)) // not liked, so it should not change the counters
+
+		outOfSync, err := sqlDB.RebuildCounts(context.Background())
+		AssertNoError(t, err)
+		Assert(t, outOfSync, 0, "number of out of sync counters after liking and unliking")
+
+		countTable, err := targetTblName.Value()
+		AssertNoError(t, err)
+		countTable = "Likeable" + countTable + "Count"
+		_, err = db.Exec(db.Rebind(`UPDATE `+countTable+` SET likes = 42 WHERE counter = 'target' AND id = ?`), target1)
+		AssertNoError(t, err)
+		_, err = db.Exec(db.Rebind(`DELETE FROM `+countTable+` WHERE counter = 'liker' AND id = ?`), liker2.Id)
+		AssertNoError(t, err)
+		_, err = db.Exec(db.Rebind(`INSERT INTO `+countTable+`(counter, id, likes) VALUES ('target', ?, 3)`), notLiked)
+		AssertNoError(t, err)
+
+		outOfSync, err = sqlDB.RebuildCounts(context.Background())
+		AssertNoError(t, err)
+		Assert(t, outOfSync, 3, "number of out of sync counters")
+
+		counts, err := sqlDB.GetLikesCountBatch([]string{target1, target2, notLiked})
+		AssertNoError(t, err)
+		Assert(t, counts, map[string]int{target1: 2, target2: 1, notLiked: 0}, "rebuilt likes counts")
+		userLikes, err := sqlDB.GetUserLikesCountBatch([]string{liker1.Id, liker2.Id})
+		AssertNoError(t, err)
+		Assert(t, userLikes, map[string]int{liker1.Id: 2, liker2.Id: 1}, "rebuilt user likes counts")
+
+		outOfSync, err = sqlDB.RebuildCounts(context.Background())
+		AssertNoError(t, err)
+		Assert(t, outOfSync, 0, "number of out of sync counters after the rebuild")
+
+		// the counters of a deleted target are dropped
+		deleted := createTargetEntity(t, db)
+		_, err = db.Exec(db.Rebind(`INSERT INTO `+countTable+`(counter, id, likes) VALUES ('target', ?, 2), ('reaction:like', ?, 2)`), deleted, deleted)
+		AssertNoError(t, err)
+		targetTable, err := targetTblName.Value()
+		AssertNoError(t, err)
+		_, err = db.Exec(db.Rebind(`DELETE FROM `+targetTable+` WHERE id = ?`), deleted)
+		AssertNoError(t, err)
+		outOfSync, err = sqlDB.RebuildCounts(context.Background())
+		AssertNoError(t, err)
+		Assert(t, outOfSync, 2, "number of out of sync counters of the deleted target")
+		var counters int
+		err = db.Get(&counters, db.Rebind(`SELECT COUNT(*) FROM `+countTable+` WHERE id = ?`), deleted)
+		AssertNoError(t, err)
+		Assert(t, counters, 0, "number of stored counters of the deleted target")
+	})
+	t.Run("reacting", func(t *testing.T) {
+		target, notLiked := createTargetEntity(t, db), createTargetEntity(t, db)
+		profiles := randomDistinctProfiles(2)
+		liker1, liker2 := profiles[0], profiles[1]
+		profilesDB.CreateProfile(liker1)
+		profilesDB.CreateProfile(liker2)
+		assertReaction := func(t testing.TB, liker string, want values.Reaction) {
+			t.Helper()
+			got, err := sqlDB.GetReaction(target, liker)
+			AssertNoError(t, err)
+			Assert(t, got, want, "reaction of liker")
+		}
+		assertCounts := func(t testing.TB, reactions map[values.Reaction]int) {
+			t.Helper()
+			want := values.NewReactionCounts()
+			for reaction, count := range reactions {
+				want[reaction] = count
+			}
+			counts, err := sqlDB.GetReactionCountsBatch([]string{target})
+			AssertNoError(t, err)
+			Assert(t, counts[target], want, "reaction counts")
+			likes, err := sqlDB.GetLikesCount(target)
+			AssertNoError(t, err)
+			Assert(t, likes, want.Total(), "likes count")
+		}
+
+		inserted, err := sqlDB.SetReaction(target, liker1.Id, values.ReactionLove, time.Now())
+		AssertNoError(t, err)
+		Assert(t, inserted, true, "reacting to a target which isn't liked inserts a like")
+		like(t, sqlDB, target, liker2.Id)
+		assertReaction(t, liker1.Id, values.ReactionLove)
+		assertReaction(t, liker2.Id, values.ReactionLike)
+		assertCounts(t, map[values.Reaction]int{values.ReactionLove: 1, values.ReactionLike: 1})
+
+		// changing a reaction doesn't add a like, and liking doesn't change the reaction
+		for i := 0; i < 2; i++ {
+			inserted, err = sqlDB.SetReaction(target, liker1.Id, values.ReactionAngry, time.Now())
+			AssertNoError(t, err)
+			Assert(t, inserted, false, "changing the reaction inserts a like")
+		}
+		like(t, sqlDB, target, liker1.Id)
+		assertReaction(t, liker1.Id, values.ReactionAngry)
+		assertCounts(t, map[values.Reaction]int{values.ReactionAngry: 1, values.ReactionLike: 1})
+		isLiked, err := sqlDB.IsLiked(target, liker1.Id)
+		AssertNoError(t, err)
+		Assert(t, isLiked, true, "a reaction is a like")
+
+		reactions, err := sqlDB.GetReactionsBatch([]string{target, notLiked}, liker1.Id)
+		AssertNoError(t, err)
+		Assert(t, reactions, map[string]values.Reaction{target: values.ReactionAngry, notLiked: ""}, "reactions of liker")
+
+		AssertNoError(t, sqlDB.Unlike(target, liker1.Id))
+		assertReaction(t, liker1.Id, "")
+		assertCounts(t, map[values.Reaction]int{values.ReactionLike: 1})
+
+		outOfSync, err := sqlDB.RebuildCounts(context.Background())
+		AssertNoError(t, err)
+		Assert(t, outOfSync, 0, "number of out of sync counters after reacting")
+	})
+	t.Run("paginating likers of a target, newest first", func(t *testing.T) {
+		target := createTargetEntity(t, db)
+		var likers []string
+		for _, liker := range randomDistinctProfiles(5) {
+			profilesDB.CreateProfile(liker)
+			likers = append([]string{liker.Id}, likers...)
+			like(t, sqlDB, target, liker.Id)
+		}
+		getLikerIds := func(likes []values.Like) (ids []string) {
+			for _, like := range likes {
+				ids = append(ids, like.Liker)
+			}
+			return
+		}
+
+		page := pagination.Page{Limit: 3}
+		gotPage, err := sqlDB.GetLikersPage(target, page)
+		AssertNoError(t, err)
+		Assert(t, getLikerIds(gotPage), likers[:3], "the first page")
+
+		page.After = gotPage[len(gotPage)-1].Cursor()
+		gotPage, err = sqlDB.GetLikersPage(target, page)
+		AssertNoError(t, err)
+		Assert(t, getLikerIds(gotPage), likers[3:], "the second page")
+	})
+	t.Run("paginating targets liked by user, newest likes first", func(t *testing.T) {
+		profile := RandomProfileModel()
+		profilesDB.CreateProfile(profile)
+
+		// targets are liked in the order of creation, but with decreasing times,
+		// so the listing should not be in the order of like ids.
+		// The times are in the future so that the profile's likes from other tests (if its random id collides) come last
+		var targets []string
+		now := time.Now().Add(time.Hour)
+		for i := 0; i < 5; i++ {
+			target := createTargetEntity(t, db)
+			targets = append(targets, target)
+			_, err := sqlDB.Like(target, profile.Id, now.Add(-time.Duration(i)*time.Hour))
+			AssertNoError(t, err)
+		}
+		getTargetIds := func(likes []values.Like) (ids []string) {
+			for _, like := range likes {
+				ids = append(ids, like.Target)
+			}
+			return
+		}
+
+		page := pagination.Page{Limit: 3}
+		gotPage, err := sqlDB.GetUserLikesPage(profile.Id, page)
+		AssertNoError(t, err)
+		Assert(t, getTargetIds(gotPage), targets[:3], "the first page")
+		Assert(t, gotPage[0].CreatedAt, now.Unix(), "creation time of the newest like")
+
+		page = pagination.Page{After: gotPage[len(gotPage)-1].Cursor(), Limit: 2}
+		gotPage, err = sqlDB.GetUserLikesPage(profile.Id, page)
+		AssertNoError(t, err)
+		Assert(t, getTargetIds(gotPage), targets[3:], "the second page")
+	})
 }
 
 func setupSqlDB(t testing.TB, db *sqlx.DB) *sql_db.SqlDB {
@@ -127,7 +395,23 @@ func setupSqlDB(t testing.TB, db *sqlx.DB) *sql_db.SqlDB {
 	_, err = db.Exec(`
 		CREATE TABLE IF NOT EXISTS ` + targetTable + `(
 		    id INTEGER PRIMARY KEY
-		)
+		);
+		CREATE TABLE IF NOT EXISTS Likeable` + targetTable + `(
+			id ` + SerialPrimaryKey(db) + `,
+			target_id INT NOT NULL, 
+			liker_id INT NOT NULL, 
+			created_at BIGINT NOT NULL DEFAULT 0,
+			reaction VARCHAR(16) NOT NULL DEFAULT 'like',
+			FOREIGN KEY(target_id) REFERENCES ` + targetTable + `(id), 
+			FOREIGN KEY(liker_id) REFERENCES Profile(id),
+			UNIQUE(target_id, liker_id)
+		);
+		CREATE TABLE IF NOT EXISTS Likeable` + targetTable + `Count(
+			counter VARCHAR(16) NOT NULL,
+			id VARCHAR(255) NOT NULL,
+			likes INT NOT NULL,
+			PRIMARY KEY(counter, id)
+		);
     `)
 	AssertNoError(t, err)
 	sqlDB, err := sql_db.NewSqlDB(db, targetTblName)
@@ -135,14 +419,38 @@ func setupSqlDB(t testing.TB, db *sqlx.DB) *sql_db.SqlDB {
 	return sqlDB
 }
 
+func like(t testing.TB, sqlDB *sql_db.SqlDB, target, liker string) {
+	t.Helper()
+	_, err := sqlDB.Like(target, liker, time.Now())
+	AssertNoError(t, err)
+}
+
+// randomDistinctProfiles is used where the same profile appearing twice would be deduplicated as a single like
+func randomDistinctProfiles(count int) (profiles []profile_models.ProfileModel) {
+	ids := map[string]bool{}
+	for len(profiles) < count {
+		profile := RandomProfileModel()
+		if ids[profile.Id] {
+			continue
+		}
+		ids[profile.Id] = true
+		profiles = append(profiles, profile)
+	}
+	return
+}
+
+// lastTargetId is used instead of random ids, since the tests create hundreds of targets which would collide
+var lastTargetId int
+
 func createTargetEntity(t testing.TB, db *sqlx.DB) (id string) {
 	t.Helper()
 	targetTable, err := targetTblName.Value()
 	AssertNoError(t, err)
-	id = RandomId()
-	_, err = db.Exec(`
+	lastTargetId++
+	id = strconv.Itoa(lastTargetId)
+	_, err = db.Exec(db.Rebind(`
 		INSERT INTO `+targetTable+`(id) VALUES (?)
-    `, id)
+    `), id)
 	AssertNoError(t, err)
 	return
 }
@@ -0,0 +1,61 @@
+package values
+
+import (
+	"github.com/k0marov/go-socnet/core/general/core_values"
+	"github.com/k0marov/go-socnet/core/general/pagination"
+)
+
+type Like struct {
+	Id        string             `db:"id"`
+	Target    string             `db:"target_id"`
+	Liker     core_values.UserId `db:"liker_id"`
+	CreatedAt int64              `db:"created_at"`
+}
+
+// Cursor points at this like in a listing of likes, which is ordered by recency
+func (l Like) Cursor() pagination.Cursor {
+	return pagination.Cursor{CreatedAt: l.CreatedAt, Id: l.Id}
+}
+
+// Reaction is the kind of a like, a plain like is the ReactionLike reaction
+type Reaction string
+
+const (
+	ReactionLike  Reaction = "like"
+	ReactionLove  Reaction = "love"
+	ReactionLaugh Reaction = "laugh"
+	ReactionSad   Reaction = "sad"
+	ReactionAngry Reaction = "angry"
+)
+
+// Reactions lists all supported kinds of reactions
+var Reactions = []Reaction{ReactionLike, ReactionLove, ReactionLaugh, ReactionSad, ReactionAngry}
+
+func (r Reaction) IsValid() bool {
+	for _, reaction := range Reactions {
+		if r == reaction {
+			return true
+		}
+	}
+	return false
+}
+
+// ReactionCounts holds the number of reactions of every kind received by a target
+type ReactionCounts map[Reaction]int
+
+// NewReactionCounts returns the counts of a target without any reactions
+func NewReactionCounts() ReactionCounts {
+	counts := ReactionCounts{}
+	for _, reaction := range Reactions {
+		counts[reaction] = 0
+	}
+	return counts
+}
+
+// Total returns the number of reactions of all kinds, which is the number of likes of a target
+func (c ReactionCounts) Total() (total int) {
+	for _, count := range c {
+		total += count
+	}
+	return
+}
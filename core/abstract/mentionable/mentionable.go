@@ -0,0 +1,42 @@
+package mentionable
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/k0marov/go-socnet/core/abstract/mentionable/service"
+	"github.com/k0marov/go-socnet/core/abstract/mentionable/store/sql_db"
+	"github.com/k0marov/go-socnet/core/abstract/table_name"
+	"github.com/k0marov/go-socnet/core/general/core_err"
+)
+
+type (
+	UsernamesResolver   = service.UsernamesResolver
+	MentionsResolver    = service.MentionsResolver
+	MentionsAdder       = service.MentionsAdder
+	MentionsReplacer    = service.MentionsReplacer
+	MentionsBatchGetter = service.MentionsBatchGetter
+)
+
+type mentionable struct {
+	AddMentions      MentionsAdder
+	ReplaceMentions  MentionsReplacer
+	GetMentionsBatch MentionsBatchGetter
+}
+
+func NewMentionable(db *sqlx.DB, targetTableName table_name.TableName) (mentionable, error) {
+	// store
+	store, err := sql_db.NewSqlDB(db, targetTableName)
+	if err != nil {
+		return mentionable{}, core_err.Rethrow("opening the mentionable sql db", err)
+	}
+	// service
+	return mentionable{
+		AddMentions:      service.NewMentionsAdder(store.AddMentions),
+		ReplaceMentions:  service.NewMentionsReplacer(store.ReplaceMentions),
+		GetMentionsBatch: service.NewMentionsBatchGetter(store.GetMentionsBatch),
+	}, nil
+}
+
+// NewMentionsResolver doesn't depend on the target, so it is shared by all mentionables
+func NewMentionsResolver(resolveUsernames UsernamesResolver) MentionsResolver {
+	return service.NewMentionsResolver(resolveUsernames)
+}
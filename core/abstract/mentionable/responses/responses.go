@@ -0,0 +1,21 @@
+package responses
+
+import "github.com/k0marov/go-socnet/core/abstract/mentionable/values"
+
+type MentionResponse struct {
+	ProfileId string `json:"profile_id"`
+	Offset    int    `json:"offset"`
+	Length    int    `json:"length"`
+}
+
+func NewMentionsResponse(mentions []values.Mention) []MentionResponse {
+	respList := make([]MentionResponse, 0, len(mentions))
+	for _, mention := range mentions {
+		respList = append(respList, MentionResponse{
+			ProfileId: mention.Profile,
+			Offset:    mention.Offset,
+			Length:    mention.Length,
+		})
+	}
+	return respList
+}
@@ -0,0 +1,65 @@
+package service
+
+import (
+	"github.com/k0marov/go-socnet/core/abstract/mentionable/values"
+	"github.com/k0marov/go-socnet/core/general/core_err"
+	"github.com/k0marov/go-socnet/core/general/core_values"
+	"github.com/k0marov/go-socnet/core/general/unit_of_work"
+)
+
+type (
+	StoreMentionsAdder       func(ex unit_of_work.Executor, targetId string, mentions []values.Mention) error
+	StoreMentionsReplacer    func(ex unit_of_work.Executor, targetId string, mentions []values.Mention) error
+	StoreMentionsBatchGetter func(targetIds []string) (map[string][]values.Mention, error)
+)
+
+type (
+	// UsernamesResolver returns the ids of profiles with provided usernames, skipping the ones that don't exist
+	UsernamesResolver func(usernames []string) (map[string]core_values.UserId, error)
+	// MentionsResolver finds the mentions in a text, leaving the usernames of nonexistent profiles as plain text
+	MentionsResolver func(text string) ([]values.Mention, error)
+	// MentionsAdder stores the mentions of a newly created target as a part of the unit of work creating it
+	MentionsAdder func(ex unit_of_work.Executor, targetId string, mentions []values.Mention) error
+	// MentionsReplacer replaces all mentions of a target, e.g. when its text is edited, as a part of the unit of work changing it
+	MentionsReplacer func(ex unit_of_work.Executor, targetId string, mentions []values.Mention) error
+	// MentionsBatchGetter returns the mentions of each target ordered by their offset
+	MentionsBatchGetter func(targetIds []string) (map[string][]values.Mention, error)
+)
+
+func NewMentionsResolver(resolveUsernames UsernamesResolver) MentionsResolver {
+	return func(text string) ([]values.Mention, error) {
+		found := values.FindMentions(text)
+		if len(found) == 0 {
+			return []values.Mention{}, nil
+		}
+		var usernames []string
+		for _, mention := range found {
+			usernames = append(usernames, mention.Username)
+		}
+		ids, err := resolveUsernames(usernames)
+		if err != nil {
+			return []values.Mention{}, core_err.Rethrow("resolving mentioned usernames", err)
+		}
+		mentions := []values.Mention{}
+		for _, mention := range found {
+			id, ok := ids[mention.Username]
+			if !ok {
+				continue
+			}
+			mentions = append(mentions, values.Mention{Profile: id, Offset: mention.Offset, Length: mention.Length})
+		}
+		return mentions, nil
+	}
+}
+
+func NewMentionsAdder(addMentions StoreMentionsAdder) MentionsAdder {
+	return MentionsAdder(addMentions)
+}
+
+func NewMentionsReplacer(replaceMentions StoreMentionsReplacer) MentionsReplacer {
+	return MentionsReplacer(replaceMentions)
+}
+
+func NewMentionsBatchGetter(getMentions StoreMentionsBatchGetter) MentionsBatchGetter {
+	return MentionsBatchGetter(getMentions)
+}
@@ -0,0 +1,69 @@
+package service_test
+
+import (
+	"github.com/k0marov/go-socnet/core/abstract/mentionable/service"
+	"github.com/k0marov/go-socnet/core/abstract/mentionable/values"
+	"github.com/k0marov/go-socnet/core/general/core_values"
+	. "github.com/k0marov/go-socnet/core/helpers/test_helpers"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMentionsResolver(t *testing.T) {
+	existing := map[string]core_values.UserId{
+		"john":     "1",
+		"jane_doe": "2",
+		"user42":   "3",
+	}
+	resolveUsernames := func(usernames []string) (map[string]core_values.UserId, error) {
+		ids := map[string]core_values.UserId{}
+		for _, username := range usernames {
+			if id, ok := existing[username]; ok {
+				ids[username] = id
+			}
+		}
+		return ids, nil
+	}
+	cases := []struct {
+		text string
+		want []values.Mention
+	}{
+		{"", []values.Mention{}},
+		{"no mentions here", []values.Mention{}},
+		{"@john", []values.Mention{{Profile: "1", Offset: 0, Length: 5}}},
+		{"hi @john and @jane_doe!", []values.Mention{{Profile: "1", Offset: 3, Length: 5}, {Profile: "2", Offset: 13, Length: 9}}},
+		{"@john, @john.", []values.Mention{{Profile: "1", Offset: 0, Length: 5}, {Profile: "1", Offset: 7, Length: 5}}},
+		{"@nobody and @user42", []values.Mention{{Profile: "3", Offset: 12, Length: 7}}},
+		{"write to john@example.com", []values.Mention{}},
+		{"@@john and @ john", []values.Mention{}},
+		{"привет @john", []values.Mention{{Profile: "1", Offset: 7, Length: 5}}},
+		{"@" + strings.Repeat("a", values.MaxUsernameLength+1), []values.Mention{}},
+	}
+	sut := service.NewMentionsResolver(resolveUsernames)
+	for _, c := range cases {
+		t.Run(c.text, func(t *testing.T) {
+			got, err := sut(c.text)
+			AssertNoError(t, err)
+			Assert(t, got, c.want, "resolved mentions")
+		})
+	}
+	t.Run("only the found usernames are resolved", func(t *testing.T) {
+		resolveUsernames := func(usernames []string) (map[string]core_values.UserId, error) {
+			if reflect.DeepEqual(usernames, []string{"john", "nobody"}) {
+				return map[string]core_values.UserId{"john": "1"}, nil
+			}
+			panic("unexpected args")
+		}
+		got, err := service.NewMentionsResolver(resolveUsernames)("@john @nobody")
+		AssertNoError(t, err)
+		Assert(t, got, []values.Mention{{Profile: "1", Offset: 0, Length: 5}}, "resolved mentions")
+	})
+	t.Run("error case - resolving usernames throws", func(t *testing.T) {
+		resolveUsernames := func([]string) (map[string]core_values.UserId, error) {
+			return nil, RandomError()
+		}
+		_, err := service.NewMentionsResolver(resolveUsernames)("@john")
+		AssertSomeError(t, err)
+	})
+}
@@ -0,0 +1,76 @@
+package sql_db
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/k0marov/go-socnet/core/abstract/mentionable/values"
+	"github.com/k0marov/go-socnet/core/abstract/table_name"
+	"github.com/k0marov/go-socnet/core/general/core_err"
+	"github.com/k0marov/go-socnet/core/general/unit_of_work"
+)
+
+type SqlDB struct {
+	sql                  *sqlx.DB
+	safeMentionableTable string
+}
+
+// NewSqlDB expects the Mentionable<Target> table to be already created by a migration in core/general/migrations
+func NewSqlDB(db *sqlx.DB, targetTable table_name.TableName) (*SqlDB, error) {
+	targetName, err := targetTable.Value()
+	if err != nil {
+		return nil, core_err.Rethrow("getting target table name", err)
+	}
+	mentionableName, err := table_name.NewTableName("Mentionable" + targetName).Value()
+	if err != nil {
+		return nil, core_err.Rethrow("generating mentionable table name", err)
+	}
+	return &SqlDB{sql: db, safeMentionableTable: mentionableName}, nil
+}
+
+func (db *SqlDB) AddMentions(ex unit_of_work.Executor, target string, mentions []values.Mention) error {
+	for _, mention := range mentions {
+		_, err := ex.Exec(ex.Rebind(`
+			INSERT INTO `+db.safeMentionableTable+`(target_id, profile_id, textOffset, textLength) VALUES (?, ?, ?, ?)
+		`), target, mention.Profile, mention.Offset, mention.Length)
+		if err != nil {
+			return core_err.Rethrow("inserting a mention", err)
+		}
+	}
+	return nil
+}
+
+func (db *SqlDB) ReplaceMentions(ex unit_of_work.Executor, target string, mentions []values.Mention) error {
+	_, err := ex.Exec(ex.Rebind(`
+		DELETE FROM `+db.safeMentionableTable+` WHERE target_id = ?
+	`), target)
+	if err != nil {
+		return core_err.Rethrow("deleting the old mentions", err)
+	}
+	return db.AddMentions(ex, target, mentions)
+}
+
+func (db *SqlDB) GetMentionsBatch(targets []string) (map[string][]values.Mention, error) {
+	mentions := map[string][]values.Mention{}
+	if len(targets) == 0 {
+		return mentions, nil
+	}
+	query, args, err := sqlx.In(`
+		SELECT target_id, profile_id, textOffset, textLength FROM `+db.safeMentionableTable+`
+		WHERE target_id IN (?)
+		ORDER BY target_id, textOffset
+	`, targets)
+	if err != nil {
+		return nil, core_err.Rethrow("building the query for mentions of targets", err)
+	}
+	var rows []struct {
+		Target string `db:"target_id"`
+		values.Mention
+	}
+	err = db.sql.Select(&rows, db.sql.Rebind(query), args...)
+	if err != nil {
+		return nil, core_err.Rethrow("SELECTing mentions of targets", err)
+	}
+	for _, row := range rows {
+		mentions[row.Target] = append(mentions[row.Target], row.Mention)
+	}
+	return mentions, nil
+}
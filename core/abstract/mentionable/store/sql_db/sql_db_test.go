@@ -0,0 +1,134 @@
+package sql_db_test
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/k0marov/go-socnet/core/abstract/mentionable/store/sql_db"
+	"github.com/k0marov/go-socnet/core/abstract/mentionable/values"
+	"github.com/k0marov/go-socnet/core/abstract/table_name"
+	. "github.com/k0marov/go-socnet/core/helpers/test_helpers"
+	"strconv"
+	"testing"
+
+	profiles_db "github.com/k0marov/go-socnet/features/profiles/store/sql_db"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+var targetTblName = table_name.NewTableName("Target")
+
+func TestSqlDB_ErrorHandling(t *testing.T) {
+	db := OpenTestDB(t)
+	sqlDB := setupSqlDB(t, db)
+	db.Close() // this will make all calls to db throw
+	t.Run("AddMentions", func(t *testing.T) {
+		err := sqlDB.AddMentions(db, RandomId(), RandomMentions())
+		AssertSomeError(t, err)
+	})
+	t.Run("ReplaceMentions", func(t *testing.T) {
+		err := sqlDB.ReplaceMentions(db, RandomId(), RandomMentions())
+		AssertSomeError(t, err)
+	})
+	t.Run("GetMentionsBatch", func(t *testing.T) {
+		_, err := sqlDB.GetMentionsBatch([]string{RandomId()})
+		AssertSomeError(t, err)
+	})
+}
+
+func TestSqlDB(t *testing.T) {
+	db := OpenTestDB(t)
+	sqlDB := setupSqlDB(t, db)
+	profilesDB, err := profiles_db.NewSqlDB(db)
+	AssertNoError(t, err)
+	createProfile := func(t testing.TB) string {
+		t.Helper()
+		profile := RandomProfileModel()
+		AssertNoError(t, profilesDB.CreateProfile(profile))
+		return profile.Id
+	}
+
+	t.Run("adding, getting and replacing mentions", func(t *testing.T) {
+		target1 := createTargetEntity(t, db)
+		target2 := createTargetEntity(t, db)
+		profile1 := createProfile(t)
+		profile2 := createProfile(t)
+
+		// mentions are returned ordered by offset, regardless of the order they were added in
+		mentions1 := []values.Mention{
+			{Profile: profile2, Offset: 10, Length: 4},
+			{Profile: profile1, Offset: 0, Length: 6},
+		}
+		err := sqlDB.AddMentions(db, target1, mentions1)
+		AssertNoError(t, err)
+		mentions2 := []values.Mention{{Profile: profile1, Offset: 3, Length: 8}}
+		err = sqlDB.AddMentions(db, target2, mentions2)
+		AssertNoError(t, err)
+
+		got, err := sqlDB.GetMentionsBatch([]string{target1, target2})
+		AssertNoError(t, err)
+		Assert(t, got[target1], []values.Mention{mentions1[1], mentions1[0]}, "mentions of the first target")
+		Assert(t, got[target2], mentions2, "mentions of the second target")
+
+		// replacing the mentions of one target doesn't affect the other one
+		newMentions := []values.Mention{{Profile: profile2, Offset: 1, Length: 5}}
+		err = sqlDB.ReplaceMentions(db, target1, newMentions)
+		AssertNoError(t, err)
+		got, err = sqlDB.GetMentionsBatch([]string{target1, target2})
+		AssertNoError(t, err)
+		Assert(t, got[target1], newMentions, "replaced mentions of the first target")
+		Assert(t, got[target2], mentions2, "mentions of the second target")
+
+		// replacing with no mentions removes all of them
+		err = sqlDB.ReplaceMentions(db, target2, nil)
+		AssertNoError(t, err)
+		got, err = sqlDB.GetMentionsBatch([]string{target2})
+		AssertNoError(t, err)
+		Assert(t, len(got[target2]), 0, "number of mentions of the second target")
+	})
+	t.Run("getting mentions of targets without mentions", func(t *testing.T) {
+		got, err := sqlDB.GetMentionsBatch([]string{createTargetEntity(t, db)})
+		AssertNoError(t, err)
+		Assert(t, len(got), 0, "number of targets with mentions")
+
+		got, err = sqlDB.GetMentionsBatch([]string{})
+		AssertNoError(t, err)
+		Assert(t, len(got), 0, "number of targets with mentions")
+	})
+}
+
+func setupSqlDB(t testing.TB, db *sqlx.DB) *sql_db.SqlDB {
+	t.Helper()
+	targetTable, err := targetTblName.Value()
+	AssertNoError(t, err)
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS ` + targetTable + `(
+		    id INTEGER PRIMARY KEY
+		);
+		CREATE TABLE IF NOT EXISTS Mentionable` + targetTable + `(
+			target_id INT NOT NULL,
+			profile_id INT NOT NULL,
+			textOffset INT NOT NULL,
+			textLength INT NOT NULL,
+			FOREIGN KEY(target_id) REFERENCES ` + targetTable + `(id) ON DELETE CASCADE,
+			FOREIGN KEY(profile_id) REFERENCES Profile(id) ON DELETE CASCADE
+		);
+    `)
+	AssertNoError(t, err)
+	sqlDB, err := sql_db.NewSqlDB(db, targetTblName)
+	AssertNoError(t, err)
+	return sqlDB
+}
+
+// lastTargetId is used instead of random ids, so that the created targets never collide
+var lastTargetId int
+
+func createTargetEntity(t testing.TB, db *sqlx.DB) (id string) {
+	t.Helper()
+	targetTable, err := targetTblName.Value()
+	AssertNoError(t, err)
+	lastTargetId++
+	id = strconv.Itoa(lastTargetId)
+	_, err = db.Exec(db.Rebind(`
+		INSERT INTO `+targetTable+`(id) VALUES (?)
+    `), id)
+	AssertNoError(t, err)
+	return
+}
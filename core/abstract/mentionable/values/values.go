@@ -0,0 +1,55 @@
+package values
+
+import "github.com/k0marov/go-socnet/core/general/core_values"
+
+// Mention is a "@username" in the text of a target which refers to an existing profile.
+// Offset and Length are measured in characters (not bytes) and include the "@".
+type Mention struct {
+	Profile core_values.UserId `db:"profile_id"`
+	Offset  int                `db:"textOffset"`
+	Length  int                `db:"textLength"`
+}
+
+// MaxUsernameLength is the maximum length of a username allowed by the auth service
+const MaxUsernameLength = 20
+
+// RawMention is a "@username" found in the text before it is resolved to a profile
+type RawMention struct {
+	Username string
+	Offset   int
+	Length   int
+}
+
+// FindMentions returns all "@username"s in the text.
+// A username consists of latin letters, digits and underscores
+// and the "@" should not be a part of a word, so that emails are not treated as mentions.
+func FindMentions(text string) []RawMention {
+	var mentions []RawMention
+	chars := []rune(text)
+	for i := 0; i < len(chars); i++ {
+		if chars[i] != '@' || (i > 0 && (isUsernameChar(chars[i-1]) || chars[i-1] == '@')) {
+			continue
+		}
+		end := i + 1
+		for end < len(chars) && isUsernameChar(chars[end]) {
+			end++
+		}
+		usernameLength := end - i - 1
+		if usernameLength > 0 && usernameLength <= MaxUsernameLength {
+			mentions = append(mentions, RawMention{
+				Username: string(chars[i+1 : end]),
+				Offset:   i,
+				Length:   end - i,
+			})
+		}
+		i = end - 1
+	}
+	return mentions
+}
+
+func isUsernameChar(char rune) bool {
+	return char == '_' ||
+		(char >= 'a' && char <= 'z') ||
+		(char >= 'A' && char <= 'Z') ||
+		(char >= '0' && char <= '9')
+}
@@ -23,9 +23,9 @@ func NewSqlDB(db *sqlx.DB, targetTable table_name.TableName) (*SqlDB, error) {
 }
 
 func (db *SqlDB) GetOwner(targetId string) (core_values.UserId, error) {
-	row := db.sql.QueryRow(`
+	row := db.sql.QueryRow(db.sql.Rebind(`
 		SELECT owner_id FROM `+db.safeTargetTable+` WHERE id = ?
-    `, targetId)
+    `), targetId)
 	var owner core_values.UserId
 	err := row.Scan(&owner)
 	if err == sql.ErrNoRows {
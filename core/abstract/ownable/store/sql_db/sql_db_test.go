@@ -16,7 +16,7 @@ import (
 var targetTblName = table_name.NewTableName("Target")
 
 func TestSqlDB_ErrorHandling(t *testing.T) {
-	db := OpenSqliteDB(t)
+	db := OpenTestDB(t)
 	sqlDB := setupSqlDB(t, db)
 	db.Close() // this will make all calls to db throw
 	t.Run("GetOwner", func(t *testing.T) {
@@ -26,13 +26,13 @@ func TestSqlDB_ErrorHandling(t *testing.T) {
 }
 
 func TestSqlDB_Injection(t *testing.T) {
-	db := OpenSqliteDB(t)
+	db := OpenTestDB(t)
 	_, err := sql_db.NewSqlDB(db, table_name.NewTableName("'; DROP TABLE Students; --"))
 	AssertSomeError(t, err)
 }
 
 func TestSqlDB(t *testing.T) {
-	db := OpenSqliteDB(t)
+	db := OpenTestDB(t)
 	sqlDB := setupSqlDB(t, db)
 	profilesDB, _ := profiles_db.NewSqlDB(db)
 
@@ -74,9 +74,9 @@ func createTargetEntity(t testing.TB, db *sqlx.DB, owner core_values.UserId) (id
 	targetTable, err := targetTblName.Value()
 	AssertNoError(t, err)
 	id = RandomId()
-	_, err = db.Exec(`
+	_, err = db.Exec(db.Rebind(`
 		INSERT INTO `+targetTable+`(id, owner_id) VALUES (?, ?)
-    `, id, owner)
+    `), id, owner)
 	AssertNoError(t, err)
 	return
 }
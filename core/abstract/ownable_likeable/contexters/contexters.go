@@ -2,6 +2,7 @@ package contexters
 
 import (
 	"github.com/k0marov/go-socnet/core/abstract/likeable"
+	"github.com/k0marov/go-socnet/core/abstract/likeable/values"
 	"github.com/k0marov/go-socnet/core/general/core_err"
 	"github.com/k0marov/go-socnet/core/general/core_values"
 )
@@ -9,19 +10,47 @@ import (
 type OwnLikeContext struct {
 	IsLiked bool
 	IsMine  bool
+	// Reaction is the caller's reaction to the target, it is empty if the target is not liked
+	Reaction values.Reaction
 }
 
 type OwnLikeContextGetter func(target string, owner, caller core_values.UserId) (OwnLikeContext, error)
 
-func NewOwnLikeContextGetter(checkLiked likeable.LikeChecker) OwnLikeContextGetter {
+func NewOwnLikeContextGetter(getReaction likeable.ReactionGetter) OwnLikeContextGetter {
 	return func(target string, owner, caller core_values.UserId) (OwnLikeContext, error) {
-		isLiked, err := checkLiked(target, caller)
+		reaction, err := getReaction(target, caller)
 		if err != nil {
 			return OwnLikeContext{}, core_err.Rethrow("checking if target is liked", err)
 		}
 		return OwnLikeContext{
-			IsLiked: isLiked,
-			IsMine:  caller == owner,
+			IsLiked:  reaction != "",
+			IsMine:   caller == owner,
+			Reaction: reaction,
 		}, nil
 	}
 }
+
+// OwnLikeContextsGetter accepts the owners of targets keyed by target ids and returns the contexts keyed the same way
+type OwnLikeContextsGetter func(owners map[string]core_values.UserId, caller core_values.UserId) (map[string]OwnLikeContext, error)
+
+func NewOwnLikeContextsGetter(getReactions likeable.ReactionBatchGetter) OwnLikeContextsGetter {
+	return func(owners map[string]core_values.UserId, caller core_values.UserId) (map[string]OwnLikeContext, error) {
+		targets := make([]string, 0, len(owners))
+		for target := range owners {
+			targets = append(targets, target)
+		}
+		reactions, err := getReactions(targets, caller)
+		if err != nil {
+			return nil, core_err.Rethrow("checking if targets are liked", err)
+		}
+		contexts := map[string]OwnLikeContext{}
+		for target, owner := range owners {
+			contexts[target] = OwnLikeContext{
+				IsLiked:  reactions[target] != "",
+				IsMine:   caller == owner,
+				Reaction: reactions[target],
+			}
+		}
+		return contexts, nil
+	}
+}
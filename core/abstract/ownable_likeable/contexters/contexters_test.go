@@ -2,6 +2,7 @@ package contexters_test
 
 import (
 	"fmt"
+	"github.com/k0marov/go-socnet/core/abstract/likeable/values"
 	"github.com/k0marov/go-socnet/core/abstract/ownable_likeable/contexters"
 	"github.com/k0marov/go-socnet/core/general/core_values"
 	. "github.com/k0marov/go-socnet/core/helpers/test_helpers"
@@ -12,19 +13,19 @@ func TestLikeableContextAdder(t *testing.T) {
 	target := RandomId()
 	caller := RandomId()
 
-	runCase := func(isLiked, isMine bool) {
-		t.Run(fmt.Sprintf("isLiked = %v, isMine = %v", isLiked, isMine), func(t *testing.T) {
-			checkLiked := func(targetId string, callerId core_values.UserId) (bool, error) {
+	runCase := func(reaction values.Reaction, isMine bool) {
+		t.Run(fmt.Sprintf("reaction = %q, isMine = %v", reaction, isMine), func(t *testing.T) {
+			getReaction := func(targetId string, callerId core_values.UserId) (values.Reaction, error) {
 				if targetId == target && callerId == caller {
-					return isLiked, nil
+					return reaction, nil
 				}
 				panic("unexpected args")
 			}
-			t.Run("error case - getting isLiked throws", func(t *testing.T) {
-				checkLiked := func(string, core_values.UserId) (bool, error) {
-					return false, RandomError()
+			t.Run("error case - getting the reaction throws", func(t *testing.T) {
+				getReaction := func(string, core_values.UserId) (values.Reaction, error) {
+					return "", RandomError()
 				}
-				_, err := contexters.NewOwnLikeContextGetter(checkLiked)(target, "", caller)
+				_, err := contexters.NewOwnLikeContextGetter(getReaction)(target, "", caller)
 				AssertSomeError(t, err)
 			})
 			t.Run("happy case", func(t *testing.T) {
@@ -34,19 +35,49 @@ func TestLikeableContextAdder(t *testing.T) {
 				} else {
 					owner = RandomId()
 				}
-				gotContext, err := contexters.NewOwnLikeContextGetter(checkLiked)(target, owner, caller)
+				gotContext, err := contexters.NewOwnLikeContextGetter(getReaction)(target, owner, caller)
 				AssertNoError(t, err)
 				wantContext := contexters.OwnLikeContext{
-					IsLiked: isLiked,
-					IsMine:  isMine,
+					IsLiked:  reaction != "",
+					IsMine:   isMine,
+					Reaction: reaction,
 				}
 				Assert(t, gotContext, wantContext, "returned context")
 			})
 		})
 	}
 
-	runCase(true, true)
-	runCase(true, false)
-	runCase(false, true)
-	runCase(false, false)
+	runCase(values.ReactionLike, true)
+	runCase(values.ReactionLove, false)
+	runCase("", true)
+	runCase("", false)
+}
+
+func TestOwnLikeContextsGetter(t *testing.T) {
+	caller := RandomId()
+	liked, notLiked, mine := RandomId(), RandomId(), RandomId()
+	owners := map[string]core_values.UserId{liked: RandomId(), notLiked: RandomId(), mine: caller}
+	t.Run("error case - getting the reactions throws", func(t *testing.T) {
+		getReactions := func([]string, core_values.UserId) (map[string]values.Reaction, error) {
+			return nil, RandomError()
+		}
+		_, err := contexters.NewOwnLikeContextsGetter(getReactions)(owners, caller)
+		AssertSomeError(t, err)
+	})
+	t.Run("happy case", func(t *testing.T) {
+		getReactions := func(targets []string, callerId core_values.UserId) (map[string]values.Reaction, error) {
+			if len(targets) == len(owners) && callerId == caller {
+				return map[string]values.Reaction{liked: values.ReactionLaugh, notLiked: "", mine: ""}, nil
+			}
+			panic("unexpected args")
+		}
+		gotContexts, err := contexters.NewOwnLikeContextsGetter(getReactions)(owners, caller)
+		AssertNoError(t, err)
+		wantContexts := map[string]contexters.OwnLikeContext{
+			liked:    {IsLiked: true, IsMine: false, Reaction: values.ReactionLaugh},
+			notLiked: {IsLiked: false, IsMine: false},
+			mine:     {IsLiked: false, IsMine: true},
+		}
+		Assert(t, gotContexts, wantContexts, "returned contexts")
+	})
 }
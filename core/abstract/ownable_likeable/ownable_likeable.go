@@ -4,15 +4,33 @@ import (
 	"github.com/k0marov/go-socnet/core/abstract/likeable"
 	"github.com/k0marov/go-socnet/core/abstract/ownable"
 	"github.com/k0marov/go-socnet/core/abstract/ownable_likeable/service"
+	"github.com/k0marov/go-socnet/core/general/events"
 )
 
-type SafeLikeToggler = service.SafeLikeToggler
+type (
+	SafeLikeToggler    = service.SafeLikeToggler
+	SafeLiker          = service.SafeLiker
+	SafeUnliker        = service.SafeUnliker
+	SafeReactionSetter = service.SafeReactionSetter
+)
 
 type ownableLikeable struct {
-	SafeToggleLike SafeLikeToggler
+	SafeToggleLike  SafeLikeToggler
+	SafeLike        SafeLiker
+	SafeUnlike      SafeUnliker
+	SafeSetReaction SafeReactionSetter
 }
 
-func NewOwnableLikeable(getOwner ownable.OwnerGetter, toggleLike likeable.LikeToggler) ownableLikeable {
-	safeToggleLike := service.NewSafeLikeToggler(getOwner, toggleLike)
-	return ownableLikeable{SafeToggleLike: safeToggleLike}
+// NewOwnableLikeable publishes the events of liking the targets, which have the provided type
+func NewOwnableLikeable(getOwner ownable.OwnerGetter, toggleLike likeable.LikeToggler, like likeable.Liker, unlike likeable.Unliker, setReaction likeable.ReactionSetter, targetType events.TargetType, publish events.Publisher) ownableLikeable {
+	safeToggleLike := service.NewSafeLikeToggler(getOwner, toggleLike, targetType, publish)
+	safeLike := service.NewSafeLiker(getOwner, like, targetType, publish)
+	safeUnlike := service.NewSafeUnliker(getOwner, unlike, targetType, publish)
+	safeSetReaction := service.NewSafeReactionSetter(getOwner, setReaction, targetType, publish)
+	return ownableLikeable{
+		SafeToggleLike:  safeToggleLike,
+		SafeLike:        safeLike,
+		SafeUnlike:      safeUnlike,
+		SafeSetReaction: safeSetReaction,
+	}
 }
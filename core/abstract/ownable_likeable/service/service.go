@@ -2,15 +2,24 @@ package service
 
 import (
 	"github.com/k0marov/go-socnet/core/abstract/likeable"
+	"github.com/k0marov/go-socnet/core/abstract/likeable/values"
 	"github.com/k0marov/go-socnet/core/abstract/ownable"
 	"github.com/k0marov/go-socnet/core/general/client_errors"
 	"github.com/k0marov/go-socnet/core/general/core_err"
 	"github.com/k0marov/go-socnet/core/general/core_values"
+	"github.com/k0marov/go-socnet/core/general/events"
+	"time"
 )
 
-type SafeLikeToggler func(target string, caller core_values.UserId) error
+type (
+	SafeLikeToggler    func(target string, caller core_values.UserId) error
+	SafeLiker          func(target string, caller core_values.UserId) error
+	SafeUnliker        func(target string, caller core_values.UserId) error
+	SafeReactionSetter func(target string, caller core_values.UserId, reaction values.Reaction) error
+)
 
-func NewSafeLikeToggler(getOwner ownable.OwnerGetter, toggleLike likeable.LikeToggler) SafeLikeToggler {
+// NewSafeLikeToggler publishes a Liked event only when toggling inserts a like, so that its owner is notified once
+func NewSafeLikeToggler(getOwner ownable.OwnerGetter, toggleLike likeable.LikeToggler, targetType events.TargetType, publish events.Publisher) SafeLikeToggler {
 	return func(target string, caller core_values.UserId) error {
 		owner, err := getOwner(target)
 		if err != nil {
@@ -19,10 +28,97 @@ func NewSafeLikeToggler(getOwner ownable.OwnerGetter, toggleLike likeable.LikeTo
 		if owner == caller {
 			return client_errors.LikingYourself
 		}
-		err = toggleLike(target, caller)
+		isLiked, err := toggleLike(target, caller)
 		if err != nil {
 			return core_err.Rethrow("toggling like on OwnableLikeable", err)
 		}
+		if isLiked {
+			publishLiked(publish, owner, caller, targetType, target)
+		}
+		publishLikesChanged(publish, caller, targetType, target)
 		return nil
 	}
 }
+
+// NewSafeLiker publishes a Liked event only when the like is inserted, so that liking the target again doesn't notify its owner
+func NewSafeLiker(getOwner ownable.OwnerGetter, like likeable.Liker, targetType events.TargetType, publish events.Publisher) SafeLiker {
+	return func(target string, caller core_values.UserId) error {
+		owner, err := getOwner(target)
+		if err != nil {
+			return core_err.Rethrow("getting owner of OwnableLikeable", err)
+		}
+		if owner == caller {
+			return client_errors.LikingYourself
+		}
+		inserted, err := like(target, caller)
+		if err != nil {
+			return core_err.Rethrow("liking OwnableLikeable", err)
+		}
+		if inserted {
+			publishLiked(publish, owner, caller, targetType, target)
+		}
+		publishLikesChanged(publish, caller, targetType, target)
+		return nil
+	}
+}
+
+// NewSafeReactionSetter publishes a Liked event only when a new like is inserted, changing the reaction doesn't notify the owner
+func NewSafeReactionSetter(getOwner ownable.OwnerGetter, setReaction likeable.ReactionSetter, targetType events.TargetType, publish events.Publisher) SafeReactionSetter {
+	return func(target string, caller core_values.UserId, reaction values.Reaction) error {
+		owner, err := getOwner(target)
+		if err != nil {
+			return core_err.Rethrow("getting owner of OwnableLikeable", err)
+		}
+		if owner == caller {
+			return client_errors.LikingYourself
+		}
+		inserted, err := setReaction(target, caller, reaction)
+		if err != nil {
+			return core_err.Rethrow("setting a reaction on OwnableLikeable", err)
+		}
+		if inserted {
+			publishLiked(publish, owner, caller, targetType, target)
+		}
+		publishLikesChanged(publish, caller, targetType, target)
+		return nil
+	}
+}
+
+// NewSafeUnliker returns an unliker which checks that the target exists.
+// Unlike liking, unliking your own target is allowed, since it is a no-op.
+func NewSafeUnliker(getOwner ownable.OwnerGetter, unlike likeable.Unliker, targetType events.TargetType, publish events.Publisher) SafeUnliker {
+	return func(target string, caller core_values.UserId) error {
+		_, err := getOwner(target)
+		if err != nil {
+			return core_err.Rethrow("getting owner of OwnableLikeable", err)
+		}
+		err = unlike(target, caller)
+		if err != nil {
+			return core_err.Rethrow("unliking OwnableLikeable", err)
+		}
+		publishLikesChanged(publish, caller, targetType, target)
+		return nil
+	}
+}
+
+func publishLiked(publish events.Publisher, owner, liker core_values.UserId, targetType events.TargetType, target string) {
+	publish(events.Event{
+		Kind:       events.Liked,
+		Actor:      liker,
+		Recipient:  owner,
+		TargetType: targetType,
+		TargetId:   target,
+		CreatedAt:  time.Now(),
+	})
+}
+
+// publishLikesChanged lets the viewers of the target update its likes, so it is published after every successful action
+func publishLikesChanged(publish events.Publisher, actor core_values.UserId, targetType events.TargetType, target string) {
+	publish(events.Event{
+		Kind:       events.LikesChanged,
+		Actor:      actor,
+		TargetType: targetType,
+		TargetId:   target,
+		CreatedAt:  time.Now(),
+	})
+}
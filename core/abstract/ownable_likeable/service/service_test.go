@@ -1,17 +1,34 @@
 package service_test
 
 import (
+	"github.com/k0marov/go-socnet/core/abstract/likeable/values"
 	"github.com/k0marov/go-socnet/core/abstract/ownable_likeable/service"
 	"github.com/k0marov/go-socnet/core/general/client_errors"
 	"github.com/k0marov/go-socnet/core/general/core_values"
+	"github.com/k0marov/go-socnet/core/general/events"
 	. "github.com/k0marov/go-socnet/core/helpers/test_helpers"
 	"testing"
+	"time"
 )
 
+func noPublishing(events.Event) {
+	panic("unexpected publishing")
+}
+
+// recordPublished returns a publisher which appends the events to published, clearing their CreatedAt after checking it
+func recordPublished(t testing.TB, published *[]events.Event) events.Publisher {
+	return func(event events.Event) {
+		Assert(t, TimeAlmostNow(event.CreatedAt), true, "event time is almost now")
+		event.CreatedAt = time.Time{}
+		*published = append(*published, event)
+	}
+}
+
 func TestSafeLikeToggler(t *testing.T) {
 	target := RandomId()
 	owner := RandomId()
 	caller := RandomId()
+	targetType := events.Post
 
 	getOwner := func(targetId string) (core_values.UserId, error) {
 		if targetId == target {
@@ -20,31 +37,198 @@ func TestSafeLikeToggler(t *testing.T) {
 		panic("unexpected args")
 	}
 	t.Run("error case - caller is owner", func(t *testing.T) {
-		err := service.NewSafeLikeToggler(getOwner, nil)(target, owner)
+		err := service.NewSafeLikeToggler(getOwner, nil, targetType, noPublishing)(target, owner)
 		AssertError(t, err, client_errors.LikingYourself)
 	})
 	t.Run("error case - getting author throws", func(t *testing.T) {
 		getOwner := func(targetId string) (core_values.UserId, error) {
 			return "", RandomError()
 		}
-		err := service.NewSafeLikeToggler(getOwner, nil)(target, caller)
+		err := service.NewSafeLikeToggler(getOwner, nil, targetType, noPublishing)(target, caller)
 		AssertSomeError(t, err)
 	})
-	toggleLike := func(targetId string, callerId core_values.UserId) error {
+	toggleLike := func(targetId string, callerId core_values.UserId) (bool, error) {
 		if targetId == target && callerId == caller {
-			return nil
+			return true, nil
 		}
 		panic("unexpected args")
 	}
 	t.Run("error case - toggling like throws", func(t *testing.T) {
-		toggleLike := func(string, core_values.UserId) error {
+		toggleLike := func(string, core_values.UserId) (bool, error) {
+			return false, RandomError()
+		}
+		err := service.NewSafeLikeToggler(getOwner, toggleLike, targetType, noPublishing)(target, caller)
+		AssertSomeError(t, err)
+	})
+	liked := events.Event{Kind: events.Liked, Actor: caller, Recipient: owner, TargetType: targetType, TargetId: target}
+	likesChanged := events.Event{Kind: events.LikesChanged, Actor: caller, TargetType: targetType, TargetId: target}
+	t.Run("happy case - liking notifies the owner", func(t *testing.T) {
+		var published []events.Event
+		err := service.NewSafeLikeToggler(getOwner, toggleLike, targetType, recordPublished(t, &published))(target, caller)
+		AssertNoError(t, err)
+		Assert(t, published, []events.Event{liked, likesChanged}, "published events")
+	})
+	t.Run("happy case - unliking or a like which wasn't inserted only changes the likes", func(t *testing.T) {
+		toggleLike := func(string, core_values.UserId) (bool, error) {
+			return false, nil
+		}
+		var published []events.Event
+		err := service.NewSafeLikeToggler(getOwner, toggleLike, targetType, recordPublished(t, &published))(target, caller)
+		AssertNoError(t, err)
+		Assert(t, published, []events.Event{likesChanged}, "published events")
+	})
+}
+
+func TestSafeLiker(t *testing.T) {
+	target := RandomId()
+	owner := RandomId()
+	caller := RandomId()
+	targetType := events.Comment
+
+	getOwner := func(targetId string) (core_values.UserId, error) {
+		if targetId == target {
+			return owner, nil
+		}
+		panic("unexpected args")
+	}
+	t.Run("error case - caller is owner", func(t *testing.T) {
+		err := service.NewSafeLiker(getOwner, nil, targetType, noPublishing)(target, owner)
+		AssertError(t, err, client_errors.LikingYourself)
+	})
+	t.Run("error case - getting owner throws", func(t *testing.T) {
+		getOwner := func(targetId string) (core_values.UserId, error) {
+			return "", client_errors.NotFound
+		}
+		err := service.NewSafeLiker(getOwner, nil, targetType, noPublishing)(target, caller)
+		AssertError(t, err, client_errors.NotFound)
+	})
+	newLike := func(inserted bool) func(string, core_values.UserId) (bool, error) {
+		return func(targetId string, callerId core_values.UserId) (bool, error) {
+			if targetId == target && callerId == caller {
+				return inserted, nil
+			}
+			panic("unexpected args")
+		}
+	}
+	t.Run("error case - liking throws", func(t *testing.T) {
+		like := func(string, core_values.UserId) (bool, error) {
+			return false, RandomError()
+		}
+		err := service.NewSafeLiker(getOwner, like, targetType, noPublishing)(target, caller)
+		AssertSomeError(t, err)
+	})
+	liked := events.Event{Kind: events.Liked, Actor: caller, Recipient: owner, TargetType: targetType, TargetId: target}
+	likesChanged := events.Event{Kind: events.LikesChanged, Actor: caller, TargetType: targetType, TargetId: target}
+	t.Run("happy case - liking notifies the owner", func(t *testing.T) {
+		var published []events.Event
+		err := service.NewSafeLiker(getOwner, newLike(true), targetType, recordPublished(t, &published))(target, caller)
+		AssertNoError(t, err)
+		Assert(t, published, []events.Event{liked, likesChanged}, "published events")
+	})
+	t.Run("happy case - liking an already liked target doesn't notify the owner", func(t *testing.T) {
+		var published []events.Event
+		err := service.NewSafeLiker(getOwner, newLike(false), targetType, recordPublished(t, &published))(target, caller)
+		AssertNoError(t, err)
+		Assert(t, published, []events.Event{likesChanged}, "published events")
+	})
+}
+
+func TestSafeUnliker(t *testing.T) {
+	target := RandomId()
+	owner := RandomId()
+	caller := RandomId()
+	targetType := events.Post
+
+	getOwner := func(targetId string) (core_values.UserId, error) {
+		if targetId == target {
+			return owner, nil
+		}
+		panic("unexpected args")
+	}
+	t.Run("error case - getting owner throws", func(t *testing.T) {
+		getOwner := func(targetId string) (core_values.UserId, error) {
+			return "", client_errors.NotFound
+		}
+		err := service.NewSafeUnliker(getOwner, nil, targetType, noPublishing)(target, caller)
+		AssertError(t, err, client_errors.NotFound)
+	})
+	unlike := func(targetId string, callerId core_values.UserId) error {
+		if targetId == target && (callerId == caller || callerId == owner) {
+			return nil
+		}
+		panic("unexpected args")
+	}
+	t.Run("error case - unliking throws", func(t *testing.T) {
+		unlike := func(string, core_values.UserId) error {
 			return RandomError()
 		}
-		err := service.NewSafeLikeToggler(getOwner, toggleLike)(target, caller)
+		err := service.NewSafeUnliker(getOwner, unlike, targetType, noPublishing)(target, caller)
 		AssertSomeError(t, err)
 	})
 	t.Run("happy case", func(t *testing.T) {
-		err := service.NewSafeLikeToggler(getOwner, toggleLike)(target, caller)
+		var published []events.Event
+		err := service.NewSafeUnliker(getOwner, unlike, targetType, recordPublished(t, &published))(target, caller)
+		AssertNoError(t, err)
+		want := events.Event{Kind: events.LikesChanged, Actor: caller, TargetType: targetType, TargetId: target}
+		Assert(t, published, []events.Event{want}, "published events")
+	})
+	t.Run("happy case - caller is owner", func(t *testing.T) {
+		err := service.NewSafeUnliker(getOwner, unlike, targetType, func(events.Event) {})(target, owner)
+		AssertNoError(t, err)
+	})
+}
+
+func TestSafeReactionSetter(t *testing.T) {
+	target := RandomId()
+	owner := RandomId()
+	caller := RandomId()
+	reaction := RandomReaction()
+	targetType := events.Post
+
+	getOwner := func(targetId string) (core_values.UserId, error) {
+		if targetId == target {
+			return owner, nil
+		}
+		panic("unexpected args")
+	}
+	t.Run("error case - caller is owner", func(t *testing.T) {
+		err := service.NewSafeReactionSetter(getOwner, nil, targetType, noPublishing)(target, owner, reaction)
+		AssertError(t, err, client_errors.LikingYourself)
+	})
+	t.Run("error case - getting author throws", func(t *testing.T) {
+		getOwner := func(targetId string) (core_values.UserId, error) {
+			return "", RandomError()
+		}
+		err := service.NewSafeReactionSetter(getOwner, nil, targetType, noPublishing)(target, caller, reaction)
+		AssertSomeError(t, err)
+	})
+	newSetReaction := func(inserted bool) func(string, core_values.UserId, values.Reaction) (bool, error) {
+		return func(targetId string, callerId core_values.UserId, gotReaction values.Reaction) (bool, error) {
+			if targetId == target && callerId == caller && gotReaction == reaction {
+				return inserted, nil
+			}
+			panic("unexpected args")
+		}
+	}
+	t.Run("error case - setting the reaction throws", func(t *testing.T) {
+		setReaction := func(string, core_values.UserId, values.Reaction) (bool, error) {
+			return false, RandomError()
+		}
+		err := service.NewSafeReactionSetter(getOwner, setReaction, targetType, noPublishing)(target, caller, reaction)
+		AssertSomeError(t, err)
+	})
+	liked := events.Event{Kind: events.Liked, Actor: caller, Recipient: owner, TargetType: targetType, TargetId: target}
+	likesChanged := events.Event{Kind: events.LikesChanged, Actor: caller, TargetType: targetType, TargetId: target}
+	t.Run("happy case - the first reaction notifies the owner", func(t *testing.T) {
+		var published []events.Event
+		err := service.NewSafeReactionSetter(getOwner, newSetReaction(true), targetType, recordPublished(t, &published))(target, caller, reaction)
+		AssertNoError(t, err)
+		Assert(t, published, []events.Event{liked, likesChanged}, "published events")
+	})
+	t.Run("happy case - changing the reaction doesn't notify the owner", func(t *testing.T) {
+		var published []events.Event
+		err := service.NewSafeReactionSetter(getOwner, newSetReaction(false), targetType, recordPublished(t, &published))(target, caller, reaction)
 		AssertNoError(t, err)
+		Assert(t, published, []events.Event{likesChanged}, "published events")
 	})
 }
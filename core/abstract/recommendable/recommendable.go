@@ -18,15 +18,17 @@ type Recommendable struct {
 	UpdateRecs RecsUpdater
 }
 
-func NewRecommendable(db *sqlx.DB, tableName table_name.TableName) (Recommendable, error) {
+// NewRecommendable recommends the targets from tableName, using the likes of the targets and the follows,
+// which are the likes of the entities from followedTableName
+func NewRecommendable(db *sqlx.DB, tableName, followedTableName table_name.TableName) (Recommendable, error) {
 	// store
-	sqlDB, err := sql_db.NewSqlDB(db, tableName)
+	sqlDB, err := sql_db.NewSqlDB(db, tableName, followedTableName)
 	if err != nil {
 		return Recommendable{}, core_err.Rethrow("opening Recommendable sql db", err)
 	}
 	// service
 	getRecs := service.NewRecsGetter(sqlDB.GetRecs, sqlDB.GetRandom)
-	updateRecs := service.NewRecsUpdater()
+	updateRecs := service.NewRecsUpdater(sqlDB.GetLikes, sqlDB.GetFollows, sqlDB.GetUsersWithRecs, sqlDB.SetRecs)
 	return Recommendable{
 		GetRecs:    getRecs,
 		UpdateRecs: updateRecs,
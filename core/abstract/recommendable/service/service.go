@@ -1,22 +1,138 @@
 package service
 
 import (
-	"errors"
+	"context"
+	"fmt"
+	"github.com/k0marov/go-socnet/core/abstract/recommendable/values"
 	"github.com/k0marov/go-socnet/core/general/core_err"
 	"github.com/k0marov/go-socnet/core/general/core_values"
+	"sort"
 )
 
-type StoreRandomGetter = func(count int) ([]string, error)
+type StoreRandomGetter = func(user core_values.UserId, count int) ([]string, error)
 type StoreRecsGetter = func(user core_values.UserId, count int) ([]string, error)
-type StoreRecsSetter = func(core_values.UserId, []string) error
+type StoreRecsSetter = func(context.Context, core_values.UserId, []string) error
+type StoreUsersWithRecsGetter = func(context.Context) ([]core_values.UserId, error)
+type StoreLikesGetter = func(context.Context) ([]values.Like, error)
+type StoreFollowsGetter = func(context.Context) ([]values.Follow, error)
 
 type RecsGetter = func(user core_values.UserId, count int) ([]string, error)
-type RecsUpdater = func() error
+type RecsUpdater = func(ctx context.Context) error
 
-func NewRecsUpdater() RecsUpdater {
-	return func() error {
-		return errors.New("unimplemented")
+// MaxRecsPerUser is the amount of top scored targets stored for every user
+const MaxRecsPerUser = 100
+
+// FollowWeight is added to the similarity between a user and every profile the user follows
+const FollowWeight = 3
+
+// NewRecsUpdater uses user-based collaborative filtering:
+// users who liked the same targets (or who are followed by the user) are considered similar,
+// and every target liked by a similar user is scored by the sum of similarities.
+// Targets owned or already liked by the user are never recommended to them.
+// Users who no longer like or follow anything get their old recommendations cleared.
+// A failure to store the recommendations of one user doesn't stop the update for the others,
+// the failed users are reported in the returned error. Cancelling ctx stops the update.
+func NewRecsUpdater(getLikes StoreLikesGetter, getFollows StoreFollowsGetter, getUsersWithRecs StoreUsersWithRecsGetter, setRecs StoreRecsSetter) RecsUpdater {
+	return func(ctx context.Context) error {
+		likes, err := getLikes(ctx)
+		if err != nil {
+			return core_err.Rethrow("getting all likes", err)
+		}
+		follows, err := getFollows(ctx)
+		if err != nil {
+			return core_err.Rethrow("getting all follows", err)
+		}
+		usersWithRecs, err := getUsersWithRecs(ctx)
+		if err != nil {
+			return core_err.Rethrow("getting users with recommendations", err)
+		}
+
+		likedBy := map[core_values.UserId]map[string]bool{}
+		likersOf := map[string][]core_values.UserId{}
+		ownerOf := map[string]core_values.UserId{}
+		for _, like := range likes {
+			ownerOf[like.Target] = like.Owner
+			if likedBy[like.Liker] == nil {
+				likedBy[like.Liker] = map[string]bool{}
+			}
+			likedBy[like.Liker][like.Target] = true
+			likersOf[like.Target] = append(likersOf[like.Target], like.Liker)
+		}
+		followedBy := map[core_values.UserId][]core_values.UserId{}
+		for _, follow := range follows {
+			followedBy[follow.Follower] = append(followedBy[follow.Follower], follow.Target)
+		}
+
+		users := map[core_values.UserId]bool{}
+		for user := range likedBy {
+			users[user] = true
+		}
+		for user := range followedBy {
+			users[user] = true
+		}
+		for _, user := range usersWithRecs {
+			users[user] = true
+		}
+
+		var failedUsers []core_values.UserId
+		var lastErr error
+		for user := range users {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			recs := recommend(user, likedBy, likersOf, ownerOf, followedBy[user])
+			err := setRecs(ctx, user, recs)
+			if err != nil {
+				failedUsers = append(failedUsers, user)
+				lastErr = err
+			}
+		}
+		if len(failedUsers) != 0 {
+			sort.Strings(failedUsers)
+			return core_err.Rethrow(fmt.Sprintf("setting recommendations for users %v", failedUsers), lastErr)
+		}
+		return nil
+	}
+}
+
+func recommend(user core_values.UserId, likedBy map[core_values.UserId]map[string]bool, likersOf map[string][]core_values.UserId, ownerOf map[string]core_values.UserId, follows []core_values.UserId) []string {
+	similarity := map[core_values.UserId]int{}
+	for target := range likedBy[user] {
+		for _, liker := range likersOf[target] {
+			if liker != user {
+				similarity[liker]++
+			}
+		}
+	}
+	for _, followed := range follows {
+		if followed != user {
+			similarity[followed] += FollowWeight
+		}
+	}
+
+	scores := map[string]int{}
+	for similarUser, sim := range similarity {
+		for target := range likedBy[similarUser] {
+			if !likedBy[user][target] && ownerOf[target] != user {
+				scores[target] += sim
+			}
+		}
+	}
+
+	recs := make([]string, 0, len(scores))
+	for target := range scores {
+		recs = append(recs, target)
+	}
+	sort.Slice(recs, func(i, j int) bool {
+		if scores[recs[i]] != scores[recs[j]] {
+			return scores[recs[i]] > scores[recs[j]]
+		}
+		return recs[i] < recs[j]
+	})
+	if len(recs) > MaxRecsPerUser {
+		recs = recs[:MaxRecsPerUser]
 	}
+	return recs
 }
 
 func NewRecsGetter(getRecs StoreRecsGetter, getRandom StoreRandomGetter) RecsGetter {
@@ -29,7 +145,7 @@ func NewRecsGetter(getRecs StoreRecsGetter, getRandom StoreRandomGetter) RecsGet
 			return recs, nil
 		}
 
-		randomRecs, err := getRandom(count - len(recs))
+		randomRecs, err := getRandom(user, count-len(recs))
 		if err != nil {
 			return []string{}, core_err.Rethrow("getting random recommendations", err)
 		}
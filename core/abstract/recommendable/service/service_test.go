@@ -1,12 +1,126 @@
 package service_test
 
 import (
+	"context"
 	"github.com/k0marov/go-socnet/core/abstract/recommendable/service"
+	"github.com/k0marov/go-socnet/core/abstract/recommendable/values"
 	"github.com/k0marov/go-socnet/core/general/core_values"
 	. "github.com/k0marov/go-socnet/core/helpers/test_helpers"
+	"strconv"
 	"testing"
 )
 
+func TestRecsUpdater(t *testing.T) {
+	likes := []values.Like{
+		{Target: "1", Liker: "alice"},
+		{Target: "2", Liker: "alice"},
+		{Target: "1", Liker: "bob"},
+		{Target: "3", Liker: "bob"},
+		{Target: "4", Liker: "carol"},
+		{Target: "5", Liker: "dave"},
+		{Target: "6", Liker: "bob", Owner: "alice"},
+	}
+	follows := []values.Follow{
+		{Target: "carol", Follower: "alice"},
+	}
+	getLikes := func(context.Context) ([]values.Like, error) {
+		return likes, nil
+	}
+	getFollows := func(context.Context) ([]values.Follow, error) {
+		return follows, nil
+	}
+	getUsersWithRecs := func(context.Context) ([]core_values.UserId, error) {
+		return []core_values.UserId{"alice", "erin"}, nil
+	}
+	t.Run("error case - getting likes throws", func(t *testing.T) {
+		getLikes := func(context.Context) ([]values.Like, error) {
+			return nil, RandomError()
+		}
+		err := service.NewRecsUpdater(getLikes, nil, nil, nil)(context.Background())
+		AssertSomeError(t, err)
+	})
+	t.Run("error case - getting follows throws", func(t *testing.T) {
+		getFollows := func(context.Context) ([]values.Follow, error) {
+			return nil, RandomError()
+		}
+		err := service.NewRecsUpdater(getLikes, getFollows, nil, nil)(context.Background())
+		AssertSomeError(t, err)
+	})
+	t.Run("error case - getting users with recs throws", func(t *testing.T) {
+		getUsersWithRecs := func(context.Context) ([]core_values.UserId, error) {
+			return nil, RandomError()
+		}
+		err := service.NewRecsUpdater(getLikes, getFollows, getUsersWithRecs, nil)(context.Background())
+		AssertSomeError(t, err)
+	})
+	t.Run("error case - setting recs for one user throws - the other users are still updated", func(t *testing.T) {
+		gotRecs := map[core_values.UserId][]string{}
+		setRecs := func(_ context.Context, user core_values.UserId, recs []string) error {
+			if user == "alice" {
+				return RandomError()
+			}
+			gotRecs[user] = recs
+			return nil
+		}
+		err := service.NewRecsUpdater(getLikes, getFollows, getUsersWithRecs, setRecs)(context.Background())
+		AssertSomeError(t, err)
+		Assert(t, len(gotRecs), 4, "number of updated users")
+	})
+	t.Run("happy case", func(t *testing.T) {
+		gotRecs := map[core_values.UserId][]string{}
+		setRecs := func(_ context.Context, user core_values.UserId, recs []string) error {
+			gotRecs[user] = recs
+			return nil
+		}
+		err := service.NewRecsUpdater(getLikes, getFollows, getUsersWithRecs, setRecs)(context.Background())
+		AssertNoError(t, err)
+		wantRecs := map[core_values.UserId][]string{
+			"alice": {"4", "3"}, // carol is followed (weight 3), bob liked the same post (weight 1), alice's own post 6 is skipped
+			"bob":   {"2"},
+			"carol": {},
+			"dave":  {},
+			"erin":  {}, // erin no longer likes or follows anything, so their old recs are cleared
+		}
+		Assert(t, gotRecs, wantRecs, "the stored recommendations")
+	})
+	t.Run("error case - ctx is cancelled - no more users are updated", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		updated := 0
+		setRecs := func(context.Context, core_values.UserId, []string) error {
+			updated++
+			cancel()
+			return nil
+		}
+		err := service.NewRecsUpdater(getLikes, getFollows, getUsersWithRecs, setRecs)(ctx)
+		AssertError(t, err, context.Canceled)
+		Assert(t, updated, 1, "number of updated users")
+	})
+	t.Run("happy case - limiting the amount of recs", func(t *testing.T) {
+		var likes []values.Like
+		for i := 0; i < service.MaxRecsPerUser*2; i++ {
+			likes = append(likes, values.Like{Target: strconv.Itoa(i), Liker: "bob"})
+		}
+		likes = append(likes, values.Like{Target: "0", Liker: "alice"})
+		getLikes := func(context.Context) ([]values.Like, error) {
+			return likes, nil
+		}
+		getFollows := func(context.Context) ([]values.Follow, error) {
+			return nil, nil
+		}
+		setRecs := func(_ context.Context, user core_values.UserId, recs []string) error {
+			if user == "alice" {
+				Assert(t, len(recs), service.MaxRecsPerUser, "number of recs")
+			}
+			return nil
+		}
+		getUsersWithRecs := func(context.Context) ([]core_values.UserId, error) {
+			return nil, nil
+		}
+		err := service.NewRecsUpdater(getLikes, getFollows, getUsersWithRecs, setRecs)(context.Background())
+		AssertNoError(t, err)
+	})
+}
+
 func TestRecsGetter(t *testing.T) {
 	target := RandomId()
 	count := 5
@@ -36,8 +150,8 @@ func TestRecsGetter(t *testing.T) {
 		}
 		t.Run("happy case", func(t *testing.T) {
 			randomRecs := recs[2:]
-			randomRecsGetter := func(gotCount int) ([]string, error) {
-				if gotCount == count-2 {
+			randomRecsGetter := func(user core_values.UserId, gotCount int) ([]string, error) {
+				if user == target && gotCount == count-2 {
 					return randomRecs, nil
 				}
 				panic("unexpected")
@@ -47,7 +161,7 @@ func TestRecsGetter(t *testing.T) {
 			Assert(t, got, recs, "merged recommendations")
 		})
 		t.Run("error case - getting random recs throws", func(t *testing.T) {
-			randomRecsGetter := func(int) ([]string, error) {
+			randomRecsGetter := func(core_values.UserId, int) ([]string, error) {
 				return nil, RandomError()
 			}
 			_, err := service.NewRecsGetter(storeRecsGetter, randomRecsGetter)(target, count)
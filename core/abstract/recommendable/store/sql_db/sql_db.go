@@ -1,8 +1,9 @@
 package sql_db
 
 import (
-	"fmt"
+	"context"
 	"github.com/jmoiron/sqlx"
+	"github.com/k0marov/go-socnet/core/abstract/recommendable/values"
 	"github.com/k0marov/go-socnet/core/abstract/table_name"
 	"github.com/k0marov/go-socnet/core/general/core_err"
 	"github.com/k0marov/go-socnet/core/general/core_values"
@@ -10,12 +11,16 @@ import (
 )
 
 type SqlDB struct {
-	sql            *sqlx.DB
-	safeRecTable   string
-	safeTargeTable string
+	sql               *sqlx.DB
+	safeRecTable      string
+	safeTargeTable    string
+	safeLikeableTable string
+	safeFollowsTable  string
 }
 
-func NewSqlDB(db *sqlx.DB, targetTable table_name.TableName) (*SqlDB, error) {
+// NewSqlDB reads the follows from the Likeable of followedTable, where liking a followed entity means following it.
+// It expects the <Target>Recommendation table to be already created by a migration in core/general/migrations
+func NewSqlDB(db *sqlx.DB, targetTable, followedTable table_name.TableName) (*SqlDB, error) {
 	targetName, err := targetTable.Value()
 	if err != nil {
 		return nil, core_err.Rethrow("getting target table name", err)
@@ -24,54 +29,46 @@ func NewSqlDB(db *sqlx.DB, targetTable table_name.TableName) (*SqlDB, error) {
 	if err != nil {
 		return nil, core_err.Rethrow("getting recommendation table name", err)
 	}
-	err = initSQL(db, targetName, recommendationTable)
+	likeableTable, err := table_name.NewTableName("Likeable" + targetName).Value()
 	if err != nil {
-		return nil, core_err.Rethrow("while initializing sql", err)
+		return nil, core_err.Rethrow("getting likeable table name", err)
 	}
-	return &SqlDB{sql: db, safeRecTable: recommendationTable, safeTargeTable: targetName}, nil
-}
-
-func initSQL(db *sqlx.DB, verifiedTarget, verifiedRecommendation string) error {
-	_, err := db.Exec(`
-		CREATE TABLE IF NOT EXISTS ` + verifiedRecommendation + `(
-			recommendation_id INT NOT NULL, 
-			user_id INT NOT NULL, 
-			FOREIGN KEY(recommendation_id) REFERENCES ` + verifiedTarget + `(id), 
-			FOREIGN KEY(user_id) REFERENCES Profile(id)
-		)
-    `)
+	followedName, err := followedTable.Value()
 	if err != nil {
-		return fmt.Errorf("while creating table %s: %w", verifiedRecommendation, err)
+		return nil, core_err.Rethrow("getting followed table name", err)
 	}
-	verifiedIndex := verifiedRecommendation + "Index"
-	_, err = db.Exec(`
-		CREATE INDEX IF NOT EXISTS `+verifiedIndex+` ON `+verifiedRecommendation+` (user_id)
-    `, verifiedIndex)
+	followsTable, err := table_name.NewTableName("Likeable" + followedName).Value()
 	if err != nil {
-		return fmt.Errorf("while creating index %s: %w", verifiedIndex, err)
+		return nil, core_err.Rethrow("getting follows table name", err)
 	}
-	return nil
+	return &SqlDB{sql: db, safeRecTable: recommendationTable, safeTargeTable: targetName, safeLikeableTable: likeableTable, safeFollowsTable: followsTable}, nil
 }
 
+// GetRecs skips the stored recommendations which the user has liked since they were stored
 func (db *SqlDB) GetRecs(user core_values.UserId, count int) ([]string, error) {
 	var recs []string
-	err := db.sql.Select(&recs, `
+	err := db.sql.Select(&recs, db.sql.Rebind(`
 		SELECT recommendation_id FROM `+db.safeRecTable+` WHERE user_id = ?
+		AND recommendation_id NOT IN (SELECT target_id FROM `+db.safeLikeableTable+` WHERE liker_id = ?)
 		ORDER BY RANDOM()
 	    LIMIT ? 
-    `, user, count)
+    `), user, user, count)
 	if err != nil {
 		return []string{}, core_err.Rethrow("selecting recs from DB", err)
 	}
 	return recs, nil
 }
-func (db *SqlDB) GetRandom(count int) ([]string, error) {
+
+// GetRandom returns random targets which are neither owned nor liked by the user
+func (db *SqlDB) GetRandom(user core_values.UserId, count int) ([]string, error) {
 	var recs []string
-	err := db.sql.Select(&recs, `
+	err := db.sql.Select(&recs, db.sql.Rebind(`
 		SELECT id FROM `+db.safeTargeTable+` 
+		WHERE owner_id != ?
+		AND id NOT IN (SELECT target_id FROM `+db.safeLikeableTable+` WHERE liker_id = ?)
 		ORDER BY RANDOM() 
 		LIMIT ?
-    `, count)
+    `), user, user, count)
 	if err != nil {
 		return []string{}, core_err.Rethrow("selecting random recs", err)
 	}
@@ -83,12 +80,66 @@ type recModel struct {
 	RecommendationId string `db:"recommendation_id"`
 }
 
-func (db *SqlDB) SetRecs(user core_values.UserId, recs []string) error {
-	_, err := db.sql.NamedExec(`
-		INSERT INTO `+db.safeRecTable+`(recommendation_id, user_id) VALUES (:recommendation_id, :user_id)
-    `, helpers.MapForEach(recs, func(rec string) recModel { return recModel{UserId: user, RecommendationId: rec} }))
+// SetRecs replaces all previous recommendations of the user with the provided ones
+func (db *SqlDB) SetRecs(ctx context.Context, user core_values.UserId, recs []string) error {
+	tx, err := db.sql.BeginTxx(ctx, nil)
+	if err != nil {
+		return core_err.Rethrow("beginning a transaction", err)
+	}
+	defer tx.Rollback()
+	_, err = tx.ExecContext(ctx, tx.Rebind(`
+		DELETE FROM `+db.safeRecTable+` WHERE user_id = ?
+    `), user)
 	if err != nil {
-		return core_err.Rethrow("builk inserting recs into DB", err)
+		return core_err.Rethrow("deleting old recs from DB", err)
+	}
+	if len(recs) != 0 {
+		_, err = tx.NamedExecContext(ctx, `
+			INSERT INTO `+db.safeRecTable+`(recommendation_id, user_id) VALUES (:recommendation_id, :user_id)
+		`, helpers.MapForEach(recs, func(rec string) recModel { return recModel{UserId: user, RecommendationId: rec} }))
+		if err != nil {
+			return core_err.Rethrow("builk inserting recs into DB", err)
+		}
+	}
+	err = tx.Commit()
+	if err != nil {
+		return core_err.Rethrow("committing new recs", err)
 	}
 	return nil
 }
+
+// GetUsersWithRecs returns every user who has at least one stored recommendation
+func (db *SqlDB) GetUsersWithRecs(ctx context.Context) ([]core_values.UserId, error) {
+	var users []core_values.UserId
+	err := db.sql.SelectContext(ctx, &users, `
+		SELECT DISTINCT user_id FROM `+db.safeRecTable+`
+    `)
+	if err != nil {
+		return []core_values.UserId{}, core_err.Rethrow("selecting users with recs", err)
+	}
+	return users, nil
+}
+
+func (db *SqlDB) GetLikes(ctx context.Context) ([]values.Like, error) {
+	var likes []values.Like
+	err := db.sql.SelectContext(ctx, &likes, `
+		SELECT l.target_id, l.liker_id, t.owner_id 
+		FROM `+db.safeLikeableTable+` l
+		JOIN `+db.safeTargeTable+` t ON t.id = l.target_id
+    `)
+	if err != nil {
+		return []values.Like{}, core_err.Rethrow("selecting all likes", err)
+	}
+	return likes, nil
+}
+
+func (db *SqlDB) GetFollows(ctx context.Context) ([]values.Follow, error) {
+	var follows []values.Follow
+	err := db.sql.SelectContext(ctx, &follows, `
+		SELECT target_id, liker_id FROM `+db.safeFollowsTable+`
+    `)
+	if err != nil {
+		return []values.Follow{}, core_err.Rethrow("selecting all follows", err)
+	}
+	return follows, nil
+}
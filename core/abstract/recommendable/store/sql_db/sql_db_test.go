@@ -1,8 +1,11 @@
 package sql_db_test
 
 import (
+	"context"
 	"github.com/jmoiron/sqlx"
+	likeable_db "github.com/k0marov/go-socnet/core/abstract/likeable/store/sql_db"
 	"github.com/k0marov/go-socnet/core/abstract/recommendable/store/sql_db"
+	"github.com/k0marov/go-socnet/core/abstract/recommendable/values"
 	"github.com/k0marov/go-socnet/core/abstract/table_name"
 	. "github.com/k0marov/go-socnet/core/helpers/test_helpers"
 	profiles_db "github.com/k0marov/go-socnet/features/profiles/store/sql_db"
@@ -10,12 +13,13 @@ import (
 	"reflect"
 	"sort"
 	"testing"
+	"time"
 )
 
 var targetTblName = table_name.NewTableName("Target")
 
 func TestSqlDB_ErrorHandling(t *testing.T) {
-	db := OpenSqliteDB(t)
+	db := OpenTestDB(t)
 	sqlDB := setupSqlDB(t, db)
 	db.Close() // this will make all calls to db throw
 	t.Run("GetRecs", func(t *testing.T) {
@@ -23,16 +27,28 @@ func TestSqlDB_ErrorHandling(t *testing.T) {
 		AssertSomeError(t, err)
 	})
 	t.Run("GetRandom", func(t *testing.T) {
-		_, err := sqlDB.GetRandom(RandomInt())
+		_, err := sqlDB.GetRandom(RandomId(), RandomInt())
 		AssertSomeError(t, err)
 	})
 	t.Run("SetRecs", func(t *testing.T) {
-		err := sqlDB.SetRecs(RandomId(), []string{RandomId(), RandomId()})
+		err := sqlDB.SetRecs(context.Background(), RandomId(), []string{RandomId(), RandomId()})
+		AssertSomeError(t, err)
+	})
+	t.Run("GetUsersWithRecs", func(t *testing.T) {
+		_, err := sqlDB.GetUsersWithRecs(context.Background())
+		AssertSomeError(t, err)
+	})
+	t.Run("GetLikes", func(t *testing.T) {
+		_, err := sqlDB.GetLikes(context.Background())
+		AssertSomeError(t, err)
+	})
+	t.Run("GetFollows", func(t *testing.T) {
+		_, err := sqlDB.GetFollows(context.Background())
 		AssertSomeError(t, err)
 	})
 }
 func TestSqlDB(t *testing.T) {
-	db := OpenSqliteDB(t)
+	db := OpenTestDB(t)
 	sqlDB := setupSqlDB(t, db)
 	profilesDB, err := profiles_db.NewSqlDB(db)
 	AssertNoError(t, err)
@@ -43,10 +59,10 @@ func TestSqlDB(t *testing.T) {
 	// add a hundred recommendations for this profile
 	var targets []string
 	for i := 0; i < 100; i++ {
-		targets = append(targets, createTargetEntity(t, db))
+		targets = append(targets, createTargetEntity(t, db, RandomId()))
 	}
 	sort.Strings(targets)
-	err = sqlDB.SetRecs(profile.Id, targets)
+	err = sqlDB.SetRecs(context.Background(), profile.Id, targets)
 	AssertNoError(t, err)
 
 	gotRecs, err := sqlDB.GetRecs(profile.Id, 100)
@@ -64,20 +80,116 @@ func TestSqlDB(t *testing.T) {
 	Assert(t, len(gotRecsLimited), 10, "length of limited recs")
 
 	// assert getting random posts works
-	gotRandom, err := sqlDB.GetRandom(100)
+	gotRandom, err := sqlDB.GetRandom(profile.Id, 100)
 	AssertNoError(t, err)
 	sort.Strings(gotRandom)
 	Assert(t, gotRandom, targets, "returned random recs")
 
 	// assert that limiting count on random posts works
-	gotRandomLimited, err := sqlDB.GetRandom(10)
+	gotRandomLimited, err := sqlDB.GetRandom(profile.Id, 10)
 	AssertNoError(t, err)
 	Assert(t, len(gotRandomLimited), 10, "length of limited random recs")
+
+	// assert setting recs again replaces the old ones
+	newRecs := targets[:5]
+	err = sqlDB.SetRecs(context.Background(), profile.Id, newRecs)
+	AssertNoError(t, err)
+	gotRecs, err = sqlDB.GetRecs(profile.Id, 100)
+	AssertNoError(t, err)
+	sort.Strings(gotRecs)
+	Assert(t, gotRecs, newRecs, "recommendations after replacing")
+
+	users, err := sqlDB.GetUsersWithRecs(context.Background())
+	AssertNoError(t, err)
+	Assert(t, users, []string{profile.Id}, "users with recommendations")
+
+	// assert setting empty recs removes all old ones
+	err = sqlDB.SetRecs(context.Background(), profile.Id, []string{})
+	AssertNoError(t, err)
+	gotRecs, err = sqlDB.GetRecs(profile.Id, 100)
+	AssertNoError(t, err)
+	Assert(t, len(gotRecs), 0, "number of recommendations after clearing")
+	users, err = sqlDB.GetUsersWithRecs(context.Background())
+	AssertNoError(t, err)
+	Assert(t, len(users), 0, "number of users with recommendations after clearing")
+}
+
+func TestSqlDB_OwnAndLikedTargets(t *testing.T) {
+	db := OpenTestDB(t)
+	sqlDB := setupSqlDB(t, db)
+	profilesDB, err := profiles_db.NewSqlDB(db)
+	AssertNoError(t, err)
+	likeableTarget, err := likeable_db.NewSqlDB(db, targetTblName)
+	AssertNoError(t, err)
+
+	user := RandomProfileModel()
+	profilesDB.CreateProfile(user)
+	own := createTargetEntity(t, db, user.Id)
+	liked := createTargetEntity(t, db, RandomId())
+	other := createTargetEntity(t, db, RandomId())
+	_, err = likeableTarget.Like(liked, user.Id, time.Now())
+	AssertNoError(t, err)
+
+	// assert random targets don't include the own and the liked ones
+	gotRandom, err := sqlDB.GetRandom(user.Id, 100)
+	AssertNoError(t, err)
+	Assert(t, contains(gotRandom, own), false, "the own target is among random ones")
+	Assert(t, contains(gotRandom, liked), false, "the liked target is among random ones")
+	Assert(t, contains(gotRandom, other), true, "the other target is among random ones")
+
+	// assert the recommendations liked after they were stored are skipped
+	err = sqlDB.SetRecs(context.Background(), user.Id, []string{liked, other})
+	AssertNoError(t, err)
+	gotRecs, err := sqlDB.GetRecs(user.Id, 100)
+	AssertNoError(t, err)
+	Assert(t, gotRecs, []string{other}, "returned recommendations")
+}
+
+func TestSqlDB_LikesAndFollows(t *testing.T) {
+	db := OpenTestDB(t)
+	sqlDB := setupSqlDB(t, db)
+	profilesDB, err := profiles_db.NewSqlDB(db)
+	AssertNoError(t, err)
+	likeableTarget, err := likeable_db.NewSqlDB(db, targetTblName)
+	AssertNoError(t, err)
+	likeableProfile, err := likeable_db.NewSqlDB(db, profilesDB.TableName)
+	AssertNoError(t, err)
+
+	liker := RandomProfileModel()
+	profilesDB.CreateProfile(liker)
+	followed := RandomProfileModel()
+	profilesDB.CreateProfile(followed)
+	owner := RandomId()
+	target := createTargetEntity(t, db, owner)
+
+	_, err = likeableTarget.Like(target, liker.Id, time.Now())
+	AssertNoError(t, err)
+	_, err = likeableProfile.Like(followed.Id, liker.Id, time.Now())
+	AssertNoError(t, err)
+
+	likes, err := sqlDB.GetLikes(context.Background())
+	AssertNoError(t, err)
+	Assert(t, contains(likes, values.Like{Target: target, Liker: liker.Id, Owner: owner}), true, "the like is returned")
+
+	follows, err := sqlDB.GetFollows(context.Background())
+	AssertNoError(t, err)
+	Assert(t, contains(follows, values.Follow{Target: followed.Id, Follower: liker.Id}), true, "the follow is returned")
+}
+
+func contains[T comparable](list []T, elem T) bool {
+	for _, e := range list {
+		if e == elem {
+			return true
+		}
+	}
+	return false
 }
 
 func TestSqlDB_Injection(t *testing.T) {
-	db := OpenSqliteDB(t)
-	_, err := sql_db.NewSqlDB(db, table_name.NewTableName("'; DROP TABLE Students; --"))
+	db := OpenTestDB(t)
+	_, err := sql_db.NewSqlDB(db, table_name.NewTableName("'; DROP TABLE Students; --"), profiles_db.ProfileTableName)
+	AssertSomeError(t, err)
+	_, err = sql_db.NewSqlDB(db, targetTblName, table_name.NewTableName("'; DROP TABLE Students; --"))
 	AssertSomeError(t, err)
 }
 
@@ -87,23 +199,46 @@ func setupSqlDB(t testing.TB, db *sqlx.DB) *sql_db.SqlDB {
 	AssertNoError(t, err)
 	_, err = db.Exec(`
 		CREATE TABLE IF NOT EXISTS ` + targetTable + `(
-		    id INTEGER PRIMARY KEY
-		)
+		    id INTEGER PRIMARY KEY,
+		    owner_id INT NOT NULL
+		);
+		CREATE TABLE IF NOT EXISTS Likeable` + targetTable + `(
+			id ` + SerialPrimaryKey(db) + `,
+			target_id INT NOT NULL, 
+			liker_id INT NOT NULL, 
+			created_at BIGINT NOT NULL DEFAULT 0,
+			reaction VARCHAR(16) NOT NULL DEFAULT 'like',
+			FOREIGN KEY(target_id) REFERENCES ` + targetTable + `(id), 
+			FOREIGN KEY(liker_id) REFERENCES Profile(id),
+			UNIQUE(target_id, liker_id)
+		);
+		CREATE TABLE IF NOT EXISTS Likeable` + targetTable + `Count(
+			counter VARCHAR(16) NOT NULL,
+			id VARCHAR(255) NOT NULL,
+			likes INT NOT NULL,
+			PRIMARY KEY(counter, id)
+		);
+		CREATE TABLE IF NOT EXISTS ` + targetTable + `Recommendation(
+			recommendation_id INT NOT NULL, 
+			user_id INT NOT NULL, 
+			FOREIGN KEY(recommendation_id) REFERENCES ` + targetTable + `(id), 
+			FOREIGN KEY(user_id) REFERENCES Profile(id)
+		);
    `)
 	AssertNoError(t, err)
-	sqlDB, err := sql_db.NewSqlDB(db, targetTblName)
+	sqlDB, err := sql_db.NewSqlDB(db, targetTblName, profiles_db.ProfileTableName)
 	AssertNoError(t, err)
 	return sqlDB
 }
 
-func createTargetEntity(t testing.TB, db *sqlx.DB) (id string) {
+func createTargetEntity(t testing.TB, db *sqlx.DB, owner string) (id string) {
 	t.Helper()
 	targetTable, err := targetTblName.Value()
 	AssertNoError(t, err)
 	id = RandomId()
-	_, err = db.Exec(`
-		INSERT INTO `+targetTable+`(id) VALUES (?)
-   `, id)
+	_, err = db.Exec(db.Rebind(`
+		INSERT INTO `+targetTable+`(id, owner_id) VALUES (?, ?)
+   `), id, owner)
 	AssertNoError(t, err)
 	return
 }
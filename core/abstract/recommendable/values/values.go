@@ -0,0 +1,14 @@
+package values
+
+import "github.com/k0marov/go-socnet/core/general/core_values"
+
+type Like struct {
+	Target string             `db:"target_id"`
+	Liker  core_values.UserId `db:"liker_id"`
+	Owner  core_values.UserId `db:"owner_id"`
+}
+
+type Follow struct {
+	Target   core_values.UserId `db:"target_id"`
+	Follower core_values.UserId `db:"liker_id"`
+}
@@ -0,0 +1,101 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"github.com/k0marov/go-socnet/core/general/core_err"
+	"github.com/k0marov/go-socnet/core/general/periodic"
+	"net/http"
+	"sync"
+)
+
+// App owns the http server, the periodic jobs and the db, so that they can be shut down together
+type App struct {
+	server *http.Server
+	db     *sqlx.DB
+	jobs   []*periodic.Job
+
+	mu       sync.Mutex
+	jobsCtx  context.Context
+	stopJobs context.CancelFunc
+	jobsDone sync.WaitGroup
+}
+
+func NewApp(addr string, handler http.Handler, db *sqlx.DB, jobs []*periodic.Job) *App {
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	return &App{
+		server:   &http.Server{Addr: addr, Handler: handler},
+		db:       db,
+		jobs:     jobs,
+		jobsCtx:  jobsCtx,
+		stopJobs: stopJobs,
+	}
+}
+
+// OnShutdown registers f to be called when Shutdown starts, e.g. to end the long-lived requests, which are not drained otherwise
+func (a *App) OnShutdown(f func()) {
+	a.server.RegisterOnShutdown(f)
+}
+
+func (a *App) Handler() http.Handler {
+	return a.server.Handler
+}
+
+// Start starts the periodic jobs and serves http requests.
+// It blocks until the server is shut down, returning nil if that was caused by Shutdown.
+func (a *App) Start() error {
+	a.mu.Lock()
+	if a.jobsCtx.Err() == nil { // jobs are not started if Shutdown was already called
+		for _, job := range a.jobs {
+			a.jobsDone.Add(1)
+			go func(job *periodic.Job) {
+				defer a.jobsDone.Done()
+				job.Run(a.jobsCtx)
+			}(job)
+		}
+	}
+	a.mu.Unlock()
+
+	err := a.server.ListenAndServe()
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		a.stopJobs()
+		return core_err.Rethrow("serving http", err)
+	}
+	return nil
+}
+
+// Shutdown waits for the in-flight requests to finish, stops the periodic jobs and closes the db.
+// If ctx is done before that, the remaining requests and jobs are abandoned, but the db is still closed.
+func (a *App) Shutdown(ctx context.Context) error {
+	serverErr := a.server.Shutdown(ctx)
+
+	a.mu.Lock()
+	a.stopJobs()
+	a.mu.Unlock()
+	jobsStopped := make(chan struct{})
+	go func() {
+		a.jobsDone.Wait()
+		close(jobsStopped)
+	}()
+	var jobsErr error
+	select {
+	case <-jobsStopped:
+	case <-ctx.Done():
+		jobsErr = fmt.Errorf("while waiting for periodic jobs to stop: %w", ctx.Err())
+	}
+
+	dbErr := a.db.Close()
+
+	if serverErr != nil {
+		return core_err.Rethrow("shutting down the http server", serverErr)
+	}
+	if jobsErr != nil {
+		return jobsErr
+	}
+	if dbErr != nil {
+		return core_err.Rethrow("closing the db", dbErr)
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package core_test
+
+import (
+	"context"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"github.com/k0marov/go-socnet/core"
+	"github.com/k0marov/go-socnet/core/general/periodic"
+	. "github.com/k0marov/go-socnet/core/helpers/test_helpers"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func freeAddr(t testing.TB) string {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	AssertNoError(t, err)
+	defer l.Close()
+	return l.Addr().String()
+}
+
+func waitUntilServing(t testing.TB, addr string) {
+	t.Helper()
+	for i := 0; i < 100; i++ {
+		conn, err := net.Dial("tcp", addr)
+		if err == nil {
+			conn.Close()
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("the server did not start listening on %v", addr)
+}
+
+func TestApp(t *testing.T) {
+	t.Run("shutdown drains in-flight requests, stops the jobs and closes the db", func(t *testing.T) {
+		addr := freeAddr(t)
+		db, err := sqlx.Open("sqlite3", ":memory:")
+		AssertNoError(t, err)
+
+		requestStarted := make(chan struct{})
+		releaseRequest := make(chan struct{})
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			close(requestStarted)
+			<-releaseRequest
+			fmt.Fprint(w, "done")
+		})
+		jobCancelled := make(chan struct{})
+		job := periodic.NewJob(RandomString(), time.Hour, 0, func(ctx context.Context) error {
+			go func() {
+				<-ctx.Done()
+				close(jobCancelled)
+			}()
+			return nil
+		})
+
+		app := core.NewApp(addr, handler, db, []*periodic.Job{job})
+		startErr := make(chan error, 1)
+		go func() { startErr <- app.Start() }()
+		waitUntilServing(t, addr)
+
+		response := make(chan string, 1)
+		go func() {
+			resp, err := http.Get("http://" + addr)
+			if err != nil {
+				response <- err.Error()
+				return
+			}
+			defer resp.Body.Close()
+			body, _ := io.ReadAll(resp.Body)
+			response <- string(body)
+		}()
+		<-requestStarted
+
+		shutdownErr := make(chan error, 1)
+		go func() { shutdownErr <- app.Shutdown(context.Background()) }()
+		time.Sleep(50 * time.Millisecond)
+		select {
+		case <-shutdownErr:
+			t.Fatal("shutdown returned before the in-flight request finished")
+		default:
+		}
+		close(releaseRequest)
+
+		Assert(t, <-response, "done", "response to the in-flight request")
+		AssertNoError(t, <-shutdownErr)
+		AssertNoError(t, <-startErr)
+		<-jobCancelled
+		Assert(t, job.Status().Runs, 1, "number of job runs")
+		AssertSomeError(t, db.Ping())
+	})
+	t.Run("error case - the address is already in use", func(t *testing.T) {
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		AssertNoError(t, err)
+		defer l.Close()
+		db, err := sqlx.Open("sqlite3", ":memory:")
+		AssertNoError(t, err)
+
+		app := core.NewApp(l.Addr().String(), http.NotFoundHandler(), db, nil)
+		AssertSomeError(t, app.Start())
+		AssertNoError(t, app.Shutdown(context.Background()))
+	})
+}
@@ -84,6 +84,12 @@ var LikingYourself = ClientError{
 	HTTPCode:       http.StatusBadRequest,
 }
 
+var InvalidReaction = ClientError{
+	DetailCode:     "invalid-reaction",
+	ReadableDetail: "The reaction should be one of: like, love, laugh, sad, angry.",
+	HTTPCode:       http.StatusBadRequest,
+}
+
 var TextTooLong = ClientError{
 	DetailCode:     "long-text",
 	ReadableDetail: "The provided text is too long.",
@@ -96,6 +102,18 @@ var EmptyText = ClientError{
 	HTTPCode:       http.StatusBadRequest,
 }
 
+var InvalidParentComment = ClientError{
+	DetailCode:     "invalid-parent-comment",
+	ReadableDetail: "The parent comment should be an existing comment of the same post.",
+	HTTPCode:       http.StatusBadRequest,
+}
+
+var CommentsDisabled = ClientError{
+	DetailCode:     "comments-disabled",
+	ReadableDetail: "The author of the post has disabled comments for it.",
+	HTTPCode:       http.StatusForbidden,
+}
+
 var NonIntegerCount = ClientError{
 	DetailCode:     "non-integer-count",
 	ReadableDetail: "The \"count\" query argument of the feed endpoint should only be set to integers.",
@@ -107,3 +125,63 @@ var TooBigCount = ClientError{
 	ReadableDetail: "The provided count is too big.",
 	HTTPCode:       http.StatusBadRequest,
 }
+
+var InvalidLimit = ClientError{
+	DetailCode:     "invalid-limit",
+	ReadableDetail: "The \"limit\" query argument should be set to a positive integer.",
+	HTTPCode:       http.StatusBadRequest,
+}
+
+var TooBigLimit = ClientError{
+	DetailCode:     "too-big-limit",
+	ReadableDetail: "The provided limit is too big.",
+	HTTPCode:       http.StatusBadRequest,
+}
+
+var InvalidCursor = ClientError{
+	DetailCode:     "invalid-cursor",
+	ReadableDetail: "The provided cursor is not valid.",
+	HTTPCode:       http.StatusBadRequest,
+}
+
+var InvalidImageIndex = ClientError{
+	DetailCode:     "invalid-image-index",
+	ReadableDetail: "Every kept image should refer to a different existing image of the post by its index.",
+	HTTPCode:       http.StatusBadRequest,
+}
+
+var InvalidTag = ClientError{
+	DetailCode:     "invalid-tag",
+	ReadableDetail: "A tag can only contain letters, digits and underscores and should be at most 64 characters long.",
+	HTTPCode:       http.StatusBadRequest,
+}
+
+var InvalidSearchQuery = ClientError{
+	DetailCode:     "invalid-search-query",
+	ReadableDetail: "The \"q\" query argument should contain from 1 to 10 words.",
+	HTTPCode:       http.StatusBadRequest,
+}
+
+var InvalidSearchType = ClientError{
+	DetailCode:     "invalid-search-type",
+	ReadableDetail: "The \"type\" query argument should be one of \"posts\", \"profiles\" or \"comments\".",
+	HTTPCode:       http.StatusBadRequest,
+}
+
+var TooManyViewedPosts = ClientError{
+	DetailCode:     "too-many-viewed-posts",
+	ReadableDetail: "The \"posts\" query argument should contain at most 50 post ids.",
+	HTTPCode:       http.StatusBadRequest,
+}
+
+var MessagingYourself = ClientError{
+	DetailCode:     "messaging-yourself",
+	ReadableDetail: "You cannot start a conversation with yourself.",
+	HTTPCode:       http.StatusBadRequest,
+}
+
+var EmptyMessage = ClientError{
+	DetailCode:     "empty-message",
+	ReadableDetail: "A message should have a text or at least one image.",
+	HTTPCode:       http.StatusBadRequest,
+}
@@ -0,0 +1,242 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/k0marov/go-socnet/core/general/core_err"
+	"github.com/k0marov/go-socnet/core/general/database"
+	"golang.org/x/crypto/bcrypt"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+type Config struct {
+	Server ServerConfig `json:"server"`
+	Auth   AuthConfig   `json:"auth"`
+	DB     DBConfig     `json:"db"`
+	Static StaticConfig `json:"static"`
+	Limits LimitsConfig `json:"limits"`
+}
+
+type ServerConfig struct {
+	Port int `json:"port"`
+	// ShutdownTimeout is the number of seconds that in-flight requests are given to finish on shutdown
+	ShutdownTimeout int `json:"shutdown_timeout"`
+}
+
+type AuthConfig struct {
+	HashCost  int    `json:"hash_cost"`
+	StorePath string `json:"store_path"`
+}
+
+type DBConfig struct {
+	Driver string `json:"driver"`
+	DSN    string `json:"dsn"`
+}
+
+type StaticConfig struct {
+	// Dir is the file system path of a directory where static files will be stored
+	Dir string `json:"dir"`
+	// Host is the URL from which the static directory can be accessed
+	Host string `json:"host"`
+	// PrivateDir is the file system path of a directory where private files, like the images of direct messages, will be stored.
+	// It should not be accessible from the static host, since these files are served only to the users allowed to see them.
+	PrivateDir string `json:"private_dir"`
+}
+
+type LimitsConfig struct {
+	MaxPostTextLength    int `json:"max_post_text_length"`
+	MaxCommentTextLength int `json:"max_comment_text_length"`
+	MaxMessageTextLength int `json:"max_message_text_length"`
+	MaxAboutLength       int `json:"max_about_length"`
+	MaxFeedCount         int `json:"max_feed_count"`
+}
+
+// Default returns the config with every setting except for the static dir and host filled in
+func Default() Config {
+	return Config{
+		Server: ServerConfig{Port: 4242, ShutdownTimeout: 10},
+		Auth:   AuthConfig{HashCost: 8, StorePath: "auth.db.csv"},
+		DB:     DBConfig{Driver: database.SQLite, DSN: "db.sqlite3"},
+		Static: StaticConfig{PrivateDir: "private"},
+		Limits: LimitsConfig{
+			MaxPostTextLength:    1000,
+			MaxCommentTextLength: 255,
+			MaxMessageTextLength: 1000,
+			MaxAboutLength:       255,
+			MaxFeedCount:         50,
+		},
+	}
+}
+
+// EnvLookuper os.LookupEnv implements this
+type EnvLookuper = func(key string) (string, bool)
+
+// FileReader os.ReadFile implements this
+type FileReader = func(name string) ([]byte, error)
+
+const configFileEnv = "SOCIO_CONFIG"
+
+var ErrInvalidConfig = errors.New("invalid config")
+
+// Load builds the config from the defaults, overridden by a JSON config file (if there is one),
+// then by environment variables and then by command line flags.
+// The config file path can be provided in the -config flag or the SOCIO_CONFIG environment variable.
+func Load(args []string, lookupEnv EnvLookuper, readFile FileReader) (Config, error) {
+	cfg := Default()
+
+	flags := flag.NewFlagSet("socnet", flag.ContinueOnError)
+	configFile := flags.String("config", "", "path to a JSON config file")
+	settings := cfg.settings()
+	var flagValues []func() error
+	for _, s := range settings {
+		s := s
+		flags.Func(s.flag, s.usage+" (env "+s.env+")", func(value string) error {
+			flagValues = append(flagValues, func() error { return s.set(value) })
+			return nil
+		})
+	}
+	err := flags.Parse(args)
+	if err != nil {
+		return Config{}, core_err.Rethrow("parsing command line flags", err)
+	}
+
+	if *configFile == "" {
+		*configFile, _ = lookupEnv(configFileEnv)
+	}
+	if *configFile != "" {
+		contents, err := readFile(*configFile)
+		if err != nil {
+			return Config{}, core_err.Rethrow("reading the config file", err)
+		}
+		err = json.Unmarshal(contents, &cfg)
+		if err != nil {
+			return Config{}, core_err.Rethrow("parsing the config file", err)
+		}
+	}
+
+	for _, s := range settings {
+		if value, exists := lookupEnv(s.env); exists {
+			err := s.set(value)
+			if err != nil {
+				return Config{}, fmt.Errorf("while reading environment variable %s: %w", s.env, err)
+			}
+		}
+	}
+	for _, setFlag := range flagValues {
+		err := setFlag()
+		if err != nil {
+			return Config{}, core_err.Rethrow("applying a command line flag", err)
+		}
+	}
+
+	err = cfg.Validate()
+	if err != nil {
+		return Config{}, err
+	}
+	return cfg, nil
+}
+
+// setting binds a config field to an environment variable and a command line flag
+type setting struct {
+	env   string
+	flag  string
+	usage string
+	set   func(value string) error
+}
+
+func (c *Config) settings() []setting {
+	return []setting{
+		intSetting("SOCIO_PORT", "port", "port of the http server", &c.Server.Port),
+		intSetting("SOCIO_SHUTDOWN_TIMEOUT", "shutdown-timeout", "seconds given to in-flight requests to finish on shutdown", &c.Server.ShutdownTimeout),
+		intSetting("SOCIO_AUTH_HASH_COST", "auth-hash-cost", "bcrypt cost of password hashes", &c.Auth.HashCost),
+		stringSetting("SOCIO_AUTH_STORE", "auth-store", "path to the file of the auth store", &c.Auth.StorePath),
+		stringSetting("SOCIO_DB_DRIVER", "db-driver", "database driver, sqlite3 or postgres", &c.DB.Driver),
+		stringSetting("SOCIO_DB_DSN", "db-dsn", "data source name for the database driver", &c.DB.DSN),
+		stringSetting("SOCIO_STATIC_DIR", "static-dir", "file system path of the directory for static files", &c.Static.Dir),
+		stringSetting("SOCIO_STATIC_HOST", "static-host", "URL from which the static directory can be accessed", &c.Static.Host),
+		stringSetting("SOCIO_PRIVATE_DIR", "private-dir", "file system path of the directory for private files, outside of the static directory", &c.Static.PrivateDir),
+		intSetting("SOCIO_MAX_POST_TEXT_LENGTH", "max-post-text-length", "maximum length of a post text", &c.Limits.MaxPostTextLength),
+		intSetting("SOCIO_MAX_COMMENT_TEXT_LENGTH", "max-comment-text-length", "maximum length of a comment text", &c.Limits.MaxCommentTextLength),
+		intSetting("SOCIO_MAX_MESSAGE_TEXT_LENGTH", "max-message-text-length", "maximum length of a direct message text", &c.Limits.MaxMessageTextLength),
+		intSetting("SOCIO_MAX_ABOUT_LENGTH", "max-about-length", "maximum length of a profile about", &c.Limits.MaxAboutLength),
+		intSetting("SOCIO_MAX_FEED_COUNT", "max-feed-count", "maximum number of posts in a single feed request", &c.Limits.MaxFeedCount),
+	}
+}
+
+func stringSetting(env, flag, usage string, field *string) setting {
+	return setting{env, flag, usage, func(value string) error {
+		*field = value
+		return nil
+	}}
+}
+
+func intSetting(env, flag, usage string, field *int) setting {
+	return setting{env, flag, usage, func(value string) error {
+		num, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("%v should be an integer, but got %q", flag, value)
+		}
+		*field = num
+		return nil
+	}}
+}
+
+// Validate checks that the config can be used to run the server
+func (c Config) Validate() error {
+	if c.Server.Port <= 0 || c.Server.Port > 65535 {
+		return fmt.Errorf("%w: port %d is out of range", ErrInvalidConfig, c.Server.Port)
+	}
+	if c.Auth.HashCost < bcrypt.MinCost || c.Auth.HashCost > bcrypt.MaxCost {
+		return fmt.Errorf("%w: auth hash cost should be between %d and %d", ErrInvalidConfig, bcrypt.MinCost, bcrypt.MaxCost)
+	}
+	if c.Auth.StorePath == "" {
+		return fmt.Errorf("%w: auth store path is not set", ErrInvalidConfig)
+	}
+	if c.DB.Driver != database.SQLite && c.DB.Driver != database.Postgres {
+		return fmt.Errorf("%w: database driver %q is not supported", ErrInvalidConfig, c.DB.Driver)
+	}
+	if c.Static.Dir == "" {
+		return fmt.Errorf("%w: static dir is not set, set it to a path like ./static/", ErrInvalidConfig)
+	}
+	if c.Static.Host == "" {
+		return fmt.Errorf("%w: static host is not set, set it to the URL from which the static dir can be accessed", ErrInvalidConfig)
+	}
+	if c.Static.PrivateDir == "" {
+		return fmt.Errorf("%w: private dir is not set", ErrInvalidConfig)
+	}
+	if isInside(c.Static.PrivateDir, c.Static.Dir) {
+		return fmt.Errorf("%w: private dir should not be inside the static dir, otherwise the private files are public", ErrInvalidConfig)
+	}
+	positive := []struct {
+		name  string
+		value int
+	}{
+		{"shutdown timeout", c.Server.ShutdownTimeout},
+		{"max post text length", c.Limits.MaxPostTextLength},
+		{"max comment text length", c.Limits.MaxCommentTextLength},
+		{"max message text length", c.Limits.MaxMessageTextLength},
+		{"max about length", c.Limits.MaxAboutLength},
+		{"max feed count", c.Limits.MaxFeedCount},
+	}
+	for _, setting := range positive {
+		if setting.value <= 0 {
+			return fmt.Errorf("%w: %v should be positive", ErrInvalidConfig, setting.name)
+		}
+	}
+	return nil
+}
+
+// isInside returns whether the path is dir itself or one of its descendants
+func isInside(path, dir string) bool {
+	absPath, errPath := filepath.Abs(path)
+	absDir, errDir := filepath.Abs(dir)
+	if errPath != nil || errDir != nil {
+		return false
+	}
+	rel, err := filepath.Rel(absDir, absPath)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
@@ -0,0 +1,128 @@
+package config_test
+
+import (
+	"errors"
+	"github.com/k0marov/go-socnet/core/general/config"
+	. "github.com/k0marov/go-socnet/core/helpers/test_helpers"
+	"testing"
+)
+
+func TestLoad(t *testing.T) {
+	noEnv := func(string) (string, bool) { return "", false }
+	envFrom := func(env map[string]string) config.EnvLookuper {
+		return func(key string) (string, bool) {
+			value, exists := env[key]
+			return value, exists
+		}
+	}
+	noFile := func(string) ([]byte, error) {
+		panic("unexpected call")
+	}
+	fileFrom := func(path, contents string) config.FileReader {
+		return func(name string) ([]byte, error) {
+			if name == path {
+				return []byte(contents), nil
+			}
+			panic("unexpected args")
+		}
+	}
+	staticFlags := []string{"-static-dir", "./static", "-static-host", "static.host"}
+
+	t.Run("happy case - defaults", func(t *testing.T) {
+		cfg, err := config.Load(staticFlags, noEnv, noFile)
+		AssertNoError(t, err)
+		want := config.Default()
+		want.Static = config.StaticConfig{Dir: "./static", Host: "static.host", PrivateDir: "private"}
+		Assert(t, cfg, want, "loaded config")
+	})
+	t.Run("happy case - env overrides the file and flags override env", func(t *testing.T) {
+		file := fileFrom("cfg.json", `{"server": {"port": 1111}, "db": {"dsn": "file.db"}, "static": {"dir": "/srv/static", "host": "file.host"}}`)
+		env := envFrom(map[string]string{
+			"SOCIO_CONFIG":      "cfg.json",
+			"SOCIO_PORT":        "2222",
+			"SOCIO_STATIC_HOST": "env.host",
+		})
+		cfg, err := config.Load([]string{"-port", "3333"}, env, file)
+		AssertNoError(t, err)
+		Assert(t, cfg.Server.Port, 3333, "port")
+		Assert(t, cfg.Static.Host, "env.host", "static host")
+		Assert(t, cfg.Static.Dir, "/srv/static", "static dir")
+		Assert(t, cfg.DB.DSN, "file.db", "db dsn")
+		Assert(t, cfg.DB.Driver, config.Default().DB.Driver, "db driver")
+	})
+	t.Run("happy case - config file from the flag", func(t *testing.T) {
+		file := fileFrom("flag.json", `{"limits": {"max_feed_count": 10}}`)
+		env := envFrom(map[string]string{"SOCIO_CONFIG": "env.json"})
+		cfg, err := config.Load(append([]string{"-config", "flag.json"}, staticFlags...), env, file)
+		AssertNoError(t, err)
+		Assert(t, cfg.Limits.MaxFeedCount, 10, "max feed count")
+	})
+	t.Run("error case - reading the config file throws", func(t *testing.T) {
+		readFile := func(string) ([]byte, error) {
+			return nil, RandomError()
+		}
+		_, err := config.Load([]string{"-config", "cfg.json"}, noEnv, readFile)
+		AssertSomeError(t, err)
+	})
+	t.Run("error case - config file is not valid JSON", func(t *testing.T) {
+		_, err := config.Load([]string{"-config", "cfg.json"}, noEnv, fileFrom("cfg.json", "{"))
+		AssertSomeError(t, err)
+	})
+	t.Run("error case - non-integer env var", func(t *testing.T) {
+		env := envFrom(map[string]string{"SOCIO_PORT": "abc"})
+		_, err := config.Load(staticFlags, env, noFile)
+		AssertSomeError(t, err)
+	})
+	t.Run("error case - non-integer flag", func(t *testing.T) {
+		_, err := config.Load(append([]string{"-max-feed-count", "many"}, staticFlags...), noEnv, noFile)
+		AssertSomeError(t, err)
+	})
+	t.Run("error case - unknown flag", func(t *testing.T) {
+		_, err := config.Load([]string{"-unknown", "1"}, noEnv, noFile)
+		AssertSomeError(t, err)
+	})
+	t.Run("error case - static settings are not provided", func(t *testing.T) {
+		_, err := config.Load(nil, noEnv, noFile)
+		Assert(t, errors.Is(err, config.ErrInvalidConfig), true, "returned error is ErrInvalidConfig")
+	})
+}
+
+func TestValidate(t *testing.T) {
+	valid := func() config.Config {
+		cfg := config.Default()
+		cfg.Static = config.StaticConfig{Dir: "./static", Host: "static.host", PrivateDir: "./private"}
+		return cfg
+	}
+	AssertNoError(t, valid().Validate())
+
+	cases := []struct {
+		name   string
+		modify func(*config.Config)
+	}{
+		{"port is zero", func(c *config.Config) { c.Server.Port = 0 }},
+		{"port is too big", func(c *config.Config) { c.Server.Port = 70000 }},
+		{"hash cost is too small", func(c *config.Config) { c.Auth.HashCost = 1 }},
+		{"hash cost is too big", func(c *config.Config) { c.Auth.HashCost = 100 }},
+		{"zero shutdown timeout", func(c *config.Config) { c.Server.ShutdownTimeout = 0 }},
+		{"auth store path is empty", func(c *config.Config) { c.Auth.StorePath = "" }},
+		{"unsupported db driver", func(c *config.Config) { c.DB.Driver = "mysql" }},
+		{"static dir is empty", func(c *config.Config) { c.Static.Dir = "" }},
+		{"static host is empty", func(c *config.Config) { c.Static.Host = "" }},
+		{"private dir is empty", func(c *config.Config) { c.Static.PrivateDir = "" }},
+		{"private dir is the static dir", func(c *config.Config) { c.Static.PrivateDir = "static/" }},
+		{"private dir is inside the static dir", func(c *config.Config) { c.Static.PrivateDir = "./static/private" }},
+		{"negative post text length", func(c *config.Config) { c.Limits.MaxPostTextLength = -1 }},
+		{"zero comment text length", func(c *config.Config) { c.Limits.MaxCommentTextLength = 0 }},
+		{"zero message text length", func(c *config.Config) { c.Limits.MaxMessageTextLength = 0 }},
+		{"zero about length", func(c *config.Config) { c.Limits.MaxAboutLength = 0 }},
+		{"zero feed count", func(c *config.Config) { c.Limits.MaxFeedCount = 0 }},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cfg := valid()
+			c.modify(&cfg)
+			err := cfg.Validate()
+			Assert(t, errors.Is(err, config.ErrInvalidConfig), true, "returned error is ErrInvalidConfig")
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"github.com/jmoiron/sqlx/reflectx"
+	"github.com/k0marov/go-socnet/core/general/core_err"
+	"net/url"
+	"strings"
+
+	_ "github.com/lib/pq"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// Supported drivers
+const (
+	SQLite   = "sqlite3"
+	Postgres = "postgres"
+)
+
+// sqliteMaxOpenConns is small because sqlite allows only one writer at a time anyway
+const sqliteMaxOpenConns = 4
+
+// sqliteParams are set on every sqlite connection unless the dsn sets them itself.
+// WAL lets the readers go on during a write, a writer waits for the lock for up to the busy timeout
+// instead of failing with "database is locked", and immediate transactions take the write lock at BEGIN,
+// so two transactions can't deadlock upgrading their read locks.
+// Foreign keys are enforced as on postgres; the migrations give them ON DELETE CASCADE, so deletes aren't blocked.
+var sqliteParams = [][2]string{
+	{"_journal_mode", "WAL"},
+	{"_busy_timeout", "5000"},
+	{"_txlock", "immediate"},
+	{"_foreign_keys", "1"},
+}
+
+var ErrUnsupportedDriver = errors.New("the database driver is not supported")
+var ErrNoSQLiteFTS5 = errors.New("the db has FTS5 tables, but the sqlite driver is built without FTS5, build with -tags sqlite_fts5")
+
+// Open opens a db using one of the supported drivers and prepares it to be used by the sql stores
+func Open(driver, dsn string) (*sqlx.DB, error) {
+	if driver != SQLite && driver != Postgres {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupportedDriver, driver)
+	}
+	if driver == SQLite {
+		dsn = withSQLiteParams(dsn)
+	}
+	db, err := sqlx.Open(driver, dsn)
+	if err != nil {
+		return nil, core_err.Rethrow("opening the database", err)
+	}
+	switch driver {
+	case SQLite:
+		if isSQLiteMemory(dsn) {
+			// every connection to :memory: gets its own db, and a shared cache fails concurrent writes
+			// with "database table is locked" instead of waiting, so an in-memory db uses a single connection
+			db.SetMaxOpenConns(1)
+		} else {
+			db.SetMaxOpenConns(sqliteMaxOpenConns)
+		}
+	case Postgres:
+		// postgres folds unquoted identifiers like createdAt to lower case, so they are returned as createdat
+		db.Mapper = reflectx.NewMapperTagFunc("db", strings.ToLower, strings.ToLower)
+	}
+	return db, nil
+}
+
+func withSQLiteParams(dsn string) string {
+	query := ""
+	if i := strings.IndexByte(dsn, '?'); i >= 0 {
+		query = dsn[i+1:]
+	}
+	params, _ := url.ParseQuery(query)
+	sep := "?"
+	if strings.Contains(dsn, "?") {
+		sep = "&"
+	}
+	for _, param := range sqliteParams {
+		if !params.Has(param[0]) {
+			dsn += sep + param[0] + "=" + param[1]
+			sep = "&"
+		}
+	}
+	return dsn
+}
+
+func isSQLiteMemory(dsn string) bool {
+	return strings.HasPrefix(dsn, ":memory:") || strings.HasPrefix(dsn, "file::memory:") || strings.Contains(dsn, "mode=memory")
+}
@@ -0,0 +1,58 @@
+package database_test
+
+import (
+	"errors"
+	"github.com/k0marov/go-socnet/core/general/database"
+	. "github.com/k0marov/go-socnet/core/helpers/test_helpers"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestOpen(t *testing.T) {
+	t.Run("error case - unsupported driver", func(t *testing.T) {
+		_, err := database.Open("mysql", RandomString())
+		Assert(t, errors.Is(err, database.ErrUnsupportedDriver), true, "returned error is ErrUnsupportedDriver")
+	})
+	t.Run("happy case - sqlite", func(t *testing.T) {
+		db, err := database.Open(database.SQLite, ":memory:")
+		AssertNoError(t, err)
+		defer db.Close()
+		AssertNoError(t, db.Ping())
+		if database.SQLiteFTS5 {
+			_, err = db.Exec(`CREATE VIRTUAL TABLE Search USING fts5(text)`)
+			AssertNoError(t, err)
+		}
+	})
+	t.Run("sqlite file db should use WAL and a small pool", func(t *testing.T) {
+		db, err := database.Open(database.SQLite, filepath.Join(t.TempDir(), "test.db"))
+		AssertNoError(t, err)
+		defer db.Close()
+		var journalMode string
+		AssertNoError(t, db.Get(&journalMode, "PRAGMA journal_mode"))
+		Assert(t, journalMode, "wal", "journal mode")
+		var busyTimeout int
+		AssertNoError(t, db.Get(&busyTimeout, "PRAGMA busy_timeout"))
+		Assert(t, busyTimeout > 0, true, "busy timeout is set")
+		var foreignKeys int
+		AssertNoError(t, db.Get(&foreignKeys, "PRAGMA foreign_keys"))
+		Assert(t, foreignKeys, 1, "foreign keys are enforced")
+		Assert(t, db.Stats().MaxOpenConnections > 1, true, "more than 1 connection is allowed")
+	})
+	t.Run("sqlite in-memory db should use a single connection", func(t *testing.T) {
+		db, err := database.Open(database.SQLite, "file::memory:?cache=shared")
+		AssertNoError(t, err)
+		defer db.Close()
+		Assert(t, db.Stats().MaxOpenConnections, 1, "max open connections")
+	})
+	t.Run("postgres should map the lower case column names", func(t *testing.T) {
+		db, err := database.Open(database.Postgres, "host=localhost")
+		AssertNoError(t, err)
+		defer db.Close()
+		type model struct {
+			CreatedAt int64 `db:"createdAt"`
+		}
+		_, found := db.Mapper.TypeMap(reflect.TypeOf(model{})).Names["createdat"]
+		Assert(t, found, true, "createdAt tag is mapped to createdat")
+	})
+}
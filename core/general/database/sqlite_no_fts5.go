@@ -0,0 +1,6 @@
+//go:build !sqlite_fts5
+
+package database
+
+// SQLiteFTS5 is true when go-sqlite3 is compiled with FTS5, which the SQLite search indexes need
+const SQLiteFTS5 = false
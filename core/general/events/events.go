@@ -0,0 +1,63 @@
+package events
+
+import (
+	"github.com/k0marov/go-socnet/core/general/core_values"
+	"log"
+	"time"
+)
+
+// Kind is what happened
+type Kind string
+
+const (
+	Followed  Kind = "follow"
+	Liked     Kind = "like"
+	Commented Kind = "comment"
+	Replied   Kind = "reply"
+	Mentioned Kind = "mention"
+
+	// CommentCreated and LikesChanged have no Recipient, they are used to update the clients viewing the target
+	CommentCreated Kind = "comment-created"
+	LikesChanged   Kind = "likes-changed"
+)
+
+// TargetType is the type of the entity an event happened to
+type TargetType string
+
+const (
+	Profile TargetType = "profile"
+	Post    TargetType = "post"
+	Comment TargetType = "comment"
+)
+
+// Event is a domain event of Actor doing something to a target that concerns Recipient,
+// e.g. following Recipient's profile or replying to Recipient's comment.
+// Recipient is empty for the events which don't concern a particular profile.
+type Event struct {
+	Kind       Kind
+	Actor      core_values.UserId
+	Recipient  core_values.UserId
+	TargetType TargetType
+	TargetId   string
+	// Comment is the comment created by the action, it is empty for follows and likes
+	Comment   string
+	CreatedAt time.Time
+}
+
+// Publisher delivers an event to every subscriber.
+// It doesn't return an error, since a failing subscriber shouldn't fail the action which emitted the event.
+type Publisher func(Event)
+
+// Subscriber handles the events, e.g. by turning them into notifications
+type Subscriber func(Event) error
+
+// NewPublisher returns a publisher which synchronously calls the subscribers, logging their errors
+func NewPublisher(subscribers ...Subscriber) Publisher {
+	return func(event Event) {
+		for _, handle := range subscribers {
+			if err := handle(event); err != nil {
+				log.Printf("while handling the %q event: %v", event.Kind, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package events_test
+
+import (
+	"github.com/k0marov/go-socnet/core/general/events"
+	. "github.com/k0marov/go-socnet/core/helpers/test_helpers"
+	"testing"
+	"time"
+)
+
+func TestPublisher(t *testing.T) {
+	event := events.Event{
+		Kind:       events.Liked,
+		Actor:      RandomId(),
+		Recipient:  RandomId(),
+		TargetType: events.Post,
+		TargetId:   RandomId(),
+		CreatedAt:  time.Now(),
+	}
+	t.Run("happy case", func(t *testing.T) {
+		var handled []string
+		subscriber := func(name string) events.Subscriber {
+			return func(got events.Event) error {
+				Assert(t, got, event, "handled event")
+				handled = append(handled, name)
+				return nil
+			}
+		}
+		events.NewPublisher(subscriber("first"), subscriber("second"))(event)
+		Assert(t, handled, []string{"first", "second"}, "subscribers that handled the event")
+	})
+	t.Run("a failing subscriber doesn't stop the others", func(t *testing.T) {
+		failing := func(events.Event) error {
+			return RandomError()
+		}
+		handled := false
+		succeeding := func(events.Event) error {
+			handled = true
+			return nil
+		}
+		events.NewPublisher(failing, succeeding)(event)
+		Assert(t, handled, true, "the second subscriber handled the event")
+	})
+}
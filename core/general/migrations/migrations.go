@@ -0,0 +1,191 @@
+package migrations
+
+import (
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"github.com/k0marov/go-socnet/core/general/core_err"
+	"github.com/k0marov/go-socnet/core/general/database"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Migration is a versioned change of the db schema.
+// Once a migration is released it should never be edited, a new migration should be added instead.
+type Migration struct {
+	Version int
+	Name    string
+	SQL     string
+	// Skipped migrations are recorded as applied without executing their SQL
+	Skipped bool
+}
+
+func (m Migration) Checksum() string {
+	sum := sha256.Sum256([]byte(m.SQL))
+	return hex.EncodeToString(sum[:])
+}
+
+var ErrSchemaTooNew = errors.New("the db schema is newer than the one supported by this binary")
+var ErrChecksumMismatch = errors.New("an applied migration differs from the one known to this binary")
+
+// migrationFiles has a directory of migrations for every supported database driver.
+// Migrations with the same version should make the same change in every dialect.
+//
+//go:embed sql/*/*.sql
+var migrationFiles embed.FS
+
+var dialectDirs = map[string]string{
+	database.SQLite:   "sql/sqlite",
+	database.Postgres: "sql/postgres",
+}
+
+// sqliteFTS5Migrations create FTS5 tables, so they are skipped when go-sqlite3 is built without FTS5.
+// Search falls back to LIKE queries on a db without these tables.
+var sqliteFTS5Migrations = map[string]bool{"search": true}
+
+var migrationFilename = regexp.MustCompile(`^(\d{4})_(\w+)\.sql$`)
+
+// Load reads migrations from files named like 0001_initial_schema.sql, ordering them by version.
+// Versions should start from 1 and have no gaps.
+func Load(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, core_err.Rethrow("reading the migrations directory", err)
+	}
+	var migrations []Migration
+	for _, entry := range entries {
+		match := migrationFilename.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("%v is not a valid migration filename", entry.Name())
+		}
+		version, _ := strconv.Atoi(match[1])
+		sql, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, core_err.Rethrow("reading a migration file", err)
+		}
+		migrations = append(migrations, Migration{Version: version, Name: match[2], SQL: string(sql)})
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	for i, migration := range migrations {
+		if migration.Version != i+1 {
+			return nil, fmt.Errorf("expected migration with version %d, but got %d", i+1, migration.Version)
+		}
+	}
+	return migrations, nil
+}
+
+// Migrate brings the db schema up to date with the migrations embedded into this binary
+func Migrate(db *sqlx.DB) error {
+	dir, ok := dialectDirs[db.DriverName()]
+	if !ok {
+		return fmt.Errorf("%w: %v", database.ErrUnsupportedDriver, db.DriverName())
+	}
+	migrations, err := Load(migrationFiles, dir)
+	if err != nil {
+		return core_err.Rethrow("loading the embedded migrations", err)
+	}
+	if db.DriverName() == database.SQLite && !database.SQLiteFTS5 {
+		err := skipSQLiteFTS5(db, migrations)
+		if err != nil {
+			return err
+		}
+	}
+	return Apply(db, migrations)
+}
+
+// skipSQLiteFTS5 skips the migrations creating FTS5 tables.
+// A db which already has FTS5 tables can't be used at all, since the triggers keeping them in sync would fail.
+func skipSQLiteFTS5(db *sqlx.DB, migrations []Migration) error {
+	var hasFTS5 bool
+	err := db.Get(&hasFTS5, `
+		SELECT EXISTS(SELECT 1 FROM sqlite_master WHERE type = 'table' AND sql LIKE 'CREATE VIRTUAL TABLE % USING fts5%')
+	`)
+	if err != nil {
+		return core_err.Rethrow("checking for FTS5 tables", err)
+	}
+	if hasFTS5 {
+		return database.ErrNoSQLiteFTS5
+	}
+	for i := range migrations {
+		migrations[i].Skipped = sqliteFTS5Migrations[migrations[i].Name]
+	}
+	return nil
+}
+
+type appliedMigration struct {
+	Version  int    `db:"version"`
+	Checksum string `db:"checksum"`
+}
+
+// Apply applies the migrations which are not applied yet, each one in its own transaction.
+// It refuses to touch the db if the already applied migrations do not match the provided ones.
+func Apply(db *sqlx.DB, migrations []Migration) error {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS schema_migrations(
+			version INT PRIMARY KEY, 
+			name VARCHAR(255) NOT NULL, 
+			checksum VARCHAR(64) NOT NULL, 
+			appliedAt BIGINT NOT NULL
+		)
+	`)
+	if err != nil {
+		return core_err.Rethrow("creating schema_migrations table", err)
+	}
+	var applied []appliedMigration
+	err = db.Select(&applied, `
+		SELECT version, checksum FROM schema_migrations ORDER BY version
+	`)
+	if err != nil {
+		return core_err.Rethrow("getting the applied migrations", err)
+	}
+	for i, a := range applied {
+		if a.Version != i+1 {
+			return fmt.Errorf("the applied migrations are not contiguous: expected version %d, but got %d", i+1, a.Version)
+		}
+		if a.Version > len(migrations) {
+			return fmt.Errorf("the db has migration %d applied, but the latest known one is %d: %w", a.Version, len(migrations), ErrSchemaTooNew)
+		}
+		if migrations[a.Version-1].Checksum() != a.Checksum {
+			return fmt.Errorf("migration %d: %w", a.Version, ErrChecksumMismatch)
+		}
+	}
+	for _, migration := range migrations[len(applied):] {
+		err := apply(db, migration)
+		if err != nil {
+			return fmt.Errorf("while applying migration %04d_%s: %w", migration.Version, migration.Name, err)
+		}
+	}
+	return nil
+}
+
+func apply(db *sqlx.DB, migration Migration) error {
+	tx, err := db.Beginx()
+	if err != nil {
+		return core_err.Rethrow("beginning a transaction", err)
+	}
+	defer tx.Rollback()
+	if !migration.Skipped {
+		_, err = tx.Exec(migration.SQL)
+		if err != nil {
+			return core_err.Rethrow("executing the migration", err)
+		}
+	}
+	_, err = tx.Exec(tx.Rebind(`
+		INSERT INTO schema_migrations(version, name, checksum, appliedAt) VALUES (?, ?, ?, ?)
+	`), migration.Version, migration.Name, migration.Checksum(), time.Now().Unix())
+	if err != nil {
+		return core_err.Rethrow("recording the applied migration", err)
+	}
+	err = tx.Commit()
+	if err != nil {
+		return core_err.Rethrow("committing the migration", err)
+	}
+	return nil
+}
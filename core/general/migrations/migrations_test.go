@@ -0,0 +1,221 @@
+package migrations_test
+
+import (
+	"errors"
+	"github.com/jmoiron/sqlx"
+	"github.com/k0marov/go-socnet/core/general/database"
+	"github.com/k0marov/go-socnet/core/general/migrations"
+	. "github.com/k0marov/go-socnet/core/helpers/test_helpers"
+	_ "github.com/mattn/go-sqlite3"
+	"os"
+	"strconv"
+	"testing"
+	"testing/fstest"
+)
+
+func openEmptyDB(t testing.TB) *sqlx.DB {
+	t.Helper()
+	db, err := sqlx.Open("sqlite3", ":memory:")
+	AssertNoError(t, err)
+	db.SetMaxOpenConns(1) // every connection to ":memory:" opens a new db
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func tableExists(t testing.TB, db *sqlx.DB, table string) bool {
+	t.Helper()
+	var exists bool
+	err := db.Get(&exists, `SELECT EXISTS(SELECT 1 FROM sqlite_master WHERE type = 'table' AND name = ?)`, table)
+	AssertNoError(t, err)
+	return exists
+}
+
+func TestLoad(t *testing.T) {
+	t.Run("happy case", func(t *testing.T) {
+		fsys := fstest.MapFS{
+			"sql/0002_second.sql": {Data: []byte("second")},
+			"sql/0001_first.sql":  {Data: []byte("first")},
+		}
+		got, err := migrations.Load(fsys, "sql")
+		AssertNoError(t, err)
+		want := []migrations.Migration{
+			{Version: 1, Name: "first", SQL: "first"},
+			{Version: 2, Name: "second", SQL: "second"},
+		}
+		Assert(t, got, want, "loaded migrations")
+	})
+	t.Run("error case - invalid filename", func(t *testing.T) {
+		fsys := fstest.MapFS{"sql/first.sql": {Data: []byte("first")}}
+		_, err := migrations.Load(fsys, "sql")
+		AssertSomeError(t, err)
+	})
+	t.Run("error case - a gap between versions", func(t *testing.T) {
+		fsys := fstest.MapFS{
+			"sql/0001_first.sql": {Data: []byte("first")},
+			"sql/0003_third.sql": {Data: []byte("third")},
+		}
+		_, err := migrations.Load(fsys, "sql")
+		AssertSomeError(t, err)
+	})
+}
+
+func TestDialectsAreInSync(t *testing.T) {
+	sqlite, err := migrations.Load(os.DirFS("sql"), "sqlite")
+	AssertNoError(t, err)
+	postgres, err := migrations.Load(os.DirFS("sql"), "postgres")
+	AssertNoError(t, err)
+	AssertFatal(t, len(postgres), len(sqlite), "number of postgres migrations")
+	for i := range sqlite {
+		Assert(t, postgres[i].Name, sqlite[i].Name, "name of migration "+strconv.Itoa(sqlite[i].Version))
+	}
+}
+
+func TestApply(t *testing.T) {
+	first := migrations.Migration{Version: 1, Name: "first", SQL: `CREATE TABLE First(id INTEGER PRIMARY KEY);`}
+	second := migrations.Migration{Version: 2, Name: "second", SQL: `CREATE TABLE Second(id INTEGER PRIMARY KEY);`}
+
+	t.Run("applying only the new migrations", func(t *testing.T) {
+		db := openEmptyDB(t)
+		err := migrations.Apply(db, []migrations.Migration{first})
+		AssertNoError(t, err)
+		Assert(t, tableExists(t, db, "First"), true, "first table exists")
+		Assert(t, tableExists(t, db, "Second"), false, "second table exists")
+
+		err = migrations.Apply(db, []migrations.Migration{first, second})
+		AssertNoError(t, err)
+		Assert(t, tableExists(t, db, "Second"), true, "second table exists")
+
+		// applying again is a no-op
+		err = migrations.Apply(db, []migrations.Migration{first, second})
+		AssertNoError(t, err)
+	})
+	t.Run("error case - the schema is newer than the binary", func(t *testing.T) {
+		db := openEmptyDB(t)
+		err := migrations.Apply(db, []migrations.Migration{first, second})
+		AssertNoError(t, err)
+		err = migrations.Apply(db, []migrations.Migration{first})
+		Assert(t, errors.Is(err, migrations.ErrSchemaTooNew), true, "returned error is ErrSchemaTooNew")
+	})
+	t.Run("error case - an applied migration was changed", func(t *testing.T) {
+		db := openEmptyDB(t)
+		err := migrations.Apply(db, []migrations.Migration{first})
+		AssertNoError(t, err)
+		changed := first
+		changed.SQL += "\nCREATE TABLE Changed(id INTEGER PRIMARY KEY);"
+		err = migrations.Apply(db, []migrations.Migration{changed, second})
+		Assert(t, errors.Is(err, migrations.ErrChecksumMismatch), true, "returned error is ErrChecksumMismatch")
+		Assert(t, tableExists(t, db, "Second"), false, "second table exists")
+	})
+	t.Run("skipped migrations are recorded without executing them", func(t *testing.T) {
+		db := openEmptyDB(t)
+		skipped := second
+		skipped.Skipped = true
+		err := migrations.Apply(db, []migrations.Migration{first, skipped})
+		AssertNoError(t, err)
+		Assert(t, tableExists(t, db, "Second"), false, "table of the skipped migration exists")
+
+		// the checksum of a skipped migration is the same, so it isn't applied again
+		err = migrations.Apply(db, []migrations.Migration{first, second})
+		AssertNoError(t, err)
+		Assert(t, tableExists(t, db, "Second"), false, "table of the skipped migration exists")
+	})
+	t.Run("error case - a failing migration is rolled back", func(t *testing.T) {
+		db := openEmptyDB(t)
+		failing := migrations.Migration{Version: 1, Name: "failing", SQL: `CREATE TABLE First(id INTEGER PRIMARY KEY); NOT SQL;`}
+		err := migrations.Apply(db, []migrations.Migration{failing})
+		AssertSomeError(t, err)
+		Assert(t, tableExists(t, db, "First"), false, "table of the failed migration exists")
+
+		err = migrations.Apply(db, []migrations.Migration{first})
+		AssertNoError(t, err)
+	})
+}
+
+func TestMigrate(t *testing.T) {
+	db := openEmptyDB(t)
+	err := migrations.Migrate(db)
+	AssertNoError(t, err)
+	for _, table := range []string{"Profile", "Post", "PostImage", "Comment", "LikeableProfile", "LikeablePost", "LikeableComment", "PostRecommendation"} {
+		Assert(t, tableExists(t, db, table), true, table+" table exists")
+	}
+	// the search migration creates FTS5 tables only when they are available
+	Assert(t, tableExists(t, db, "PostSearch"), database.SQLiteFTS5, "PostSearch table exists")
+	err = migrations.Migrate(db)
+	AssertNoError(t, err)
+}
+
+func TestMigrate_ForeignKeysCascade(t *testing.T) {
+	db := openEmptyDB(t)
+	AssertNoError(t, migrations.Migrate(db))
+	var foreignKeys []struct {
+		Table    string `db:"name"`
+		Column   string `db:"from"`
+		OnDelete string `db:"on_delete"`
+	}
+	err := db.Select(&foreignKeys, `
+		SELECT m.name, f."from", f.on_delete FROM sqlite_master m JOIN pragma_foreign_key_list(m.name) f
+		WHERE m.type = 'table'
+	`)
+	AssertNoError(t, err)
+	for _, fk := range foreignKeys {
+		// a comment with replies is never deleted, it is replaced with a tombstone
+		if fk.Table == "Comment" && fk.Column == "parent_id" {
+			continue
+		}
+		Assert(t, fk.OnDelete, "CASCADE", "ON DELETE action of "+fk.Table+"."+fk.Column)
+	}
+}
+
+func TestCascadeLikes(t *testing.T) {
+	db := openEmptyDB(t)
+	all, err := migrations.Load(os.DirFS("sql"), "sqlite")
+	AssertNoError(t, err)
+	cascade := 0
+	for i := range all {
+		all[i].Skipped = all[i].Name == "search" && !database.SQLiteFTS5
+		if all[i].Name == "cascade_likes" {
+			cascade = i
+		}
+	}
+	AssertNoError(t, migrations.Apply(db, all[:cascade]))
+	_, err = db.Exec(`
+		INSERT INTO Profile(id, username, about, avatarPath) VALUES (1, 'author', '', ''), (2, 'liker', '', '');
+		INSERT INTO Post(id, owner_id, textContent, createdAt) VALUES (1, 1, '', 0);
+		INSERT INTO LikeablePost(id, target_id, liker_id, created_at, reaction) VALUES (5, 1, 2, 42, 'laugh');
+		INSERT INTO LikeablePostCount(counter, id, likes) VALUES ('target', '1', 1), ('liker', '2', 1), ('reaction:laugh', '1', 1);
+	`)
+	AssertNoError(t, err)
+
+	AssertNoError(t, migrations.Apply(db, all))
+
+	var like struct {
+		Id        int    `db:"id"`
+		CreatedAt int64  `db:"created_at"`
+		Reaction  string `db:"reaction"`
+	}
+	err = db.Get(&like, `SELECT id, created_at, reaction FROM LikeablePost WHERE target_id = 1 AND liker_id = 2`)
+	AssertNoError(t, err)
+	Assert(t, like.Id, 5, "id of the like")
+	Assert(t, like.CreatedAt, int64(42), "creation time of the like")
+	Assert(t, like.Reaction, "laugh", "reaction of the like")
+
+	_, err = db.Exec(`PRAGMA foreign_keys = ON`)
+	AssertNoError(t, err)
+	_, err = db.Exec(`DELETE FROM Post WHERE id = 1`)
+	AssertNoError(t, err)
+	var likes int
+	err = db.Get(&likes, `SELECT COUNT(*) FROM LikeablePost`)
+	AssertNoError(t, err)
+	Assert(t, likes, 0, "number of likes after the post was deleted")
+	rows, err := db.Queryx(`SELECT counter || ':' || id, likes FROM LikeablePostCount`)
+	AssertNoError(t, err)
+	defer rows.Close()
+	counters := map[string]int{}
+	for rows.Next() {
+		var counter string
+		var count int
+		AssertNoError(t, rows.Scan(&counter, &count))
+		counters[counter] = count
+	}
+	Assert(t, counters, map[string]int{"liker:2": 0}, "likes counters after the post was deleted")
+}
@@ -0,0 +1,63 @@
+CREATE TABLE IF NOT EXISTS Profile(
+	id INTEGER PRIMARY KEY,
+	username VARCHAR(255) NOT NULL,
+	about TEXT NOT NULL,
+	avatarPath VARCHAR(255) NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS Post(
+	id SERIAL PRIMARY KEY,
+	owner_id INT NOT NULL,
+	textContent TEXT NOT NULL,
+	createdAt BIGINT NOT NULL,
+	FOREIGN KEY(owner_id) REFERENCES Profile(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS PostImage(
+	post_id INT NOT NULL,
+	path VARCHAR(255),
+	ind INT,
+	FOREIGN KEY(post_id) REFERENCES Post(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS Comment(
+	id SERIAL PRIMARY KEY,
+	post_id INT NOT NULL,
+	owner_id INT NOT NULL,
+	textContent TEXT NOT NULL,
+	createdAt BIGINT NOT NULL,
+	FOREIGN KEY(post_id) REFERENCES Post(id) ON DELETE CASCADE,
+	FOREIGN KEY(owner_id) REFERENCES Profile(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS LikeableProfile(
+	target_id INT NOT NULL,
+	liker_id INT NOT NULL,
+	FOREIGN KEY(target_id) REFERENCES Profile(id),
+	FOREIGN KEY(liker_id) REFERENCES Profile(id)
+);
+CREATE INDEX IF NOT EXISTS LikeableProfileIndex ON LikeableProfile (target_id, liker_id);
+
+CREATE TABLE IF NOT EXISTS LikeablePost(
+	target_id INT NOT NULL,
+	liker_id INT NOT NULL,
+	FOREIGN KEY(target_id) REFERENCES Post(id),
+	FOREIGN KEY(liker_id) REFERENCES Profile(id)
+);
+CREATE INDEX IF NOT EXISTS LikeablePostIndex ON LikeablePost (target_id, liker_id);
+
+CREATE TABLE IF NOT EXISTS LikeableComment(
+	target_id INT NOT NULL,
+	liker_id INT NOT NULL,
+	FOREIGN KEY(target_id) REFERENCES Comment(id),
+	FOREIGN KEY(liker_id) REFERENCES Profile(id)
+);
+CREATE INDEX IF NOT EXISTS LikeableCommentIndex ON LikeableComment (target_id, liker_id);
+
+CREATE TABLE IF NOT EXISTS PostRecommendation(
+	recommendation_id INT NOT NULL,
+	user_id INT NOT NULL,
+	FOREIGN KEY(recommendation_id) REFERENCES Post(id),
+	FOREIGN KEY(user_id) REFERENCES Profile(id)
+);
+CREATE INDEX IF NOT EXISTS PostRecommendationIndex ON PostRecommendation (user_id);
@@ -0,0 +1,2 @@
+ALTER TABLE Post ADD COLUMN editedAt BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE Comment ADD COLUMN editedAt BIGINT NOT NULL DEFAULT 0;
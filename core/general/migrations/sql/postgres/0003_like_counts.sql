@@ -0,0 +1,35 @@
+-- Likeable<Target>Count keeps the number of likes received by every target (counter = 'target')
+-- and the number of targets liked by every user (counter = 'liker').
+-- It is updated in the same transaction as the Likeable<Target> rows and can be rebuilt from them at any time.
+CREATE TABLE LikeableProfileCount(
+	counter VARCHAR(16) NOT NULL,
+	id VARCHAR(255) NOT NULL,
+	likes INT NOT NULL,
+	PRIMARY KEY(counter, id)
+);
+INSERT INTO LikeableProfileCount(counter, id, likes)
+	SELECT 'target', CAST(target_id AS VARCHAR(255)), COUNT(*) FROM LikeableProfile GROUP BY target_id;
+INSERT INTO LikeableProfileCount(counter, id, likes)
+	SELECT 'liker', CAST(liker_id AS VARCHAR(255)), COUNT(*) FROM LikeableProfile GROUP BY liker_id;
+
+CREATE TABLE LikeablePostCount(
+	counter VARCHAR(16) NOT NULL,
+	id VARCHAR(255) NOT NULL,
+	likes INT NOT NULL,
+	PRIMARY KEY(counter, id)
+);
+INSERT INTO LikeablePostCount(counter, id, likes)
+	SELECT 'target', CAST(target_id AS VARCHAR(255)), COUNT(*) FROM LikeablePost GROUP BY target_id;
+INSERT INTO LikeablePostCount(counter, id, likes)
+	SELECT 'liker', CAST(liker_id AS VARCHAR(255)), COUNT(*) FROM LikeablePost GROUP BY liker_id;
+
+CREATE TABLE LikeableCommentCount(
+	counter VARCHAR(16) NOT NULL,
+	id VARCHAR(255) NOT NULL,
+	likes INT NOT NULL,
+	PRIMARY KEY(counter, id)
+);
+INSERT INTO LikeableCommentCount(counter, id, likes)
+	SELECT 'target', CAST(target_id AS VARCHAR(255)), COUNT(*) FROM LikeableComment GROUP BY target_id;
+INSERT INTO LikeableCommentCount(counter, id, likes)
+	SELECT 'liker', CAST(liker_id AS VARCHAR(255)), COUNT(*) FROM LikeableComment GROUP BY liker_id;
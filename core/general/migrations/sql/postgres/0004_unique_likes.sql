@@ -0,0 +1,28 @@
+-- Duplicate likes could be inserted by concurrent toggles, so they are removed before adding the unique index
+-- and the likes counters are rebuilt afterwards.
+DELETE FROM LikeableProfile a USING LikeableProfile b WHERE a.target_id = b.target_id AND a.liker_id = b.liker_id AND a.ctid > b.ctid;
+DROP INDEX IF EXISTS LikeableProfileIndex;
+CREATE UNIQUE INDEX LikeableProfileUnique ON LikeableProfile(target_id, liker_id);
+DELETE FROM LikeableProfileCount;
+INSERT INTO LikeableProfileCount(counter, id, likes)
+	SELECT 'target', CAST(target_id AS VARCHAR(255)), COUNT(*) FROM LikeableProfile GROUP BY target_id;
+INSERT INTO LikeableProfileCount(counter, id, likes)
+	SELECT 'liker', CAST(liker_id AS VARCHAR(255)), COUNT(*) FROM LikeableProfile GROUP BY liker_id;
+
+DELETE FROM LikeablePost a USING LikeablePost b WHERE a.target_id = b.target_id AND a.liker_id = b.liker_id AND a.ctid > b.ctid;
+DROP INDEX IF EXISTS LikeablePostIndex;
+CREATE UNIQUE INDEX LikeablePostUnique ON LikeablePost(target_id, liker_id);
+DELETE FROM LikeablePostCount;
+INSERT INTO LikeablePostCount(counter, id, likes)
+	SELECT 'target', CAST(target_id AS VARCHAR(255)), COUNT(*) FROM LikeablePost GROUP BY target_id;
+INSERT INTO LikeablePostCount(counter, id, likes)
+	SELECT 'liker', CAST(liker_id AS VARCHAR(255)), COUNT(*) FROM LikeablePost GROUP BY liker_id;
+
+DELETE FROM LikeableComment a USING LikeableComment b WHERE a.target_id = b.target_id AND a.liker_id = b.liker_id AND a.ctid > b.ctid;
+DROP INDEX IF EXISTS LikeableCommentIndex;
+CREATE UNIQUE INDEX LikeableCommentUnique ON LikeableComment(target_id, liker_id);
+DELETE FROM LikeableCommentCount;
+INSERT INTO LikeableCommentCount(counter, id, likes)
+	SELECT 'target', CAST(target_id AS VARCHAR(255)), COUNT(*) FROM LikeableComment GROUP BY target_id;
+INSERT INTO LikeableCommentCount(counter, id, likes)
+	SELECT 'liker', CAST(liker_id AS VARCHAR(255)), COUNT(*) FROM LikeableComment GROUP BY liker_id;
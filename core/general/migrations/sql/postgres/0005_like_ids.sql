@@ -0,0 +1,4 @@
+-- Likes get an id which orders them by recency, so that the likers of a target can be listed newest first.
+ALTER TABLE LikeableProfile ADD COLUMN id SERIAL PRIMARY KEY;
+ALTER TABLE LikeablePost ADD COLUMN id SERIAL PRIMARY KEY;
+ALTER TABLE LikeableComment ADD COLUMN id SERIAL PRIMARY KEY;
@@ -0,0 +1,12 @@
+-- Likes created before this migration have an unknown creation time, so they are ordered only by id.
+ALTER TABLE LikeableProfile ADD COLUMN created_at BIGINT NOT NULL DEFAULT 0;
+CREATE INDEX LikeableProfileTargetRecency ON LikeableProfile(target_id, created_at, id);
+CREATE INDEX LikeableProfileLikerRecency ON LikeableProfile(liker_id, created_at, id);
+
+ALTER TABLE LikeablePost ADD COLUMN created_at BIGINT NOT NULL DEFAULT 0;
+CREATE INDEX LikeablePostTargetRecency ON LikeablePost(target_id, created_at, id);
+CREATE INDEX LikeablePostLikerRecency ON LikeablePost(liker_id, created_at, id);
+
+ALTER TABLE LikeableComment ADD COLUMN created_at BIGINT NOT NULL DEFAULT 0;
+CREATE INDEX LikeableCommentTargetRecency ON LikeableComment(target_id, created_at, id);
+CREATE INDEX LikeableCommentLikerRecency ON LikeableComment(liker_id, created_at, id);
@@ -0,0 +1,13 @@
+-- Every like gets a kind of reaction, the existing likes become plain likes.
+-- Likeable<Target>Count also keeps the number of reactions of every kind received by a target (counter = 'reaction:<kind>').
+ALTER TABLE LikeableProfile ADD COLUMN reaction VARCHAR(16) NOT NULL DEFAULT 'like';
+INSERT INTO LikeableProfileCount(counter, id, likes)
+	SELECT 'reaction:' || reaction, CAST(target_id AS VARCHAR(255)), COUNT(*) FROM LikeableProfile GROUP BY target_id, reaction;
+
+ALTER TABLE LikeablePost ADD COLUMN reaction VARCHAR(16) NOT NULL DEFAULT 'like';
+INSERT INTO LikeablePostCount(counter, id, likes)
+	SELECT 'reaction:' || reaction, CAST(target_id AS VARCHAR(255)), COUNT(*) FROM LikeablePost GROUP BY target_id, reaction;
+
+ALTER TABLE LikeableComment ADD COLUMN reaction VARCHAR(16) NOT NULL DEFAULT 'like';
+INSERT INTO LikeableCommentCount(counter, id, likes)
+	SELECT 'reaction:' || reaction, CAST(target_id AS VARCHAR(255)), COUNT(*) FROM LikeableComment GROUP BY target_id, reaction;
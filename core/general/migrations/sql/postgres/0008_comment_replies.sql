@@ -0,0 +1,6 @@
+-- A comment can be a reply to another comment of the same post.
+-- A deleted comment which has replies is kept as a tombstone with an empty text and a non-zero deletedAt.
+ALTER TABLE Comment ADD COLUMN parent_id INT REFERENCES Comment(id);
+ALTER TABLE Comment ADD COLUMN deletedAt BIGINT NOT NULL DEFAULT 0;
+CREATE INDEX CommentParentRecency ON Comment(parent_id, createdAt, id);
+CREATE INDEX CommentPostRecency ON Comment(post_id, createdAt, id);
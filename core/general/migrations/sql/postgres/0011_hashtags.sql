@@ -0,0 +1,24 @@
+-- Hashtags of posts. Tag names are stored normalized: lowercase and without the "#".
+CREATE TABLE Tag(
+	id SERIAL PRIMARY KEY,
+	name VARCHAR(64) NOT NULL UNIQUE
+);
+
+-- usedAt is the time the tag was added to the post, it is used for computing trending tags
+CREATE TABLE PostTag(
+	post_id INT NOT NULL,
+	tag_id INT NOT NULL,
+	usedAt BIGINT NOT NULL,
+	PRIMARY KEY(post_id, tag_id),
+	FOREIGN KEY(post_id) REFERENCES Post(id) ON DELETE CASCADE,
+	FOREIGN KEY(tag_id) REFERENCES Tag(id) ON DELETE CASCADE
+);
+CREATE INDEX PostTagByTag ON PostTag(tag_id);
+CREATE INDEX PostTagByUsedAt ON PostTag(usedAt);
+
+-- TrendingTag is periodically recomputed from the uses of tags inside a sliding window
+CREATE TABLE TrendingTag(
+	tag_id INT PRIMARY KEY,
+	uses INT NOT NULL,
+	FOREIGN KEY(tag_id) REFERENCES Tag(id) ON DELETE CASCADE
+);
@@ -0,0 +1,6 @@
+-- Full-text search indexes of posts, comments and profiles.
+-- The "simple" configuration is used since the texts can be in any language.
+-- The expressions should be the same as the ones used by the search queries, otherwise the indexes aren't used.
+CREATE INDEX PostSearch ON Post USING GIN (to_tsvector('simple', textContent));
+CREATE INDEX CommentSearch ON Comment USING GIN (to_tsvector('simple', textContent));
+CREATE INDEX ProfileSearch ON Profile USING GIN (to_tsvector('simple', username || ' ' || about));
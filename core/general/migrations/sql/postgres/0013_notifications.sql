@@ -0,0 +1,28 @@
+-- Notifications of recipients about the actions of other profiles.
+-- Likes and follows of the same target are grouped into one notification while it is unread,
+-- so its lastActor_id and updatedAt are of the latest action and the other actors are in NotificationActor.
+-- target_id isn't a foreign key, since the target can be a profile, a post or a comment.
+CREATE TABLE Notification(
+	id SERIAL PRIMARY KEY,
+	recipient_id INT NOT NULL,
+	kind VARCHAR(16) NOT NULL,
+	targetType VARCHAR(16) NOT NULL,
+	target_id INT NOT NULL,
+	comment_id INT,
+	lastActor_id INT NOT NULL,
+	createdAt BIGINT NOT NULL,
+	updatedAt BIGINT NOT NULL,
+	readAt BIGINT NOT NULL DEFAULT 0,
+	FOREIGN KEY(recipient_id) REFERENCES Profile(id) ON DELETE CASCADE,
+	FOREIGN KEY(comment_id) REFERENCES Comment(id) ON DELETE CASCADE,
+	FOREIGN KEY(lastActor_id) REFERENCES Profile(id) ON DELETE CASCADE
+);
+CREATE INDEX NotificationByRecipient ON Notification(recipient_id, updatedAt);
+
+CREATE TABLE NotificationActor(
+	notification_id INT NOT NULL,
+	actor_id INT NOT NULL,
+	PRIMARY KEY(notification_id, actor_id),
+	FOREIGN KEY(notification_id) REFERENCES Notification(id) ON DELETE CASCADE,
+	FOREIGN KEY(actor_id) REFERENCES Profile(id) ON DELETE CASCADE
+);
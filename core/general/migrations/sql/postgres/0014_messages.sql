@@ -0,0 +1,35 @@
+-- One-to-one conversations between profiles.
+-- The participants are ordered by values.NewParticipants, so that every pair of profiles has at most one conversation.
+-- lastMessageAt is 0 until the first message is sent.
+CREATE TABLE Conversation(
+	id SERIAL PRIMARY KEY,
+	firstProfile_id INT NOT NULL,
+	secondProfile_id INT NOT NULL,
+	createdAt BIGINT NOT NULL,
+	lastMessageAt BIGINT NOT NULL DEFAULT 0,
+	UNIQUE(firstProfile_id, secondProfile_id),
+	FOREIGN KEY(firstProfile_id) REFERENCES Profile(id) ON DELETE CASCADE,
+	FOREIGN KEY(secondProfile_id) REFERENCES Profile(id) ON DELETE CASCADE
+);
+CREATE INDEX ConversationBySecondProfile ON Conversation(secondProfile_id, lastMessageAt);
+
+-- readAt is the time when the other participant has read the message, 0 while it is unread.
+CREATE TABLE Message(
+	id SERIAL PRIMARY KEY,
+	conversation_id INT NOT NULL,
+	sender_id INT NOT NULL,
+	textContent TEXT NOT NULL,
+	createdAt BIGINT NOT NULL,
+	readAt BIGINT NOT NULL DEFAULT 0,
+	FOREIGN KEY(conversation_id) REFERENCES Conversation(id) ON DELETE CASCADE,
+	FOREIGN KEY(sender_id) REFERENCES Profile(id) ON DELETE CASCADE
+);
+CREATE INDEX MessageByConversation ON Message(conversation_id, createdAt);
+
+CREATE TABLE MessageImage(
+	message_id INT NOT NULL,
+	path VARCHAR(255) NOT NULL,
+	ind INT NOT NULL,
+	PRIMARY KEY(message_id, ind),
+	FOREIGN KEY(message_id) REFERENCES Message(id) ON DELETE CASCADE
+);
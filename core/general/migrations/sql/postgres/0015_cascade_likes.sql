@@ -0,0 +1,49 @@
+-- The likes of a deleted target or liker are deleted together with it.
+-- Before a target is deleted, its counters are deleted and the counters of its likers are decremented.
+ALTER TABLE LikeableProfile
+	DROP CONSTRAINT likeableprofile_target_id_fkey,
+	DROP CONSTRAINT likeableprofile_liker_id_fkey,
+	ADD CONSTRAINT likeableprofile_target_id_fkey FOREIGN KEY(target_id) REFERENCES Profile(id) ON DELETE CASCADE,
+	ADD CONSTRAINT likeableprofile_liker_id_fkey FOREIGN KEY(liker_id) REFERENCES Profile(id) ON DELETE CASCADE;
+
+ALTER TABLE LikeablePost
+	DROP CONSTRAINT likeablepost_target_id_fkey,
+	DROP CONSTRAINT likeablepost_liker_id_fkey,
+	ADD CONSTRAINT likeablepost_target_id_fkey FOREIGN KEY(target_id) REFERENCES Post(id) ON DELETE CASCADE,
+	ADD CONSTRAINT likeablepost_liker_id_fkey FOREIGN KEY(liker_id) REFERENCES Profile(id) ON DELETE CASCADE;
+
+ALTER TABLE LikeableComment
+	DROP CONSTRAINT likeablecomment_target_id_fkey,
+	DROP CONSTRAINT likeablecomment_liker_id_fkey,
+	ADD CONSTRAINT likeablecomment_target_id_fkey FOREIGN KEY(target_id) REFERENCES Comment(id) ON DELETE CASCADE,
+	ADD CONSTRAINT likeablecomment_liker_id_fkey FOREIGN KEY(liker_id) REFERENCES Profile(id) ON DELETE CASCADE;
+
+CREATE FUNCTION likeable_profile_cascade() RETURNS TRIGGER AS $$
+BEGIN
+	UPDATE LikeableProfileCount SET likes = likes - 1
+		WHERE counter = 'liker' AND id IN (SELECT CAST(liker_id AS VARCHAR(255)) FROM LikeableProfile WHERE target_id = OLD.id);
+	DELETE FROM LikeableProfileCount WHERE counter <> 'liker' AND id = CAST(OLD.id AS VARCHAR(255));
+	RETURN OLD;
+END;
+$$ LANGUAGE plpgsql;
+CREATE TRIGGER LikeableProfileCascade BEFORE DELETE ON Profile FOR EACH ROW EXECUTE FUNCTION likeable_profile_cascade();
+
+CREATE FUNCTION likeable_post_cascade() RETURNS TRIGGER AS $$
+BEGIN
+	UPDATE LikeablePostCount SET likes = likes - 1
+		WHERE counter = 'liker' AND id IN (SELECT CAST(liker_id AS VARCHAR(255)) FROM LikeablePost WHERE target_id = OLD.id);
+	DELETE FROM LikeablePostCount WHERE counter <> 'liker' AND id = CAST(OLD.id AS VARCHAR(255));
+	RETURN OLD;
+END;
+$$ LANGUAGE plpgsql;
+CREATE TRIGGER LikeablePostCascade BEFORE DELETE ON Post FOR EACH ROW EXECUTE FUNCTION likeable_post_cascade();
+
+CREATE FUNCTION likeable_comment_cascade() RETURNS TRIGGER AS $$
+BEGIN
+	UPDATE LikeableCommentCount SET likes = likes - 1
+		WHERE counter = 'liker' AND id IN (SELECT CAST(liker_id AS VARCHAR(255)) FROM LikeableComment WHERE target_id = OLD.id);
+	DELETE FROM LikeableCommentCount WHERE counter <> 'liker' AND id = CAST(OLD.id AS VARCHAR(255));
+	RETURN OLD;
+END;
+$$ LANGUAGE plpgsql;
+CREATE TRIGGER LikeableCommentCascade BEFORE DELETE ON Comment FOR EACH ROW EXECUTE FUNCTION likeable_comment_cascade();
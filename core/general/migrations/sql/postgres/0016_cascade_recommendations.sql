@@ -0,0 +1,6 @@
+-- The recommendations of a deleted post or user are deleted together with it.
+ALTER TABLE PostRecommendation
+	DROP CONSTRAINT postrecommendation_recommendation_id_fkey,
+	DROP CONSTRAINT postrecommendation_user_id_fkey,
+	ADD CONSTRAINT postrecommendation_recommendation_id_fkey FOREIGN KEY(recommendation_id) REFERENCES Post(id) ON DELETE CASCADE,
+	ADD CONSTRAINT postrecommendation_user_id_fkey FOREIGN KEY(user_id) REFERENCES Profile(id) ON DELETE CASCADE;
@@ -0,0 +1,28 @@
+-- Duplicate likes could be inserted by concurrent toggles, so they are removed before adding the unique index
+-- and the likes counters are rebuilt afterwards.
+DELETE FROM LikeableProfile WHERE rowid NOT IN (SELECT MIN(rowid) FROM LikeableProfile GROUP BY target_id, liker_id);
+DROP INDEX IF EXISTS LikeableProfileIndex;
+CREATE UNIQUE INDEX LikeableProfileUnique ON LikeableProfile(target_id, liker_id);
+DELETE FROM LikeableProfileCount;
+INSERT INTO LikeableProfileCount(counter, id, likes)
+	SELECT 'target', CAST(target_id AS VARCHAR(255)), COUNT(*) FROM LikeableProfile GROUP BY target_id;
+INSERT INTO LikeableProfileCount(counter, id, likes)
+	SELECT 'liker', CAST(liker_id AS VARCHAR(255)), COUNT(*) FROM LikeableProfile GROUP BY liker_id;
+
+DELETE FROM LikeablePost WHERE rowid NOT IN (SELECT MIN(rowid) FROM LikeablePost GROUP BY target_id, liker_id);
+DROP INDEX IF EXISTS LikeablePostIndex;
+CREATE UNIQUE INDEX LikeablePostUnique ON LikeablePost(target_id, liker_id);
+DELETE FROM LikeablePostCount;
+INSERT INTO LikeablePostCount(counter, id, likes)
+	SELECT 'target', CAST(target_id AS VARCHAR(255)), COUNT(*) FROM LikeablePost GROUP BY target_id;
+INSERT INTO LikeablePostCount(counter, id, likes)
+	SELECT 'liker', CAST(liker_id AS VARCHAR(255)), COUNT(*) FROM LikeablePost GROUP BY liker_id;
+
+DELETE FROM LikeableComment WHERE rowid NOT IN (SELECT MIN(rowid) FROM LikeableComment GROUP BY target_id, liker_id);
+DROP INDEX IF EXISTS LikeableCommentIndex;
+CREATE UNIQUE INDEX LikeableCommentUnique ON LikeableComment(target_id, liker_id);
+DELETE FROM LikeableCommentCount;
+INSERT INTO LikeableCommentCount(counter, id, likes)
+	SELECT 'target', CAST(target_id AS VARCHAR(255)), COUNT(*) FROM LikeableComment GROUP BY target_id;
+INSERT INTO LikeableCommentCount(counter, id, likes)
+	SELECT 'liker', CAST(liker_id AS VARCHAR(255)), COUNT(*) FROM LikeableComment GROUP BY liker_id;
@@ -0,0 +1,37 @@
+-- Likes get an id which orders them by recency, so that the likers of a target can be listed newest first.
+-- SQLite can't add a primary key to an existing table, so the tables are recreated.
+CREATE TABLE LikeableProfileNew(
+	id INTEGER PRIMARY KEY,
+	target_id INT NOT NULL,
+	liker_id INT NOT NULL,
+	FOREIGN KEY(target_id) REFERENCES Profile(id),
+	FOREIGN KEY(liker_id) REFERENCES Profile(id)
+);
+INSERT INTO LikeableProfileNew(target_id, liker_id) SELECT target_id, liker_id FROM LikeableProfile ORDER BY rowid;
+DROP TABLE LikeableProfile;
+ALTER TABLE LikeableProfileNew RENAME TO LikeableProfile;
+CREATE UNIQUE INDEX LikeableProfileUnique ON LikeableProfile(target_id, liker_id);
+
+CREATE TABLE LikeablePostNew(
+	id INTEGER PRIMARY KEY,
+	target_id INT NOT NULL,
+	liker_id INT NOT NULL,
+	FOREIGN KEY(target_id) REFERENCES Post(id),
+	FOREIGN KEY(liker_id) REFERENCES Profile(id)
+);
+INSERT INTO LikeablePostNew(target_id, liker_id) SELECT target_id, liker_id FROM LikeablePost ORDER BY rowid;
+DROP TABLE LikeablePost;
+ALTER TABLE LikeablePostNew RENAME TO LikeablePost;
+CREATE UNIQUE INDEX LikeablePostUnique ON LikeablePost(target_id, liker_id);
+
+CREATE TABLE LikeableCommentNew(
+	id INTEGER PRIMARY KEY,
+	target_id INT NOT NULL,
+	liker_id INT NOT NULL,
+	FOREIGN KEY(target_id) REFERENCES Comment(id),
+	FOREIGN KEY(liker_id) REFERENCES Profile(id)
+);
+INSERT INTO LikeableCommentNew(target_id, liker_id) SELECT target_id, liker_id FROM LikeableComment ORDER BY rowid;
+DROP TABLE LikeableComment;
+ALTER TABLE LikeableCommentNew RENAME TO LikeableComment;
+CREATE UNIQUE INDEX LikeableCommentUnique ON LikeableComment(target_id, liker_id);
@@ -0,0 +1,2 @@
+-- The author of a post can turn off commenting on it.
+ALTER TABLE Post ADD COLUMN commentsDisabled BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,21 @@
+-- Mentions of profiles in the text of posts and comments.
+-- textOffset and textLength are measured in characters and cover the whole "@username".
+CREATE TABLE MentionablePost(
+	target_id INT NOT NULL,
+	profile_id INT NOT NULL,
+	textOffset INT NOT NULL,
+	textLength INT NOT NULL,
+	FOREIGN KEY(target_id) REFERENCES Post(id) ON DELETE CASCADE,
+	FOREIGN KEY(profile_id) REFERENCES Profile(id) ON DELETE CASCADE
+);
+CREATE INDEX MentionablePostTarget ON MentionablePost(target_id);
+
+CREATE TABLE MentionableComment(
+	target_id INT NOT NULL,
+	profile_id INT NOT NULL,
+	textOffset INT NOT NULL,
+	textLength INT NOT NULL,
+	FOREIGN KEY(target_id) REFERENCES Comment(id) ON DELETE CASCADE,
+	FOREIGN KEY(profile_id) REFERENCES Profile(id) ON DELETE CASCADE
+);
+CREATE INDEX MentionableCommentTarget ON MentionableComment(target_id);
@@ -0,0 +1,41 @@
+-- Full-text search indexes of posts, comments and profiles.
+-- FTS5 is only available when go-sqlite3 is built with the "sqlite_fts5" tag.
+-- The indexes are external content tables, which are kept in sync with the indexed tables by triggers.
+CREATE VIRTUAL TABLE PostSearch USING fts5(textContent, content='Post', content_rowid='id', tokenize='unicode61');
+CREATE TRIGGER PostSearchInsert AFTER INSERT ON Post BEGIN
+	INSERT INTO PostSearch(rowid, textContent) VALUES (new.id, new.textContent);
+END;
+CREATE TRIGGER PostSearchUpdate AFTER UPDATE OF textContent ON Post BEGIN
+	INSERT INTO PostSearch(PostSearch, rowid, textContent) VALUES ('delete', old.id, old.textContent);
+	INSERT INTO PostSearch(rowid, textContent) VALUES (new.id, new.textContent);
+END;
+CREATE TRIGGER PostSearchDelete AFTER DELETE ON Post BEGIN
+	INSERT INTO PostSearch(PostSearch, rowid, textContent) VALUES ('delete', old.id, old.textContent);
+END;
+INSERT INTO PostSearch(PostSearch) VALUES ('rebuild');
+
+CREATE VIRTUAL TABLE CommentSearch USING fts5(textContent, content='Comment', content_rowid='id', tokenize='unicode61');
+CREATE TRIGGER CommentSearchInsert AFTER INSERT ON Comment BEGIN
+	INSERT INTO CommentSearch(rowid, textContent) VALUES (new.id, new.textContent);
+END;
+CREATE TRIGGER CommentSearchUpdate AFTER UPDATE OF textContent ON Comment BEGIN
+	INSERT INTO CommentSearch(CommentSearch, rowid, textContent) VALUES ('delete', old.id, old.textContent);
+	INSERT INTO CommentSearch(rowid, textContent) VALUES (new.id, new.textContent);
+END;
+CREATE TRIGGER CommentSearchDelete AFTER DELETE ON Comment BEGIN
+	INSERT INTO CommentSearch(CommentSearch, rowid, textContent) VALUES ('delete', old.id, old.textContent);
+END;
+INSERT INTO CommentSearch(CommentSearch) VALUES ('rebuild');
+
+CREATE VIRTUAL TABLE ProfileSearch USING fts5(username, about, content='Profile', content_rowid='id', tokenize='unicode61');
+CREATE TRIGGER ProfileSearchInsert AFTER INSERT ON Profile BEGIN
+	INSERT INTO ProfileSearch(rowid, username, about) VALUES (new.id, new.username, new.about);
+END;
+CREATE TRIGGER ProfileSearchUpdate AFTER UPDATE OF username, about ON Profile BEGIN
+	INSERT INTO ProfileSearch(ProfileSearch, rowid, username, about) VALUES ('delete', old.id, old.username, old.about);
+	INSERT INTO ProfileSearch(rowid, username, about) VALUES (new.id, new.username, new.about);
+END;
+CREATE TRIGGER ProfileSearchDelete AFTER DELETE ON Profile BEGIN
+	INSERT INTO ProfileSearch(ProfileSearch, rowid, username, about) VALUES ('delete', old.id, old.username, old.about);
+END;
+INSERT INTO ProfileSearch(ProfileSearch) VALUES ('rebuild');
@@ -0,0 +1,71 @@
+-- The likes of a deleted target or liker are deleted together with it.
+-- Before a target is deleted, its counters are deleted and the counters of its likers are decremented.
+-- SQLite can't alter a foreign key, so the tables are recreated.
+CREATE TABLE LikeableProfileNew(
+	id INTEGER PRIMARY KEY,
+	target_id INT NOT NULL,
+	liker_id INT NOT NULL,
+	created_at BIGINT NOT NULL DEFAULT 0,
+	reaction VARCHAR(16) NOT NULL DEFAULT 'like',
+	FOREIGN KEY(target_id) REFERENCES Profile(id) ON DELETE CASCADE,
+	FOREIGN KEY(liker_id) REFERENCES Profile(id) ON DELETE CASCADE
+);
+INSERT INTO LikeableProfileNew(id, target_id, liker_id, created_at, reaction)
+	SELECT id, target_id, liker_id, created_at, reaction FROM LikeableProfile;
+DROP TABLE LikeableProfile;
+ALTER TABLE LikeableProfileNew RENAME TO LikeableProfile;
+CREATE UNIQUE INDEX LikeableProfileUnique ON LikeableProfile(target_id, liker_id);
+CREATE INDEX LikeableProfileTargetRecency ON LikeableProfile(target_id, created_at, id);
+CREATE INDEX LikeableProfileLikerRecency ON LikeableProfile(liker_id, created_at, id);
+
+CREATE TABLE LikeablePostNew(
+	id INTEGER PRIMARY KEY,
+	target_id INT NOT NULL,
+	liker_id INT NOT NULL,
+	created_at BIGINT NOT NULL DEFAULT 0,
+	reaction VARCHAR(16) NOT NULL DEFAULT 'like',
+	FOREIGN KEY(target_id) REFERENCES Post(id) ON DELETE CASCADE,
+	FOREIGN KEY(liker_id) REFERENCES Profile(id) ON DELETE CASCADE
+);
+INSERT INTO LikeablePostNew(id, target_id, liker_id, created_at, reaction)
+	SELECT id, target_id, liker_id, created_at, reaction FROM LikeablePost;
+DROP TABLE LikeablePost;
+ALTER TABLE LikeablePostNew RENAME TO LikeablePost;
+CREATE UNIQUE INDEX LikeablePostUnique ON LikeablePost(target_id, liker_id);
+CREATE INDEX LikeablePostTargetRecency ON LikeablePost(target_id, created_at, id);
+CREATE INDEX LikeablePostLikerRecency ON LikeablePost(liker_id, created_at, id);
+
+CREATE TABLE LikeableCommentNew(
+	id INTEGER PRIMARY KEY,
+	target_id INT NOT NULL,
+	liker_id INT NOT NULL,
+	created_at BIGINT NOT NULL DEFAULT 0,
+	reaction VARCHAR(16) NOT NULL DEFAULT 'like',
+	FOREIGN KEY(target_id) REFERENCES Comment(id) ON DELETE CASCADE,
+	FOREIGN KEY(liker_id) REFERENCES Profile(id) ON DELETE CASCADE
+);
+INSERT INTO LikeableCommentNew(id, target_id, liker_id, created_at, reaction)
+	SELECT id, target_id, liker_id, created_at, reaction FROM LikeableComment;
+DROP TABLE LikeableComment;
+ALTER TABLE LikeableCommentNew RENAME TO LikeableComment;
+CREATE UNIQUE INDEX LikeableCommentUnique ON LikeableComment(target_id, liker_id);
+CREATE INDEX LikeableCommentTargetRecency ON LikeableComment(target_id, created_at, id);
+CREATE INDEX LikeableCommentLikerRecency ON LikeableComment(liker_id, created_at, id);
+
+CREATE TRIGGER LikeableProfileCascade BEFORE DELETE ON Profile BEGIN
+	UPDATE LikeableProfileCount SET likes = likes - 1
+		WHERE counter = 'liker' AND id IN (SELECT CAST(liker_id AS VARCHAR(255)) FROM LikeableProfile WHERE target_id = old.id);
+	DELETE FROM LikeableProfileCount WHERE counter <> 'liker' AND id = CAST(old.id AS VARCHAR(255));
+END;
+
+CREATE TRIGGER LikeablePostCascade BEFORE DELETE ON Post BEGIN
+	UPDATE LikeablePostCount SET likes = likes - 1
+		WHERE counter = 'liker' AND id IN (SELECT CAST(liker_id AS VARCHAR(255)) FROM LikeablePost WHERE target_id = old.id);
+	DELETE FROM LikeablePostCount WHERE counter <> 'liker' AND id = CAST(old.id AS VARCHAR(255));
+END;
+
+CREATE TRIGGER LikeableCommentCascade BEFORE DELETE ON Comment BEGIN
+	UPDATE LikeableCommentCount SET likes = likes - 1
+		WHERE counter = 'liker' AND id IN (SELECT CAST(liker_id AS VARCHAR(255)) FROM LikeableComment WHERE target_id = old.id);
+	DELETE FROM LikeableCommentCount WHERE counter <> 'liker' AND id = CAST(old.id AS VARCHAR(255));
+END;
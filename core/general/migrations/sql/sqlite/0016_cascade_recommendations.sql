@@ -0,0 +1,12 @@
+-- The recommendations of a deleted post or user are deleted together with it.
+-- SQLite can't alter a foreign key, so the table is recreated.
+CREATE TABLE PostRecommendationNew(
+	recommendation_id INT NOT NULL,
+	user_id INT NOT NULL,
+	FOREIGN KEY(recommendation_id) REFERENCES Post(id) ON DELETE CASCADE,
+	FOREIGN KEY(user_id) REFERENCES Profile(id) ON DELETE CASCADE
+);
+INSERT INTO PostRecommendationNew(recommendation_id, user_id) SELECT recommendation_id, user_id FROM PostRecommendation;
+DROP TABLE PostRecommendation;
+ALTER TABLE PostRecommendationNew RENAME TO PostRecommendation;
+CREATE INDEX PostRecommendationIndex ON PostRecommendation(user_id);
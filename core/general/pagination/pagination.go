@@ -0,0 +1,91 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"errors"
+	"github.com/k0marov/go-socnet/core/general/client_errors"
+	"strconv"
+	"strings"
+)
+
+const DefaultLimit = 20
+const MaxLimit = 100
+
+// Cursor points at the last item of the previous page of a listing ordered by (createdAt, id) descending
+type Cursor struct {
+	CreatedAt int64
+	Id        string
+}
+
+func (c Cursor) IsZero() bool {
+	return c == Cursor{}
+}
+
+// Encode returns an opaque string representation of the cursor, which is empty for the zero Cursor
+func (c Cursor) Encode() string {
+	if c.IsZero() {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatInt(c.CreatedAt, 10) + ":" + c.Id))
+}
+
+func DecodeCursor(encoded string) (Cursor, error) {
+	if encoded == "" {
+		return Cursor{}, nil
+	}
+	decoded, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return Cursor{}, err
+	}
+	createdAtStr, id, found := strings.Cut(string(decoded), ":")
+	if !found || id == "" {
+		return Cursor{}, errors.New("cursor does not contain an id")
+	}
+	createdAt, err := strconv.ParseInt(createdAtStr, 10, 64)
+	if err != nil {
+		return Cursor{}, err
+	}
+	return Cursor{CreatedAt: createdAt, Id: id}, nil
+}
+
+type Page struct {
+	After Cursor
+	Limit int
+}
+
+func NewPage(limitStr, cursorStr string) (Page, error) {
+	limit := DefaultLimit
+	if limitStr != "" {
+		var err error
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil || limit <= 0 {
+			return Page{}, client_errors.InvalidLimit
+		}
+	}
+	if limit > MaxLimit {
+		return Page{}, client_errors.TooBigLimit
+	}
+	cursor, err := DecodeCursor(cursorStr)
+	if err != nil {
+		return Page{}, client_errors.InvalidCursor
+	}
+	return Page{After: cursor, Limit: limit}, nil
+}
+
+// Condition returns an SQL condition which selects the rows after the page cursor.
+// It should be used together with "ORDER BY <createdAtCol> DESC, <idCol> DESC LIMIT <page.Limit>"
+func (p Page) Condition(createdAtCol, idCol string) (string, []any) {
+	if p.After.IsZero() {
+		return "1 = 1", []any{}
+	}
+	cond := "(" + createdAtCol + " < ? OR (" + createdAtCol + " = ? AND " + idCol + " < ?))"
+	return cond, []any{p.After.CreatedAt, p.After.CreatedAt, p.After.Id}
+}
+
+// NextCursor returns the cursor of the page following the provided items, or the zero Cursor if it is the last page
+func NextCursor[T any](items []T, page Page, getCursor func(T) Cursor) Cursor {
+	if len(items) == 0 || len(items) < page.Limit {
+		return Cursor{}
+	}
+	return getCursor(items[len(items)-1])
+}
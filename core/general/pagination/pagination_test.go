@@ -0,0 +1,71 @@
+package pagination_test
+
+import (
+	"github.com/k0marov/go-socnet/core/general/client_errors"
+	"github.com/k0marov/go-socnet/core/general/pagination"
+	. "github.com/k0marov/go-socnet/core/helpers/test_helpers"
+	"strconv"
+	"testing"
+)
+
+func TestCursor(t *testing.T) {
+	t.Run("encoding and decoding", func(t *testing.T) {
+		cursor := pagination.Cursor{CreatedAt: RandomTime().Unix(), Id: RandomId()}
+		got, err := pagination.DecodeCursor(cursor.Encode())
+		AssertNoError(t, err)
+		Assert(t, got, cursor, "decoded cursor")
+	})
+	t.Run("zero cursor is encoded as empty string", func(t *testing.T) {
+		Assert(t, pagination.Cursor{}.Encode(), "", "encoded zero cursor")
+		got, err := pagination.DecodeCursor("")
+		AssertNoError(t, err)
+		Assert(t, got, pagination.Cursor{}, "decoded empty cursor")
+	})
+	t.Run("error case - invalid cursor", func(t *testing.T) {
+		for _, invalid := range []string{"!!!", "YWJj", "YWJjOjQy", "NDI6"} {
+			_, err := pagination.DecodeCursor(invalid)
+			AssertSomeError(t, err)
+		}
+	})
+}
+
+func TestNewPage(t *testing.T) {
+	cursor := pagination.Cursor{CreatedAt: RandomTime().Unix(), Id: RandomId()}
+	cases := []struct {
+		limit, cursor string
+		want          pagination.Page
+		wantErr       error
+	}{
+		{"", "", pagination.Page{Limit: pagination.DefaultLimit}, nil},
+		{"5", cursor.Encode(), pagination.Page{Limit: 5, After: cursor}, nil},
+		{"abc", "", pagination.Page{}, client_errors.InvalidLimit},
+		{"-1", "", pagination.Page{}, client_errors.InvalidLimit},
+		{strconv.Itoa(pagination.MaxLimit + 1), "", pagination.Page{}, client_errors.TooBigLimit},
+		{"5", "!!!", pagination.Page{}, client_errors.InvalidCursor},
+	}
+	for _, c := range cases {
+		t.Run(c.limit+" "+c.cursor, func(t *testing.T) {
+			got, err := pagination.NewPage(c.limit, c.cursor)
+			if c.wantErr != nil {
+				AssertError(t, err, c.wantErr)
+			} else {
+				AssertNoError(t, err)
+				Assert(t, got, c.want, "returned page")
+			}
+		})
+	}
+}
+
+func TestNextCursor(t *testing.T) {
+	getCursor := func(id string) pagination.Cursor {
+		return pagination.Cursor{CreatedAt: 42, Id: id}
+	}
+	t.Run("full page", func(t *testing.T) {
+		got := pagination.NextCursor([]string{"3", "2"}, pagination.Page{Limit: 2}, getCursor)
+		Assert(t, got, getCursor("2"), "next cursor")
+	})
+	t.Run("last page", func(t *testing.T) {
+		got := pagination.NextCursor([]string{"1"}, pagination.Page{Limit: 2}, getCursor)
+		Assert(t, got, pagination.Cursor{}, "next cursor")
+	})
+}
@@ -1,12 +1,72 @@
 package periodic
 
-import "time"
+import (
+	"context"
+	"log"
+	"math/rand"
+	"sync"
+	"time"
+)
 
-func RunPeriodically(f func(), period time.Duration) {
-	go func() {
-		for {
-			f()
-			time.Sleep(period)
+type Task = func(ctx context.Context) error
+
+// Status describes the last run of a Job
+type Status struct {
+	Runs    int
+	LastRun time.Time
+	LastErr error
+}
+
+// Job runs a task every period plus a random jitter, so that jobs with the same period do not all fire at once
+type Job struct {
+	name   string
+	task   Task
+	period time.Duration
+	jitter time.Duration
+
+	mu     sync.Mutex
+	status Status
+}
+
+func NewJob(name string, period, jitter time.Duration, task Task) *Job {
+	return &Job{name: name, task: task, period: period, jitter: jitter}
+}
+
+// Run runs the task right away and then periodically until ctx is cancelled.
+// It blocks, so it is usually called in a separate goroutine.
+func (j *Job) Run(ctx context.Context) {
+	for {
+		err := j.task(ctx)
+		if err != nil && ctx.Err() == nil {
+			log.Printf("while running the periodic job %q: %v", j.name, err)
 		}
-	}()
+		j.mu.Lock()
+		j.status = Status{Runs: j.status.Runs + 1, LastRun: time.Now(), LastErr: err}
+		j.mu.Unlock()
+
+		timer := time.NewTimer(j.nextDelay())
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+func (j *Job) Name() string {
+	return j.name
+}
+
+func (j *Job) Status() Status {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.status
+}
+
+func (j *Job) nextDelay() time.Duration {
+	if j.jitter <= 0 {
+		return j.period
+	}
+	return j.period + time.Duration(rand.Int63n(int64(j.jitter)))
 }
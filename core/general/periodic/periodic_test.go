@@ -0,0 +1,66 @@
+package periodic_test
+
+import (
+	"context"
+	"github.com/k0marov/go-socnet/core/general/periodic"
+	. "github.com/k0marov/go-socnet/core/helpers/test_helpers"
+	"testing"
+	"time"
+)
+
+func TestJob(t *testing.T) {
+	t.Run("runs the task periodically until the context is cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		runs := make(chan struct{}, 100)
+		job := periodic.NewJob(RandomString(), time.Millisecond, time.Millisecond, func(gotCtx context.Context) error {
+			if gotCtx != ctx {
+				panic("unexpected args")
+			}
+			select {
+			case runs <- struct{}{}:
+			default:
+			}
+			return nil
+		})
+		stopped := make(chan struct{})
+		go func() {
+			job.Run(ctx)
+			close(stopped)
+		}()
+		for i := 0; i < 3; i++ {
+			<-runs
+		}
+		cancel()
+		<-stopped
+		Assert(t, job.Status().Runs >= 3, true, "the task was run at least 3 times")
+	})
+	t.Run("reports the last run and the last error", func(t *testing.T) {
+		tErr := RandomError()
+		ctx, cancel := context.WithCancel(context.Background())
+		job := periodic.NewJob(RandomString(), time.Hour, 0, func(context.Context) error {
+			cancel()
+			return tErr
+		})
+		Assert(t, job.Status(), periodic.Status{}, "status before the first run")
+		job.Run(ctx)
+		status := job.Status()
+		Assert(t, status.Runs, 1, "number of runs")
+		Assert(t, status.LastErr, tErr, "last error")
+		Assert(t, TimeAlmostNow(status.LastRun), true, "last run is now")
+	})
+	t.Run("does not wait for the next run after the context is cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		job := periodic.NewJob(RandomString(), time.Hour, time.Hour, func(context.Context) error { return nil })
+		stopped := make(chan struct{})
+		go func() {
+			job.Run(ctx)
+			close(stopped)
+		}()
+		cancel()
+		select {
+		case <-stopped:
+		case <-time.After(time.Second):
+			t.Fatal("job did not stop after the context was cancelled")
+		}
+	})
+}
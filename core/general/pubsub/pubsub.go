@@ -0,0 +1,111 @@
+package pubsub
+
+import "sync"
+
+// Message is delivered to every subscriber of the topic it is published to
+type Message struct {
+	// Event is the name of the message type, e.g. "notification"
+	Event string
+	Data  any
+}
+
+// Hub is an in-process publish/subscribe hub.
+// Publishing never blocks: every subscription has a bounded buffer, and a subscriber which falls behind by more
+// than its buffer is disconnected, so that a slow client can't stall the publishers or silently miss messages.
+type Hub struct {
+	bufferSize int
+
+	mu     sync.Mutex
+	topics map[string]map[*Subscription]bool
+	closed bool
+}
+
+func NewHub(bufferSize int) *Hub {
+	return &Hub{bufferSize: bufferSize, topics: map[string]map[*Subscription]bool{}}
+}
+
+// Subscription receives the messages published to any of its topics until it is closed or disconnected
+type Subscription struct {
+	hub      *Hub
+	topics   []string
+	messages chan Message
+	closed   bool // guarded by hub.mu
+}
+
+// Messages returns the channel of received messages, which is closed when the subscription is closed or disconnected
+func (s *Subscription) Messages() <-chan Message {
+	return s.messages
+}
+
+// Close unsubscribes from all topics, it is safe to call it more than once
+func (s *Subscription) Close() {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+	s.hub.remove(s)
+}
+
+// Subscribe returns a subscription which must be closed when it is no longer needed.
+// If the hub is already closed, the returned subscription is closed too.
+func (h *Hub) Subscribe(topics ...string) *Subscription {
+	sub := &Subscription{hub: h, topics: topics, messages: make(chan Message, h.bufferSize)}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		sub.closed = true
+		close(sub.messages)
+		return sub
+	}
+	for _, topic := range topics {
+		if h.topics[topic] == nil {
+			h.topics[topic] = map[*Subscription]bool{}
+		}
+		h.topics[topic][sub] = true
+	}
+	return sub
+}
+
+func (h *Hub) Publish(topic string, msg Message) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for sub := range h.topics[topic] {
+		select {
+		case sub.messages <- msg:
+		default:
+			h.remove(sub)
+		}
+	}
+}
+
+// HasSubscribers allows to skip preparing the messages nobody is going to receive
+func (h *Hub) HasSubscribers(topic string) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.topics[topic]) > 0
+}
+
+// Close disconnects all subscribers, e.g. when the server is shutting down
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.closed = true
+	for _, subs := range h.topics {
+		for sub := range subs {
+			h.remove(sub)
+		}
+	}
+}
+
+// remove must be called with h.mu locked
+func (h *Hub) remove(sub *Subscription) {
+	if sub.closed {
+		return
+	}
+	sub.closed = true
+	for _, topic := range sub.topics {
+		delete(h.topics[topic], sub)
+		if len(h.topics[topic]) == 0 {
+			delete(h.topics, topic)
+		}
+	}
+	close(sub.messages)
+}
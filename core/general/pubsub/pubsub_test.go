@@ -0,0 +1,129 @@
+package pubsub_test
+
+import (
+	"fmt"
+	"github.com/k0marov/go-socnet/core/general/pubsub"
+	. "github.com/k0marov/go-socnet/core/helpers/test_helpers"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// receiveAll returns the buffered messages of a subscription and whether it is still open
+func receiveAll(sub *pubsub.Subscription) (msgs []pubsub.Message, isOpen bool) {
+	for {
+		select {
+		case msg, ok := <-sub.Messages():
+			if !ok {
+				return msgs, false
+			}
+			msgs = append(msgs, msg)
+		default:
+			return msgs, true
+		}
+	}
+}
+
+func TestHub(t *testing.T) {
+	t.Run("messages are delivered to the subscribers of the topic", func(t *testing.T) {
+		hub := pubsub.NewHub(10)
+		sub1 := hub.Subscribe("a", "b")
+		sub2 := hub.Subscribe("b")
+		msgA := pubsub.Message{Event: "a", Data: RandomString()}
+		msgB := pubsub.Message{Event: "b", Data: RandomString()}
+		hub.Publish("a", msgA)
+		hub.Publish("b", msgB)
+		hub.Publish("c", pubsub.Message{Event: "c"})
+
+		got, isOpen := receiveAll(sub1)
+		Assert(t, got, []pubsub.Message{msgA, msgB}, "messages of the first subscriber")
+		Assert(t, isOpen, true, "first subscription is open")
+		got, isOpen = receiveAll(sub2)
+		Assert(t, got, []pubsub.Message{msgB}, "messages of the second subscriber")
+		Assert(t, isOpen, true, "second subscription is open")
+	})
+	t.Run("closing a subscription", func(t *testing.T) {
+		hub := pubsub.NewHub(10)
+		sub := hub.Subscribe("a")
+		Assert(t, hub.HasSubscribers("a"), true, "topic has subscribers")
+		sub.Close()
+		sub.Close()
+		Assert(t, hub.HasSubscribers("a"), false, "topic has subscribers")
+		hub.Publish("a", pubsub.Message{Event: "a"})
+		got, isOpen := receiveAll(sub)
+		Assert(t, len(got), 0, "number of received messages")
+		Assert(t, isOpen, false, "subscription is open")
+	})
+	t.Run("a subscriber which falls behind is disconnected", func(t *testing.T) {
+		hub := pubsub.NewHub(2)
+		slow := hub.Subscribe("a")
+		for i := 0; i < 3; i++ {
+			hub.Publish("a", pubsub.Message{Event: "a", Data: i})
+		}
+		got, isOpen := receiveAll(slow)
+		Assert(t, got, []pubsub.Message{{Event: "a", Data: 0}, {Event: "a", Data: 1}}, "messages received before disconnecting")
+		Assert(t, isOpen, false, "subscription is open")
+		Assert(t, hub.HasSubscribers("a"), false, "topic has subscribers")
+		slow.Close() // closing a disconnected subscription does nothing
+	})
+	t.Run("closing the hub disconnects everyone", func(t *testing.T) {
+		hub := pubsub.NewHub(10)
+		sub := hub.Subscribe("a", "b")
+		hub.Close()
+		_, isOpen := receiveAll(sub)
+		Assert(t, isOpen, false, "subscription is open")
+		_, isOpen = receiveAll(hub.Subscribe("a"))
+		Assert(t, isOpen, false, "subscription made after closing is open")
+	})
+	t.Run("concurrent usage", func(t *testing.T) {
+		hub := pubsub.NewHub(1000)
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(2)
+			go func() {
+				defer wg.Done()
+				sub := hub.Subscribe("a")
+				defer sub.Close()
+				receiveAll(sub)
+			}()
+			go func() {
+				defer wg.Done()
+				hub.Publish("a", pubsub.Message{Event: "a"})
+			}()
+		}
+		wg.Wait()
+	})
+}
+
+// BenchmarkHub_Publish measures fanning out messages to many connected clients.
+// The clients which can't keep up with the publisher are disconnected, their number is reported as a metric.
+func BenchmarkHub_Publish(b *testing.B) {
+	for _, subscribers := range []int{10, 1000, 10000} {
+		b.Run(fmt.Sprintf("%d subscribers", subscribers), func(b *testing.B) {
+			hub := pubsub.NewHub(64)
+			var closing, disconnected int32
+			var wg sync.WaitGroup
+			for i := 0; i < subscribers; i++ {
+				sub := hub.Subscribe("topic")
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					for range sub.Messages() {
+					}
+					if atomic.LoadInt32(&closing) == 0 {
+						atomic.AddInt32(&disconnected, 1)
+					}
+				}()
+			}
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				hub.Publish("topic", pubsub.Message{Event: "bench", Data: i})
+			}
+			b.StopTimer()
+			atomic.StoreInt32(&closing, 1)
+			hub.Close()
+			wg.Wait()
+			b.ReportMetric(float64(disconnected), "disconnected")
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package static_store
+
+import (
+	"github.com/k0marov/go-socnet/core/general/core_err"
+	"github.com/k0marov/go-socnet/core/general/core_values"
+	"github.com/k0marov/go-socnet/core/general/core_values/ref"
+	"os"
+	"path/filepath"
+)
+
+// StagingDirName is the directory inside the static directory where the files of unfinished work are kept.
+// Everything inside it can be safely deleted when nothing is running.
+const StagingDirName = ".staging"
+
+// TempDirCreator os.MkdirTemp implements this
+type TempDirCreator = func(dir, pattern string) (string, error)
+
+// FileRenamer os.Rename implements this
+type FileRenamer = func(oldPath, newPath string) error
+
+type StagingBeginner = func() (*Staging, error)
+
+type stagedFile struct {
+	staged core_values.StaticPath
+	final  core_values.StaticPath
+}
+
+// Staging writes static files into a temporary directory under the static directory.
+// They are moved into place only on Commit, so a failure (or a crash) before that
+// never leaves any files behind at the paths that are referenced by the db.
+type Staging struct {
+	staticDir  string
+	dir        core_values.StaticPath
+	createFile StaticFileCreator
+	mkdirAll   RecursiveDirCreator
+	rename     FileRenamer
+	deleteDir  StaticDirDeleter
+	deleteFile StaticFileDeleter
+	staged     []stagedFile
+	committed  []core_values.StaticPath
+}
+
+func NewStagingBeginner(staticDir string, mkdirAll RecursiveDirCreator, mkdirTemp TempDirCreator, createFile StaticFileCreator, rename FileRenamer, deleteDir StaticDirDeleter, deleteFile StaticFileDeleter) StagingBeginner {
+	return func() (*Staging, error) {
+		stagingRoot := filepath.Join(staticDir, StagingDirName)
+		err := mkdirAll(stagingRoot, 0777)
+		if err != nil {
+			return nil, core_err.Rethrow("creating the staging directory", err)
+		}
+		fullDir, err := mkdirTemp(stagingRoot, "")
+		if err != nil {
+			return nil, core_err.Rethrow("creating a temporary staging directory", err)
+		}
+		return &Staging{
+			staticDir:  staticDir,
+			dir:        filepath.Join(StagingDirName, filepath.Base(fullDir)),
+			createFile: createFile,
+			mkdirAll:   mkdirAll,
+			rename:     rename,
+			deleteDir:  deleteDir,
+			deleteFile: deleteFile,
+		}, nil
+	}
+}
+
+func NewStagingBeginnerImpl(staticDir string) StagingBeginner {
+	return NewStagingBeginner(staticDir, os.MkdirAll, os.MkdirTemp, NewStaticFileCreatorImpl(staticDir), os.Rename, NewStaticDirDeleterImpl(staticDir), NewStaticFileDeleterImpl(staticDir))
+}
+
+// CreateFile implements StaticFileCreator.
+// It returns the path which the file will have after the staging is committed.
+func (s *Staging) CreateFile(data ref.Ref[[]byte], dir, filename string) (core_values.StaticPath, error) {
+	staged, err := s.createFile(data, filepath.Join(s.dir, dir), filename)
+	if err != nil {
+		return "", core_err.Rethrow("creating a staged file", err)
+	}
+	final := filepath.Join(dir, filename)
+	s.staged = append(s.staged, stagedFile{staged: staged, final: final})
+	return final, nil
+}
+
+// Commit moves all staged files into place.
+// If one of them cannot be moved, the ones that were already moved are deleted.
+func (s *Staging) Commit() error {
+	for _, file := range s.staged {
+		fullPath := filepath.Join(s.staticDir, file.final)
+		err := s.mkdirAll(filepath.Dir(fullPath), 0777)
+		if err == nil {
+			err = s.rename(filepath.Join(s.staticDir, file.staged), fullPath)
+		}
+		if err != nil {
+			s.Revert()
+			return core_err.Rethrow("moving a staged file into place", err)
+		}
+		s.committed = append(s.committed, file.final)
+	}
+	s.staged = nil
+	return nil
+}
+
+// Revert deletes the files that were moved into place by Commit
+func (s *Staging) Revert() error {
+	var firstErr error
+	for _, path := range s.committed {
+		err := s.deleteFile(path)
+		if err != nil && firstErr == nil {
+			firstErr = core_err.Rethrow("deleting a committed file", err)
+		}
+	}
+	s.committed = nil
+	return firstErr
+}
+
+// Discard deletes the temporary directory together with all the files that were not committed
+func (s *Staging) Discard() error {
+	s.staged = nil
+	err := s.deleteDir(s.dir)
+	if err != nil {
+		return core_err.Rethrow("deleting the temporary staging directory", err)
+	}
+	return nil
+}
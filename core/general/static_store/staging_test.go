@@ -0,0 +1,107 @@
+package static_store_test
+
+import (
+	"github.com/k0marov/go-socnet/core/general/core_values/ref"
+	"github.com/k0marov/go-socnet/core/general/static_store"
+	. "github.com/k0marov/go-socnet/core/helpers/test_helpers"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestStaging(t *testing.T) {
+	staticDir := t.TempDir()
+	beginStaging := static_store.NewStagingBeginnerImpl(staticDir)
+	staticPath := func(path string) string {
+		return filepath.Join(staticDir, path)
+	}
+	assertExists := func(t testing.TB, path string, want bool) {
+		t.Helper()
+		_, err := os.Stat(staticPath(path))
+		Assert(t, err == nil, want, "file "+path+" exists")
+	}
+	stagingEntries := func(t testing.TB) int {
+		t.Helper()
+		entries, err := os.ReadDir(staticPath(static_store.StagingDirName))
+		AssertNoError(t, err)
+		return len(entries)
+	}
+	stage := func(t testing.TB, staging *static_store.Staging) (string, []byte) {
+		t.Helper()
+		data := []byte(RandomString())
+		dataRef, _ := ref.NewRef(&data)
+		dir := RandomString()
+		path, err := staging.CreateFile(dataRef, dir, "file")
+		AssertNoError(t, err)
+		Assert(t, path, filepath.Join(dir, "file"), "returned path")
+		assertExists(t, path, false)
+		return path, data
+	}
+
+	t.Run("committing moves the files into place", func(t *testing.T) {
+		staging, err := beginStaging()
+		AssertNoError(t, err)
+		path, data := stage(t, staging)
+
+		err = staging.Commit()
+		AssertNoError(t, err)
+		gotData, err := os.ReadFile(staticPath(path))
+		AssertNoError(t, err)
+		Assert(t, gotData, data, "committed file contents")
+
+		err = staging.Discard()
+		AssertNoError(t, err)
+		assertExists(t, path, true)
+		Assert(t, stagingEntries(t), 0, "number of leftover staging directories")
+	})
+	t.Run("discarding without committing leaves nothing behind", func(t *testing.T) {
+		staging, err := beginStaging()
+		AssertNoError(t, err)
+		path, _ := stage(t, staging)
+
+		err = staging.Discard()
+		AssertNoError(t, err)
+		assertExists(t, path, false)
+		Assert(t, stagingEntries(t), 0, "number of leftover staging directories")
+	})
+	t.Run("reverting deletes the committed files", func(t *testing.T) {
+		staging, err := beginStaging()
+		AssertNoError(t, err)
+		path, _ := stage(t, staging)
+
+		AssertNoError(t, staging.Commit())
+		AssertNoError(t, staging.Revert())
+		assertExists(t, path, false)
+		AssertNoError(t, staging.Discard())
+	})
+	t.Run("error case - moving a file throws", func(t *testing.T) {
+		renamed := 0
+		rename := func(oldPath, newPath string) error {
+			if renamed == 1 {
+				return RandomError()
+			}
+			renamed++
+			return os.Rename(oldPath, newPath)
+		}
+		beginStaging := static_store.NewStagingBeginner(staticDir, os.MkdirAll, os.MkdirTemp, static_store.NewStaticFileCreatorImpl(staticDir), rename, static_store.NewStaticDirDeleterImpl(staticDir), static_store.NewStaticFileDeleterImpl(staticDir))
+		staging, err := beginStaging()
+		AssertNoError(t, err)
+		path1, _ := stage(t, staging)
+		path2, _ := stage(t, staging)
+
+		err = staging.Commit()
+		AssertSomeError(t, err)
+		assertExists(t, path1, false)
+		assertExists(t, path2, false)
+		AssertNoError(t, staging.Discard())
+	})
+	t.Run("error case - creating the temporary dir throws", func(t *testing.T) {
+		mkdirTemp := func(string, string) (string, error) {
+			return "", RandomError()
+		}
+		mkdirAll := func(string, fs.FileMode) error { return nil }
+		_, err := static_store.NewStagingBeginner(staticDir, mkdirAll, mkdirTemp, nil, nil, nil, nil)()
+		AssertSomeError(t, err)
+	})
+}
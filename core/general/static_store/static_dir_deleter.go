@@ -10,9 +10,9 @@ import (
 // DirDeleter os.RemoveAll implements this
 type DirDeleter = func(dir string) error
 
-func NewStaticDirDeleter(deleteDir DirDeleter) StaticDirDeleter {
+func NewStaticDirDeleter(staticDir string, deleteDir DirDeleter) StaticDirDeleter {
 	return func(dir core_values.StaticPath) error {
-		fullDir := filepath.Join(StaticDir, dir)
+		fullDir := filepath.Join(staticDir, dir)
 		err := deleteDir(fullDir)
 		if err != nil {
 			return fmt.Errorf("while deleting a static dir (%v) : %w", fullDir, err)
@@ -21,6 +21,6 @@ func NewStaticDirDeleter(deleteDir DirDeleter) StaticDirDeleter {
 	}
 }
 
-func NewStaticDirDeleterImpl() StaticDirDeleter {
-	return NewStaticDirDeleter(os.RemoveAll)
+func NewStaticDirDeleterImpl(staticDir string) StaticDirDeleter {
+	return NewStaticDirDeleter(staticDir, os.RemoveAll)
 }
@@ -8,8 +8,9 @@ import (
 )
 
 func TestStaticDirDeleter(t *testing.T) {
+	tStaticDir := RandomString()
 	tPath := RandomString()
-	wantDirPath := filepath.Join(static_store2.StaticDir, tPath)
+	wantDirPath := filepath.Join(tStaticDir, tPath)
 	t.Run("happy case", func(t *testing.T) {
 		deleteDir := func(dir string) error {
 			if dir == wantDirPath {
@@ -17,7 +18,7 @@ func TestStaticDirDeleter(t *testing.T) {
 			}
 			panic("unexpected args")
 		}
-		sut := static_store2.NewStaticDirDeleter(deleteDir)
+		sut := static_store2.NewStaticDirDeleter(tStaticDir, deleteDir)
 		err := sut(tPath)
 		AssertNoError(t, err)
 	})
@@ -25,7 +26,7 @@ func TestStaticDirDeleter(t *testing.T) {
 		deleteDir := func(string) error {
 			return RandomError()
 		}
-		err := static_store2.NewStaticDirDeleter(deleteDir)(tPath)
+		err := static_store2.NewStaticDirDeleter(tStaticDir, deleteDir)(tPath)
 		AssertSomeError(t, err)
 	})
 }
@@ -14,9 +14,9 @@ type RecursiveDirCreator = func(path string, perm fs.FileMode) error
 // FileCreator os.WriteFile implements this
 type FileCreator = func(name string, data []byte, perm fs.FileMode) error
 
-func NewStaticFileCreator(mkdirAll RecursiveDirCreator, writeFile FileCreator) StaticFileCreator {
+func NewStaticFileCreator(staticDir string, mkdirAll RecursiveDirCreator, writeFile FileCreator) StaticFileCreator {
 	return func(data ref.Ref[[]byte], dir, filename string) (string, error) {
-		fullDir := filepath.Join(StaticDir, dir)
+		fullDir := filepath.Join(staticDir, dir)
 		err := mkdirAll(fullDir, 0777)
 		if err != nil {
 			return "", fmt.Errorf("error while creating a new directory: %w", err)
@@ -31,6 +31,6 @@ func NewStaticFileCreator(mkdirAll RecursiveDirCreator, writeFile FileCreator) S
 	}
 }
 
-func NewStaticFileCreatorImpl() StaticFileCreator {
-	return NewStaticFileCreator(os.MkdirAll, os.WriteFile)
+func NewStaticFileCreatorImpl(staticDir string) StaticFileCreator {
+	return NewStaticFileCreator(staticDir, os.MkdirAll, os.WriteFile)
 }
@@ -11,11 +11,12 @@ import (
 )
 
 func TestStaticFileCreator(t *testing.T) {
+	tStaticDir := RandomString()
 	tData := []byte(RandomString())
 	tDataRef, _ := ref.NewRef(&tData)
 	tDir := RandomString()
 	tFilename := RandomString()
-	wantDir := filepath.Join(static_store2.StaticDir, tDir)
+	wantDir := filepath.Join(tStaticDir, tDir)
 	wantFullPath := filepath.Join(wantDir, tFilename)
 	wantPath := filepath.Join(tDir, tFilename)
 
@@ -34,7 +35,7 @@ func TestStaticFileCreator(t *testing.T) {
 					}
 					panic("called with unexpected arguments")
 				}
-				sut := static_store2.NewStaticFileCreator(recursiveDirCreator, writeFile) // now nil
+				sut := static_store2.NewStaticFileCreator(tStaticDir, recursiveDirCreator, writeFile) // now nil
 				gotPath, err := sut(tDataRef, tDir, tFilename)
 				AssertNoError(t, err)
 				Assert(t, gotPath, wantPath, "returned path")
@@ -43,7 +44,7 @@ func TestStaticFileCreator(t *testing.T) {
 				writeFile := func(string, []byte, fs.FileMode) error {
 					return RandomError()
 				}
-				sut := static_store2.NewStaticFileCreator(recursiveDirCreator, writeFile)
+				sut := static_store2.NewStaticFileCreator(tStaticDir, recursiveDirCreator, writeFile)
 				_, err := sut(tDataRef, tDir, tFilename)
 				AssertSomeError(t, err)
 			})
@@ -52,7 +53,7 @@ func TestStaticFileCreator(t *testing.T) {
 			recursiveDirCreator := func(path string, perm fs.FileMode) error {
 				return RandomError()
 			}
-			sut := static_store2.NewStaticFileCreator(recursiveDirCreator, nil) // writefile shouldn't be called, so it's nil
+			sut := static_store2.NewStaticFileCreator(tStaticDir, recursiveDirCreator, nil) // writefile shouldn't be called, so it's nil
 			_, err := sut(tDataRef, tDir, tFilename)
 			AssertSomeError(t, err)
 		})
@@ -0,0 +1,26 @@
+package static_store
+
+import (
+	"fmt"
+	"github.com/k0marov/go-socnet/core/general/core_values"
+	"os"
+	"path/filepath"
+)
+
+// FileDeleter os.Remove implements this
+type FileDeleter = func(name string) error
+
+func NewStaticFileDeleter(staticDir string, deleteFile FileDeleter) StaticFileDeleter {
+	return func(path core_values.StaticPath) error {
+		fullPath := filepath.Join(staticDir, path)
+		err := deleteFile(fullPath)
+		if err != nil {
+			return fmt.Errorf("while deleting a static file (%v) : %w", fullPath, err)
+		}
+		return nil
+	}
+}
+
+func NewStaticFileDeleterImpl(staticDir string) StaticFileDeleter {
+	return NewStaticFileDeleter(staticDir, os.Remove)
+}
@@ -0,0 +1,31 @@
+package static_store_test
+
+import (
+	"github.com/k0marov/go-socnet/core/general/static_store"
+	. "github.com/k0marov/go-socnet/core/helpers/test_helpers"
+	"path/filepath"
+	"testing"
+)
+
+func TestStaticFileDeleter(t *testing.T) {
+	tStaticDir := RandomString()
+	tPath := RandomString()
+	wantFullPath := filepath.Join(tStaticDir, tPath)
+	t.Run("happy case", func(t *testing.T) {
+		deleteFile := func(name string) error {
+			if name == wantFullPath {
+				return nil
+			}
+			panic("unexpected args")
+		}
+		err := static_store.NewStaticFileDeleter(tStaticDir, deleteFile)(tPath)
+		AssertNoError(t, err)
+	})
+	t.Run("error case - deleting the file throws", func(t *testing.T) {
+		deleteFile := func(string) error {
+			return RandomError()
+		}
+		err := static_store.NewStaticFileDeleter(tStaticDir, deleteFile)(tPath)
+		AssertSomeError(t, err)
+	})
+}
@@ -0,0 +1,66 @@
+package unit_of_work
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/k0marov/go-socnet/core/general/core_err"
+	"github.com/k0marov/go-socnet/core/general/static_store"
+)
+
+// Executor is implemented by both *sqlx.DB and *sqlx.Tx,
+// so sql stores that accept it can be used either on their own or as a part of a UnitOfWork
+type Executor interface {
+	sqlx.Ext
+	Get(dest any, query string, args ...any) error
+	Select(dest any, query string, args ...any) error
+}
+
+// UnitOfWork groups db writes with static file writes, so that either all of them happen or none of them do
+type UnitOfWork struct {
+	Tx    *sqlx.Tx
+	Files *static_store.Staging
+}
+
+type (
+	Work       = func(uow UnitOfWork) error
+	Runner     = func(work Work) error
+	TxBeginner = func() (*sqlx.Tx, error)
+)
+
+// NewRunner returns a Runner which commits the unit of work only if the work succeeds.
+//
+// Staged files are moved into place before the transaction is committed,
+// so a crash in between can only leave some unreferenced files behind, never db rows referencing missing files.
+func NewRunner(beginTx TxBeginner, beginStaging static_store.StagingBeginner) Runner {
+	return func(work Work) error {
+		tx, err := beginTx()
+		if err != nil {
+			return core_err.Rethrow("beginning a transaction", err)
+		}
+		defer tx.Rollback()
+		files, err := beginStaging()
+		if err != nil {
+			return core_err.Rethrow("beginning file staging", err)
+		}
+		defer files.Discard()
+
+		err = work(UnitOfWork{Tx: tx, Files: files})
+		if err != nil {
+			return err
+		}
+
+		err = files.Commit()
+		if err != nil {
+			return core_err.Rethrow("committing staged files", err)
+		}
+		err = tx.Commit()
+		if err != nil {
+			files.Revert()
+			return core_err.Rethrow("committing a transaction", err)
+		}
+		return nil
+	}
+}
+
+func NewRunnerImpl(db *sqlx.DB, staticDir string) Runner {
+	return NewRunner(db.Beginx, static_store.NewStagingBeginnerImpl(staticDir))
+}
@@ -0,0 +1,70 @@
+package unit_of_work_test
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/k0marov/go-socnet/core/general/core_values/ref"
+	"github.com/k0marov/go-socnet/core/general/unit_of_work"
+	. "github.com/k0marov/go-socnet/core/helpers/test_helpers"
+	_ "github.com/mattn/go-sqlite3"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRunner(t *testing.T) {
+	staticDir := t.TempDir()
+
+	db := OpenTestDB(t)
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS UnitOfWorkTest(value TEXT NOT NULL)`)
+	AssertNoError(t, err)
+	runInUnit := unit_of_work.NewRunnerImpl(db, staticDir)
+
+	doWork := func(uow unit_of_work.UnitOfWork) (value string, path string) {
+		value = RandomString()
+		_, err := uow.Tx.Exec(uow.Tx.Rebind(`INSERT INTO UnitOfWorkTest(value) VALUES (?)`), value)
+		AssertNoError(t, err)
+		data := []byte(value)
+		dataRef, _ := ref.NewRef(&data)
+		path, err = uow.Files.CreateFile(dataRef, RandomString(), "file")
+		AssertNoError(t, err)
+		return
+	}
+	assertWorkDone := func(t testing.TB, value, path string, want bool) {
+		t.Helper()
+		var count int
+		err := db.Get(&count, db.Rebind(`SELECT COUNT(*) FROM UnitOfWorkTest WHERE value = ?`), value)
+		AssertNoError(t, err)
+		Assert(t, count == 1, want, "row was inserted")
+		_, err = os.Stat(filepath.Join(staticDir, path))
+		Assert(t, err == nil, want, "file was created")
+	}
+
+	t.Run("happy case - work succeeds", func(t *testing.T) {
+		var value, path string
+		err := runInUnit(func(uow unit_of_work.UnitOfWork) error {
+			value, path = doWork(uow)
+			return nil
+		})
+		AssertNoError(t, err)
+		assertWorkDone(t, value, path, true)
+	})
+	t.Run("error case - work fails", func(t *testing.T) {
+		var value, path string
+		wantErr := RandomError()
+		err := runInUnit(func(uow unit_of_work.UnitOfWork) error {
+			value, path = doWork(uow)
+			return wantErr
+		})
+		AssertError(t, err, wantErr)
+		assertWorkDone(t, value, path, false)
+	})
+	t.Run("error case - beginning a transaction fails", func(t *testing.T) {
+		beginTx := func() (*sqlx.Tx, error) {
+			return nil, RandomError()
+		}
+		err := unit_of_work.NewRunner(beginTx, nil)(func(unit_of_work.UnitOfWork) error {
+			panic("work should not be run")
+		})
+		AssertSomeError(t, err)
+	})
+}
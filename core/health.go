@@ -0,0 +1,35 @@
+package core
+
+import (
+	"github.com/k0marov/go-socnet/core/general/periodic"
+	"github.com/k0marov/go-socnet/core/helpers/http_helpers"
+	"net/http"
+)
+
+type JobStatusResponse struct {
+	Name          string `json:"name"`
+	Runs          int    `json:"runs"`
+	LastRun       int64  `json:"last_run"`
+	LastRunFailed bool   `json:"last_run_failed"`
+}
+
+type HealthResponse struct {
+	Jobs []JobStatusResponse `json:"jobs"`
+}
+
+// NewHealthHandler reports the last run of every periodic job, LastRun is 0 if the job has not run yet.
+// It is served without auth, so the errors themselves are only logged by the jobs.
+func NewHealthHandler(jobs []*periodic.Job) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		resp := HealthResponse{Jobs: []JobStatusResponse{}}
+		for _, job := range jobs {
+			status := job.Status()
+			jobResp := JobStatusResponse{Name: job.Name(), Runs: status.Runs, LastRunFailed: status.LastErr != nil}
+			if !status.LastRun.IsZero() {
+				jobResp.LastRun = status.LastRun.Unix()
+			}
+			resp.Jobs = append(resp.Jobs, jobResp)
+		}
+		http_helpers.WriteJson(w, resp)
+	}
+}
@@ -0,0 +1,32 @@
+package core_test
+
+import (
+	"context"
+	"github.com/k0marov/go-socnet/core"
+	"github.com/k0marov/go-socnet/core/general/periodic"
+	. "github.com/k0marov/go-socnet/core/helpers/http_test_helpers"
+	. "github.com/k0marov/go-socnet/core/helpers/test_helpers"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHealthHandler(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	failedJob := periodic.NewJob(RandomString(), time.Hour, 0, func(context.Context) error {
+		cancel()
+		return RandomError()
+	})
+	failedJob.Run(ctx)
+	notRunJob := periodic.NewJob(RandomString(), time.Hour, 0, nil)
+
+	response := httptest.NewRecorder()
+	core.NewHealthHandler([]*periodic.Job{failedJob, notRunJob}).ServeHTTP(response, CreateRequest(nil))
+
+	AssertStatusCode(t, response, 200)
+	wantResponse := core.HealthResponse{Jobs: []core.JobStatusResponse{
+		{Name: failedJob.Name(), Runs: 1, LastRun: failedJob.Status().LastRun.Unix(), LastRunFailed: true},
+		{Name: notRunJob.Name()},
+	}}
+	AssertJSONData(t, response, wantResponse)
+}
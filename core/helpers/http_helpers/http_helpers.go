@@ -6,6 +6,7 @@ import (
 	"github.com/k0marov/go-socnet/core/general/core_entities"
 	"github.com/k0marov/go-socnet/core/general/core_values"
 	"github.com/k0marov/go-socnet/core/general/core_values/ref"
+	"github.com/k0marov/go-socnet/core/general/pagination"
 	"io"
 	"log"
 	"net/http"
@@ -63,3 +64,12 @@ func ParseFile(r *http.Request, field string) (core_values.FileData, bool) {
 	}
 	return dataRef, true
 }
+
+func GetPageOrThrowClientError(w http.ResponseWriter, r *http.Request) (pagination.Page, bool) {
+	page, err := pagination.NewPage(r.URL.Query().Get("limit"), r.URL.Query().Get("cursor"))
+	if err != nil {
+		HandleServiceError(w, err)
+		return pagination.Page{}, false
+	}
+	return page, true
+}
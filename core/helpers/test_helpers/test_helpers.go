@@ -4,17 +4,25 @@ import (
 	"encoding/json"
 	"errors"
 	"github.com/jmoiron/sqlx"
+	likeable_values "github.com/k0marov/go-socnet/core/abstract/likeable/values"
+	mentionable_values "github.com/k0marov/go-socnet/core/abstract/mentionable/values"
 	likeable_contexters "github.com/k0marov/go-socnet/core/abstract/ownable_likeable/contexters"
 	"github.com/k0marov/go-socnet/core/general/client_errors"
+	"github.com/k0marov/go-socnet/core/general/config"
 	"github.com/k0marov/go-socnet/core/general/core_entities"
 	"github.com/k0marov/go-socnet/core/general/core_values"
 	"github.com/k0marov/go-socnet/core/general/core_values/ref"
+	"github.com/k0marov/go-socnet/core/general/database"
+	"github.com/k0marov/go-socnet/core/general/migrations"
 	"math"
 	random "math/rand"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"reflect"
 	"strconv"
+	"sync"
 	"testing"
 	"time"
 
@@ -166,6 +174,15 @@ func RandomContextedPost() post_entities.ContextedPost {
 		Post:           RandomPost(),
 		Author:         RandomContextedProfile(),
 		OwnLikeContext: RandomLikeableContext(),
+		LatestComments: []post_entities.CommentPreview{RandomCommentPreview(), RandomCommentPreview()},
+	}
+}
+func RandomCommentPreview() post_entities.CommentPreview {
+	return post_entities.CommentPreview{
+		Id:        RandomId(),
+		Author:    RandomContextedProfile(),
+		Text:      RandomString(),
+		CreatedAt: RandomTime().Unix(),
 	}
 }
 func RandomNewPostData() post_values.NewPostData {
@@ -178,11 +195,24 @@ func RandomNewPostData() post_values.NewPostData {
 
 func RandomLikeableContext() likeable_contexters.OwnLikeContext {
 	return likeable_contexters.OwnLikeContext{
-		IsMine:  RandomBool(),
-		IsLiked: RandomBool(),
+		IsMine:   RandomBool(),
+		IsLiked:  true,
+		Reaction: RandomReaction(),
 	}
 }
 
+func RandomReaction() likeable_values.Reaction {
+	return likeable_values.Reactions[rand.Intn(len(likeable_values.Reactions))]
+}
+
+func RandomReactionCounts() likeable_values.ReactionCounts {
+	counts := likeable_values.ReactionCounts{}
+	for _, reaction := range likeable_values.Reactions {
+		counts[reaction] = RandomInt()
+	}
+	return counts
+}
+
 func RandomTime() time.Time {
 	return time.Date(2022, 6, 17, 16, 53, 42, 0, time.UTC)
 }
@@ -193,6 +223,7 @@ func RandomPostModel() post_models.PostModel {
 		AuthorId:  RandomString(),
 		Text:      RandomString(),
 		CreatedAt: RandomTime().Unix(),
+		EditedAt:  RandomTime().Unix(),
 		Images:    RandomPostImageModels(),
 	}
 }
@@ -204,22 +235,86 @@ func RandomPostImageModels() []post_models.PostImageModel {
 	}
 }
 func RandomPost() post_entities.Post {
+	reactions := RandomReactionCounts()
 	return post_entities.Post{
-		PostModel: RandomPostModel(),
-		Images:    RandomPostImages(),
-		Likes:     RandomInt(),
+		PostModel:     RandomPostModel(),
+		Images:        RandomPostImages(),
+		Likes:         reactions.Total(),
+		Reactions:     reactions,
+		CommentsCount: RandomInt(),
+		Mentions:      RandomMentions(),
 	}
 }
 
-func OpenSqliteDB(t testing.TB) *sqlx.DB {
+// TestPostgresDSNEnv can be set to run the tests against a postgres db instead of sqlite.
+// All data in the public schema of that db is dropped, and the packages should be tested one at a time (go test -p 1).
+const TestPostgresDSNEnv = "SOCIO_TEST_POSTGRES_DSN"
+
+var resetPostgres sync.Once
+
+// OpenTestDB opens a migrated db which is shared by all calls in the same test binary
+func OpenTestDB(t testing.TB) *sqlx.DB {
 	t.Helper()
-	sql, err := sqlx.Open("sqlite3", "file::memory:?cache=shared")
+	driver, dsn := database.SQLite, "file::memory:?cache=shared"
+	if pgDSN, exists := os.LookupEnv(TestPostgresDSNEnv); exists {
+		driver, dsn = database.Postgres, pgDSN
+	}
+	sql, err := database.Open(driver, dsn)
 	if err != nil {
-		t.Fatalf("error while opening in-memory database: %v", err)
+		t.Fatalf("error while opening test database: %v", err)
+	}
+	if driver == database.Postgres {
+		resetPostgres.Do(func() {
+			_, err = sql.Exec(`DROP SCHEMA public CASCADE; CREATE SCHEMA public;`)
+		})
+		if err != nil {
+			t.Fatalf("error while resetting postgres test database: %v", err)
+		}
+	}
+	err = migrations.Migrate(sql)
+	if err != nil {
+		t.Fatalf("error while migrating test database: %v", err)
 	}
 	return sql
 }
 
+// OpenTestFileDB opens a migrated sqlite db stored in a temporary file, so that it uses a pool of connections like a real one.
+// If TestPostgresDSNEnv is set, it is the same as OpenTestDB.
+func OpenTestFileDB(t testing.TB) *sqlx.DB {
+	t.Helper()
+	if _, exists := os.LookupEnv(TestPostgresDSNEnv); exists {
+		return OpenTestDB(t)
+	}
+	sql, err := database.Open(database.SQLite, filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("error while opening test database: %v", err)
+	}
+	t.Cleanup(func() { sql.Close() })
+	err = migrations.Migrate(sql)
+	if err != nil {
+		t.Fatalf("error while migrating test database: %v", err)
+	}
+	return sql
+}
+
+// SerialPrimaryKey returns the definition of an auto incremented integer primary key in the dialect of db.
+// It is used by tests which create their own tables.
+func SerialPrimaryKey(db *sqlx.DB) string {
+	if db.DriverName() == database.Postgres {
+		return "SERIAL PRIMARY KEY"
+	}
+	return "INTEGER PRIMARY KEY"
+}
+
+// TestConfig returns the default config with static and private files stored in temporary directories
+func TestConfig(t testing.TB) config.Config {
+	cfg := config.Default()
+	cfg.Static.Dir = t.TempDir()
+	cfg.Static.Host = "static.host"
+	cfg.Static.PrivateDir = t.TempDir()
+	return cfg
+}
+
 func TimeAlmostEqual(t, want time.Time) bool {
 	return math.Abs(t.Sub(want).Minutes()) < 1
 }
@@ -256,15 +351,23 @@ func RandomContextedComments() []comment_entities.ContextedComment {
 func RandomCommentModel() comment_models.CommentModel {
 	return comment_models.CommentModel{
 		Id:        RandomId(),
+		PostId:    RandomId(),
+		ParentId:  RandomId(),
 		AuthorId:  RandomString(),
 		Text:      RandomString(),
 		CreatedAt: RandomTime().Unix(),
+		EditedAt:  RandomTime().Unix(),
+		DeletedAt: RandomTime().Unix(),
+		Replies:   RandomInt(),
 	}
 }
 func RandomComment() comment_entities.Comment {
+	reactions := RandomReactionCounts()
 	return comment_entities.Comment{
 		CommentModel: RandomCommentModel(),
-		Likes:        RandomInt(),
+		Likes:        reactions.Total(),
+		Reactions:    reactions,
+		Mentions:     RandomMentions(),
 	}
 }
 
@@ -280,6 +383,13 @@ func RandomBool() bool {
 	return rand.Float32() > 0.5
 }
 
+func RandomMentions() []mentionable_values.Mention {
+	return []mentionable_values.Mention{
+		{Profile: RandomId(), Offset: 0, Length: 1 + RandomInt()},
+		{Profile: RandomId(), Offset: 200 + RandomInt(), Length: 1 + RandomInt()},
+	}
+}
+
 func RandomInt() int {
 	return rand.Intn(100)
 }
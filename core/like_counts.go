@@ -0,0 +1,38 @@
+package core
+
+import (
+	"context"
+	"github.com/jmoiron/sqlx"
+	"github.com/k0marov/go-socnet/core/abstract/likeable"
+	"github.com/k0marov/go-socnet/core/general/core_err"
+	"github.com/k0marov/go-socnet/features/comments"
+	"github.com/k0marov/go-socnet/features/posts"
+	"github.com/k0marov/go-socnet/features/profiles"
+	"log"
+)
+
+// NewLikeCountsRebuilder returns a function which rebuilds the stored likes counters of every Likeable from scratch,
+// dropping the counters of deleted targets. It is run periodically to reconcile counters that drifted
+// (e.g. the ones left by targets deleted before migration 0015) and can be run manually with deploy/rebuild_like_counts.
+func NewLikeCountsRebuilder(db *sqlx.DB) func(ctx context.Context) error {
+	rebuilders := []struct {
+		likeable string
+		rebuild  likeable.CountsRebuilder
+	}{
+		{"profiles", profiles.NewLikeCountsRebuilderImpl(db)},
+		{"posts", posts.NewLikeCountsRebuilderImpl(db)},
+		{"comments", comments.NewLikeCountsRebuilderImpl(db)},
+	}
+	return func(ctx context.Context) error {
+		for _, r := range rebuilders {
+			outOfSync, err := r.rebuild(ctx)
+			if err != nil {
+				return core_err.Rethrow("rebuilding likes counters of "+r.likeable, err)
+			}
+			if outOfSync != 0 {
+				log.Printf("rebuilt %v out of sync likes counters of %v", outOfSync, r.likeable)
+			}
+		}
+		return nil
+	}
+}
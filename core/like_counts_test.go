@@ -0,0 +1,38 @@
+package core_test
+
+import (
+	"context"
+	"github.com/k0marov/go-socnet/core"
+	. "github.com/k0marov/go-socnet/core/helpers/test_helpers"
+	"testing"
+
+	"github.com/k0marov/go-socnet/features/profiles"
+	profiles_db "github.com/k0marov/go-socnet/features/profiles/store/sql_db"
+)
+
+func TestLikeCountsRebuilder(t *testing.T) {
+	t.Run("error case - db is closed", func(t *testing.T) {
+		db := OpenTestDB(t)
+		rebuild := core.NewLikeCountsRebuilder(db)
+		db.Close()
+		AssertSomeError(t, rebuild(context.Background()))
+	})
+	t.Run("happy case", func(t *testing.T) {
+		cfg := TestConfig(t)
+		db := OpenTestDB(t)
+		profilesDB, err := profiles_db.NewSqlDB(db)
+		AssertNoError(t, err)
+		target, follower := RandomProfileModel(), RandomProfileModel()
+		AssertNoError(t, profilesDB.CreateProfile(target))
+		AssertNoError(t, profilesDB.CreateProfile(follower))
+		_, err = db.Exec(db.Rebind(`INSERT INTO LikeableProfile(target_id, liker_id) VALUES (?, ?)`), target.Id, follower.Id)
+		AssertNoError(t, err)
+
+		AssertNoError(t, core.NewLikeCountsRebuilder(db)(context.Background()))
+
+		profile, err := profiles.NewProfileGetterImpl(cfg, db)(target.Id, follower.Id)
+		AssertNoError(t, err)
+		Assert(t, profile.Follows, 0, "follows count")
+		Assert(t, profile.Followers, 1, "followers count")
+	})
+}
@@ -1,60 +1,125 @@
 package core
 
 import (
+	"fmt"
 	"github.com/go-chi/chi/v5"
-	"github.com/jmoiron/sqlx"
+	"github.com/k0marov/go-socnet/core/general/config"
+	"github.com/k0marov/go-socnet/core/general/database"
+	"github.com/k0marov/go-socnet/core/general/events"
+	"github.com/k0marov/go-socnet/core/general/migrations"
 	"github.com/k0marov/go-socnet/core/general/periodic"
+	"github.com/k0marov/go-socnet/core/general/pubsub"
+	"github.com/k0marov/go-socnet/core/general/static_store"
 	"github.com/k0marov/go-socnet/features/comments"
 	"github.com/k0marov/go-socnet/features/feed"
+	"github.com/k0marov/go-socnet/features/messages"
+	"github.com/k0marov/go-socnet/features/notifications"
 	"github.com/k0marov/go-socnet/features/posts"
 	"github.com/k0marov/go-socnet/features/profiles"
+	"github.com/k0marov/go-socnet/features/search"
+	"github.com/k0marov/go-socnet/features/stream"
 	auth "github.com/k0marov/golang-auth"
 	"log"
-	"net/http"
 	"time"
 )
 
-const AuthHashCost = 8
+const (
+	recsUpdatePeriod = 1 * time.Minute
+	recsUpdateJitter = 10 * time.Second
 
-func Setup() http.Handler {
-	sql, err := sqlx.Open("sqlite3", "db.sqlite3")
+	likeCountsRebuildPeriod = 1 * time.Hour
+	likeCountsRebuildJitter = 5 * time.Minute
+
+	trendingTagsUpdatePeriod = 5 * time.Minute
+	trendingTagsUpdateJitter = 30 * time.Second
+	trendingTagsWindow       = 24 * time.Hour
+	trendingTagsCount        = 20
+
+	notificationsGroupWindow = 1 * time.Hour
+
+	streamBufferSize      = 64
+	streamHeartbeatPeriod = 30 * time.Second
+)
+
+// Setup expects cfg to be already validated
+func Setup(cfg config.Config) *App {
+	sql, err := database.Open(cfg.DB.Driver, cfg.DB.DSN)
 	if err != nil {
 		log.Fatalf("error while opening sql db: %v", err)
 	}
-	sql.Exec("PRAGMA foreign_keys = ON;")
+	err = migrations.Migrate(sql)
+	if err != nil {
+		log.Fatalf("error while migrating the db schema: %v", err)
+	}
+
+	// files staged by units of work which were interrupted by a crash are not referenced by anything
+	err = static_store.NewStaticDirDeleterImpl(cfg.Static.Dir)(static_store.StagingDirName)
+	if err != nil {
+		log.Fatalf("error while cleaning up the static files staging directory: %v", err)
+	}
+
+	// events
+	hub := pubsub.NewHub(streamBufferSize)
+	publish := events.NewPublisher(
+		notifications.NewEventNotifierImpl(sql, notificationsGroupWindow, stream.NewNotificationPusherImpl(hub)),
+		stream.NewEventStreamerImpl(hub, posts.NewReactionCountsGetterImpl(sql)),
+	)
 
 	// profiles
 	onNewRegister := profiles.NewRegisterCallback(sql)
-	profileGetter := profiles.NewProfileGetterImpl(sql)
-	profilesRouter := profiles.NewProfilesRouterImpl(sql)
+	profileGetter := profiles.NewProfileGetterImpl(cfg, sql)
+	profilesGetter := profiles.NewProfilesGetterImpl(cfg, sql)
+	profilesRouter := profiles.NewProfilesRouterImpl(cfg, sql, publish)
+	resolveUsernames := profiles.NewUsernamesResolverImpl(sql)
+
+	// comments
+	commentsRouter := comments.NewCommentsRouterImpl(cfg, sql, profileGetter, posts.NewPostModelGetterImpl(sql), resolveUsernames, publish)
+	getCommentCounts := comments.NewCommentCountsGetterImpl(sql)
+	getCommentPreviews := comments.NewCommentPreviewsGetterImpl(sql, profilesGetter)
 
 	// posts
-	postsRouter := posts.NewPostsRouterImpl(sql, profileGetter)
-	postRecommendable := posts.NewPostRecommendable(sql)
-	periodic.RunPeriodically(func() {
-		err := postRecommendable.UpdateRecs()
-		if err != nil {
-			log.Printf("while updating recommendations for posts: %v", err)
-		}
-	}, 1*time.Minute)
+	postsRouter := posts.NewPostsRouterImpl(cfg, sql, profileGetter, profilesGetter, getCommentCounts, getCommentPreviews, resolveUsernames, publish)
+	postRecommendable := posts.NewPostRecommendable(sql, profiles.FollowedTableName)
+	updatePostRecs := periodic.NewJob("updating recommendations for posts", recsUpdatePeriod, recsUpdateJitter, postRecommendable.UpdateRecs)
+
+	// tags
+	tagsRouter := posts.NewTagsRouterImpl(cfg, sql, profilesGetter, getCommentCounts, getCommentPreviews)
+	updateTrendingTags := posts.NewTrendingTagsUpdaterImpl(sql, trendingTagsWindow, trendingTagsCount)
+	updateTrending := periodic.NewJob("updating trending tags", trendingTagsUpdatePeriod, trendingTagsUpdateJitter, updateTrendingTags)
 
 	// feed
-	feedRouter := feed.NewFeedRouterImpl(sql, postRecommendable)
+	postListing := posts.NewPostListingImpl(cfg, sql, profilesGetter, getCommentCounts, getCommentPreviews)
+	feedRouter := feed.NewFeedRouterImpl(cfg, sql, postRecommendable, profiles.NewFollowIdsGetterImpl(sql), postListing)
 
-	// comments
-	commentsRouter := comments.NewCommentsRouterImpl(sql, profileGetter)
+	// search
+	searchRouter := search.NewSearchRouterImpl(sql, postListing, profilesGetter, comments.NewCommentListingImpl(sql, profileGetter))
+
+	// notifications
+	notificationsRouter := notifications.NewNotificationsRouterImpl(sql, profilesGetter)
+	streamRouter := stream.NewStreamRouterImpl(hub, streamHeartbeatPeriod)
+
+	// direct messages
+	conversationsRouter := messages.NewConversationsRouterImpl(cfg, sql, "/api/conversations", profileGetter, profilesGetter)
+
+	// likes counters
+	rebuildLikeCounts := NewLikeCountsRebuilder(sql)
+	reconcileLikeCounts := periodic.NewJob("reconciling likes counters", likeCountsRebuildPeriod, likeCountsRebuildJitter, rebuildLikeCounts)
 
 	// auth
-	authStore, err := auth.NewStoreImpl("auth.db.csv")
+	authStore, err := auth.NewStoreImpl(cfg.Auth.StorePath)
 	if err != nil {
 		log.Fatalf("error while opening auth store: %v", err)
 	}
-	loginHandler, registerHandler := auth.NewHandlersImpl(authStore, AuthHashCost, onNewRegister)
+	loginHandler, registerHandler := auth.NewHandlersImpl(authStore, cfg.Auth.HashCost, onNewRegister)
 	authMiddleware := auth.NewTokenAuthMiddleware(authStore).Middleware
 
+	jobs := []*periodic.Job{updatePostRecs, updateTrending, reconcileLikeCounts}
+
 	// routing
 	r := chi.NewRouter()
 
+	r.Get("/health", NewHealthHandler(jobs))
+
 	r.Route("/auth", func(r chi.Router) {
 		r.Post("/login", loginHandler.ServeHTTP)
 		r.Post("/register", registerHandler.ServeHTTP)
@@ -64,9 +129,17 @@ func Setup() http.Handler {
 		r.Use(authMiddleware)
 		r.Route("/profiles", profilesRouter)
 		r.Route("/posts", postsRouter)
+		r.Route("/tags", tagsRouter)
 		r.Route("/comments", commentsRouter)
 		r.Route("/feed", feedRouter)
+		r.Route("/search", searchRouter)
+		r.Route("/notifications", notificationsRouter)
+		r.Route("/stream", streamRouter)
+		r.Route("/conversations", conversationsRouter)
 	})
 
-	return r
+	app := NewApp(fmt.Sprintf(":%v", cfg.Server.Port), r, sql, jobs)
+	// the streams never end by themselves, so they have to be closed for the in-flight requests to be drained
+	app.OnShutdown(hub.Close)
+	return app
 }
@@ -1,15 +1,41 @@
 package main
 
 import (
-	"fmt"
+	"context"
 	"github.com/k0marov/go-socnet/core"
-	"net/http"
-
-	_ "github.com/mattn/go-sqlite3"
+	"github.com/k0marov/go-socnet/core/general/config"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 )
 
-const Port = 4242
-
 func main() {
-	http.ListenAndServe(fmt.Sprintf(":%v", Port), core.Setup())
+	cfg, err := config.Load(os.Args[1:], os.LookupEnv, os.ReadFile)
+	if err != nil {
+		log.Fatalf("error while loading the config: %v", err)
+	}
+	app := core.Setup(cfg)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- app.Start()
+	}()
+	select {
+	case err := <-serveErr:
+		log.Fatalf("error while serving: %v", err)
+	case <-ctx.Done():
+	}
+	stop() // a second signal kills the process right away
+
+	log.Printf("shutting down")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), time.Duration(cfg.Server.ShutdownTimeout)*time.Second)
+	defer cancel()
+	err = app.Shutdown(shutdownCtx)
+	if err != nil {
+		log.Fatalf("error while shutting down: %v", err)
+	}
 }
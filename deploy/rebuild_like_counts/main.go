@@ -0,0 +1,33 @@
+// rebuild_like_counts recomputes all stored likes counters from the likes themselves.
+// It accepts the same configuration as the server.
+package main
+
+import (
+	"context"
+	"github.com/k0marov/go-socnet/core"
+	"github.com/k0marov/go-socnet/core/general/config"
+	"github.com/k0marov/go-socnet/core/general/database"
+	"github.com/k0marov/go-socnet/core/general/migrations"
+	"log"
+	"os"
+)
+
+func main() {
+	cfg, err := config.Load(os.Args[1:], os.LookupEnv, os.ReadFile)
+	if err != nil {
+		log.Fatalf("error while loading the config: %v", err)
+	}
+	sql, err := database.Open(cfg.DB.Driver, cfg.DB.DSN)
+	if err != nil {
+		log.Fatalf("error while opening sql db: %v", err)
+	}
+	err = migrations.Migrate(sql)
+	if err != nil {
+		log.Fatalf("error while migrating the db schema: %v", err)
+	}
+	err = core.NewLikeCountsRebuilder(sql)(context.Background())
+	if err != nil {
+		log.Fatalf("error while rebuilding likes counters: %v", err)
+	}
+	log.Printf("rebuilt likes counters")
+}
@@ -2,11 +2,14 @@ package comments
 
 import (
 	"github.com/jmoiron/sqlx"
-	"github.com/k0marov/go-socnet/core/abstract/deletable"
 	"github.com/k0marov/go-socnet/core/abstract/likeable"
+	"github.com/k0marov/go-socnet/core/abstract/mentionable"
 	"github.com/k0marov/go-socnet/core/abstract/ownable"
 	"github.com/k0marov/go-socnet/core/abstract/ownable_likeable"
 	likeable_contexters "github.com/k0marov/go-socnet/core/abstract/ownable_likeable/contexters"
+	"github.com/k0marov/go-socnet/core/general/config"
+	"github.com/k0marov/go-socnet/core/general/events"
+	"github.com/k0marov/go-socnet/core/general/unit_of_work"
 	"log"
 
 	"github.com/go-chi/chi/v5"
@@ -14,13 +17,75 @@ import (
 	"github.com/k0marov/go-socnet/features/comments/delivery/http/router"
 	"github.com/k0marov/go-socnet/features/comments/domain/contexters"
 	"github.com/k0marov/go-socnet/features/comments/domain/service"
+	store_contracts "github.com/k0marov/go-socnet/features/comments/domain/store"
 	"github.com/k0marov/go-socnet/features/comments/domain/validators"
 	"github.com/k0marov/go-socnet/features/comments/store"
 	"github.com/k0marov/go-socnet/features/comments/store/sql_db"
+	post_contexters "github.com/k0marov/go-socnet/features/posts/domain/contexters"
+	post_store "github.com/k0marov/go-socnet/features/posts/domain/store"
 	profile_service "github.com/k0marov/go-socnet/features/profiles/domain/service"
 )
 
-func NewCommentsRouterImpl(db *sqlx.DB, getProfile profile_service.ProfileGetter) func(chi.Router) {
+// NewLikeCountsRebuilderImpl returns a rebuilder of the stored comment likes counters
+func NewLikeCountsRebuilderImpl(db *sqlx.DB) likeable.CountsRebuilder {
+	sqlDB, err := sql_db.NewSqlDB(db)
+	if err != nil {
+		log.Fatalf("error while opening sql db for comments: %v", err)
+	}
+	likeableComment, err := likeable.NewLikeable(db, sqlDB.TableName)
+	if err != nil {
+		log.Fatalf("error while creating comment likeable: %v", err)
+	}
+	return likeableComment.RebuildCounts
+}
+
+// NewCommentCountsGetterImpl is used by posts to show the number of comments of every post
+func NewCommentCountsGetterImpl(db *sqlx.DB) post_store.CommentCountsGetter {
+	sqlDB, err := sql_db.NewSqlDB(db)
+	if err != nil {
+		log.Fatalf("error while opening sql db for comments: %v", err)
+	}
+	return store.NewCommentCountsGetter(sqlDB.GetCommentCounts)
+}
+
+// NewCommentPreviewsGetterImpl is used by posts to show their latest comments
+func NewCommentPreviewsGetterImpl(db *sqlx.DB, getProfiles profile_service.ProfilesGetter) post_contexters.CommentPreviewsGetter {
+	sqlDB, err := sql_db.NewSqlDB(db)
+	if err != nil {
+		log.Fatalf("error while opening sql db for comments: %v", err)
+	}
+	return service.NewPostCommentPreviewsGetter(store.NewLatestCommentsGetter(sqlDB.GetLatestComments), getProfiles)
+}
+
+type CommentListing struct {
+	GetByIds   store_contracts.CommentsByIdsGetter
+	AddContext contexters.CommentListContextAdder
+}
+
+// NewCommentListingImpl is used by other features that list comments
+func NewCommentListingImpl(db *sqlx.DB, getProfile profile_service.ProfileGetter) CommentListing {
+	sqlDB, err := sql_db.NewSqlDB(db)
+	if err != nil {
+		log.Fatalf("error while opening sql db for comments: %v", err)
+	}
+	likeableComment, err := likeable.NewLikeable(db, sqlDB.TableName)
+	if err != nil {
+		log.Fatalf("error while creating comment likeable: %v", err)
+	}
+	mentionableComment, err := mentionable.NewMentionable(db, sqlDB.TableName)
+	if err != nil {
+		log.Fatalf("error while creating comment mentionable: %v", err)
+	}
+	addContext := contexters.NewCommentContextAdder(getProfile, likeable_contexters.NewOwnLikeContextGetter(likeableComment.GetReaction))
+	return CommentListing{
+		GetByIds:   store.NewCommentsByIdsGetter(sqlDB.GetCommentsByIds, likeableComment.GetReactionCounts, mentionableComment.GetMentionsBatch),
+		AddContext: contexters.NewCommentListContextAdder(addContext),
+	}
+}
+
+// NewCommentsRouterImpl gets the commented posts from the posts feature to check whether commenting is disabled and who moderates the comments.
+// It publishes the events of commenting, replying, mentioning and liking comments.
+func NewCommentsRouterImpl(cfg config.Config, db *sqlx.DB, getProfile profile_service.ProfileGetter, getPost post_store.PostModelGetter, resolveUsernames mentionable.UsernamesResolver, publish events.Publisher) func(chi.Router) {
 	// db
 	sqlDB, err := sql_db.NewSqlDB(db)
 	if err != nil {
@@ -36,31 +101,53 @@ func NewCommentsRouterImpl(db *sqlx.DB, getProfile profile_service.ProfileGetter
 	if err != nil {
 		log.Fatalf("error while creating comment ownable: %v", err)
 	}
-	// ownable-likeable
-	ownableLikeableComment := ownable_likeable.NewOwnableLikeable(ownableComment.GetOwner, likeableComment.ToggleLike)
-
-	// deletable
-	deletableComment, err := deletable.NewDeletable(db, sqlDB.TableName, ownableComment.GetOwner)
+	// mentionable
+	mentionableComment, err := mentionable.NewMentionable(db, sqlDB.TableName)
 	if err != nil {
-		log.Fatalf("error while creating comment deletable: %v", err)
+		log.Fatalf("error while creating comment mentionable: %v", err)
 	}
+	// ownable-likeable
+	ownableLikeableComment := ownable_likeable.NewOwnableLikeable(ownableComment.GetOwner, likeableComment.ToggleLike, likeableComment.Like, likeableComment.Unlike, likeableComment.SetReaction, events.Comment, publish)
 
 	// store
-	storeCreateComment := store.NewCommentCreator(sqlDB.Create)
-	storeGetComments := store.NewCommentsGetter(sqlDB.GetComments, likeableComment.GetLikesCount)
+	runInUnit := unit_of_work.NewRunnerImpl(db, cfg.Static.Dir)
+	storeCreateComment := store.NewCommentCreator(runInUnit, sqlDB.Create, mentionableComment.AddMentions)
+	storeGetComments := store.NewCommentsGetter(sqlDB.GetComments, likeableComment.GetReactionCounts, mentionableComment.GetMentionsBatch)
+	storeGetReplies := store.NewRepliesGetter(sqlDB.GetReplies, likeableComment.GetReactionCounts, mentionableComment.GetMentionsBatch)
+	storeGetComment := store.NewCommentGetter(sqlDB.GetComment, likeableComment.GetReactionCounts, mentionableComment.GetMentionsBatch)
+	storeUpdateComment := store.NewCommentUpdater(runInUnit, sqlDB.Update, mentionableComment.ReplaceMentions)
+	storeDeleteComment := store.NewCommentDeleter(runInUnit, sqlDB.Delete, mentionableComment.ReplaceMentions)
 
 	// service
-	validator := validators.NewCommentValidator()
-	contextAdder := contexters.NewCommentListContextAdder(contexters.NewCommentContextAdder(getProfile, likeable_contexters.NewOwnLikeContextGetter(likeableComment.IsLiked)))
+	validator := validators.NewCommentValidator(cfg.Limits.MaxCommentTextLength)
+	resolveMentions := mentionable.NewMentionsResolver(resolveUsernames)
+	commentContextAdder := contexters.NewCommentContextAdder(getProfile, likeable_contexters.NewOwnLikeContextGetter(likeableComment.GetReaction))
+	contextAdder := contexters.NewCommentListContextAdder(commentContextAdder)
 
 	getComments := service.NewPostCommentsGetter(storeGetComments, contextAdder)
-	createComment := service.NewCommentCreator(validator, getProfile, storeCreateComment)
+	getReplies := service.NewCommentRepliesGetter(storeGetComment, storeGetReplies, contextAdder)
+	createComment := service.NewCommentCreator(validator, getPost, getProfile, storeGetComment, resolveMentions, storeCreateComment, publish)
+	getComment := service.NewCommentGetter(storeGetComment, commentContextAdder)
+	updateComment := service.NewCommentUpdater(ownableComment.GetOwner, validator, resolveMentions, storeUpdateComment, getComment)
 	toggleLike := service.NewCommentLikeToggler(ownableLikeableComment.SafeToggleLike)
-	delete := service.NewCommentDeleter(deletableComment.Delete)
+	like := service.NewCommentLiker(ownableLikeableComment.SafeLike)
+	unlike := service.NewCommentUnliker(ownableLikeableComment.SafeUnlike)
+	setReaction := service.NewCommentReactionSetter(ownableLikeableComment.SafeSetReaction)
+	removeReaction := service.NewCommentReactionRemover(ownableLikeableComment.SafeUnlike)
+	getLikers := service.NewCommentLikersGetter(ownableComment.GetOwner, profile_service.NewLikersGetter(likeableComment.GetLikers, getProfile))
+	delete := service.NewCommentDeleter(storeGetComment, getPost, storeDeleteComment)
 	// handlers
 	getCommentsHandler := handlers.NewGetCommentsHandler(getComments)
 	createCommentHandler := handlers.NewCreateCommentHandler(createComment)
+	getCommentHandler := handlers.NewGetCommentHandler(getComment)
+	getRepliesHandler := handlers.NewGetRepliesHandler(getReplies)
+	updateCommentHandler := handlers.NewUpdateCommentHandler(updateComment)
 	toggleLikeHandler := handlers.NewToggleLikeCommentHandler(toggleLike)
+	likeHandler := handlers.NewLikeCommentHandler(like)
+	unlikeHandler := handlers.NewUnlikeCommentHandler(unlike)
+	setReactionHandler := handlers.NewSetCommentReactionHandler(setReaction)
+	removeReactionHandler := handlers.NewRemoveCommentReactionHandler(removeReaction)
+	getLikersHandler := handlers.NewGetCommentLikersHandler(getLikers)
 	deleteHandler := handlers.NewDeleteCommentHandler(delete)
-	return router.NewCommentsRouter(getCommentsHandler, createCommentHandler, toggleLikeHandler, deleteHandler)
+	return router.NewCommentsRouter(getCommentsHandler, createCommentHandler, getCommentHandler, getRepliesHandler, updateCommentHandler, toggleLikeHandler, likeHandler, unlikeHandler, setReactionHandler, removeReactionHandler, getLikersHandler, deleteHandler)
 }
@@ -2,7 +2,9 @@ package handlers
 
 import (
 	"encoding/json"
+	likeable_values "github.com/k0marov/go-socnet/core/abstract/likeable/values"
 	"github.com/k0marov/go-socnet/core/general/client_errors"
+	"github.com/k0marov/go-socnet/core/general/core_values"
 	"github.com/k0marov/go-socnet/core/helpers/http_helpers"
 	"net/http"
 
@@ -10,12 +12,22 @@ import (
 	"github.com/k0marov/go-socnet/features/comments/delivery/http/responses"
 	"github.com/k0marov/go-socnet/features/comments/domain/service"
 	"github.com/k0marov/go-socnet/features/comments/domain/values"
+	profile_responses "github.com/k0marov/go-socnet/features/profiles/delivery/http/responses"
 )
 
 type NewCommentRequest struct {
+	Text     string `json:"text"`
+	ParentId string `json:"parent_id"`
+}
+
+type UpdateCommentRequest struct {
 	Text string `json:"text"`
 }
 
+type SetReactionRequest struct {
+	Reaction likeable_values.Reaction `json:"reaction"`
+}
+
 func NewGetCommentsHandler(getComments service.PostCommentsGetter) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		caller, ok := http_helpers.GetUserOrAddUnauthorized(w, r)
@@ -27,12 +39,40 @@ func NewGetCommentsHandler(getComments service.PostCommentsGetter) http.HandlerF
 			http_helpers.ThrowClientError(w, client_errors.IdNotProvided)
 			return
 		}
-		comments, err := getComments(postId, caller.Id)
+		page, ok := http_helpers.GetPageOrThrowClientError(w, r)
+		if !ok {
+			return
+		}
+		comments, err := getComments(postId, caller.Id, page)
+		if err != nil {
+			http_helpers.HandleServiceError(w, err)
+			return
+		}
+		http_helpers.WriteJson(w, responses.NewCommentListResponse(comments, page))
+	}
+}
+
+func NewGetRepliesHandler(getReplies service.CommentRepliesGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		caller, ok := http_helpers.GetUserOrAddUnauthorized(w, r)
+		if !ok {
+			return
+		}
+		commentId := chi.URLParam(r, "id")
+		if commentId == "" {
+			http_helpers.ThrowClientError(w, client_errors.IdNotProvided)
+			return
+		}
+		page, ok := http_helpers.GetPageOrThrowClientError(w, r)
+		if !ok {
+			return
+		}
+		replies, err := getReplies(commentId, caller.Id, page)
 		if err != nil {
 			http_helpers.HandleServiceError(w, err)
 			return
 		}
-		http_helpers.WriteJson(w, responses.NewCommentListResponse(comments))
+		http_helpers.WriteJson(w, responses.NewCommentListResponse(replies, page))
 	}
 }
 
@@ -53,6 +93,7 @@ func NewCreateCommentHandler(createComment service.CommentCreator) http.HandlerF
 			Text:   commentData.Text,
 			Author: caller.Id,
 			Post:   postId,
+			Parent: commentData.ParentId,
 		}
 		createdComment, err := createComment(newComment)
 		if err != nil {
@@ -63,7 +104,113 @@ func NewCreateCommentHandler(createComment service.CommentCreator) http.HandlerF
 	}
 }
 
+func NewGetCommentHandler(getComment service.CommentGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		caller, ok := http_helpers.GetUserOrAddUnauthorized(w, r)
+		if !ok {
+			return
+		}
+		commentId := chi.URLParam(r, "id")
+		if commentId == "" {
+			http_helpers.ThrowClientError(w, client_errors.IdNotProvided)
+			return
+		}
+		comment, err := getComment(commentId, caller.Id)
+		if err != nil {
+			http_helpers.HandleServiceError(w, err)
+			return
+		}
+		http_helpers.WriteJson(w, responses.NewCommentResponse(comment))
+	}
+}
+
+func NewUpdateCommentHandler(updateComment service.CommentUpdater) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		caller, ok := http_helpers.GetUserOrAddUnauthorized(w, r)
+		if !ok {
+			return
+		}
+		commentId := chi.URLParam(r, "id")
+		if commentId == "" {
+			http_helpers.ThrowClientError(w, client_errors.IdNotProvided)
+			return
+		}
+		var updateData UpdateCommentRequest
+		err := json.NewDecoder(r.Body).Decode(&updateData)
+		if err != nil {
+			http_helpers.ThrowClientError(w, client_errors.InvalidJsonError)
+			return
+		}
+		updatedComment, err := updateComment(commentId, caller.Id, updateData.Text)
+		if err != nil {
+			http_helpers.HandleServiceError(w, err)
+			return
+		}
+		http_helpers.WriteJson(w, responses.NewCommentResponse(updatedComment))
+	}
+}
+
 func NewToggleLikeCommentHandler(toggleLike service.CommentLikeToggler) http.HandlerFunc {
+	return newLikeActionHandler(toggleLike)
+}
+
+func NewLikeCommentHandler(like service.CommentLiker) http.HandlerFunc {
+	return newLikeActionHandler(like)
+}
+
+func NewUnlikeCommentHandler(unlike service.CommentUnliker) http.HandlerFunc {
+	return newLikeActionHandler(unlike)
+}
+
+func NewSetCommentReactionHandler(setReaction service.CommentReactionSetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		caller, ok := http_helpers.GetUserOrAddUnauthorized(w, r)
+		if !ok {
+			return
+		}
+		commentId := chi.URLParam(r, "id")
+		if commentId == "" {
+			http_helpers.ThrowClientError(w, client_errors.IdNotProvided)
+			return
+		}
+		var req SetReactionRequest
+		err := json.NewDecoder(r.Body).Decode(&req)
+		if err != nil {
+			http_helpers.ThrowClientError(w, client_errors.InvalidJsonError)
+			return
+		}
+		err = setReaction(commentId, caller.Id, req.Reaction)
+		if err != nil {
+			http_helpers.HandleServiceError(w, err)
+			return
+		}
+	}
+}
+
+func NewRemoveCommentReactionHandler(removeReaction service.CommentReactionRemover) http.HandlerFunc {
+	return newLikeActionHandler(removeReaction)
+}
+
+func newLikeActionHandler(action func(values.CommentId, core_values.UserId) error) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		caller, ok := http_helpers.GetUserOrAddUnauthorized(w, r)
+		if !ok {
+			return
+		}
+		commentId := chi.URLParam(r, "id")
+		if commentId == "" {
+			http_helpers.ThrowClientError(w, client_errors.IdNotProvided)
+			return
+		}
+		err := action(commentId, caller.Id)
+		if err != nil {
+			http_helpers.HandleServiceError(w, err)
+			return
+		}
+	}
+}
+
+func NewGetCommentLikersHandler(getLikers service.CommentLikersGetter) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		caller, ok := http_helpers.GetUserOrAddUnauthorized(w, r)
 		if !ok {
@@ -74,11 +221,16 @@ func NewToggleLikeCommentHandler(toggleLike service.CommentLikeToggler) http.Han
 			http_helpers.ThrowClientError(w, client_errors.IdNotProvided)
 			return
 		}
-		err := toggleLike(commentId, caller.Id)
+		page, ok := http_helpers.GetPageOrThrowClientError(w, r)
+		if !ok {
+			return
+		}
+		likers, next, err := getLikers(commentId, caller.Id, page)
 		if err != nil {
 			http_helpers.HandleServiceError(w, err)
 			return
 		}
+		http_helpers.WriteJson(w, profile_responses.NewProfilesResponse(likers, next))
 	}
 }
 
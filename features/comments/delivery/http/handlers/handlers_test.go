@@ -4,10 +4,14 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	likeable_values "github.com/k0marov/go-socnet/core/abstract/likeable/values"
 	"github.com/k0marov/go-socnet/core/general/client_errors"
 	"github.com/k0marov/go-socnet/core/general/core_values"
+	"github.com/k0marov/go-socnet/core/general/pagination"
 	helpers "github.com/k0marov/go-socnet/core/helpers/http_test_helpers"
 	. "github.com/k0marov/go-socnet/core/helpers/test_helpers"
+	profile_responses "github.com/k0marov/go-socnet/features/profiles/delivery/http/responses"
+	profile_entities "github.com/k0marov/go-socnet/features/profiles/domain/entities"
 	"io"
 	"net/http"
 	"net/http/httptest"
@@ -31,18 +35,29 @@ func TestNewGetCommentsHandler(t *testing.T) {
 	comments := []entities.ContextedComment{RandomContextedComment()}
 	caller := RandomAuthUser()
 
+	cursor := pagination.Cursor{CreatedAt: RandomTime().Unix(), Id: RandomId()}
+
 	helpers.BaseTest401(t, handlers.NewGetCommentsHandler(nil))
 	t.Run("happy case", func(t *testing.T) {
-		getter := func(postId post_values.PostId, callerId core_values.UserId) ([]entities.ContextedComment, error) {
-			if postId == post && callerId == caller.Id {
+		wantPage := pagination.Page{After: cursor, Limit: 1}
+		getter := func(postId post_values.PostId, callerId core_values.UserId, page pagination.Page) ([]entities.ContextedComment, error) {
+			if postId == post && callerId == caller.Id && page == wantPage {
 				return comments, nil
 			}
 			panic("unexpected args")
 		}
 		response := httptest.NewRecorder()
-		request := helpers.AddAuthDataToRequest(createRequestWithPostId(post, nil), caller)
+		request := httptest.NewRequest(http.MethodOptions, "/handler-should-not-care?post_id="+post+"&limit=1&cursor="+cursor.Encode(), nil)
+		request = helpers.AddAuthDataToRequest(request, caller)
 		handlers.NewGetCommentsHandler(getter).ServeHTTP(response, request)
-		AssertJSONData(t, response, responses.NewCommentListResponse(comments))
+		AssertJSONData(t, response, responses.NewCommentListResponse(comments, wantPage))
+	})
+	t.Run("error case - cursor is invalid", func(t *testing.T) {
+		response := httptest.NewRecorder()
+		request := httptest.NewRequest(http.MethodOptions, "/handler-should-not-care?post_id="+post+"&cursor=!!!", nil)
+		request = helpers.AddAuthDataToRequest(request, caller)
+		handlers.NewGetCommentsHandler(nil).ServeHTTP(response, request)
+		AssertClientError(t, response, client_errors.InvalidCursor)
 	})
 	t.Run("error case - post_id is not provided", func(t *testing.T) {
 		response := httptest.NewRecorder()
@@ -51,7 +66,7 @@ func TestNewGetCommentsHandler(t *testing.T) {
 		AssertClientError(t, response, client_errors.IdNotProvided)
 	})
 	helpers.BaseTestServiceErrorHandling(t, func(err error, response *httptest.ResponseRecorder) {
-		getter := func(post_values.PostId, core_values.UserId) ([]entities.ContextedComment, error) {
+		getter := func(post_values.PostId, core_values.UserId, pagination.Page) ([]entities.ContextedComment, error) {
 			return []entities.ContextedComment{}, err
 		}
 		request := helpers.AddAuthDataToRequest(createRequestWithPostId(post, nil), caller)
@@ -67,6 +82,7 @@ func TestNewCreateCommentHandler(t *testing.T) {
 		Author: user.Id,
 		Text:   RandomString(),
 		Post:   post,
+		Parent: RandomId(),
 	}
 	createdComment := RandomContextedComment()
 	t.Run("happy case", func(t *testing.T) {
@@ -78,7 +94,7 @@ func TestNewCreateCommentHandler(t *testing.T) {
 		}
 		response := httptest.NewRecorder()
 		body := bytes.NewBuffer(nil)
-		json.NewEncoder(body).Encode(handlers.NewCommentRequest{Text: wantNewComment.Text})
+		json.NewEncoder(body).Encode(handlers.NewCommentRequest{Text: wantNewComment.Text, ParentId: wantNewComment.Parent})
 		request := helpers.AddAuthDataToRequest(createRequestWithPostId(post, body), user)
 		handlers.NewCreateCommentHandler(creator).ServeHTTP(response, request)
 		AssertJSONData(t, response, responses.NewCommentResponse(createdComment))
@@ -135,6 +151,158 @@ func TestNewToggleLikeCommentHandler(t *testing.T) {
 	})
 }
 
+func TestLikeAndUnlikeCommentHandlers(t *testing.T) {
+	type action = func(values.CommentId, core_values.UserId) error
+	newHandlers := map[string]func(action) http.HandlerFunc{
+		"like":            func(like action) http.HandlerFunc { return handlers.NewLikeCommentHandler(like) },
+		"unlike":          func(unlike action) http.HandlerFunc { return handlers.NewUnlikeCommentHandler(unlike) },
+		"remove reaction": func(remove action) http.HandlerFunc { return handlers.NewRemoveCommentReactionHandler(remove) },
+	}
+	user := RandomAuthUser()
+	comment := RandomString()
+	for name, newHandler := range newHandlers {
+		t.Run(name, func(t *testing.T) {
+			helpers.BaseTest401(t, newHandler(nil))
+			t.Run("happy case", func(t *testing.T) {
+				act := func(commentId values.CommentId, caller core_values.UserId) error {
+					if commentId == comment && caller == user.Id {
+						return nil
+					}
+					panic("unexpected args")
+				}
+				response := httptest.NewRecorder()
+				newHandler(act).ServeHTTP(response, createRequestWithCommentId(comment, user))
+				AssertStatusCode(t, response, http.StatusOK)
+			})
+			t.Run("error case - id is not provided", func(t *testing.T) {
+				response := httptest.NewRecorder()
+				newHandler(nil).ServeHTTP(response, helpers.AddAuthDataToRequest(helpers.CreateRequest(nil), user))
+				AssertClientError(t, response, client_errors.IdNotProvided)
+			})
+			helpers.BaseTestServiceErrorHandling(t, func(err error, response *httptest.ResponseRecorder) {
+				act := func(values.CommentId, core_values.UserId) error {
+					return err
+				}
+				newHandler(act).ServeHTTP(response, createRequestWithCommentId(comment, user))
+			})
+		})
+	}
+}
+
+func TestSetCommentReactionHandler(t *testing.T) {
+	helpers.BaseTest401(t, handlers.NewSetCommentReactionHandler(nil))
+	comment := RandomId()
+	caller := RandomAuthUser()
+	reaction := RandomReaction()
+	createRequest := func(body io.Reader) *http.Request {
+		request := createRequestWithCommentId(comment, caller)
+		request.Body = io.NopCloser(body)
+		return request
+	}
+	createValidRequest := func() *http.Request {
+		body := bytes.NewBuffer(nil)
+		json.NewEncoder(body).Encode(handlers.SetReactionRequest{Reaction: reaction})
+		return createRequest(body)
+	}
+	t.Run("happy case", func(t *testing.T) {
+		called := false
+		setReaction := func(commentId values.CommentId, callerId core_values.UserId, gotReaction likeable_values.Reaction) error {
+			if commentId == comment && callerId == caller.Id && gotReaction == reaction {
+				called = true
+				return nil
+			}
+			panic("unexpected args")
+		}
+		response := httptest.NewRecorder()
+		handlers.NewSetCommentReactionHandler(setReaction).ServeHTTP(response, createValidRequest())
+		AssertStatusCode(t, response, http.StatusOK)
+		Assert(t, called, true, "service called")
+	})
+	t.Run("error case - id is not provided", func(t *testing.T) {
+		response := httptest.NewRecorder()
+		handlers.NewSetCommentReactionHandler(nil).ServeHTTP(response, helpers.AddAuthDataToRequest(helpers.CreateRequest(nil), caller))
+		AssertClientError(t, response, client_errors.IdNotProvided)
+	})
+	t.Run("error case - body is not valid JSON", func(t *testing.T) {
+		response := httptest.NewRecorder()
+		handlers.NewSetCommentReactionHandler(nil).ServeHTTP(response, createRequest(bytes.NewBufferString("not json")))
+		AssertClientError(t, response, client_errors.InvalidJsonError)
+	})
+	helpers.BaseTestServiceErrorHandling(t, func(err error, response *httptest.ResponseRecorder) {
+		setReaction := func(values.CommentId, core_values.UserId, likeable_values.Reaction) error {
+			return err
+		}
+		handlers.NewSetCommentReactionHandler(setReaction).ServeHTTP(response, createValidRequest())
+	})
+}
+
+func TestGetCommentLikersHandler(t *testing.T) {
+	caller := RandomAuthUser()
+	comment := RandomId()
+	helpers.BaseTest401(t, handlers.NewGetCommentLikersHandler(nil))
+	t.Run("happy case", func(t *testing.T) {
+		likers := []profile_entities.ContextedProfile{RandomContextedProfile(), RandomContextedProfile()}
+		cursor := pagination.Cursor{Id: RandomId()}
+		next := pagination.Cursor{Id: RandomId()}
+		wantPage := pagination.Page{After: cursor, Limit: 2}
+		getLikers := func(commentId values.CommentId, callerId core_values.UserId, page pagination.Page) ([]profile_entities.ContextedProfile, pagination.Cursor, error) {
+			if commentId == comment && callerId == caller.Id && page == wantPage {
+				return likers, next, nil
+			}
+			panic("unexpected args")
+		}
+		request := createRequestWithCommentId(comment, caller)
+		request.URL.RawQuery = "limit=2&cursor=" + cursor.Encode()
+		response := httptest.NewRecorder()
+		handlers.NewGetCommentLikersHandler(getLikers).ServeHTTP(response, request)
+		AssertJSONData(t, response, profile_responses.NewProfilesResponse(likers, next))
+	})
+	t.Run("error case - id is not provided", func(t *testing.T) {
+		response := httptest.NewRecorder()
+		handlers.NewGetCommentLikersHandler(nil).ServeHTTP(response, helpers.AddAuthDataToRequest(helpers.CreateRequest(nil), caller))
+		AssertClientError(t, response, client_errors.IdNotProvided)
+	})
+	helpers.BaseTestServiceErrorHandling(t, func(err error, response *httptest.ResponseRecorder) {
+		getLikers := func(values.CommentId, core_values.UserId, pagination.Page) ([]profile_entities.ContextedProfile, pagination.Cursor, error) {
+			return nil, pagination.Cursor{}, err
+		}
+		handlers.NewGetCommentLikersHandler(getLikers).ServeHTTP(response, createRequestWithCommentId(comment, caller))
+	})
+}
+
+func TestGetRepliesHandler(t *testing.T) {
+	caller := RandomAuthUser()
+	comment := RandomId()
+	helpers.BaseTest401(t, handlers.NewGetRepliesHandler(nil))
+	t.Run("happy case", func(t *testing.T) {
+		replies := []entities.ContextedComment{RandomContextedComment(), RandomContextedComment()}
+		cursor := pagination.Cursor{CreatedAt: RandomTime().Unix(), Id: RandomId()}
+		wantPage := pagination.Page{After: cursor, Limit: 2}
+		getReplies := func(commentId values.CommentId, callerId core_values.UserId, page pagination.Page) ([]entities.ContextedComment, error) {
+			if commentId == comment && callerId == caller.Id && page == wantPage {
+				return replies, nil
+			}
+			panic("unexpected args")
+		}
+		request := createRequestWithCommentId(comment, caller)
+		request.URL.RawQuery = "limit=2&cursor=" + cursor.Encode()
+		response := httptest.NewRecorder()
+		handlers.NewGetRepliesHandler(getReplies).ServeHTTP(response, request)
+		AssertJSONData(t, response, responses.NewCommentListResponse(replies, wantPage))
+	})
+	t.Run("error case - id is not provided", func(t *testing.T) {
+		response := httptest.NewRecorder()
+		handlers.NewGetRepliesHandler(nil).ServeHTTP(response, helpers.AddAuthDataToRequest(helpers.CreateRequest(nil), caller))
+		AssertClientError(t, response, client_errors.IdNotProvided)
+	})
+	helpers.BaseTestServiceErrorHandling(t, func(err error, response *httptest.ResponseRecorder) {
+		getReplies := func(values.CommentId, core_values.UserId, pagination.Page) ([]entities.ContextedComment, error) {
+			return nil, err
+		}
+		handlers.NewGetRepliesHandler(getReplies).ServeHTTP(response, createRequestWithCommentId(comment, caller))
+	})
+}
+
 func TestDeleteCommentHandler(t *testing.T) {
 	helpers.BaseTest401(t, handlers.NewDeleteCommentHandler(nil))
 	comment := RandomId()
@@ -166,3 +334,76 @@ func TestDeleteCommentHandler(t *testing.T) {
 		handlers.NewDeleteCommentHandler(delete)(response, request)
 	})
 }
+
+func TestGetCommentHandler(t *testing.T) {
+	helpers.BaseTest401(t, handlers.NewGetCommentHandler(nil))
+	comment := RandomContextedComment()
+	caller := RandomAuthUser()
+	t.Run("happy case", func(t *testing.T) {
+		getter := func(commentId values.CommentId, callerId core_values.UserId) (entities.ContextedComment, error) {
+			if commentId == comment.Id && callerId == caller.Id {
+				return comment, nil
+			}
+			panic("unexpected args")
+		}
+		response := httptest.NewRecorder()
+		handlers.NewGetCommentHandler(getter).ServeHTTP(response, createRequestWithCommentId(comment.Id, caller))
+		AssertJSONData(t, response, responses.NewCommentResponse(comment))
+	})
+	t.Run("error case - id is not provided", func(t *testing.T) {
+		response := httptest.NewRecorder()
+		handlers.NewGetCommentHandler(nil).ServeHTTP(response, helpers.AddAuthDataToRequest(helpers.CreateRequest(nil), caller))
+		AssertClientError(t, response, client_errors.IdNotProvided)
+	})
+	helpers.BaseTestServiceErrorHandling(t, func(err error, response *httptest.ResponseRecorder) {
+		getter := func(values.CommentId, core_values.UserId) (entities.ContextedComment, error) {
+			return entities.ContextedComment{}, err
+		}
+		handlers.NewGetCommentHandler(getter).ServeHTTP(response, createRequestWithCommentId(comment.Id, caller))
+	})
+}
+
+func TestUpdateCommentHandler(t *testing.T) {
+	helpers.BaseTest401(t, handlers.NewUpdateCommentHandler(nil))
+	comment := RandomId()
+	caller := RandomAuthUser()
+	newText := RandomString()
+	createRequest := func(body io.Reader) *http.Request {
+		request := createRequestWithCommentId(comment, caller)
+		request.Body = io.NopCloser(body)
+		return request
+	}
+	createValidRequest := func() *http.Request {
+		body := bytes.NewBuffer(nil)
+		json.NewEncoder(body).Encode(handlers.UpdateCommentRequest{Text: newText})
+		return createRequest(body)
+	}
+	t.Run("happy case", func(t *testing.T) {
+		updatedComment := RandomContextedComment()
+		updater := func(commentId values.CommentId, callerId core_values.UserId, text string) (entities.ContextedComment, error) {
+			if commentId == comment && callerId == caller.Id && text == newText {
+				return updatedComment, nil
+			}
+			panic("unexpected args")
+		}
+		response := httptest.NewRecorder()
+		handlers.NewUpdateCommentHandler(updater).ServeHTTP(response, createValidRequest())
+		AssertJSONData(t, response, responses.NewCommentResponse(updatedComment))
+	})
+	t.Run("error case - id is not provided", func(t *testing.T) {
+		response := httptest.NewRecorder()
+		handlers.NewUpdateCommentHandler(nil).ServeHTTP(response, helpers.AddAuthDataToRequest(helpers.CreateRequest(nil), caller))
+		AssertClientError(t, response, client_errors.IdNotProvided)
+	})
+	t.Run("error case - body is not valid JSON", func(t *testing.T) {
+		response := httptest.NewRecorder()
+		handlers.NewUpdateCommentHandler(nil).ServeHTTP(response, createRequest(bytes.NewBufferString("not json")))
+		AssertClientError(t, response, client_errors.InvalidJsonError)
+	})
+	helpers.BaseTestServiceErrorHandling(t, func(err error, response *httptest.ResponseRecorder) {
+		updater := func(values.CommentId, core_values.UserId, string) (entities.ContextedComment, error) {
+			return entities.ContextedComment{}, err
+		}
+		handlers.NewUpdateCommentHandler(updater).ServeHTTP(response, createValidRequest())
+	})
+}
@@ -1,40 +1,61 @@
 package responses
 
 import (
+	likeable_values "github.com/k0marov/go-socnet/core/abstract/likeable/values"
+	mentionable_responses "github.com/k0marov/go-socnet/core/abstract/mentionable/responses"
+	"github.com/k0marov/go-socnet/core/general/pagination"
 	"github.com/k0marov/go-socnet/features/comments/domain/entities"
 	profile_responses "github.com/k0marov/go-socnet/features/profiles/delivery/http/responses"
 )
 
 type CommentResponse struct {
-	Id        string                            `json:"id"`
-	Author    profile_responses.ProfileResponse `json:"author"`
-	Text      string                            `json:"text"`
-	CreatedAt int64                             `json:"created_at"`
-	Likes     int                               `json:"likes"`
-	IsLiked   bool                              `json:"is_liked"`
-	IsMine    bool                              `json:"is_mine"`
+	Id        string                                  `json:"id"`
+	ParentId  string                                  `json:"parent_id,omitempty"`
+	Author    profile_responses.ProfileResponse       `json:"author"`
+	Text      string                                  `json:"text"`
+	CreatedAt int64                                   `json:"created_at"`
+	EditedAt  int64                                   `json:"edited_at,omitempty"`
+	DeletedAt int64                                   `json:"deleted_at,omitempty"`
+	Replies   int                                     `json:"replies"`
+	Likes     int                                     `json:"likes"`
+	Reactions likeable_values.ReactionCounts          `json:"reactions"`
+	IsLiked   bool                                    `json:"is_liked"`
+	Reaction  likeable_values.Reaction                `json:"reaction,omitempty"`
+	IsMine    bool                                    `json:"is_mine"`
+	Mentions  []mentionable_responses.MentionResponse `json:"mentions"`
 }
 
 type CommentsResponse struct {
-	Comments []CommentResponse `json:"comments"`
+	Comments   []CommentResponse `json:"comments"`
+	NextCursor string            `json:"next_cursor"`
 }
 
 func NewCommentResponse(comment entities.ContextedComment) CommentResponse {
 	return CommentResponse{
 		Id:        comment.Id,
+		ParentId:  comment.ParentId,
 		Author:    profile_responses.NewProfileResponse(comment.Author),
 		Text:      comment.Text,
 		CreatedAt: comment.CreatedAt,
+		EditedAt:  comment.EditedAt,
+		DeletedAt: comment.DeletedAt,
+		Replies:   comment.Replies,
 		Likes:     comment.Likes,
+		Reactions: comment.Reactions,
 		IsLiked:   comment.IsLiked,
+		Reaction:  comment.Reaction,
 		IsMine:    comment.IsMine,
+		Mentions:  mentionable_responses.NewMentionsResponse(comment.Mentions),
 	}
 }
 
-func NewCommentListResponse(comments []entities.ContextedComment) CommentsResponse {
+func NewCommentListResponse(comments []entities.ContextedComment, page pagination.Page) CommentsResponse {
 	commentsResp := make([]CommentResponse, 0)
 	for _, comment := range comments {
 		commentsResp = append(commentsResp, NewCommentResponse(comment))
 	}
-	return CommentsResponse{Comments: commentsResp}
+	return CommentsResponse{
+		Comments:   commentsResp,
+		NextCursor: pagination.NextCursor(comments, page, entities.ContextedComment.Cursor).Encode(),
+	}
 }
@@ -5,11 +5,19 @@ import (
 	"net/http"
 )
 
-func NewCommentsRouter(getComments, createComment, toggleLike, delete http.HandlerFunc) func(chi.Router) {
+func NewCommentsRouter(getComments, createComment, getComment, getReplies, update, toggleLike, like, unlike, setReaction, removeReaction, getLikers, delete http.HandlerFunc) func(chi.Router) {
 	return func(r chi.Router) {
 		r.Get("/", getComments)
 		r.Post("/", createComment)
+		r.Get("/{id}", getComment)
+		r.Get("/{id}/replies", getReplies)
+		r.Put("/{id}", update)
 		r.Post("/{id}/toggle-like", toggleLike)
+		r.Put("/{id}/like", like)
+		r.Delete("/{id}/like", unlike)
+		r.Put("/{id}/reaction", setReaction)
+		r.Delete("/{id}/reaction", removeReaction)
+		r.Get("/{id}/likes", getLikers)
 		r.Delete("/{id}", delete)
 	}
 }
@@ -1,14 +1,28 @@
 package entities
 
 import (
+	likeable_values "github.com/k0marov/go-socnet/core/abstract/likeable/values"
+	mentionable_values "github.com/k0marov/go-socnet/core/abstract/mentionable/values"
 	"github.com/k0marov/go-socnet/core/abstract/ownable_likeable/contexters"
+	"github.com/k0marov/go-socnet/core/general/pagination"
 	"github.com/k0marov/go-socnet/features/comments/domain/models"
 	profile_entities "github.com/k0marov/go-socnet/features/profiles/domain/entities"
 )
 
 type Comment struct {
 	models.CommentModel
-	Likes int
+	Likes     int
+	Reactions likeable_values.ReactionCounts
+	Mentions  []mentionable_values.Mention
+}
+
+// IsDeleted reports whether the comment is a tombstone left in place of a deleted comment which has replies
+func (c Comment) IsDeleted() bool {
+	return c.DeletedAt != 0
+}
+
+func (c Comment) Cursor() pagination.Cursor {
+	return pagination.Cursor{CreatedAt: c.CreatedAt, Id: c.Id}
 }
 
 type ContextedComment struct {
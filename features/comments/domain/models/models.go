@@ -3,11 +3,17 @@ package models
 import (
 	"github.com/k0marov/go-socnet/core/general/core_values"
 	"github.com/k0marov/go-socnet/features/comments/domain/values"
+	post_values "github.com/k0marov/go-socnet/features/posts/domain/values"
 )
 
 type CommentModel struct {
 	Id        values.CommentId   `db:"id"`
+	PostId    post_values.PostId `db:"post_id"`
+	ParentId  values.CommentId   `db:"parent_id"` // empty for top-level comments
 	AuthorId  core_values.UserId `db:"owner_id"`
 	Text      string             `db:"textContent"`
 	CreatedAt int64              `db:"createdAt"`
+	EditedAt  int64              `db:"editedAt"`
+	DeletedAt int64              `db:"deletedAt"` // non-zero for tombstones of deleted comments which have replies
+	Replies   int                `db:"replies"`
 }
@@ -1,11 +1,19 @@
 package service
 
 import (
-	"github.com/k0marov/go-socnet/core/abstract/deletable"
+	"errors"
+	"fmt"
+	likeable_values "github.com/k0marov/go-socnet/core/abstract/likeable/values"
+	"github.com/k0marov/go-socnet/core/abstract/mentionable"
+	mentionable_values "github.com/k0marov/go-socnet/core/abstract/mentionable/values"
+	"github.com/k0marov/go-socnet/core/abstract/ownable"
 	"github.com/k0marov/go-socnet/core/abstract/ownable_likeable"
 	likeable_contexters "github.com/k0marov/go-socnet/core/abstract/ownable_likeable/contexters"
+	"github.com/k0marov/go-socnet/core/general/client_errors"
 	"github.com/k0marov/go-socnet/core/general/core_err"
 	"github.com/k0marov/go-socnet/core/general/core_values"
+	"github.com/k0marov/go-socnet/core/general/events"
+	"github.com/k0marov/go-socnet/core/general/pagination"
 	"time"
 
 	"github.com/k0marov/go-socnet/features/comments/domain/contexters"
@@ -14,20 +22,32 @@ import (
 	"github.com/k0marov/go-socnet/features/comments/domain/store"
 	"github.com/k0marov/go-socnet/features/comments/domain/validators"
 	"github.com/k0marov/go-socnet/features/comments/domain/values"
+	post_contexters "github.com/k0marov/go-socnet/features/posts/domain/contexters"
+	post_entities "github.com/k0marov/go-socnet/features/posts/domain/entities"
+	post_store "github.com/k0marov/go-socnet/features/posts/domain/store"
 	post_values "github.com/k0marov/go-socnet/features/posts/domain/values"
+	profile_entities "github.com/k0marov/go-socnet/features/profiles/domain/entities"
 	profile_service "github.com/k0marov/go-socnet/features/profiles/domain/service"
 )
 
 type (
-	PostCommentsGetter func(post post_values.PostId, caller core_values.UserId) ([]entities.ContextedComment, error)
-	CommentCreator     func(newComment values.NewCommentValue) (entities.ContextedComment, error)
-	CommentLikeToggler func(values.CommentId, core_values.UserId) error
-	CommentDeleter     func(comment values.CommentId, caller core_values.UserId) error
+	PostCommentsGetter     func(post post_values.PostId, caller core_values.UserId, page pagination.Page) ([]entities.ContextedComment, error)
+	CommentRepliesGetter   func(comment values.CommentId, caller core_values.UserId, page pagination.Page) ([]entities.ContextedComment, error)
+	CommentCreator         func(newComment values.NewCommentValue) (entities.ContextedComment, error)
+	CommentLikeToggler     func(values.CommentId, core_values.UserId) error
+	CommentLiker           func(values.CommentId, core_values.UserId) error
+	CommentUnliker         func(values.CommentId, core_values.UserId) error
+	CommentReactionSetter  func(values.CommentId, core_values.UserId, likeable_values.Reaction) error
+	CommentReactionRemover func(values.CommentId, core_values.UserId) error
+	CommentLikersGetter    func(comment values.CommentId, caller core_values.UserId, page pagination.Page) ([]profile_entities.ContextedProfile, pagination.Cursor, error)
+	CommentDeleter         func(comment values.CommentId, caller core_values.UserId) error
+	CommentGetter          func(comment values.CommentId, caller core_values.UserId) (entities.ContextedComment, error)
+	CommentUpdater         func(comment values.CommentId, caller core_values.UserId, newText string) (entities.ContextedComment, error)
 )
 
 func NewPostCommentsGetter(getComments store.CommentsGetter, addContexts contexters.CommentListContextAdder) PostCommentsGetter {
-	return func(post post_values.PostId, caller core_values.UserId) ([]entities.ContextedComment, error) {
-		comments, err := getComments(post)
+	return func(post post_values.PostId, caller core_values.UserId, page pagination.Page) ([]entities.ContextedComment, error) {
+		comments, err := getComments(post, page)
 		if err != nil {
 			return []entities.ContextedComment{}, core_err.Rethrow("getting post contextedComments from store", err)
 		}
@@ -39,20 +59,73 @@ func NewPostCommentsGetter(getComments store.CommentsGetter, addContexts context
 	}
 }
 
-func NewCommentCreator(validate validators.CommentValidator, getProfile profile_service.ProfileGetter, createComment store.Creator) CommentCreator {
+func NewCommentRepliesGetter(getComment store.CommentGetter, getReplies store.RepliesGetter, addContexts contexters.CommentListContextAdder) CommentRepliesGetter {
+	return func(comment values.CommentId, caller core_values.UserId, page pagination.Page) ([]entities.ContextedComment, error) {
+		_, err := getComment(comment) // to throw NotFound if the comment doesn't exist
+		if err != nil {
+			if errors.Is(err, core_err.ErrNotFound) {
+				return []entities.ContextedComment{}, client_errors.NotFound
+			}
+			return []entities.ContextedComment{}, core_err.Rethrow("getting the replied comment from store", err)
+		}
+		replies, err := getReplies(comment, page)
+		if err != nil {
+			return []entities.ContextedComment{}, core_err.Rethrow("getting comment replies from store", err)
+		}
+		contextedReplies, err := addContexts(replies, caller)
+		if err != nil {
+			return []entities.ContextedComment{}, core_err.Rethrow("adding contexts to replies", err)
+		}
+		return contextedReplies, nil
+	}
+}
+
+// NewCommentCreator publishes the events of commenting, replying and mentioning, so that the concerned profiles are notified
+func NewCommentCreator(validate validators.CommentValidator, getPost post_store.PostModelGetter, getProfile profile_service.ProfileGetter, getParent store.CommentGetter, resolveMentions mentionable.MentionsResolver, createComment store.Creator, publish events.Publisher) CommentCreator {
 	return func(newComment values.NewCommentValue) (entities.ContextedComment, error) {
 		clientErr, isValid := validate(newComment)
 		if !isValid {
 			return entities.ContextedComment{}, clientErr
 		}
 
+		post, err := getPost(newComment.Post)
+		if errors.Is(err, core_err.ErrNotFound) {
+			return entities.ContextedComment{}, client_errors.NotFound
+		}
+		if err != nil {
+			return entities.ContextedComment{}, core_err.Rethrow("getting the commented post", err)
+		}
+		if post.CommentsDisabled {
+			return entities.ContextedComment{}, client_errors.CommentsDisabled
+		}
+
+		var parentAuthor core_values.UserId
+		if newComment.Parent != "" {
+			parent, err := getParent(newComment.Parent)
+			if errors.Is(err, core_err.ErrNotFound) {
+				return entities.ContextedComment{}, client_errors.InvalidParentComment
+			}
+			if err != nil {
+				return entities.ContextedComment{}, core_err.Rethrow("getting the parent comment", err)
+			}
+			if parent.PostId != newComment.Post || parent.IsDeleted() {
+				return entities.ContextedComment{}, client_errors.InvalidParentComment
+			}
+			parentAuthor = parent.AuthorId
+		}
+
 		author, err := getProfile(newComment.Author, newComment.Author)
 		if err != nil {
 			return entities.ContextedComment{}, core_err.Rethrow("getting author's profile", err)
 		}
 
+		mentions, err := resolveMentions(newComment.Text)
+		if err != nil {
+			return entities.ContextedComment{}, core_err.Rethrow("resolving mentions in the comment text", err)
+		}
+
 		createdAt := time.Now().UTC()
-		newId, err := createComment(newComment, createdAt)
+		newId, err := createComment(newComment, mentions, createdAt)
 		if err != nil {
 			return entities.ContextedComment{}, core_err.Rethrow("creating new comment", err)
 		}
@@ -61,11 +134,15 @@ func NewCommentCreator(validate validators.CommentValidator, getProfile profile_
 			Comment: entities.Comment{
 				CommentModel: models.CommentModel{
 					Id:        newId,
+					PostId:    newComment.Post,
+					ParentId:  newComment.Parent,
 					AuthorId:  newComment.Author,
 					Text:      newComment.Text,
 					CreatedAt: createdAt.Unix(),
 				},
-				Likes: 0,
+				Likes:     0,
+				Reactions: likeable_values.NewReactionCounts(),
+				Mentions:  mentions,
 			},
 			OwnLikeContext: likeable_contexters.OwnLikeContext{
 				IsLiked: false,
@@ -73,14 +150,196 @@ func NewCommentCreator(validate validators.CommentValidator, getProfile profile_
 			},
 			Author: author,
 		}
+		publishCommentEvents(publish, comment.CommentModel, mentions, post.AuthorId, parentAuthor, createdAt)
 		return comment, nil
 	}
 }
 
+// publishCommentEvents announces the new comment to the viewers of the post
+// and notifies the author of the replied comment, the author of the post and the mentioned profiles.
+// Everyone is notified only once and the author of the new comment is never notified.
+func publishCommentEvents(publish events.Publisher, comment models.CommentModel, mentions []mentionable_values.Mention, postAuthor, parentAuthor core_values.UserId, createdAt time.Time) {
+	notified := map[core_values.UserId]bool{comment.AuthorId: true}
+	notify := func(kind events.Kind, recipient core_values.UserId, targetType events.TargetType, target string) {
+		if recipient == "" || notified[recipient] {
+			return
+		}
+		notified[recipient] = true
+		publish(events.Event{
+			Kind:       kind,
+			Actor:      comment.AuthorId,
+			Recipient:  recipient,
+			TargetType: targetType,
+			TargetId:   target,
+			Comment:    comment.Id,
+			CreatedAt:  createdAt,
+		})
+	}
+	publish(events.Event{
+		Kind:       events.CommentCreated,
+		Actor:      comment.AuthorId,
+		TargetType: events.Post,
+		TargetId:   comment.PostId,
+		Comment:    comment.Id,
+		CreatedAt:  createdAt,
+	})
+	notify(events.Replied, parentAuthor, events.Comment, comment.ParentId)
+	notify(events.Commented, postAuthor, events.Post, comment.PostId)
+	for _, mention := range mentions {
+		notify(events.Mentioned, mention.Profile, events.Post, comment.PostId)
+	}
+}
+
 func NewCommentLikeToggler(safeToggleLike ownable_likeable.SafeLikeToggler) CommentLikeToggler {
 	return CommentLikeToggler(safeToggleLike)
 }
 
-func NewCommentDeleter(delete deletable.Deleter) CommentDeleter {
-	return CommentDeleter(delete)
+func NewCommentLiker(safeLike ownable_likeable.SafeLiker) CommentLiker {
+	return CommentLiker(safeLike)
+}
+
+func NewCommentUnliker(safeUnlike ownable_likeable.SafeUnliker) CommentUnliker {
+	return CommentUnliker(safeUnlike)
+}
+
+func NewCommentReactionSetter(safeSetReaction ownable_likeable.SafeReactionSetter) CommentReactionSetter {
+	return CommentReactionSetter(safeSetReaction)
+}
+
+// NewCommentReactionRemover returns a remover of the caller's reaction of any kind, which is the same as unliking
+func NewCommentReactionRemover(safeUnlike ownable_likeable.SafeUnliker) CommentReactionRemover {
+	return CommentReactionRemover(safeUnlike)
+}
+
+// NewCommentDeleter returns a deleter which leaves a tombstone in place of a comment that has replies.
+// A comment can be deleted by its author or by the author of the post it belongs to.
+func NewCommentDeleter(getComment store.CommentGetter, getPost post_store.PostModelGetter, deleteComment store.Deleter) CommentDeleter {
+	return func(commentId values.CommentId, caller core_values.UserId) error {
+		comment, err := getComment(commentId)
+		if errors.Is(err, core_err.ErrNotFound) {
+			return client_errors.NotFound
+		}
+		if err != nil {
+			return core_err.Rethrow("getting the comment from store", err)
+		}
+		if comment.AuthorId != caller {
+			post, err := getPost(comment.PostId)
+			if err != nil {
+				return core_err.Rethrow("getting the post of comment", err)
+			}
+			if post.AuthorId != caller {
+				return client_errors.InsufficientPermissions
+			}
+		}
+		err = deleteComment(commentId, time.Now().UTC())
+		if err != nil {
+			return core_err.Rethrow("deleting a comment in store", err)
+		}
+		return nil
+	}
+}
+
+func NewCommentGetter(getComment store.CommentGetter, addContext contexters.CommentContextAdder) CommentGetter {
+	return func(commentId values.CommentId, caller core_values.UserId) (entities.ContextedComment, error) {
+		comment, err := getComment(commentId)
+		if err != nil {
+			if errors.Is(err, core_err.ErrNotFound) {
+				return entities.ContextedComment{}, client_errors.NotFound
+			}
+			return entities.ContextedComment{}, core_err.Rethrow("getting a comment from store", err)
+		}
+		contextedComment, err := addContext(comment, caller)
+		if err != nil {
+			return entities.ContextedComment{}, core_err.Rethrow("adding context to comment", err)
+		}
+		return contextedComment, nil
+	}
+}
+
+func NewCommentUpdater(getOwner ownable.OwnerGetter, validate validators.CommentValidator, resolveMentions mentionable.MentionsResolver, updateComment store.Updater, getUpdated CommentGetter) CommentUpdater {
+	return func(comment values.CommentId, caller core_values.UserId, newText string) (entities.ContextedComment, error) {
+		owner, err := getOwner(comment)
+		if err != nil {
+			if errors.Is(err, core_err.ErrNotFound) {
+				return entities.ContextedComment{}, client_errors.NotFound
+			}
+			return entities.ContextedComment{}, core_err.Rethrow("getting comment owner", err)
+		}
+		if owner != caller {
+			return entities.ContextedComment{}, client_errors.InsufficientPermissions
+		}
+		clientErr, isValid := validate(values.NewCommentValue{Author: owner, Text: newText})
+		if !isValid {
+			return entities.ContextedComment{}, clientErr
+		}
+		mentions, err := resolveMentions(newText)
+		if err != nil {
+			return entities.ContextedComment{}, core_err.Rethrow("resolving mentions in the updated comment text", err)
+		}
+		err = updateComment(comment, newText, mentions, time.Now().UTC())
+		if errors.Is(err, core_err.ErrNotFound) {
+			return entities.ContextedComment{}, client_errors.NotFound
+		}
+		if err != nil {
+			return entities.ContextedComment{}, core_err.Rethrow("updating a comment in store", err)
+		}
+		return getUpdated(comment, caller)
+	}
+}
+
+func NewCommentLikersGetter(getAuthor ownable.OwnerGetter, getLikers profile_service.LikersGetter) CommentLikersGetter {
+	return func(comment values.CommentId, caller core_values.UserId, page pagination.Page) ([]profile_entities.ContextedProfile, pagination.Cursor, error) {
+		_, err := getAuthor(comment) // to throw NotFound if the comment doesn't exist
+		if err != nil {
+			return []profile_entities.ContextedProfile{}, pagination.Cursor{}, core_err.Rethrow("getting comment author", err)
+		}
+		likers, next, err := getLikers(comment, caller, page)
+		if err != nil {
+			return []profile_entities.ContextedProfile{}, pagination.Cursor{}, core_err.Rethrow("getting likers of comment", err)
+		}
+		return likers, next, nil
+	}
+}
+
+// NewPostCommentPreviewsGetter gets the latest comments of all posts and their authors at once
+func NewPostCommentPreviewsGetter(getLatest store.LatestCommentsGetter, getAuthors profile_service.ProfilesGetter) post_contexters.CommentPreviewsGetter {
+	return func(posts []post_values.PostId, count int, caller core_values.UserId) (map[post_values.PostId][]post_entities.CommentPreview, error) {
+		latest, err := getLatest(posts, count)
+		if err != nil {
+			return nil, core_err.Rethrow("getting latest comments of posts from store", err)
+		}
+		var authorIds []core_values.UserId
+		isAuthor := map[core_values.UserId]bool{}
+		for _, comments := range latest {
+			for _, comment := range comments {
+				if !isAuthor[comment.AuthorId] {
+					isAuthor[comment.AuthorId] = true
+					authorIds = append(authorIds, comment.AuthorId)
+				}
+			}
+		}
+		previews := map[post_values.PostId][]post_entities.CommentPreview{}
+		if len(authorIds) == 0 {
+			return previews, nil
+		}
+		authors, err := getAuthors(authorIds, caller)
+		if err != nil {
+			return nil, core_err.Rethrow("getting authors of latest comments", err)
+		}
+		for post, comments := range latest {
+			for _, comment := range comments {
+				author, ok := authors[comment.AuthorId]
+				if !ok {
+					return nil, fmt.Errorf("getting author %v of comment %v: %w", comment.AuthorId, comment.Id, core_err.ErrNotFound)
+				}
+				previews[post] = append(previews[post], post_entities.CommentPreview{
+					Id:        comment.Id,
+					Author:    author,
+					Text:      comment.Text,
+					CreatedAt: comment.CreatedAt,
+				})
+			}
+		}
+		return previews, nil
+	}
 }
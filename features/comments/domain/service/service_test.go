@@ -1,9 +1,16 @@
 package service_test
 
 import (
+	"errors"
+	"fmt"
+	likeable_values "github.com/k0marov/go-socnet/core/abstract/likeable/values"
+	mentionable_values "github.com/k0marov/go-socnet/core/abstract/mentionable/values"
 	likeable_contexters "github.com/k0marov/go-socnet/core/abstract/ownable_likeable/contexters"
 	"github.com/k0marov/go-socnet/core/general/client_errors"
+	"github.com/k0marov/go-socnet/core/general/core_err"
 	"github.com/k0marov/go-socnet/core/general/core_values"
+	"github.com/k0marov/go-socnet/core/general/events"
+	"github.com/k0marov/go-socnet/core/general/pagination"
 	. "github.com/k0marov/go-socnet/core/helpers/test_helpers"
 	"reflect"
 	"testing"
@@ -13,6 +20,8 @@ import (
 	"github.com/k0marov/go-socnet/features/comments/domain/models"
 	"github.com/k0marov/go-socnet/features/comments/domain/service"
 	"github.com/k0marov/go-socnet/features/comments/domain/values"
+	post_entities "github.com/k0marov/go-socnet/features/posts/domain/entities"
+	post_models "github.com/k0marov/go-socnet/features/posts/domain/models"
 	post_values "github.com/k0marov/go-socnet/features/posts/domain/values"
 	profile_entities "github.com/k0marov/go-socnet/features/profiles/domain/entities"
 )
@@ -21,15 +30,19 @@ func TestCommentCreator(t *testing.T) {
 	newComment := RandomNewComment()
 	createdId := RandomString()
 	author := RandomContextedProfile()
+	mentions := RandomMentions()
 	createdComment := entities.ContextedComment{
 		Comment: entities.Comment{
 			CommentModel: models.CommentModel{
 				Id:        createdId,
+				PostId:    newComment.Post,
 				AuthorId:  newComment.Author,
 				Text:      newComment.Text,
 				CreatedAt: time.Now().Unix(),
 			},
-			Likes: 0,
+			Likes:     0,
+			Reactions: likeable_values.NewReactionCounts(),
+			Mentions:  mentions,
 		},
 		OwnLikeContext: likeable_contexters.OwnLikeContext{
 			IsLiked: false,
@@ -48,9 +61,41 @@ func TestCommentCreator(t *testing.T) {
 		validator := func(value values.NewCommentValue) (client_errors.ClientError, bool) {
 			return clientErr, false
 		}
-		_, err := service.NewCommentCreator(validator, nil, nil)(newComment)
+		_, err := service.NewCommentCreator(validator, nil, nil, nil, nil, nil, nil)(newComment)
 		AssertError(t, err, clientErr)
 	})
+	post := RandomPostModel()
+	post.Id = newComment.Post
+	post.CommentsDisabled = false
+	getPost := func(postId post_values.PostId) (post_models.PostModel, error) {
+		if postId == newComment.Post {
+			return post, nil
+		}
+		panic("unexpected args")
+	}
+	t.Run("error case - post not found", func(t *testing.T) {
+		getPost := func(post_values.PostId) (post_models.PostModel, error) {
+			return post_models.PostModel{}, fmt.Errorf("wrapped: %w", core_err.ErrNotFound)
+		}
+		_, err := service.NewCommentCreator(validator, getPost, nil, nil, nil, nil, nil)(newComment)
+		AssertError(t, err, client_errors.NotFound)
+	})
+	t.Run("error case - getting post throws", func(t *testing.T) {
+		getPost := func(post_values.PostId) (post_models.PostModel, error) {
+			return post_models.PostModel{}, RandomError()
+		}
+		_, err := service.NewCommentCreator(validator, getPost, nil, nil, nil, nil, nil)(newComment)
+		AssertSomeError(t, err)
+	})
+	t.Run("error case - comments of the post are disabled", func(t *testing.T) {
+		getPost := func(post_values.PostId) (post_models.PostModel, error) {
+			disabled := post
+			disabled.CommentsDisabled = true
+			return disabled, nil
+		}
+		_, err := service.NewCommentCreator(validator, getPost, nil, nil, nil, nil, nil)(newComment)
+		AssertError(t, err, client_errors.CommentsDisabled)
+	})
 	profileGetter := func(target, caller core_values.UserId) (profile_entities.ContextedProfile, error) {
 		if target == newComment.Author && caller == newComment.Author {
 			return author, nil
@@ -61,47 +106,192 @@ func TestCommentCreator(t *testing.T) {
 		profileGetter := func(target, caller core_values.UserId) (profile_entities.ContextedProfile, error) {
 			return profile_entities.ContextedProfile{}, RandomError()
 		}
-		_, err := service.NewCommentCreator(validator, profileGetter, nil)(newComment)
+		_, err := service.NewCommentCreator(validator, getPost, profileGetter, nil, nil, nil, nil)(newComment)
 		AssertSomeError(t, err)
 	})
 
-	creator := func(gotComment values.NewCommentValue, createdAt time.Time) (values.CommentId, error) {
-		if gotComment == newComment && TimeAlmostNow(createdAt) {
+	resolveMentions := func(text string) ([]mentionable_values.Mention, error) {
+		if text == newComment.Text {
+			return mentions, nil
+		}
+		panic("unexpected args")
+	}
+	t.Run("resolving mentions throws", func(t *testing.T) {
+		resolveMentions := func(string) ([]mentionable_values.Mention, error) {
+			return nil, RandomError()
+		}
+		_, err := service.NewCommentCreator(validator, getPost, profileGetter, nil, resolveMentions, nil, nil)(newComment)
+		AssertSomeError(t, err)
+	})
+	creator := func(gotComment values.NewCommentValue, gotMentions []mentionable_values.Mention, createdAt time.Time) (values.CommentId, error) {
+		if gotComment == newComment && reflect.DeepEqual(gotMentions, mentions) && TimeAlmostNow(createdAt) {
 			return createdId, nil
 		}
 		panic("unexpected args")
 	}
 	t.Run("creator throws", func(t *testing.T) {
-		creator := func(values.NewCommentValue, time.Time) (values.CommentId, error) {
+		creator := func(values.NewCommentValue, []mentionable_values.Mention, time.Time) (values.CommentId, error) {
 			return "", RandomError()
 		}
-		_, err := service.NewCommentCreator(validator, profileGetter, creator)(newComment)
+		_, err := service.NewCommentCreator(validator, getPost, profileGetter, nil, resolveMentions, creator, nil)(newComment)
 		AssertSomeError(t, err)
 	})
-	sut := service.NewCommentCreator(validator, profileGetter, creator)
-	gotCreated, err := sut(newComment)
-	AssertNoError(t, err)
-	Assert(t, TimeAlmostNow(time.Unix(gotCreated.CreatedAt, 0)), true, "createdAt is time.Now()")
-	gotCreated.CreatedAt = createdComment.CreatedAt
-	Assert(t, gotCreated, createdComment, "the returned created comment")
+	var published []events.Event
+	publish := func(event events.Event) {
+		published = append(published, event)
+	}
+	t.Run("happy case", func(t *testing.T) {
+		published = nil
+		sut := service.NewCommentCreator(validator, getPost, profileGetter, nil, resolveMentions, creator, publish)
+		gotCreated, err := sut(newComment)
+		AssertNoError(t, err)
+		Assert(t, TimeAlmostNow(time.Unix(gotCreated.CreatedAt, 0)), true, "createdAt is time.Now()")
+		gotCreated.CreatedAt = createdComment.CreatedAt
+		Assert(t, gotCreated, createdComment, "the returned created comment")
+
+		// the comment is announced to the viewers of the post, the post author and the mentioned profiles are notified
+		AssertFatal(t, len(published), 2+len(mentions), "number of published events")
+		wantCreated := events.Event{
+			Kind:       events.CommentCreated,
+			Actor:      newComment.Author,
+			TargetType: events.Post,
+			TargetId:   newComment.Post,
+			Comment:    createdId,
+			CreatedAt:  published[0].CreatedAt,
+		}
+		Assert(t, published[0], wantCreated, "comment creation event")
+		Assert(t, published[1].Kind, events.Commented, "kind of the second event")
+		Assert(t, published[1].Recipient, post.AuthorId, "recipient of the second event")
+		for i, mention := range mentions {
+			event := published[i+2]
+			want := events.Event{
+				Kind:       events.Mentioned,
+				Actor:      newComment.Author,
+				Recipient:  mention.Profile,
+				TargetType: events.Post,
+				TargetId:   newComment.Post,
+				Comment:    createdId,
+				CreatedAt:  event.CreatedAt,
+			}
+			Assert(t, event, want, "mention event")
+		}
+	})
+	t.Run("happy case - commenting your own post doesn't notify you", func(t *testing.T) {
+		published = nil
+		getPost := func(post_values.PostId) (post_models.PostModel, error) {
+			ownPost := post
+			ownPost.AuthorId = newComment.Author
+			return ownPost, nil
+		}
+		resolveMentions := func(string) ([]mentionable_values.Mention, error) {
+			return []mentionable_values.Mention{{Profile: newComment.Author}}, nil
+		}
+		creator := func(values.NewCommentValue, []mentionable_values.Mention, time.Time) (values.CommentId, error) {
+			return createdId, nil
+		}
+		_, err := service.NewCommentCreator(validator, getPost, profileGetter, nil, resolveMentions, creator, publish)(newComment)
+		AssertNoError(t, err)
+		AssertFatal(t, len(published), 1, "number of published events")
+		Assert(t, published[0].Kind, events.CommentCreated, "kind of the only event")
+	})
+	t.Run("replies", func(t *testing.T) {
+		parent := RandomComment()
+		parent.PostId = newComment.Post
+		parent.DeletedAt = 0
+		reply := newComment
+		reply.Parent = parent.Id
+		validator := func(values.NewCommentValue) (client_errors.ClientError, bool) {
+			return client_errors.ClientError{}, true
+		}
+		getParent := func(id values.CommentId) (entities.Comment, error) {
+			if id == parent.Id {
+				return parent, nil
+			}
+			panic("unexpected args")
+		}
+		t.Run("error case - parent does not exist", func(t *testing.T) {
+			getParent := func(values.CommentId) (entities.Comment, error) {
+				return entities.Comment{}, fmt.Errorf("wrapped: %w", core_err.ErrNotFound)
+			}
+			_, err := service.NewCommentCreator(validator, getPost, profileGetter, getParent, nil, nil, nil)(reply)
+			AssertError(t, err, client_errors.InvalidParentComment)
+		})
+		t.Run("error case - getting parent throws", func(t *testing.T) {
+			getParent := func(values.CommentId) (entities.Comment, error) {
+				return entities.Comment{}, RandomError()
+			}
+			_, err := service.NewCommentCreator(validator, getPost, profileGetter, getParent, nil, nil, nil)(reply)
+			AssertSomeError(t, err)
+		})
+		t.Run("error case - parent belongs to another post", func(t *testing.T) {
+			reply := reply
+			reply.Post = RandomId()
+			getPost := func(post_values.PostId) (post_models.PostModel, error) {
+				return post, nil
+			}
+			_, err := service.NewCommentCreator(validator, getPost, profileGetter, getParent, nil, nil, nil)(reply)
+			AssertError(t, err, client_errors.InvalidParentComment)
+		})
+		t.Run("error case - parent is deleted", func(t *testing.T) {
+			getParent := func(values.CommentId) (entities.Comment, error) {
+				deleted := parent
+				deleted.DeletedAt = RandomTime().Unix()
+				return deleted, nil
+			}
+			_, err := service.NewCommentCreator(validator, getPost, profileGetter, getParent, nil, nil, nil)(reply)
+			AssertError(t, err, client_errors.InvalidParentComment)
+		})
+		t.Run("happy case", func(t *testing.T) {
+			resolveMentions := func(string) ([]mentionable_values.Mention, error) {
+				return []mentionable_values.Mention{}, nil
+			}
+			creator := func(gotComment values.NewCommentValue, _ []mentionable_values.Mention, createdAt time.Time) (values.CommentId, error) {
+				if gotComment == reply {
+					return createdId, nil
+				}
+				panic("unexpected args")
+			}
+			published = nil
+			gotCreated, err := service.NewCommentCreator(validator, getPost, profileGetter, getParent, resolveMentions, creator, publish)(reply)
+			AssertNoError(t, err)
+			Assert(t, gotCreated.ParentId, parent.Id, "parent of the created reply")
+
+			// the author of the parent is notified about the reply and the post author about the comment
+			AssertFatal(t, len(published), 3, "number of published events")
+			Assert(t, published[0].Kind, events.CommentCreated, "kind of the first event")
+			wantReply := events.Event{
+				Kind:       events.Replied,
+				Actor:      reply.Author,
+				Recipient:  parent.AuthorId,
+				TargetType: events.Comment,
+				TargetId:   parent.Id,
+				Comment:    createdId,
+				CreatedAt:  published[1].CreatedAt,
+			}
+			Assert(t, published[1], wantReply, "reply event")
+			Assert(t, published[2].Kind, events.Commented, "kind of the third event")
+			Assert(t, published[2].Recipient, post.AuthorId, "recipient of the third event")
+		})
+	})
 }
 func TestPostCommentsGetter(t *testing.T) {
 	post := RandomString()
 	caller := RandomId()
 	comments := []entities.Comment{RandomComment()}
 	contextedComments := []entities.ContextedComment{RandomContextedComment()}
+	page := pagination.Page{Limit: RandomInt()}
 
-	commentsGetter := func(postId post_values.PostId) ([]entities.Comment, error) {
-		if postId == post {
+	commentsGetter := func(postId post_values.PostId, gotPage pagination.Page) ([]entities.Comment, error) {
+		if postId == post && gotPage == page {
 			return comments, nil
 		}
 		panic("unexpected args")
 	}
 	t.Run("error case - getting the comments returns some error", func(t *testing.T) {
-		commentsGetter := func(post_values.PostId) ([]entities.Comment, error) {
+		commentsGetter := func(post_values.PostId, pagination.Page) ([]entities.Comment, error) {
 			return []entities.Comment{}, RandomError()
 		}
-		_, err := service.NewPostCommentsGetter(commentsGetter, nil)(post, caller)
+		_, err := service.NewPostCommentsGetter(commentsGetter, nil)(post, caller, page)
 		AssertSomeError(t, err)
 	})
 	contextAdder := func(commentList []entities.Comment, callerId core_values.UserId) ([]entities.ContextedComment, error) {
@@ -114,10 +304,379 @@ func TestPostCommentsGetter(t *testing.T) {
 		contextAdder := func([]entities.Comment, core_values.UserId) ([]entities.ContextedComment, error) {
 			return nil, RandomError()
 		}
-		_, err := service.NewPostCommentsGetter(commentsGetter, contextAdder)(post, caller)
+		_, err := service.NewPostCommentsGetter(commentsGetter, contextAdder)(post, caller, page)
 		AssertSomeError(t, err)
 	})
-	gotComments, err := service.NewPostCommentsGetter(commentsGetter, contextAdder)(post, caller)
+	gotComments, err := service.NewPostCommentsGetter(commentsGetter, contextAdder)(post, caller, page)
 	AssertNoError(t, err)
 	Assert(t, gotComments, contextedComments, "returned comments")
 }
+
+func TestCommentRepliesGetter(t *testing.T) {
+	comment := RandomId()
+	caller := RandomId()
+	replies := []entities.Comment{RandomComment()}
+	contextedReplies := []entities.ContextedComment{RandomContextedComment()}
+	page := pagination.Page{Limit: RandomInt()}
+
+	getComment := func(id values.CommentId) (entities.Comment, error) {
+		if id == comment {
+			return RandomComment(), nil
+		}
+		panic("unexpected args")
+	}
+	t.Run("error case - comment is not found", func(t *testing.T) {
+		getComment := func(values.CommentId) (entities.Comment, error) {
+			return entities.Comment{}, fmt.Errorf("wrapped: %w", core_err.ErrNotFound)
+		}
+		_, err := service.NewCommentRepliesGetter(getComment, nil, nil)(comment, caller, page)
+		AssertError(t, err, client_errors.NotFound)
+	})
+	t.Run("error case - getting comment throws", func(t *testing.T) {
+		getComment := func(values.CommentId) (entities.Comment, error) {
+			return entities.Comment{}, RandomError()
+		}
+		_, err := service.NewCommentRepliesGetter(getComment, nil, nil)(comment, caller, page)
+		AssertSomeError(t, err)
+	})
+	getReplies := func(parent values.CommentId, gotPage pagination.Page) ([]entities.Comment, error) {
+		if parent == comment && gotPage == page {
+			return replies, nil
+		}
+		panic("unexpected args")
+	}
+	t.Run("error case - getting replies throws", func(t *testing.T) {
+		getReplies := func(values.CommentId, pagination.Page) ([]entities.Comment, error) {
+			return nil, RandomError()
+		}
+		_, err := service.NewCommentRepliesGetter(getComment, getReplies, nil)(comment, caller, page)
+		AssertSomeError(t, err)
+	})
+	addContexts := func(commentList []entities.Comment, callerId core_values.UserId) ([]entities.ContextedComment, error) {
+		if reflect.DeepEqual(commentList, replies) && callerId == caller {
+			return contextedReplies, nil
+		}
+		panic("unexpected args")
+	}
+	t.Run("error case - adding contexts throws", func(t *testing.T) {
+		addContexts := func([]entities.Comment, core_values.UserId) ([]entities.ContextedComment, error) {
+			return nil, RandomError()
+		}
+		_, err := service.NewCommentRepliesGetter(getComment, getReplies, addContexts)(comment, caller, page)
+		AssertSomeError(t, err)
+	})
+	t.Run("happy case", func(t *testing.T) {
+		gotReplies, err := service.NewCommentRepliesGetter(getComment, getReplies, addContexts)(comment, caller, page)
+		AssertNoError(t, err)
+		Assert(t, gotReplies, contextedReplies, "returned replies")
+	})
+}
+
+func TestCommentDeleter(t *testing.T) {
+	comment := RandomComment()
+	post := RandomPostModel()
+	post.Id = comment.PostId
+
+	getComment := func(commentId values.CommentId) (entities.Comment, error) {
+		if commentId == comment.Id {
+			return comment, nil
+		}
+		panic("unexpected args")
+	}
+	t.Run("error case - comment not found", func(t *testing.T) {
+		getComment := func(values.CommentId) (entities.Comment, error) {
+			return entities.Comment{}, fmt.Errorf("wrapped: %w", core_err.ErrNotFound)
+		}
+		err := service.NewCommentDeleter(getComment, nil, nil)(comment.Id, comment.AuthorId)
+		AssertError(t, err, client_errors.NotFound)
+	})
+	t.Run("error case - getting comment throws", func(t *testing.T) {
+		getComment := func(values.CommentId) (entities.Comment, error) {
+			return entities.Comment{}, RandomError()
+		}
+		err := service.NewCommentDeleter(getComment, nil, nil)(comment.Id, comment.AuthorId)
+		AssertSomeError(t, err)
+	})
+	getPost := func(postId post_values.PostId) (post_models.PostModel, error) {
+		if postId == post.Id {
+			return post, nil
+		}
+		panic("unexpected args")
+	}
+	t.Run("error case - getting post throws", func(t *testing.T) {
+		getPost := func(post_values.PostId) (post_models.PostModel, error) {
+			return post_models.PostModel{}, RandomError()
+		}
+		err := service.NewCommentDeleter(getComment, getPost, nil)(comment.Id, post.AuthorId)
+		AssertSomeError(t, err)
+	})
+	t.Run("error case - caller is neither the comment author nor the post author", func(t *testing.T) {
+		err := service.NewCommentDeleter(getComment, getPost, nil)(comment.Id, RandomId())
+		AssertError(t, err, client_errors.InsufficientPermissions)
+	})
+	deleteComment := func(commentId values.CommentId, deletedAt time.Time) error {
+		if commentId == comment.Id && TimeAlmostNow(deletedAt) {
+			return nil
+		}
+		panic("unexpected args")
+	}
+	t.Run("error case - deleting throws", func(t *testing.T) {
+		deleteComment := func(values.CommentId, time.Time) error {
+			return RandomError()
+		}
+		err := service.NewCommentDeleter(getComment, getPost, deleteComment)(comment.Id, comment.AuthorId)
+		AssertSomeError(t, err)
+	})
+	t.Run("happy case - caller is the comment author", func(t *testing.T) {
+		err := service.NewCommentDeleter(getComment, nil, deleteComment)(comment.Id, comment.AuthorId)
+		AssertNoError(t, err)
+	})
+	t.Run("happy case - caller is the post author", func(t *testing.T) {
+		err := service.NewCommentDeleter(getComment, getPost, deleteComment)(comment.Id, post.AuthorId)
+		AssertNoError(t, err)
+	})
+}
+
+func TestCommentGetter(t *testing.T) {
+	commentId := RandomId()
+	caller := RandomId()
+	comment := RandomComment()
+	contextedComment := RandomContextedComment()
+
+	getComment := func(id values.CommentId) (entities.Comment, error) {
+		if id == commentId {
+			return comment, nil
+		}
+		panic("unexpected args")
+	}
+	t.Run("error case - comment is not found", func(t *testing.T) {
+		getComment := func(values.CommentId) (entities.Comment, error) {
+			return entities.Comment{}, fmt.Errorf("wrapped: %w", core_err.ErrNotFound)
+		}
+		_, err := service.NewCommentGetter(getComment, nil)(commentId, caller)
+		AssertError(t, err, client_errors.NotFound)
+	})
+	t.Run("error case - getting comment throws", func(t *testing.T) {
+		getComment := func(values.CommentId) (entities.Comment, error) {
+			return entities.Comment{}, RandomError()
+		}
+		_, err := service.NewCommentGetter(getComment, nil)(commentId, caller)
+		AssertSomeError(t, err)
+	})
+	addContext := func(gotComment entities.Comment, callerId core_values.UserId) (entities.ContextedComment, error) {
+		if reflect.DeepEqual(gotComment, comment) && callerId == caller {
+			return contextedComment, nil
+		}
+		panic("unexpected args")
+	}
+	t.Run("error case - adding context throws", func(t *testing.T) {
+		addContext := func(entities.Comment, core_values.UserId) (entities.ContextedComment, error) {
+			return entities.ContextedComment{}, RandomError()
+		}
+		_, err := service.NewCommentGetter(getComment, addContext)(commentId, caller)
+		AssertSomeError(t, err)
+	})
+	gotComment, err := service.NewCommentGetter(getComment, addContext)(commentId, caller)
+	AssertNoError(t, err)
+	Assert(t, gotComment, contextedComment, "returned comment")
+}
+
+func TestCommentUpdater(t *testing.T) {
+	comment := RandomId()
+	caller := RandomId()
+	newText := RandomString()
+	updatedComment := RandomContextedComment()
+
+	getOwner := func(commentId values.CommentId) (core_values.UserId, error) {
+		if commentId == comment {
+			return caller, nil
+		}
+		panic("unexpected args")
+	}
+	t.Run("error case - getting owner throws", func(t *testing.T) {
+		getOwner := func(values.CommentId) (core_values.UserId, error) {
+			return "", RandomError()
+		}
+		_, err := service.NewCommentUpdater(getOwner, nil, nil, nil, nil)(comment, caller, newText)
+		AssertSomeError(t, err)
+	})
+	t.Run("error case - comment is not found", func(t *testing.T) {
+		getOwner := func(values.CommentId) (core_values.UserId, error) {
+			return "", core_err.Rethrow("getting the owner", core_err.ErrNotFound)
+		}
+		_, err := service.NewCommentUpdater(getOwner, nil, nil, nil, nil)(comment, caller, newText)
+		AssertError(t, err, client_errors.NotFound)
+	})
+	t.Run("error case - caller is not the owner", func(t *testing.T) {
+		_, err := service.NewCommentUpdater(getOwner, nil, nil, nil, nil)(comment, RandomId(), newText)
+		AssertError(t, err, client_errors.InsufficientPermissions)
+	})
+	validate := func(newComment values.NewCommentValue) (client_errors.ClientError, bool) {
+		if newComment == (values.NewCommentValue{Author: caller, Text: newText}) {
+			return client_errors.ClientError{}, true
+		}
+		panic("unexpected args")
+	}
+	t.Run("error case - validation fails", func(t *testing.T) {
+		clientErr := RandomClientError()
+		validate := func(values.NewCommentValue) (client_errors.ClientError, bool) {
+			return clientErr, false
+		}
+		_, err := service.NewCommentUpdater(getOwner, validate, nil, nil, nil)(comment, caller, newText)
+		AssertError(t, err, clientErr)
+	})
+	mentions := RandomMentions()
+	resolveMentions := func(text string) ([]mentionable_values.Mention, error) {
+		if text == newText {
+			return mentions, nil
+		}
+		panic("unexpected args")
+	}
+	t.Run("error case - resolving mentions throws", func(t *testing.T) {
+		resolveMentions := func(string) ([]mentionable_values.Mention, error) {
+			return nil, RandomError()
+		}
+		_, err := service.NewCommentUpdater(getOwner, validate, resolveMentions, nil, nil)(comment, caller, newText)
+		AssertSomeError(t, err)
+	})
+	updateComment := func(commentId values.CommentId, text string, gotMentions []mentionable_values.Mention, editedAt time.Time) error {
+		if commentId == comment && text == newText && reflect.DeepEqual(gotMentions, mentions) && TimeAlmostNow(editedAt) {
+			return nil
+		}
+		panic("unexpected args")
+	}
+	t.Run("error case - updating throws", func(t *testing.T) {
+		updateComment := func(values.CommentId, string, []mentionable_values.Mention, time.Time) error {
+			return RandomError()
+		}
+		_, err := service.NewCommentUpdater(getOwner, validate, resolveMentions, updateComment, nil)(comment, caller, newText)
+		AssertSomeError(t, err)
+	})
+	t.Run("error case - comment is deleted", func(t *testing.T) {
+		updateComment := func(values.CommentId, string, []mentionable_values.Mention, time.Time) error {
+			return core_err.ErrNotFound
+		}
+		_, err := service.NewCommentUpdater(getOwner, validate, resolveMentions, updateComment, nil)(comment, caller, newText)
+		AssertError(t, err, client_errors.NotFound)
+	})
+	getUpdated := func(commentId values.CommentId, callerId core_values.UserId) (entities.ContextedComment, error) {
+		if commentId == comment && callerId == caller {
+			return updatedComment, nil
+		}
+		panic("unexpected args")
+	}
+	t.Run("happy case", func(t *testing.T) {
+		gotComment, err := service.NewCommentUpdater(getOwner, validate, resolveMentions, updateComment, getUpdated)(comment, caller, newText)
+		AssertNoError(t, err)
+		Assert(t, gotComment, updatedComment, "returned updated comment")
+	})
+}
+
+func TestCommentLikersGetter(t *testing.T) {
+	comment := RandomId()
+	caller := RandomId()
+	page := pagination.Page{Limit: RandomInt()}
+	wantLikers := []profile_entities.ContextedProfile{RandomContextedProfile()}
+	wantNext := pagination.Cursor{Id: RandomId()}
+
+	getAuthor := func(id values.CommentId) (core_values.UserId, error) {
+		if id == comment {
+			return RandomId(), nil
+		}
+		panic("unexpected args")
+	}
+	t.Run("error case - comment does not exist", func(t *testing.T) {
+		getAuthor := func(values.CommentId) (core_values.UserId, error) {
+			return "", client_errors.NotFound
+		}
+		_, _, err := service.NewCommentLikersGetter(getAuthor, nil)(comment, caller, page)
+		AssertError(t, err, client_errors.NotFound)
+	})
+	getLikers := func(target string, callerId core_values.UserId, gotPage pagination.Page) ([]profile_entities.ContextedProfile, pagination.Cursor, error) {
+		if target == comment && callerId == caller && gotPage == page {
+			return wantLikers, wantNext, nil
+		}
+		panic("unexpected args")
+	}
+	t.Run("error case - getting likers throws", func(t *testing.T) {
+		getLikers := func(string, core_values.UserId, pagination.Page) ([]profile_entities.ContextedProfile, pagination.Cursor, error) {
+			return nil, pagination.Cursor{}, RandomError()
+		}
+		_, _, err := service.NewCommentLikersGetter(getAuthor, getLikers)(comment, caller, page)
+		AssertSomeError(t, err)
+	})
+	t.Run("happy case", func(t *testing.T) {
+		likers, next, err := service.NewCommentLikersGetter(getAuthor, getLikers)(comment, caller, page)
+		AssertNoError(t, err)
+		Assert(t, likers, wantLikers, "returned likers")
+		Assert(t, next, wantNext, "cursor of the next page")
+	})
+}
+
+func TestPostCommentPreviewsGetter(t *testing.T) {
+	post1, post2 := RandomId(), RandomId()
+	posts := []post_values.PostId{post1, post2}
+	count := RandomInt()
+	caller := RandomId()
+	author1, author2 := RandomContextedProfile(), RandomContextedProfile()
+	comment1, comment2, comment3 := RandomCommentModel(), RandomCommentModel(), RandomCommentModel()
+	comment1.AuthorId, comment2.AuthorId, comment3.AuthorId = author1.Id, author2.Id, author1.Id
+	latest := map[post_values.PostId][]models.CommentModel{
+		post1: {comment1, comment2},
+		post2: {comment3},
+	}
+
+	getLatest := func(postIds []post_values.PostId, gotCount int) (map[post_values.PostId][]models.CommentModel, error) {
+		if reflect.DeepEqual(postIds, posts) && gotCount == count {
+			return latest, nil
+		}
+		panic("unexpected args")
+	}
+	t.Run("error case - getting latest comments throws", func(t *testing.T) {
+		getLatest := func([]post_values.PostId, int) (map[post_values.PostId][]models.CommentModel, error) {
+			return nil, RandomError()
+		}
+		_, err := service.NewPostCommentPreviewsGetter(getLatest, nil)(posts, count, caller)
+		AssertSomeError(t, err)
+	})
+	getAuthors := func(ids []core_values.UserId, callerId core_values.UserId) (map[core_values.UserId]profile_entities.ContextedProfile, error) {
+		if len(ids) == 2 && callerId == caller {
+			return map[core_values.UserId]profile_entities.ContextedProfile{author1.Id: author1, author2.Id: author2}, nil
+		}
+		panic("unexpected args")
+	}
+	t.Run("error case - getting authors throws", func(t *testing.T) {
+		getAuthors := func([]core_values.UserId, core_values.UserId) (map[core_values.UserId]profile_entities.ContextedProfile, error) {
+			return nil, RandomError()
+		}
+		_, err := service.NewPostCommentPreviewsGetter(getLatest, getAuthors)(posts, count, caller)
+		AssertSomeError(t, err)
+	})
+	t.Run("error case - author does not exist", func(t *testing.T) {
+		getAuthors := func([]core_values.UserId, core_values.UserId) (map[core_values.UserId]profile_entities.ContextedProfile, error) {
+			return map[core_values.UserId]profile_entities.ContextedProfile{author1.Id: author1}, nil
+		}
+		_, err := service.NewPostCommentPreviewsGetter(getLatest, getAuthors)(posts, count, caller)
+		Assert(t, errors.Is(err, core_err.ErrNotFound), true, "returned error is ErrNotFound")
+	})
+	t.Run("happy case", func(t *testing.T) {
+		previews, err := service.NewPostCommentPreviewsGetter(getLatest, getAuthors)(posts, count, caller)
+		AssertNoError(t, err)
+		preview := func(comment models.CommentModel, author profile_entities.ContextedProfile) post_entities.CommentPreview {
+			return post_entities.CommentPreview{Id: comment.Id, Author: author, Text: comment.Text, CreatedAt: comment.CreatedAt}
+		}
+		wantPreviews := map[post_values.PostId][]post_entities.CommentPreview{
+			post1: {preview(comment1, author1), preview(comment2, author2)},
+			post2: {preview(comment3, author1)},
+		}
+		Assert(t, previews, wantPreviews, "returned previews")
+	})
+	t.Run("happy case - no comments", func(t *testing.T) {
+		getLatest := func([]post_values.PostId, int) (map[post_values.PostId][]models.CommentModel, error) {
+			return map[post_values.PostId][]models.CommentModel{}, nil
+		}
+		previews, err := service.NewPostCommentPreviewsGetter(getLatest, nil)(posts, count, caller)
+		AssertNoError(t, err)
+		Assert(t, len(previews), 0, "number of returned previews")
+	})
+}
@@ -1,14 +1,25 @@
 package store
 
 import (
+	mentionable_values "github.com/k0marov/go-socnet/core/abstract/mentionable/values"
+	"github.com/k0marov/go-socnet/core/general/pagination"
 	"time"
 
 	"github.com/k0marov/go-socnet/features/comments/domain/entities"
+	"github.com/k0marov/go-socnet/features/comments/domain/models"
 	"github.com/k0marov/go-socnet/features/comments/domain/values"
 	post_values "github.com/k0marov/go-socnet/features/posts/domain/values"
 )
 
 type (
-	CommentsGetter func(post post_values.PostId) ([]entities.Comment, error)
-	Creator        func(newComment values.NewCommentValue, createdAt time.Time) (values.CommentId, error)
+	CommentsGetter func(post post_values.PostId, page pagination.Page) ([]entities.Comment, error)
+	RepliesGetter  func(parent values.CommentId, page pagination.Page) ([]entities.Comment, error)
+	CommentGetter  func(comment values.CommentId) (entities.Comment, error)
+	// CommentsByIdsGetter returns the comments in the order of provided ids, skipping the ones that don't exist
+	CommentsByIdsGetter func(ids []values.CommentId) ([]entities.Comment, error)
+	Creator             func(newComment values.NewCommentValue, mentions []mentionable_values.Mention, createdAt time.Time) (values.CommentId, error)
+	Updater             func(comment values.CommentId, newText string, mentions []mentionable_values.Mention, editedAt time.Time) error
+	Deleter             func(comment values.CommentId, deletedAt time.Time) error
+
+	LatestCommentsGetter func(posts []post_values.PostId, count int) (map[post_values.PostId][]models.CommentModel, error)
 )
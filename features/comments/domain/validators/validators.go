@@ -7,14 +7,12 @@ import (
 
 type CommentValidator func(values.NewCommentValue) (client_errors.ClientError, bool)
 
-const MaxTextLength = 255
-
-func NewCommentValidator() CommentValidator {
+func NewCommentValidator(maxTextLength int) CommentValidator {
 	return func(newComment values.NewCommentValue) (client_errors.ClientError, bool) {
 		if newComment.Text == "" {
 			return client_errors.EmptyText, false
 		}
-		if len(newComment.Text) > MaxTextLength {
+		if len(newComment.Text) > maxTextLength {
 			return client_errors.TextTooLong, false
 		}
 		return client_errors.ClientError{}, true
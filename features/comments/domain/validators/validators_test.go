@@ -11,6 +11,7 @@ import (
 )
 
 func TestCommentValidator(t *testing.T) {
+	const maxTextLength = 255
 	cases := []struct {
 		comment values.NewCommentValue
 
@@ -19,12 +20,13 @@ func TestCommentValidator(t *testing.T) {
 	}{
 		{values.NewCommentValue{Text: "Normal text"}, true, client_errors.ClientError{}},
 		{values.NewCommentValue{Text: ""}, false, client_errors.EmptyText},
-		{values.NewCommentValue{Text: strings.Repeat("looooong", 100)}, false, client_errors.TextTooLong},
+		{values.NewCommentValue{Text: strings.Repeat("a", maxTextLength)}, true, client_errors.ClientError{}},
+		{values.NewCommentValue{Text: strings.Repeat("a", maxTextLength+1)}, false, client_errors.TextTooLong},
 	}
 
 	for _, testCase := range cases {
 		t.Run(testCase.comment.Text, func(t *testing.T) {
-			gotErr, gotValid := validators.NewCommentValidator()(testCase.comment)
+			gotErr, gotValid := validators.NewCommentValidator(maxTextLength)(testCase.comment)
 			AssertFatal(t, gotValid, testCase.isValid, "the result of validation")
 			Assert(t, gotErr, testCase.wantErr, "the returned client error")
 		})
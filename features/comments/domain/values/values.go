@@ -10,5 +10,6 @@ type CommentId = string
 type NewCommentValue struct {
 	Author core_values.UserId
 	Post   post_values.PostId
+	Parent CommentId // empty for top-level comments
 	Text   string
 }
@@ -3,7 +3,11 @@ package integration_test
 import (
 	"bytes"
 	"encoding/json"
+	likeable_values "github.com/k0marov/go-socnet/core/abstract/likeable/values"
+	mentionable_responses "github.com/k0marov/go-socnet/core/abstract/mentionable/responses"
+	"github.com/k0marov/go-socnet/core/general/client_errors"
 	"github.com/k0marov/go-socnet/core/general/core_values"
+	"github.com/k0marov/go-socnet/core/general/events"
 	helpers "github.com/k0marov/go-socnet/core/helpers/http_test_helpers"
 	. "github.com/k0marov/go-socnet/core/helpers/test_helpers"
 	"net/http"
@@ -16,6 +20,7 @@ import (
 	"github.com/k0marov/go-socnet/features/comments/delivery/http/handlers"
 	"github.com/k0marov/go-socnet/features/comments/delivery/http/responses"
 	"github.com/k0marov/go-socnet/features/comments/domain/values"
+	"github.com/k0marov/go-socnet/features/posts"
 	post_models "github.com/k0marov/go-socnet/features/posts/domain/models"
 	post_values "github.com/k0marov/go-socnet/features/posts/domain/values"
 	posts_db "github.com/k0marov/go-socnet/features/posts/store/sql_db"
@@ -27,15 +32,16 @@ import (
 
 func TestComments(t *testing.T) {
 	// db
-	sql := OpenSqliteDB(t)
+	cfg := TestConfig(t)
+	sql := OpenTestDB(t)
 	r := chi.NewRouter()
 	// profiles
 	fakeRegisterProfile := profiles.NewRegisterCallback(sql)
-	getProfile := profiles.NewProfileGetterImpl(sql)
+	getProfile := profiles.NewProfileGetterImpl(cfg, sql)
 	// posts
 	postsDB, _ := posts_db.NewSqlDB(sql)
 	createPost := func(author core_values.UserId) post_values.PostId {
-		id, _ := postsDB.CreatePost(post_models.PostToCreate{
+		id, _ := postsDB.CreatePost(sql, post_models.PostToCreate{
 			Author:    author,
 			Text:      RandomString(),
 			CreatedAt: RandomTime(),
@@ -43,7 +49,7 @@ func TestComments(t *testing.T) {
 		return id
 	}
 	// comments
-	r.Route("/comments", comments.NewCommentsRouterImpl(sql, getProfile))
+	r.Route("/comments", comments.NewCommentsRouterImpl(cfg, sql, getProfile, posts.NewPostModelGetterImpl(sql), profiles.NewUsernamesResolverImpl(sql), events.NewPublisher()))
 
 	assertComments := func(t testing.TB, got, want []responses.CommentResponse) {
 		t.Helper()
@@ -52,15 +58,20 @@ func TestComments(t *testing.T) {
 			Assert(t, comment, want[i], "comment")
 		}
 	}
-	addComment := func(t testing.TB, post post_values.PostId, caller auth.User) responses.CommentResponse {
+	postComment := func(t testing.TB, post post_values.PostId, newComment handlers.NewCommentRequest, caller auth.User) *httptest.ResponseRecorder {
 		t.Helper()
-
-		newComment := handlers.NewCommentRequest{Text: RandomString()}
 		body := bytes.NewBuffer(nil)
 		json.NewEncoder(body).Encode(newComment)
 		request := helpers.AddAuthDataToRequest(httptest.NewRequest(http.MethodPost, "/comments/?post_id="+post, body), caller)
 		response := httptest.NewRecorder()
 		r.ServeHTTP(response, request)
+		return response
+	}
+	addReply := func(t testing.TB, post post_values.PostId, parent values.CommentId, caller auth.User) responses.CommentResponse {
+		t.Helper()
+
+		newComment := handlers.NewCommentRequest{Text: RandomString(), ParentId: parent}
+		response := postComment(t, post, newComment, caller)
 
 		AssertStatusCode(t, response, http.StatusOK)
 		var returnedComment responses.CommentResponse
@@ -69,21 +80,28 @@ func TestComments(t *testing.T) {
 		wantAuthor, _ := getProfile(caller.Id, caller.Id)
 		wantComment := responses.CommentResponse{
 			Id:        returnedComment.Id,
+			ParentId:  parent,
 			Author:    profile_responses.NewProfileResponse(wantAuthor),
 			Text:      newComment.Text,
 			CreatedAt: time.Now().Unix(),
 			Likes:     0,
+			Reactions: likeable_values.NewReactionCounts(),
 			IsLiked:   false,
 			IsMine:    true,
+			Mentions:  []mentionable_responses.MentionResponse{},
 		}
 		assertComments(t, []responses.CommentResponse{returnedComment}, []responses.CommentResponse{wantComment})
 
 		return returnedComment
 	}
-	getComments := func(t testing.TB, post post_values.PostId, caller auth.User) []responses.CommentResponse {
+	addComment := func(t testing.TB, post post_values.PostId, caller auth.User) responses.CommentResponse {
+		t.Helper()
+		return addReply(t, post, "", caller)
+	}
+	getCommentList := func(t testing.TB, url string, caller auth.User) []responses.CommentResponse {
 		t.Helper()
 
-		request := helpers.AddAuthDataToRequest(httptest.NewRequest(http.MethodGet, "/comments/?post_id="+post, nil), caller)
+		request := helpers.AddAuthDataToRequest(httptest.NewRequest(http.MethodGet, url, nil), caller)
 		response := httptest.NewRecorder()
 		r.ServeHTTP(response, request)
 
@@ -93,6 +111,14 @@ func TestComments(t *testing.T) {
 
 		return commentsResponse.Comments
 	}
+	getComments := func(t testing.TB, post post_values.PostId, caller auth.User) []responses.CommentResponse {
+		t.Helper()
+		return getCommentList(t, "/comments/?post_id="+post, caller)
+	}
+	getReplies := func(t testing.TB, comment values.CommentId, caller auth.User) []responses.CommentResponse {
+		t.Helper()
+		return getCommentList(t, "/comments/"+comment+"/replies", caller)
+	}
 	toggleLike := func(t testing.TB, comment values.CommentId, caller auth.User) {
 		t.Helper()
 		request := helpers.AddAuthDataToRequest(httptest.NewRequest(http.MethodPost, "/comments/"+comment+"/toggle-like", nil), caller)
@@ -100,12 +126,25 @@ func TestComments(t *testing.T) {
 		r.ServeHTTP(response, request)
 		AssertStatusCode(t, response, http.StatusOK)
 	}
-	deleteComment := func(t testing.TB, comment values.CommentId, caller auth.User) {
+	setReaction := func(t testing.TB, comment values.CommentId, caller auth.User, reaction likeable_values.Reaction) *httptest.ResponseRecorder {
+		t.Helper()
+		body := bytes.NewBuffer(nil)
+		json.NewEncoder(body).Encode(handlers.SetReactionRequest{Reaction: reaction})
+		request := helpers.AddAuthDataToRequest(httptest.NewRequest(http.MethodPut, "/comments/"+comment+"/reaction", body), caller)
+		response := httptest.NewRecorder()
+		r.ServeHTTP(response, request)
+		return response
+	}
+	requestDelete := func(t testing.TB, comment values.CommentId, caller auth.User) *httptest.ResponseRecorder {
 		t.Helper()
 		request := helpers.AddAuthDataToRequest(httptest.NewRequest(http.MethodDelete, "/comments/"+comment, nil), caller)
 		response := httptest.NewRecorder()
 		r.ServeHTTP(response, request)
-		AssertStatusCode(t, response, http.StatusOK)
+		return response
+	}
+	deleteComment := func(t testing.TB, comment values.CommentId, caller auth.User) {
+		t.Helper()
+		AssertStatusCode(t, requestDelete(t, comment, caller), http.StatusOK)
 	}
 
 	t.Run("creating, reading and deleting comments", func(t *testing.T) {
@@ -145,7 +184,37 @@ func TestComments(t *testing.T) {
 			comments = getComments(t, post, user1)
 			Assert(t, comments[0].IsLiked, false, "isLiked")
 		})
-		// delete the second comment
+		t.Run("reacting to comments", func(t *testing.T) {
+			assertReactions := func(t testing.TB, reaction likeable_values.Reaction, counts likeable_values.ReactionCounts) {
+				t.Helper()
+				comment := getComments(t, post, user1)[0]
+				Assert(t, comment.Reaction, reaction, "caller's reaction")
+				Assert(t, comment.IsLiked, reaction != "", "isLiked")
+				Assert(t, comment.Reactions, counts, "reaction counts")
+				Assert(t, comment.Likes, counts.Total(), "likes count")
+			}
+			AssertStatusCode(t, setReaction(t, comment2.Id, user1, likeable_values.ReactionLove), http.StatusOK)
+			counts := likeable_values.NewReactionCounts()
+			counts[likeable_values.ReactionLove] = 1
+			assertReactions(t, likeable_values.ReactionLove, counts)
+
+			// changing the reaction keeps a single like
+			AssertStatusCode(t, setReaction(t, comment2.Id, user1, likeable_values.ReactionSad), http.StatusOK)
+			counts = likeable_values.NewReactionCounts()
+			counts[likeable_values.ReactionSad] = 1
+			assertReactions(t, likeable_values.ReactionSad, counts)
+
+			AssertClientError(t, setReaction(t, comment2.Id, user1, "wow"), client_errors.InvalidReaction)
+			AssertClientError(t, setReaction(t, comment2.Id, user2, likeable_values.ReactionLove), client_errors.LikingYourself)
+
+			request := helpers.AddAuthDataToRequest(httptest.NewRequest(http.MethodDelete, "/comments/"+comment2.Id+"/reaction", nil), user1)
+			response := httptest.NewRecorder()
+			r.ServeHTTP(response, request)
+			AssertStatusCode(t, response, http.StatusOK)
+			assertReactions(t, "", likeable_values.NewReactionCounts())
+		})
+		// delete the second comment, which is liked
+		toggleLike(t, comment2.Id, user1)
 		deleteComment(t, comment2.Id, user2)
 		// assert it was deleted
 		comments = getComments(t, post, user2)
@@ -155,4 +224,110 @@ func TestComments(t *testing.T) {
 		// assert it was deleted
 		assertComments(t, getComments(t, post, user2), []responses.CommentResponse{})
 	})
+	t.Run("replying to comments", func(t *testing.T) {
+		user1 := RandomAuthUser()
+		user2 := RandomAuthUser()
+		fakeRegisterProfile(user1)
+		fakeRegisterProfile(user2)
+		post := createPost(user1.Id)
+
+		comment := addComment(t, post, user1)
+		reply := addReply(t, post, comment.Id, user2)
+		nestedReply := addReply(t, post, reply.Id, user1)
+
+		// only top-level comments are listed for the post, along with the number of their replies
+		comment.Replies = 1
+		assertComments(t, getComments(t, post, user1), []responses.CommentResponse{comment})
+		reply.Replies = 1
+		assertComments(t, getReplies(t, comment.Id, user2), []responses.CommentResponse{reply})
+		assertComments(t, getReplies(t, reply.Id, user1), []responses.CommentResponse{nestedReply})
+
+		// the parent should belong to the same post
+		otherPost := createPost(user1.Id)
+		response := postComment(t, otherPost, handlers.NewCommentRequest{Text: RandomString(), ParentId: comment.Id}, user2)
+		AssertClientError(t, response, client_errors.InvalidParentComment)
+
+		// deleting a comment with replies leaves a tombstone in its place
+		deleteComment(t, comment.Id, user1)
+		comments := getComments(t, post, user2)
+		AssertFatal(t, len(comments), 1, "number of comments")
+		Assert(t, comments[0].Id, comment.Id, "id of the tombstone")
+		Assert(t, comments[0].Text, "", "text of the tombstone")
+		Assert(t, comments[0].DeletedAt != 0, true, "the tombstone is marked as deleted")
+		assertComments(t, getReplies(t, comment.Id, user2), []responses.CommentResponse{reply})
+
+		// a tombstone cannot be replied to
+		response = postComment(t, post, handlers.NewCommentRequest{Text: RandomString(), ParentId: comment.Id}, user2)
+		AssertClientError(t, response, client_errors.InvalidParentComment)
+
+		// deleting a comment without replies removes it
+		deleteComment(t, nestedReply.Id, user1)
+		reply.Replies = 0
+		assertComments(t, getReplies(t, comment.Id, user2), []responses.CommentResponse{reply})
+
+		// deleting the last reply of a tombstone removes the tombstone too
+		deleteComment(t, reply.Id, user2)
+		assertComments(t, getComments(t, post, user2), []responses.CommentResponse{})
+
+		request := helpers.AddAuthDataToRequest(httptest.NewRequest(http.MethodGet, "/comments/9999999/replies", nil), user1)
+		notFound := httptest.NewRecorder()
+		r.ServeHTTP(notFound, request)
+		AssertClientError(t, notFound, client_errors.NotFound)
+	})
+	t.Run("moderating comments of own post", func(t *testing.T) {
+		postAuthor := RandomAuthUser()
+		commenter := RandomAuthUser()
+		stranger := RandomAuthUser()
+		fakeRegisterProfile(postAuthor)
+		fakeRegisterProfile(commenter)
+		fakeRegisterProfile(stranger)
+		post := createPost(postAuthor.Id)
+
+		comment1 := addComment(t, post, commenter)
+		comment2 := addComment(t, post, commenter)
+
+		// only the comment author or the post author can delete a comment
+		AssertClientError(t, requestDelete(t, comment1.Id, stranger), client_errors.InsufficientPermissions)
+		deleteComment(t, comment1.Id, postAuthor)
+		assertComments(t, getComments(t, post, commenter), []responses.CommentResponse{comment2})
+
+		// new comments can't be added after the post author disables them
+		err := postsDB.SetCommentsDisabled(post, true)
+		AssertNoError(t, err)
+		response := postComment(t, post, handlers.NewCommentRequest{Text: RandomString()}, commenter)
+		AssertClientError(t, response, client_errors.CommentsDisabled)
+		assertComments(t, getComments(t, post, commenter), []responses.CommentResponse{comment2})
+
+		// commenting a post that doesn't exist
+		response = postComment(t, "9999999", handlers.NewCommentRequest{Text: RandomString()}, commenter)
+		AssertClientError(t, response, client_errors.NotFound)
+	})
+	t.Run("mentioning profiles in comments", func(t *testing.T) {
+		author := RandomAuthUser()
+		mentioned := auth.User{Id: RandomId(), Username: "mentioned_" + RandomId()}
+		fakeRegisterProfile(author)
+		fakeRegisterProfile(mentioned)
+		post := createPost(author.Id)
+
+		// mentions of unknown usernames are left as plain text
+		text := "hi @" + mentioned.Username + " and @nobody_here"
+		response := postComment(t, post, handlers.NewCommentRequest{Text: text}, author)
+		AssertStatusCode(t, response, http.StatusOK)
+		var comment responses.CommentResponse
+		json.NewDecoder(response.Body).Decode(&comment)
+		wantMentions := []mentionable_responses.MentionResponse{
+			{ProfileId: mentioned.Id, Offset: 3, Length: len(mentioned.Username) + 1},
+		}
+		Assert(t, comment.Mentions, wantMentions, "mentions of the created comment")
+		Assert(t, getComments(t, post, author)[0].Mentions, wantMentions, "mentions of the stored comment")
+
+		// editing the text replaces the mentions
+		body := bytes.NewBuffer(nil)
+		json.NewEncoder(body).Encode(handlers.UpdateCommentRequest{Text: "no mentions anymore"})
+		request := helpers.AddAuthDataToRequest(httptest.NewRequest(http.MethodPut, "/comments/"+comment.Id, body), author)
+		response = httptest.NewRecorder()
+		r.ServeHTTP(response, request)
+		AssertStatusCode(t, response, http.StatusOK)
+		Assert(t, getComments(t, post, author)[0].Mentions, []mentionable_responses.MentionResponse{}, "mentions of the edited comment")
+	})
 }
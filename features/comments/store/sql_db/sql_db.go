@@ -1,10 +1,13 @@
 package sql_db
 
 import (
+	"database/sql"
 	"fmt"
 	"github.com/jmoiron/sqlx"
 	"github.com/k0marov/go-socnet/core/abstract/table_name"
 	"github.com/k0marov/go-socnet/core/general/core_err"
+	"github.com/k0marov/go-socnet/core/general/pagination"
+	"github.com/k0marov/go-socnet/core/general/unit_of_work"
 	"time"
 
 	"github.com/k0marov/go-socnet/features/comments/domain/models"
@@ -17,57 +20,244 @@ type SqlDB struct {
 	TableName table_name.TableName
 }
 
+// NewSqlDB expects the schema to be already migrated with core/general/migrations
 func NewSqlDB(db *sqlx.DB) (*SqlDB, error) {
-	err := initSQL(db)
-	if err != nil {
-		return nil, core_err.Rethrow("initializing sql for comments", err)
-	}
 	return &SqlDB{db, table_name.NewTableName("Comment")}, nil
 }
 
-func initSQL(db *sqlx.DB) error {
-	_, err := db.Exec(`
-		CREATE TABLE IF NOT EXISTS Comment(
-		    id INTEGER PRIMARY KEY, 
-		   	post_id INT NOT NULL, 
-		   	owner_id INT NOT NULL, 
-		   	textContent TEXT NOT NULL, 
-		   	createdAt INT NOT NULL, 
-		   	FOREIGN KEY(post_id) REFERENCES Post(id) ON DELETE CASCADE, 
-		   	FOREIGN KEY(owner_id) REFERENCES Profile(id) ON DELETE CASCADE
-		)
-   `)
+// commentColumns selects the columns of models.CommentModel from the Comment table
+const commentColumns = `
+	id, post_id, COALESCE(CAST(parent_id AS VARCHAR(255)), '') AS parent_id, owner_id, textContent, createdAt, editedAt, deletedAt,
+	(SELECT COUNT(*) FROM Comment AS Reply WHERE Reply.parent_id = Comment.id) AS replies
+`
+
+func (db *SqlDB) GetComment(id values.CommentId) (models.CommentModel, error) {
+	var comment models.CommentModel
+	err := db.sql.Get(&comment, db.sql.Rebind(`
+		SELECT `+commentColumns+`
+		FROM Comment
+		WHERE id = ?
+    `), id)
+	if err == sql.ErrNoRows {
+		return models.CommentModel{}, core_err.ErrNotFound
+	}
 	if err != nil {
-		return core_err.Rethrow("creating Comment table", err)
+		return models.CommentModel{}, core_err.Rethrow("SELECTing a comment", err)
 	}
-	return nil
+	return comment, nil
 }
 
-func (db *SqlDB) GetComments(post post_values.PostId) ([]models.CommentModel, error) {
+// GetComments returns the top-level comments of a post
+func (db *SqlDB) GetComments(post post_values.PostId, page pagination.Page) ([]models.CommentModel, error) {
+	cond, condArgs := page.Condition("createdAt", "id")
+	args := append([]any{post}, condArgs...)
 	var comments []models.CommentModel
-	err := db.sql.Select(&comments, `
-		SELECT id, owner_id, textContent, createdAt
+	err := db.sql.Select(&comments, db.sql.Rebind(`
+		SELECT `+commentColumns+`
 		FROM Comment 
-		WHERE post_id = ?
-		ORDER BY createdAt DESC
-    `, post)
+		WHERE post_id = ? AND parent_id IS NULL AND `+cond+`
+		ORDER BY createdAt DESC, id DESC
+		LIMIT ?
+    `), append(args, page.Limit)...)
 	if err != nil {
 		return []models.CommentModel{}, core_err.Rethrow("SELECTing post comments", err)
 	}
 	return comments, nil
 }
 
-func (db *SqlDB) Create(newComment values.NewCommentValue, createdAt time.Time) (values.CommentId, error) {
-	res, err := db.sql.Exec(`
-		INSERT INTO Comment(post_id, owner_id, textContent, createdAt) 
-		VALUES (?, ?, ?, ?)
-    `, newComment.Post, newComment.Author, newComment.Text, createdAt.Unix())
+func (db *SqlDB) GetReplies(parent values.CommentId, page pagination.Page) ([]models.CommentModel, error) {
+	cond, condArgs := page.Condition("createdAt", "id")
+	args := append([]any{parent}, condArgs...)
+	var replies []models.CommentModel
+	err := db.sql.Select(&replies, db.sql.Rebind(`
+		SELECT `+commentColumns+`
+		FROM Comment
+		WHERE parent_id = ? AND `+cond+`
+		ORDER BY createdAt DESC, id DESC
+		LIMIT ?
+    `), append(args, page.Limit)...)
 	if err != nil {
-		return "", core_err.Rethrow("INSERTing a new comment", err)
+		return []models.CommentModel{}, core_err.Rethrow("SELECTing comment replies", err)
+	}
+	return replies, nil
+}
+
+// GetCommentsByIds returns the comments in the order of provided ids, skipping the ones that don't exist
+func (db *SqlDB) GetCommentsByIds(ids []values.CommentId) ([]models.CommentModel, error) {
+	if len(ids) == 0 {
+		return []models.CommentModel{}, nil
+	}
+	query, args, err := sqlx.In(`
+		SELECT `+commentColumns+`
+		FROM Comment
+		WHERE id IN (?)
+	`, ids)
+	if err != nil {
+		return []models.CommentModel{}, core_err.Rethrow("building the query for comments by ids", err)
+	}
+	var found []models.CommentModel
+	err = db.sql.Select(&found, db.sql.Rebind(query), args...)
+	if err != nil {
+		return []models.CommentModel{}, core_err.Rethrow("SELECTing comments by ids", err)
 	}
-	newId, err := res.LastInsertId()
+	foundById := map[values.CommentId]models.CommentModel{}
+	for _, comment := range found {
+		foundById[comment.Id] = comment
+	}
+	comments := []models.CommentModel{}
+	for _, id := range ids {
+		if comment, ok := foundById[id]; ok {
+			comments = append(comments, comment)
+		}
+	}
+	return comments, nil
+}
+
+// GetCommentCounts returns the number of comments and replies of each post, not counting tombstones.
+// The posts without comments are missing from the map.
+func (db *SqlDB) GetCommentCounts(posts []post_values.PostId) (map[post_values.PostId]int, error) {
+	counts := map[post_values.PostId]int{}
+	if len(posts) == 0 {
+		return counts, nil
+	}
+	query, args, err := sqlx.In(`
+		SELECT post_id, COUNT(*) AS comments
+		FROM Comment
+		WHERE post_id IN (?) AND deletedAt = 0
+		GROUP BY post_id
+	`, posts)
 	if err != nil {
-		return "", core_err.Rethrow("getting the ID of newly inserted comment", err)
+		return nil, core_err.Rethrow("building the query for comment counts", err)
+	}
+	var rows []struct {
+		PostId   post_values.PostId `db:"post_id"`
+		Comments int                `db:"comments"`
+	}
+	err = db.sql.Select(&rows, db.sql.Rebind(query), args...)
+	if err != nil {
+		return nil, core_err.Rethrow("SELECTing comment counts of posts", err)
+	}
+	for _, row := range rows {
+		counts[row.PostId] = row.Comments
+	}
+	return counts, nil
+}
+
+// GetLatestComments returns up to count latest top-level comments of each post, newest first, skipping tombstones.
+// The numbers of replies of the returned comments are not filled in.
+func (db *SqlDB) GetLatestComments(posts []post_values.PostId, count int) (map[post_values.PostId][]models.CommentModel, error) {
+	latest := map[post_values.PostId][]models.CommentModel{}
+	if len(posts) == 0 {
+		return latest, nil
+	}
+	query, args, err := sqlx.In(`
+		SELECT id, post_id, owner_id, textContent, createdAt, editedAt
+		FROM (
+			SELECT id, post_id, owner_id, textContent, createdAt, editedAt,
+				ROW_NUMBER() OVER (PARTITION BY post_id ORDER BY createdAt DESC, id DESC) AS position
+			FROM Comment
+			WHERE post_id IN (?) AND parent_id IS NULL AND deletedAt = 0
+		) AS Latest
+		WHERE position <= ?
+		ORDER BY post_id, createdAt DESC, id DESC
+	`, posts, count)
+	if err != nil {
+		return nil, core_err.Rethrow("building the query for latest comments", err)
+	}
+	var comments []models.CommentModel
+	err = db.sql.Select(&comments, db.sql.Rebind(query), args...)
+	if err != nil {
+		return nil, core_err.Rethrow("SELECTing latest comments of posts", err)
+	}
+	for _, comment := range comments {
+		latest[comment.PostId] = append(latest[comment.PostId], comment)
+	}
+	return latest, nil
+}
+
+func (db *SqlDB) Create(ex unit_of_work.Executor, newComment values.NewCommentValue, createdAt time.Time) (values.CommentId, error) {
+	var newId int64
+	err := ex.QueryRowx(ex.Rebind(`
+		INSERT INTO Comment(post_id, parent_id, owner_id, textContent, createdAt) 
+		VALUES (?, ?, ?, ?, ?)
+		RETURNING id
+    `), newComment.Post, sql.NullString{String: newComment.Parent, Valid: newComment.Parent != ""}, newComment.Author, newComment.Text, createdAt.Unix()).Scan(&newId)
+	if err != nil {
+		return "", core_err.Rethrow("INSERTing a new comment", err)
 	}
 	return fmt.Sprintf("%d", newId), nil
 }
+
+// Update returns core_err.ErrNotFound if the comment doesn't exist or is a tombstone
+func (db *SqlDB) Update(ex unit_of_work.Executor, id values.CommentId, newText string, editedAt time.Time) error {
+	res, err := ex.Exec(ex.Rebind(`
+		UPDATE Comment SET textContent = ?, editedAt = ? WHERE id = ? AND deletedAt = 0
+    `), newText, editedAt.Unix(), id)
+	if err != nil {
+		return core_err.Rethrow("UPDATEing a comment", err)
+	}
+	updated, err := res.RowsAffected()
+	if err != nil {
+		return core_err.Rethrow("getting the number of updated comments", err)
+	}
+	if updated == 0 {
+		return core_err.ErrNotFound
+	}
+	return nil
+}
+
+// Delete removes a comment, or replaces it with a tombstone if it has replies, so that they are kept.
+// A tombstone whose last reply was removed is removed as well.
+func (db *SqlDB) Delete(ex unit_of_work.Executor, id values.CommentId, deletedAt time.Time) error {
+	var replies int
+	err := ex.Get(&replies, ex.Rebind(`
+		SELECT COUNT(*) FROM Comment WHERE parent_id = ?
+    `), id)
+	if err != nil {
+		return core_err.Rethrow("counting the replies of a comment", err)
+	}
+	if replies > 0 {
+		_, err = ex.Exec(ex.Rebind(`
+			UPDATE Comment SET textContent = '', deletedAt = ? WHERE id = ?
+		`), deletedAt.Unix(), id)
+		if err != nil {
+			return core_err.Rethrow("replacing a comment with a tombstone", err)
+		}
+		return nil
+	}
+	for {
+		var parent values.CommentId
+		err = ex.Get(&parent, ex.Rebind(`
+			SELECT COALESCE(CAST(parent_id AS VARCHAR(255)), '') FROM Comment WHERE id = ?
+		`), id)
+		if err == sql.ErrNoRows {
+			return nil
+		}
+		if err != nil {
+			return core_err.Rethrow("getting the parent of a comment", err)
+		}
+		_, err = ex.Exec(ex.Rebind(`
+			DELETE FROM Comment WHERE id = ?
+		`), id)
+		if err != nil {
+			return core_err.Rethrow("deleting a comment", err)
+		}
+		if parent == "" {
+			return nil
+		}
+		var orphanedTombstone bool
+		err = ex.Get(&orphanedTombstone, ex.Rebind(`
+			SELECT EXISTS(
+				SELECT 1 FROM Comment WHERE id = ? AND deletedAt != 0
+				AND NOT EXISTS(SELECT 1 FROM Comment AS Reply WHERE Reply.parent_id = Comment.id)
+			)
+		`), parent)
+		if err != nil {
+			return core_err.Rethrow("checking whether the parent of a comment is an orphaned tombstone", err)
+		}
+		if !orphanedTombstone {
+			return nil
+		}
+		id = parent
+	}
+}
@@ -1,7 +1,10 @@
 package sql_db_test
 
 import (
+	"github.com/jmoiron/sqlx"
+	"github.com/k0marov/go-socnet/core/general/core_err"
 	"github.com/k0marov/go-socnet/core/general/core_values"
+	"github.com/k0marov/go-socnet/core/general/pagination"
 	. "github.com/k0marov/go-socnet/core/helpers/test_helpers"
 	"testing"
 	"time"
@@ -17,47 +20,82 @@ import (
 )
 
 func TestSqlDB_ErrorHandling(t *testing.T) {
-	db := OpenSqliteDB(t)
+	db := OpenTestDB(t)
 	sqlDB, err := sql_db.NewSqlDB(db)
 	AssertNoError(t, err)
 	db.Close() // this will make all calls to db throw
 	t.Run("GetComments", func(t *testing.T) {
-		_, err := sqlDB.GetComments(RandomString())
+		_, err := sqlDB.GetComments(RandomString(), pagination.Page{Limit: 10})
+		AssertSomeError(t, err)
+	})
+	t.Run("GetReplies", func(t *testing.T) {
+		_, err := sqlDB.GetReplies(RandomId(), pagination.Page{Limit: 10})
+		AssertSomeError(t, err)
+	})
+	t.Run("GetCommentsByIds", func(t *testing.T) {
+		_, err := sqlDB.GetCommentsByIds([]values.CommentId{RandomId()})
+		AssertSomeError(t, err)
+	})
+	t.Run("GetCommentCounts", func(t *testing.T) {
+		_, err := sqlDB.GetCommentCounts([]post_values.PostId{RandomId()})
+		AssertSomeError(t, err)
+	})
+	t.Run("GetLatestComments", func(t *testing.T) {
+		_, err := sqlDB.GetLatestComments([]post_values.PostId{RandomId()}, 2)
+		AssertSomeError(t, err)
+	})
+	t.Run("GetComment", func(t *testing.T) {
+		_, err := sqlDB.GetComment(RandomId())
+		AssertSomeError(t, err)
+	})
+	t.Run("Update", func(t *testing.T) {
+		err := sqlDB.Update(db, RandomId(), RandomString(), RandomTime())
 		AssertSomeError(t, err)
 	})
 	t.Run("Create", func(t *testing.T) {
-		_, err := sqlDB.Create(RandomNewComment(), RandomTime())
+		_, err := sqlDB.Create(db, RandomNewComment(), RandomTime())
+		AssertSomeError(t, err)
+	})
+	t.Run("Delete", func(t *testing.T) {
+		err := sqlDB.Delete(db, RandomId(), RandomTime())
 		AssertSomeError(t, err)
 	})
 }
 
 func TestSqlDB(t *testing.T) {
-	createComment := func(t testing.TB, db *sql_db.SqlDB, post post_values.PostId, author core_values.UserId, yearCreatedAt int) models.CommentModel {
+	createReply := func(t testing.TB, db *sqlx.DB, sqlDB *sql_db.SqlDB, post post_values.PostId, parent values.CommentId, author core_values.UserId, yearCreatedAt int) models.CommentModel {
 		t.Helper()
 		newComment := values.NewCommentValue{
 			Author: author,
 			Post:   post,
+			Parent: parent,
 			Text:   RandomString(),
 		}
 		createdAt := time.Date(yearCreatedAt, 0, 0, 0, 0, 0, 0, time.UTC)
-		id, err := db.Create(newComment, createdAt)
+		id, err := sqlDB.Create(db, newComment, createdAt)
 		AssertNoError(t, err)
 		return models.CommentModel{
 			Id:        id,
+			PostId:    post,
+			ParentId:  parent,
 			AuthorId:  author,
 			Text:      newComment.Text,
 			CreatedAt: createdAt.Unix(),
 		}
 	}
+	createComment := func(t testing.TB, db *sqlx.DB, sqlDB *sql_db.SqlDB, post post_values.PostId, author core_values.UserId, yearCreatedAt int) models.CommentModel {
+		t.Helper()
+		return createReply(t, db, sqlDB, post, "", author, yearCreatedAt)
+	}
 	getComments := func(t testing.TB, db *sql_db.SqlDB, post post_values.PostId) []models.CommentModel {
 		t.Helper()
-		comments, err := db.GetComments(post)
+		comments, err := db.GetComments(post, pagination.Page{Limit: pagination.MaxLimit})
 		AssertNoError(t, err)
 		return comments
 	}
 
 	t.Run("creating and reading comments", func(t *testing.T) {
-		db := OpenSqliteDB(t)
+		db := OpenTestDB(t)
 		sqlDB, err := sql_db.NewSqlDB(db)
 		AssertNoError(t, err)
 		profilesDb, _ := profiles_db.NewSqlDB(db)
@@ -68,7 +106,7 @@ func TestSqlDB(t *testing.T) {
 		profilesDb.CreateProfile(author)
 
 		// create a post
-		postId, _ := postsDb.CreatePost(post_models.PostToCreate{
+		postId, _ := postsDb.CreatePost(db, post_models.PostToCreate{
 			Author:    author.Id,
 			Text:      RandomString(),
 			CreatedAt: time.Now(),
@@ -79,7 +117,7 @@ func TestSqlDB(t *testing.T) {
 		profilesDb.CreateProfile(commenter)
 
 		// create the first comment
-		firstComment := createComment(t, sqlDB, postId, commenter.Id, 2020)
+		firstComment := createComment(t, db, sqlDB, postId, commenter.Id, 2020)
 
 		// assert it was created
 		comments := getComments(t, sqlDB, postId)
@@ -87,7 +125,7 @@ func TestSqlDB(t *testing.T) {
 		Assert(t, comments[0], firstComment, "the created comment")
 
 		// create the second comment
-		secondComment := createComment(t, sqlDB, postId, commenter.Id, 2022)
+		secondComment := createComment(t, db, sqlDB, postId, commenter.Id, 2022)
 
 		// assert it was created (and comments are returned ordered by createdAt)
 		comments = getComments(t, sqlDB, postId)
@@ -95,4 +133,207 @@ func TestSqlDB(t *testing.T) {
 		Assert(t, comments[0], secondComment, "the second created comment")
 		Assert(t, comments[1], firstComment, "the first created comment")
 	})
+	t.Run("getting comments by ids", func(t *testing.T) {
+		db := OpenTestDB(t)
+		sqlDB, err := sql_db.NewSqlDB(db)
+		AssertNoError(t, err)
+		profilesDb, _ := profiles_db.NewSqlDB(db)
+		postsDb, _ := posts_db.NewSqlDB(db)
+
+		author := RandomProfileModel()
+		profilesDb.CreateProfile(author)
+		postId, _ := postsDb.CreatePost(db, post_models.PostToCreate{
+			Author:    author.Id,
+			Text:      RandomString(),
+			CreatedAt: time.Now(),
+		})
+		comment1 := createComment(t, db, sqlDB, postId, author.Id, 2020)
+		comment2 := createComment(t, db, sqlDB, postId, author.Id, 2021)
+
+		// comments are returned in the order of ids and the nonexistent ones are skipped
+		got, err := sqlDB.GetCommentsByIds([]values.CommentId{comment2.Id, "9999999", comment1.Id})
+		AssertNoError(t, err)
+		Assert(t, got, []models.CommentModel{comment2, comment1}, "returned comments")
+
+		got, err = sqlDB.GetCommentsByIds([]values.CommentId{})
+		AssertNoError(t, err)
+		Assert(t, len(got), 0, "number of returned comments")
+	})
+	t.Run("paginating comments", func(t *testing.T) {
+		db := OpenTestDB(t)
+		sqlDB, err := sql_db.NewSqlDB(db)
+		AssertNoError(t, err)
+		profilesDb, _ := profiles_db.NewSqlDB(db)
+		postsDb, _ := posts_db.NewSqlDB(db)
+
+		author := RandomProfileModel()
+		profilesDb.CreateProfile(author)
+		postId, _ := postsDb.CreatePost(db, post_models.PostToCreate{
+			Author:    author.Id,
+			Text:      RandomString(),
+			CreatedAt: time.Now(),
+		})
+
+		oldest := createComment(t, db, sqlDB, postId, author.Id, 2001)
+		middle := createComment(t, db, sqlDB, postId, author.Id, 2002)
+		sameTime := createComment(t, db, sqlDB, postId, author.Id, 2002)
+		newest := createComment(t, db, sqlDB, postId, author.Id, 2003)
+
+		page := pagination.Page{Limit: 2}
+		comments, err := sqlDB.GetComments(postId, page)
+		AssertNoError(t, err)
+		Assert(t, comments, []models.CommentModel{newest, sameTime}, "the first page")
+
+		page.After = pagination.Cursor{CreatedAt: sameTime.CreatedAt, Id: sameTime.Id}
+		comments, err = sqlDB.GetComments(postId, page)
+		AssertNoError(t, err)
+		Assert(t, comments, []models.CommentModel{middle, oldest}, "the second page")
+	})
+	t.Run("getting and updating a comment", func(t *testing.T) {
+		db := OpenTestDB(t)
+		sqlDB, err := sql_db.NewSqlDB(db)
+		AssertNoError(t, err)
+		profilesDb, _ := profiles_db.NewSqlDB(db)
+		postsDb, _ := posts_db.NewSqlDB(db)
+
+		author := RandomProfileModel()
+		profilesDb.CreateProfile(author)
+		postId, _ := postsDb.CreatePost(db, post_models.PostToCreate{
+			Author:    author.Id,
+			Text:      RandomString(),
+			CreatedAt: time.Now(),
+		})
+
+		_, err = sqlDB.GetComment("9999999")
+		AssertError(t, err, core_err.ErrNotFound)
+
+		comment := createComment(t, db, sqlDB, postId, author.Id, 2020)
+		gotComment, err := sqlDB.GetComment(comment.Id)
+		AssertNoError(t, err)
+		Assert(t, gotComment, comment, "the created comment")
+
+		newText := RandomString()
+		editedAt := time.Date(2021, 0, 0, 0, 0, 0, 0, time.UTC)
+		err = sqlDB.Update(db, comment.Id, newText, editedAt)
+		AssertNoError(t, err)
+
+		comment.Text = newText
+		comment.EditedAt = editedAt.Unix()
+		gotComment, err = sqlDB.GetComment(comment.Id)
+		AssertNoError(t, err)
+		Assert(t, gotComment, comment, "the updated comment")
+	})
+	t.Run("replies and deleting comments", func(t *testing.T) {
+		db := OpenTestDB(t)
+		sqlDB, err := sql_db.NewSqlDB(db)
+		AssertNoError(t, err)
+		profilesDb, _ := profiles_db.NewSqlDB(db)
+		postsDb, _ := posts_db.NewSqlDB(db)
+
+		author := RandomProfileModel()
+		profilesDb.CreateProfile(author)
+		postId, _ := postsDb.CreatePost(db, post_models.PostToCreate{
+			Author:    author.Id,
+			Text:      RandomString(),
+			CreatedAt: time.Now(),
+		})
+
+		comment := createComment(t, db, sqlDB, postId, author.Id, 2020)
+		firstReply := createReply(t, db, sqlDB, postId, comment.Id, author.Id, 2021)
+		secondReply := createReply(t, db, sqlDB, postId, comment.Id, author.Id, 2022)
+		nestedReply := createReply(t, db, sqlDB, postId, firstReply.Id, author.Id, 2023)
+
+		// only top-level comments are listed for the post, with the number of their direct replies
+		comment.Replies = 2
+		Assert(t, getComments(t, sqlDB, postId), []models.CommentModel{comment}, "top-level comments")
+
+		firstReply.Replies = 1
+		replies, err := sqlDB.GetReplies(comment.Id, pagination.Page{Limit: pagination.MaxLimit})
+		AssertNoError(t, err)
+		Assert(t, replies, []models.CommentModel{secondReply, firstReply}, "replies of the comment")
+		replies, err = sqlDB.GetReplies(firstReply.Id, pagination.Page{Limit: pagination.MaxLimit})
+		AssertNoError(t, err)
+		Assert(t, replies, []models.CommentModel{nestedReply}, "replies of the reply")
+
+		// a comment without replies is deleted
+		err = sqlDB.Delete(db, secondReply.Id, time.Now())
+		AssertNoError(t, err)
+		_, err = sqlDB.GetComment(secondReply.Id)
+		AssertError(t, err, core_err.ErrNotFound)
+
+		// a comment with replies is replaced with a tombstone
+		deletedAt := time.Date(2024, 0, 0, 0, 0, 0, 0, time.UTC)
+		err = sqlDB.Delete(db, comment.Id, deletedAt)
+		AssertNoError(t, err)
+		comment.Text = ""
+		comment.DeletedAt = deletedAt.Unix()
+		comment.Replies = 1
+		gotComment, err := sqlDB.GetComment(comment.Id)
+		AssertNoError(t, err)
+		Assert(t, gotComment, comment, "the tombstone of the deleted comment")
+		replies, err = sqlDB.GetReplies(comment.Id, pagination.Page{Limit: pagination.MaxLimit})
+		AssertNoError(t, err)
+		Assert(t, replies, []models.CommentModel{firstReply}, "replies of the deleted comment")
+
+		// a tombstone cannot be updated
+		err = sqlDB.Update(db, comment.Id, RandomString(), time.Now())
+		AssertError(t, err, core_err.ErrNotFound)
+
+		// deleting the last reply removes the tombstones left without replies up the thread
+		err = sqlDB.Delete(db, firstReply.Id, deletedAt)
+		AssertNoError(t, err)
+		_, err = sqlDB.GetComment(firstReply.Id)
+		AssertNoError(t, err)
+		err = sqlDB.Delete(db, nestedReply.Id, time.Now())
+		AssertNoError(t, err)
+		for _, deleted := range []values.CommentId{nestedReply.Id, firstReply.Id, comment.Id} {
+			_, err = sqlDB.GetComment(deleted)
+			AssertError(t, err, core_err.ErrNotFound)
+		}
+	})
+	t.Run("comment counts and latest comments of posts", func(t *testing.T) {
+		db := OpenTestDB(t)
+		sqlDB, err := sql_db.NewSqlDB(db)
+		AssertNoError(t, err)
+		profilesDb, _ := profiles_db.NewSqlDB(db)
+		postsDb, _ := posts_db.NewSqlDB(db)
+
+		author := RandomProfileModel()
+		profilesDb.CreateProfile(author)
+		createPost := func() post_values.PostId {
+			postId, err := postsDb.CreatePost(db, post_models.PostToCreate{
+				Author:    author.Id,
+				Text:      RandomString(),
+				CreatedAt: time.Now(),
+			})
+			AssertNoError(t, err)
+			return postId
+		}
+		post1, post2, emptyPost := createPost(), createPost(), createPost()
+
+		oldest := createComment(t, db, sqlDB, post1, author.Id, 2001)
+		middle := createComment(t, db, sqlDB, post1, author.Id, 2002)
+		newest := createComment(t, db, sqlDB, post1, author.Id, 2003)
+		createReply(t, db, sqlDB, post1, oldest.Id, author.Id, 2004)
+		deleted := createComment(t, db, sqlDB, post1, author.Id, 2005)
+		AssertNoError(t, sqlDB.Delete(db, deleted.Id, time.Now()))
+		only := createComment(t, db, sqlDB, post2, author.Id, 2001)
+
+		posts := []post_values.PostId{post1, post2, emptyPost}
+		counts, err := sqlDB.GetCommentCounts(posts)
+		AssertNoError(t, err)
+		Assert(t, counts, map[post_values.PostId]int{post1: 4, post2: 1}, "comment counts")
+
+		latest, err := sqlDB.GetLatestComments(posts, 2)
+		AssertNoError(t, err)
+		wantLatest := map[post_values.PostId][]models.CommentModel{
+			post1: {newest, middle},
+			post2: {only},
+		}
+		Assert(t, latest, wantLatest, "latest comments")
+
+		counts, err = sqlDB.GetCommentCounts([]post_values.PostId{})
+		AssertNoError(t, err)
+		Assert(t, len(counts), 0, "comment counts of no posts")
+	})
 }
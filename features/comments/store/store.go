@@ -2,44 +2,168 @@ package store
 
 import (
 	"github.com/k0marov/go-socnet/core/abstract/likeable"
+	"github.com/k0marov/go-socnet/core/abstract/mentionable"
+	mentionable_values "github.com/k0marov/go-socnet/core/abstract/mentionable/values"
 	"github.com/k0marov/go-socnet/core/general/core_err"
 	"github.com/k0marov/go-socnet/core/general/core_values"
+	"github.com/k0marov/go-socnet/core/general/pagination"
+	"github.com/k0marov/go-socnet/core/general/unit_of_work"
 	"time"
 
 	"github.com/k0marov/go-socnet/features/comments/domain/entities"
 	"github.com/k0marov/go-socnet/features/comments/domain/models"
 	"github.com/k0marov/go-socnet/features/comments/domain/store"
 	"github.com/k0marov/go-socnet/features/comments/domain/values"
+	post_store "github.com/k0marov/go-socnet/features/posts/domain/store"
 	post_values "github.com/k0marov/go-socnet/features/posts/domain/values"
 )
 
 type (
-	DBCommentsGetter func(post post_values.PostId) ([]models.CommentModel, error)
-	DBAuthorGetter   func(post post_values.PostId) (core_values.UserId, error)
-	DBCommentCreator func(newComment values.NewCommentValue, createdAt time.Time) (values.CommentId, error)
+	DBCommentsGetter      func(post post_values.PostId, page pagination.Page) ([]models.CommentModel, error)
+	DBRepliesGetter       func(parent values.CommentId, page pagination.Page) ([]models.CommentModel, error)
+	DBCommentsByIdsGetter func(ids []values.CommentId) ([]models.CommentModel, error)
+	DBAuthorGetter        func(post post_values.PostId) (core_values.UserId, error)
+	DBCommentCreator      func(ex unit_of_work.Executor, newComment values.NewCommentValue, createdAt time.Time) (values.CommentId, error)
+	DBCommentGetter       func(comment values.CommentId) (models.CommentModel, error)
+	DBCommentUpdater      func(ex unit_of_work.Executor, comment values.CommentId, newText string, editedAt time.Time) error
+	DBCommentDeleter      func(ex unit_of_work.Executor, comment values.CommentId, deletedAt time.Time) error
+
+	DBCommentCountsGetter  func(posts []post_values.PostId) (map[post_values.PostId]int, error)
+	DBLatestCommentsGetter func(posts []post_values.PostId, count int) (map[post_values.PostId][]models.CommentModel, error)
 )
 
-func NewCommentsGetter(getComments DBCommentsGetter, getLikes likeable.LikesCountGetter) store.CommentsGetter {
-	return func(post post_values.PostId) (comments []entities.Comment, error error) {
-		commentModels, err := getComments(post)
+func NewCommentsGetter(getComments DBCommentsGetter, getReactions likeable.ReactionCountsGetter, getMentions mentionable.MentionsBatchGetter) store.CommentsGetter {
+	return func(post post_values.PostId, page pagination.Page) ([]entities.Comment, error) {
+		commentModels, err := getComments(post, page)
 		if err != nil {
 			return []entities.Comment{}, core_err.Rethrow("getting post comments from db", err)
 		}
-		for _, model := range commentModels {
-			likes, err := getLikes(model.Id)
+		return modelsToComments(commentModels, getReactions, getMentions)
+	}
+}
+
+func NewRepliesGetter(getReplies DBRepliesGetter, getReactions likeable.ReactionCountsGetter, getMentions mentionable.MentionsBatchGetter) store.RepliesGetter {
+	return func(parent values.CommentId, page pagination.Page) ([]entities.Comment, error) {
+		replyModels, err := getReplies(parent, page)
+		if err != nil {
+			return []entities.Comment{}, core_err.Rethrow("getting comment replies from db", err)
+		}
+		return modelsToComments(replyModels, getReactions, getMentions)
+	}
+}
+
+func NewCommentsByIdsGetter(getComments DBCommentsByIdsGetter, getReactions likeable.ReactionCountsGetter, getMentions mentionable.MentionsBatchGetter) store.CommentsByIdsGetter {
+	return func(ids []values.CommentId) ([]entities.Comment, error) {
+		commentModels, err := getComments(ids)
+		if err != nil {
+			return []entities.Comment{}, core_err.Rethrow("getting comments by ids from db", err)
+		}
+		return modelsToComments(commentModels, getReactions, getMentions)
+	}
+}
+
+func modelsToComments(commentModels []models.CommentModel, getReactions likeable.ReactionCountsGetter, getMentions mentionable.MentionsBatchGetter) (comments []entities.Comment, err error) {
+	if len(commentModels) == 0 {
+		return
+	}
+	ids := make([]values.CommentId, len(commentModels))
+	for i, model := range commentModels {
+		ids[i] = model.Id
+	}
+	mentions, err := getMentions(ids)
+	if err != nil {
+		return []entities.Comment{}, core_err.Rethrow("getting mentions of comments", err)
+	}
+	for _, model := range commentModels {
+		reactions, err := getReactions(model.Id)
+		if err != nil {
+			return []entities.Comment{}, core_err.Rethrow("getting reaction counts for comment", err)
+		}
+		comment := entities.Comment{
+			CommentModel: model,
+			Likes:        reactions.Total(),
+			Reactions:    reactions,
+			Mentions:     mentions[model.Id],
+		}
+		comments = append(comments, comment)
+	}
+	return comments, nil
+}
+
+// NewCommentCreator creates the comment together with its mentions in a single unit of work
+func NewCommentCreator(runInUnit unit_of_work.Runner, createComment DBCommentCreator, addMentions mentionable.MentionsAdder) store.Creator {
+	return func(newComment values.NewCommentValue, mentions []mentionable_values.Mention, createdAt time.Time) (values.CommentId, error) {
+		var id values.CommentId
+		err := runInUnit(func(uow unit_of_work.UnitOfWork) error {
+			newId, err := createComment(uow.Tx, newComment, createdAt)
 			if err != nil {
-				return []entities.Comment{}, core_err.Rethrow("getting likes count for comment", err)
+				return core_err.Rethrow("creating a comment in db", err)
 			}
-			comment := entities.Comment{
-				CommentModel: model,
-				Likes:        likes,
+			err = addMentions(uow.Tx, newId, mentions)
+			if err != nil {
+				return core_err.Rethrow("adding mentions of the new comment", err)
 			}
-			comments = append(comments, comment)
+			id = newId
+			return nil
+		})
+		if err != nil {
+			return "", err
 		}
-		return
+		return id, nil
 	}
 }
 
-func NewCommentCreator(createComment DBCommentCreator) store.Creator {
-	return store.Creator(createComment)
+func NewCommentUpdater(runInUnit unit_of_work.Runner, updateComment DBCommentUpdater, replaceMentions mentionable.MentionsReplacer) store.Updater {
+	return func(comment values.CommentId, newText string, mentions []mentionable_values.Mention, editedAt time.Time) error {
+		return runInUnit(func(uow unit_of_work.UnitOfWork) error {
+			err := updateComment(uow.Tx, comment, newText, editedAt)
+			if err != nil {
+				return core_err.Rethrow("updating a comment in db", err)
+			}
+			err = replaceMentions(uow.Tx, comment, mentions)
+			if err != nil {
+				return core_err.Rethrow("replacing mentions of the updated comment", err)
+			}
+			return nil
+		})
+	}
+}
+
+// NewCommentDeleter also removes the mentions, since they would be left behind by a tombstone
+func NewCommentDeleter(runInUnit unit_of_work.Runner, deleteComment DBCommentDeleter, replaceMentions mentionable.MentionsReplacer) store.Deleter {
+	return func(comment values.CommentId, deletedAt time.Time) error {
+		return runInUnit(func(uow unit_of_work.UnitOfWork) error {
+			err := deleteComment(uow.Tx, comment, deletedAt)
+			if err != nil {
+				return core_err.Rethrow("deleting a comment in db", err)
+			}
+			err = replaceMentions(uow.Tx, comment, nil)
+			if err != nil {
+				return core_err.Rethrow("removing mentions of the deleted comment", err)
+			}
+			return nil
+		})
+	}
+}
+
+func NewCommentCountsGetter(getCounts DBCommentCountsGetter) post_store.CommentCountsGetter {
+	return post_store.CommentCountsGetter(getCounts)
+}
+
+func NewLatestCommentsGetter(getLatest DBLatestCommentsGetter) store.LatestCommentsGetter {
+	return store.LatestCommentsGetter(getLatest)
+}
+
+func NewCommentGetter(getComment DBCommentGetter, getReactions likeable.ReactionCountsGetter, getMentions mentionable.MentionsBatchGetter) store.CommentGetter {
+	return func(comment values.CommentId) (entities.Comment, error) {
+		model, err := getComment(comment)
+		if err != nil {
+			return entities.Comment{}, core_err.Rethrow("getting a comment from db", err)
+		}
+		comments, err := modelsToComments([]models.CommentModel{model}, getReactions, getMentions)
+		if err != nil {
+			return entities.Comment{}, err
+		}
+		return comments[0], nil
+	}
 }
@@ -1,53 +1,355 @@
 package store_test
 
 import (
+	likeable_values "github.com/k0marov/go-socnet/core/abstract/likeable/values"
+	mentionable_values "github.com/k0marov/go-socnet/core/abstract/mentionable/values"
 	"github.com/k0marov/go-socnet/core/general/core_values"
+	"github.com/k0marov/go-socnet/core/general/pagination"
+	"github.com/k0marov/go-socnet/core/general/unit_of_work"
 	. "github.com/k0marov/go-socnet/core/helpers/test_helpers"
+	"reflect"
 	"testing"
+	"time"
 
 	"github.com/k0marov/go-socnet/features/comments/domain/entities"
 	comment_models "github.com/k0marov/go-socnet/features/comments/domain/models"
+	"github.com/k0marov/go-socnet/features/comments/domain/values"
 	"github.com/k0marov/go-socnet/features/comments/store"
 )
 
 func TestCommentsGetter(t *testing.T) {
 	commentModels := []comment_models.CommentModel{RandomCommentModel()}
-	likes := RandomInt()
+	reactions := RandomReactionCounts()
 	author := RandomId()
+	page := pagination.Page{Limit: RandomInt()}
 
-	commentsGetter := func(authorId core_values.UserId) ([]comment_models.CommentModel, error) {
-		if authorId == author {
+	commentsGetter := func(authorId core_values.UserId, gotPage pagination.Page) ([]comment_models.CommentModel, error) {
+		if authorId == author && gotPage == page {
 			return commentModels, nil
 		}
 		panic("unexpected args")
 	}
 	t.Run("error case - getting comments from db throws", func(t *testing.T) {
-		commentsGetter := func(id core_values.UserId) ([]comment_models.CommentModel, error) {
+		commentsGetter := func(core_values.UserId, pagination.Page) ([]comment_models.CommentModel, error) {
 			return nil, RandomError()
 		}
-		_, err := store.NewCommentsGetter(commentsGetter, nil)(author)
+		_, err := store.NewCommentsGetter(commentsGetter, nil, nil)(author, page)
 		AssertSomeError(t, err)
 	})
-	likesGetter := func(targetId string) (int, error) {
+	mentions := map[values.CommentId][]mentionable_values.Mention{commentModels[0].Id: RandomMentions()}
+	getMentions := func(ids []values.CommentId) (map[values.CommentId][]mentionable_values.Mention, error) {
+		if reflect.DeepEqual(ids, []values.CommentId{commentModels[0].Id}) {
+			return mentions, nil
+		}
+		panic("unexpected args")
+	}
+	t.Run("error case - getting mentions throws", func(t *testing.T) {
+		getMentions := func([]values.CommentId) (map[values.CommentId][]mentionable_values.Mention, error) {
+			return nil, RandomError()
+		}
+		_, err := store.NewCommentsGetter(commentsGetter, nil, getMentions)(author, page)
+		AssertSomeError(t, err)
+	})
+	reactionsGetter := func(targetId string) (likeable_values.ReactionCounts, error) {
 		if targetId == commentModels[0].Id {
-			return likes, nil
+			return reactions, nil
 		}
 		panic("unexpected args")
 	}
-	t.Run("error case - getting likes throws", func(t *testing.T) {
-		likesGetter := func(string) (int, error) {
-			return 0, RandomError()
+	t.Run("error case - getting reaction counts throws", func(t *testing.T) {
+		reactionsGetter := func(string) (likeable_values.ReactionCounts, error) {
+			return nil, RandomError()
 		}
-		_, err := store.NewCommentsGetter(commentsGetter, likesGetter)(author)
+		_, err := store.NewCommentsGetter(commentsGetter, reactionsGetter, getMentions)(author, page)
 		AssertSomeError(t, err)
 	})
-	gotComments, err := store.NewCommentsGetter(commentsGetter, likesGetter)(author)
+	gotComments, err := store.NewCommentsGetter(commentsGetter, reactionsGetter, getMentions)(author, page)
 	AssertNoError(t, err)
 	wantComments := []entities.Comment{
 		{
 			CommentModel: commentModels[0],
-			Likes:        likes,
+			Likes:        reactions.Total(),
+			Reactions:    reactions,
+			Mentions:     mentions[commentModels[0].Id],
 		},
 	}
 	Assert(t, gotComments, wantComments, "returned comments")
 }
+
+func TestCommentsByIdsGetter(t *testing.T) {
+	commentModels := []comment_models.CommentModel{RandomCommentModel()}
+	ids := []values.CommentId{commentModels[0].Id, RandomId()}
+	reactions := RandomReactionCounts()
+	mentions := map[values.CommentId][]mentionable_values.Mention{commentModels[0].Id: RandomMentions()}
+
+	getComments := func(gotIds []values.CommentId) ([]comment_models.CommentModel, error) {
+		if reflect.DeepEqual(gotIds, ids) {
+			return commentModels, nil
+		}
+		panic("unexpected args")
+	}
+	getMentions := func([]values.CommentId) (map[values.CommentId][]mentionable_values.Mention, error) {
+		return mentions, nil
+	}
+	getReactions := func(string) (likeable_values.ReactionCounts, error) {
+		return reactions, nil
+	}
+	t.Run("error case - getting comments from db throws", func(t *testing.T) {
+		getComments := func([]values.CommentId) ([]comment_models.CommentModel, error) {
+			return nil, RandomError()
+		}
+		_, err := store.NewCommentsByIdsGetter(getComments, getReactions, getMentions)(ids)
+		AssertSomeError(t, err)
+	})
+	gotComments, err := store.NewCommentsByIdsGetter(getComments, getReactions, getMentions)(ids)
+	AssertNoError(t, err)
+	wantComments := []entities.Comment{
+		{
+			CommentModel: commentModels[0],
+			Likes:        reactions.Total(),
+			Reactions:    reactions,
+			Mentions:     mentions[commentModels[0].Id],
+		},
+	}
+	Assert(t, gotComments, wantComments, "returned comments")
+}
+
+func TestRepliesGetter(t *testing.T) {
+	replyModels := []comment_models.CommentModel{RandomCommentModel(), RandomCommentModel()}
+	reactions := map[values.CommentId]likeable_values.ReactionCounts{
+		replyModels[0].Id: RandomReactionCounts(),
+		replyModels[1].Id: RandomReactionCounts(),
+	}
+	parent := RandomId()
+	page := pagination.Page{Limit: RandomInt()}
+
+	getReplies := func(parentId values.CommentId, gotPage pagination.Page) ([]comment_models.CommentModel, error) {
+		if parentId == parent && gotPage == page {
+			return replyModels, nil
+		}
+		panic("unexpected args")
+	}
+	t.Run("error case - getting replies from db throws", func(t *testing.T) {
+		getReplies := func(values.CommentId, pagination.Page) ([]comment_models.CommentModel, error) {
+			return nil, RandomError()
+		}
+		_, err := store.NewRepliesGetter(getReplies, nil, nil)(parent, page)
+		AssertSomeError(t, err)
+	})
+	mentions := map[values.CommentId][]mentionable_values.Mention{replyModels[1].Id: RandomMentions()}
+	getMentions := func(ids []values.CommentId) (map[values.CommentId][]mentionable_values.Mention, error) {
+		if reflect.DeepEqual(ids, []values.CommentId{replyModels[0].Id, replyModels[1].Id}) {
+			return mentions, nil
+		}
+		panic("unexpected args")
+	}
+	getReactions := func(targetId string) (likeable_values.ReactionCounts, error) {
+		if counts, ok := reactions[targetId]; ok {
+			return counts, nil
+		}
+		panic("unexpected args")
+	}
+	t.Run("error case - getting reaction counts throws", func(t *testing.T) {
+		getReactions := func(string) (likeable_values.ReactionCounts, error) {
+			return nil, RandomError()
+		}
+		_, err := store.NewRepliesGetter(getReplies, getReactions, getMentions)(parent, page)
+		AssertSomeError(t, err)
+	})
+	gotReplies, err := store.NewRepliesGetter(getReplies, getReactions, getMentions)(parent, page)
+	AssertNoError(t, err)
+	wantReplies := []entities.Comment{
+		{CommentModel: replyModels[0], Likes: reactions[replyModels[0].Id].Total(), Reactions: reactions[replyModels[0].Id]},
+		{CommentModel: replyModels[1], Likes: reactions[replyModels[1].Id].Total(), Reactions: reactions[replyModels[1].Id], Mentions: mentions[replyModels[1].Id]},
+	}
+	Assert(t, gotReplies, wantReplies, "returned replies")
+}
+
+func TestCommentGetter(t *testing.T) {
+	commentModel := RandomCommentModel()
+	reactions := RandomReactionCounts()
+
+	getComment := func(commentId values.CommentId) (comment_models.CommentModel, error) {
+		if commentId == commentModel.Id {
+			return commentModel, nil
+		}
+		panic("unexpected args")
+	}
+	t.Run("error case - getting comment from db throws", func(t *testing.T) {
+		getComment := func(values.CommentId) (comment_models.CommentModel, error) {
+			return comment_models.CommentModel{}, RandomError()
+		}
+		_, err := store.NewCommentGetter(getComment, nil, nil)(commentModel.Id)
+		AssertSomeError(t, err)
+	})
+	mentions := RandomMentions()
+	getMentions := func(ids []values.CommentId) (map[values.CommentId][]mentionable_values.Mention, error) {
+		if reflect.DeepEqual(ids, []values.CommentId{commentModel.Id}) {
+			return map[values.CommentId][]mentionable_values.Mention{commentModel.Id: mentions}, nil
+		}
+		panic("unexpected args")
+	}
+	getReactions := func(targetId string) (likeable_values.ReactionCounts, error) {
+		if targetId == commentModel.Id {
+			return reactions, nil
+		}
+		panic("unexpected args")
+	}
+	t.Run("error case - getting reaction counts throws", func(t *testing.T) {
+		getReactions := func(string) (likeable_values.ReactionCounts, error) {
+			return nil, RandomError()
+		}
+		_, err := store.NewCommentGetter(getComment, getReactions, getMentions)(commentModel.Id)
+		AssertSomeError(t, err)
+	})
+	gotComment, err := store.NewCommentGetter(getComment, getReactions, getMentions)(commentModel.Id)
+	AssertNoError(t, err)
+	wantComment := entities.Comment{CommentModel: commentModel, Likes: reactions.Total(), Reactions: reactions, Mentions: mentions}
+	Assert(t, gotComment, wantComment, "returned comment")
+}
+
+func TestCommentCreator(t *testing.T) {
+	newComment := RandomNewComment()
+	mentions := RandomMentions()
+	createdAt := RandomTime()
+	createdId := RandomId()
+
+	runInUnit := func(work unit_of_work.Work) error {
+		return work(unit_of_work.UnitOfWork{})
+	}
+	t.Run("error case - running the unit of work returns an error", func(t *testing.T) {
+		tErr := RandomError()
+		runInUnit := func(unit_of_work.Work) error {
+			return tErr
+		}
+		_, err := store.NewCommentCreator(runInUnit, nil, nil)(newComment, mentions, createdAt)
+		AssertError(t, err, tErr)
+	})
+	createComment := func(ex unit_of_work.Executor, gotComment values.NewCommentValue, gotCreatedAt time.Time) (values.CommentId, error) {
+		if gotComment == newComment && gotCreatedAt == createdAt {
+			return createdId, nil
+		}
+		panic("unexpected args")
+	}
+	t.Run("error case - creating comment in db throws", func(t *testing.T) {
+		createComment := func(unit_of_work.Executor, values.NewCommentValue, time.Time) (values.CommentId, error) {
+			return "", RandomError()
+		}
+		_, err := store.NewCommentCreator(runInUnit, createComment, nil)(newComment, mentions, createdAt)
+		AssertSomeError(t, err)
+	})
+	t.Run("error case - adding mentions throws", func(t *testing.T) {
+		addMentions := func(unit_of_work.Executor, string, []mentionable_values.Mention) error {
+			return RandomError()
+		}
+		_, err := store.NewCommentCreator(runInUnit, createComment, addMentions)(newComment, mentions, createdAt)
+		AssertSomeError(t, err)
+	})
+	t.Run("happy case", func(t *testing.T) {
+		addMentions := func(ex unit_of_work.Executor, target string, gotMentions []mentionable_values.Mention) error {
+			if target == createdId && reflect.DeepEqual(gotMentions, mentions) {
+				return nil
+			}
+			panic("unexpected args")
+		}
+		gotId, err := store.NewCommentCreator(runInUnit, createComment, addMentions)(newComment, mentions, createdAt)
+		AssertNoError(t, err)
+		Assert(t, gotId, createdId, "id of the created comment")
+	})
+}
+
+func TestCommentUpdater(t *testing.T) {
+	comment := RandomId()
+	newText := RandomString()
+	mentions := RandomMentions()
+	editedAt := RandomTime()
+
+	runInUnit := func(work unit_of_work.Work) error {
+		return work(unit_of_work.UnitOfWork{})
+	}
+	t.Run("error case - running the unit of work returns an error", func(t *testing.T) {
+		tErr := RandomError()
+		runInUnit := func(unit_of_work.Work) error {
+			return tErr
+		}
+		err := store.NewCommentUpdater(runInUnit, nil, nil)(comment, newText, mentions, editedAt)
+		AssertError(t, err, tErr)
+	})
+	updateComment := func(ex unit_of_work.Executor, commentId values.CommentId, text string, gotEditedAt time.Time) error {
+		if commentId == comment && text == newText && gotEditedAt == editedAt {
+			return nil
+		}
+		panic("unexpected args")
+	}
+	t.Run("error case - updating comment in db throws", func(t *testing.T) {
+		updateComment := func(unit_of_work.Executor, values.CommentId, string, time.Time) error {
+			return RandomError()
+		}
+		err := store.NewCommentUpdater(runInUnit, updateComment, nil)(comment, newText, mentions, editedAt)
+		AssertSomeError(t, err)
+	})
+	t.Run("error case - replacing mentions throws", func(t *testing.T) {
+		replaceMentions := func(unit_of_work.Executor, string, []mentionable_values.Mention) error {
+			return RandomError()
+		}
+		err := store.NewCommentUpdater(runInUnit, updateComment, replaceMentions)(comment, newText, mentions, editedAt)
+		AssertSomeError(t, err)
+	})
+	t.Run("happy case", func(t *testing.T) {
+		replaceMentions := func(ex unit_of_work.Executor, target string, gotMentions []mentionable_values.Mention) error {
+			if target == comment && reflect.DeepEqual(gotMentions, mentions) {
+				return nil
+			}
+			panic("unexpected args")
+		}
+		err := store.NewCommentUpdater(runInUnit, updateComment, replaceMentions)(comment, newText, mentions, editedAt)
+		AssertNoError(t, err)
+	})
+}
+
+func TestCommentDeleter(t *testing.T) {
+	comment := RandomId()
+	deletedAt := RandomTime()
+
+	runInUnit := func(work unit_of_work.Work) error {
+		return work(unit_of_work.UnitOfWork{})
+	}
+	t.Run("error case - running the unit of work returns an error", func(t *testing.T) {
+		tErr := RandomError()
+		runInUnit := func(unit_of_work.Work) error {
+			return tErr
+		}
+		err := store.NewCommentDeleter(runInUnit, nil, nil)(comment, deletedAt)
+		AssertError(t, err, tErr)
+	})
+	deleteComment := func(ex unit_of_work.Executor, commentId values.CommentId, gotDeletedAt time.Time) error {
+		if commentId == comment && gotDeletedAt == deletedAt {
+			return nil
+		}
+		panic("unexpected args")
+	}
+	t.Run("error case - deleting comment in db throws", func(t *testing.T) {
+		deleteComment := func(unit_of_work.Executor, values.CommentId, time.Time) error {
+			return RandomError()
+		}
+		err := store.NewCommentDeleter(runInUnit, deleteComment, nil)(comment, deletedAt)
+		AssertSomeError(t, err)
+	})
+	t.Run("error case - removing mentions throws", func(t *testing.T) {
+		replaceMentions := func(unit_of_work.Executor, string, []mentionable_values.Mention) error {
+			return RandomError()
+		}
+		err := store.NewCommentDeleter(runInUnit, deleteComment, replaceMentions)(comment, deletedAt)
+		AssertSomeError(t, err)
+	})
+	t.Run("happy case", func(t *testing.T) {
+		replaceMentions := func(ex unit_of_work.Executor, target string, gotMentions []mentionable_values.Mention) error {
+			if target == comment && len(gotMentions) == 0 {
+				return nil
+			}
+			panic("unexpected args")
+		}
+		err := store.NewCommentDeleter(runInUnit, deleteComment, replaceMentions)(comment, deletedAt)
+		AssertNoError(t, err)
+	})
+}
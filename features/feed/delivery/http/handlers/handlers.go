@@ -19,6 +19,25 @@ func NewFeedHandler(getFeed service.FeedGetter) http.HandlerFunc {
 			http_helpers.HandleServiceError(w, err)
 			return
 		}
-		http_helpers.WriteJson(w, responses.FeedResponse{Posts: posts})
+		http_helpers.WriteJson(w, responses.NewFeedResponse(posts))
+	}
+}
+
+func NewFollowingFeedHandler(getFeed service.FollowingFeedGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		caller, ok := http_helpers.GetUserOrAddUnauthorized(w, r)
+		if !ok {
+			return
+		}
+		page, ok := http_helpers.GetPageOrThrowClientError(w, r)
+		if !ok {
+			return
+		}
+		posts, err := getFeed(caller.Id, page)
+		if err != nil {
+			http_helpers.HandleServiceError(w, err)
+			return
+		}
+		http_helpers.WriteJson(w, responses.NewFollowingFeedResponse(posts, page))
 	}
 }
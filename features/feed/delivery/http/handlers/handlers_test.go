@@ -1,11 +1,14 @@
 package handlers_test
 
 import (
+	"github.com/k0marov/go-socnet/core/general/client_errors"
 	"github.com/k0marov/go-socnet/core/general/core_values"
+	"github.com/k0marov/go-socnet/core/general/pagination"
 	helpers "github.com/k0marov/go-socnet/core/helpers/http_test_helpers"
 	. "github.com/k0marov/go-socnet/core/helpers/test_helpers"
 	"github.com/k0marov/go-socnet/features/feed/delivery/http/handlers"
 	"github.com/k0marov/go-socnet/features/feed/delivery/http/responses"
+	post_entities "github.com/k0marov/go-socnet/features/posts/domain/entities"
 	"io"
 	"net/http"
 	"net/http/httptest"
@@ -18,12 +21,12 @@ func createRequestWithCount(count string, body io.Reader) *http.Request {
 
 func TestFeedHandler(t *testing.T) {
 	count := RandomString()
-	posts := []string{RandomString(), RandomString()}
+	posts := []post_entities.ContextedPost{RandomContextedPost(), RandomContextedPost()}
 	caller := RandomAuthUser()
 
 	helpers.BaseTest401(t, handlers.NewFeedHandler(nil))
 	t.Run("happy case", func(t *testing.T) {
-		getter := func(countStr string, callerId core_values.UserId) ([]string, error) {
+		getter := func(countStr string, callerId core_values.UserId) ([]post_entities.ContextedPost, error) {
 			if countStr == count && callerId == caller.Id {
 				return posts, nil
 			}
@@ -32,13 +35,47 @@ func TestFeedHandler(t *testing.T) {
 		response := httptest.NewRecorder()
 		request := helpers.AddAuthDataToRequest(createRequestWithCount(count, nil), caller)
 		handlers.NewFeedHandler(getter).ServeHTTP(response, request)
-		AssertJSONData(t, response, responses.FeedResponse{Posts: posts})
+		AssertJSONData(t, response, responses.NewFeedResponse(posts))
 	})
 	helpers.BaseTestServiceErrorHandling(t, func(err error, response *httptest.ResponseRecorder) {
-		getter := func(string, core_values.UserId) ([]string, error) {
-			return []string{}, err
+		getter := func(string, core_values.UserId) ([]post_entities.ContextedPost, error) {
+			return []post_entities.ContextedPost{}, err
 		}
 		request := helpers.AddAuthDataToRequest(createRequestWithCount(count, nil), caller)
 		handlers.NewFeedHandler(getter).ServeHTTP(response, request)
 	})
 }
+
+func TestFollowingFeedHandler(t *testing.T) {
+	caller := RandomAuthUser()
+	posts := []post_entities.ContextedPost{RandomContextedPost(), RandomContextedPost()}
+	cursor := pagination.Cursor{CreatedAt: RandomTime().Unix(), Id: RandomId()}
+	createRequest := func(query string) *http.Request {
+		return helpers.AddAuthDataToRequest(httptest.NewRequest(http.MethodGet, "/handler-should-not-care"+query, nil), caller)
+	}
+
+	helpers.BaseTest401(t, handlers.NewFollowingFeedHandler(nil))
+	t.Run("happy case", func(t *testing.T) {
+		wantPage := pagination.Page{After: cursor, Limit: 2}
+		getter := func(callerId core_values.UserId, page pagination.Page) ([]post_entities.ContextedPost, error) {
+			if callerId == caller.Id && page == wantPage {
+				return posts, nil
+			}
+			panic("unexpected args")
+		}
+		response := httptest.NewRecorder()
+		handlers.NewFollowingFeedHandler(getter).ServeHTTP(response, createRequest("?limit=2&cursor="+cursor.Encode()))
+		AssertJSONData(t, response, responses.NewFollowingFeedResponse(posts, wantPage))
+	})
+	t.Run("error case - invalid limit", func(t *testing.T) {
+		response := httptest.NewRecorder()
+		handlers.NewFollowingFeedHandler(nil).ServeHTTP(response, createRequest("?limit=abc"))
+		AssertClientError(t, response, client_errors.InvalidLimit)
+	})
+	helpers.BaseTestServiceErrorHandling(t, func(err error, response *httptest.ResponseRecorder) {
+		getter := func(core_values.UserId, pagination.Page) ([]post_entities.ContextedPost, error) {
+			return nil, err
+		}
+		handlers.NewFollowingFeedHandler(getter).ServeHTTP(response, createRequest(""))
+	})
+}
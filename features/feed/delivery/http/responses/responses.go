@@ -1,5 +1,27 @@
 package responses
 
+import (
+	"github.com/k0marov/go-socnet/core/general/pagination"
+	post_responses "github.com/k0marov/go-socnet/features/posts/delivery/http/responses"
+	post_entities "github.com/k0marov/go-socnet/features/posts/domain/entities"
+)
+
 type FeedResponse struct {
-	Posts []string `json:"posts"`
+	Posts []post_responses.PostResponse `json:"posts"`
+}
+
+func NewFeedResponse(posts []post_entities.ContextedPost) FeedResponse {
+	return FeedResponse{Posts: post_responses.NewPostResponses(posts)}
+}
+
+type FollowingFeedResponse struct {
+	Posts      []post_responses.PostResponse `json:"posts"`
+	NextCursor string                        `json:"next_cursor"`
+}
+
+func NewFollowingFeedResponse(posts []post_entities.ContextedPost, page pagination.Page) FollowingFeedResponse {
+	return FollowingFeedResponse{
+		Posts:      post_responses.NewPostResponses(posts),
+		NextCursor: pagination.NextCursor(posts, page, post_entities.ContextedPost.Cursor).Encode(),
+	}
 }
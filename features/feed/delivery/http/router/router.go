@@ -5,8 +5,9 @@ import (
 	"net/http"
 )
 
-func NewFeedRouter(feedHandler http.HandlerFunc) func(chi.Router) {
+func NewFeedRouter(feedHandler, followingFeedHandler http.HandlerFunc) func(chi.Router) {
 	return func(r chi.Router) {
 		r.Get("/", feedHandler)
+		r.Get("/following", followingFeedHandler)
 	}
 }
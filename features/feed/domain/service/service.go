@@ -1,16 +1,23 @@
 package service
 
 import (
+	"github.com/k0marov/go-socnet/core/abstract/likeable"
 	"github.com/k0marov/go-socnet/core/abstract/recommendable"
 	"github.com/k0marov/go-socnet/core/general/client_errors"
+	"github.com/k0marov/go-socnet/core/general/core_err"
 	"github.com/k0marov/go-socnet/core/general/core_values"
+	"github.com/k0marov/go-socnet/core/general/pagination"
 	"strconv"
+
+	post_contexters "github.com/k0marov/go-socnet/features/posts/domain/contexters"
+	post_entities "github.com/k0marov/go-socnet/features/posts/domain/entities"
+	post_store "github.com/k0marov/go-socnet/features/posts/domain/store"
 )
 
-type FeedGetter = func(count string, caller core_values.UserId) ([]string, error)
+type FeedGetter = func(count string, caller core_values.UserId) ([]post_entities.ContextedPost, error)
+type FollowingFeedGetter = func(caller core_values.UserId, page pagination.Page) ([]post_entities.ContextedPost, error)
 
 const DefaultCount = 5
-const MaxCount = 50
 
 func convertCount(countStr string) (count int, ok bool) {
 	if countStr == "" {
@@ -20,15 +27,49 @@ func convertCount(countStr string) (count int, ok bool) {
 	return countConv, err == nil
 }
 
-func NewFeedGetter(getFeed recommendable.RecsGetter) FeedGetter {
-	return func(countStr string, caller core_values.UserId) ([]string, error) {
+// NewFeedGetter resolves the recommended post ids into posts, skipping the ones that were deleted
+func NewFeedGetter(maxCount int, getFeed recommendable.RecsGetter, getPosts post_store.PostsByIdsGetter, addContext post_contexters.PostListContextAdder) FeedGetter {
+	return func(countStr string, caller core_values.UserId) ([]post_entities.ContextedPost, error) {
 		count, ok := convertCount(countStr)
 		if !ok {
-			return []string{}, client_errors.NonIntegerCount
+			return []post_entities.ContextedPost{}, client_errors.NonIntegerCount
+		}
+		if count > maxCount {
+			return []post_entities.ContextedPost{}, client_errors.TooBigCount
+		}
+		ids, err := getFeed(caller, count)
+		if err != nil {
+			return []post_entities.ContextedPost{}, core_err.Rethrow("getting recommended post ids", err)
+		}
+		posts, err := getPosts(ids)
+		if err != nil {
+			return []post_entities.ContextedPost{}, core_err.Rethrow("getting recommended posts", err)
+		}
+		ctxPosts, err := addContext(posts, caller)
+		if err != nil {
+			return []post_entities.ContextedPost{}, core_err.Rethrow("adding context to recommended posts", err)
+		}
+		return ctxPosts, nil
+	}
+}
+
+func NewFollowingFeedGetter(getFollows likeable.UserLikesGetter, getPosts post_store.AuthorsPostsGetter, addContext post_contexters.PostListContextAdder) FollowingFeedGetter {
+	return func(caller core_values.UserId, page pagination.Page) ([]post_entities.ContextedPost, error) {
+		follows, err := getFollows(caller)
+		if err != nil {
+			return []post_entities.ContextedPost{}, core_err.Rethrow("getting follows of caller", err)
+		}
+		if len(follows) == 0 {
+			return []post_entities.ContextedPost{}, nil
+		}
+		posts, err := getPosts(follows, page)
+		if err != nil {
+			return []post_entities.ContextedPost{}, core_err.Rethrow("getting posts of follows", err)
 		}
-		if count > MaxCount {
-			return []string{}, client_errors.TooBigCount
+		ctxPosts, err := addContext(posts, caller)
+		if err != nil {
+			return []post_entities.ContextedPost{}, core_err.Rethrow("adding context to posts", err)
 		}
-		return getFeed(caller, count)
+		return ctxPosts, nil
 	}
 }
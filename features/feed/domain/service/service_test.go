@@ -1,30 +1,37 @@
 package service_test
 
 import (
+	"fmt"
 	"github.com/k0marov/go-socnet/core/general/client_errors"
 	"github.com/k0marov/go-socnet/core/general/core_values"
+	"github.com/k0marov/go-socnet/core/general/pagination"
 	. "github.com/k0marov/go-socnet/core/helpers/test_helpers"
 	"github.com/k0marov/go-socnet/features/feed/domain/service"
+	post_entities "github.com/k0marov/go-socnet/features/posts/domain/entities"
+	"reflect"
 	"testing"
 )
 
 func TestFeedGetter(t *testing.T) {
+	const maxCount = 50
 	caller := RandomId()
 	countStr := "8"
-	posts := []string{RandomId(), RandomId(), RandomId()}
+	ids := []string{RandomId(), RandomId(), RandomId()}
+	posts := []post_entities.Post{RandomPost(), RandomPost()}
+	ctxPosts := []post_entities.ContextedPost{RandomContextedPost(), RandomContextedPost()}
 
 	t.Run("error case - count is not int", func(t *testing.T) {
-		_, err := service.NewFeedGetter(nil)("asdf", caller)
+		_, err := service.NewFeedGetter(maxCount, nil, nil, nil)("asdf", caller)
 		AssertError(t, err, client_errors.NonIntegerCount)
 	})
 	t.Run("error case - count is too big", func(t *testing.T) {
-		_, err := service.NewFeedGetter(nil)("9999", caller)
+		_, err := service.NewFeedGetter(maxCount, nil, nil, nil)(fmt.Sprint(maxCount+1), caller)
 		AssertError(t, err, client_errors.TooBigCount)
 	})
 
 	feedGetter := func(callerId core_values.UserId, count int) ([]string, error) {
 		if count == 8 && callerId == caller {
-			return posts, nil
+			return ids, nil
 		}
 		panic("unexpected")
 	}
@@ -32,25 +39,112 @@ func TestFeedGetter(t *testing.T) {
 		feedGetter := func(core_values.UserId, int) ([]string, error) {
 			return nil, RandomError()
 		}
-		_, err := service.NewFeedGetter(feedGetter)(countStr, caller)
+		_, err := service.NewFeedGetter(maxCount, feedGetter, nil, nil)(countStr, caller)
+		AssertSomeError(t, err)
+	})
+	postsGetter := func(postIds []string) ([]post_entities.Post, error) {
+		if reflect.DeepEqual(postIds, ids) {
+			return posts, nil
+		}
+		panic("unexpected")
+	}
+	t.Run("error case - getting posts throws", func(t *testing.T) {
+		postsGetter := func([]string) ([]post_entities.Post, error) {
+			return nil, RandomError()
+		}
+		_, err := service.NewFeedGetter(maxCount, feedGetter, postsGetter, nil)(countStr, caller)
+		AssertSomeError(t, err)
+	})
+	addContext := func(postList []post_entities.Post, callerId core_values.UserId) ([]post_entities.ContextedPost, error) {
+		if reflect.DeepEqual(postList, posts) && callerId == caller {
+			return ctxPosts, nil
+		}
+		panic("unexpected")
+	}
+	t.Run("error case - adding context throws", func(t *testing.T) {
+		addContext := func([]post_entities.Post, core_values.UserId) ([]post_entities.ContextedPost, error) {
+			return nil, RandomError()
+		}
+		_, err := service.NewFeedGetter(maxCount, feedGetter, postsGetter, addContext)(countStr, caller)
 		AssertSomeError(t, err)
 	})
 
 	t.Run("happy case", func(t *testing.T) {
-		gotPosts, err := service.NewFeedGetter(feedGetter)(countStr, caller)
+		gotPosts, err := service.NewFeedGetter(maxCount, feedGetter, postsGetter, addContext)(countStr, caller)
 		AssertNoError(t, err)
-		Assert(t, gotPosts, posts, "returned posts")
+		Assert(t, gotPosts, ctxPosts, "returned posts")
 	})
 	t.Run("happy case - count is empty", func(t *testing.T) {
 		feedGetter := func(callerId core_values.UserId, count int) ([]string, error) {
 			if count == service.DefaultCount && callerId == caller {
-				return posts, nil
+				return ids, nil
 			}
 			panic("unexpected")
 		}
-		gotPosts, err := service.NewFeedGetter(feedGetter)("", caller)
+		gotPosts, err := service.NewFeedGetter(maxCount, feedGetter, postsGetter, addContext)("", caller)
 		AssertNoError(t, err)
-		Assert(t, gotPosts, posts, "returned posts")
+		Assert(t, gotPosts, ctxPosts, "returned posts")
 	})
 
 }
+
+func TestFollowingFeedGetter(t *testing.T) {
+	caller := RandomId()
+	page := pagination.Page{Limit: RandomInt()}
+	follows := []core_values.UserId{RandomId(), RandomId()}
+	posts := []post_entities.Post{RandomPost()}
+	ctxPosts := []post_entities.ContextedPost{RandomContextedPost()}
+
+	getFollows := func(user core_values.UserId) ([]string, error) {
+		if user == caller {
+			return follows, nil
+		}
+		panic("unexpected args")
+	}
+	t.Run("error case - getting follows throws", func(t *testing.T) {
+		getFollows := func(core_values.UserId) ([]string, error) {
+			return nil, RandomError()
+		}
+		_, err := service.NewFollowingFeedGetter(getFollows, nil, nil)(caller, page)
+		AssertSomeError(t, err)
+	})
+	t.Run("happy case - caller does not follow anyone", func(t *testing.T) {
+		getFollows := func(core_values.UserId) ([]string, error) {
+			return []string{}, nil
+		}
+		gotPosts, err := service.NewFollowingFeedGetter(getFollows, nil, nil)(caller, page)
+		AssertNoError(t, err)
+		Assert(t, len(gotPosts), 0, "number of returned posts")
+	})
+	getPosts := func(authors []core_values.UserId, gotPage pagination.Page) ([]post_entities.Post, error) {
+		if reflect.DeepEqual(authors, follows) && gotPage == page {
+			return posts, nil
+		}
+		panic("unexpected args")
+	}
+	t.Run("error case - getting posts throws", func(t *testing.T) {
+		getPosts := func([]core_values.UserId, pagination.Page) ([]post_entities.Post, error) {
+			return nil, RandomError()
+		}
+		_, err := service.NewFollowingFeedGetter(getFollows, getPosts, nil)(caller, page)
+		AssertSomeError(t, err)
+	})
+	addContext := func(postList []post_entities.Post, callerId core_values.UserId) ([]post_entities.ContextedPost, error) {
+		if reflect.DeepEqual(postList, posts) && callerId == caller {
+			return ctxPosts, nil
+		}
+		panic("unexpected args")
+	}
+	t.Run("error case - adding context throws", func(t *testing.T) {
+		addContext := func([]post_entities.Post, core_values.UserId) ([]post_entities.ContextedPost, error) {
+			return nil, RandomError()
+		}
+		_, err := service.NewFollowingFeedGetter(getFollows, getPosts, addContext)(caller, page)
+		AssertSomeError(t, err)
+	})
+	t.Run("happy case", func(t *testing.T) {
+		gotPosts, err := service.NewFollowingFeedGetter(getFollows, getPosts, addContext)(caller, page)
+		AssertNoError(t, err)
+		Assert(t, gotPosts, ctxPosts, "returned posts")
+	})
+}
@@ -3,17 +3,22 @@ package feed
 import (
 	"github.com/go-chi/chi/v5"
 	"github.com/jmoiron/sqlx"
+	"github.com/k0marov/go-socnet/core/abstract/likeable"
 	"github.com/k0marov/go-socnet/core/abstract/recommendable"
+	"github.com/k0marov/go-socnet/core/general/config"
 	"github.com/k0marov/go-socnet/features/feed/delivery/http/handlers"
 	"github.com/k0marov/go-socnet/features/feed/delivery/http/router"
 	"github.com/k0marov/go-socnet/features/feed/domain/service"
+	"github.com/k0marov/go-socnet/features/posts"
 )
 
-func NewFeedRouterImpl(db *sqlx.DB, postRecommendable recommendable.Recommendable) func(chi.Router) {
+func NewFeedRouterImpl(cfg config.Config, db *sqlx.DB, postRecommendable recommendable.Recommendable, getFollows likeable.UserLikesGetter, postListing posts.PostListing) func(chi.Router) {
 	// service
-	getFeed := service.NewFeedGetter(postRecommendable.GetRecs)
+	getFeed := service.NewFeedGetter(cfg.Limits.MaxFeedCount, postRecommendable.GetRecs, postListing.GetByIds, postListing.AddContext)
+	getFollowingFeed := service.NewFollowingFeedGetter(getFollows, postListing.GetByAuthors, postListing.AddContext)
 	// handlers
 	feedHandler := handlers.NewFeedHandler(getFeed)
+	followingFeedHandler := handlers.NewFollowingFeedHandler(getFollowingFeed)
 
-	return router.NewFeedRouter(feedHandler)
+	return router.NewFeedRouter(feedHandler, followingFeedHandler)
 }
@@ -0,0 +1,178 @@
+package handlers
+
+import (
+	"github.com/go-chi/chi/v5"
+	"github.com/k0marov/go-socnet/core/general/client_errors"
+	"github.com/k0marov/go-socnet/core/helpers/http_helpers"
+	"net/http"
+	"path/filepath"
+	"strconv"
+
+	"github.com/k0marov/go-socnet/features/messages/delivery/http/responses"
+	"github.com/k0marov/go-socnet/features/messages/domain/service"
+	"github.com/k0marov/go-socnet/features/messages/domain/values"
+)
+
+func NewStartConversationHandler(startConversation service.ConversationStarter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		caller, ok := http_helpers.GetUserOrAddUnauthorized(w, r)
+		if !ok {
+			return
+		}
+		interlocutor := r.URL.Query().Get("profile_id")
+		if interlocutor == "" {
+			http_helpers.ThrowClientError(w, client_errors.IdNotProvided)
+			return
+		}
+		id, err := startConversation(caller.Id, interlocutor)
+		if err != nil {
+			http_helpers.HandleServiceError(w, err)
+			return
+		}
+		http_helpers.WriteJson(w, responses.StartedConversationResponse{Id: id})
+	}
+}
+
+func NewGetConversationsHandler(getConversations service.ConversationsGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		caller, ok := http_helpers.GetUserOrAddUnauthorized(w, r)
+		if !ok {
+			return
+		}
+		page, ok := http_helpers.GetPageOrThrowClientError(w, r)
+		if !ok {
+			return
+		}
+		conversations, next, err := getConversations(caller.Id, page)
+		if err != nil {
+			http_helpers.HandleServiceError(w, err)
+			return
+		}
+		http_helpers.WriteJson(w, responses.NewConversationsResponse(conversations, next))
+	}
+}
+
+func NewGetUnreadCountHandler(getUnreadCount service.UnreadCountGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		caller, ok := http_helpers.GetUserOrAddUnauthorized(w, r)
+		if !ok {
+			return
+		}
+		count, err := getUnreadCount(caller.Id)
+		if err != nil {
+			http_helpers.HandleServiceError(w, err)
+			return
+		}
+		http_helpers.WriteJson(w, responses.UnreadCountResponse{Count: count})
+	}
+}
+
+// NewSendMessageHandler expects a multipart form with the "text" field and optional "image_<i>" files, starting from "image_1"
+func NewSendMessageHandler(sendMessage service.MessageSender) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		caller, ok := http_helpers.GetUserOrAddUnauthorized(w, r)
+		if !ok {
+			return
+		}
+		conversation := chi.URLParam(r, "id")
+		if conversation == "" {
+			http_helpers.ThrowClientError(w, client_errors.IdNotProvided)
+			return
+		}
+		newMessage := values.NewMessageData{
+			Conversation: conversation,
+			Sender:       caller.Id,
+			Text:         r.FormValue("text"),
+			Images:       parseImages(r),
+		}
+		message, err := sendMessage(newMessage)
+		if err != nil {
+			http_helpers.HandleServiceError(w, err)
+			return
+		}
+		http_helpers.WriteJson(w, responses.NewMessageResponse(message))
+	}
+}
+
+func parseImages(r *http.Request) []values.MessageImageFile {
+	images := []values.MessageImageFile{}
+	for i := 1; ; i++ {
+		file, ok := http_helpers.ParseFile(r, "image_"+strconv.Itoa(i))
+		if !ok {
+			return images
+		}
+		images = append(images, values.MessageImageFile{File: file, Index: i})
+	}
+}
+
+func NewGetMessagesHandler(getMessages service.MessagesGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		caller, ok := http_helpers.GetUserOrAddUnauthorized(w, r)
+		if !ok {
+			return
+		}
+		conversation := chi.URLParam(r, "id")
+		if conversation == "" {
+			http_helpers.ThrowClientError(w, client_errors.IdNotProvided)
+			return
+		}
+		page, ok := http_helpers.GetPageOrThrowClientError(w, r)
+		if !ok {
+			return
+		}
+		messages, next, err := getMessages(conversation, caller.Id, page)
+		if err != nil {
+			http_helpers.HandleServiceError(w, err)
+			return
+		}
+		http_helpers.WriteJson(w, responses.NewMessagesResponse(messages, next))
+	}
+}
+
+// NewGetMessageImageHandler serves an image of a message from the private dir, only to the participants of the conversation
+func NewGetMessageImageHandler(getImage service.MessageImageGetter, privateDir string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		caller, ok := http_helpers.GetUserOrAddUnauthorized(w, r)
+		if !ok {
+			return
+		}
+		conversation, message := chi.URLParam(r, "id"), chi.URLParam(r, "message_id")
+		if conversation == "" || message == "" {
+			http_helpers.ThrowClientError(w, client_errors.IdNotProvided)
+			return
+		}
+		index, err := strconv.Atoi(chi.URLParam(r, "index"))
+		if err != nil {
+			// there is no image with such an index
+			http_helpers.ThrowClientError(w, client_errors.NotFound)
+			return
+		}
+		path, err := getImage(conversation, message, index, caller.Id)
+		if err != nil {
+			http_helpers.HandleServiceError(w, err)
+			return
+		}
+		// shared caches shouldn't keep a private image
+		w.Header().Set("Cache-Control", "private")
+		http.ServeFile(w, r, filepath.Join(privateDir, path))
+	}
+}
+
+func NewReadConversationHandler(readConversation service.ConversationReader) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		caller, ok := http_helpers.GetUserOrAddUnauthorized(w, r)
+		if !ok {
+			return
+		}
+		conversation := chi.URLParam(r, "id")
+		if conversation == "" {
+			http_helpers.ThrowClientError(w, client_errors.IdNotProvided)
+			return
+		}
+		err := readConversation(conversation, caller.Id)
+		if err != nil {
+			http_helpers.HandleServiceError(w, err)
+			return
+		}
+	}
+}
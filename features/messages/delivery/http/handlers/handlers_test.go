@@ -0,0 +1,292 @@
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/go-chi/chi/v5"
+	"github.com/k0marov/go-socnet/core/general/client_errors"
+	"github.com/k0marov/go-socnet/core/general/core_values"
+	"github.com/k0marov/go-socnet/core/general/pagination"
+	helpers "github.com/k0marov/go-socnet/core/helpers/http_test_helpers"
+	. "github.com/k0marov/go-socnet/core/helpers/test_helpers"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/k0marov/go-socnet/features/messages/delivery/http/handlers"
+	"github.com/k0marov/go-socnet/features/messages/delivery/http/responses"
+	"github.com/k0marov/go-socnet/features/messages/domain/entities"
+	"github.com/k0marov/go-socnet/features/messages/domain/models"
+	"github.com/k0marov/go-socnet/features/messages/domain/values"
+	auth "github.com/k0marov/golang-auth"
+)
+
+func createRequestWithId(id values.ConversationId, caller auth.User, target string, body io.Reader) *http.Request {
+	request := httptest.NewRequest(http.MethodGet, target, body)
+	ctx := chi.NewRouteContext()
+	ctx.URLParams.Add("id", id)
+	request = request.WithContext(context.WithValue(request.Context(), chi.RouteCtxKey, ctx))
+	return helpers.AddAuthDataToRequest(request, caller)
+}
+
+func randomMessage() entities.Message {
+	return entities.Message{
+		MessageModel: models.MessageModel{Id: RandomId(), ConversationId: RandomId(), SenderId: RandomId(), Text: RandomString(), CreatedAt: RandomTime().Unix()},
+		Images:       []values.MessageImage{{URL: RandomString(), Index: 1}},
+	}
+}
+
+func TestStartConversationHandler(t *testing.T) {
+	caller := RandomAuthUser()
+	interlocutor := RandomId()
+	helpers.BaseTest401(t, handlers.NewStartConversationHandler(nil))
+	t.Run("happy case", func(t *testing.T) {
+		conversation := RandomId()
+		start := func(callerId, interlocutorId core_values.UserId) (values.ConversationId, error) {
+			if callerId == caller.Id && interlocutorId == interlocutor {
+				return conversation, nil
+			}
+			panic("unexpected args")
+		}
+		request := helpers.AddAuthDataToRequest(httptest.NewRequest(http.MethodPost, "/handler-should-not-care?profile_id="+interlocutor, nil), caller)
+		response := httptest.NewRecorder()
+		handlers.NewStartConversationHandler(start).ServeHTTP(response, request)
+		AssertJSONData(t, response, responses.StartedConversationResponse{Id: conversation})
+	})
+	t.Run("error case - profile id is not provided", func(t *testing.T) {
+		response := httptest.NewRecorder()
+		handlers.NewStartConversationHandler(nil).ServeHTTP(response, helpers.AddAuthDataToRequest(helpers.CreateRequest(nil), caller))
+		AssertClientError(t, response, client_errors.IdNotProvided)
+	})
+	helpers.BaseTestServiceErrorHandling(t, func(err error, response *httptest.ResponseRecorder) {
+		start := func(core_values.UserId, core_values.UserId) (values.ConversationId, error) {
+			return "", err
+		}
+		request := helpers.AddAuthDataToRequest(httptest.NewRequest(http.MethodPost, "/handler-should-not-care?profile_id="+interlocutor, nil), caller)
+		handlers.NewStartConversationHandler(start).ServeHTTP(response, request)
+	})
+}
+
+func TestGetConversationsHandler(t *testing.T) {
+	caller := RandomAuthUser()
+	helpers.BaseTest401(t, handlers.NewGetConversationsHandler(nil))
+	t.Run("happy case", func(t *testing.T) {
+		conversations := []entities.ContextedConversation{{
+			Conversation: entities.Conversation{ConversationModel: models.ConversationModel{Id: RandomId(), Unread: 3}, LastMessage: randomMessage()},
+			Interlocutor: RandomContextedProfile(),
+		}}
+		next := pagination.Cursor{CreatedAt: int64(RandomInt()), Id: RandomId()}
+		getConversations := func(callerId core_values.UserId, page pagination.Page) ([]entities.ContextedConversation, pagination.Cursor, error) {
+			if callerId == caller.Id && page == (pagination.Page{Limit: 5}) {
+				return conversations, next, nil
+			}
+			panic("unexpected args")
+		}
+		request := helpers.AddAuthDataToRequest(httptest.NewRequest(http.MethodGet, "/handler-should-not-care?limit=5", nil), caller)
+		response := httptest.NewRecorder()
+		handlers.NewGetConversationsHandler(getConversations).ServeHTTP(response, request)
+		AssertJSONData(t, response, responses.NewConversationsResponse(conversations, next))
+	})
+	t.Run("error case - cursor is invalid", func(t *testing.T) {
+		request := helpers.AddAuthDataToRequest(httptest.NewRequest(http.MethodGet, "/handler-should-not-care?cursor=!", nil), caller)
+		response := httptest.NewRecorder()
+		handlers.NewGetConversationsHandler(nil).ServeHTTP(response, request)
+		AssertClientError(t, response, client_errors.InvalidCursor)
+	})
+	helpers.BaseTestServiceErrorHandling(t, func(err error, response *httptest.ResponseRecorder) {
+		getConversations := func(core_values.UserId, pagination.Page) ([]entities.ContextedConversation, pagination.Cursor, error) {
+			return nil, pagination.Cursor{}, err
+		}
+		handlers.NewGetConversationsHandler(getConversations).ServeHTTP(response, helpers.AddAuthDataToRequest(helpers.CreateRequest(nil), caller))
+	})
+}
+
+func TestGetUnreadCountHandler(t *testing.T) {
+	caller := RandomAuthUser()
+	helpers.BaseTest401(t, handlers.NewGetUnreadCountHandler(nil))
+	t.Run("happy case", func(t *testing.T) {
+		count := RandomInt()
+		getCount := func(callerId core_values.UserId) (int, error) {
+			if callerId == caller.Id {
+				return count, nil
+			}
+			panic("unexpected args")
+		}
+		response := httptest.NewRecorder()
+		handlers.NewGetUnreadCountHandler(getCount).ServeHTTP(response, helpers.AddAuthDataToRequest(helpers.CreateRequest(nil), caller))
+		AssertJSONData(t, response, responses.UnreadCountResponse{Count: count})
+	})
+	helpers.BaseTestServiceErrorHandling(t, func(err error, response *httptest.ResponseRecorder) {
+		getCount := func(core_values.UserId) (int, error) {
+			return 0, err
+		}
+		handlers.NewGetUnreadCountHandler(getCount).ServeHTTP(response, helpers.AddAuthDataToRequest(helpers.CreateRequest(nil), caller))
+	})
+}
+
+func TestSendMessageHandler(t *testing.T) {
+	caller := RandomAuthUser()
+	conversation := RandomId()
+	createRequest := func(newMessage values.NewMessageData) *http.Request {
+		body := bytes.NewBuffer(nil)
+		writer := multipart.NewWriter(body)
+		writer.WriteField("text", newMessage.Text)
+		for _, image := range newMessage.Images {
+			fw, _ := writer.CreateFormFile(fmt.Sprintf("image_%d", image.Index), RandomString())
+			fw.Write(image.File.Value())
+		}
+		writer.Close()
+		request := createRequestWithId(conversation, caller, "/handler-should-not-care", body)
+		request.Header.Set("Content-Type", writer.FormDataContentType())
+		return request
+	}
+	helpers.BaseTest401(t, handlers.NewSendMessageHandler(nil))
+	cases := []values.NewMessageData{
+		{Conversation: conversation, Sender: caller.Id, Text: "no images", Images: []values.MessageImageFile{}},
+		{Conversation: conversation, Sender: caller.Id, Text: "", Images: []values.MessageImageFile{{File: RandomFileData(), Index: 1}, {File: RandomFileData(), Index: 2}}},
+	}
+	for _, newMessage := range cases {
+		t.Run(fmt.Sprintf("%d images", len(newMessage.Images)), func(t *testing.T) {
+			message := randomMessage()
+			send := func(gotMessage values.NewMessageData) (entities.Message, error) {
+				if reflect.DeepEqual(gotMessage, newMessage) {
+					return message, nil
+				}
+				panic(fmt.Sprintf("unexpected args: newMessage = %+v", gotMessage))
+			}
+			response := httptest.NewRecorder()
+			handlers.NewSendMessageHandler(send).ServeHTTP(response, createRequest(newMessage))
+			AssertJSONData(t, response, responses.NewMessageResponse(message))
+		})
+	}
+	t.Run("error case - id is not provided", func(t *testing.T) {
+		response := httptest.NewRecorder()
+		handlers.NewSendMessageHandler(nil).ServeHTTP(response, helpers.AddAuthDataToRequest(helpers.CreateRequest(nil), caller))
+		AssertClientError(t, response, client_errors.IdNotProvided)
+	})
+	helpers.BaseTestServiceErrorHandling(t, func(err error, response *httptest.ResponseRecorder) {
+		send := func(values.NewMessageData) (entities.Message, error) {
+			return entities.Message{}, err
+		}
+		handlers.NewSendMessageHandler(send).ServeHTTP(response, createRequest(values.NewMessageData{Text: RandomString()}))
+	})
+}
+
+func TestGetMessagesHandler(t *testing.T) {
+	caller := RandomAuthUser()
+	conversation := RandomId()
+	helpers.BaseTest401(t, handlers.NewGetMessagesHandler(nil))
+	t.Run("happy case", func(t *testing.T) {
+		messages := []entities.Message{randomMessage(), randomMessage()}
+		next := pagination.Cursor{CreatedAt: int64(RandomInt()), Id: RandomId()}
+		getMessages := func(gotConversation values.ConversationId, callerId core_values.UserId, page pagination.Page) ([]entities.Message, pagination.Cursor, error) {
+			if gotConversation == conversation && callerId == caller.Id && page == (pagination.Page{Limit: 2}) {
+				return messages, next, nil
+			}
+			panic("unexpected args")
+		}
+		response := httptest.NewRecorder()
+		handlers.NewGetMessagesHandler(getMessages).ServeHTTP(response, createRequestWithId(conversation, caller, "/handler-should-not-care?limit=2", nil))
+		AssertJSONData(t, response, responses.NewMessagesResponse(messages, next))
+	})
+	t.Run("error case - id is not provided", func(t *testing.T) {
+		response := httptest.NewRecorder()
+		handlers.NewGetMessagesHandler(nil).ServeHTTP(response, helpers.AddAuthDataToRequest(helpers.CreateRequest(nil), caller))
+		AssertClientError(t, response, client_errors.IdNotProvided)
+	})
+	t.Run("error case - limit is invalid", func(t *testing.T) {
+		response := httptest.NewRecorder()
+		handlers.NewGetMessagesHandler(nil).ServeHTTP(response, createRequestWithId(conversation, caller, "/handler-should-not-care?limit=-1", nil))
+		AssertClientError(t, response, client_errors.InvalidLimit)
+	})
+	helpers.BaseTestServiceErrorHandling(t, func(err error, response *httptest.ResponseRecorder) {
+		getMessages := func(values.ConversationId, core_values.UserId, pagination.Page) ([]entities.Message, pagination.Cursor, error) {
+			return nil, pagination.Cursor{}, err
+		}
+		handlers.NewGetMessagesHandler(getMessages).ServeHTTP(response, createRequestWithId(conversation, caller, "/handler-should-not-care", nil))
+	})
+}
+
+func TestReadConversationHandler(t *testing.T) {
+	caller := RandomAuthUser()
+	conversation := RandomId()
+	helpers.BaseTest401(t, handlers.NewReadConversationHandler(nil))
+	t.Run("happy case", func(t *testing.T) {
+		read := func(gotConversation values.ConversationId, callerId core_values.UserId) error {
+			if gotConversation == conversation && callerId == caller.Id {
+				return nil
+			}
+			panic("unexpected args")
+		}
+		response := httptest.NewRecorder()
+		handlers.NewReadConversationHandler(read).ServeHTTP(response, createRequestWithId(conversation, caller, "/handler-should-not-care", nil))
+		AssertStatusCode(t, response, http.StatusOK)
+	})
+	t.Run("error case - id is not provided", func(t *testing.T) {
+		response := httptest.NewRecorder()
+		handlers.NewReadConversationHandler(nil).ServeHTTP(response, helpers.AddAuthDataToRequest(helpers.CreateRequest(nil), caller))
+		AssertClientError(t, response, client_errors.IdNotProvided)
+	})
+	helpers.BaseTestServiceErrorHandling(t, func(err error, response *httptest.ResponseRecorder) {
+		read := func(values.ConversationId, core_values.UserId) error {
+			return err
+		}
+		handlers.NewReadConversationHandler(read).ServeHTTP(response, createRequestWithId(conversation, caller, "/handler-should-not-care", nil))
+	})
+}
+
+func TestGetMessageImageHandler(t *testing.T) {
+	caller := RandomAuthUser()
+	conversation := RandomId()
+	message := RandomId()
+	createRequest := func(index string) *http.Request {
+		request := httptest.NewRequest(http.MethodGet, "/handler-should-not-care", nil)
+		ctx := chi.NewRouteContext()
+		ctx.URLParams.Add("id", conversation)
+		ctx.URLParams.Add("message_id", message)
+		ctx.URLParams.Add("index", index)
+		request = request.WithContext(context.WithValue(request.Context(), chi.RouteCtxKey, ctx))
+		return helpers.AddAuthDataToRequest(request, caller)
+	}
+	helpers.BaseTest401(t, handlers.NewGetMessageImageHandler(nil, ""))
+	t.Run("happy case", func(t *testing.T) {
+		privateDir := t.TempDir()
+		image := []byte(RandomString())
+		path := filepath.Join("message_"+message, "image_1")
+		AssertNoError(t, os.MkdirAll(filepath.Join(privateDir, filepath.Dir(path)), 0777))
+		AssertNoError(t, os.WriteFile(filepath.Join(privateDir, path), image, 0666))
+		getImage := func(gotConversation values.ConversationId, gotMessage values.MessageId, index int, callerId core_values.UserId) (core_values.StaticPath, error) {
+			if gotConversation == conversation && gotMessage == message && index == 1 && callerId == caller.Id {
+				return path, nil
+			}
+			panic("unexpected args")
+		}
+		response := httptest.NewRecorder()
+		handlers.NewGetMessageImageHandler(getImage, privateDir).ServeHTTP(response, createRequest("1"))
+		AssertStatusCode(t, response, http.StatusOK)
+		Assert(t, response.Body.Bytes(), image, "served image")
+		Assert(t, response.Header().Get("Cache-Control"), "private", "Cache-Control header")
+	})
+	t.Run("error case - id is not provided", func(t *testing.T) {
+		response := httptest.NewRecorder()
+		handlers.NewGetMessageImageHandler(nil, "").ServeHTTP(response, helpers.AddAuthDataToRequest(helpers.CreateRequest(nil), caller))
+		AssertClientError(t, response, client_errors.IdNotProvided)
+	})
+	t.Run("error case - index is not a number", func(t *testing.T) {
+		response := httptest.NewRecorder()
+		handlers.NewGetMessageImageHandler(nil, "").ServeHTTP(response, createRequest("abc"))
+		AssertClientError(t, response, client_errors.NotFound)
+	})
+	helpers.BaseTestServiceErrorHandling(t, func(err error, response *httptest.ResponseRecorder) {
+		getImage := func(values.ConversationId, values.MessageId, int, core_values.UserId) (core_values.StaticPath, error) {
+			return "", err
+		}
+		handlers.NewGetMessageImageHandler(getImage, "").ServeHTTP(response, createRequest("1"))
+	})
+}
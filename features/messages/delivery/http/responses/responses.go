@@ -0,0 +1,93 @@
+package responses
+
+import (
+	"github.com/k0marov/go-socnet/core/general/pagination"
+	"github.com/k0marov/go-socnet/core/helpers"
+	"github.com/k0marov/go-socnet/features/messages/domain/entities"
+	"github.com/k0marov/go-socnet/features/messages/domain/values"
+	profile_responses "github.com/k0marov/go-socnet/features/profiles/delivery/http/responses"
+)
+
+type MessageImageResponse struct {
+	Index int    `json:"index"`
+	Url   string `json:"url"`
+}
+
+type MessageResponse struct {
+	Id             string                 `json:"id"`
+	ConversationId string                 `json:"conversation_id"`
+	SenderId       string                 `json:"sender_id"`
+	Text           string                 `json:"text"`
+	Images         []MessageImageResponse `json:"images"`
+	CreatedAt      int64                  `json:"created_at"`
+	// ReadAt is the time when the recipient has read the message, 0 while it is unread
+	ReadAt int64 `json:"read_at"`
+}
+
+type MessagesResponse struct {
+	Messages   []MessageResponse `json:"messages"`
+	NextCursor string            `json:"next_cursor"`
+}
+
+type ConversationResponse struct {
+	Id           string                            `json:"id"`
+	Interlocutor profile_responses.ProfileResponse `json:"interlocutor"`
+	LastMessage  MessageResponse                   `json:"last_message"`
+	Unread       int                               `json:"unread"`
+}
+
+type ConversationsResponse struct {
+	Conversations []ConversationResponse `json:"conversations"`
+	NextCursor    string                 `json:"next_cursor"`
+}
+
+type StartedConversationResponse struct {
+	Id string `json:"id"`
+}
+
+type UnreadCountResponse struct {
+	Count int `json:"count"`
+}
+
+func newMessageImageListResponse(images []values.MessageImage) []MessageImageResponse {
+	respList := make([]MessageImageResponse, 0, len(images))
+	for _, image := range images {
+		respList = append(respList, MessageImageResponse{Index: image.Index, Url: image.URL})
+	}
+	return respList
+}
+
+func NewMessageResponse(message entities.Message) MessageResponse {
+	return MessageResponse{
+		Id:             message.Id,
+		ConversationId: message.ConversationId,
+		SenderId:       message.SenderId,
+		Text:           message.Text,
+		Images:         newMessageImageListResponse(message.Images),
+		CreatedAt:      message.CreatedAt,
+		ReadAt:         message.ReadAt,
+	}
+}
+
+func NewMessagesResponse(messages []entities.Message, next pagination.Cursor) MessagesResponse {
+	return MessagesResponse{
+		Messages:   helpers.MapForEach(messages, NewMessageResponse),
+		NextCursor: next.Encode(),
+	}
+}
+
+func NewConversationResponse(conversation entities.ContextedConversation) ConversationResponse {
+	return ConversationResponse{
+		Id:           conversation.Id,
+		Interlocutor: profile_responses.NewProfileResponse(conversation.Interlocutor),
+		LastMessage:  NewMessageResponse(conversation.LastMessage),
+		Unread:       conversation.Unread,
+	}
+}
+
+func NewConversationsResponse(conversations []entities.ContextedConversation, next pagination.Cursor) ConversationsResponse {
+	return ConversationsResponse{
+		Conversations: helpers.MapForEach(conversations, NewConversationResponse),
+		NextCursor:    next.Encode(),
+	}
+}
@@ -0,0 +1,20 @@
+package router
+
+import (
+	"github.com/go-chi/chi/v5"
+	"net/http"
+)
+
+func NewConversationsRouter(startConversation, getConversations, getUnreadCount, sendMessage, getMessages, getMessageImage, readConversation http.HandlerFunc) func(chi.Router) {
+	return func(r chi.Router) {
+		r.Post("/", startConversation)
+		r.Get("/", getConversations)
+		r.Get("/unread-count", getUnreadCount)
+		r.Route("/{id}", func(r chi.Router) {
+			r.Post("/messages", sendMessage)
+			r.Get("/messages", getMessages)
+			r.Get("/messages/{message_id}/images/{index}", getMessageImage)
+			r.Post("/read", readConversation)
+		})
+	}
+}
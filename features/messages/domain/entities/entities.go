@@ -0,0 +1,39 @@
+package entities
+
+import (
+	"github.com/k0marov/go-socnet/core/general/pagination"
+	"github.com/k0marov/go-socnet/features/messages/domain/models"
+	"github.com/k0marov/go-socnet/features/messages/domain/values"
+	profile_entities "github.com/k0marov/go-socnet/features/profiles/domain/entities"
+)
+
+type Message struct {
+	models.MessageModel
+	Images []values.MessageImage
+}
+
+func (m Message) Cursor() pagination.Cursor {
+	return pagination.Cursor{CreatedAt: m.CreatedAt, Id: m.Id}
+}
+
+type Conversation struct {
+	models.ConversationModel
+	LastMessage Message
+}
+
+type ContextedConversation struct {
+	Conversation
+	Interlocutor profile_entities.ContextedProfile
+}
+
+// ImageURLs replaces the stored paths of the images of a message with the URLs from which they are served
+func ImageURLs(message models.MessageModel, imageURL values.ImageURLBuilder) []values.MessageImage {
+	images := []values.MessageImage{}
+	for _, model := range message.Images {
+		images = append(images, values.MessageImage{
+			URL:   imageURL(message.ConversationId, message.Id, model.Index),
+			Index: model.Index,
+		})
+	}
+	return images
+}
@@ -0,0 +1,58 @@
+package models
+
+import (
+	"github.com/k0marov/go-socnet/core/general/core_values"
+	"github.com/k0marov/go-socnet/core/general/pagination"
+	"time"
+
+	"github.com/k0marov/go-socnet/features/messages/domain/values"
+)
+
+type ConversationModel struct {
+	Id       values.ConversationId `db:"id"`
+	FirstId  core_values.UserId    `db:"firstProfile_id"`
+	SecondId core_values.UserId    `db:"secondProfile_id"`
+	// Unread is the number of messages unread by the participant for whom the conversation was listed
+	Unread        int   `db:"unread"`
+	CreatedAt     int64 `db:"createdAt"`
+	LastMessageAt int64 `db:"lastMessageAt"`
+}
+
+func (c ConversationModel) HasParticipant(profile core_values.UserId) bool {
+	return c.FirstId == profile || c.SecondId == profile
+}
+
+// Interlocutor returns the participant other than profile
+func (c ConversationModel) Interlocutor(profile core_values.UserId) core_values.UserId {
+	if c.FirstId == profile {
+		return c.SecondId
+	}
+	return c.FirstId
+}
+
+// Cursor uses the time of the latest message, since the conversations are listed by it
+func (c ConversationModel) Cursor() pagination.Cursor {
+	return pagination.Cursor{CreatedAt: c.LastMessageAt, Id: c.Id}
+}
+
+type MessageToCreate struct {
+	Conversation values.ConversationId
+	Sender       core_values.UserId
+	Text         string
+	CreatedAt    time.Time
+}
+
+type MessageImageModel struct {
+	Index int                    `db:"ind"`
+	Path  core_values.StaticPath `db:"path"`
+}
+
+type MessageModel struct {
+	Id             values.MessageId      `db:"id"`
+	ConversationId values.ConversationId `db:"conversation_id"`
+	SenderId       core_values.UserId    `db:"sender_id"`
+	Text           string                `db:"textContent"`
+	CreatedAt      int64                 `db:"createdAt"`
+	ReadAt         int64                 `db:"readAt"`
+	Images         []MessageImageModel
+}
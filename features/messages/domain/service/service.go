@@ -0,0 +1,166 @@
+package service
+
+import (
+	"errors"
+	"github.com/k0marov/go-socnet/core/general/client_errors"
+	"github.com/k0marov/go-socnet/core/general/core_err"
+	"github.com/k0marov/go-socnet/core/general/core_values"
+	"github.com/k0marov/go-socnet/core/general/pagination"
+	"time"
+
+	"github.com/k0marov/go-socnet/features/messages/domain/entities"
+	"github.com/k0marov/go-socnet/features/messages/domain/store"
+	"github.com/k0marov/go-socnet/features/messages/domain/validators"
+	"github.com/k0marov/go-socnet/features/messages/domain/values"
+	profile_service "github.com/k0marov/go-socnet/features/profiles/domain/service"
+)
+
+type (
+	// ConversationStarter returns the conversation of caller with interlocutor, creating it if there is none
+	ConversationStarter func(caller, interlocutor core_values.UserId) (values.ConversationId, error)
+	// ConversationsGetter returns the conversations of caller, the one with the latest message first, and the cursor of the next page
+	ConversationsGetter func(caller core_values.UserId, page pagination.Page) ([]entities.ContextedConversation, pagination.Cursor, error)
+	MessageSender       func(newMessage values.NewMessageData) (entities.Message, error)
+	// MessagesGetter returns the messages of a conversation, newest first, and the cursor of the next page
+	MessagesGetter     func(conversation values.ConversationId, caller core_values.UserId, page pagination.Page) ([]entities.Message, pagination.Cursor, error)
+	ConversationReader func(conversation values.ConversationId, caller core_values.UserId) error
+	// MessageImageGetter returns the path of an image of a message in the private dir, if caller participates in the conversation
+	MessageImageGetter func(conversation values.ConversationId, message values.MessageId, index int, caller core_values.UserId) (core_values.StaticPath, error)
+	// UnreadCountGetter returns the number of unread messages of caller in all of their conversations
+	UnreadCountGetter func(caller core_values.UserId) (int, error)
+)
+
+func NewConversationStarter(getProfile profile_service.ProfileGetter, createConversation store.ConversationCreator) ConversationStarter {
+	return func(caller, interlocutor core_values.UserId) (values.ConversationId, error) {
+		if caller == interlocutor {
+			return "", client_errors.MessagingYourself
+		}
+		_, err := getProfile(interlocutor, caller)
+		if err != nil {
+			return "", core_err.Rethrow("getting the interlocutor", err)
+		}
+		first, second := values.NewParticipants(caller, interlocutor)
+		id, err := createConversation(first, second, time.Now())
+		if err != nil {
+			return "", core_err.Rethrow("creating a conversation in store", err)
+		}
+		return id, nil
+	}
+}
+
+func NewConversationsGetter(getConversations store.ConversationsGetter, getProfiles profile_service.ProfilesGetter) ConversationsGetter {
+	return func(caller core_values.UserId, page pagination.Page) ([]entities.ContextedConversation, pagination.Cursor, error) {
+		conversations, err := getConversations(caller, page)
+		if err != nil {
+			return []entities.ContextedConversation{}, pagination.Cursor{}, core_err.Rethrow("getting conversations from store", err)
+		}
+		contexted := []entities.ContextedConversation{}
+		if len(conversations) == 0 {
+			return contexted, pagination.Cursor{}, nil
+		}
+		var interlocutorIds []core_values.UserId
+		for _, conversation := range conversations {
+			interlocutorIds = append(interlocutorIds, conversation.Interlocutor(caller))
+		}
+		interlocutors, err := getProfiles(interlocutorIds, caller)
+		if err != nil {
+			return []entities.ContextedConversation{}, pagination.Cursor{}, core_err.Rethrow("getting interlocutors of conversations", err)
+		}
+		for _, conversation := range conversations {
+			interlocutor, ok := interlocutors[conversation.Interlocutor(caller)]
+			if !ok {
+				continue
+			}
+			contexted = append(contexted, entities.ContextedConversation{Conversation: conversation, Interlocutor: interlocutor})
+		}
+		// the cursor is taken from the stored conversations, so that skipping some of them doesn't end the listing early
+		return contexted, pagination.NextCursor(conversations, page, entities.Conversation.Cursor), nil
+	}
+}
+
+func NewMessageSender(validate validators.MessageValidator, getConversation store.ConversationGetter, createMessage store.MessageCreator) MessageSender {
+	return func(newMessage values.NewMessageData) (entities.Message, error) {
+		if clientErr, ok := validate(newMessage); !ok {
+			return entities.Message{}, clientErr
+		}
+		err := checkParticipant(getConversation, newMessage.Conversation, newMessage.Sender)
+		if err != nil {
+			return entities.Message{}, err
+		}
+		message, err := createMessage(newMessage, time.Now())
+		if err != nil {
+			return entities.Message{}, core_err.Rethrow("creating a message in store", err)
+		}
+		return message, nil
+	}
+}
+
+func NewMessagesGetter(getConversation store.ConversationGetter, getMessages store.MessagesGetter) MessagesGetter {
+	return func(conversation values.ConversationId, caller core_values.UserId, page pagination.Page) ([]entities.Message, pagination.Cursor, error) {
+		err := checkParticipant(getConversation, conversation, caller)
+		if err != nil {
+			return []entities.Message{}, pagination.Cursor{}, err
+		}
+		messages, err := getMessages(conversation, page)
+		if err != nil {
+			return []entities.Message{}, pagination.Cursor{}, core_err.Rethrow("getting messages from store", err)
+		}
+		return messages, pagination.NextCursor(messages, page, entities.Message.Cursor), nil
+	}
+}
+
+func NewMessageImageGetter(getConversation store.ConversationGetter, getImage store.MessageImageGetter) MessageImageGetter {
+	return func(conversation values.ConversationId, message values.MessageId, index int, caller core_values.UserId) (core_values.StaticPath, error) {
+		err := checkParticipant(getConversation, conversation, caller)
+		if err != nil {
+			return "", err
+		}
+		path, err := getImage(conversation, message, index)
+		if errors.Is(err, core_err.ErrNotFound) {
+			return "", client_errors.NotFound
+		}
+		if err != nil {
+			return "", core_err.Rethrow("getting a message image from store", err)
+		}
+		return path, nil
+	}
+}
+
+func NewConversationReader(getConversation store.ConversationGetter, readConversation store.ConversationReader) ConversationReader {
+	return func(conversation values.ConversationId, caller core_values.UserId) error {
+		err := checkParticipant(getConversation, conversation, caller)
+		if err != nil {
+			return err
+		}
+		err = readConversation(conversation, caller, time.Now())
+		if err != nil {
+			return core_err.Rethrow("marking a conversation as read in store", err)
+		}
+		return nil
+	}
+}
+
+func NewUnreadCountGetter(getUnreadCount store.UnreadCountGetter) UnreadCountGetter {
+	return func(caller core_values.UserId) (int, error) {
+		count, err := getUnreadCount(caller)
+		if err != nil {
+			return 0, core_err.Rethrow("getting the number of unread messages from store", err)
+		}
+		return count, nil
+	}
+}
+
+// checkParticipant makes sure that only the participants of a conversation can read and write its messages
+func checkParticipant(getConversation store.ConversationGetter, id values.ConversationId, caller core_values.UserId) error {
+	conversation, err := getConversation(id)
+	if errors.Is(err, core_err.ErrNotFound) {
+		return client_errors.NotFound
+	}
+	if err != nil {
+		return core_err.Rethrow("getting a conversation", err)
+	}
+	if !conversation.HasParticipant(caller) {
+		return client_errors.InsufficientPermissions
+	}
+	return nil
+}
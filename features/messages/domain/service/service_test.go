@@ -0,0 +1,319 @@
+package service_test
+
+import (
+	"github.com/k0marov/go-socnet/core/general/client_errors"
+	"github.com/k0marov/go-socnet/core/general/core_err"
+	"github.com/k0marov/go-socnet/core/general/core_values"
+	"github.com/k0marov/go-socnet/core/general/pagination"
+	. "github.com/k0marov/go-socnet/core/helpers/test_helpers"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/k0marov/go-socnet/features/messages/domain/entities"
+	"github.com/k0marov/go-socnet/features/messages/domain/models"
+	"github.com/k0marov/go-socnet/features/messages/domain/service"
+	"github.com/k0marov/go-socnet/features/messages/domain/store"
+	"github.com/k0marov/go-socnet/features/messages/domain/values"
+	profile_entities "github.com/k0marov/go-socnet/features/profiles/domain/entities"
+)
+
+// baseTestParticipantCheck tests the cases in which a conversation can't be accessed by caller
+func baseTestParticipantCheck(t *testing.T, conversation values.ConversationId, caller core_values.UserId, callSut func(store.ConversationGetter) error) {
+	t.Helper()
+	t.Run("error case - conversation is not found", func(t *testing.T) {
+		getConversation := func(values.ConversationId) (models.ConversationModel, error) {
+			return models.ConversationModel{}, core_err.ErrNotFound
+		}
+		AssertError(t, callSut(getConversation), client_errors.NotFound)
+	})
+	t.Run("error case - getting the conversation returns an error", func(t *testing.T) {
+		getConversation := func(values.ConversationId) (models.ConversationModel, error) {
+			return models.ConversationModel{}, RandomError()
+		}
+		AssertSomeError(t, callSut(getConversation))
+	})
+	t.Run("error case - caller is not a participant", func(t *testing.T) {
+		getConversation := func(id values.ConversationId) (models.ConversationModel, error) {
+			if id == conversation {
+				return models.ConversationModel{Id: id, FirstId: RandomId(), SecondId: RandomId()}, nil
+			}
+			panic("unexpected args")
+		}
+		AssertError(t, callSut(getConversation), client_errors.InsufficientPermissions)
+	})
+}
+
+// conversationOf returns a getter of the conversation between caller and someone else
+func conversationOf(conversation values.ConversationId, caller core_values.UserId) store.ConversationGetter {
+	return func(id values.ConversationId) (models.ConversationModel, error) {
+		if id == conversation {
+			return models.ConversationModel{Id: id, FirstId: RandomId(), SecondId: caller}, nil
+		}
+		panic("unexpected args")
+	}
+}
+
+func TestConversationStarter(t *testing.T) {
+	caller := RandomId()
+	interlocutor := RandomId()
+	getProfile := func(id, callerId core_values.UserId) (profile_entities.ContextedProfile, error) {
+		if id == interlocutor && callerId == caller {
+			return RandomContextedProfile(), nil
+		}
+		panic("unexpected args")
+	}
+	t.Run("happy case", func(t *testing.T) {
+		conversation := RandomId()
+		wantFirst, wantSecond := values.NewParticipants(caller, interlocutor)
+		createConversation := func(first, second core_values.UserId, createdAt time.Time) (values.ConversationId, error) {
+			if first == wantFirst && second == wantSecond && TimeAlmostNow(createdAt) {
+				return conversation, nil
+			}
+			panic("unexpected args")
+		}
+		got, err := service.NewConversationStarter(getProfile, createConversation)(caller, interlocutor)
+		AssertNoError(t, err)
+		Assert(t, got, conversation, "returned conversation id")
+	})
+	t.Run("error case - messaging yourself", func(t *testing.T) {
+		_, err := service.NewConversationStarter(nil, nil)(caller, caller)
+		AssertError(t, err, client_errors.MessagingYourself)
+	})
+	t.Run("error case - interlocutor is not found", func(t *testing.T) {
+		getProfile := func(core_values.UserId, core_values.UserId) (profile_entities.ContextedProfile, error) {
+			return profile_entities.ContextedProfile{}, client_errors.NotFound
+		}
+		_, err := service.NewConversationStarter(getProfile, nil)(caller, interlocutor)
+		AssertError(t, err, client_errors.NotFound)
+	})
+	t.Run("error case - store returns an error", func(t *testing.T) {
+		createConversation := func(core_values.UserId, core_values.UserId, time.Time) (values.ConversationId, error) {
+			return "", RandomError()
+		}
+		_, err := service.NewConversationStarter(getProfile, createConversation)(caller, interlocutor)
+		AssertSomeError(t, err)
+	})
+}
+
+func TestConversationsGetter(t *testing.T) {
+	caller := RandomId()
+	page := pagination.Page{Limit: 3}
+	interlocutor1 := RandomContextedProfile()
+	interlocutor2 := RandomContextedProfile()
+	conversations := []entities.Conversation{
+		{ConversationModel: models.ConversationModel{Id: RandomId(), FirstId: caller, SecondId: interlocutor1.Id, LastMessageAt: 3}},
+		{ConversationModel: models.ConversationModel{Id: RandomId(), FirstId: RandomId(), SecondId: caller, LastMessageAt: 2}},
+		{ConversationModel: models.ConversationModel{Id: RandomId(), FirstId: interlocutor2.Id, SecondId: caller, LastMessageAt: 1}},
+	}
+	getConversations := func(participant core_values.UserId, gotPage pagination.Page) ([]entities.Conversation, error) {
+		if participant == caller && gotPage == page {
+			return conversations, nil
+		}
+		panic("unexpected args")
+	}
+	t.Run("happy case", func(t *testing.T) {
+		getProfiles := func(ids []core_values.UserId, callerId core_values.UserId) (map[core_values.UserId]profile_entities.ContextedProfile, error) {
+			wantIds := []core_values.UserId{interlocutor1.Id, conversations[1].FirstId, interlocutor2.Id}
+			if reflect.DeepEqual(ids, wantIds) && callerId == caller {
+				// the interlocutor of the second conversation was deleted
+				return map[core_values.UserId]profile_entities.ContextedProfile{interlocutor1.Id: interlocutor1, interlocutor2.Id: interlocutor2}, nil
+			}
+			panic("unexpected args")
+		}
+		got, next, err := service.NewConversationsGetter(getConversations, getProfiles)(caller, page)
+		AssertNoError(t, err)
+		want := []entities.ContextedConversation{
+			{Conversation: conversations[0], Interlocutor: interlocutor1},
+			{Conversation: conversations[2], Interlocutor: interlocutor2},
+		}
+		Assert(t, got, want, "returned conversations")
+		Assert(t, next, conversations[2].Cursor(), "next cursor")
+	})
+	t.Run("no conversations", func(t *testing.T) {
+		getConversations := func(core_values.UserId, pagination.Page) ([]entities.Conversation, error) {
+			return []entities.Conversation{}, nil
+		}
+		got, next, err := service.NewConversationsGetter(getConversations, nil)(caller, page)
+		AssertNoError(t, err)
+		Assert(t, len(got), 0, "number of conversations")
+		Assert(t, next, pagination.Cursor{}, "next cursor")
+	})
+	t.Run("error case - store returns an error", func(t *testing.T) {
+		getConversations := func(core_values.UserId, pagination.Page) ([]entities.Conversation, error) {
+			return nil, RandomError()
+		}
+		_, _, err := service.NewConversationsGetter(getConversations, nil)(caller, page)
+		AssertSomeError(t, err)
+	})
+	t.Run("error case - getting profiles returns an error", func(t *testing.T) {
+		getProfiles := func([]core_values.UserId, core_values.UserId) (map[core_values.UserId]profile_entities.ContextedProfile, error) {
+			return nil, RandomError()
+		}
+		_, _, err := service.NewConversationsGetter(getConversations, getProfiles)(caller, page)
+		AssertSomeError(t, err)
+	})
+}
+
+func TestMessageSender(t *testing.T) {
+	newMessage := values.NewMessageData{Conversation: RandomId(), Sender: RandomId(), Text: RandomString()}
+	validate := func(message values.NewMessageData) (client_errors.ClientError, bool) {
+		if reflect.DeepEqual(message, newMessage) {
+			return client_errors.ClientError{}, true
+		}
+		panic("unexpected args")
+	}
+	getConversation := conversationOf(newMessage.Conversation, newMessage.Sender)
+	t.Run("happy case", func(t *testing.T) {
+		message := entities.Message{MessageModel: models.MessageModel{Id: RandomId(), Text: newMessage.Text}}
+		createMessage := func(gotMessage values.NewMessageData, createdAt time.Time) (entities.Message, error) {
+			if reflect.DeepEqual(gotMessage, newMessage) && TimeAlmostNow(createdAt) {
+				return message, nil
+			}
+			panic("unexpected args")
+		}
+		got, err := service.NewMessageSender(validate, getConversation, createMessage)(newMessage)
+		AssertNoError(t, err)
+		Assert(t, got, message, "returned message")
+	})
+	t.Run("error case - validation fails", func(t *testing.T) {
+		clientErr := RandomClientError()
+		validate := func(values.NewMessageData) (client_errors.ClientError, bool) {
+			return clientErr, false
+		}
+		_, err := service.NewMessageSender(validate, nil, nil)(newMessage)
+		AssertError(t, err, clientErr)
+	})
+	baseTestParticipantCheck(t, newMessage.Conversation, newMessage.Sender, func(getConversation store.ConversationGetter) error {
+		_, err := service.NewMessageSender(validate, getConversation, nil)(newMessage)
+		return err
+	})
+	t.Run("error case - store returns an error", func(t *testing.T) {
+		createMessage := func(values.NewMessageData, time.Time) (entities.Message, error) {
+			return entities.Message{}, RandomError()
+		}
+		_, err := service.NewMessageSender(validate, getConversation, createMessage)(newMessage)
+		AssertSomeError(t, err)
+	})
+}
+
+func TestMessagesGetter(t *testing.T) {
+	conversation := RandomId()
+	caller := RandomId()
+	page := pagination.Page{Limit: 2}
+	getConversation := conversationOf(conversation, caller)
+	t.Run("happy case", func(t *testing.T) {
+		messages := []entities.Message{
+			{MessageModel: models.MessageModel{Id: RandomId(), CreatedAt: 2}},
+			{MessageModel: models.MessageModel{Id: RandomId(), CreatedAt: 1}},
+		}
+		getMessages := func(gotConversation values.ConversationId, gotPage pagination.Page) ([]entities.Message, error) {
+			if gotConversation == conversation && gotPage == page {
+				return messages, nil
+			}
+			panic("unexpected args")
+		}
+		got, next, err := service.NewMessagesGetter(getConversation, getMessages)(conversation, caller, page)
+		AssertNoError(t, err)
+		Assert(t, got, messages, "returned messages")
+		Assert(t, next, messages[1].Cursor(), "next cursor")
+	})
+	baseTestParticipantCheck(t, conversation, caller, func(getConversation store.ConversationGetter) error {
+		_, _, err := service.NewMessagesGetter(getConversation, nil)(conversation, caller, page)
+		return err
+	})
+	t.Run("error case - store returns an error", func(t *testing.T) {
+		getMessages := func(values.ConversationId, pagination.Page) ([]entities.Message, error) {
+			return nil, RandomError()
+		}
+		_, _, err := service.NewMessagesGetter(getConversation, getMessages)(conversation, caller, page)
+		AssertSomeError(t, err)
+	})
+}
+
+func TestMessageImageGetter(t *testing.T) {
+	conversation := RandomId()
+	message := RandomId()
+	index := RandomInt()
+	caller := RandomId()
+	getConversation := conversationOf(conversation, caller)
+	t.Run("happy case", func(t *testing.T) {
+		path := RandomString()
+		getImage := func(gotConversation values.ConversationId, gotMessage values.MessageId, gotIndex int) (core_values.StaticPath, error) {
+			if gotConversation == conversation && gotMessage == message && gotIndex == index {
+				return path, nil
+			}
+			panic("unexpected args")
+		}
+		got, err := service.NewMessageImageGetter(getConversation, getImage)(conversation, message, index, caller)
+		AssertNoError(t, err)
+		Assert(t, got, path, "returned path")
+	})
+	baseTestParticipantCheck(t, conversation, caller, func(getConversation store.ConversationGetter) error {
+		_, err := service.NewMessageImageGetter(getConversation, nil)(conversation, message, index, caller)
+		return err
+	})
+	t.Run("error case - image is not found", func(t *testing.T) {
+		getImage := func(values.ConversationId, values.MessageId, int) (core_values.StaticPath, error) {
+			return "", core_err.ErrNotFound
+		}
+		_, err := service.NewMessageImageGetter(getConversation, getImage)(conversation, message, index, caller)
+		AssertError(t, err, client_errors.NotFound)
+	})
+	t.Run("error case - store returns an error", func(t *testing.T) {
+		getImage := func(values.ConversationId, values.MessageId, int) (core_values.StaticPath, error) {
+			return "", RandomError()
+		}
+		_, err := service.NewMessageImageGetter(getConversation, getImage)(conversation, message, index, caller)
+		AssertSomeError(t, err)
+	})
+}
+
+func TestConversationReader(t *testing.T) {
+	conversation := RandomId()
+	caller := RandomId()
+	getConversation := conversationOf(conversation, caller)
+	t.Run("happy case", func(t *testing.T) {
+		read := func(gotConversation values.ConversationId, reader core_values.UserId, readAt time.Time) error {
+			if gotConversation == conversation && reader == caller && TimeAlmostNow(readAt) {
+				return nil
+			}
+			panic("unexpected args")
+		}
+		err := service.NewConversationReader(getConversation, read)(conversation, caller)
+		AssertNoError(t, err)
+	})
+	baseTestParticipantCheck(t, conversation, caller, func(getConversation store.ConversationGetter) error {
+		return service.NewConversationReader(getConversation, nil)(conversation, caller)
+	})
+	t.Run("error case - store returns an error", func(t *testing.T) {
+		read := func(values.ConversationId, core_values.UserId, time.Time) error {
+			return RandomError()
+		}
+		err := service.NewConversationReader(getConversation, read)(conversation, caller)
+		AssertSomeError(t, err)
+	})
+}
+
+func TestUnreadCountGetter(t *testing.T) {
+	caller := RandomId()
+	t.Run("happy case", func(t *testing.T) {
+		count := RandomInt()
+		getCount := func(participant core_values.UserId) (int, error) {
+			if participant == caller {
+				return count, nil
+			}
+			panic("unexpected args")
+		}
+		got, err := service.NewUnreadCountGetter(getCount)(caller)
+		AssertNoError(t, err)
+		Assert(t, got, count, "returned count")
+	})
+	t.Run("error case - store returns an error", func(t *testing.T) {
+		getCount := func(core_values.UserId) (int, error) {
+			return 0, RandomError()
+		}
+		_, err := service.NewUnreadCountGetter(getCount)(caller)
+		AssertSomeError(t, err)
+	})
+}
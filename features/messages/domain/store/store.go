@@ -0,0 +1,29 @@
+package store
+
+import (
+	"github.com/k0marov/go-socnet/core/general/core_values"
+	"github.com/k0marov/go-socnet/core/general/pagination"
+	"time"
+
+	"github.com/k0marov/go-socnet/features/messages/domain/entities"
+	"github.com/k0marov/go-socnet/features/messages/domain/models"
+	"github.com/k0marov/go-socnet/features/messages/domain/values"
+)
+
+type (
+	// ConversationGetter returns core_err.ErrNotFound if the conversation doesn't exist. The Unread field is not filled in.
+	ConversationGetter func(id values.ConversationId) (models.ConversationModel, error)
+	// ConversationCreator returns the id of the conversation between the participants, creating it if there is none
+	ConversationCreator func(first, second core_values.UserId, createdAt time.Time) (values.ConversationId, error)
+	// ConversationsGetter returns the conversations of participant which have messages, the one with the latest message first
+	ConversationsGetter func(participant core_values.UserId, page pagination.Page) ([]entities.Conversation, error)
+	MessageCreator      func(newMessage values.NewMessageData, createdAt time.Time) (entities.Message, error)
+	// MessageImageGetter returns the path of an image of a message in the private dir,
+	// or core_err.ErrNotFound if the message isn't in the conversation or has no image with this index
+	MessageImageGetter func(conversation values.ConversationId, message values.MessageId, index int) (core_values.StaticPath, error)
+	// MessagesGetter returns the messages of a conversation, newest first
+	MessagesGetter func(conversation values.ConversationId, page pagination.Page) ([]entities.Message, error)
+	// ConversationReader marks all messages which were sent to reader in the conversation as read
+	ConversationReader func(conversation values.ConversationId, reader core_values.UserId, readAt time.Time) error
+	UnreadCountGetter  func(participant core_values.UserId) (int, error)
+)
@@ -0,0 +1,27 @@
+package validators
+
+import (
+	"github.com/k0marov/go-socnet/core/general/client_errors"
+	"github.com/k0marov/go-socnet/core/general/image_decoder"
+	"github.com/k0marov/go-socnet/features/messages/domain/values"
+)
+
+type MessageValidator func(newMessage values.NewMessageData) (client_errors.ClientError, bool)
+
+func NewMessageValidator(maxTextLength int, decodeImg image_decoder.ImageDecoder) MessageValidator {
+	return func(newMessage values.NewMessageData) (client_errors.ClientError, bool) {
+		if newMessage.Text == "" && len(newMessage.Images) == 0 {
+			return client_errors.EmptyMessage, false
+		}
+		if len(newMessage.Text) > maxTextLength {
+			return client_errors.TextTooLong, false
+		}
+		for _, image := range newMessage.Images {
+			_, err := decodeImg(image.File.Value())
+			if err != nil {
+				return client_errors.InvalidImage, false
+			}
+		}
+		return client_errors.ClientError{}, true
+	}
+}
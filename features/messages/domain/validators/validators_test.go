@@ -0,0 +1,67 @@
+package validators_test
+
+import (
+	"github.com/k0marov/go-socnet/core/general/client_errors"
+	"github.com/k0marov/go-socnet/core/general/image_decoder"
+	. "github.com/k0marov/go-socnet/core/helpers/test_helpers"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/k0marov/go-socnet/features/messages/domain/validators"
+	"github.com/k0marov/go-socnet/features/messages/domain/values"
+)
+
+func TestMessageValidator(t *testing.T) {
+	const maxTextLength = 100
+	validImages := func([]byte) (image_decoder.Image, error) {
+		return image_decoder.Image{Height: 123, Width: 345}, nil
+	}
+	images := []values.MessageImageFile{{File: RandomFileData(), Index: 1}, {File: RandomFileData(), Index: 2}}
+	cases := []struct {
+		name        string
+		text        string
+		images      []values.MessageImageFile
+		expectedErr client_errors.ClientError
+	}{
+		{"only text", "some short text", nil, client_errors.ClientError{}},
+		{"only images", "", images, client_errors.ClientError{}},
+		{"text of max length", strings.Repeat("a", maxTextLength), images, client_errors.ClientError{}},
+		{"neither text nor images", "", nil, client_errors.EmptyMessage},
+		{"too long text", strings.Repeat("a", maxTextLength+1), nil, client_errors.TextTooLong},
+	}
+	for _, testCase := range cases {
+		t.Run(testCase.name, func(t *testing.T) {
+			newMessage := values.NewMessageData{Sender: RandomId(), Text: testCase.text, Images: testCase.images}
+			gotErr, ok := validators.NewMessageValidator(maxTextLength, validImages)(newMessage)
+			AssertError(t, gotErr, testCase.expectedErr)
+			Assert(t, ok, testCase.expectedErr == client_errors.ClientError{}, "returned 'ok' value")
+		})
+	}
+	t.Run("images validation", func(t *testing.T) {
+		newMessage := values.NewMessageData{Sender: RandomId(), Images: images}
+		t.Run("happy case", func(t *testing.T) {
+			imagesChecked := 0
+			decoder := func(image []byte) (image_decoder.Image, error) {
+				for _, messageImage := range images {
+					if reflect.DeepEqual(messageImage.File.Value(), image) {
+						imagesChecked++
+						return image_decoder.Image{Height: 420, Width: 840}, nil
+					}
+				}
+				panic("unexpected args")
+			}
+			_, ok := validators.NewMessageValidator(maxTextLength, decoder)(newMessage)
+			Assert(t, ok, true, "ok is true")
+			Assert(t, imagesChecked, len(images), "amount of checked images")
+		})
+		t.Run("error case", func(t *testing.T) {
+			decoder := func([]byte) (image_decoder.Image, error) {
+				return image_decoder.Image{}, RandomError()
+			}
+			clientErr, ok := validators.NewMessageValidator(maxTextLength, decoder)(newMessage)
+			Assert(t, ok, false, "ok is false")
+			AssertError(t, clientErr, client_errors.InvalidImage)
+		})
+	})
+}
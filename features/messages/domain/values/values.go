@@ -0,0 +1,36 @@
+package values
+
+import "github.com/k0marov/go-socnet/core/general/core_values"
+
+type ConversationId = string
+type MessageId = string
+
+type NewMessageData struct {
+	Conversation ConversationId
+	Sender       core_values.UserId
+	Text         string
+	Images       []MessageImageFile
+}
+
+type MessageImageFile struct {
+	File  core_values.FileData
+	Index int
+}
+
+type MessageImage struct {
+	URL   core_values.FileURL
+	Index int
+}
+
+// ImageURLBuilder returns the URL of the handler serving an image of a message.
+// The images are private, so they are served only to the participants of the conversation and not from the static host.
+type ImageURLBuilder = func(conversation ConversationId, message MessageId, index int) core_values.FileURL
+
+// NewParticipants orders the two participants of a conversation,
+// so that the same pair of profiles always refers to the same conversation
+func NewParticipants(profile1, profile2 core_values.UserId) (first, second core_values.UserId) {
+	if profile1 < profile2 {
+		return profile1, profile2
+	}
+	return profile2, profile1
+}
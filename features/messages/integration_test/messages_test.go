@@ -0,0 +1,198 @@
+package integration_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/k0marov/go-socnet/core/general/client_errors"
+	helpers "github.com/k0marov/go-socnet/core/helpers/http_test_helpers"
+	. "github.com/k0marov/go-socnet/core/helpers/test_helpers"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/k0marov/go-socnet/features/messages"
+	"github.com/k0marov/go-socnet/features/messages/delivery/http/responses"
+	"github.com/k0marov/go-socnet/features/profiles"
+	auth "github.com/k0marov/golang-auth"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestMessages(t *testing.T) {
+	// db
+	cfg := TestConfig(t)
+	sql := OpenTestDB(t)
+	r := chi.NewRouter()
+	// profiles
+	fakeRegisterProfile := profiles.NewRegisterCallback(sql)
+	getProfile := profiles.NewProfileGetterImpl(cfg, sql)
+	getProfiles := profiles.NewProfilesGetterImpl(cfg, sql)
+	// messages
+	r.Route("/conversations", messages.NewConversationsRouterImpl(cfg, sql, "/conversations", getProfile, getProfiles))
+
+	image, err := os.ReadFile(filepath.Join("testdata", "test_image.jpg"))
+	AssertNoError(t, err)
+	serve := func(t testing.TB, request *http.Request, caller auth.User) *httptest.ResponseRecorder {
+		t.Helper()
+		response := httptest.NewRecorder()
+		r.ServeHTTP(response, helpers.AddAuthDataToRequest(request, caller))
+		return response
+	}
+	startConversation := func(t testing.TB, caller, interlocutor auth.User) string {
+		t.Helper()
+		response := serve(t, httptest.NewRequest(http.MethodPost, "/conversations/?profile_id="+interlocutor.Id, nil), caller)
+		AssertStatusCode(t, response, http.StatusOK)
+		var started responses.StartedConversationResponse
+		json.NewDecoder(response.Body).Decode(&started)
+		return started.Id
+	}
+	sendMessage := func(t testing.TB, conversation string, text string, images [][]byte, caller auth.User) *httptest.ResponseRecorder {
+		t.Helper()
+		body := bytes.NewBuffer(nil)
+		writer := multipart.NewWriter(body)
+		writer.WriteField("text", text)
+		for i, image := range images {
+			fw, _ := writer.CreateFormFile("image_"+strconv.Itoa(i+1), "image.jpg")
+			fw.Write(image)
+		}
+		writer.Close()
+		request := httptest.NewRequest(http.MethodPost, "/conversations/"+conversation+"/messages", body)
+		request.Header.Set("Content-Type", writer.FormDataContentType())
+		return serve(t, request, caller)
+	}
+	getMessages := func(t testing.TB, conversation string, caller auth.User) responses.MessagesResponse {
+		t.Helper()
+		response := serve(t, httptest.NewRequest(http.MethodGet, "/conversations/"+conversation+"/messages", nil), caller)
+		AssertStatusCode(t, response, http.StatusOK)
+		var messages responses.MessagesResponse
+		json.NewDecoder(response.Body).Decode(&messages)
+		return messages
+	}
+	getConversations := func(t testing.TB, caller auth.User) []responses.ConversationResponse {
+		t.Helper()
+		response := serve(t, httptest.NewRequest(http.MethodGet, "/conversations/", nil), caller)
+		AssertStatusCode(t, response, http.StatusOK)
+		var conversations responses.ConversationsResponse
+		json.NewDecoder(response.Body).Decode(&conversations)
+		return conversations.Conversations
+	}
+	getUnreadCount := func(t testing.TB, caller auth.User) int {
+		t.Helper()
+		response := serve(t, httptest.NewRequest(http.MethodGet, "/conversations/unread-count", nil), caller)
+		AssertStatusCode(t, response, http.StatusOK)
+		var count responses.UnreadCountResponse
+		json.NewDecoder(response.Body).Decode(&count)
+		return count.Count
+	}
+
+	alice := RandomAuthUser()
+	bob := RandomAuthUser()
+	eve := RandomAuthUser()
+	fakeRegisterProfile(alice)
+	fakeRegisterProfile(bob)
+	fakeRegisterProfile(eve)
+
+	conversation := startConversation(t, alice, bob)
+	Assert(t, startConversation(t, bob, alice), conversation, "conversation started by the other participant")
+
+	t.Run("sending messages", func(t *testing.T) {
+		response := sendMessage(t, conversation, "hi bob", nil, alice)
+		AssertStatusCode(t, response, http.StatusOK)
+		response = sendMessage(t, conversation, "look at this", [][]byte{image}, alice)
+		AssertStatusCode(t, response, http.StatusOK)
+		var sent responses.MessageResponse
+		json.NewDecoder(response.Body).Decode(&sent)
+		AssertFatal(t, len(sent.Images), 1, "number of images of the sent message")
+		imageURL := sent.Images[0].Url
+
+		// the image is served only to the participants
+		response = serve(t, httptest.NewRequest(http.MethodGet, imageURL, nil), bob)
+		AssertStatusCode(t, response, http.StatusOK)
+		Assert(t, response.Body.Bytes(), image, "the served image")
+		AssertClientError(t, serve(t, httptest.NewRequest(http.MethodGet, imageURL, nil), eve), client_errors.InsufficientPermissions)
+		AssertClientError(t, serve(t, httptest.NewRequest(http.MethodGet, strings.TrimSuffix(imageURL, "1")+"2", nil), bob), client_errors.NotFound)
+		// and it isn't stored in the public static dir
+		staticFiles, err := os.ReadDir(cfg.Static.Dir)
+		AssertNoError(t, err)
+		Assert(t, len(staticFiles), 0, "number of files in the static dir")
+
+		AssertClientError(t, sendMessage(t, conversation, "", nil, alice), client_errors.EmptyMessage)
+		AssertClientError(t, sendMessage(t, conversation, "hi", [][]byte{[]byte("not an image")}, alice), client_errors.InvalidImage)
+	})
+	t.Run("only participants can access a conversation", func(t *testing.T) {
+		AssertClientError(t, sendMessage(t, conversation, "hi", nil, eve), client_errors.InsufficientPermissions)
+		response := serve(t, httptest.NewRequest(http.MethodGet, "/conversations/"+conversation+"/messages", nil), eve)
+		AssertClientError(t, response, client_errors.InsufficientPermissions)
+		response = serve(t, httptest.NewRequest(http.MethodPost, "/conversations/"+conversation+"/read", nil), eve)
+		AssertClientError(t, response, client_errors.InsufficientPermissions)
+		Assert(t, len(getConversations(t, eve)), 0, "number of conversations of a non-participant")
+
+		response = serve(t, httptest.NewRequest(http.MethodGet, "/conversations/424242/messages", nil), eve)
+		AssertClientError(t, response, client_errors.NotFound)
+	})
+	t.Run("starting a conversation with yourself or a nonexistent profile", func(t *testing.T) {
+		response := serve(t, httptest.NewRequest(http.MethodPost, "/conversations/?profile_id="+alice.Id, nil), alice)
+		AssertClientError(t, response, client_errors.MessagingYourself)
+		response = serve(t, httptest.NewRequest(http.MethodPost, "/conversations/?profile_id=424242", nil), alice)
+		AssertClientError(t, response, client_errors.NotFound)
+	})
+	t.Run("listing conversations and reading them", func(t *testing.T) {
+		// a conversation without messages isn't listed, the one with the latest message is first
+		startConversation(t, bob, eve)
+		evesConversation := startConversation(t, eve, bob)
+		conversations := getConversations(t, bob)
+		AssertFatal(t, len(conversations), 1, "number of conversations")
+		AssertStatusCode(t, sendMessage(t, evesConversation, "hello from eve", nil, eve), http.StatusOK)
+
+		conversations = getConversations(t, bob)
+		AssertFatal(t, len(conversations), 2, "number of conversations")
+		Assert(t, conversations[0].Id, evesConversation, "the conversation with the latest message")
+		Assert(t, conversations[0].Interlocutor.Id, eve.Id, "interlocutor")
+		Assert(t, conversations[0].LastMessage.Text, "hello from eve", "last message")
+		Assert(t, conversations[1].Id, conversation, "the second conversation")
+		Assert(t, conversations[1].Interlocutor.Id, alice.Id, "interlocutor")
+		Assert(t, conversations[1].LastMessage.Text, "look at this", "last message")
+		Assert(t, conversations[1].Unread, 2, "number of unread messages")
+		Assert(t, getUnreadCount(t, bob), 3, "unread count of bob")
+		Assert(t, getUnreadCount(t, alice), 0, "unread count of alice")
+
+		response := serve(t, httptest.NewRequest(http.MethodPost, "/conversations/"+conversation+"/read", nil), bob)
+		AssertStatusCode(t, response, http.StatusOK)
+		Assert(t, getUnreadCount(t, bob), 1, "unread count of bob after reading the conversation with alice")
+
+		// the sender sees that the messages were read
+		messages := getMessages(t, conversation, alice).Messages
+		AssertFatal(t, len(messages), 2, "number of messages")
+		Assert(t, messages[0].Text, "look at this", "the latest message")
+		for _, message := range messages {
+			Assert(t, message.SenderId, alice.Id, "sender")
+			Assert(t, TimeAlmostNow(time.Unix(message.ReadAt, 0)), true, "message is read now")
+		}
+	})
+	t.Run("paginating message history", func(t *testing.T) {
+		for i := 0; i < 3; i++ {
+			AssertStatusCode(t, sendMessage(t, conversation, RandomString(), nil, bob), http.StatusOK)
+		}
+		var all []responses.MessageResponse
+		cursor := ""
+		for {
+			response := serve(t, httptest.NewRequest(http.MethodGet, "/conversations/"+conversation+"/messages?limit=2&cursor="+cursor, nil), alice)
+			AssertStatusCode(t, response, http.StatusOK)
+			var page responses.MessagesResponse
+			json.NewDecoder(response.Body).Decode(&page)
+			all = append(all, page.Messages...)
+			if page.NextCursor == "" {
+				break
+			}
+			cursor = page.NextCursor
+		}
+		Assert(t, len(all), 5, "number of messages in all pages")
+		Assert(t, all[4].Text, "hi bob", "the first message")
+	})
+}
@@ -0,0 +1,61 @@
+package messages
+
+import (
+	"fmt"
+	"github.com/go-chi/chi/v5"
+	"github.com/jmoiron/sqlx"
+	"github.com/k0marov/go-socnet/core/general/config"
+	"github.com/k0marov/go-socnet/core/general/core_values"
+	"github.com/k0marov/go-socnet/core/general/image_decoder"
+	"github.com/k0marov/go-socnet/core/general/unit_of_work"
+	"log"
+
+	"github.com/k0marov/go-socnet/features/messages/delivery/http/handlers"
+	"github.com/k0marov/go-socnet/features/messages/delivery/http/router"
+	"github.com/k0marov/go-socnet/features/messages/domain/service"
+	"github.com/k0marov/go-socnet/features/messages/domain/validators"
+	"github.com/k0marov/go-socnet/features/messages/domain/values"
+	"github.com/k0marov/go-socnet/features/messages/store"
+	"github.com/k0marov/go-socnet/features/messages/store/file_storage"
+	"github.com/k0marov/go-socnet/features/messages/store/sql_db"
+	profile_service "github.com/k0marov/go-socnet/features/profiles/domain/service"
+)
+
+// NewConversationsRouterImpl stores the images of messages in the private dir and serves them only to the participants.
+// basePath is the path at which the router is mounted, the URLs of the images start with it.
+func NewConversationsRouterImpl(cfg config.Config, db *sqlx.DB, basePath string, getProfile profile_service.ProfileGetter, getProfiles profile_service.ProfilesGetter) func(chi.Router) {
+	// db
+	sqlDB, err := sql_db.NewSqlDB(db)
+	if err != nil {
+		log.Fatalf("error while opening sql db for messages: %v", err)
+	}
+
+	// store
+	imageURL := func(conversation values.ConversationId, message values.MessageId, index int) core_values.FileURL {
+		return fmt.Sprintf("%s/%s/messages/%s/images/%d", basePath, conversation, message, index)
+	}
+	storeCreateMessage := store.NewStoreMessageCreator(unit_of_work.NewRunnerImpl(db, cfg.Static.PrivateDir), sqlDB.CreateMessage, file_storage.NewMessageImageFilesCreator(), sqlDB.AddMessageImages, imageURL)
+	storeGetMessages := store.NewStoreMessagesGetter(sqlDB.GetMessages, imageURL)
+	storeGetConversations := store.NewStoreConversationsGetter(sqlDB.GetConversations, sqlDB.GetLastMessages, imageURL)
+
+	// service
+	validate := validators.NewMessageValidator(cfg.Limits.MaxMessageTextLength, image_decoder.ImageDecoderImpl)
+	startConversation := service.NewConversationStarter(getProfile, sqlDB.CreateConversation)
+	getConversations := service.NewConversationsGetter(storeGetConversations, getProfiles)
+	getUnreadCount := service.NewUnreadCountGetter(sqlDB.GetUnreadCount)
+	sendMessage := service.NewMessageSender(validate, sqlDB.GetConversation, storeCreateMessage)
+	getMessages := service.NewMessagesGetter(sqlDB.GetConversation, storeGetMessages)
+	getMessageImage := service.NewMessageImageGetter(sqlDB.GetConversation, sqlDB.GetMessageImage)
+	readConversation := service.NewConversationReader(sqlDB.GetConversation, sqlDB.MarkConversationRead)
+
+	// handlers
+	startConversationHandler := handlers.NewStartConversationHandler(startConversation)
+	getConversationsHandler := handlers.NewGetConversationsHandler(getConversations)
+	getUnreadCountHandler := handlers.NewGetUnreadCountHandler(getUnreadCount)
+	sendMessageHandler := handlers.NewSendMessageHandler(sendMessage)
+	getMessagesHandler := handlers.NewGetMessagesHandler(getMessages)
+	getMessageImageHandler := handlers.NewGetMessageImageHandler(getMessageImage, cfg.Static.PrivateDir)
+	readConversationHandler := handlers.NewReadConversationHandler(readConversation)
+
+	return router.NewConversationsRouter(startConversationHandler, getConversationsHandler, getUnreadCountHandler, sendMessageHandler, getMessagesHandler, getMessageImageHandler, readConversationHandler)
+}
@@ -0,0 +1,32 @@
+package file_storage
+
+import (
+	"github.com/k0marov/go-socnet/core/general/core_err"
+	"github.com/k0marov/go-socnet/core/general/core_values"
+	"github.com/k0marov/go-socnet/core/general/static_store"
+	"strconv"
+
+	"github.com/k0marov/go-socnet/features/messages/domain/values"
+)
+
+const MessagePrefix = "message_"
+const ImagePrefix = "image_"
+
+// MessageImageFilesCreator stores the images of a message using the provided file creator, e.g. the one of a unit of work
+type MessageImageFilesCreator = func(static_store.StaticFileCreator, values.MessageId, []values.MessageImageFile) ([]core_values.StaticPath, error)
+
+// NewMessageImageFilesCreator stores the images in the directory of the message.
+// The file creator should write into the private dir, since only the participants of the conversation may see the images.
+func NewMessageImageFilesCreator() MessageImageFilesCreator {
+	return func(createFile static_store.StaticFileCreator, message values.MessageId, images []values.MessageImageFile) (paths []core_values.StaticPath, err error) {
+		dir := MessagePrefix + message
+		for _, image := range images {
+			path, err := createFile(image.File, dir, ImagePrefix+strconv.Itoa(image.Index))
+			if err != nil {
+				return paths, core_err.Rethrow("storing a file", err)
+			}
+			paths = append(paths, path)
+		}
+		return
+	}
+}
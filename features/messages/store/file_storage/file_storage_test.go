@@ -0,0 +1,41 @@
+package file_storage_test
+
+import (
+	"github.com/k0marov/go-socnet/core/general/core_values"
+	. "github.com/k0marov/go-socnet/core/helpers/test_helpers"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/k0marov/go-socnet/features/messages/domain/values"
+	"github.com/k0marov/go-socnet/features/messages/store/file_storage"
+)
+
+func TestMessageImageFilesCreator(t *testing.T) {
+	message := RandomId()
+	images := []values.MessageImageFile{{File: RandomFileData(), Index: 1}, {File: RandomFileData(), Index: 2}}
+	paths := []core_values.StaticPath{RandomString(), RandomString()}
+	t.Run("happy case", func(t *testing.T) {
+		wantDir := file_storage.MessagePrefix + message
+		filesStored := 0
+		createFile := func(file core_values.FileData, dir string, filename string) (core_values.StaticPath, error) {
+			wantFilename := file_storage.ImagePrefix + strconv.Itoa(filesStored+1)
+			if filename == wantFilename && dir == wantDir && reflect.DeepEqual(file, images[filesStored].File) {
+				filesStored++
+				return paths[filesStored-1], nil
+			}
+			panic("unexpected args")
+		}
+		gotPaths, err := file_storage.NewMessageImageFilesCreator()(createFile, message, images)
+		AssertNoError(t, err)
+		Assert(t, gotPaths, paths, "returned paths")
+		Assert(t, filesStored, len(images), "number of stored files")
+	})
+	t.Run("error case", func(t *testing.T) {
+		createFile := func(core_values.FileData, string, string) (core_values.StaticPath, error) {
+			return "", RandomError()
+		}
+		_, err := file_storage.NewMessageImageFilesCreator()(createFile, message, images)
+		AssertSomeError(t, err)
+	})
+}
@@ -0,0 +1,241 @@
+package sql_db
+
+import (
+	"database/sql"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"github.com/k0marov/go-socnet/core/general/core_err"
+	"github.com/k0marov/go-socnet/core/general/core_values"
+	"github.com/k0marov/go-socnet/core/general/pagination"
+	"github.com/k0marov/go-socnet/core/general/unit_of_work"
+	"time"
+
+	"github.com/k0marov/go-socnet/features/messages/domain/models"
+	"github.com/k0marov/go-socnet/features/messages/domain/values"
+)
+
+type SqlDB struct {
+	sql *sqlx.DB
+}
+
+// NewSqlDB expects the schema to be already migrated with core/general/migrations
+func NewSqlDB(db *sqlx.DB) (*SqlDB, error) {
+	return &SqlDB{sql: db}, nil
+}
+
+const conversationColumns = `id, firstProfile_id, secondProfile_id, createdAt, lastMessageAt`
+
+const messageColumns = `id, conversation_id, sender_id, textContent, createdAt, readAt`
+
+func (db *SqlDB) GetConversation(id values.ConversationId) (models.ConversationModel, error) {
+	var conversation models.ConversationModel
+	err := db.sql.Get(&conversation, db.sql.Rebind(`
+		SELECT `+conversationColumns+` FROM Conversation WHERE id = ?
+	`), id)
+	if err == sql.ErrNoRows {
+		return models.ConversationModel{}, core_err.ErrNotFound
+	}
+	if err != nil {
+		return models.ConversationModel{}, core_err.Rethrow("SELECTing a conversation", err)
+	}
+	return conversation, nil
+}
+
+// CreateConversation expects the participants to be ordered by values.NewParticipants
+func (db *SqlDB) CreateConversation(first, second core_values.UserId, createdAt time.Time) (values.ConversationId, error) {
+	_, err := db.sql.Exec(db.sql.Rebind(`
+		INSERT INTO Conversation(firstProfile_id, secondProfile_id, createdAt) VALUES (?, ?, ?)
+		ON CONFLICT DO NOTHING
+	`), first, second, createdAt.Unix())
+	if err != nil {
+		return "", core_err.Rethrow("inserting a conversation", err)
+	}
+	var id int64
+	err = db.sql.Get(&id, db.sql.Rebind(`
+		SELECT id FROM Conversation WHERE firstProfile_id = ? AND secondProfile_id = ?
+	`), first, second)
+	if err != nil {
+		return "", core_err.Rethrow("SELECTing the id of a conversation", err)
+	}
+	return fmt.Sprintf("%d", id), nil
+}
+
+// GetConversations returns the conversations of participant which have messages, the one with the latest message first
+func (db *SqlDB) GetConversations(participant core_values.UserId, page pagination.Page) ([]models.ConversationModel, error) {
+	cond, condArgs := page.Condition("lastMessageAt", "id")
+	args := append([]any{participant, participant, participant}, condArgs...)
+	conversations := []models.ConversationModel{}
+	err := db.sql.Select(&conversations, db.sql.Rebind(`
+		SELECT `+conversationColumns+`,
+			(SELECT COUNT(*) FROM Message WHERE conversation_id = Conversation.id AND sender_id != ? AND readAt = 0) AS unread
+		FROM Conversation
+		WHERE (firstProfile_id = ? OR secondProfile_id = ?) AND lastMessageAt != 0 AND `+cond+`
+		ORDER BY lastMessageAt DESC, id DESC
+		LIMIT ?
+	`), append(args, page.Limit)...)
+	if err != nil {
+		return []models.ConversationModel{}, core_err.Rethrow("SELECTing conversations", err)
+	}
+	return conversations, nil
+}
+
+// CreateMessage also moves the conversation of the message to the top of the conversations list
+func (db *SqlDB) CreateMessage(ex unit_of_work.Executor, newMessage models.MessageToCreate) (values.MessageId, error) {
+	var id int64
+	err := ex.QueryRowx(ex.Rebind(`
+		INSERT INTO Message(conversation_id, sender_id, textContent, createdAt) VALUES (?, ?, ?, ?) RETURNING id
+	`), newMessage.Conversation, newMessage.Sender, newMessage.Text, newMessage.CreatedAt.Unix()).Scan(&id)
+	if err != nil {
+		return "", core_err.Rethrow("inserting a message", err)
+	}
+	_, err = ex.Exec(ex.Rebind(`
+		UPDATE Conversation SET lastMessageAt = ? WHERE id = ?
+	`), newMessage.CreatedAt.Unix(), newMessage.Conversation)
+	if err != nil {
+		return "", core_err.Rethrow("updating the time of the last message of a conversation", err)
+	}
+	return fmt.Sprintf("%d", id), nil
+}
+
+func (db *SqlDB) AddMessageImages(ex unit_of_work.Executor, message values.MessageId, images []models.MessageImageModel) error {
+	for _, image := range images {
+		_, err := ex.Exec(ex.Rebind(`
+			INSERT INTO MessageImage(message_id, path, ind) VALUES (?, ?, ?)
+		`), message, image.Path, image.Index)
+		if err != nil {
+			return core_err.Rethrow("inserting a message image", err)
+		}
+	}
+	return nil
+}
+
+// GetMessageImage returns the path of an image of a message in the conversation or core_err.ErrNotFound
+func (db *SqlDB) GetMessageImage(conversation values.ConversationId, message values.MessageId, index int) (core_values.StaticPath, error) {
+	var path core_values.StaticPath
+	err := db.sql.Get(&path, db.sql.Rebind(`
+		SELECT path FROM MessageImage
+		JOIN Message ON Message.id = MessageImage.message_id
+		WHERE Message.conversation_id = ? AND MessageImage.message_id = ? AND MessageImage.ind = ?
+	`), conversation, message, index)
+	if err == sql.ErrNoRows {
+		return "", core_err.ErrNotFound
+	}
+	if err != nil {
+		return "", core_err.Rethrow("SELECTing a message image", err)
+	}
+	return path, nil
+}
+
+// GetMessages returns the messages of a conversation, newest first
+func (db *SqlDB) GetMessages(conversation values.ConversationId, page pagination.Page) ([]models.MessageModel, error) {
+	cond, condArgs := page.Condition("createdAt", "id")
+	args := append([]any{conversation}, condArgs...)
+	messages := []models.MessageModel{}
+	err := db.sql.Select(&messages, db.sql.Rebind(`
+		SELECT `+messageColumns+`
+		FROM Message
+		WHERE conversation_id = ? AND `+cond+`
+		ORDER BY createdAt DESC, id DESC
+		LIMIT ?
+	`), append(args, page.Limit)...)
+	if err != nil {
+		return []models.MessageModel{}, core_err.Rethrow("SELECTing messages", err)
+	}
+	err = db.addImages(messages)
+	if err != nil {
+		return []models.MessageModel{}, err
+	}
+	return messages, nil
+}
+
+// GetLastMessages returns the latest message of each conversation, the conversations without messages are missing from the map
+func (db *SqlDB) GetLastMessages(conversations []values.ConversationId) (map[values.ConversationId]models.MessageModel, error) {
+	last := map[values.ConversationId]models.MessageModel{}
+	if len(conversations) == 0 {
+		return last, nil
+	}
+	query, args, err := sqlx.In(`
+		SELECT `+messageColumns+`
+		FROM (
+			SELECT `+messageColumns+`,
+				ROW_NUMBER() OVER (PARTITION BY conversation_id ORDER BY createdAt DESC, id DESC) AS position
+			FROM Message
+			WHERE conversation_id IN (?)
+		) AS Latest
+		WHERE position = 1
+	`, conversations)
+	if err != nil {
+		return nil, core_err.Rethrow("building the query for last messages", err)
+	}
+	var messages []models.MessageModel
+	err = db.sql.Select(&messages, db.sql.Rebind(query), args...)
+	if err != nil {
+		return nil, core_err.Rethrow("SELECTing last messages of conversations", err)
+	}
+	err = db.addImages(messages)
+	if err != nil {
+		return nil, err
+	}
+	for _, message := range messages {
+		last[message.ConversationId] = message
+	}
+	return last, nil
+}
+
+// MarkConversationRead keeps the readAt of the messages which are already read
+func (db *SqlDB) MarkConversationRead(conversation values.ConversationId, reader core_values.UserId, readAt time.Time) error {
+	_, err := db.sql.Exec(db.sql.Rebind(`
+		UPDATE Message SET readAt = ? WHERE conversation_id = ? AND sender_id != ? AND readAt = 0
+	`), readAt.Unix(), conversation, reader)
+	if err != nil {
+		return core_err.Rethrow("marking the messages of a conversation as read", err)
+	}
+	return nil
+}
+
+// GetUnreadCount returns the number of unread messages sent to participant in all of their conversations
+func (db *SqlDB) GetUnreadCount(participant core_values.UserId) (int, error) {
+	var count int
+	err := db.sql.Get(&count, db.sql.Rebind(`
+		SELECT COUNT(*) FROM Message
+		JOIN Conversation ON Conversation.id = Message.conversation_id
+		WHERE (firstProfile_id = ? OR secondProfile_id = ?) AND sender_id != ? AND readAt = 0
+	`), participant, participant, participant)
+	if err != nil {
+		return 0, core_err.Rethrow("counting unread messages", err)
+	}
+	return count, nil
+}
+
+// addImages fills in the images of all messages with a single query
+func (db *SqlDB) addImages(messages []models.MessageModel) error {
+	if len(messages) == 0 {
+		return nil
+	}
+	ids := make([]values.MessageId, len(messages))
+	for i, message := range messages {
+		ids[i] = message.Id
+	}
+	query, args, err := sqlx.In(`
+		SELECT message_id, path, ind FROM MessageImage WHERE message_id IN (?) ORDER BY message_id, ind
+	`, ids)
+	if err != nil {
+		return core_err.Rethrow("building the query for images of messages", err)
+	}
+	var images []struct {
+		MessageId values.MessageId `db:"message_id"`
+		models.MessageImageModel
+	}
+	err = db.sql.Select(&images, db.sql.Rebind(query), args...)
+	if err != nil {
+		return core_err.Rethrow("SELECTing images of messages", err)
+	}
+	imagesByMessage := map[values.MessageId][]models.MessageImageModel{}
+	for _, image := range images {
+		imagesByMessage[image.MessageId] = append(imagesByMessage[image.MessageId], image.MessageImageModel)
+	}
+	for i := range messages {
+		messages[i].Images = imagesByMessage[messages[i].Id]
+	}
+	return nil
+}
@@ -0,0 +1,225 @@
+package sql_db_test
+
+import (
+	"github.com/k0marov/go-socnet/core/general/core_err"
+	"github.com/k0marov/go-socnet/core/general/core_values"
+	"github.com/k0marov/go-socnet/core/general/pagination"
+	. "github.com/k0marov/go-socnet/core/helpers/test_helpers"
+	"testing"
+	"time"
+
+	"github.com/k0marov/go-socnet/features/messages/domain/models"
+	"github.com/k0marov/go-socnet/features/messages/domain/values"
+	"github.com/k0marov/go-socnet/features/messages/store/sql_db"
+	profiles_db "github.com/k0marov/go-socnet/features/profiles/store/sql_db"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestSqlDB_ErrorHandling(t *testing.T) {
+	db := OpenTestDB(t)
+	sqlDB, err := sql_db.NewSqlDB(db)
+	AssertNoError(t, err)
+	db.Close() // this will make all calls to db throw
+	t.Run("GetConversation", func(t *testing.T) {
+		_, err := sqlDB.GetConversation(RandomId())
+		AssertSomeError(t, err)
+	})
+	t.Run("CreateConversation", func(t *testing.T) {
+		_, err := sqlDB.CreateConversation(RandomId(), RandomId(), RandomTime())
+		AssertSomeError(t, err)
+	})
+	t.Run("GetConversations", func(t *testing.T) {
+		_, err := sqlDB.GetConversations(RandomId(), pagination.Page{Limit: 10})
+		AssertSomeError(t, err)
+	})
+	t.Run("CreateMessage", func(t *testing.T) {
+		_, err := sqlDB.CreateMessage(db, models.MessageToCreate{Conversation: RandomId(), Sender: RandomId(), Text: RandomString()})
+		AssertSomeError(t, err)
+	})
+	t.Run("AddMessageImages", func(t *testing.T) {
+		err := sqlDB.AddMessageImages(db, RandomId(), []models.MessageImageModel{{Index: 1, Path: RandomString()}})
+		AssertSomeError(t, err)
+	})
+	t.Run("GetMessageImage", func(t *testing.T) {
+		_, err := sqlDB.GetMessageImage(RandomId(), RandomId(), 1)
+		AssertSomeError(t, err)
+	})
+	t.Run("GetMessages", func(t *testing.T) {
+		_, err := sqlDB.GetMessages(RandomId(), pagination.Page{Limit: 10})
+		AssertSomeError(t, err)
+	})
+	t.Run("GetLastMessages", func(t *testing.T) {
+		_, err := sqlDB.GetLastMessages([]values.ConversationId{RandomId()})
+		AssertSomeError(t, err)
+	})
+	t.Run("MarkConversationRead", func(t *testing.T) {
+		err := sqlDB.MarkConversationRead(RandomId(), RandomId(), RandomTime())
+		AssertSomeError(t, err)
+	})
+	t.Run("GetUnreadCount", func(t *testing.T) {
+		_, err := sqlDB.GetUnreadCount(RandomId())
+		AssertSomeError(t, err)
+	})
+}
+
+func TestSqlDB(t *testing.T) {
+	db := OpenTestDB(t)
+	sqlDB, err := sql_db.NewSqlDB(db)
+	AssertNoError(t, err)
+	profilesDB, err := profiles_db.NewSqlDB(db)
+	AssertNoError(t, err)
+
+	createProfile := func(t testing.TB) core_values.UserId {
+		t.Helper()
+		profile := RandomProfileModel()
+		AssertNoError(t, profilesDB.CreateProfile(profile))
+		return profile.Id
+	}
+	atMinute := func(minute int) time.Time {
+		return time.Date(2022, 6, 1, 12, minute, 0, 0, time.UTC)
+	}
+	createConversation := func(t testing.TB, profile1, profile2 core_values.UserId) values.ConversationId {
+		t.Helper()
+		first, second := values.NewParticipants(profile1, profile2)
+		id, err := sqlDB.CreateConversation(first, second, atMinute(0))
+		AssertNoError(t, err)
+		return id
+	}
+	sendMessage := func(t testing.TB, conversation values.ConversationId, sender core_values.UserId, minute int, images ...models.MessageImageModel) models.MessageModel {
+		t.Helper()
+		newMessage := models.MessageToCreate{Conversation: conversation, Sender: sender, Text: RandomString(), CreatedAt: atMinute(minute)}
+		id, err := sqlDB.CreateMessage(db, newMessage)
+		AssertNoError(t, err)
+		AssertNoError(t, sqlDB.AddMessageImages(db, id, images))
+		return models.MessageModel{
+			Id:             id,
+			ConversationId: conversation,
+			SenderId:       sender,
+			Text:           newMessage.Text,
+			CreatedAt:      newMessage.CreatedAt.Unix(),
+			Images:         images,
+		}
+	}
+
+	t.Run("creating and getting conversations", func(t *testing.T) {
+		profile1 := createProfile(t)
+		profile2 := createProfile(t)
+		id := createConversation(t, profile1, profile2)
+
+		// the same pair of profiles always gets the same conversation
+		Assert(t, createConversation(t, profile2, profile1), id, "id of the conversation created again")
+
+		first, second := values.NewParticipants(profile1, profile2)
+		got, err := sqlDB.GetConversation(id)
+		AssertNoError(t, err)
+		Assert(t, got, models.ConversationModel{Id: id, FirstId: first, SecondId: second, CreatedAt: atMinute(0).Unix()}, "the stored conversation")
+	})
+	t.Run("getting a conversation that doesn't exist", func(t *testing.T) {
+		_, err := sqlDB.GetConversation("424242")
+		AssertError(t, err, core_err.ErrNotFound)
+	})
+	t.Run("sending messages and listing them", func(t *testing.T) {
+		sender := createProfile(t)
+		recipient := createProfile(t)
+		conversation := createConversation(t, sender, recipient)
+		images := []models.MessageImageModel{{Index: 1, Path: RandomString()}, {Index: 2, Path: RandomString()}}
+		msg1 := sendMessage(t, conversation, sender, 1, images...)
+		msg2 := sendMessage(t, conversation, recipient, 2)
+		msg3 := sendMessage(t, conversation, sender, 3)
+
+		got, err := sqlDB.GetMessages(conversation, pagination.Page{Limit: 2})
+		AssertNoError(t, err)
+		Assert(t, got, []models.MessageModel{msg3, msg2}, "the first page of messages")
+		got, err = sqlDB.GetMessages(conversation, pagination.Page{After: pagination.Cursor{CreatedAt: msg2.CreatedAt, Id: msg2.Id}, Limit: 2})
+		AssertNoError(t, err)
+		Assert(t, got, []models.MessageModel{msg1}, "the second page of messages")
+
+		last, err := sqlDB.GetLastMessages([]values.ConversationId{conversation, createConversation(t, sender, createProfile(t))})
+		AssertNoError(t, err)
+		Assert(t, last, map[values.ConversationId]models.MessageModel{conversation: msg3}, "last messages")
+	})
+	t.Run("getting an image of a message", func(t *testing.T) {
+		sender := createProfile(t)
+		conversation := createConversation(t, sender, createProfile(t))
+		otherConversation := createConversation(t, sender, createProfile(t))
+		image := models.MessageImageModel{Index: 1, Path: RandomString()}
+		msg := sendMessage(t, conversation, sender, 1, image)
+
+		path, err := sqlDB.GetMessageImage(conversation, msg.Id, 1)
+		AssertNoError(t, err)
+		Assert(t, path, image.Path, "the path of the image")
+
+		_, err = sqlDB.GetMessageImage(conversation, msg.Id, 2)
+		AssertError(t, err, core_err.ErrNotFound)
+		// the message should be in the given conversation
+		_, err = sqlDB.GetMessageImage(otherConversation, msg.Id, 1)
+		AssertError(t, err, core_err.ErrNotFound)
+	})
+	t.Run("listing conversations by the latest message", func(t *testing.T) {
+		caller := createProfile(t)
+		interlocutor1 := createProfile(t)
+		interlocutor2 := createProfile(t)
+		conversation1 := createConversation(t, caller, interlocutor1)
+		conversation2 := createConversation(t, interlocutor2, caller)
+		createConversation(t, caller, createProfile(t)) // without messages, so it's not listed
+
+		sendMessage(t, conversation1, caller, 1)
+		sendMessage(t, conversation2, interlocutor2, 2)
+		sendMessage(t, conversation1, interlocutor1, 3)
+		sendMessage(t, conversation1, interlocutor1, 4)
+
+		got, err := sqlDB.GetConversations(caller, pagination.Page{Limit: 10})
+		AssertNoError(t, err)
+		AssertFatal(t, len(got), 2, "number of conversations")
+		Assert(t, got[0].Id, conversation1, "the conversation with the latest message")
+		Assert(t, got[0].LastMessageAt, atMinute(4).Unix(), "time of the latest message")
+		Assert(t, got[0].Unread, 2, "number of unread messages")
+		Assert(t, got[1].Id, conversation2, "the second conversation")
+		Assert(t, got[1].Unread, 1, "number of unread messages")
+
+		got, err = sqlDB.GetConversations(caller, pagination.Page{After: got[0].Cursor(), Limit: 10})
+		AssertNoError(t, err)
+		AssertFatal(t, len(got), 1, "number of conversations on the second page")
+		Assert(t, got[0].Id, conversation2, "the conversation on the second page")
+
+		// the messages of caller are unread by interlocutor1
+		got, err = sqlDB.GetConversations(interlocutor1, pagination.Page{Limit: 10})
+		AssertNoError(t, err)
+		AssertFatal(t, len(got), 1, "number of conversations")
+		Assert(t, got[0].Unread, 1, "number of unread messages")
+	})
+	t.Run("reading conversations", func(t *testing.T) {
+		caller := createProfile(t)
+		interlocutor1 := createProfile(t)
+		interlocutor2 := createProfile(t)
+		conversation1 := createConversation(t, caller, interlocutor1)
+		conversation2 := createConversation(t, caller, interlocutor2)
+		sendMessage(t, conversation1, caller, 1)
+		sendMessage(t, conversation1, interlocutor1, 2)
+		sendMessage(t, conversation1, interlocutor1, 3)
+		sendMessage(t, conversation2, interlocutor2, 4)
+		assertUnread := func(t testing.TB, participant core_values.UserId, want int) {
+			t.Helper()
+			count, err := sqlDB.GetUnreadCount(participant)
+			AssertNoError(t, err)
+			Assert(t, count, want, "number of unread messages")
+		}
+		assertUnread(t, caller, 3)
+		assertUnread(t, interlocutor1, 1)
+
+		err := sqlDB.MarkConversationRead(conversation1, caller, atMinute(5))
+		AssertNoError(t, err)
+		assertUnread(t, caller, 1)
+		assertUnread(t, interlocutor1, 1)
+
+		// only the messages sent to the reader are marked as read, and reading again keeps the time of the first read
+		err = sqlDB.MarkConversationRead(conversation1, caller, atMinute(6))
+		AssertNoError(t, err)
+		messages, err := sqlDB.GetMessages(conversation1, pagination.Page{Limit: 10})
+		AssertNoError(t, err)
+		AssertFatal(t, len(messages), 3, "number of messages")
+		Assert(t, messages[0].ReadAt, atMinute(5).Unix(), "read time of a message sent to the reader")
+		Assert(t, messages[1].ReadAt, atMinute(5).Unix(), "read time of a message sent to the reader")
+		Assert(t, messages[2].ReadAt, int64(0), "read time of a message sent by the reader")
+	})
+}
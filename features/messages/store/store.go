@@ -0,0 +1,121 @@
+package store
+
+import (
+	"github.com/k0marov/go-socnet/core/general/core_err"
+	"github.com/k0marov/go-socnet/core/general/core_values"
+	"github.com/k0marov/go-socnet/core/general/pagination"
+	"github.com/k0marov/go-socnet/core/general/unit_of_work"
+	"time"
+
+	"github.com/k0marov/go-socnet/features/messages/domain/entities"
+	"github.com/k0marov/go-socnet/features/messages/domain/models"
+	"github.com/k0marov/go-socnet/features/messages/domain/store"
+	"github.com/k0marov/go-socnet/features/messages/domain/values"
+	"github.com/k0marov/go-socnet/features/messages/store/file_storage"
+)
+
+type (
+	DBConversationsGetter func(core_values.UserId, pagination.Page) ([]models.ConversationModel, error)
+	DBLastMessagesGetter  func([]values.ConversationId) (map[values.ConversationId]models.MessageModel, error)
+	DBMessagesGetter      func(values.ConversationId, pagination.Page) ([]models.MessageModel, error)
+
+	DBMessageCreator     func(ex unit_of_work.Executor, newMessage models.MessageToCreate) (values.MessageId, error)
+	DBMessageImagesAdder func(unit_of_work.Executor, values.MessageId, []models.MessageImageModel) error
+)
+
+// NewStoreMessageCreator creates the message together with its images in a single unit of work,
+// so a failure at any step leaves neither the message nor its image files behind
+func NewStoreMessageCreator(runInUnit unit_of_work.Runner, createMessage DBMessageCreator, storeImages file_storage.MessageImageFilesCreator, addImages DBMessageImagesAdder, imageURL values.ImageURLBuilder) store.MessageCreator {
+	return func(newMessage values.NewMessageData, createdAt time.Time) (entities.Message, error) {
+		var message models.MessageModel
+		err := runInUnit(func(uow unit_of_work.UnitOfWork) error {
+			messageToCreate := models.MessageToCreate{
+				Conversation: newMessage.Conversation,
+				Sender:       newMessage.Sender,
+				Text:         newMessage.Text,
+				CreatedAt:    createdAt,
+			}
+			id, err := createMessage(uow.Tx, messageToCreate)
+			if err != nil {
+				return core_err.Rethrow("creating a message in db", err)
+			}
+			imagePaths, err := storeImages(uow.Files.CreateFile, id, newMessage.Images)
+			if err != nil {
+				return core_err.Rethrow("storing image files", err)
+			}
+			var images []models.MessageImageModel
+			for i, path := range imagePaths {
+				images = append(images, models.MessageImageModel{
+					Path:  path,
+					Index: newMessage.Images[i].Index,
+				})
+			}
+			err = addImages(uow.Tx, id, images)
+			if err != nil {
+				return core_err.Rethrow("adding image paths to db", err)
+			}
+			message = models.MessageModel{
+				Id:             id,
+				ConversationId: newMessage.Conversation,
+				SenderId:       newMessage.Sender,
+				Text:           newMessage.Text,
+				CreatedAt:      createdAt.Unix(),
+				Images:         images,
+			}
+			return nil
+		})
+		if err != nil {
+			return entities.Message{}, err
+		}
+		return modelToMessage(message, imageURL), nil
+	}
+}
+
+func NewStoreMessagesGetter(getMessages DBMessagesGetter, imageURL values.ImageURLBuilder) store.MessagesGetter {
+	return func(conversation values.ConversationId, page pagination.Page) ([]entities.Message, error) {
+		models, err := getMessages(conversation, page)
+		if err != nil {
+			return []entities.Message{}, core_err.Rethrow("getting messages from db", err)
+		}
+		messages := []entities.Message{}
+		for _, model := range models {
+			messages = append(messages, modelToMessage(model, imageURL))
+		}
+		return messages, nil
+	}
+}
+
+func NewStoreConversationsGetter(getConversations DBConversationsGetter, getLastMessages DBLastMessagesGetter, imageURL values.ImageURLBuilder) store.ConversationsGetter {
+	return func(participant core_values.UserId, page pagination.Page) ([]entities.Conversation, error) {
+		models, err := getConversations(participant, page)
+		if err != nil {
+			return []entities.Conversation{}, core_err.Rethrow("getting conversations from db", err)
+		}
+		if len(models) == 0 {
+			return []entities.Conversation{}, nil
+		}
+		ids := make([]values.ConversationId, len(models))
+		for i, model := range models {
+			ids[i] = model.Id
+		}
+		lastMessages, err := getLastMessages(ids)
+		if err != nil {
+			return []entities.Conversation{}, core_err.Rethrow("getting last messages of conversations from db", err)
+		}
+		conversations := []entities.Conversation{}
+		for _, model := range models {
+			conversations = append(conversations, entities.Conversation{
+				ConversationModel: model,
+				LastMessage:       modelToMessage(lastMessages[model.Id], imageURL),
+			})
+		}
+		return conversations, nil
+	}
+}
+
+func modelToMessage(model models.MessageModel, imageURL values.ImageURLBuilder) entities.Message {
+	return entities.Message{
+		MessageModel: model,
+		Images:       entities.ImageURLs(model, imageURL),
+	}
+}
@@ -0,0 +1,193 @@
+package store_test
+
+import (
+	"fmt"
+	"github.com/k0marov/go-socnet/core/general/core_values"
+	"github.com/k0marov/go-socnet/core/general/pagination"
+	"github.com/k0marov/go-socnet/core/general/static_store"
+	"github.com/k0marov/go-socnet/core/general/unit_of_work"
+	. "github.com/k0marov/go-socnet/core/helpers/test_helpers"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/k0marov/go-socnet/features/messages/domain/entities"
+	"github.com/k0marov/go-socnet/features/messages/domain/models"
+	"github.com/k0marov/go-socnet/features/messages/domain/values"
+	"github.com/k0marov/go-socnet/features/messages/store"
+)
+
+func imageURL(conversation values.ConversationId, message values.MessageId, index int) core_values.FileURL {
+	return fmt.Sprintf("url of image %d of message %v in %v", index, message, conversation)
+}
+
+func TestStoreMessageCreator(t *testing.T) {
+	newMessage := values.NewMessageData{
+		Conversation: RandomId(),
+		Sender:       RandomId(),
+		Text:         RandomString(),
+		Images:       []values.MessageImageFile{{File: RandomFileData(), Index: 1}, {File: RandomFileData(), Index: 2}},
+	}
+	createdAt := time.Now()
+	messageId := RandomId()
+	paths := []core_values.StaticPath{RandomString(), RandomString()}
+	wantImages := []models.MessageImageModel{{Path: paths[0], Index: 1}, {Path: paths[1], Index: 2}}
+
+	runInUnit := func(work unit_of_work.Work) error {
+		return work(unit_of_work.UnitOfWork{})
+	}
+	t.Run("error case - running the unit of work returns an error", func(t *testing.T) {
+		tErr := RandomError()
+		runInUnit := func(unit_of_work.Work) error {
+			return tErr
+		}
+		_, err := store.NewStoreMessageCreator(runInUnit, nil, nil, nil, imageURL)(newMessage, createdAt)
+		AssertError(t, err, tErr)
+	})
+	createMessage := func(ex unit_of_work.Executor, message models.MessageToCreate) (values.MessageId, error) {
+		want := models.MessageToCreate{Conversation: newMessage.Conversation, Sender: newMessage.Sender, Text: newMessage.Text, CreatedAt: createdAt}
+		if message == want {
+			return messageId, nil
+		}
+		panic("unexpected args")
+	}
+	t.Run("error case - creating the message returns an error", func(t *testing.T) {
+		createMessage := func(unit_of_work.Executor, models.MessageToCreate) (values.MessageId, error) {
+			return "", RandomError()
+		}
+		_, err := store.NewStoreMessageCreator(runInUnit, createMessage, nil, nil, imageURL)(newMessage, createdAt)
+		AssertSomeError(t, err)
+	})
+	storeImages := func(createFile static_store.StaticFileCreator, message values.MessageId, images []values.MessageImageFile) ([]core_values.StaticPath, error) {
+		if message == messageId && reflect.DeepEqual(images, newMessage.Images) {
+			return paths, nil
+		}
+		panic("unexpected args")
+	}
+	t.Run("error case - storing the images returns an error", func(t *testing.T) {
+		storeImages := func(static_store.StaticFileCreator, values.MessageId, []values.MessageImageFile) ([]core_values.StaticPath, error) {
+			return nil, RandomError()
+		}
+		_, err := store.NewStoreMessageCreator(runInUnit, createMessage, storeImages, nil, imageURL)(newMessage, createdAt)
+		AssertSomeError(t, err)
+	})
+	addImages := func(ex unit_of_work.Executor, message values.MessageId, images []models.MessageImageModel) error {
+		if message == messageId && reflect.DeepEqual(images, wantImages) {
+			return nil
+		}
+		panic("unexpected args")
+	}
+	t.Run("error case - adding the images returns an error", func(t *testing.T) {
+		addImages := func(unit_of_work.Executor, values.MessageId, []models.MessageImageModel) error {
+			return RandomError()
+		}
+		_, err := store.NewStoreMessageCreator(runInUnit, createMessage, storeImages, addImages, imageURL)(newMessage, createdAt)
+		AssertSomeError(t, err)
+	})
+	t.Run("happy case", func(t *testing.T) {
+		got, err := store.NewStoreMessageCreator(runInUnit, createMessage, storeImages, addImages, imageURL)(newMessage, createdAt)
+		AssertNoError(t, err)
+		want := entities.Message{
+			MessageModel: models.MessageModel{
+				Id:             messageId,
+				ConversationId: newMessage.Conversation,
+				SenderId:       newMessage.Sender,
+				Text:           newMessage.Text,
+				CreatedAt:      createdAt.Unix(),
+				Images:         wantImages,
+			},
+			Images: []values.MessageImage{
+				{URL: imageURL(newMessage.Conversation, messageId, 1), Index: 1},
+				{URL: imageURL(newMessage.Conversation, messageId, 2), Index: 2},
+			},
+		}
+		Assert(t, got, want, "the created message")
+	})
+}
+
+func TestStoreMessagesGetter(t *testing.T) {
+	conversation := RandomId()
+	page := pagination.Page{Limit: RandomInt()}
+	t.Run("happy case", func(t *testing.T) {
+		model := models.MessageModel{Id: RandomId(), ConversationId: conversation, Text: RandomString(), Images: []models.MessageImageModel{{Index: 1, Path: RandomString()}}}
+		getMessages := func(gotConversation values.ConversationId, gotPage pagination.Page) ([]models.MessageModel, error) {
+			if gotConversation == conversation && gotPage == page {
+				return []models.MessageModel{model}, nil
+			}
+			panic("unexpected args")
+		}
+		got, err := store.NewStoreMessagesGetter(getMessages, imageURL)(conversation, page)
+		AssertNoError(t, err)
+		want := []entities.Message{{MessageModel: model, Images: []values.MessageImage{{URL: imageURL(conversation, model.Id, 1), Index: 1}}}}
+		Assert(t, got, want, "returned messages")
+	})
+	t.Run("error case - db returns an error", func(t *testing.T) {
+		getMessages := func(values.ConversationId, pagination.Page) ([]models.MessageModel, error) {
+			return nil, RandomError()
+		}
+		_, err := store.NewStoreMessagesGetter(getMessages, imageURL)(conversation, page)
+		AssertSomeError(t, err)
+	})
+}
+
+func TestStoreConversationsGetter(t *testing.T) {
+	participant := RandomId()
+	page := pagination.Page{Limit: RandomInt()}
+	conversations := []models.ConversationModel{{Id: RandomId(), Unread: 2}, {Id: RandomId()}}
+	lastMessages := map[values.ConversationId]models.MessageModel{
+		conversations[0].Id: {Id: RandomId(), Text: RandomString()},
+		conversations[1].Id: {Id: RandomId(), ConversationId: conversations[1].Id, Images: []models.MessageImageModel{{Index: 1, Path: RandomString()}}},
+	}
+	getConversations := func(gotParticipant core_values.UserId, gotPage pagination.Page) ([]models.ConversationModel, error) {
+		if gotParticipant == participant && gotPage == page {
+			return conversations, nil
+		}
+		panic("unexpected args")
+	}
+	t.Run("error case - getting conversations returns an error", func(t *testing.T) {
+		getConversations := func(core_values.UserId, pagination.Page) ([]models.ConversationModel, error) {
+			return nil, RandomError()
+		}
+		_, err := store.NewStoreConversationsGetter(getConversations, nil, imageURL)(participant, page)
+		AssertSomeError(t, err)
+	})
+	getLastMessages := func(ids []values.ConversationId) (map[values.ConversationId]models.MessageModel, error) {
+		if reflect.DeepEqual(ids, []values.ConversationId{conversations[0].Id, conversations[1].Id}) {
+			return lastMessages, nil
+		}
+		panic("unexpected args")
+	}
+	t.Run("error case - getting last messages returns an error", func(t *testing.T) {
+		getLastMessages := func([]values.ConversationId) (map[values.ConversationId]models.MessageModel, error) {
+			return nil, RandomError()
+		}
+		_, err := store.NewStoreConversationsGetter(getConversations, getLastMessages, imageURL)(participant, page)
+		AssertSomeError(t, err)
+	})
+	t.Run("happy case", func(t *testing.T) {
+		got, err := store.NewStoreConversationsGetter(getConversations, getLastMessages, imageURL)(participant, page)
+		AssertNoError(t, err)
+		want := []entities.Conversation{
+			{
+				ConversationModel: conversations[0],
+				LastMessage:       entities.Message{MessageModel: lastMessages[conversations[0].Id], Images: []values.MessageImage{}},
+			},
+			{
+				ConversationModel: conversations[1],
+				LastMessage: entities.Message{
+					MessageModel: lastMessages[conversations[1].Id],
+					Images:       []values.MessageImage{{URL: imageURL(conversations[1].Id, lastMessages[conversations[1].Id].Id, 1), Index: 1}},
+				},
+			},
+		}
+		Assert(t, got, want, "returned conversations")
+	})
+	t.Run("no conversations", func(t *testing.T) {
+		getConversations := func(core_values.UserId, pagination.Page) ([]models.ConversationModel, error) {
+			return []models.ConversationModel{}, nil
+		}
+		got, err := store.NewStoreConversationsGetter(getConversations, nil, imageURL)(participant, page)
+		AssertNoError(t, err)
+		Assert(t, len(got), 0, "number of conversations")
+	})
+}
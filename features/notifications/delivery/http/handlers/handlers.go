@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"github.com/go-chi/chi/v5"
+	"github.com/k0marov/go-socnet/core/general/client_errors"
+	"github.com/k0marov/go-socnet/core/helpers/http_helpers"
+	"github.com/k0marov/go-socnet/features/notifications/delivery/http/responses"
+	"github.com/k0marov/go-socnet/features/notifications/domain/service"
+	"net/http"
+)
+
+func NewGetNotificationsHandler(getNotifications service.NotificationsGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		caller, ok := http_helpers.GetUserOrAddUnauthorized(w, r)
+		if !ok {
+			return
+		}
+		page, ok := http_helpers.GetPageOrThrowClientError(w, r)
+		if !ok {
+			return
+		}
+		notifications, next, err := getNotifications(caller.Id, page)
+		if err != nil {
+			http_helpers.HandleServiceError(w, err)
+			return
+		}
+		http_helpers.WriteJson(w, responses.NewNotificationsResponse(notifications, next))
+	}
+}
+
+func NewGetUnreadCountHandler(getUnreadCount service.UnreadCountGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		caller, ok := http_helpers.GetUserOrAddUnauthorized(w, r)
+		if !ok {
+			return
+		}
+		count, err := getUnreadCount(caller.Id)
+		if err != nil {
+			http_helpers.HandleServiceError(w, err)
+			return
+		}
+		http_helpers.WriteJson(w, responses.UnreadCountResponse{Count: count})
+	}
+}
+
+func NewReadNotificationHandler(readNotification service.NotificationReader) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		caller, ok := http_helpers.GetUserOrAddUnauthorized(w, r)
+		if !ok {
+			return
+		}
+		id := chi.URLParam(r, "id")
+		if id == "" {
+			http_helpers.ThrowClientError(w, client_errors.IdNotProvided)
+			return
+		}
+		err := readNotification(id, caller.Id)
+		if err != nil {
+			http_helpers.HandleServiceError(w, err)
+			return
+		}
+	}
+}
+
+func NewReadAllNotificationsHandler(readAll service.AllNotificationsReader) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		caller, ok := http_helpers.GetUserOrAddUnauthorized(w, r)
+		if !ok {
+			return
+		}
+		err := readAll(caller.Id)
+		if err != nil {
+			http_helpers.HandleServiceError(w, err)
+			return
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package handlers_test
+
+import (
+	"context"
+	"github.com/go-chi/chi/v5"
+	"github.com/k0marov/go-socnet/core/general/client_errors"
+	"github.com/k0marov/go-socnet/core/general/core_values"
+	"github.com/k0marov/go-socnet/core/general/events"
+	"github.com/k0marov/go-socnet/core/general/pagination"
+	helpers "github.com/k0marov/go-socnet/core/helpers/http_test_helpers"
+	. "github.com/k0marov/go-socnet/core/helpers/test_helpers"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/k0marov/go-socnet/features/notifications/delivery/http/handlers"
+	"github.com/k0marov/go-socnet/features/notifications/delivery/http/responses"
+	"github.com/k0marov/go-socnet/features/notifications/domain/entities"
+	"github.com/k0marov/go-socnet/features/notifications/domain/models"
+	"github.com/k0marov/go-socnet/features/notifications/domain/values"
+	auth "github.com/k0marov/golang-auth"
+)
+
+func TestGetNotificationsHandler(t *testing.T) {
+	caller := RandomAuthUser()
+	helpers.BaseTest401(t, handlers.NewGetNotificationsHandler(nil))
+	t.Run("happy case", func(t *testing.T) {
+		notifications := []entities.ContextedNotification{{
+			NotificationModel: models.NotificationModel{Id: RandomId(), Kind: events.Liked, TargetType: events.Post, TargetId: RandomId(), Actors: 3, ReadAt: 42},
+			LastActor:         RandomContextedProfile(),
+		}}
+		next := pagination.Cursor{CreatedAt: int64(RandomInt()), Id: RandomId()}
+		getNotifications := func(callerId core_values.UserId, page pagination.Page) ([]entities.ContextedNotification, pagination.Cursor, error) {
+			if callerId == caller.Id && page == (pagination.Page{Limit: 5}) {
+				return notifications, next, nil
+			}
+			panic("unexpected args")
+		}
+		request := helpers.AddAuthDataToRequest(httptest.NewRequest(http.MethodGet, "/handler-should-not-care?limit=5", nil), caller)
+		response := httptest.NewRecorder()
+		handlers.NewGetNotificationsHandler(getNotifications).ServeHTTP(response, request)
+		AssertJSONData(t, response, responses.NewNotificationsResponse(notifications, next))
+	})
+	t.Run("error case - cursor is invalid", func(t *testing.T) {
+		request := helpers.AddAuthDataToRequest(httptest.NewRequest(http.MethodGet, "/handler-should-not-care?cursor=!", nil), caller)
+		response := httptest.NewRecorder()
+		handlers.NewGetNotificationsHandler(nil).ServeHTTP(response, request)
+		AssertClientError(t, response, client_errors.InvalidCursor)
+	})
+	helpers.BaseTestServiceErrorHandling(t, func(err error, response *httptest.ResponseRecorder) {
+		getNotifications := func(core_values.UserId, pagination.Page) ([]entities.ContextedNotification, pagination.Cursor, error) {
+			return nil, pagination.Cursor{}, err
+		}
+		handlers.NewGetNotificationsHandler(getNotifications).ServeHTTP(response, helpers.AddAuthDataToRequest(helpers.CreateRequest(nil), caller))
+	})
+}
+
+func TestGetUnreadCountHandler(t *testing.T) {
+	caller := RandomAuthUser()
+	helpers.BaseTest401(t, handlers.NewGetUnreadCountHandler(nil))
+	t.Run("happy case", func(t *testing.T) {
+		count := RandomInt()
+		getCount := func(callerId core_values.UserId) (int, error) {
+			if callerId == caller.Id {
+				return count, nil
+			}
+			panic("unexpected args")
+		}
+		response := httptest.NewRecorder()
+		handlers.NewGetUnreadCountHandler(getCount).ServeHTTP(response, helpers.AddAuthDataToRequest(helpers.CreateRequest(nil), caller))
+		AssertJSONData(t, response, responses.UnreadCountResponse{Count: count})
+	})
+	helpers.BaseTestServiceErrorHandling(t, func(err error, response *httptest.ResponseRecorder) {
+		getCount := func(core_values.UserId) (int, error) {
+			return 0, err
+		}
+		handlers.NewGetUnreadCountHandler(getCount).ServeHTTP(response, helpers.AddAuthDataToRequest(helpers.CreateRequest(nil), caller))
+	})
+}
+
+func createRequestWithId(id values.NotificationId, caller auth.User) *http.Request {
+	request := helpers.CreateRequest(nil)
+	ctx := chi.NewRouteContext()
+	ctx.URLParams.Add("id", id)
+	request = request.WithContext(context.WithValue(request.Context(), chi.RouteCtxKey, ctx))
+	return helpers.AddAuthDataToRequest(request, caller)
+}
+
+func TestReadNotificationHandler(t *testing.T) {
+	caller := RandomAuthUser()
+	id := RandomId()
+	helpers.BaseTest401(t, handlers.NewReadNotificationHandler(nil))
+	t.Run("happy case", func(t *testing.T) {
+		read := func(gotId values.NotificationId, callerId core_values.UserId) error {
+			if gotId == id && callerId == caller.Id {
+				return nil
+			}
+			panic("unexpected args")
+		}
+		response := httptest.NewRecorder()
+		handlers.NewReadNotificationHandler(read).ServeHTTP(response, createRequestWithId(id, caller))
+		AssertStatusCode(t, response, http.StatusOK)
+	})
+	t.Run("error case - id is not provided", func(t *testing.T) {
+		response := httptest.NewRecorder()
+		handlers.NewReadNotificationHandler(nil).ServeHTTP(response, helpers.AddAuthDataToRequest(helpers.CreateRequest(nil), caller))
+		AssertClientError(t, response, client_errors.IdNotProvided)
+	})
+	helpers.BaseTestServiceErrorHandling(t, func(err error, response *httptest.ResponseRecorder) {
+		read := func(values.NotificationId, core_values.UserId) error {
+			return err
+		}
+		handlers.NewReadNotificationHandler(read).ServeHTTP(response, createRequestWithId(id, caller))
+	})
+}
+
+func TestReadAllNotificationsHandler(t *testing.T) {
+	caller := RandomAuthUser()
+	helpers.BaseTest401(t, handlers.NewReadAllNotificationsHandler(nil))
+	t.Run("happy case", func(t *testing.T) {
+		readAll := func(callerId core_values.UserId) error {
+			if callerId == caller.Id {
+				return nil
+			}
+			panic("unexpected args")
+		}
+		response := httptest.NewRecorder()
+		handlers.NewReadAllNotificationsHandler(readAll).ServeHTTP(response, helpers.AddAuthDataToRequest(helpers.CreateRequest(nil), caller))
+		AssertStatusCode(t, response, http.StatusOK)
+	})
+	helpers.BaseTestServiceErrorHandling(t, func(err error, response *httptest.ResponseRecorder) {
+		readAll := func(core_values.UserId) error {
+			return err
+		}
+		handlers.NewReadAllNotificationsHandler(readAll).ServeHTTP(response, helpers.AddAuthDataToRequest(helpers.CreateRequest(nil), caller))
+	})
+}
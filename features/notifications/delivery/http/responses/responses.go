@@ -0,0 +1,57 @@
+package responses
+
+import (
+	"github.com/k0marov/go-socnet/core/general/events"
+	"github.com/k0marov/go-socnet/core/general/pagination"
+	"github.com/k0marov/go-socnet/core/helpers"
+	"github.com/k0marov/go-socnet/features/notifications/domain/entities"
+	profile_responses "github.com/k0marov/go-socnet/features/profiles/delivery/http/responses"
+)
+
+type TargetResponse struct {
+	Type events.TargetType `json:"type"`
+	Id   string            `json:"id"`
+}
+
+type NotificationResponse struct {
+	Id        string                            `json:"id"`
+	Kind      events.Kind                       `json:"kind"`
+	Target    TargetResponse                    `json:"target"`
+	CommentId string                            `json:"comment_id,omitempty"`
+	LastActor profile_responses.ProfileResponse `json:"last_actor"`
+	// ActorsCount is the number of profiles whose actions are grouped into the notification, e.g. the number of likers
+	ActorsCount int   `json:"actors_count"`
+	CreatedAt   int64 `json:"created_at"`
+	UpdatedAt   int64 `json:"updated_at"`
+	IsRead      bool  `json:"is_read"`
+}
+
+type NotificationsResponse struct {
+	Notifications []NotificationResponse `json:"notifications"`
+	NextCursor    string                 `json:"next_cursor"`
+}
+
+type UnreadCountResponse struct {
+	Count int `json:"count"`
+}
+
+func NewNotificationResponse(notification entities.ContextedNotification) NotificationResponse {
+	return NotificationResponse{
+		Id:          notification.Id,
+		Kind:        notification.Kind,
+		Target:      TargetResponse{Type: notification.TargetType, Id: notification.TargetId},
+		CommentId:   notification.CommentId,
+		LastActor:   profile_responses.NewProfileResponse(notification.LastActor),
+		ActorsCount: notification.Actors,
+		CreatedAt:   notification.CreatedAt,
+		UpdatedAt:   notification.UpdatedAt,
+		IsRead:      notification.ReadAt != 0,
+	}
+}
+
+func NewNotificationsResponse(notifications []entities.ContextedNotification, next pagination.Cursor) NotificationsResponse {
+	return NotificationsResponse{
+		Notifications: helpers.MapForEach(notifications, NewNotificationResponse),
+		NextCursor:    next.Encode(),
+	}
+}
@@ -0,0 +1,15 @@
+package router
+
+import (
+	"github.com/go-chi/chi/v5"
+	"net/http"
+)
+
+func NewNotificationsRouter(getNotifications, getUnreadCount, readNotification, readAll http.HandlerFunc) func(chi.Router) {
+	return func(r chi.Router) {
+		r.Get("/", getNotifications)
+		r.Get("/unread-count", getUnreadCount)
+		r.Post("/read-all", readAll)
+		r.Post("/{id}/read", readNotification)
+	}
+}
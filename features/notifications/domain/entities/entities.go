@@ -0,0 +1,11 @@
+package entities
+
+import (
+	"github.com/k0marov/go-socnet/features/notifications/domain/models"
+	profile_entities "github.com/k0marov/go-socnet/features/profiles/domain/entities"
+)
+
+type ContextedNotification struct {
+	models.NotificationModel
+	LastActor profile_entities.ContextedProfile
+}
@@ -0,0 +1,26 @@
+package models
+
+import (
+	"github.com/k0marov/go-socnet/core/general/core_values"
+	"github.com/k0marov/go-socnet/core/general/events"
+	"github.com/k0marov/go-socnet/core/general/pagination"
+)
+
+type NotificationModel struct {
+	Id          string             `db:"id"`
+	Kind        events.Kind        `db:"kind"`
+	TargetType  events.TargetType  `db:"targetType"`
+	TargetId    string             `db:"target_id"`
+	CommentId   string             `db:"comment_id"`
+	LastActorId core_values.UserId `db:"lastActor_id"`
+	// Actors is the number of distinct profiles whose actions are grouped into the notification
+	Actors    int   `db:"actors"`
+	CreatedAt int64 `db:"createdAt"`
+	UpdatedAt int64 `db:"updatedAt"`
+	ReadAt    int64 `db:"readAt"`
+}
+
+// Cursor uses the time of the latest grouped action, since the notifications are listed by it
+func (n NotificationModel) Cursor() pagination.Cursor {
+	return pagination.Cursor{CreatedAt: n.UpdatedAt, Id: n.Id}
+}
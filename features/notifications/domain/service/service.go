@@ -0,0 +1,119 @@
+package service
+
+import (
+	"errors"
+	"github.com/k0marov/go-socnet/core/general/client_errors"
+	"github.com/k0marov/go-socnet/core/general/core_err"
+	"github.com/k0marov/go-socnet/core/general/core_values"
+	"github.com/k0marov/go-socnet/core/general/events"
+	"github.com/k0marov/go-socnet/core/general/pagination"
+	"time"
+
+	"github.com/k0marov/go-socnet/features/notifications/domain/entities"
+	"github.com/k0marov/go-socnet/features/notifications/domain/models"
+	"github.com/k0marov/go-socnet/features/notifications/domain/store"
+	"github.com/k0marov/go-socnet/features/notifications/domain/values"
+	profile_service "github.com/k0marov/go-socnet/features/profiles/domain/service"
+)
+
+type (
+	// NotificationPusher delivers a stored notification to its recipient if they are connected
+	NotificationPusher func(recipient core_values.UserId, notification models.NotificationModel)
+)
+
+type (
+	// NotificationsGetter returns the notifications of caller, the most recently updated first, and the cursor of the next page
+	NotificationsGetter    func(caller core_values.UserId, page pagination.Page) ([]entities.ContextedNotification, pagination.Cursor, error)
+	UnreadCountGetter      func(caller core_values.UserId) (int, error)
+	NotificationReader     func(id values.NotificationId, caller core_values.UserId) error
+	AllNotificationsReader func(caller core_values.UserId) error
+)
+
+// NewEventNotifier turns the events into notifications of their recipients.
+// The groupable events about the same target which happen within groupWindow of each other are grouped into one notification.
+// The notification is pushed only after it is stored, so that the recipient gets its id and the number of grouped actors.
+func NewEventNotifier(groupWindow time.Duration, addNotification store.NotificationAdder, groupNotification store.NotificationGrouper, push NotificationPusher) events.Subscriber {
+	return func(event events.Event) error {
+		if event.Recipient == "" || event.Actor == event.Recipient {
+			return nil
+		}
+		var notification models.NotificationModel
+		var err error
+		if values.IsGroupable(event.Kind) {
+			notification, err = groupNotification(event, event.CreatedAt.Add(-groupWindow))
+		} else {
+			notification, err = addNotification(event)
+		}
+		if err != nil {
+			return core_err.Rethrow("storing a notification", err)
+		}
+		push(event.Recipient, notification)
+		return nil
+	}
+}
+
+func NewNotificationsGetter(getNotifications store.NotificationsGetter, getProfiles profile_service.ProfilesGetter) NotificationsGetter {
+	return func(caller core_values.UserId, page pagination.Page) ([]entities.ContextedNotification, pagination.Cursor, error) {
+		notifications, err := getNotifications(caller, page)
+		if err != nil {
+			return []entities.ContextedNotification{}, pagination.Cursor{}, core_err.Rethrow("getting notifications from store", err)
+		}
+		var actorIds []core_values.UserId
+		isActor := map[core_values.UserId]bool{}
+		for _, notification := range notifications {
+			if !isActor[notification.LastActorId] {
+				isActor[notification.LastActorId] = true
+				actorIds = append(actorIds, notification.LastActorId)
+			}
+		}
+		contexted := []entities.ContextedNotification{}
+		if len(actorIds) > 0 {
+			actors, err := getProfiles(actorIds, caller)
+			if err != nil {
+				return []entities.ContextedNotification{}, pagination.Cursor{}, core_err.Rethrow("getting actors of notifications", err)
+			}
+			for _, notification := range notifications {
+				actor, ok := actors[notification.LastActorId]
+				if !ok {
+					continue
+				}
+				contexted = append(contexted, entities.ContextedNotification{NotificationModel: notification, LastActor: actor})
+			}
+		}
+		// the cursor is taken from the stored notifications, so that skipping some of them doesn't end the listing early
+		return contexted, pagination.NextCursor(notifications, page, models.NotificationModel.Cursor), nil
+	}
+}
+
+func NewUnreadCountGetter(getUnreadCount store.UnreadCountGetter) UnreadCountGetter {
+	return func(caller core_values.UserId) (int, error) {
+		count, err := getUnreadCount(caller)
+		if err != nil {
+			return 0, core_err.Rethrow("getting the number of unread notifications from store", err)
+		}
+		return count, nil
+	}
+}
+
+func NewNotificationReader(readNotification store.NotificationReader) NotificationReader {
+	return func(id values.NotificationId, caller core_values.UserId) error {
+		err := readNotification(id, caller, time.Now())
+		if err != nil {
+			if errors.Is(err, core_err.ErrNotFound) {
+				return client_errors.NotFound
+			}
+			return core_err.Rethrow("marking a notification as read in store", err)
+		}
+		return nil
+	}
+}
+
+func NewAllNotificationsReader(readAll store.AllNotificationsReader) AllNotificationsReader {
+	return func(caller core_values.UserId) error {
+		err := readAll(caller, time.Now())
+		if err != nil {
+			return core_err.Rethrow("marking all notifications as read in store", err)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,226 @@
+package service_test
+
+import (
+	"github.com/k0marov/go-socnet/core/general/client_errors"
+	"github.com/k0marov/go-socnet/core/general/core_err"
+	"github.com/k0marov/go-socnet/core/general/core_values"
+	"github.com/k0marov/go-socnet/core/general/events"
+	"github.com/k0marov/go-socnet/core/general/pagination"
+	. "github.com/k0marov/go-socnet/core/helpers/test_helpers"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/k0marov/go-socnet/features/notifications/domain/entities"
+	"github.com/k0marov/go-socnet/features/notifications/domain/models"
+	"github.com/k0marov/go-socnet/features/notifications/domain/service"
+	"github.com/k0marov/go-socnet/features/notifications/domain/values"
+	profile_entities "github.com/k0marov/go-socnet/features/profiles/domain/entities"
+)
+
+func TestEventNotifier(t *testing.T) {
+	window := time.Hour
+	newEvent := func(kind events.Kind) events.Event {
+		return events.Event{Kind: kind, Actor: RandomId(), Recipient: RandomId(), TargetType: events.Post, TargetId: RandomId(), CreatedAt: RandomTime()}
+	}
+	noPushing := func(core_values.UserId, models.NotificationModel) {
+		panic("unexpected pushing")
+	}
+	type pushedNotification struct {
+		recipient    core_values.UserId
+		notification models.NotificationModel
+	}
+	var pushed []pushedNotification
+	push := func(recipient core_values.UserId, notification models.NotificationModel) {
+		pushed = append(pushed, pushedNotification{recipient, notification})
+	}
+	t.Run("the events of the recipient's own actions are skipped", func(t *testing.T) {
+		event := newEvent(events.Commented)
+		event.Actor = event.Recipient
+		err := service.NewEventNotifier(window, nil, nil, noPushing)(event)
+		AssertNoError(t, err)
+	})
+	t.Run("the events without a recipient are skipped", func(t *testing.T) {
+		event := newEvent(events.LikesChanged)
+		event.Recipient = ""
+		err := service.NewEventNotifier(window, nil, nil, noPushing)(event)
+		AssertNoError(t, err)
+	})
+	t.Run("groupable events", func(t *testing.T) {
+		event := newEvent(events.Liked)
+		t.Run("happy case - the grouped notification is pushed", func(t *testing.T) {
+			pushed = nil
+			grouped := models.NotificationModel{Id: RandomId(), Kind: event.Kind, LastActorId: event.Actor, Actors: 1 + RandomInt()}
+			group := func(gotEvent events.Event, since time.Time) (models.NotificationModel, error) {
+				if gotEvent == event && since.Equal(event.CreatedAt.Add(-window)) {
+					return grouped, nil
+				}
+				panic("unexpected args")
+			}
+			err := service.NewEventNotifier(window, nil, group, push)(event)
+			AssertNoError(t, err)
+			Assert(t, pushed, []pushedNotification{{event.Recipient, grouped}}, "pushed notifications")
+		})
+		t.Run("error case - store throws", func(t *testing.T) {
+			group := func(events.Event, time.Time) (models.NotificationModel, error) {
+				return models.NotificationModel{}, RandomError()
+			}
+			err := service.NewEventNotifier(window, nil, group, noPushing)(event)
+			AssertSomeError(t, err)
+		})
+	})
+	t.Run("other events", func(t *testing.T) {
+		event := newEvent(events.Mentioned)
+		t.Run("happy case - the added notification is pushed", func(t *testing.T) {
+			pushed = nil
+			added := models.NotificationModel{Id: RandomId(), Kind: event.Kind, LastActorId: event.Actor, Actors: 1}
+			add := func(gotEvent events.Event) (models.NotificationModel, error) {
+				if gotEvent == event {
+					return added, nil
+				}
+				panic("unexpected args")
+			}
+			err := service.NewEventNotifier(window, add, nil, push)(event)
+			AssertNoError(t, err)
+			Assert(t, pushed, []pushedNotification{{event.Recipient, added}}, "pushed notifications")
+		})
+		t.Run("error case - store throws", func(t *testing.T) {
+			add := func(events.Event) (models.NotificationModel, error) {
+				return models.NotificationModel{}, RandomError()
+			}
+			err := service.NewEventNotifier(window, add, nil, noPushing)(event)
+			AssertSomeError(t, err)
+		})
+	})
+}
+
+func TestNotificationsGetter(t *testing.T) {
+	caller := RandomId()
+	page := pagination.Page{Limit: 3}
+	actor1 := RandomContextedProfile()
+	actor2 := RandomContextedProfile()
+	deleted := RandomId()
+	notifications := []models.NotificationModel{
+		{Id: "3", Kind: events.Liked, LastActorId: actor1.Id, UpdatedAt: 30},
+		{Id: "2", Kind: events.Commented, LastActorId: deleted, UpdatedAt: 20},
+		{Id: "1", Kind: events.Followed, LastActorId: actor1.Id, UpdatedAt: 10},
+	}
+	getNotifications := func(recipient core_values.UserId, gotPage pagination.Page) ([]models.NotificationModel, error) {
+		if recipient == caller && gotPage == page {
+			return notifications, nil
+		}
+		panic("unexpected args")
+	}
+	t.Run("error case - store throws", func(t *testing.T) {
+		getNotifications := func(core_values.UserId, pagination.Page) ([]models.NotificationModel, error) {
+			return nil, RandomError()
+		}
+		_, _, err := service.NewNotificationsGetter(getNotifications, nil)(caller, page)
+		AssertSomeError(t, err)
+	})
+	getProfiles := func(ids []core_values.UserId, gotCaller core_values.UserId) (map[core_values.UserId]profile_entities.ContextedProfile, error) {
+		if reflect.DeepEqual(ids, []core_values.UserId{actor1.Id, deleted}) && gotCaller == caller {
+			return map[core_values.UserId]profile_entities.ContextedProfile{actor1.Id: actor1, actor2.Id: actor2}, nil
+		}
+		panic("unexpected args")
+	}
+	t.Run("error case - getting actors throws", func(t *testing.T) {
+		getProfiles := func([]core_values.UserId, core_values.UserId) (map[core_values.UserId]profile_entities.ContextedProfile, error) {
+			return nil, RandomError()
+		}
+		_, _, err := service.NewNotificationsGetter(getNotifications, getProfiles)(caller, page)
+		AssertSomeError(t, err)
+	})
+	t.Run("happy case - the notifications of nonexistent actors are skipped", func(t *testing.T) {
+		got, next, err := service.NewNotificationsGetter(getNotifications, getProfiles)(caller, page)
+		AssertNoError(t, err)
+		want := []entities.ContextedNotification{
+			{NotificationModel: notifications[0], LastActor: actor1},
+			{NotificationModel: notifications[2], LastActor: actor1},
+		}
+		Assert(t, got, want, "returned notifications")
+		Assert(t, next, pagination.Cursor{CreatedAt: 10, Id: "1"}, "next cursor")
+	})
+	t.Run("happy case - no notifications", func(t *testing.T) {
+		getNotifications := func(core_values.UserId, pagination.Page) ([]models.NotificationModel, error) {
+			return []models.NotificationModel{}, nil
+		}
+		got, next, err := service.NewNotificationsGetter(getNotifications, nil)(caller, page)
+		AssertNoError(t, err)
+		Assert(t, got, []entities.ContextedNotification{}, "returned notifications")
+		Assert(t, next, pagination.Cursor{}, "next cursor")
+	})
+}
+
+func TestUnreadCountGetter(t *testing.T) {
+	caller := RandomId()
+	t.Run("happy case", func(t *testing.T) {
+		count := RandomInt()
+		getCount := func(recipient core_values.UserId) (int, error) {
+			if recipient == caller {
+				return count, nil
+			}
+			panic("unexpected args")
+		}
+		got, err := service.NewUnreadCountGetter(getCount)(caller)
+		AssertNoError(t, err)
+		Assert(t, got, count, "returned count")
+	})
+	t.Run("error case - store throws", func(t *testing.T) {
+		getCount := func(core_values.UserId) (int, error) {
+			return 0, RandomError()
+		}
+		_, err := service.NewUnreadCountGetter(getCount)(caller)
+		AssertSomeError(t, err)
+	})
+}
+
+func TestNotificationReader(t *testing.T) {
+	caller := RandomId()
+	id := RandomId()
+	t.Run("happy case", func(t *testing.T) {
+		read := func(gotId values.NotificationId, recipient core_values.UserId, readAt time.Time) error {
+			if gotId == id && recipient == caller && TimeAlmostNow(readAt) {
+				return nil
+			}
+			panic("unexpected args")
+		}
+		err := service.NewNotificationReader(read)(id, caller)
+		AssertNoError(t, err)
+	})
+	t.Run("error case - notification is not found", func(t *testing.T) {
+		read := func(values.NotificationId, core_values.UserId, time.Time) error {
+			return core_err.ErrNotFound
+		}
+		err := service.NewNotificationReader(read)(id, caller)
+		AssertError(t, err, client_errors.NotFound)
+	})
+	t.Run("error case - store throws", func(t *testing.T) {
+		read := func(values.NotificationId, core_values.UserId, time.Time) error {
+			return RandomError()
+		}
+		err := service.NewNotificationReader(read)(id, caller)
+		AssertSomeError(t, err)
+	})
+}
+
+func TestAllNotificationsReader(t *testing.T) {
+	caller := RandomId()
+	t.Run("happy case", func(t *testing.T) {
+		readAll := func(recipient core_values.UserId, readAt time.Time) error {
+			if recipient == caller && TimeAlmostNow(readAt) {
+				return nil
+			}
+			panic("unexpected args")
+		}
+		err := service.NewAllNotificationsReader(readAll)(caller)
+		AssertNoError(t, err)
+	})
+	t.Run("error case - store throws", func(t *testing.T) {
+		readAll := func(core_values.UserId, time.Time) error {
+			return RandomError()
+		}
+		err := service.NewAllNotificationsReader(readAll)(caller)
+		AssertSomeError(t, err)
+	})
+}
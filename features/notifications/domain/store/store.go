@@ -0,0 +1,23 @@
+package store
+
+import (
+	"github.com/k0marov/go-socnet/core/general/core_values"
+	"github.com/k0marov/go-socnet/core/general/events"
+	"github.com/k0marov/go-socnet/core/general/pagination"
+	"github.com/k0marov/go-socnet/features/notifications/domain/models"
+	"github.com/k0marov/go-socnet/features/notifications/domain/values"
+	"time"
+)
+
+type (
+	// NotificationAdder adds a notification about event and returns it
+	NotificationAdder func(event events.Event) (models.NotificationModel, error)
+	// NotificationGrouper adds the actor of event to the latest unread notification about the same target updated after since,
+	// or adds a new notification if there is no such notification. It returns the notification in which event ended up.
+	NotificationGrouper func(event events.Event, since time.Time) (models.NotificationModel, error)
+	NotificationsGetter func(recipient core_values.UserId, page pagination.Page) ([]models.NotificationModel, error)
+	UnreadCountGetter   func(recipient core_values.UserId) (int, error)
+	// NotificationReader marks a notification of recipient as read, returning core_err.ErrNotFound if recipient has no such notification
+	NotificationReader     func(id values.NotificationId, recipient core_values.UserId, readAt time.Time) error
+	AllNotificationsReader func(recipient core_values.UserId, readAt time.Time) error
+)
@@ -0,0 +1,11 @@
+package values
+
+import "github.com/k0marov/go-socnet/core/general/events"
+
+type NotificationId = string
+
+// IsGroupable tells whether the events of a kind are grouped into one notification per target,
+// e.g. "X and 5 others liked your post". The events which create comments are never grouped.
+func IsGroupable(kind events.Kind) bool {
+	return kind == events.Liked || kind == events.Followed
+}
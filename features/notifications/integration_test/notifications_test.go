@@ -0,0 +1,147 @@
+package integration_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/k0marov/go-socnet/core/general/client_errors"
+	"github.com/k0marov/go-socnet/core/general/core_values"
+	"github.com/k0marov/go-socnet/core/general/events"
+	helpers "github.com/k0marov/go-socnet/core/helpers/http_test_helpers"
+	. "github.com/k0marov/go-socnet/core/helpers/test_helpers"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/k0marov/go-socnet/features/comments"
+	comment_handlers "github.com/k0marov/go-socnet/features/comments/delivery/http/handlers"
+	comment_responses "github.com/k0marov/go-socnet/features/comments/delivery/http/responses"
+	"github.com/k0marov/go-socnet/features/notifications"
+	"github.com/k0marov/go-socnet/features/notifications/delivery/http/responses"
+	"github.com/k0marov/go-socnet/features/notifications/domain/models"
+	"github.com/k0marov/go-socnet/features/posts"
+	post_models "github.com/k0marov/go-socnet/features/posts/domain/models"
+	posts_db "github.com/k0marov/go-socnet/features/posts/store/sql_db"
+	"github.com/k0marov/go-socnet/features/profiles"
+	auth "github.com/k0marov/golang-auth"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestNotifications(t *testing.T) {
+	// db
+	cfg := TestConfig(t)
+	sql := OpenTestDB(t)
+	// notifications, nobody is connected to the stream
+	dontPush := func(core_values.UserId, models.NotificationModel) {}
+	publish := events.NewPublisher(notifications.NewEventNotifierImpl(sql, time.Hour, dontPush))
+	// profiles
+	fakeRegisterProfile := profiles.NewRegisterCallback(sql)
+	getProfile := profiles.NewProfileGetterImpl(cfg, sql)
+	getProfiles := profiles.NewProfilesGetterImpl(cfg, sql)
+	resolveUsernames := profiles.NewUsernamesResolverImpl(sql)
+	// posts
+	postsDB, _ := posts_db.NewSqlDB(sql)
+	getCommentCounts := comments.NewCommentCountsGetterImpl(sql)
+	getCommentPreviews := comments.NewCommentPreviewsGetterImpl(sql, getProfiles)
+
+	r := chi.NewRouter()
+	r.Route("/profiles", profiles.NewProfilesRouterImpl(cfg, sql, publish))
+	r.Route("/posts", posts.NewPostsRouterImpl(cfg, sql, getProfile, getProfiles, getCommentCounts, getCommentPreviews, resolveUsernames, publish))
+	r.Route("/comments", comments.NewCommentsRouterImpl(cfg, sql, getProfile, posts.NewPostModelGetterImpl(sql), resolveUsernames, publish))
+	r.Route("/notifications", notifications.NewNotificationsRouterImpl(sql, getProfiles))
+
+	do := func(t testing.TB, method, url string, body io.Reader, caller auth.User) *httptest.ResponseRecorder {
+		t.Helper()
+		request := helpers.AddAuthDataToRequest(httptest.NewRequest(method, url, body), caller)
+		response := httptest.NewRecorder()
+		r.ServeHTTP(response, request)
+		return response
+	}
+	getNotifications := func(t testing.TB, caller auth.User) []responses.NotificationResponse {
+		t.Helper()
+		response := do(t, http.MethodGet, "/notifications/", nil, caller)
+		AssertStatusCode(t, response, http.StatusOK)
+		var notificationsResp responses.NotificationsResponse
+		json.NewDecoder(response.Body).Decode(&notificationsResp)
+		return notificationsResp.Notifications
+	}
+	getUnreadCount := func(t testing.TB, caller auth.User) int {
+		t.Helper()
+		response := do(t, http.MethodGet, "/notifications/unread-count", nil, caller)
+		AssertStatusCode(t, response, http.StatusOK)
+		var count responses.UnreadCountResponse
+		json.NewDecoder(response.Body).Decode(&count)
+		return count.Count
+	}
+
+	author := auth.User{Id: RandomId(), Username: "author"}
+	fan1 := auth.User{Id: RandomId(), Username: "fan1"}
+	fan2 := auth.User{Id: RandomId(), Username: "fan2"}
+	fakeRegisterProfile(author)
+	fakeRegisterProfile(fan1)
+	fakeRegisterProfile(fan2)
+	post, err := postsDB.CreatePost(sql, post_models.PostToCreate{Author: author.Id, Text: RandomString(), CreatedAt: time.Now()})
+	AssertNoError(t, err)
+
+	// fan1 follows the author
+	AssertStatusCode(t, do(t, http.MethodPut, "/profiles/"+author.Id+"/follow", nil, fan1), http.StatusOK)
+	// both fans like the post, their likes are grouped
+	AssertStatusCode(t, do(t, http.MethodPut, "/posts/"+post+"/like", nil, fan1), http.StatusOK)
+	AssertStatusCode(t, do(t, http.MethodPut, "/posts/"+post+"/like", nil, fan2), http.StatusOK)
+	// fan1 comments on the post mentioning fan2
+	body := bytes.NewBuffer(nil)
+	json.NewEncoder(body).Encode(comment_handlers.NewCommentRequest{Text: "hi @fan2"})
+	response := do(t, http.MethodPost, "/comments/?post_id="+post, body, fan1)
+	AssertStatusCode(t, response, http.StatusOK)
+	var comment comment_responses.CommentResponse
+	json.NewDecoder(response.Body).Decode(&comment)
+	// the author replying to the comment notifies fan1, but not the author themselves
+	body = bytes.NewBuffer(nil)
+	json.NewEncoder(body).Encode(comment_handlers.NewCommentRequest{Text: RandomString(), ParentId: comment.Id})
+	AssertStatusCode(t, do(t, http.MethodPost, "/comments/?post_id="+post, body, author), http.StatusOK)
+
+	t.Run("listing notifications", func(t *testing.T) {
+		got := getNotifications(t, author)
+		AssertFatal(t, len(got), 3, "number of notifications of the author")
+		Assert(t, got[0].Kind, events.Commented, "kind of the newest notification")
+		Assert(t, got[0].Target, responses.TargetResponse{Type: events.Post, Id: post}, "commented target")
+		Assert(t, got[0].CommentId, comment.Id, "created comment")
+		Assert(t, got[0].LastActor.Id, fan1.Id, "commenter")
+		Assert(t, got[1].Kind, events.Liked, "kind of the grouped notification")
+		Assert(t, got[1].ActorsCount, 2, "number of likers")
+		Assert(t, got[1].LastActor.Id, fan2.Id, "last liker")
+		Assert(t, got[2].Kind, events.Followed, "kind of the oldest notification")
+		Assert(t, got[2].Target, responses.TargetResponse{Type: events.Profile, Id: author.Id}, "followed target")
+		Assert(t, got[2].IsRead, false, "notification is read")
+
+		got = getNotifications(t, fan2)
+		AssertFatal(t, len(got), 1, "number of notifications of the mentioned profile")
+		Assert(t, got[0].Kind, events.Mentioned, "kind of the notification")
+		Assert(t, got[0].CommentId, comment.Id, "mentioning comment")
+
+		got = getNotifications(t, fan1)
+		AssertFatal(t, len(got), 1, "number of notifications of the replied profile")
+		Assert(t, got[0].Kind, events.Replied, "kind of the notification")
+		Assert(t, got[0].Target, responses.TargetResponse{Type: events.Comment, Id: comment.Id}, "replied target")
+	})
+	t.Run("reading notifications", func(t *testing.T) {
+		Assert(t, getUnreadCount(t, author), 3, "number of unread notifications")
+		notification := getNotifications(t, author)[0]
+
+		// only the recipient can read a notification
+		response := do(t, http.MethodPost, "/notifications/"+notification.Id+"/read", nil, fan1)
+		AssertClientError(t, response, client_errors.NotFound)
+
+		response = do(t, http.MethodPost, "/notifications/"+notification.Id+"/read", nil, author)
+		AssertStatusCode(t, response, http.StatusOK)
+		Assert(t, getUnreadCount(t, author), 2, "number of unread notifications")
+		Assert(t, getNotifications(t, author)[0].IsRead, true, "notification is read")
+
+		response = do(t, http.MethodPost, "/notifications/read-all", nil, author)
+		AssertStatusCode(t, response, http.StatusOK)
+		Assert(t, getUnreadCount(t, author), 0, "number of unread notifications")
+		Assert(t, getUnreadCount(t, fan2), 1, "number of unread notifications of another profile")
+	})
+}
@@ -0,0 +1,44 @@
+package notifications
+
+import (
+	"github.com/go-chi/chi/v5"
+	"github.com/jmoiron/sqlx"
+	"github.com/k0marov/go-socnet/core/general/events"
+	"github.com/k0marov/go-socnet/features/notifications/delivery/http/handlers"
+	"github.com/k0marov/go-socnet/features/notifications/delivery/http/router"
+	"github.com/k0marov/go-socnet/features/notifications/domain/service"
+	"github.com/k0marov/go-socnet/features/notifications/store/sql_db"
+	profile_service "github.com/k0marov/go-socnet/features/profiles/domain/service"
+	"log"
+	"time"
+)
+
+// NewEventNotifierImpl returns the subscriber which stores the notifications about the events published by other features.
+// The stored notifications are pushed to the connected recipients with push, which is provided by the stream feature.
+func NewEventNotifierImpl(db *sqlx.DB, groupWindow time.Duration, push service.NotificationPusher) events.Subscriber {
+	sqlDB, err := sql_db.NewSqlDB(db)
+	if err != nil {
+		log.Fatalf("error while opening sql db for notifications: %v", err)
+	}
+	return service.NewEventNotifier(groupWindow, sqlDB.AddNotification, sqlDB.GroupNotification, push)
+}
+
+func NewNotificationsRouterImpl(db *sqlx.DB, getProfiles profile_service.ProfilesGetter) func(chi.Router) {
+	// db
+	sqlDB, err := sql_db.NewSqlDB(db)
+	if err != nil {
+		log.Fatalf("error while opening sql db for notifications: %v", err)
+	}
+	// service
+	getNotifications := service.NewNotificationsGetter(sqlDB.GetNotifications, getProfiles)
+	getUnreadCount := service.NewUnreadCountGetter(sqlDB.GetUnreadCount)
+	readNotification := service.NewNotificationReader(sqlDB.MarkRead)
+	readAll := service.NewAllNotificationsReader(sqlDB.MarkAllRead)
+	// handlers
+	getNotificationsHandler := handlers.NewGetNotificationsHandler(getNotifications)
+	getUnreadCountHandler := handlers.NewGetUnreadCountHandler(getUnreadCount)
+	readNotificationHandler := handlers.NewReadNotificationHandler(readNotification)
+	readAllHandler := handlers.NewReadAllNotificationsHandler(readAll)
+
+	return router.NewNotificationsRouter(getNotificationsHandler, getUnreadCountHandler, readNotificationHandler, readAllHandler)
+}
@@ -0,0 +1,191 @@
+package sql_db
+
+import (
+	"database/sql"
+	"github.com/jmoiron/sqlx"
+	"github.com/k0marov/go-socnet/core/general/core_err"
+	"github.com/k0marov/go-socnet/core/general/core_values"
+	"github.com/k0marov/go-socnet/core/general/events"
+	"github.com/k0marov/go-socnet/core/general/pagination"
+	"github.com/k0marov/go-socnet/core/general/unit_of_work"
+	"github.com/k0marov/go-socnet/features/notifications/domain/models"
+	"github.com/k0marov/go-socnet/features/notifications/domain/values"
+	"time"
+)
+
+type SqlDB struct {
+	sql *sqlx.DB
+}
+
+// NewSqlDB expects the schema to be already migrated with core/general/migrations
+func NewSqlDB(db *sqlx.DB) (*SqlDB, error) {
+	return &SqlDB{sql: db}, nil
+}
+
+// notificationColumns selects the columns of models.NotificationModel from the Notification table
+const notificationColumns = `
+	id, kind, targetType, target_id, COALESCE(CAST(comment_id AS VARCHAR(255)), '') AS comment_id, lastActor_id, createdAt, updatedAt, readAt,
+	(SELECT COUNT(*) FROM NotificationActor WHERE NotificationActor.notification_id = Notification.id) AS actors
+`
+
+func (db *SqlDB) AddNotification(event events.Event) (models.NotificationModel, error) {
+	tx, err := db.sql.Beginx()
+	if err != nil {
+		return models.NotificationModel{}, core_err.Rethrow("starting a transaction", err)
+	}
+	defer tx.Rollback()
+	id, err := addNotification(tx, event)
+	if err != nil {
+		return models.NotificationModel{}, err
+	}
+	notification, err := getNotification(tx, id)
+	if err != nil {
+		return models.NotificationModel{}, err
+	}
+	err = tx.Commit()
+	if err != nil {
+		return models.NotificationModel{}, core_err.Rethrow("committing the added notification", err)
+	}
+	return notification, nil
+}
+
+func (db *SqlDB) GroupNotification(event events.Event, since time.Time) (models.NotificationModel, error) {
+	tx, err := db.sql.Beginx()
+	if err != nil {
+		return models.NotificationModel{}, core_err.Rethrow("starting a transaction", err)
+	}
+	defer tx.Rollback()
+	var group string
+	err = tx.Get(&group, tx.Rebind(`
+		SELECT id FROM Notification
+		WHERE recipient_id = ? AND kind = ? AND targetType = ? AND target_id = ? AND comment_id IS NULL AND readAt = 0 AND updatedAt >= ?
+		ORDER BY updatedAt DESC, id DESC
+		LIMIT 1
+	`), event.Recipient, event.Kind, event.TargetType, event.TargetId, since.Unix())
+	if err == sql.ErrNoRows {
+		group, err = addNotification(tx, event)
+	} else if err == nil {
+		err = addToGroup(tx, group, event)
+	} else {
+		err = core_err.Rethrow("SELECTing the notification group", err)
+	}
+	if err != nil {
+		return models.NotificationModel{}, err
+	}
+	notification, err := getNotification(tx, group)
+	if err != nil {
+		return models.NotificationModel{}, err
+	}
+	err = tx.Commit()
+	if err != nil {
+		return models.NotificationModel{}, core_err.Rethrow("committing the grouped notification", err)
+	}
+	return notification, nil
+}
+
+func addNotification(ex unit_of_work.Executor, event events.Event) (id string, err error) {
+	var comment any
+	if event.Comment != "" {
+		comment = event.Comment
+	}
+	err = ex.QueryRowx(ex.Rebind(`
+		INSERT INTO Notification(recipient_id, kind, targetType, target_id, comment_id, lastActor_id, createdAt, updatedAt) 
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?) RETURNING id
+	`), event.Recipient, event.Kind, event.TargetType, event.TargetId, comment, event.Actor, event.CreatedAt.Unix(), event.CreatedAt.Unix()).Scan(&id)
+	if err != nil {
+		return "", core_err.Rethrow("inserting a notification", err)
+	}
+	_, err = ex.Exec(ex.Rebind(`
+		INSERT INTO NotificationActor(notification_id, actor_id) VALUES (?, ?)
+	`), id, event.Actor)
+	if err != nil {
+		return "", core_err.Rethrow("inserting the actor of a notification", err)
+	}
+	return id, nil
+}
+
+func addToGroup(ex unit_of_work.Executor, group string, event events.Event) error {
+	_, err := ex.Exec(ex.Rebind(`
+		INSERT INTO NotificationActor(notification_id, actor_id) VALUES (?, ?) 
+		ON CONFLICT DO NOTHING
+	`), group, event.Actor)
+	if err != nil {
+		return core_err.Rethrow("inserting an actor of a notification group", err)
+	}
+	_, err = ex.Exec(ex.Rebind(`
+		UPDATE Notification SET lastActor_id = ?, updatedAt = ? WHERE id = ?
+	`), event.Actor, event.CreatedAt.Unix(), group)
+	if err != nil {
+		return core_err.Rethrow("updating a notification group", err)
+	}
+	return nil
+}
+
+func getNotification(ex unit_of_work.Executor, id string) (models.NotificationModel, error) {
+	var notification models.NotificationModel
+	err := ex.Get(&notification, ex.Rebind(`
+		SELECT `+notificationColumns+` FROM Notification WHERE id = ?
+	`), id)
+	if err != nil {
+		return models.NotificationModel{}, core_err.Rethrow("SELECTing the stored notification", err)
+	}
+	return notification, nil
+}
+
+// GetNotifications returns the notifications of recipient, the most recently updated first
+func (db *SqlDB) GetNotifications(recipient core_values.UserId, page pagination.Page) ([]models.NotificationModel, error) {
+	cond, condArgs := page.Condition("updatedAt", "id")
+	args := append(append([]any{recipient}, condArgs...), page.Limit)
+	notifications := []models.NotificationModel{}
+	err := db.sql.Select(&notifications, db.sql.Rebind(`
+		SELECT `+notificationColumns+`
+		FROM Notification
+		WHERE recipient_id = ? AND `+cond+`
+		ORDER BY updatedAt DESC, id DESC
+		LIMIT ?
+	`), args...)
+	if err != nil {
+		return []models.NotificationModel{}, core_err.Rethrow("SELECTing notifications", err)
+	}
+	return notifications, nil
+}
+
+func (db *SqlDB) GetUnreadCount(recipient core_values.UserId) (int, error) {
+	var count int
+	err := db.sql.Get(&count, db.sql.Rebind(`
+		SELECT COUNT(*) FROM Notification WHERE recipient_id = ? AND readAt = 0
+	`), recipient)
+	if err != nil {
+		return 0, core_err.Rethrow("counting unread notifications", err)
+	}
+	return count, nil
+}
+
+// MarkRead keeps the readAt of a notification which is already read
+func (db *SqlDB) MarkRead(id values.NotificationId, recipient core_values.UserId, readAt time.Time) error {
+	res, err := db.sql.Exec(db.sql.Rebind(`
+		UPDATE Notification SET readAt = CASE WHEN readAt = 0 THEN ? ELSE readAt END
+		WHERE id = ? AND recipient_id = ?
+	`), readAt.Unix(), id, recipient)
+	if err != nil {
+		return core_err.Rethrow("marking a notification as read", err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return core_err.Rethrow("getting the number of notifications marked as read", err)
+	}
+	if affected == 0 {
+		return core_err.ErrNotFound
+	}
+	return nil
+}
+
+func (db *SqlDB) MarkAllRead(recipient core_values.UserId, readAt time.Time) error {
+	_, err := db.sql.Exec(db.sql.Rebind(`
+		UPDATE Notification SET readAt = ? WHERE recipient_id = ? AND readAt = 0
+	`), readAt.Unix(), recipient)
+	if err != nil {
+		return core_err.Rethrow("marking all notifications as read", err)
+	}
+	return nil
+}
@@ -0,0 +1,211 @@
+package sql_db_test
+
+import (
+	"github.com/k0marov/go-socnet/core/general/core_err"
+	"github.com/k0marov/go-socnet/core/general/core_values"
+	"github.com/k0marov/go-socnet/core/general/events"
+	"github.com/k0marov/go-socnet/core/general/pagination"
+	. "github.com/k0marov/go-socnet/core/helpers/test_helpers"
+	"testing"
+	"time"
+
+	comment_values "github.com/k0marov/go-socnet/features/comments/domain/values"
+	comments_db "github.com/k0marov/go-socnet/features/comments/store/sql_db"
+	"github.com/k0marov/go-socnet/features/notifications/domain/models"
+	"github.com/k0marov/go-socnet/features/notifications/store/sql_db"
+	post_models "github.com/k0marov/go-socnet/features/posts/domain/models"
+	posts_db "github.com/k0marov/go-socnet/features/posts/store/sql_db"
+	profiles_db "github.com/k0marov/go-socnet/features/profiles/store/sql_db"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestSqlDB_ErrorHandling(t *testing.T) {
+	db := OpenTestDB(t)
+	sqlDB, err := sql_db.NewSqlDB(db)
+	AssertNoError(t, err)
+	db.Close() // this will make all calls to db throw
+	t.Run("AddNotification", func(t *testing.T) {
+		_, err := sqlDB.AddNotification(events.Event{Kind: events.Followed, Actor: RandomId(), Recipient: RandomId()})
+		AssertSomeError(t, err)
+	})
+	t.Run("GroupNotification", func(t *testing.T) {
+		_, err := sqlDB.GroupNotification(events.Event{Kind: events.Liked, Actor: RandomId(), Recipient: RandomId()}, RandomTime())
+		AssertSomeError(t, err)
+	})
+	t.Run("GetNotifications", func(t *testing.T) {
+		_, err := sqlDB.GetNotifications(RandomId(), pagination.Page{Limit: 10})
+		AssertSomeError(t, err)
+	})
+	t.Run("GetUnreadCount", func(t *testing.T) {
+		_, err := sqlDB.GetUnreadCount(RandomId())
+		AssertSomeError(t, err)
+	})
+	t.Run("MarkRead", func(t *testing.T) {
+		err := sqlDB.MarkRead(RandomId(), RandomId(), RandomTime())
+		AssertSomeError(t, err)
+	})
+	t.Run("MarkAllRead", func(t *testing.T) {
+		err := sqlDB.MarkAllRead(RandomId(), RandomTime())
+		AssertSomeError(t, err)
+	})
+}
+
+func TestSqlDB(t *testing.T) {
+	db := OpenTestDB(t)
+	sqlDB, err := sql_db.NewSqlDB(db)
+	AssertNoError(t, err)
+	profilesDB, err := profiles_db.NewSqlDB(db)
+	AssertNoError(t, err)
+	postsDB, err := posts_db.NewSqlDB(db)
+	AssertNoError(t, err)
+	commentsDB, err := comments_db.NewSqlDB(db)
+	AssertNoError(t, err)
+
+	createProfile := func(t testing.TB) core_values.UserId {
+		t.Helper()
+		profile := RandomProfileModel()
+		AssertNoError(t, profilesDB.CreateProfile(profile))
+		return profile.Id
+	}
+	atMinute := func(minute int) time.Time {
+		return time.Date(2022, 6, 1, 12, minute, 0, 0, time.UTC)
+	}
+	getAll := func(t testing.TB, recipient core_values.UserId) []models.NotificationModel {
+		t.Helper()
+		notifications, err := sqlDB.GetNotifications(recipient, pagination.Page{Limit: 100})
+		AssertNoError(t, err)
+		return notifications
+	}
+
+	t.Run("adding and listing notifications", func(t *testing.T) {
+		recipient := createProfile(t)
+		actor := createProfile(t)
+		post, err := postsDB.CreatePost(db, post_models.PostToCreate{Author: recipient, Text: RandomString(), CreatedAt: atMinute(0)})
+		AssertNoError(t, err)
+		comment, err := commentsDB.Create(db, comment_values.NewCommentValue{Author: actor, Post: post, Text: RandomString()}, atMinute(1))
+		AssertNoError(t, err)
+
+		follow := events.Event{Kind: events.Followed, Actor: actor, Recipient: recipient, TargetType: events.Profile, TargetId: recipient, CreatedAt: atMinute(1)}
+		addedFollow, err := sqlDB.AddNotification(follow)
+		AssertNoError(t, err)
+		commented := events.Event{Kind: events.Commented, Actor: actor, Recipient: recipient, TargetType: events.Post, TargetId: post, Comment: comment, CreatedAt: atMinute(2)}
+		addedCommented, err := sqlDB.AddNotification(commented)
+		AssertNoError(t, err)
+
+		got := getAll(t, recipient)
+		AssertFatal(t, len(got), 2, "number of notifications")
+		Assert(t, []models.NotificationModel{addedCommented, addedFollow}, got, "returned added notifications")
+		wantCommented := models.NotificationModel{
+			Id:          got[0].Id,
+			Kind:        events.Commented,
+			TargetType:  events.Post,
+			TargetId:    post,
+			CommentId:   comment,
+			LastActorId: actor,
+			Actors:      1,
+			CreatedAt:   atMinute(2).Unix(),
+			UpdatedAt:   atMinute(2).Unix(),
+		}
+		Assert(t, got[0], wantCommented, "the newest notification")
+		wantFollow := models.NotificationModel{
+			Id:          got[1].Id,
+			Kind:        events.Followed,
+			TargetType:  events.Profile,
+			TargetId:    recipient,
+			LastActorId: actor,
+			Actors:      1,
+			CreatedAt:   atMinute(1).Unix(),
+			UpdatedAt:   atMinute(1).Unix(),
+		}
+		Assert(t, got[1], wantFollow, "the oldest notification")
+
+		// paging
+		firstPage, err := sqlDB.GetNotifications(recipient, pagination.Page{Limit: 1})
+		AssertNoError(t, err)
+		Assert(t, firstPage, got[:1], "the first page")
+		secondPage, err := sqlDB.GetNotifications(recipient, pagination.Page{After: firstPage[0].Cursor(), Limit: 1})
+		AssertNoError(t, err)
+		Assert(t, secondPage, got[1:], "the second page")
+
+		// the notifications of other profiles are not listed
+		Assert(t, len(getAll(t, actor)), 0, "number of notifications of the actor")
+	})
+	t.Run("grouping notifications", func(t *testing.T) {
+		recipient := createProfile(t)
+		liker1 := createProfile(t)
+		liker2 := createProfile(t)
+		target := RandomId()
+		like := func(liker core_values.UserId, minute int) events.Event {
+			return events.Event{Kind: events.Liked, Actor: liker, Recipient: recipient, TargetType: events.Post, TargetId: target, CreatedAt: atMinute(minute)}
+		}
+		group := func(t testing.TB, event events.Event, since time.Time) models.NotificationModel {
+			t.Helper()
+			notification, err := sqlDB.GroupNotification(event, since)
+			AssertNoError(t, err)
+			return notification
+		}
+
+		group(t, like(liker1, 0), atMinute(0))
+		group(t, like(liker2, 5), atMinute(0))
+		// the same actor is counted once
+		grouped := group(t, like(liker2, 6), atMinute(0))
+		got := getAll(t, recipient)
+		AssertFatal(t, len(got), 1, "number of notifications")
+		Assert(t, grouped, got[0], "returned grouped notification")
+		Assert(t, got[0].Actors, 2, "number of grouped actors")
+		Assert(t, got[0].LastActorId, liker2, "last actor")
+		Assert(t, got[0].CreatedAt, atMinute(0).Unix(), "creation time")
+		Assert(t, got[0].UpdatedAt, atMinute(6).Unix(), "update time")
+
+		// the notifications updated before since start a new group
+		newGroup := group(t, like(liker1, 20), atMinute(10))
+		got = getAll(t, recipient)
+		AssertFatal(t, len(got), 2, "number of notifications")
+		Assert(t, got[0].Actors, 1, "number of actors in the new group")
+		Assert(t, newGroup, got[0], "returned new group")
+
+		// the read notifications start a new group too
+		AssertNoError(t, sqlDB.MarkRead(got[0].Id, recipient, atMinute(21)))
+		group(t, like(liker2, 22), atMinute(10))
+		Assert(t, len(getAll(t, recipient)), 3, "number of notifications")
+
+		// the notifications about other targets are not grouped
+		other := like(liker2, 23)
+		other.TargetId = RandomId()
+		group(t, other, atMinute(10))
+		Assert(t, len(getAll(t, recipient)), 4, "number of notifications")
+	})
+	t.Run("reading notifications", func(t *testing.T) {
+		recipient := createProfile(t)
+		actor := createProfile(t)
+		for minute := 0; minute < 3; minute++ {
+			follow := events.Event{Kind: events.Followed, Actor: actor, Recipient: recipient, TargetType: events.Profile, TargetId: recipient, CreatedAt: atMinute(minute)}
+			_, err := sqlDB.AddNotification(follow)
+			AssertNoError(t, err)
+		}
+		count, err := sqlDB.GetUnreadCount(recipient)
+		AssertNoError(t, err)
+		Assert(t, count, 3, "number of unread notifications")
+
+		notification := getAll(t, recipient)[0]
+		AssertNoError(t, sqlDB.MarkRead(notification.Id, recipient, atMinute(10)))
+		// reading an already read notification keeps the time it was read at
+		AssertNoError(t, sqlDB.MarkRead(notification.Id, recipient, atMinute(20)))
+		Assert(t, getAll(t, recipient)[0].ReadAt, atMinute(10).Unix(), "read time")
+		count, err = sqlDB.GetUnreadCount(recipient)
+		AssertNoError(t, err)
+		Assert(t, count, 2, "number of unread notifications")
+
+		// the notifications of other profiles can't be read
+		err = sqlDB.MarkRead(notification.Id, actor, atMinute(10))
+		AssertError(t, err, core_err.ErrNotFound)
+		err = sqlDB.MarkRead("424242", recipient, atMinute(10))
+		AssertError(t, err, core_err.ErrNotFound)
+
+		AssertNoError(t, sqlDB.MarkAllRead(recipient, atMinute(30)))
+		count, err = sqlDB.GetUnreadCount(recipient)
+		AssertNoError(t, err)
+		Assert(t, count, 0, "number of unread notifications")
+		Assert(t, getAll(t, recipient)[0].ReadAt, atMinute(10).Unix(), "read time of the already read notification")
+	})
+}
@@ -1,7 +1,10 @@
 package handlers
 
 import (
+	"encoding/json"
+	likeable_values "github.com/k0marov/go-socnet/core/abstract/likeable/values"
 	"github.com/k0marov/go-socnet/core/general/client_errors"
+	"github.com/k0marov/go-socnet/core/general/core_values"
 	helpers "github.com/k0marov/go-socnet/core/helpers/http_helpers"
 	"net/http"
 	"strconv"
@@ -9,6 +12,7 @@ import (
 	"github.com/k0marov/go-socnet/features/posts/delivery/http/responses"
 	"github.com/k0marov/go-socnet/features/posts/domain/service"
 	"github.com/k0marov/go-socnet/features/posts/domain/values"
+	profile_responses "github.com/k0marov/go-socnet/features/profiles/delivery/http/responses"
 
 	"github.com/go-chi/chi/v5"
 )
@@ -43,16 +47,85 @@ func NewGetListByIdHandler(getPosts service.PostsGetter) http.HandlerFunc {
 			helpers.ThrowClientError(w, client_errors.IdNotProvided)
 			return
 		}
-		posts, err := getPosts(profileId, user.Id)
+		page, ok := helpers.GetPageOrThrowClientError(w, r)
+		if !ok {
+			return
+		}
+		posts, err := getPosts(profileId, user.Id, page)
 		if err != nil {
 			helpers.HandleServiceError(w, err)
 			return
 		}
-		helpers.WriteJson(w, responses.NewPostListResponse(posts))
+		helpers.WriteJson(w, responses.NewPostListResponse(posts, page))
+	})
+}
+
+func NewGetHandler(getPost service.PostGetter) http.HandlerFunc {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, ok := helpers.GetUserOrAddUnauthorized(w, r)
+		if !ok {
+			return
+		}
+		postId := chi.URLParam(r, "id")
+		if postId == "" {
+			helpers.ThrowClientError(w, client_errors.IdNotProvided)
+			return
+		}
+		post, err := getPost(postId, user.Id)
+		if err != nil {
+			helpers.HandleServiceError(w, err)
+			return
+		}
+		helpers.WriteJson(w, responses.NewPostResponse(post))
+	})
+}
+
+func NewUpdateHandler(updatePost service.PostUpdater) http.HandlerFunc {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, ok := helpers.GetUserOrAddUnauthorized(w, r)
+		if !ok {
+			return
+		}
+		postId := chi.URLParam(r, "id")
+		if postId == "" {
+			helpers.ThrowClientError(w, client_errors.IdNotProvided)
+			return
+		}
+		images, ok := parseImageUpdates(r)
+		if !ok {
+			helpers.ThrowClientError(w, client_errors.InvalidImageIndex)
+			return
+		}
+		upd := values.PostUpdateData{
+			Text:   r.FormValue("text"),
+			Images: images,
+		}
+		post, err := updatePost(postId, user.Id, upd)
+		if err != nil {
+			helpers.HandleServiceError(w, err)
+			return
+		}
+		helpers.WriteJson(w, responses.NewPostResponse(post))
 	})
 }
 
 func NewToggleLikeHandler(toggleLike service.PostLikeToggler) http.HandlerFunc {
+	return newLikeActionHandler(toggleLike)
+}
+
+func NewLikeHandler(like service.PostLiker) http.HandlerFunc {
+	return newLikeActionHandler(like)
+}
+
+func NewUnlikeHandler(unlike service.PostUnliker) http.HandlerFunc {
+	return newLikeActionHandler(unlike)
+}
+
+type SetReactionRequest struct {
+	Reaction likeable_values.Reaction `json:"reaction"`
+}
+
+func NewSetReactionHandler(setReaction service.PostReactionSetter) http.HandlerFunc {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		user, ok := helpers.GetUserOrAddUnauthorized(w, r)
 		if !ok {
@@ -63,13 +136,128 @@ func NewToggleLikeHandler(toggleLike service.PostLikeToggler) http.HandlerFunc {
 			helpers.ThrowClientError(w, client_errors.IdNotProvided)
 			return
 		}
-		err := toggleLike(postId, user.Id)
+		var req SetReactionRequest
+		err := json.NewDecoder(r.Body).Decode(&req)
+		if err != nil {
+			helpers.ThrowClientError(w, client_errors.InvalidJsonError)
+			return
+		}
+		err = setReaction(postId, user.Id, req.Reaction)
 		if err != nil {
 			helpers.HandleServiceError(w, err)
 		}
 	})
 }
 
+type SetCommentsDisabledRequest struct {
+	CommentsDisabled bool `json:"comments_disabled"`
+}
+
+func NewSetCommentsDisabledHandler(disableComments service.CommentsDisabler) http.HandlerFunc {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, ok := helpers.GetUserOrAddUnauthorized(w, r)
+		if !ok {
+			return
+		}
+		postId := chi.URLParam(r, "id")
+		if postId == "" {
+			helpers.ThrowClientError(w, client_errors.IdNotProvided)
+			return
+		}
+		var req SetCommentsDisabledRequest
+		err := json.NewDecoder(r.Body).Decode(&req)
+		if err != nil {
+			helpers.ThrowClientError(w, client_errors.InvalidJsonError)
+			return
+		}
+		err = disableComments(postId, user.Id, req.CommentsDisabled)
+		if err != nil {
+			helpers.HandleServiceError(w, err)
+		}
+	})
+}
+
+func NewGetTagPostsHandler(getTagPosts service.TagPostsGetter) http.HandlerFunc {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, ok := helpers.GetUserOrAddUnauthorized(w, r)
+		if !ok {
+			return
+		}
+		tag := chi.URLParam(r, "tag")
+		page, ok := helpers.GetPageOrThrowClientError(w, r)
+		if !ok {
+			return
+		}
+		posts, err := getTagPosts(tag, user.Id, page)
+		if err != nil {
+			helpers.HandleServiceError(w, err)
+			return
+		}
+		helpers.WriteJson(w, responses.NewPostListResponse(posts, page))
+	})
+}
+
+func NewGetTrendingTagsHandler(getTrending service.TrendingTagsGetter) http.HandlerFunc {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, ok := helpers.GetUserOrAddUnauthorized(w, r)
+		if !ok {
+			return
+		}
+		tags, err := getTrending()
+		if err != nil {
+			helpers.HandleServiceError(w, err)
+			return
+		}
+		helpers.WriteJson(w, responses.NewTrendingTagsResponse(tags))
+	})
+}
+
+func NewRemoveReactionHandler(removeReaction service.PostReactionRemover) http.HandlerFunc {
+	return newLikeActionHandler(removeReaction)
+}
+
+func newLikeActionHandler(action func(values.PostId, core_values.UserId) error) http.HandlerFunc {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, ok := helpers.GetUserOrAddUnauthorized(w, r)
+		if !ok {
+			return
+		}
+		postId := chi.URLParam(r, "id")
+		if postId == "" {
+			helpers.ThrowClientError(w, client_errors.IdNotProvided)
+			return
+		}
+		err := action(postId, user.Id)
+		if err != nil {
+			helpers.HandleServiceError(w, err)
+		}
+	})
+}
+
+func NewGetLikersHandler(getLikers service.PostLikersGetter) http.HandlerFunc {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, ok := helpers.GetUserOrAddUnauthorized(w, r)
+		if !ok {
+			return
+		}
+		postId := chi.URLParam(r, "id")
+		if postId == "" {
+			helpers.ThrowClientError(w, client_errors.IdNotProvided)
+			return
+		}
+		page, ok := helpers.GetPageOrThrowClientError(w, r)
+		if !ok {
+			return
+		}
+		likers, next, err := getLikers(postId, user.Id, page)
+		if err != nil {
+			helpers.HandleServiceError(w, err)
+			return
+		}
+		helpers.WriteJson(w, profile_responses.NewProfilesResponse(likers, next))
+	})
+}
+
 func NewCreateHandler(createPost service.PostCreator) http.HandlerFunc {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		user, ok := helpers.GetUserOrAddUnauthorized(w, r)
@@ -100,3 +288,25 @@ func parseImages(r *http.Request) []values.PostImageFile {
 		images = append(images, image)
 	}
 }
+
+// parseImageUpdates parses the full list of images of an edited post.
+// Field "image_<i>" is either a new image file or the index of an existing image of the post, which is put at position i.
+func parseImageUpdates(r *http.Request) ([]values.PostImageUpdate, bool) {
+	images := []values.PostImageUpdate{}
+	for i := 1; ; i++ {
+		field := "image_" + strconv.Itoa(i)
+		if file, ok := helpers.ParseFile(r, field); ok {
+			images = append(images, values.PostImageUpdate{Index: i, File: file})
+			continue
+		}
+		oldIndexStr := r.FormValue(field)
+		if oldIndexStr == "" {
+			return images, true
+		}
+		oldIndex, err := strconv.Atoi(oldIndexStr)
+		if err != nil || oldIndex <= 0 {
+			return []values.PostImageUpdate{}, false
+		}
+		images = append(images, values.PostImageUpdate{Index: i, OldIndex: oldIndex})
+	}
+}
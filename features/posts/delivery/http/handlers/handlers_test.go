@@ -3,11 +3,17 @@ package handlers_test
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
+	likeable_values "github.com/k0marov/go-socnet/core/abstract/likeable/values"
 	"github.com/k0marov/go-socnet/core/general/client_errors"
 	"github.com/k0marov/go-socnet/core/general/core_values"
+	"github.com/k0marov/go-socnet/core/general/pagination"
 	helpers "github.com/k0marov/go-socnet/core/helpers/http_test_helpers"
 	. "github.com/k0marov/go-socnet/core/helpers/test_helpers"
+	profile_responses "github.com/k0marov/go-socnet/features/profiles/delivery/http/responses"
+	profile_entities "github.com/k0marov/go-socnet/features/profiles/domain/entities"
+	"io"
 	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
@@ -30,16 +36,25 @@ func TestGetListById(t *testing.T) {
 	t.Run("happy case", func(t *testing.T) {
 		randomProfile := RandomString()
 		posts := []entities.ContextedPost{RandomContextedPost(), RandomContextedPost()}
-		getter := func(profileId, callerId core_values.UserId) ([]entities.ContextedPost, error) {
-			if profileId == randomProfile && callerId == caller.Id {
+		cursor := pagination.Cursor{CreatedAt: RandomTime().Unix(), Id: RandomId()}
+		wantPage := pagination.Page{After: cursor, Limit: 2}
+		getter := func(profileId, callerId core_values.UserId, page pagination.Page) ([]entities.ContextedPost, error) {
+			if profileId == randomProfile && callerId == caller.Id && page == wantPage {
 				return posts, nil
 			}
 			panic("unexpected args")
 		}
-		request := helpers.AddAuthDataToRequest(httptest.NewRequest(http.MethodGet, "/handler-should-not-care?profile_id="+randomProfile, nil), caller)
+		url := "/handler-should-not-care?profile_id=" + randomProfile + "&limit=2&cursor=" + cursor.Encode()
+		request := helpers.AddAuthDataToRequest(httptest.NewRequest(http.MethodGet, url, nil), caller)
 		response := httptest.NewRecorder()
 		handlers.NewGetListByIdHandler(getter).ServeHTTP(response, request)
-		AssertJSONData(t, response, responses.NewPostListResponse(posts))
+		AssertJSONData(t, response, responses.NewPostListResponse(posts, wantPage))
+	})
+	t.Run("error case - limit is too big", func(t *testing.T) {
+		request := helpers.AddAuthDataToRequest(httptest.NewRequest(http.MethodGet, "/handler-should-not-care?profile_id=42&limit=1000", nil), caller)
+		response := httptest.NewRecorder()
+		handlers.NewGetListByIdHandler(nil).ServeHTTP(response, request)
+		AssertClientError(t, response, client_errors.TooBigLimit)
 	})
 	t.Run("error case - profile id is not provided", func(t *testing.T) {
 		request := helpers.AddAuthDataToRequest(helpers.CreateRequest(nil), caller)
@@ -48,7 +63,7 @@ func TestGetListById(t *testing.T) {
 		AssertClientError(t, response, client_errors.IdNotProvided)
 	})
 	helpers.BaseTestServiceErrorHandling(t, func(err error, rr *httptest.ResponseRecorder) {
-		getter := func(profile, caller core_values.UserId) ([]entities.ContextedPost, error) {
+		getter := func(profile, caller core_values.UserId, page pagination.Page) ([]entities.ContextedPost, error) {
 			return []entities.ContextedPost{}, err
 		}
 		request := helpers.AddAuthDataToRequest(httptest.NewRequest(http.MethodGet, "/handler-should-not-care?profile_id=42", nil), caller)
@@ -89,6 +104,185 @@ func TestToggleLike(t *testing.T) {
 	})
 }
 
+func TestLikeAndUnlike(t *testing.T) {
+	type action = func(values.PostId, core_values.UserId) error
+	newHandlers := map[string]func(action) http.HandlerFunc{
+		"like":            func(like action) http.HandlerFunc { return handlers.NewLikeHandler(like) },
+		"unlike":          func(unlike action) http.HandlerFunc { return handlers.NewUnlikeHandler(unlike) },
+		"remove reaction": func(remove action) http.HandlerFunc { return handlers.NewRemoveReactionHandler(remove) },
+	}
+	for name, newHandler := range newHandlers {
+		t.Run(name, func(t *testing.T) {
+			helpers.BaseTest401(t, newHandler(nil))
+			t.Run("happy case", func(t *testing.T) {
+				randomPost := RandomString()
+				randomUser := RandomAuthUser()
+				called := false
+				act := func(post values.PostId, fromUser core_values.UserId) error {
+					if post == randomPost && fromUser == randomUser.Id {
+						called = true
+						return nil
+					}
+					panic("unexpected args")
+				}
+				request := helpers.AddAuthDataToRequest(createRequestWithPostId(randomPost), randomUser)
+				response := httptest.NewRecorder()
+				newHandler(act).ServeHTTP(response, request)
+				AssertStatusCode(t, response, http.StatusOK)
+				Assert(t, called, true, "service called")
+			})
+			t.Run("error case - id is not provided", func(t *testing.T) {
+				request := helpers.AddAuthDataToRequest(helpers.CreateRequest(nil), RandomAuthUser())
+				response := httptest.NewRecorder()
+				newHandler(nil).ServeHTTP(response, request)
+				AssertClientError(t, response, client_errors.IdNotProvided)
+			})
+			helpers.BaseTestServiceErrorHandling(t, func(err error, rr *httptest.ResponseRecorder) {
+				act := func(values.PostId, core_values.UserId) error {
+					return err
+				}
+				request := helpers.AddAuthDataToRequest(createRequestWithPostId("42"), RandomAuthUser())
+				newHandler(act).ServeHTTP(rr, request)
+			})
+		})
+	}
+}
+
+func TestSetReactionHandler(t *testing.T) {
+	caller := RandomAuthUser()
+	post := RandomId()
+	reaction := RandomReaction()
+	createRequest := func(body io.Reader) *http.Request {
+		request := helpers.AddAuthDataToRequest(createRequestWithPostId(post), caller)
+		request.Body = io.NopCloser(body)
+		return request
+	}
+	createValidRequest := func() *http.Request {
+		body := bytes.NewBuffer(nil)
+		json.NewEncoder(body).Encode(handlers.SetReactionRequest{Reaction: reaction})
+		return createRequest(body)
+	}
+	helpers.BaseTest401(t, handlers.NewSetReactionHandler(nil))
+	t.Run("happy case", func(t *testing.T) {
+		called := false
+		setReaction := func(postId values.PostId, callerId core_values.UserId, gotReaction likeable_values.Reaction) error {
+			if postId == post && callerId == caller.Id && gotReaction == reaction {
+				called = true
+				return nil
+			}
+			panic("unexpected args")
+		}
+		response := httptest.NewRecorder()
+		handlers.NewSetReactionHandler(setReaction).ServeHTTP(response, createValidRequest())
+		AssertStatusCode(t, response, http.StatusOK)
+		Assert(t, called, true, "service called")
+	})
+	t.Run("error case - id is not provided", func(t *testing.T) {
+		response := httptest.NewRecorder()
+		handlers.NewSetReactionHandler(nil).ServeHTTP(response, helpers.AddAuthDataToRequest(helpers.CreateRequest(nil), caller))
+		AssertClientError(t, response, client_errors.IdNotProvided)
+	})
+	t.Run("error case - body is not valid JSON", func(t *testing.T) {
+		response := httptest.NewRecorder()
+		handlers.NewSetReactionHandler(nil).ServeHTTP(response, createRequest(bytes.NewBufferString("not json")))
+		AssertClientError(t, response, client_errors.InvalidJsonError)
+	})
+	helpers.BaseTestServiceErrorHandling(t, func(err error, rr *httptest.ResponseRecorder) {
+		setReaction := func(values.PostId, core_values.UserId, likeable_values.Reaction) error {
+			return err
+		}
+		handlers.NewSetReactionHandler(setReaction).ServeHTTP(rr, createValidRequest())
+	})
+}
+
+func TestSetCommentsDisabledHandler(t *testing.T) {
+	caller := RandomAuthUser()
+	post := RandomId()
+	disabled := RandomBool()
+	createRequest := func(body io.Reader) *http.Request {
+		request := helpers.AddAuthDataToRequest(createRequestWithPostId(post), caller)
+		request.Body = io.NopCloser(body)
+		return request
+	}
+	createValidRequest := func() *http.Request {
+		body := bytes.NewBuffer(nil)
+		json.NewEncoder(body).Encode(handlers.SetCommentsDisabledRequest{CommentsDisabled: disabled})
+		return createRequest(body)
+	}
+	helpers.BaseTest401(t, handlers.NewSetCommentsDisabledHandler(nil))
+	t.Run("happy case", func(t *testing.T) {
+		called := false
+		disableComments := func(postId values.PostId, callerId core_values.UserId, gotDisabled bool) error {
+			if postId == post && callerId == caller.Id && gotDisabled == disabled {
+				called = true
+				return nil
+			}
+			panic("unexpected args")
+		}
+		response := httptest.NewRecorder()
+		handlers.NewSetCommentsDisabledHandler(disableComments).ServeHTTP(response, createValidRequest())
+		AssertStatusCode(t, response, http.StatusOK)
+		Assert(t, called, true, "service called")
+	})
+	t.Run("error case - id is not provided", func(t *testing.T) {
+		response := httptest.NewRecorder()
+		handlers.NewSetCommentsDisabledHandler(nil).ServeHTTP(response, helpers.AddAuthDataToRequest(helpers.CreateRequest(nil), caller))
+		AssertClientError(t, response, client_errors.IdNotProvided)
+	})
+	t.Run("error case - body is not valid JSON", func(t *testing.T) {
+		response := httptest.NewRecorder()
+		handlers.NewSetCommentsDisabledHandler(nil).ServeHTTP(response, createRequest(bytes.NewBufferString("not json")))
+		AssertClientError(t, response, client_errors.InvalidJsonError)
+	})
+	helpers.BaseTestServiceErrorHandling(t, func(err error, rr *httptest.ResponseRecorder) {
+		disableComments := func(values.PostId, core_values.UserId, bool) error {
+			return err
+		}
+		handlers.NewSetCommentsDisabledHandler(disableComments).ServeHTTP(rr, createValidRequest())
+	})
+}
+
+func TestGetLikersHandler(t *testing.T) {
+	caller := RandomAuthUser()
+	post := RandomId()
+	helpers.BaseTest401(t, handlers.NewGetLikersHandler(nil))
+	t.Run("happy case", func(t *testing.T) {
+		likers := []profile_entities.ContextedProfile{RandomContextedProfile(), RandomContextedProfile()}
+		cursor := pagination.Cursor{Id: RandomId()}
+		next := pagination.Cursor{Id: RandomId()}
+		wantPage := pagination.Page{After: cursor, Limit: 2}
+		getLikers := func(postId values.PostId, callerId core_values.UserId, page pagination.Page) ([]profile_entities.ContextedProfile, pagination.Cursor, error) {
+			if postId == post && callerId == caller.Id && page == wantPage {
+				return likers, next, nil
+			}
+			panic("unexpected args")
+		}
+		request := createRequestWithPostId(post)
+		request.URL.RawQuery = "limit=2&cursor=" + cursor.Encode()
+		response := httptest.NewRecorder()
+		handlers.NewGetLikersHandler(getLikers).ServeHTTP(response, helpers.AddAuthDataToRequest(request, caller))
+		AssertJSONData(t, response, profile_responses.NewProfilesResponse(likers, next))
+	})
+	t.Run("error case - id is not provided", func(t *testing.T) {
+		response := httptest.NewRecorder()
+		handlers.NewGetLikersHandler(nil).ServeHTTP(response, helpers.AddAuthDataToRequest(helpers.CreateRequest(nil), caller))
+		AssertClientError(t, response, client_errors.IdNotProvided)
+	})
+	t.Run("error case - limit is too big", func(t *testing.T) {
+		request := createRequestWithPostId(post)
+		request.URL.RawQuery = "limit=1000"
+		response := httptest.NewRecorder()
+		handlers.NewGetLikersHandler(nil).ServeHTTP(response, helpers.AddAuthDataToRequest(request, caller))
+		AssertClientError(t, response, client_errors.TooBigLimit)
+	})
+	helpers.BaseTestServiceErrorHandling(t, func(err error, rr *httptest.ResponseRecorder) {
+		getLikers := func(values.PostId, core_values.UserId, pagination.Page) ([]profile_entities.ContextedProfile, pagination.Cursor, error) {
+			return nil, pagination.Cursor{}, err
+		}
+		handlers.NewGetLikersHandler(getLikers).ServeHTTP(rr, helpers.AddAuthDataToRequest(createRequestWithPostId(post), caller))
+	})
+}
+
 func TestCreatePost_ErrorHandling(t *testing.T) {
 	helpers.BaseTest401(t, handlers.NewCreateHandler(nil))
 	helpers.BaseTestServiceErrorHandling(t, func(err error, rr *httptest.ResponseRecorder) {
@@ -194,3 +388,160 @@ func TestDelete(t *testing.T) {
 		handlers.NewDeleteHandler(deleter).ServeHTTP(response, request)
 	})
 }
+
+func TestGetHandler(t *testing.T) {
+	helpers.BaseTest401(t, handlers.NewGetHandler(nil))
+	t.Run("happy case", func(t *testing.T) {
+		randomPost := RandomContextedPost()
+		caller := RandomAuthUser()
+		getter := func(post values.PostId, callerId core_values.UserId) (entities.ContextedPost, error) {
+			if post == randomPost.Id && callerId == caller.Id {
+				return randomPost, nil
+			}
+			panic("unexpected args")
+		}
+		request := helpers.AddAuthDataToRequest(createRequestWithPostId(randomPost.Id), caller)
+		response := httptest.NewRecorder()
+		handlers.NewGetHandler(getter).ServeHTTP(response, request)
+		AssertJSONData(t, response, responses.NewPostResponse(randomPost))
+	})
+	t.Run("error case - post id is not provided", func(t *testing.T) {
+		request := helpers.AddAuthDataToRequest(helpers.CreateRequest(nil), RandomAuthUser())
+		response := httptest.NewRecorder()
+		handlers.NewGetHandler(nil).ServeHTTP(response, request)
+		AssertClientError(t, response, client_errors.IdNotProvided)
+	})
+	helpers.BaseTestServiceErrorHandling(t, func(err error, rr *httptest.ResponseRecorder) {
+		getter := func(values.PostId, core_values.UserId) (entities.ContextedPost, error) {
+			return entities.ContextedPost{}, err
+		}
+		request := helpers.AddAuthDataToRequest(createRequestWithPostId("42"), RandomAuthUser())
+		handlers.NewGetHandler(getter).ServeHTTP(rr, request)
+	})
+}
+
+func TestUpdateHandler(t *testing.T) {
+	createRequest := func(post values.PostId, text string, imageFields map[string]string, imageFiles map[string][]byte) *http.Request {
+		body := bytes.NewBuffer(nil)
+		writer := multipart.NewWriter(body)
+		writer.WriteField("text", text)
+		for field, value := range imageFields {
+			writer.WriteField(field, value)
+		}
+		for field, file := range imageFiles {
+			fw, _ := writer.CreateFormFile(field, RandomString())
+			fw.Write(file)
+		}
+		writer.Close()
+
+		request := createRequestWithPostId(post)
+		request.Body = io.NopCloser(body)
+		request.Header.Set("Content-Type", writer.FormDataContentType())
+		return request
+	}
+	post := RandomId()
+	caller := RandomAuthUser()
+
+	helpers.BaseTest401(t, handlers.NewUpdateHandler(nil))
+	t.Run("happy case", func(t *testing.T) {
+		newImage := RandomFileData()
+		wantUpdate := values.PostUpdateData{
+			Text: RandomString(),
+			Images: []values.PostImageUpdate{
+				{Index: 1, OldIndex: 3},
+				{Index: 2, File: newImage},
+				{Index: 3, OldIndex: 1},
+			},
+		}
+		updatedPost := RandomContextedPost()
+		updater := func(postId values.PostId, callerId core_values.UserId, upd values.PostUpdateData) (entities.ContextedPost, error) {
+			if postId == post && callerId == caller.Id && reflect.DeepEqual(upd, wantUpdate) {
+				return updatedPost, nil
+			}
+			panic(fmt.Sprintf("unexpected args: upd = %+v", upd))
+		}
+		fields := map[string]string{"image_1": "3", "image_3": "1"}
+		files := map[string][]byte{"image_2": newImage.Value()}
+		request := helpers.AddAuthDataToRequest(createRequest(post, wantUpdate.Text, fields, files), caller)
+		response := httptest.NewRecorder()
+		handlers.NewUpdateHandler(updater).ServeHTTP(response, request)
+		AssertJSONData(t, response, responses.NewPostResponse(updatedPost))
+	})
+	t.Run("error case - post id is not provided", func(t *testing.T) {
+		request := helpers.AddAuthDataToRequest(helpers.CreateRequest(nil), caller)
+		response := httptest.NewRecorder()
+		handlers.NewUpdateHandler(nil).ServeHTTP(response, request)
+		AssertClientError(t, response, client_errors.IdNotProvided)
+	})
+	t.Run("error case - image index is not an integer", func(t *testing.T) {
+		fields := map[string]string{"image_1": "abc"}
+		request := helpers.AddAuthDataToRequest(createRequest(post, RandomString(), fields, nil), caller)
+		response := httptest.NewRecorder()
+		handlers.NewUpdateHandler(nil).ServeHTTP(response, request)
+		AssertClientError(t, response, client_errors.InvalidImageIndex)
+	})
+	helpers.BaseTestServiceErrorHandling(t, func(err error, rr *httptest.ResponseRecorder) {
+		updater := func(values.PostId, core_values.UserId, values.PostUpdateData) (entities.ContextedPost, error) {
+			return entities.ContextedPost{}, err
+		}
+		request := helpers.AddAuthDataToRequest(createRequest(post, RandomString(), nil, nil), caller)
+		handlers.NewUpdateHandler(updater).ServeHTTP(rr, request)
+	})
+}
+
+func TestGetTagPostsHandler(t *testing.T) {
+	caller := RandomAuthUser()
+	createRequestWithTag := func(tag, query string) *http.Request {
+		request := helpers.AddAuthDataToRequest(httptest.NewRequest(http.MethodGet, "/handler-should-not-care"+query, nil), caller)
+		ctx := chi.NewRouteContext()
+		ctx.URLParams.Add("tag", tag)
+		return request.WithContext(context.WithValue(request.Context(), chi.RouteCtxKey, ctx))
+	}
+	helpers.BaseTest401(t, handlers.NewGetTagPostsHandler(nil))
+	t.Run("happy case", func(t *testing.T) {
+		tag := RandomString()
+		posts := []entities.ContextedPost{RandomContextedPost()}
+		wantPage := pagination.Page{Limit: 1}
+		getter := func(gotTag string, callerId core_values.UserId, page pagination.Page) ([]entities.ContextedPost, error) {
+			if gotTag == tag && callerId == caller.Id && page == wantPage {
+				return posts, nil
+			}
+			panic("unexpected args")
+		}
+		response := httptest.NewRecorder()
+		handlers.NewGetTagPostsHandler(getter).ServeHTTP(response, createRequestWithTag(tag, "?limit=1"))
+		AssertJSONData(t, response, responses.NewPostListResponse(posts, wantPage))
+	})
+	t.Run("error case - cursor is invalid", func(t *testing.T) {
+		response := httptest.NewRecorder()
+		handlers.NewGetTagPostsHandler(nil).ServeHTTP(response, createRequestWithTag(RandomString(), "?cursor=!!!"))
+		AssertClientError(t, response, client_errors.InvalidCursor)
+	})
+	helpers.BaseTestServiceErrorHandling(t, func(err error, rr *httptest.ResponseRecorder) {
+		getter := func(string, core_values.UserId, pagination.Page) ([]entities.ContextedPost, error) {
+			return nil, err
+		}
+		handlers.NewGetTagPostsHandler(getter).ServeHTTP(rr, createRequestWithTag(RandomString(), ""))
+	})
+}
+
+func TestGetTrendingTagsHandler(t *testing.T) {
+	helpers.BaseTest401(t, handlers.NewGetTrendingTagsHandler(nil))
+	t.Run("happy case", func(t *testing.T) {
+		tags := []values.TrendingTag{{Tag: RandomString(), Uses: RandomInt()}, {Tag: RandomString(), Uses: RandomInt()}}
+		getter := func() ([]values.TrendingTag, error) {
+			return tags, nil
+		}
+		request := helpers.AddAuthDataToRequest(helpers.CreateRequest(nil), RandomAuthUser())
+		response := httptest.NewRecorder()
+		handlers.NewGetTrendingTagsHandler(getter).ServeHTTP(response, request)
+		AssertJSONData(t, response, responses.NewTrendingTagsResponse(tags))
+	})
+	helpers.BaseTestServiceErrorHandling(t, func(err error, rr *httptest.ResponseRecorder) {
+		getter := func() ([]values.TrendingTag, error) {
+			return nil, err
+		}
+		request := helpers.AddAuthDataToRequest(helpers.CreateRequest(nil), RandomAuthUser())
+		handlers.NewGetTrendingTagsHandler(getter).ServeHTTP(rr, request)
+	})
+}
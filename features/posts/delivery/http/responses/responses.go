@@ -1,6 +1,9 @@
 package responses
 
 import (
+	likeable_values "github.com/k0marov/go-socnet/core/abstract/likeable/values"
+	mentionable_responses "github.com/k0marov/go-socnet/core/abstract/mentionable/responses"
+	"github.com/k0marov/go-socnet/core/general/pagination"
 	"github.com/k0marov/go-socnet/features/posts/domain/entities"
 	"github.com/k0marov/go-socnet/features/posts/domain/values"
 	profile_responses "github.com/k0marov/go-socnet/features/profiles/delivery/http/responses"
@@ -23,36 +26,100 @@ func newPostImageListResponse(images []values.PostImage) []PostImageResponse {
 	return respList
 }
 
-type PostResponse struct {
+type CommentPreviewResponse struct {
 	Id        string                            `json:"id"`
 	Author    profile_responses.ProfileResponse `json:"author"`
 	Text      string                            `json:"text"`
 	CreatedAt int64                             `json:"created_at"`
-	Images    []PostImageResponse               `json:"images"`
-	Likes     int                               `json:"likes"`
-	IsLiked   bool                              `json:"is_liked"`
-	IsMine    bool                              `json:"is_mine"`
+}
+
+func newCommentPreviewListResponse(previews []entities.CommentPreview) []CommentPreviewResponse {
+	if len(previews) == 0 {
+		return nil
+	}
+	respList := make([]CommentPreviewResponse, 0, len(previews))
+	for _, preview := range previews {
+		resp := CommentPreviewResponse{
+			Id:        preview.Id,
+			Author:    profile_responses.NewProfileResponse(preview.Author),
+			Text:      preview.Text,
+			CreatedAt: preview.CreatedAt,
+		}
+		respList = append(respList, resp)
+	}
+	return respList
+}
+
+type PostResponse struct {
+	Id               string                                  `json:"id"`
+	Author           profile_responses.ProfileResponse       `json:"author"`
+	Text             string                                  `json:"text"`
+	CreatedAt        int64                                   `json:"created_at"`
+	EditedAt         int64                                   `json:"edited_at,omitempty"`
+	Images           []PostImageResponse                     `json:"images"`
+	Likes            int                                     `json:"likes"`
+	Reactions        likeable_values.ReactionCounts          `json:"reactions"`
+	IsLiked          bool                                    `json:"is_liked"`
+	Reaction         likeable_values.Reaction                `json:"reaction,omitempty"`
+	IsMine           bool                                    `json:"is_mine"`
+	CommentsCount    int                                     `json:"comments_count"`
+	CommentsDisabled bool                                    `json:"comments_disabled"`
+	LatestComments   []CommentPreviewResponse                `json:"latest_comments,omitempty"`
+	Mentions         []mentionable_responses.MentionResponse `json:"mentions"`
 }
 type PostsResponse struct {
-	Posts []PostResponse `json:"posts"`
+	Posts      []PostResponse `json:"posts"`
+	NextCursor string         `json:"next_cursor"`
+}
+
+func NewPostResponse(post entities.ContextedPost) PostResponse {
+	return PostResponse{
+		Id:               post.Id,
+		Author:           profile_responses.NewProfileResponse(post.Author),
+		Text:             post.Text,
+		CreatedAt:        post.CreatedAt,
+		EditedAt:         post.EditedAt,
+		Images:           newPostImageListResponse(post.Images),
+		Likes:            post.Likes,
+		Reactions:        post.Reactions,
+		IsLiked:          post.IsLiked,
+		Reaction:         post.Reaction,
+		IsMine:           post.IsMine,
+		CommentsCount:    post.CommentsCount,
+		CommentsDisabled: post.CommentsDisabled,
+		LatestComments:   newCommentPreviewListResponse(post.LatestComments),
+		Mentions:         mentionable_responses.NewMentionsResponse(post.Mentions),
+	}
 }
 
-func NewPostListResponse(posts []entities.ContextedPost) PostsResponse {
+func NewPostResponses(posts []entities.ContextedPost) []PostResponse {
 	postResponses := make([]PostResponse, 0)
 	for _, post := range posts {
-		resp := PostResponse{
-			Id:        post.Id,
-			Author:    profile_responses.NewProfileResponse(post.Author),
-			Text:      post.Text,
-			CreatedAt: post.CreatedAt,
-			Images:    newPostImageListResponse(post.Images),
-			Likes:     post.Likes,
-			IsLiked:   post.IsLiked,
-			IsMine:    post.IsMine,
-		}
-		postResponses = append(postResponses, resp)
+		postResponses = append(postResponses, NewPostResponse(post))
 	}
+	return postResponses
+}
+
+func NewPostListResponse(posts []entities.ContextedPost, page pagination.Page) PostsResponse {
 	return PostsResponse{
-		Posts: postResponses,
+		Posts:      NewPostResponses(posts),
+		NextCursor: pagination.NextCursor(posts, page, entities.ContextedPost.Cursor).Encode(),
+	}
+}
+
+type TrendingTagResponse struct {
+	Tag  string `json:"tag"`
+	Uses int    `json:"uses"`
+}
+
+type TrendingTagsResponse struct {
+	Tags []TrendingTagResponse `json:"tags"`
+}
+
+func NewTrendingTagsResponse(tags []values.TrendingTag) TrendingTagsResponse {
+	respList := make([]TrendingTagResponse, 0, len(tags))
+	for _, tag := range tags {
+		respList = append(respList, TrendingTagResponse{Tag: tag.Tag, Uses: tag.Uses})
 	}
+	return TrendingTagsResponse{Tags: respList}
 }
@@ -5,11 +5,26 @@ import (
 	"net/http"
 )
 
-func NewPostsRouter(create, getPosts, deletePost, toggleLike http.HandlerFunc) func(chi.Router) {
+func NewPostsRouter(create, getPosts, getPost, update, deletePost, toggleLike, like, unlike, setReaction, removeReaction, getLikers, setCommentsDisabled http.HandlerFunc) func(chi.Router) {
 	return func(r chi.Router) {
 		r.Post("/", create)
 		r.Get("/", getPosts)
+		r.Get("/{id}", getPost)
+		r.Put("/{id}", update)
 		r.Delete("/{id}", deletePost)
 		r.Post("/{id}/toggle-like", toggleLike)
+		r.Put("/{id}/like", like)
+		r.Delete("/{id}/like", unlike)
+		r.Put("/{id}/reaction", setReaction)
+		r.Delete("/{id}/reaction", removeReaction)
+		r.Get("/{id}/likes", getLikers)
+		r.Put("/{id}/comments-disabled", setCommentsDisabled)
+	}
+}
+
+func NewTagsRouter(getTrending, getTagPosts http.HandlerFunc) func(chi.Router) {
+	return func(r chi.Router) {
+		r.Get("/trending", getTrending)
+		r.Get("/{tag}/posts", getTagPosts)
 	}
 }
@@ -1,19 +1,26 @@
 package contexters
 
 import (
+	"fmt"
 	likeable_contexters "github.com/k0marov/go-socnet/core/abstract/ownable_likeable/contexters"
 	"github.com/k0marov/go-socnet/core/general/core_err"
 	"github.com/k0marov/go-socnet/core/general/core_values"
 
-	"github.com/k0marov/go-socnet/core/helpers"
 	"github.com/k0marov/go-socnet/features/posts/domain/entities"
+	"github.com/k0marov/go-socnet/features/posts/domain/values"
 	profile_service "github.com/k0marov/go-socnet/features/profiles/domain/service"
 )
 
+// CommentsPreviewLength is the number of the latest comments shown together with a post
+const CommentsPreviewLength = 2
+
 type PostContextAdder func(post entities.Post, caller core_values.UserId) (entities.ContextedPost, error)
 type PostListContextAdder func(posts []entities.Post, caller core_values.UserId) ([]entities.ContextedPost, error)
 
-func NewPostContextAdder(getProfile profile_service.ProfileGetter, getContext likeable_contexters.OwnLikeContextGetter) PostContextAdder {
+// CommentPreviewsGetter returns up to count latest comments of each post, newest first
+type CommentPreviewsGetter func(posts []values.PostId, count int, caller core_values.UserId) (map[values.PostId][]entities.CommentPreview, error)
+
+func NewPostContextAdder(getProfile profile_service.ProfileGetter, getContext likeable_contexters.OwnLikeContextGetter, getPreviews CommentPreviewsGetter) PostContextAdder {
 	return func(post entities.Post, caller core_values.UserId) (entities.ContextedPost, error) {
 		author, err := getProfile(post.PostModel.AuthorId, caller)
 		if err != nil {
@@ -23,19 +30,63 @@ func NewPostContextAdder(getProfile profile_service.ProfileGetter, getContext li
 		if err != nil {
 			return entities.ContextedPost{}, core_err.Rethrow("getting context of post", err)
 		}
+		previews, err := getPreviews([]values.PostId{post.Id}, CommentsPreviewLength, caller)
+		if err != nil {
+			return entities.ContextedPost{}, core_err.Rethrow("getting latest comments of post", err)
+		}
 		ctxPost := entities.ContextedPost{
 			Post:           post,
 			OwnLikeContext: context,
 			Author:         author,
+			LatestComments: previews[post.Id],
 		}
 		return ctxPost, nil
 	}
 }
 
-func NewPostListContextAdder(addContext PostContextAdder) PostListContextAdder {
+// NewPostListContextAdder gets the authors, like contexts and latest comments of all posts at once
+func NewPostListContextAdder(getAuthors profile_service.ProfilesGetter, getContexts likeable_contexters.OwnLikeContextsGetter, getPreviews CommentPreviewsGetter) PostListContextAdder {
 	return func(posts []entities.Post, caller core_values.UserId) ([]entities.ContextedPost, error) {
-		return helpers.MapForEachWithErr(posts, func(post entities.Post) (entities.ContextedPost, error) {
-			return addContext(post, caller)
-		})
+		if len(posts) == 0 {
+			return []entities.ContextedPost{}, nil
+		}
+		var authorIds []core_values.UserId
+		isAuthor := map[core_values.UserId]bool{}
+		owners := map[string]core_values.UserId{}
+		postIds := make([]values.PostId, len(posts))
+		for i, post := range posts {
+			postIds[i] = post.Id
+			owners[post.Id] = post.AuthorId
+			if !isAuthor[post.AuthorId] {
+				isAuthor[post.AuthorId] = true
+				authorIds = append(authorIds, post.AuthorId)
+			}
+		}
+		authors, err := getAuthors(authorIds, caller)
+		if err != nil {
+			return []entities.ContextedPost{}, core_err.Rethrow("getting authors of posts", err)
+		}
+		contexts, err := getContexts(owners, caller)
+		if err != nil {
+			return []entities.ContextedPost{}, core_err.Rethrow("getting contexts of posts", err)
+		}
+		previews, err := getPreviews(postIds, CommentsPreviewLength, caller)
+		if err != nil {
+			return []entities.ContextedPost{}, core_err.Rethrow("getting latest comments of posts", err)
+		}
+		ctxPosts := make([]entities.ContextedPost, len(posts))
+		for i, post := range posts {
+			author, ok := authors[post.AuthorId]
+			if !ok {
+				return []entities.ContextedPost{}, fmt.Errorf("getting author %v of post %v: %w", post.AuthorId, post.Id, core_err.ErrNotFound)
+			}
+			ctxPosts[i] = entities.ContextedPost{
+				Post:           post,
+				OwnLikeContext: contexts[post.Id],
+				Author:         author,
+				LatestComments: previews[post.Id],
+			}
+		}
+		return ctxPosts, nil
 	}
 }
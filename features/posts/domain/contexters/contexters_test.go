@@ -1,13 +1,17 @@
 package contexters_test
 
 import (
+	"errors"
 	likeable_contexters "github.com/k0marov/go-socnet/core/abstract/ownable_likeable/contexters"
+	"github.com/k0marov/go-socnet/core/general/core_err"
 	"github.com/k0marov/go-socnet/core/general/core_values"
 	. "github.com/k0marov/go-socnet/core/helpers/test_helpers"
+	"reflect"
 	"testing"
 
 	"github.com/k0marov/go-socnet/features/posts/domain/contexters"
 	"github.com/k0marov/go-socnet/features/posts/domain/entities"
+	"github.com/k0marov/go-socnet/features/posts/domain/values"
 	profile_entities "github.com/k0marov/go-socnet/features/profiles/domain/entities"
 )
 
@@ -16,6 +20,7 @@ func TestPostContextAdder(t *testing.T) {
 	caller := RandomId()
 	author := RandomContextedProfile()
 	ctx := RandomLikeableContext()
+	previews := []entities.CommentPreview{RandomCommentPreview(), RandomCommentPreview()}
 
 	getProfile := func(id, callerId core_values.UserId) (profile_entities.ContextedProfile, error) {
 		if id == post.PostModel.AuthorId && callerId == caller {
@@ -27,7 +32,7 @@ func TestPostContextAdder(t *testing.T) {
 		getProfile := func(id, callerId core_values.UserId) (profile_entities.ContextedProfile, error) {
 			return profile_entities.ContextedProfile{}, RandomError()
 		}
-		_, err := contexters.NewPostContextAdder(getProfile, nil)(post, caller)
+		_, err := contexters.NewPostContextAdder(getProfile, nil, nil)(post, caller)
 		AssertSomeError(t, err)
 	})
 	getContext := func(target string, owner, callerId core_values.UserId) (likeable_contexters.OwnLikeContext, error) {
@@ -40,7 +45,20 @@ func TestPostContextAdder(t *testing.T) {
 		getContext := func(string, core_values.UserId, core_values.UserId) (likeable_contexters.OwnLikeContext, error) {
 			return likeable_contexters.OwnLikeContext{}, RandomError()
 		}
-		_, err := contexters.NewPostContextAdder(getProfile, getContext)(post, caller)
+		_, err := contexters.NewPostContextAdder(getProfile, getContext, nil)(post, caller)
+		AssertSomeError(t, err)
+	})
+	getPreviews := func(postIds []values.PostId, count int, callerId core_values.UserId) (map[values.PostId][]entities.CommentPreview, error) {
+		if reflect.DeepEqual(postIds, []values.PostId{post.Id}) && count == contexters.CommentsPreviewLength && callerId == caller {
+			return map[values.PostId][]entities.CommentPreview{post.Id: previews}, nil
+		}
+		panic("unexpected args")
+	}
+	t.Run("error case - getting comment previews throws", func(t *testing.T) {
+		getPreviews := func([]values.PostId, int, core_values.UserId) (map[values.PostId][]entities.CommentPreview, error) {
+			return nil, RandomError()
+		}
+		_, err := contexters.NewPostContextAdder(getProfile, getContext, getPreviews)(post, caller)
 		AssertSomeError(t, err)
 	})
 	t.Run("happy case", func(t *testing.T) {
@@ -48,9 +66,90 @@ func TestPostContextAdder(t *testing.T) {
 			Post:           post,
 			Author:         author,
 			OwnLikeContext: ctx,
+			LatestComments: previews,
 		}
-		gotPost, err := contexters.NewPostContextAdder(getProfile, getContext)(post, caller)
+		gotPost, err := contexters.NewPostContextAdder(getProfile, getContext, getPreviews)(post, caller)
 		AssertNoError(t, err)
 		Assert(t, gotPost, wantPost, "returned post")
 	})
 }
+
+func TestPostListContextAdder(t *testing.T) {
+	author1, author2 := RandomContextedProfile(), RandomContextedProfile()
+	post1, post2, post3 := RandomPost(), RandomPost(), RandomPost()
+	post1.AuthorId, post2.AuthorId, post3.AuthorId = author1.Id, author2.Id, author1.Id
+	posts := []entities.Post{post1, post2, post3}
+	caller := RandomId()
+	contexts := map[string]likeable_contexters.OwnLikeContext{
+		post1.Id: RandomLikeableContext(),
+		post2.Id: RandomLikeableContext(),
+		post3.Id: RandomLikeableContext(),
+	}
+	previews := map[values.PostId][]entities.CommentPreview{ // post2 has no comments
+		post1.Id: {RandomCommentPreview(), RandomCommentPreview()},
+		post3.Id: {RandomCommentPreview()},
+	}
+
+	getAuthors := func(ids []core_values.UserId, callerId core_values.UserId) (map[core_values.UserId]profile_entities.ContextedProfile, error) {
+		if reflect.DeepEqual(ids, []core_values.UserId{author1.Id, author2.Id}) && callerId == caller {
+			return map[core_values.UserId]profile_entities.ContextedProfile{author1.Id: author1, author2.Id: author2}, nil
+		}
+		panic("unexpected args")
+	}
+	t.Run("error case - getting authors throws", func(t *testing.T) {
+		getAuthors := func([]core_values.UserId, core_values.UserId) (map[core_values.UserId]profile_entities.ContextedProfile, error) {
+			return nil, RandomError()
+		}
+		_, err := contexters.NewPostListContextAdder(getAuthors, nil, nil)(posts, caller)
+		AssertSomeError(t, err)
+	})
+	getContexts := func(owners map[string]core_values.UserId, callerId core_values.UserId) (map[string]likeable_contexters.OwnLikeContext, error) {
+		wantOwners := map[string]core_values.UserId{post1.Id: author1.Id, post2.Id: author2.Id, post3.Id: author1.Id}
+		if reflect.DeepEqual(owners, wantOwners) && callerId == caller {
+			return contexts, nil
+		}
+		panic("unexpected args")
+	}
+	t.Run("error case - getting contexts throws", func(t *testing.T) {
+		getContexts := func(map[string]core_values.UserId, core_values.UserId) (map[string]likeable_contexters.OwnLikeContext, error) {
+			return nil, RandomError()
+		}
+		_, err := contexters.NewPostListContextAdder(getAuthors, getContexts, nil)(posts, caller)
+		AssertSomeError(t, err)
+	})
+	getPreviews := func(postIds []values.PostId, count int, callerId core_values.UserId) (map[values.PostId][]entities.CommentPreview, error) {
+		if reflect.DeepEqual(postIds, []values.PostId{post1.Id, post2.Id, post3.Id}) && count == contexters.CommentsPreviewLength && callerId == caller {
+			return previews, nil
+		}
+		panic("unexpected args")
+	}
+	t.Run("error case - getting comment previews throws", func(t *testing.T) {
+		getPreviews := func([]values.PostId, int, core_values.UserId) (map[values.PostId][]entities.CommentPreview, error) {
+			return nil, RandomError()
+		}
+		_, err := contexters.NewPostListContextAdder(getAuthors, getContexts, getPreviews)(posts, caller)
+		AssertSomeError(t, err)
+	})
+	t.Run("error case - author does not exist", func(t *testing.T) {
+		getAuthors := func([]core_values.UserId, core_values.UserId) (map[core_values.UserId]profile_entities.ContextedProfile, error) {
+			return map[core_values.UserId]profile_entities.ContextedProfile{author1.Id: author1}, nil
+		}
+		_, err := contexters.NewPostListContextAdder(getAuthors, getContexts, getPreviews)(posts, caller)
+		Assert(t, errors.Is(err, core_err.ErrNotFound), true, "returned error is ErrNotFound")
+	})
+	t.Run("happy case", func(t *testing.T) {
+		gotPosts, err := contexters.NewPostListContextAdder(getAuthors, getContexts, getPreviews)(posts, caller)
+		AssertNoError(t, err)
+		wantPosts := []entities.ContextedPost{
+			{Post: post1, Author: author1, OwnLikeContext: contexts[post1.Id], LatestComments: previews[post1.Id]},
+			{Post: post2, Author: author2, OwnLikeContext: contexts[post2.Id]},
+			{Post: post3, Author: author1, OwnLikeContext: contexts[post3.Id], LatestComments: previews[post3.Id]},
+		}
+		Assert(t, gotPosts, wantPosts, "returned posts")
+	})
+	t.Run("happy case - no posts", func(t *testing.T) {
+		gotPosts, err := contexters.NewPostListContextAdder(nil, nil, nil)([]entities.Post{}, caller)
+		AssertNoError(t, err)
+		Assert(t, len(gotPosts), 0, "number of returned posts")
+	})
+}
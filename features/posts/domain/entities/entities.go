@@ -1,7 +1,10 @@
 package entities
 
 import (
+	likeable_values "github.com/k0marov/go-socnet/core/abstract/likeable/values"
+	mentionable_values "github.com/k0marov/go-socnet/core/abstract/mentionable/values"
 	"github.com/k0marov/go-socnet/core/abstract/ownable_likeable/contexters"
+	"github.com/k0marov/go-socnet/core/general/pagination"
 	"github.com/k0marov/go-socnet/core/general/static_store"
 	"github.com/k0marov/go-socnet/features/posts/domain/models"
 	"github.com/k0marov/go-socnet/features/posts/domain/values"
@@ -10,20 +13,36 @@ import (
 
 type Post struct {
 	models.PostModel
-	Images []values.PostImage
-	Likes  int
+	Images        []values.PostImage
+	Likes         int
+	Reactions     likeable_values.ReactionCounts
+	CommentsCount int
+	Mentions      []mentionable_values.Mention
+}
+
+func (p Post) Cursor() pagination.Cursor {
+	return pagination.Cursor{CreatedAt: p.CreatedAt, Id: p.Id}
 }
 
 type ContextedPost struct {
 	Post
 	contexters.OwnLikeContext
-	Author profile_entities.ContextedProfile
+	Author         profile_entities.ContextedProfile
+	LatestComments []CommentPreview
+}
+
+// CommentPreview is a short version of a comment which is shown together with the post
+type CommentPreview struct {
+	Id        string
+	Author    profile_entities.ContextedProfile
+	Text      string
+	CreatedAt int64
 }
 
-func ImagePathsToUrls(models []models.PostImageModel) (images []values.PostImage) {
+func ImagePathsToUrls(models []models.PostImageModel, toURL static_store.PathToURLConverter) (images []values.PostImage) {
 	for _, model := range models {
 		images = append(images, values.PostImage{
-			URL:   static_store.PathToURL(model.Path),
+			URL:   toURL(model.Path),
 			Index: model.Index,
 		})
 	}
@@ -13,15 +13,23 @@ type PostToCreate struct {
 	CreatedAt time.Time
 }
 
+type PostToUpdate struct {
+	Text     string
+	EditedAt time.Time
+	Images   []PostImageModel
+}
+
 type PostImageModel struct {
 	Index int                    `db:"ind"`
 	Path  core_values.StaticPath `db:"path"`
 }
 
 type PostModel struct {
-	Id        values.PostId      `db:"id"`
-	AuthorId  core_values.UserId `db:"owner_id"`
-	Text      string             `db:"textContent"`
-	CreatedAt int64              `db:"createdAt"`
-	Images    []PostImageModel
+	Id               values.PostId      `db:"id"`
+	AuthorId         core_values.UserId `db:"owner_id"`
+	Text             string             `db:"textContent"`
+	CreatedAt        int64              `db:"createdAt"`
+	EditedAt         int64              `db:"editedAt"`
+	CommentsDisabled bool               `db:"commentsDisabled"`
+	Images           []PostImageModel
 }
@@ -1,11 +1,18 @@
 package service
 
 import (
+	"context"
+	"errors"
+	likeable_values "github.com/k0marov/go-socnet/core/abstract/likeable/values"
+	"github.com/k0marov/go-socnet/core/abstract/mentionable"
+	mentionable_values "github.com/k0marov/go-socnet/core/abstract/mentionable/values"
 	"github.com/k0marov/go-socnet/core/abstract/ownable"
 	"github.com/k0marov/go-socnet/core/abstract/ownable_likeable"
 	"github.com/k0marov/go-socnet/core/general/client_errors"
 	"github.com/k0marov/go-socnet/core/general/core_err"
 	"github.com/k0marov/go-socnet/core/general/core_values"
+	"github.com/k0marov/go-socnet/core/general/events"
+	"github.com/k0marov/go-socnet/core/general/pagination"
 	"time"
 
 	"github.com/k0marov/go-socnet/features/posts/domain/contexters"
@@ -15,13 +22,26 @@ import (
 
 	"github.com/k0marov/go-socnet/features/posts/domain/store"
 	"github.com/k0marov/go-socnet/features/posts/domain/values"
+	profile_entities "github.com/k0marov/go-socnet/features/profiles/domain/entities"
+	profile_service "github.com/k0marov/go-socnet/features/profiles/domain/service"
 )
 
 type (
-	PostDeleter     func(post values.PostId, caller core_values.UserId) error
-	PostLikeToggler func(values.PostId, core_values.UserId) error
-	PostCreator     func(values.NewPostData) error
-	PostsGetter     func(fromAuthor, caller core_values.UserId) ([]entities.ContextedPost, error)
+	PostDeleter         func(post values.PostId, caller core_values.UserId) error
+	PostLikeToggler     func(values.PostId, core_values.UserId) error
+	PostLiker           func(values.PostId, core_values.UserId) error
+	PostUnliker         func(values.PostId, core_values.UserId) error
+	PostReactionSetter  func(values.PostId, core_values.UserId, likeable_values.Reaction) error
+	PostReactionRemover func(values.PostId, core_values.UserId) error
+	PostLikersGetter    func(post values.PostId, caller core_values.UserId, page pagination.Page) ([]profile_entities.ContextedProfile, pagination.Cursor, error)
+	PostCreator         func(values.NewPostData) error
+	PostsGetter         func(fromAuthor, caller core_values.UserId, page pagination.Page) ([]entities.ContextedPost, error)
+	PostGetter          func(post values.PostId, caller core_values.UserId) (entities.ContextedPost, error)
+	PostUpdater         func(post values.PostId, caller core_values.UserId, upd values.PostUpdateData) (entities.ContextedPost, error)
+	CommentsDisabler    func(post values.PostId, caller core_values.UserId, disabled bool) error
+	TagPostsGetter      func(tag string, caller core_values.UserId, page pagination.Page) ([]entities.ContextedPost, error)
+	TrendingTagsGetter  func() ([]values.TrendingTag, error)
+	TrendingTagsUpdater func(ctx context.Context) error
 )
 
 func NewPostDeleter(getAuthor ownable.OwnerGetter, deletePost store.PostDeleter) PostDeleter {
@@ -45,23 +65,46 @@ func NewPostLikeToggler(safeToggleLike ownable_likeable.SafeLikeToggler) PostLik
 	return PostLikeToggler(safeToggleLike)
 }
 
-func NewPostCreator(validate validators.PostValidator, createPost store.PostCreator) PostCreator {
+func NewPostLiker(safeLike ownable_likeable.SafeLiker) PostLiker {
+	return PostLiker(safeLike)
+}
+
+func NewPostUnliker(safeUnlike ownable_likeable.SafeUnliker) PostUnliker {
+	return PostUnliker(safeUnlike)
+}
+
+func NewPostReactionSetter(safeSetReaction ownable_likeable.SafeReactionSetter) PostReactionSetter {
+	return PostReactionSetter(safeSetReaction)
+}
+
+// NewPostReactionRemover returns a remover of the caller's reaction of any kind, which is the same as unliking
+func NewPostReactionRemover(safeUnlike ownable_likeable.SafeUnliker) PostReactionRemover {
+	return PostReactionRemover(safeUnlike)
+}
+
+func NewPostCreator(validate validators.PostValidator, resolveMentions mentionable.MentionsResolver, createPost store.PostCreator, publish events.Publisher) PostCreator {
 	return func(newPost values.NewPostData) error {
 		clientError, ok := validate(newPost)
 		if !ok {
 			return clientError
 		}
-		err := createPost(newPost, time.Now())
+		mentions, err := resolveMentions(newPost.Text)
+		if err != nil {
+			return core_err.Rethrow("resolving mentions in the post text", err)
+		}
+		createdAt := time.Now()
+		id, err := createPost(newPost, mentions, values.FindTags(newPost.Text), createdAt)
 		if err != nil {
 			return core_err.Rethrow("creating a post in store", err)
 		}
+		publishMentions(publish, id, newPost.Author, mentions, nil, createdAt)
 		return nil
 	}
 }
 
 func NewPostsGetter(getPosts store.PostsGetter, addContext contexters.PostListContextAdder) PostsGetter {
-	return func(authorId, caller core_values.UserId) ([]entities.ContextedPost, error) {
-		posts, err := getPosts(authorId)
+	return func(authorId, caller core_values.UserId, page pagination.Page) ([]entities.ContextedPost, error) {
+		posts, err := getPosts(authorId, page)
 		if err != nil {
 			return []entities.ContextedPost{}, core_err.Rethrow("getting posts from store", err)
 		}
@@ -72,3 +115,163 @@ func NewPostsGetter(getPosts store.PostsGetter, addContext contexters.PostListCo
 		return ctxPosts, nil
 	}
 }
+
+func NewPostGetter(getPost store.PostGetter, addContext contexters.PostContextAdder) PostGetter {
+	return func(postId values.PostId, caller core_values.UserId) (entities.ContextedPost, error) {
+		post, err := getPost(postId)
+		if err != nil {
+			if errors.Is(err, core_err.ErrNotFound) {
+				return entities.ContextedPost{}, client_errors.NotFound
+			}
+			return entities.ContextedPost{}, core_err.Rethrow("getting a post from store", err)
+		}
+		ctxPost, err := addContext(post, caller)
+		if err != nil {
+			return entities.ContextedPost{}, core_err.Rethrow("adding context to post", err)
+		}
+		return ctxPost, nil
+	}
+}
+
+func NewPostUpdater(getAuthor ownable.OwnerGetter, validate validators.PostValidator, getPost store.PostGetter, resolveMentions mentionable.MentionsResolver, updatePost store.PostUpdater, getUpdated PostGetter, publish events.Publisher) PostUpdater {
+	return func(post values.PostId, caller core_values.UserId, upd values.PostUpdateData) (entities.ContextedPost, error) {
+		author, err := getAuthor(post)
+		if err != nil {
+			if errors.Is(err, core_err.ErrNotFound) {
+				return entities.ContextedPost{}, client_errors.NotFound
+			}
+			return entities.ContextedPost{}, core_err.Rethrow("getting post author", err)
+		}
+		if author != caller {
+			return entities.ContextedPost{}, client_errors.InsufficientPermissions
+		}
+		clientError, ok := validate(values.NewPostData{Author: author, Text: upd.Text, Images: upd.NewImages()})
+		if !ok {
+			return entities.ContextedPost{}, clientError
+		}
+		oldPost, err := getPost(post)
+		if err != nil {
+			return entities.ContextedPost{}, core_err.Rethrow("getting the post that is being updated", err)
+		}
+		if !keptImagesExist(upd, oldPost.Images) {
+			return entities.ContextedPost{}, client_errors.InvalidImageIndex
+		}
+		mentions, err := resolveMentions(upd.Text)
+		if err != nil {
+			return entities.ContextedPost{}, core_err.Rethrow("resolving mentions in the updated post text", err)
+		}
+		editedAt := time.Now().UTC()
+		err = updatePost(oldPost, upd, mentions, values.FindTags(upd.Text), editedAt)
+		if err != nil {
+			return entities.ContextedPost{}, core_err.Rethrow("updating a post in store", err)
+		}
+		publishMentions(publish, post, author, mentions, oldPost.Mentions, editedAt)
+		return getUpdated(post, caller)
+	}
+}
+
+// publishMentions notifies every mentioned profile once,
+// skipping the author of the post and the profiles which were already mentioned before it was edited
+func publishMentions(publish events.Publisher, post values.PostId, author core_values.UserId, mentions, oldMentions []mentionable_values.Mention, createdAt time.Time) {
+	notified := map[core_values.UserId]bool{author: true}
+	for _, mention := range oldMentions {
+		notified[mention.Profile] = true
+	}
+	for _, mention := range mentions {
+		if notified[mention.Profile] {
+			continue
+		}
+		notified[mention.Profile] = true
+		publish(events.Event{
+			Kind:       events.Mentioned,
+			Actor:      author,
+			Recipient:  mention.Profile,
+			TargetType: events.Post,
+			TargetId:   post,
+			CreatedAt:  createdAt,
+		})
+	}
+}
+
+// keptImagesExist checks that every kept image refers to a different existing image
+func keptImagesExist(upd values.PostUpdateData, oldImages []values.PostImage) bool {
+	notKept := map[int]bool{}
+	for _, image := range oldImages {
+		notKept[image.Index] = true
+	}
+	for _, image := range upd.Images {
+		if image.IsNew() {
+			continue
+		}
+		if !notKept[image.OldIndex] {
+			return false
+		}
+		delete(notKept, image.OldIndex)
+	}
+	return true
+}
+
+func NewPostLikersGetter(getAuthor ownable.OwnerGetter, getLikers profile_service.LikersGetter) PostLikersGetter {
+	return func(post values.PostId, caller core_values.UserId, page pagination.Page) ([]profile_entities.ContextedProfile, pagination.Cursor, error) {
+		_, err := getAuthor(post) // to throw NotFound if the post doesn't exist
+		if err != nil {
+			return []profile_entities.ContextedProfile{}, pagination.Cursor{}, core_err.Rethrow("getting post author", err)
+		}
+		likers, next, err := getLikers(post, caller, page)
+		if err != nil {
+			return []profile_entities.ContextedProfile{}, pagination.Cursor{}, core_err.Rethrow("getting likers of post", err)
+		}
+		return likers, next, nil
+	}
+}
+
+// NewCommentsDisabler returns a setter of the post's commentsDisabled flag, which only the post author can change
+func NewCommentsDisabler(getAuthor ownable.OwnerGetter, setDisabled store.CommentsDisabledSetter) CommentsDisabler {
+	return func(post values.PostId, caller core_values.UserId, disabled bool) error {
+		author, err := getAuthor(post)
+		if err != nil {
+			return core_err.Rethrow("getting post author", err)
+		}
+		if author != caller {
+			return client_errors.InsufficientPermissions
+		}
+		err = setDisabled(post, disabled)
+		if err != nil {
+			return core_err.Rethrow("setting commentsDisabled of a post in store", err)
+		}
+		return nil
+	}
+}
+
+func NewTagPostsGetter(getPosts store.TagPostsGetter, addContext contexters.PostListContextAdder) TagPostsGetter {
+	return func(tag string, caller core_values.UserId, page pagination.Page) ([]entities.ContextedPost, error) {
+		normalized, ok := values.NormalizeTag(tag)
+		if !ok {
+			return []entities.ContextedPost{}, client_errors.InvalidTag
+		}
+		posts, err := getPosts(normalized, page)
+		if err != nil {
+			return []entities.ContextedPost{}, core_err.Rethrow("getting posts with a tag from store", err)
+		}
+		ctxPosts, err := addContext(posts, caller)
+		if err != nil {
+			return []entities.ContextedPost{}, core_err.Rethrow("adding context to posts", err)
+		}
+		return ctxPosts, nil
+	}
+}
+
+func NewTrendingTagsGetter(getTrending store.TrendingTagsGetter) TrendingTagsGetter {
+	return TrendingTagsGetter(getTrending)
+}
+
+// NewTrendingTagsUpdater returns an updater which ranks the tags by the number of their uses during the last window
+func NewTrendingTagsUpdater(window time.Duration, count int, updateTrending store.TrendingTagsUpdater) TrendingTagsUpdater {
+	return func(ctx context.Context) error {
+		err := updateTrending(ctx, time.Now().Add(-window), count)
+		if err != nil {
+			return core_err.Rethrow("updating trending tags in store", err)
+		}
+		return nil
+	}
+}
@@ -1,10 +1,17 @@
 package service_test
 
 import (
+	"context"
+	"fmt"
+	mentionable_values "github.com/k0marov/go-socnet/core/abstract/mentionable/values"
 	"github.com/k0marov/go-socnet/core/general/client_errors"
+	"github.com/k0marov/go-socnet/core/general/core_err"
 	"github.com/k0marov/go-socnet/core/general/core_values"
+	"github.com/k0marov/go-socnet/core/general/events"
+	"github.com/k0marov/go-socnet/core/general/pagination"
 	. "github.com/k0marov/go-socnet/core/helpers/test_helpers"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -12,6 +19,7 @@ import (
 
 	"github.com/k0marov/go-socnet/features/posts/domain/service"
 	"github.com/k0marov/go-socnet/features/posts/domain/values"
+	profile_entities "github.com/k0marov/go-socnet/features/profiles/domain/entities"
 )
 
 func TestPostsGetter(t *testing.T) {
@@ -19,18 +27,19 @@ func TestPostsGetter(t *testing.T) {
 	caller := RandomString()
 	posts := []entities.Post{RandomPost()}
 	ctxPosts := []entities.ContextedPost{RandomContextedPost()}
+	page := pagination.Page{Limit: RandomInt()}
 
-	storePostsGetter := func(authorId core_values.UserId) ([]entities.Post, error) {
-		if authorId == author {
+	storePostsGetter := func(authorId core_values.UserId, gotPage pagination.Page) ([]entities.Post, error) {
+		if authorId == author && gotPage == page {
 			return posts, nil
 		}
 		panic("unexpected args")
 	}
 	t.Run("error case - store throws an error", func(t *testing.T) {
-		storeGetter := func(core_values.UserId) ([]entities.Post, error) {
+		storeGetter := func(core_values.UserId, pagination.Page) ([]entities.Post, error) {
 			return []entities.Post{}, RandomError()
 		}
-		_, err := service.NewPostsGetter(storeGetter, nil)(author, caller)
+		_, err := service.NewPostsGetter(storeGetter, nil)(author, caller, page)
 		AssertSomeError(t, err)
 	})
 	contextAdder := func(postsList []entities.Post, callerId core_values.UserId) ([]entities.ContextedPost, error) {
@@ -43,10 +52,10 @@ func TestPostsGetter(t *testing.T) {
 		contextAdder := func([]entities.Post, core_values.UserId) ([]entities.ContextedPost, error) {
 			return nil, RandomError()
 		}
-		_, err := service.NewPostsGetter(storePostsGetter, contextAdder)(author, caller)
+		_, err := service.NewPostsGetter(storePostsGetter, contextAdder)(author, caller, page)
 		AssertSomeError(t, err)
 	})
-	gotPosts, err := service.NewPostsGetter(storePostsGetter, contextAdder)(author, caller)
+	gotPosts, err := service.NewPostsGetter(storePostsGetter, contextAdder)(author, caller, page)
 	AssertNoError(t, err)
 	Assert(t, gotPosts, ctxPosts, "returned posts")
 }
@@ -105,38 +114,435 @@ func TestPostDeleter(t *testing.T) {
 
 func TestPostCreator(t *testing.T) {
 	tNewPost := RandomNewPostData()
-	t.Run("happy case", func(t *testing.T) {
-		validator := func(newPost values.NewPostData) (client_errors.ClientError, bool) {
-			if reflect.DeepEqual(newPost, tNewPost) {
-				return client_errors.ClientError{}, true
-			}
-			panic("unexpected args")
-		}
-		storeCreator := func(newPost values.NewPostData, createdAt time.Time) error {
-			if reflect.DeepEqual(newPost, tNewPost) && TimeAlmostNow(createdAt) {
-				return nil
-			}
-			panic("unexpected args")
+	tNewPost.Text = "#Go and #golang_1, #go again and issue #42"
+	wantTags := []values.Tag{"go", "golang_1"}
+	mentions := RandomMentions()
+	validator := func(newPost values.NewPostData) (client_errors.ClientError, bool) {
+		if reflect.DeepEqual(newPost, tNewPost) {
+			return client_errors.ClientError{}, true
 		}
-		err := service.NewPostCreator(validator, storeCreator)(tNewPost)
-		AssertNoError(t, err)
-	})
+		panic("unexpected args")
+	}
 	t.Run("error case - validation fails", func(t *testing.T) {
 		wantErr := RandomClientError()
 		validator := func(values.NewPostData) (client_errors.ClientError, bool) {
 			return wantErr, false
 		}
-		err := service.NewPostCreator(validator, nil)(tNewPost)
+		err := service.NewPostCreator(validator, nil, nil, nil)(tNewPost)
 		AssertError(t, err, wantErr)
 	})
+	resolveMentions := func(text string) ([]mentionable_values.Mention, error) {
+		if text == tNewPost.Text {
+			return mentions, nil
+		}
+		panic("unexpected args")
+	}
+	t.Run("error case - resolving mentions throws", func(t *testing.T) {
+		resolveMentions := func(string) ([]mentionable_values.Mention, error) {
+			return nil, RandomError()
+		}
+		err := service.NewPostCreator(validator, resolveMentions, nil, nil)(tNewPost)
+		AssertSomeError(t, err)
+	})
 	t.Run("error case - store returns error", func(t *testing.T) {
-		validator := func(values.NewPostData) (client_errors.ClientError, bool) {
+		storeCreator := func(values.NewPostData, []mentionable_values.Mention, []values.Tag, time.Time) (values.PostId, error) {
+			return "", RandomError()
+		}
+		err := service.NewPostCreator(validator, resolveMentions, storeCreator, nil)(tNewPost)
+		AssertSomeError(t, err)
+	})
+	postId := RandomId()
+	storeCreator := func(newPost values.NewPostData, gotMentions []mentionable_values.Mention, tags []values.Tag, createdAt time.Time) (values.PostId, error) {
+		if reflect.DeepEqual(newPost, tNewPost) && reflect.DeepEqual(gotMentions, mentions) && reflect.DeepEqual(tags, wantTags) && TimeAlmostNow(createdAt) {
+			return postId, nil
+		}
+		panic("unexpected args")
+	}
+	var published []events.Event
+	publish := func(event events.Event) {
+		published = append(published, event)
+	}
+	t.Run("happy case", func(t *testing.T) {
+		published = nil
+		err := service.NewPostCreator(validator, resolveMentions, storeCreator, publish)(tNewPost)
+		AssertNoError(t, err)
+		AssertFatal(t, len(published), len(mentions), "number of published events")
+		for i, mention := range mentions {
+			want := events.Event{
+				Kind:       events.Mentioned,
+				Actor:      tNewPost.Author,
+				Recipient:  mention.Profile,
+				TargetType: events.Post,
+				TargetId:   postId,
+				CreatedAt:  published[i].CreatedAt,
+			}
+			Assert(t, published[i], want, "mention event")
+		}
+	})
+	t.Run("happy case - the author and repeated mentions are notified at most once", func(t *testing.T) {
+		published = nil
+		other := RandomId()
+		resolveMentions := func(string) ([]mentionable_values.Mention, error) {
+			return []mentionable_values.Mention{{Profile: tNewPost.Author}, {Profile: other}, {Profile: other}}, nil
+		}
+		storeCreator := func(values.NewPostData, []mentionable_values.Mention, []values.Tag, time.Time) (values.PostId, error) {
+			return postId, nil
+		}
+		err := service.NewPostCreator(validator, resolveMentions, storeCreator, publish)(tNewPost)
+		AssertNoError(t, err)
+		AssertFatal(t, len(published), 1, "number of published events")
+		Assert(t, published[0].Recipient, other, "recipient of the only event")
+	})
+}
+
+func TestPostGetter(t *testing.T) {
+	postId := RandomId()
+	caller := RandomId()
+	post := RandomPost()
+	ctxPost := RandomContextedPost()
+
+	getPost := func(id values.PostId) (entities.Post, error) {
+		if id == postId {
+			return post, nil
+		}
+		panic("unexpected args")
+	}
+	t.Run("error case - post is not found", func(t *testing.T) {
+		getPost := func(values.PostId) (entities.Post, error) {
+			return entities.Post{}, fmt.Errorf("wrapped: %w", core_err.ErrNotFound)
+		}
+		_, err := service.NewPostGetter(getPost, nil)(postId, caller)
+		AssertError(t, err, client_errors.NotFound)
+	})
+	t.Run("error case - store throws an error", func(t *testing.T) {
+		getPost := func(values.PostId) (entities.Post, error) {
+			return entities.Post{}, RandomError()
+		}
+		_, err := service.NewPostGetter(getPost, nil)(postId, caller)
+		AssertSomeError(t, err)
+	})
+	addContext := func(gotPost entities.Post, callerId core_values.UserId) (entities.ContextedPost, error) {
+		if reflect.DeepEqual(gotPost, post) && callerId == caller {
+			return ctxPost, nil
+		}
+		panic("unexpected args")
+	}
+	t.Run("error case - adding context throws an error", func(t *testing.T) {
+		addContext := func(entities.Post, core_values.UserId) (entities.ContextedPost, error) {
+			return entities.ContextedPost{}, RandomError()
+		}
+		_, err := service.NewPostGetter(getPost, addContext)(postId, caller)
+		AssertSomeError(t, err)
+	})
+	gotPost, err := service.NewPostGetter(getPost, addContext)(postId, caller)
+	AssertNoError(t, err)
+	Assert(t, gotPost, ctxPost, "returned post")
+}
+
+func TestPostUpdater(t *testing.T) {
+	post := RandomId()
+	caller := RandomId()
+	oldPost := RandomPost() // has images with indices 1, 2 and 3
+	newImage := RandomFileData()
+	upd := values.PostUpdateData{
+		Text: RandomString() + " #Edited",
+		Images: []values.PostImageUpdate{
+			{Index: 1, OldIndex: 3},
+			{Index: 2, File: newImage},
+		},
+	}
+	updatedPost := RandomContextedPost()
+
+	getAuthor := func(postId values.PostId) (core_values.UserId, error) {
+		if postId == post {
+			return caller, nil
+		}
+		panic("unexpected args")
+	}
+	t.Run("error case - getting author throws", func(t *testing.T) {
+		getAuthor := func(values.PostId) (core_values.UserId, error) {
+			return "", RandomError()
+		}
+		_, err := service.NewPostUpdater(getAuthor, nil, nil, nil, nil, nil, nil)(post, caller, upd)
+		AssertSomeError(t, err)
+	})
+	t.Run("error case - post is not found", func(t *testing.T) {
+		getAuthor := func(values.PostId) (core_values.UserId, error) {
+			return "", core_err.Rethrow("getting the owner", core_err.ErrNotFound)
+		}
+		_, err := service.NewPostUpdater(getAuthor, nil, nil, nil, nil, nil, nil)(post, caller, upd)
+		AssertError(t, err, client_errors.NotFound)
+	})
+	t.Run("error case - caller is not the author", func(t *testing.T) {
+		_, err := service.NewPostUpdater(getAuthor, nil, nil, nil, nil, nil, nil)(post, RandomId(), upd)
+		AssertError(t, err, client_errors.InsufficientPermissions)
+	})
+	validate := func(newPost values.NewPostData) (client_errors.ClientError, bool) {
+		wantNewPost := values.NewPostData{
+			Author: caller,
+			Text:   upd.Text,
+			Images: []values.PostImageFile{{File: newImage, Index: 2}},
+		}
+		if reflect.DeepEqual(newPost, wantNewPost) {
 			return client_errors.ClientError{}, true
 		}
-		storeCreator := func(values.NewPostData, time.Time) error {
+		panic("unexpected args")
+	}
+	t.Run("error case - validation fails", func(t *testing.T) {
+		clientErr := RandomClientError()
+		validate := func(values.NewPostData) (client_errors.ClientError, bool) {
+			return clientErr, false
+		}
+		_, err := service.NewPostUpdater(getAuthor, validate, nil, nil, nil, nil, nil)(post, caller, upd)
+		AssertError(t, err, clientErr)
+	})
+	getPost := func(postId values.PostId) (entities.Post, error) {
+		if postId == post {
+			return oldPost, nil
+		}
+		panic("unexpected args")
+	}
+	t.Run("error case - getting the old post throws", func(t *testing.T) {
+		getPost := func(values.PostId) (entities.Post, error) {
+			return entities.Post{}, RandomError()
+		}
+		_, err := service.NewPostUpdater(getAuthor, validate, getPost, nil, nil, nil, nil)(post, caller, upd)
+		AssertSomeError(t, err)
+	})
+	t.Run("error case - kept images are invalid", func(t *testing.T) {
+		cases := map[string][]values.PostImageUpdate{
+			"image does not exist": {{Index: 1, OldIndex: 42}},
+			"image is kept twice":  {{Index: 1, OldIndex: 2}, {Index: 2, OldIndex: 2}},
+		}
+		for name, images := range cases {
+			t.Run(name, func(t *testing.T) {
+				validate := func(values.NewPostData) (client_errors.ClientError, bool) {
+					return client_errors.ClientError{}, true
+				}
+				invalidUpd := values.PostUpdateData{Text: upd.Text, Images: images}
+				_, err := service.NewPostUpdater(getAuthor, validate, getPost, nil, nil, nil, nil)(post, caller, invalidUpd)
+				AssertError(t, err, client_errors.InvalidImageIndex)
+			})
+		}
+	})
+	mentions := RandomMentions()
+	resolveMentions := func(text string) ([]mentionable_values.Mention, error) {
+		if text == upd.Text {
+			return mentions, nil
+		}
+		panic("unexpected args")
+	}
+	t.Run("error case - resolving mentions throws", func(t *testing.T) {
+		resolveMentions := func(string) ([]mentionable_values.Mention, error) {
+			return nil, RandomError()
+		}
+		_, err := service.NewPostUpdater(getAuthor, validate, getPost, resolveMentions, nil, nil, nil)(post, caller, upd)
+		AssertSomeError(t, err)
+	})
+	updatePost := func(gotOldPost entities.Post, gotUpd values.PostUpdateData, gotMentions []mentionable_values.Mention, tags []values.Tag, editedAt time.Time) error {
+		if reflect.DeepEqual(gotOldPost, oldPost) && reflect.DeepEqual(gotUpd, upd) && reflect.DeepEqual(gotMentions, mentions) && reflect.DeepEqual(tags, []values.Tag{"edited"}) && TimeAlmostNow(editedAt) {
+			return nil
+		}
+		panic("unexpected args")
+	}
+	t.Run("error case - updating the post throws", func(t *testing.T) {
+		updatePost := func(entities.Post, values.PostUpdateData, []mentionable_values.Mention, []values.Tag, time.Time) error {
+			return RandomError()
+		}
+		_, err := service.NewPostUpdater(getAuthor, validate, getPost, resolveMentions, updatePost, nil, nil)(post, caller, upd)
+		AssertSomeError(t, err)
+	})
+	getUpdated := func(postId values.PostId, callerId core_values.UserId) (entities.ContextedPost, error) {
+		if postId == post && callerId == caller {
+			return updatedPost, nil
+		}
+		panic("unexpected args")
+	}
+	var published []events.Event
+	publish := func(event events.Event) {
+		published = append(published, event)
+	}
+	t.Run("happy case", func(t *testing.T) {
+		published = nil
+		gotPost, err := service.NewPostUpdater(getAuthor, validate, getPost, resolveMentions, updatePost, getUpdated, publish)(post, caller, upd)
+		AssertNoError(t, err)
+		Assert(t, gotPost, updatedPost, "returned updated post")
+		AssertFatal(t, len(published), len(mentions), "number of published events")
+		for i, mention := range mentions {
+			want := events.Event{
+				Kind:       events.Mentioned,
+				Actor:      caller,
+				Recipient:  mention.Profile,
+				TargetType: events.Post,
+				TargetId:   post,
+				CreatedAt:  published[i].CreatedAt,
+			}
+			Assert(t, published[i], want, "mention event")
+		}
+	})
+	t.Run("happy case - only the newly mentioned profiles are notified", func(t *testing.T) {
+		published = nil
+		oldPost := oldPost
+		oldPost.Mentions = mentions[:1]
+		getPost := func(values.PostId) (entities.Post, error) {
+			return oldPost, nil
+		}
+		updatePost := func(entities.Post, values.PostUpdateData, []mentionable_values.Mention, []values.Tag, time.Time) error {
+			return nil
+		}
+		_, err := service.NewPostUpdater(getAuthor, validate, getPost, resolveMentions, updatePost, getUpdated, publish)(post, caller, upd)
+		AssertNoError(t, err)
+		AssertFatal(t, len(published), len(mentions)-1, "number of published events")
+		for i, mention := range mentions[1:] {
+			Assert(t, published[i].Recipient, mention.Profile, "recipient of a mention event")
+		}
+	})
+}
+
+func TestPostLikersGetter(t *testing.T) {
+	post := RandomId()
+	caller := RandomId()
+	page := pagination.Page{Limit: RandomInt()}
+	wantLikers := []profile_entities.ContextedProfile{RandomContextedProfile()}
+	wantNext := pagination.Cursor{Id: RandomId()}
+
+	getAuthor := func(id values.PostId) (core_values.UserId, error) {
+		if id == post {
+			return RandomId(), nil
+		}
+		panic("unexpected args")
+	}
+	t.Run("error case - post does not exist", func(t *testing.T) {
+		getAuthor := func(values.PostId) (core_values.UserId, error) {
+			return "", client_errors.NotFound
+		}
+		_, _, err := service.NewPostLikersGetter(getAuthor, nil)(post, caller, page)
+		AssertError(t, err, client_errors.NotFound)
+	})
+	getLikers := func(target string, callerId core_values.UserId, gotPage pagination.Page) ([]profile_entities.ContextedProfile, pagination.Cursor, error) {
+		if target == post && callerId == caller && gotPage == page {
+			return wantLikers, wantNext, nil
+		}
+		panic("unexpected args")
+	}
+	t.Run("error case - getting likers throws", func(t *testing.T) {
+		getLikers := func(string, core_values.UserId, pagination.Page) ([]profile_entities.ContextedProfile, pagination.Cursor, error) {
+			return nil, pagination.Cursor{}, RandomError()
+		}
+		_, _, err := service.NewPostLikersGetter(getAuthor, getLikers)(post, caller, page)
+		AssertSomeError(t, err)
+	})
+	t.Run("happy case", func(t *testing.T) {
+		likers, next, err := service.NewPostLikersGetter(getAuthor, getLikers)(post, caller, page)
+		AssertNoError(t, err)
+		Assert(t, likers, wantLikers, "returned likers")
+		Assert(t, next, wantNext, "cursor of the next page")
+	})
+}
+
+func TestCommentsDisabler(t *testing.T) {
+	post := RandomId()
+	author := RandomId()
+	disabled := RandomBool()
+	getAuthor := func(postId values.PostId) (core_values.UserId, error) {
+		if postId == post {
+			return author, nil
+		}
+		panic("unexpected args")
+	}
+	t.Run("error case - getting author throws", func(t *testing.T) {
+		getAuthor := func(values.PostId) (core_values.UserId, error) {
+			return "", RandomError()
+		}
+		err := service.NewCommentsDisabler(getAuthor, nil)(post, author, disabled)
+		AssertSomeError(t, err)
+	})
+	t.Run("error case - caller is not the post author", func(t *testing.T) {
+		err := service.NewCommentsDisabler(getAuthor, nil)(post, RandomId(), disabled)
+		AssertError(t, err, client_errors.InsufficientPermissions)
+	})
+	setDisabled := func(postId values.PostId, gotDisabled bool) error {
+		if postId == post && gotDisabled == disabled {
+			return nil
+		}
+		panic("unexpected args")
+	}
+	t.Run("error case - store throws", func(t *testing.T) {
+		setDisabled := func(values.PostId, bool) error {
+			return RandomError()
+		}
+		err := service.NewCommentsDisabler(getAuthor, setDisabled)(post, author, disabled)
+		AssertSomeError(t, err)
+	})
+	t.Run("happy case", func(t *testing.T) {
+		err := service.NewCommentsDisabler(getAuthor, setDisabled)(post, author, disabled)
+		AssertNoError(t, err)
+	})
+}
+
+func TestTagPostsGetter(t *testing.T) {
+	caller := RandomId()
+	page := pagination.Page{Limit: RandomInt()}
+	posts := []entities.Post{RandomPost()}
+	ctxPosts := []entities.ContextedPost{RandomContextedPost()}
+
+	t.Run("error case - tag is invalid", func(t *testing.T) {
+		for _, tag := range []string{"", "42", "not-a-tag", strings.Repeat("a", values.MaxTagLength+1)} {
+			_, err := service.NewTagPostsGetter(nil, nil)(tag, caller, page)
+			AssertError(t, err, client_errors.InvalidTag)
+		}
+	})
+	getPosts := func(tag values.Tag, gotPage pagination.Page) ([]entities.Post, error) {
+		if tag == "golang" && gotPage == page {
+			return posts, nil
+		}
+		panic("unexpected args")
+	}
+	t.Run("error case - getting posts throws", func(t *testing.T) {
+		getPosts := func(values.Tag, pagination.Page) ([]entities.Post, error) {
+			return nil, RandomError()
+		}
+		_, err := service.NewTagPostsGetter(getPosts, nil)("golang", caller, page)
+		AssertSomeError(t, err)
+	})
+	addContext := func(gotPosts []entities.Post, callerId core_values.UserId) ([]entities.ContextedPost, error) {
+		if reflect.DeepEqual(gotPosts, posts) && callerId == caller {
+			return ctxPosts, nil
+		}
+		panic("unexpected args")
+	}
+	t.Run("error case - adding context throws", func(t *testing.T) {
+		addContext := func([]entities.Post, core_values.UserId) ([]entities.ContextedPost, error) {
+			return nil, RandomError()
+		}
+		_, err := service.NewTagPostsGetter(getPosts, addContext)("golang", caller, page)
+		AssertSomeError(t, err)
+	})
+	t.Run("happy case - the tag is normalized", func(t *testing.T) {
+		gotPosts, err := service.NewTagPostsGetter(getPosts, addContext)("GoLang", caller, page)
+		AssertNoError(t, err)
+		Assert(t, gotPosts, ctxPosts, "returned posts")
+	})
+}
+
+func TestTrendingTagsUpdater(t *testing.T) {
+	window := time.Duration(RandomInt()) * time.Hour
+	count := RandomInt()
+	t.Run("happy case", func(t *testing.T) {
+		updateTrending := func(_ context.Context, since time.Time, gotCount int) error {
+			if TimeAlmostEqual(since, time.Now().Add(-window)) && gotCount == count {
+				return nil
+			}
+			panic("unexpected args")
+		}
+		err := service.NewTrendingTagsUpdater(window, count, updateTrending)(context.Background())
+		AssertNoError(t, err)
+	})
+	t.Run("error case - store throws", func(t *testing.T) {
+		updateTrending := func(context.Context, time.Time, int) error {
 			return RandomError()
 		}
-		err := service.NewPostCreator(validator, storeCreator)(tNewPost)
+		err := service.NewTrendingTagsUpdater(window, count, updateTrending)(context.Background())
 		AssertSomeError(t, err)
 	})
 }
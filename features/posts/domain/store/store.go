@@ -1,13 +1,39 @@
 package store
 
 import (
+	"context"
+	mentionable_values "github.com/k0marov/go-socnet/core/abstract/mentionable/values"
 	"github.com/k0marov/go-socnet/core/general/core_values"
+	"github.com/k0marov/go-socnet/core/general/pagination"
 	"time"
 
 	"github.com/k0marov/go-socnet/features/posts/domain/entities"
+	"github.com/k0marov/go-socnet/features/posts/domain/models"
 	"github.com/k0marov/go-socnet/features/posts/domain/values"
 )
 
-type PostsGetter func(authorId core_values.UserId) ([]entities.Post, error)
+type PostGetter func(post values.PostId) (entities.Post, error)
+type PostsGetter func(authorId core_values.UserId, page pagination.Page) ([]entities.Post, error)
+type AuthorsPostsGetter func(authors []core_values.UserId, page pagination.Page) ([]entities.Post, error)
+type PostsByIdsGetter func(ids []values.PostId) ([]entities.Post, error)
 type PostDeleter func(postId values.PostId, authorId core_values.UserId) error
-type PostCreator func(post values.NewPostData, createdAt time.Time) error
+type PostCreator func(post values.NewPostData, mentions []mentionable_values.Mention, tags []values.Tag, createdAt time.Time) (values.PostId, error)
+
+// PostUpdater gets the post as it is before the update, so that the removed images can be deleted
+type PostUpdater func(oldPost entities.Post, upd values.PostUpdateData, mentions []mentionable_values.Mention, tags []values.Tag, editedAt time.Time) error
+type CommentsDisabledSetter func(post values.PostId, disabled bool) error
+
+// TagPostsGetter returns the posts with the tag, newest first
+type TagPostsGetter func(tag values.Tag, page pagination.Page) ([]entities.Post, error)
+
+// TrendingTagsGetter returns the trending tags computed by the last run of TrendingTagsUpdater, most used first
+type TrendingTagsGetter func() ([]values.TrendingTag, error)
+
+// TrendingTagsUpdater recomputes the trending tags as the count most used tags since the provided time
+type TrendingTagsUpdater func(ctx context.Context, since time.Time, count int) error
+
+// PostModelGetter is used by the comments feature to check who may moderate the comments of a post and whether they are disabled
+type PostModelGetter func(post values.PostId) (models.PostModel, error)
+
+// CommentCountsGetter returns the number of comments of each post, the posts without comments may be missing from the map
+type CommentCountsGetter func(posts []values.PostId) (map[values.PostId]int, error)
@@ -8,11 +8,9 @@ import (
 
 type PostValidator func(newPost values.NewPostData) (client_errors.ClientError, bool)
 
-const MaxTextLength = 1000
-
-func NewPostValidator(decodeImg image_decoder.ImageDecoder) PostValidator {
+func NewPostValidator(maxTextLength int, decodeImg image_decoder.ImageDecoder) PostValidator {
 	return func(newPost values.NewPostData) (client_errors.ClientError, bool) {
-		if len(newPost.Text) > MaxTextLength {
+		if len(newPost.Text) > maxTextLength {
 			return client_errors.TextTooLong, false
 		}
 		for _, image := range newPost.Images {
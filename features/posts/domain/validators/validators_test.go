@@ -13,6 +13,7 @@ import (
 )
 
 func TestPostValidator(t *testing.T) {
+	const maxTextLength = 1000
 	t.Run("Text validation", func(t *testing.T) {
 		decoder := func([]byte) (image_decoder.Image, error) {
 			return image_decoder.Image{Height: 123, Width: 345}, nil
@@ -23,7 +24,8 @@ func TestPostValidator(t *testing.T) {
 		}{
 			{"", nil},
 			{"some short text", nil},
-			{strings.Repeat("looong", 300), client_errors.TextTooLong},
+			{strings.Repeat("a", maxTextLength), nil},
+			{strings.Repeat("a", maxTextLength+1), client_errors.TextTooLong},
 		}
 		for _, testCase := range cases {
 			t.Run(testCase.text, func(t *testing.T) {
@@ -32,7 +34,7 @@ func TestPostValidator(t *testing.T) {
 					Text:   testCase.text,
 					Images: nil,
 				}
-				gotErr, ok := validators.NewPostValidator(decoder)(newPost)
+				gotErr, ok := validators.NewPostValidator(maxTextLength, decoder)(newPost)
 				if testCase.expectedErr == nil {
 					AssertError(t, gotErr, client_errors.ClientError{})
 					Assert(t, ok, true, "returned 'ok' value")
@@ -66,7 +68,7 @@ func TestPostValidator(t *testing.T) {
 				}
 				panic("unexpected args")
 			}
-			clientErr, ok := validators.NewPostValidator(decoder)(newPost)
+			clientErr, ok := validators.NewPostValidator(maxTextLength, decoder)(newPost)
 			Assert(t, ok, true, "ok is true")
 			AssertError(t, clientErr, client_errors.ClientError{})
 			Assert(t, imagesChecked, len(newPost.Images), "amount of checked images")
@@ -75,7 +77,7 @@ func TestPostValidator(t *testing.T) {
 			decoder := func([]byte) (image_decoder.Image, error) {
 				return image_decoder.Image{}, RandomError()
 			}
-			clientErr, ok := validators.NewPostValidator(decoder)(newPost)
+			clientErr, ok := validators.NewPostValidator(maxTextLength, decoder)(newPost)
 			Assert(t, ok, false, "ok is false")
 			AssertError(t, clientErr, client_errors.InvalidImage)
 		})
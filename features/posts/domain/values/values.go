@@ -2,6 +2,8 @@ package values
 
 import (
 	"github.com/k0marov/go-socnet/core/general/core_values"
+	"strings"
+	"unicode"
 )
 
 type PostId = string
@@ -21,3 +23,91 @@ type PostImage struct {
 	URL   core_values.FileURL
 	Index int
 }
+
+type PostUpdateData struct {
+	Text   string
+	Images []PostImageUpdate
+}
+
+// PostImageUpdate is the image at position Index of an edited post.
+// It is either a newly uploaded File or the existing image of the post which was at position OldIndex.
+type PostImageUpdate struct {
+	Index    int
+	File     core_values.FileData
+	OldIndex int // 0 if the image is newly uploaded
+}
+
+func (u PostImageUpdate) IsNew() bool {
+	return u.OldIndex == 0
+}
+
+func (d PostUpdateData) NewImages() []PostImageFile {
+	newImages := []PostImageFile{}
+	for _, image := range d.Images {
+		if image.IsNew() {
+			newImages = append(newImages, PostImageFile{File: image.File, Index: image.Index})
+		}
+	}
+	return newImages
+}
+
+// Tag is the normalized name of a "#hashtag": lowercase and without the "#"
+type Tag = string
+
+const MaxTagLength = 64
+
+// TrendingTag is a tag together with the number of its uses inside the trending window
+type TrendingTag struct {
+	Tag  Tag `db:"name"`
+	Uses int `db:"uses"`
+}
+
+// FindTags returns the distinct tags of all "#hashtag"s in the text in the order of their first appearance.
+// Just like with mentions, the "#" should not be a part of a word.
+func FindTags(text string) []Tag {
+	tags := []Tag{}
+	found := map[Tag]bool{}
+	chars := []rune(text)
+	for i := 0; i < len(chars); i++ {
+		if chars[i] != '#' || (i > 0 && (isTagChar(chars[i-1]) || chars[i-1] == '#')) {
+			continue
+		}
+		end := i + 1
+		for end < len(chars) && isTagChar(chars[end]) {
+			end++
+		}
+		tag, ok := NormalizeTag(string(chars[i+1 : end]))
+		if ok && !found[tag] {
+			found[tag] = true
+			tags = append(tags, tag)
+		}
+		i = end - 1
+	}
+	return tags
+}
+
+// NormalizeTag returns the normalized form of a tag provided without the "#".
+// ok is false if it is not a valid tag, e.g. it consists only of digits, like in "issue #42".
+func NormalizeTag(tag string) (normalized Tag, ok bool) {
+	chars := []rune(tag)
+	if len(chars) == 0 || len(chars) > MaxTagLength {
+		return "", false
+	}
+	onlyDigits := true
+	for _, char := range chars {
+		if !isTagChar(char) {
+			return "", false
+		}
+		if !unicode.IsDigit(char) {
+			onlyDigits = false
+		}
+	}
+	if onlyDigits {
+		return "", false
+	}
+	return strings.ToLower(tag), true
+}
+
+func isTagChar(char rune) bool {
+	return char == '_' || unicode.IsLetter(char) || unicode.IsDigit(char)
+}
@@ -0,0 +1,81 @@
+package posts_test
+
+import (
+	"fmt"
+	"github.com/k0marov/go-socnet/core/abstract/likeable"
+	likeable_contexters "github.com/k0marov/go-socnet/core/abstract/ownable_likeable/contexters"
+	"github.com/k0marov/go-socnet/core/general/pagination"
+	. "github.com/k0marov/go-socnet/core/helpers/test_helpers"
+	"testing"
+	"time"
+
+	"github.com/k0marov/go-socnet/features/comments"
+	"github.com/k0marov/go-socnet/features/posts"
+	"github.com/k0marov/go-socnet/features/posts/domain/contexters"
+	"github.com/k0marov/go-socnet/features/posts/domain/entities"
+	"github.com/k0marov/go-socnet/features/posts/domain/models"
+	"github.com/k0marov/go-socnet/features/posts/store/sql_db"
+	"github.com/k0marov/go-socnet/features/profiles"
+	profiles_db "github.com/k0marov/go-socnet/features/profiles/store/sql_db"
+)
+
+// BenchmarkPostsListing compares getting a page of contexted posts with separate queries for each post
+// (its images, likes and comments counts, author, like context and latest comments) and with the batched posts listing.
+func BenchmarkPostsListing(b *testing.B) {
+	cfg := TestConfig(b)
+	db := OpenTestDB(b)
+	postsDB, err := sql_db.NewSqlDB(db)
+	AssertNoError(b, err)
+	profilesDB, err := profiles_db.NewSqlDB(db)
+	AssertNoError(b, err)
+	likeablePost, err := likeable.NewLikeable(db, postsDB.TableName)
+	AssertNoError(b, err)
+
+	getProfile := profiles.NewProfileGetterImpl(cfg, db)
+	getProfiles := profiles.NewProfilesGetterImpl(cfg, db)
+	getCommentCounts := comments.NewCommentCountsGetterImpl(db)
+	getCommentPreviews := comments.NewCommentPreviewsGetterImpl(db, getProfiles)
+	addPostContext := contexters.NewPostContextAdder(getProfile, likeable_contexters.NewOwnLikeContextGetter(likeablePost.GetReaction), getCommentPreviews)
+	listing := posts.NewPostListingImpl(cfg, db, getProfiles, getCommentCounts, getCommentPreviews)
+
+	for _, count := range []int{10, 50} {
+		author, caller := RandomProfileModel(), RandomProfileModel()
+		AssertNoError(b, profilesDB.CreateProfile(author))
+		AssertNoError(b, profilesDB.CreateProfile(caller))
+		for i := 0; i < count; i++ {
+			id, err := postsDB.CreatePost(db, models.PostToCreate{Author: author.Id, Text: RandomString(), CreatedAt: time.Unix(int64(i), 0)})
+			AssertNoError(b, err)
+			AssertNoError(b, postsDB.AddPostImages(db, id, RandomPostImageModels()))
+			_, err = likeablePost.ToggleLike(id, caller.Id)
+			AssertNoError(b, err)
+		}
+		page := pagination.Page{Limit: count}
+
+		b.Run(fmt.Sprintf("per post/%d posts", count), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				page, err := postsDB.GetPosts(author.Id, page)
+				AssertNoError(b, err)
+				for _, model := range page {
+					model, err := postsDB.GetPost(model.Id)
+					AssertNoError(b, err)
+					reactions, err := likeablePost.GetReactionCounts(model.Id)
+					AssertNoError(b, err)
+					commentCounts, err := getCommentCounts([]string{model.Id})
+					AssertNoError(b, err)
+					post := entities.Post{PostModel: model, Likes: reactions.Total(), Reactions: reactions, CommentsCount: commentCounts[model.Id]}
+					_, err = addPostContext(post, caller.Id)
+					AssertNoError(b, err)
+				}
+			}
+		})
+		b.Run(fmt.Sprintf("batched/%d posts", count), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				page, err := listing.GetByAuthors([]string{author.Id}, page)
+				AssertNoError(b, err)
+				ctxPosts, err := listing.AddContext(page, caller.Id)
+				AssertNoError(b, err)
+				Assert(b, len(ctxPosts), count, "number of posts")
+			}
+		})
+	}
+}
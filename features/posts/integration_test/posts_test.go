@@ -2,10 +2,14 @@ package posts_test
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
+	likeable_values "github.com/k0marov/go-socnet/core/abstract/likeable/values"
+	mentionable_responses "github.com/k0marov/go-socnet/core/abstract/mentionable/responses"
+	"github.com/k0marov/go-socnet/core/general/client_errors"
 	"github.com/k0marov/go-socnet/core/general/core_values"
-	"github.com/k0marov/go-socnet/core/general/static_store"
+	"github.com/k0marov/go-socnet/core/general/events"
 	helpers "github.com/k0marov/go-socnet/core/helpers/http_test_helpers"
 	. "github.com/k0marov/go-socnet/core/helpers/test_helpers"
 	"log"
@@ -15,14 +19,21 @@ import (
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/k0marov/go-socnet/features/comments"
+	comment_handlers "github.com/k0marov/go-socnet/features/comments/delivery/http/handlers"
+	comment_responses "github.com/k0marov/go-socnet/features/comments/delivery/http/responses"
 	"github.com/k0marov/go-socnet/features/posts"
+	"github.com/k0marov/go-socnet/features/posts/delivery/http/handlers"
 	"github.com/k0marov/go-socnet/features/posts/delivery/http/responses"
 	"github.com/k0marov/go-socnet/features/posts/domain/values"
 	post_storage "github.com/k0marov/go-socnet/features/posts/store/file_storage"
 	"github.com/k0marov/go-socnet/features/profiles"
+	profile_responses "github.com/k0marov/go-socnet/features/profiles/delivery/http/responses"
 	profile_entities "github.com/k0marov/go-socnet/features/profiles/domain/entities"
 	profile_models "github.com/k0marov/go-socnet/features/profiles/domain/models"
 	auth "github.com/k0marov/golang-auth"
@@ -30,23 +41,27 @@ import (
 )
 
 func TestPosts(t *testing.T) {
-	// working directory
-	os.Mkdir("tmp_test", 0777)
-	os.Chdir("tmp_test")
-	defer func() {
-		os.Chdir("..")
-		os.RemoveAll("tmp_test")
-	}()
-
 	// db
-	sql := OpenSqliteDB(t)
+	cfg := TestConfig(t)
+	sql := OpenTestDB(t)
 
 	r := chi.NewRouter()
 	// profiles
-	r.Route("/profiles", profiles.NewProfilesRouterImpl(sql))
+	r.Route("/profiles", profiles.NewProfilesRouterImpl(cfg, sql, events.NewPublisher()))
 	fakeRegisterProfile := profiles.NewRegisterCallback(sql)
+	getProfile := profiles.NewProfileGetterImpl(cfg, sql)
+	getProfiles := profiles.NewProfilesGetterImpl(cfg, sql)
 	// posts
-	r.Route("/posts", posts.NewPostsRouterImpl(sql, profiles.NewProfileGetterImpl(sql)))
+	getCommentCounts := comments.NewCommentCountsGetterImpl(sql)
+	getCommentPreviews := comments.NewCommentPreviewsGetterImpl(sql, getProfiles)
+	r.Route("/posts", posts.NewPostsRouterImpl(cfg, sql, getProfile, getProfiles, getCommentCounts, getCommentPreviews, profiles.NewUsernamesResolverImpl(sql), events.NewPublisher()))
+	// tags
+	r.Route("/tags", posts.NewTagsRouterImpl(cfg, sql, getProfiles, getCommentCounts, getCommentPreviews))
+	updateTrendingTags := posts.NewTrendingTagsUpdaterImpl(sql, time.Hour, 2)
+	// recommendations
+	updateRecs := posts.NewPostRecommendable(sql, profiles.FollowedTableName).UpdateRecs
+	// comments
+	r.Route("/comments", comments.NewCommentsRouterImpl(cfg, sql, getProfile, posts.NewPostModelGetterImpl(sql), profiles.NewUsernamesResolverImpl(sql), events.NewPublisher()))
 
 	// helpers
 	createPost := func(t testing.TB, author auth.User, images [][]byte, text string) {
@@ -79,7 +94,7 @@ func TestPosts(t *testing.T) {
 	}
 	assertImageCreated := func(t testing.TB, post responses.PostResponse, postImage responses.PostImageResponse, wantImage []byte) {
 		t.Helper()
-		path := filepath.Join(static_store.StaticDir, post_storage.GetPostDir(post.Id, post.Author.Id), post_storage.ImagePrefix+strconv.Itoa(postImage.Index))
+		path := filepath.Join(cfg.Static.Dir, post_storage.GetPostDir(post.Id, post.Author.Id), post_storage.ImagePrefix+strconv.Itoa(postImage.Index))
 		got := readFile(t, path)
 		Assert(t, got, wantImage, "the stored image data")
 	}
@@ -92,10 +107,44 @@ func TestPosts(t *testing.T) {
 	}
 	assertPostFilesDeleted := func(t testing.TB, postId values.PostId, author core_values.UserId) {
 		t.Helper()
-		postPath := filepath.Join(static_store.StaticDir, post_storage.GetPostDir(postId, author))
+		postPath := filepath.Join(cfg.Static.Dir, post_storage.GetPostDir(postId, author))
 		_, err := os.ReadDir(postPath)
 		AssertSomeError(t, err)
 	}
+	getPost := func(t testing.TB, postId values.PostId, caller auth.User) responses.PostResponse {
+		t.Helper()
+		request := helpers.AddAuthDataToRequest(httptest.NewRequest(http.MethodGet, "/posts/"+postId, nil), caller)
+		response := httptest.NewRecorder()
+		r.ServeHTTP(response, request)
+		AssertStatusCode(t, response, http.StatusOK)
+		var post responses.PostResponse
+		json.NewDecoder(response.Body).Decode(&post)
+		return post
+	}
+	// fields are "image_<i>" form values of the edit request, each being either the index of an existing image or new image data
+	editPost := func(t testing.TB, postId values.PostId, caller auth.User, text string, keptImages map[int]int, newImages map[int][]byte) *httptest.ResponseRecorder {
+		t.Helper()
+		body := bytes.NewBuffer(nil)
+		writer := multipart.NewWriter(body)
+		writer.WriteField("text", text)
+		for index, oldIndex := range keptImages {
+			writer.WriteField(fmt.Sprintf("image_%d", index), strconv.Itoa(oldIndex))
+		}
+		for index, image := range newImages {
+			fw, _ := writer.CreateFormFile(fmt.Sprintf("image_%d", index), RandomString())
+			fw.Write(image)
+		}
+		writer.Close()
+		request := helpers.AddAuthDataToRequest(httptest.NewRequest(http.MethodPut, "/posts/"+postId, body), caller)
+		request.Header.Set("Content-Type", writer.FormDataContentType())
+		response := httptest.NewRecorder()
+		r.ServeHTTP(response, request)
+		return response
+	}
+	readImageByURL := func(t testing.TB, url string) []byte {
+		t.Helper()
+		return readFile(t, filepath.Join(cfg.Static.Dir, strings.TrimPrefix(url, cfg.Static.Host+"/")))
+	}
 	toggleLike := func(t testing.TB, postId values.PostId, caller auth.User) {
 		t.Helper()
 		request := helpers.AddAuthDataToRequest(httptest.NewRequest(http.MethodPost, "/posts/"+postId+"/toggle-like", nil), caller)
@@ -103,6 +152,44 @@ func TestPosts(t *testing.T) {
 		r.ServeHTTP(response, request)
 		AssertStatusCode(t, response, http.StatusOK)
 	}
+	setLike := func(t testing.TB, method string, postId values.PostId, caller auth.User) *httptest.ResponseRecorder {
+		t.Helper()
+		request := helpers.AddAuthDataToRequest(httptest.NewRequest(method, "/posts/"+postId+"/like", nil), caller)
+		response := httptest.NewRecorder()
+		r.ServeHTTP(response, request)
+		return response
+	}
+	setReaction := func(t testing.TB, postId values.PostId, caller auth.User, reaction likeable_values.Reaction) *httptest.ResponseRecorder {
+		t.Helper()
+		body := bytes.NewBuffer(nil)
+		json.NewEncoder(body).Encode(handlers.SetReactionRequest{Reaction: reaction})
+		request := helpers.AddAuthDataToRequest(httptest.NewRequest(http.MethodPut, "/posts/"+postId+"/reaction", body), caller)
+		response := httptest.NewRecorder()
+		r.ServeHTTP(response, request)
+		return response
+	}
+	setCommentsDisabled := func(t testing.TB, postId values.PostId, caller auth.User, disabled bool) *httptest.ResponseRecorder {
+		t.Helper()
+		body := bytes.NewBuffer(nil)
+		json.NewEncoder(body).Encode(handlers.SetCommentsDisabledRequest{CommentsDisabled: disabled})
+		request := helpers.AddAuthDataToRequest(httptest.NewRequest(http.MethodPut, "/posts/"+postId+"/comments-disabled", body), caller)
+		response := httptest.NewRecorder()
+		r.ServeHTTP(response, request)
+		return response
+	}
+
+	addComment := func(t testing.TB, postId values.PostId, parent string, caller auth.User) string {
+		t.Helper()
+		body := bytes.NewBuffer(nil)
+		json.NewEncoder(body).Encode(comment_handlers.NewCommentRequest{Text: RandomString(), ParentId: parent})
+		request := helpers.AddAuthDataToRequest(httptest.NewRequest(http.MethodPost, "/comments/?post_id="+postId, body), caller)
+		response := httptest.NewRecorder()
+		r.ServeHTTP(response, request)
+		AssertStatusCode(t, response, http.StatusOK)
+		var comment comment_responses.CommentResponse
+		json.NewDecoder(response.Body).Decode(&comment)
+		return comment.Id
+	}
 
 	registerProfile := func(user auth.User) profile_entities.Profile {
 		fakeRegisterProfile(user)
@@ -139,15 +226,16 @@ func TestPosts(t *testing.T) {
 
 		log.Print(fmt.Sprintf("first:  %+v, \nsecond: %+v", posts[0], posts[1]))
 
-		Assert(t, posts[0].Text, text1, "the first post's text")
-		Assert(t, posts[0].Author.Id, user2.Id, "first post's author")
-		AssertFatal(t, len(posts[0].Images), 0, "number of images in first post")
+		// newest posts are returned first
+		Assert(t, posts[0].Text, text2, "the first post's text")
+		Assert(t, posts[0].Author.Id, user2.Id, "first posts's author")
+		AssertFatal(t, len(posts[0].Images), 2, "number of images in first post")
+		assertImageCreated(t, posts[0], posts[0].Images[0], image1)
+		assertImageCreated(t, posts[0], posts[0].Images[1], image2)
 
-		Assert(t, posts[1].Text, text2, "the second post's text")
-		Assert(t, posts[1].Author.Id, user2.Id, "second posts's author")
-		AssertFatal(t, len(posts[1].Images), 2, "number of images in second post")
-		assertImageCreated(t, posts[1], posts[1].Images[0], image1)
-		assertImageCreated(t, posts[1], posts[1].Images[1], image2)
+		Assert(t, posts[1].Text, text1, "the second post's text")
+		Assert(t, posts[1].Author.Id, user2.Id, "second post's author")
+		AssertFatal(t, len(posts[1].Images), 0, "number of images in second post")
 		// delete them
 		deletePost(t, posts[0].Id, user2)
 		deletePost(t, posts[1].Id, user2)
@@ -157,6 +245,40 @@ func TestPosts(t *testing.T) {
 		assertPostFilesDeleted(t, posts[0].Id, user2.Id)
 		assertPostFilesDeleted(t, posts[1].Id, user2.Id)
 	})
+	t.Run("getting and editing a post", func(t *testing.T) {
+		image1 := readFixture(t, "test_image.jpg")
+		image2 := readFixture(t, "test_image.jpg")
+		image2 = append(image2[:len(image2):len(image2)], 0) // make it differ from image1
+		createPost(t, user1, [][]byte{image1, image2}, "Before edit")
+		post := getPosts(t, user1.Id, user1)[0]
+		Assert(t, getPost(t, post.Id, user1), post, "the post returned by id")
+		Assert(t, post.EditedAt, int64(0), "edited_at of a new post")
+
+		// another user can't edit the post
+		response := editPost(t, post.Id, user2, "Hacked", nil, nil)
+		AssertStatusCode(t, response, http.StatusUnauthorized)
+
+		// move the second image to the first position, drop the first one and add a new one
+		newImage := readFixture(t, "test_image.jpg")
+		response = editPost(t, post.Id, user1, "After edit", map[int]int{1: 2}, map[int][]byte{2: newImage})
+		AssertStatusCode(t, response, http.StatusOK)
+
+		edited := getPost(t, post.Id, user1)
+		Assert(t, edited.Text, "After edit", "edited text")
+		Assert(t, edited.EditedAt != 0, true, "edited_at is set")
+		AssertFatal(t, len(edited.Images), 2, "number of images after edit")
+		Assert(t, edited.Images[0].Url, post.Images[1].Url, "url of the moved image")
+		Assert(t, readImageByURL(t, edited.Images[0].Url), image2, "the moved image")
+		Assert(t, readImageByURL(t, edited.Images[1].Url), newImage, "the new image")
+		_, err := os.Stat(filepath.Join(cfg.Static.Dir, strings.TrimPrefix(post.Images[0].Url, cfg.Static.Host+"/")))
+		Assert(t, os.IsNotExist(err), true, "the dropped image file is deleted")
+
+		deletePost(t, post.Id, user1)
+
+		// editing a deleted post
+		response = editPost(t, post.Id, user1, "After delete", nil, nil)
+		AssertStatusCode(t, response, http.StatusNotFound)
+	})
 	t.Run("liking posts", func(t *testing.T) {
 		// create a post belonging to 1-st profile
 		createPost(t, user1, [][]byte{}, "")
@@ -174,11 +296,268 @@ func TestPosts(t *testing.T) {
 		posts = getPosts(t, user1.Id, user2)
 		Assert(t, posts[0].IsLiked, false, "post is not liked")
 	})
+	t.Run("liking and unliking posts is idempotent", func(t *testing.T) {
+		createPost(t, user1, [][]byte{}, "")
+		post := getPosts(t, user1.Id, user1)[0]
+
+		for i := 0; i < 2; i++ {
+			AssertStatusCode(t, setLike(t, http.MethodPut, post.Id, user2), http.StatusOK)
+		}
+		got := getPosts(t, user1.Id, user2)[0]
+		Assert(t, got.IsLiked, true, "post is liked")
+		Assert(t, got.Likes, 1, "number of likes")
+
+		for i := 0; i < 2; i++ {
+			AssertStatusCode(t, setLike(t, http.MethodDelete, post.Id, user2), http.StatusOK)
+		}
+		got = getPosts(t, user1.Id, user2)[0]
+		Assert(t, got.IsLiked, false, "post is not liked")
+		Assert(t, got.Likes, 0, "number of likes")
+
+		AssertClientError(t, setLike(t, http.MethodPut, post.Id, user1), client_errors.LikingYourself)
+		AssertClientError(t, setLike(t, http.MethodPut, "9999999", user2), client_errors.NotFound)
+	})
+	t.Run("deleting liked posts", func(t *testing.T) {
+		postsBefore := len(getPosts(t, user1.Id, user1))
+		createPost(t, user1, [][]byte{}, "")
+		post := getPosts(t, user1.Id, user1)[0]
+		AssertStatusCode(t, setLike(t, http.MethodPut, post.Id, user2), http.StatusOK)
+		deletePost(t, post.Id, user1)
+		Assert(t, len(getPosts(t, user1.Id, user2)), postsBefore, "number of posts after the liked one was deleted")
+
+		// the likes of the comments are deleted together with the post
+		createPost(t, user1, [][]byte{}, "")
+		post = getPosts(t, user1.Id, user1)[0]
+		comment := addComment(t, post.Id, "", user2)
+		request := helpers.AddAuthDataToRequest(httptest.NewRequest(http.MethodPut, "/comments/"+comment+"/like", nil), user1)
+		response := httptest.NewRecorder()
+		r.ServeHTTP(response, request)
+		AssertStatusCode(t, response, http.StatusOK)
+		deletePost(t, post.Id, user1)
+		Assert(t, len(getPosts(t, user1.Id, user2)), postsBefore, "number of posts after the one with a liked comment was deleted")
+	})
+	t.Run("deleting recommended posts", func(t *testing.T) {
+		user3 := RandomAuthUser()
+		registerProfile(user3)
+		createPost(t, user1, [][]byte{}, "")
+		createPost(t, user1, [][]byte{}, "")
+		userPosts := getPosts(t, user1.Id, user1)
+		liked, recommended := userPosts[1], userPosts[0]
+		// user3 liked the same post as user2, so the other post liked by user2 is recommended to user3
+		AssertStatusCode(t, setLike(t, http.MethodPut, liked.Id, user2), http.StatusOK)
+		AssertStatusCode(t, setLike(t, http.MethodPut, recommended.Id, user2), http.StatusOK)
+		AssertStatusCode(t, setLike(t, http.MethodPut, liked.Id, user3), http.StatusOK)
+		AssertNoError(t, updateRecs(context.Background()))
+		var recs int
+		AssertNoError(t, sql.Get(&recs, `SELECT COUNT(*) FROM PostRecommendation WHERE recommendation_id = ? AND user_id = ?`, recommended.Id, user3.Id))
+		Assert(t, recs, 1, "number of stored recommendations of the post to user3")
+
+		deletePost(t, recommended.Id, user1)
+		AssertNoError(t, sql.Get(&recs, `SELECT COUNT(*) FROM PostRecommendation WHERE recommendation_id = ?`, recommended.Id))
+		Assert(t, recs, 0, "number of stored recommendations of the deleted post")
+		deletePost(t, liked.Id, user1)
+	})
+	t.Run("reacting to posts", func(t *testing.T) {
+		createPost(t, user1, [][]byte{}, "")
+		post := getPosts(t, user1.Id, user1)[0]
+		user3 := RandomAuthUser()
+		registerProfile(user3)
+
+		AssertStatusCode(t, setReaction(t, post.Id, user2, likeable_values.ReactionLaugh), http.StatusOK)
+		AssertStatusCode(t, setReaction(t, post.Id, user3, likeable_values.ReactionLaugh), http.StatusOK)
+		AssertStatusCode(t, setLike(t, http.MethodPut, post.Id, user3), http.StatusOK) // keeps the reaction
+		got := getPosts(t, user1.Id, user2)[0]
+		Assert(t, got.Reaction, likeable_values.ReactionLaugh, "caller's reaction")
+		Assert(t, got.IsLiked, true, "post is liked")
+		Assert(t, got.Likes, 2, "number of likes")
+		Assert(t, got.Reactions[likeable_values.ReactionLaugh], 2, "number of laugh reactions")
+
+		AssertStatusCode(t, setReaction(t, post.Id, user2, likeable_values.ReactionSad), http.StatusOK)
+		got = getPosts(t, user1.Id, user2)[0]
+		Assert(t, got.Reaction, likeable_values.ReactionSad, "changed reaction")
+		Assert(t, got.Likes, 2, "number of likes")
+		Assert(t, got.Reactions[likeable_values.ReactionLaugh], 1, "number of laugh reactions")
+		Assert(t, got.Reactions[likeable_values.ReactionSad], 1, "number of sad reactions")
+
+		request := helpers.AddAuthDataToRequest(httptest.NewRequest(http.MethodDelete, "/posts/"+post.Id+"/reaction", nil), user2)
+		response := httptest.NewRecorder()
+		r.ServeHTTP(response, request)
+		AssertStatusCode(t, response, http.StatusOK)
+		got = getPosts(t, user1.Id, user2)[0]
+		Assert(t, got.Reaction, likeable_values.Reaction(""), "caller's reaction after removing it")
+		Assert(t, got.Likes, 1, "number of likes")
+
+		AssertClientError(t, setReaction(t, post.Id, user2, "wow"), client_errors.InvalidReaction)
+		AssertClientError(t, setReaction(t, post.Id, user1, likeable_values.ReactionLove), client_errors.LikingYourself)
+		AssertClientError(t, setReaction(t, "9999999", user2, likeable_values.ReactionLove), client_errors.NotFound)
+	})
+	t.Run("listing likers of a post, newest first", func(t *testing.T) {
+		createPost(t, user1, [][]byte{}, "")
+		post := getPosts(t, user1.Id, user1)[0]
+		user3 := RandomAuthUser()
+		registerProfile(user3)
+		AssertStatusCode(t, setLike(t, http.MethodPut, post.Id, user2), http.StatusOK)
+		AssertStatusCode(t, setLike(t, http.MethodPut, post.Id, user3), http.StatusOK)
+
+		getLikers := func(t testing.TB, query string) profile_responses.ProfilesResponse {
+			t.Helper()
+			request := helpers.AddAuthDataToRequest(httptest.NewRequest(http.MethodGet, "/posts/"+post.Id+"/likes?"+query, nil), user1)
+			response := httptest.NewRecorder()
+			r.ServeHTTP(response, request)
+			AssertStatusCode(t, response, http.StatusOK)
+			var likers profile_responses.ProfilesResponse
+			json.NewDecoder(response.Body).Decode(&likers)
+			return likers
+		}
+		firstPage := getLikers(t, "limit=1")
+		Assert(t, len(firstPage.Profiles), 1, "number of likers on the first page")
+		Assert(t, firstPage.Profiles[0].Id, user3.Id, "the newest liker")
+		secondPage := getLikers(t, "limit=1&cursor="+firstPage.NextCursor)
+		Assert(t, len(secondPage.Profiles), 1, "number of likers on the second page")
+		Assert(t, secondPage.Profiles[0].Id, user2.Id, "the oldest liker")
+		Assert(t, secondPage.Profiles[0].IsFollowed, false, "liker is not followed by caller")
+
+		request := helpers.AddAuthDataToRequest(httptest.NewRequest(http.MethodGet, "/posts/9999999/likes", nil), user1)
+		response := httptest.NewRecorder()
+		r.ServeHTTP(response, request)
+		AssertClientError(t, response, client_errors.NotFound)
+	})
+	t.Run("comment counts and latest comments", func(t *testing.T) {
+		createPost(t, user1, [][]byte{}, "")
+		post := getPosts(t, user1.Id, user1)[0]
+		Assert(t, post.CommentsCount, 0, "comments count of a new post")
+		Assert(t, len(post.LatestComments), 0, "number of latest comments of a new post")
+
+		first := addComment(t, post.Id, "", user2)
+		second := addComment(t, post.Id, "", user1)
+		addComment(t, post.Id, first, user1)
+		third := addComment(t, post.Id, "", user2)
+
+		post = getPost(t, post.Id, user1)
+		Assert(t, post.CommentsCount, 4, "comments count including replies")
+		AssertFatal(t, len(post.LatestComments), 2, "number of latest comments")
+		Assert(t, post.LatestComments[0].Id, third, "the newest comment")
+		Assert(t, post.LatestComments[0].Author.Id, user2.Id, "author of the newest comment")
+		Assert(t, post.LatestComments[1].Id, second, "the second newest top-level comment")
+		Assert(t, getPosts(t, user1.Id, user1)[0], post, "the post in the listing")
+
+		deletePost(t, post.Id, user1)
+	})
+	t.Run("disabling comments of a post", func(t *testing.T) {
+		createPost(t, user1, [][]byte{}, "")
+		post := getPosts(t, user1.Id, user1)[0]
+		Assert(t, post.CommentsDisabled, false, "comments are enabled by default")
+		comment := addComment(t, post.Id, "", user2)
+
+		AssertClientError(t, setCommentsDisabled(t, post.Id, user2, true), client_errors.InsufficientPermissions)
+		AssertStatusCode(t, setCommentsDisabled(t, post.Id, user1, true), http.StatusOK)
+		Assert(t, getPost(t, post.Id, user2).CommentsDisabled, true, "comments are disabled")
+
+		body := bytes.NewBuffer(nil)
+		json.NewEncoder(body).Encode(comment_handlers.NewCommentRequest{Text: RandomString()})
+		request := helpers.AddAuthDataToRequest(httptest.NewRequest(http.MethodPost, "/comments/?post_id="+post.Id, body), user2)
+		response := httptest.NewRecorder()
+		r.ServeHTTP(response, request)
+		AssertClientError(t, response, client_errors.CommentsDisabled)
+
+		// the post author can still remove the existing comments
+		request = helpers.AddAuthDataToRequest(httptest.NewRequest(http.MethodDelete, "/comments/"+comment, nil), user1)
+		response = httptest.NewRecorder()
+		r.ServeHTTP(response, request)
+		AssertStatusCode(t, response, http.StatusOK)
+		Assert(t, getPost(t, post.Id, user1).CommentsCount, 0, "comments count after the comment was removed")
+
+		AssertStatusCode(t, setCommentsDisabled(t, post.Id, user1, false), http.StatusOK)
+		addComment(t, post.Id, "", user2)
+
+		deletePost(t, post.Id, user1)
+	})
+	t.Run("tag pages and trending tags", func(t *testing.T) {
+		getTagPosts := func(t testing.TB, tag string, query string) *httptest.ResponseRecorder {
+			t.Helper()
+			request := helpers.AddAuthDataToRequest(httptest.NewRequest(http.MethodGet, "/tags/"+tag+"/posts"+query, nil), user2)
+			response := httptest.NewRecorder()
+			r.ServeHTTP(response, request)
+			return response
+		}
+		getTagPostIds := func(t testing.TB, tag string, query string) (ids []values.PostId, nextCursor string) {
+			t.Helper()
+			response := getTagPosts(t, tag, query)
+			AssertStatusCode(t, response, http.StatusOK)
+			var posts responses.PostsResponse
+			json.NewDecoder(response.Body).Decode(&posts)
+			for _, post := range posts.Posts {
+				ids = append(ids, post.Id)
+			}
+			return ids, posts.NextCursor
+		}
+		getTrending := func(t testing.TB) []responses.TrendingTagResponse {
+			t.Helper()
+			request := helpers.AddAuthDataToRequest(httptest.NewRequest(http.MethodGet, "/tags/trending", nil), user2)
+			response := httptest.NewRecorder()
+			r.ServeHTTP(response, request)
+			AssertStatusCode(t, response, http.StatusOK)
+			var trending responses.TrendingTagsResponse
+			json.NewDecoder(response.Body).Decode(&trending)
+			return trending.Tags
+		}
+
+		createPost(t, user1, [][]byte{}, "#GoLang is great #go")
+		older := getPosts(t, user1.Id, user1)[0]
+		createPost(t, user1, [][]byte{}, "learning #golang and #sql, not #42")
+		newer := getPosts(t, user1.Id, user1)[0]
+
+		// tags are case-insensitive and the tag pages are paginated, newest first
+		ids, next := getTagPostIds(t, "golang", "?limit=1")
+		Assert(t, ids, []values.PostId{newer.Id}, "the first page of the tag")
+		ids, _ = getTagPostIds(t, "GOLANG", "?limit=1&cursor="+next)
+		Assert(t, ids, []values.PostId{older.Id}, "the second page of the tag")
+		AssertClientError(t, getTagPosts(t, "42", ""), client_errors.InvalidTag)
+
+		// trending tags are updated periodically
+		Assert(t, getTrending(t), []responses.TrendingTagResponse{}, "trending tags before the update")
+		AssertNoError(t, updateTrendingTags(context.Background()))
+		wantTrending := []responses.TrendingTagResponse{{Tag: "golang", Uses: 2}, {Tag: "go", Uses: 1}}
+		Assert(t, getTrending(t), wantTrending, "trending tags")
+
+		// editing a post updates its tags
+		response := editPost(t, older.Id, user1, "no more tags", nil, nil)
+		AssertStatusCode(t, response, http.StatusOK)
+		ids, _ = getTagPostIds(t, "golang", "")
+		Assert(t, ids, []values.PostId{newer.Id}, "posts with the tag after edit")
+
+		deletePost(t, older.Id, user1)
+		deletePost(t, newer.Id, user1)
+		AssertNoError(t, updateTrendingTags(context.Background()))
+		Assert(t, getTrending(t), []responses.TrendingTagResponse{}, "trending tags after the posts were deleted")
+	})
+	t.Run("mentioning profiles in posts", func(t *testing.T) {
+		mentioned := auth.User{Id: RandomId(), Username: "mentioned_" + RandomId()}
+		fakeRegisterProfile(mentioned)
+
+		// mentions of unknown usernames and email addresses are left as plain text
+		createPost(t, user1, [][]byte{}, "@"+mentioned.Username+", meet @nobody_here at me@example.com")
+		post := getPosts(t, user1.Id, user1)[0]
+		wantMentions := []mentionable_responses.MentionResponse{
+			{ProfileId: mentioned.Id, Offset: 0, Length: len(mentioned.Username) + 1},
+		}
+		Assert(t, post.Mentions, wantMentions, "mentions of the created post")
+
+		// editing the text replaces the mentions
+		response := editPost(t, post.Id, user1, "now with @"+mentioned.Username, nil, nil)
+		AssertStatusCode(t, response, http.StatusOK)
+		wantMentions = []mentionable_responses.MentionResponse{
+			{ProfileId: mentioned.Id, Offset: 9, Length: len(mentioned.Username) + 1},
+		}
+		Assert(t, getPost(t, post.Id, user1).Mentions, wantMentions, "mentions of the edited post")
+
+		deletePost(t, post.Id, user1)
+	})
 }
 
 func readFixture(t testing.TB, filename string) []byte {
 	t.Helper()
-	return readFile(t, filepath.Join("..", "testdata", filename)) // ".." since we change the working directory to tmp_test
+	return readFile(t, filepath.Join("testdata", filename))
 }
 
 func readFile(t testing.TB, filepath string) []byte {
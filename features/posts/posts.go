@@ -4,19 +4,26 @@ import (
 	"github.com/jmoiron/sqlx"
 	"github.com/k0marov/go-socnet/core/abstract/deletable"
 	"github.com/k0marov/go-socnet/core/abstract/likeable"
+	"github.com/k0marov/go-socnet/core/abstract/mentionable"
 	"github.com/k0marov/go-socnet/core/abstract/ownable"
 	"github.com/k0marov/go-socnet/core/abstract/ownable_likeable"
 	likeable_contexters "github.com/k0marov/go-socnet/core/abstract/ownable_likeable/contexters"
 	"github.com/k0marov/go-socnet/core/abstract/recommendable"
+	"github.com/k0marov/go-socnet/core/abstract/table_name"
+	"github.com/k0marov/go-socnet/core/general/config"
+	"github.com/k0marov/go-socnet/core/general/events"
 	"github.com/k0marov/go-socnet/core/general/image_decoder"
 	static_store2 "github.com/k0marov/go-socnet/core/general/static_store"
+	"github.com/k0marov/go-socnet/core/general/unit_of_work"
 	"log"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/k0marov/go-socnet/features/posts/delivery/http/handlers"
 	"github.com/k0marov/go-socnet/features/posts/delivery/http/router"
 	"github.com/k0marov/go-socnet/features/posts/domain/contexters"
 	"github.com/k0marov/go-socnet/features/posts/domain/service"
+	store_contracts "github.com/k0marov/go-socnet/features/posts/domain/store"
 	"github.com/k0marov/go-socnet/features/posts/domain/validators"
 	"github.com/k0marov/go-socnet/features/posts/store"
 	"github.com/k0marov/go-socnet/features/posts/store/file_storage"
@@ -24,20 +31,118 @@ import (
 	profile_service "github.com/k0marov/go-socnet/features/profiles/domain/service"
 )
 
-func NewPostRecommendable(db *sqlx.DB) recommendable.Recommendable {
+func NewPostRecommendable(db *sqlx.DB, followedTableName table_name.TableName) recommendable.Recommendable {
 	sqlDB, err := sql_db.NewSqlDB(db)
 	if err != nil {
 		log.Fatalf("error while opening sql db for posts: %v", err)
 	}
 	// recommendable
-	recommendablePost, err := recommendable.NewRecommendable(db, sqlDB.TableName)
+	recommendablePost, err := recommendable.NewRecommendable(db, sqlDB.TableName, followedTableName)
 	if err != nil {
 		log.Fatalf("error while creating a Post recommendable: %v", err)
 	}
 	return recommendablePost
 }
 
-func NewPostsRouterImpl(db *sqlx.DB, getContextedProfile profile_service.ProfileGetter) func(chi.Router) {
+// NewLikeCountsRebuilderImpl returns a rebuilder of the stored post likes counters
+func NewLikeCountsRebuilderImpl(db *sqlx.DB) likeable.CountsRebuilder {
+	sqlDB, err := sql_db.NewSqlDB(db)
+	if err != nil {
+		log.Fatalf("error while opening sql db for posts: %v", err)
+	}
+	likeablePost, err := likeable.NewLikeable(db, sqlDB.TableName)
+	if err != nil {
+		log.Fatalf("error while creating a Post likeable: %v", err)
+	}
+	return likeablePost.RebuildCounts
+}
+
+// NewReactionCountsGetterImpl is used to push the likes of posts to the clients viewing them
+func NewReactionCountsGetterImpl(db *sqlx.DB) likeable.ReactionCountsGetter {
+	sqlDB, err := sql_db.NewSqlDB(db)
+	if err != nil {
+		log.Fatalf("error while opening sql db for posts: %v", err)
+	}
+	likeablePost, err := likeable.NewLikeable(db, sqlDB.TableName)
+	if err != nil {
+		log.Fatalf("error while creating a Post likeable: %v", err)
+	}
+	return likeablePost.GetReactionCounts
+}
+
+type PostListing struct {
+	GetByIds     store_contracts.PostsByIdsGetter
+	GetByAuthors store_contracts.AuthorsPostsGetter
+	AddContext   contexters.PostListContextAdder
+}
+
+// NewPostListingImpl is used by other features that list posts
+func NewPostListingImpl(cfg config.Config, db *sqlx.DB, getProfiles profile_service.ProfilesGetter, getCommentCounts store_contracts.CommentCountsGetter, getCommentPreviews contexters.CommentPreviewsGetter) PostListing {
+	sqlDB, err := sql_db.NewSqlDB(db)
+	if err != nil {
+		log.Fatalf("error while opening sql db for posts: %v", err)
+	}
+	likeablePost, err := likeable.NewLikeable(db, sqlDB.TableName)
+	if err != nil {
+		log.Fatalf("error while creating a Post likeable: %v", err)
+	}
+	mentionablePost, err := mentionable.NewMentionable(db, sqlDB.TableName)
+	if err != nil {
+		log.Fatalf("error while creating a Post mentionable: %v", err)
+	}
+	toURL := static_store2.NewPathToURLConverter(cfg.Static.Host)
+	return PostListing{
+		GetByIds:     store.NewStorePostsByIdsGetter(sqlDB.GetPostsByIds, likeablePost.GetReactionCountsBatch, getCommentCounts, mentionablePost.GetMentionsBatch, toURL),
+		GetByAuthors: store.NewStoreAuthorsPostsGetter(sqlDB.GetPostsByAuthors, likeablePost.GetReactionCountsBatch, getCommentCounts, mentionablePost.GetMentionsBatch, toURL),
+		AddContext:   contexters.NewPostListContextAdder(getProfiles, likeable_contexters.NewOwnLikeContextsGetter(likeablePost.GetReactionsBatch), getCommentPreviews),
+	}
+}
+
+// NewPostModelGetterImpl is used by the comments feature to moderate the comments of posts
+func NewPostModelGetterImpl(db *sqlx.DB) store_contracts.PostModelGetter {
+	sqlDB, err := sql_db.NewSqlDB(db)
+	if err != nil {
+		log.Fatalf("error while opening sql db for posts: %v", err)
+	}
+	return sqlDB.GetPost
+}
+
+// NewTagsRouterImpl serves the posts with a tag and the trending tags, which are recomputed by the job from NewTrendingTagsUpdaterImpl
+func NewTagsRouterImpl(cfg config.Config, db *sqlx.DB, getProfiles profile_service.ProfilesGetter, getCommentCounts store_contracts.CommentCountsGetter, getCommentPreviews contexters.CommentPreviewsGetter) func(chi.Router) {
+	sqlDB, err := sql_db.NewSqlDB(db)
+	if err != nil {
+		log.Fatalf("error while opening sql db for posts: %v", err)
+	}
+	likeablePost, err := likeable.NewLikeable(db, sqlDB.TableName)
+	if err != nil {
+		log.Fatalf("error while creating a Post likeable: %v", err)
+	}
+	mentionablePost, err := mentionable.NewMentionable(db, sqlDB.TableName)
+	if err != nil {
+		log.Fatalf("error while creating a Post mentionable: %v", err)
+	}
+	toURL := static_store2.NewPathToURLConverter(cfg.Static.Host)
+	storeGetTagPosts := store.NewStoreTagPostsGetter(sqlDB.GetPostsByTag, likeablePost.GetReactionCountsBatch, getCommentCounts, mentionablePost.GetMentionsBatch, toURL)
+	addContext := contexters.NewPostListContextAdder(getProfiles, likeable_contexters.NewOwnLikeContextsGetter(likeablePost.GetReactionsBatch), getCommentPreviews)
+
+	getTagPosts := service.NewTagPostsGetter(storeGetTagPosts, addContext)
+	getTrending := service.NewTrendingTagsGetter(sqlDB.GetTrendingTags)
+
+	return router.NewTagsRouter(handlers.NewGetTrendingTagsHandler(getTrending), handlers.NewGetTagPostsHandler(getTagPosts))
+}
+
+// NewTrendingTagsUpdaterImpl returns an updater which ranks the count most used tags during the last window
+func NewTrendingTagsUpdaterImpl(db *sqlx.DB, window time.Duration, count int) service.TrendingTagsUpdater {
+	sqlDB, err := sql_db.NewSqlDB(db)
+	if err != nil {
+		log.Fatalf("error while opening sql db for posts: %v", err)
+	}
+	return service.NewTrendingTagsUpdater(window, count, sqlDB.UpdateTrendingTags)
+}
+
+// NewPostsRouterImpl gets the comment counts and previews from the comments feature, which itself depends on posts.
+// It publishes the events of liking posts.
+func NewPostsRouterImpl(cfg config.Config, db *sqlx.DB, getContextedProfile profile_service.ProfileGetter, getProfiles profile_service.ProfilesGetter, getCommentCounts store_contracts.CommentCountsGetter, getCommentPreviews contexters.CommentPreviewsGetter, resolveUsernames mentionable.UsernamesResolver, publish events.Publisher) func(chi.Router) {
 	// db
 	sqlDB, err := sql_db.NewSqlDB(db)
 	if err != nil {
@@ -56,8 +161,14 @@ func NewPostsRouterImpl(db *sqlx.DB, getContextedProfile profile_service.Profile
 		log.Fatalf("error while creating a Post ownable: %v", err)
 	}
 
+	// mentionable
+	mentionablePost, err := mentionable.NewMentionable(db, sqlDB.TableName)
+	if err != nil {
+		log.Fatalf("error while creating a Post mentionable: %v", err)
+	}
+
 	// OwnableLikeable
-	ownableLikeablePost := ownable_likeable.NewOwnableLikeable(ownablePost.GetOwner, likeablePost.ToggleLike)
+	ownableLikeablePost := ownable_likeable.NewOwnableLikeable(ownablePost.GetOwner, likeablePost.ToggleLike, likeablePost.Like, likeablePost.Unlike, likeablePost.SetReaction, events.Post, publish)
 
 	// deletable
 	deletablePost, err := deletable.NewDeletable(db, sqlDB.TableName, ownablePost.GetOwner)
@@ -66,30 +177,53 @@ func NewPostsRouterImpl(db *sqlx.DB, getContextedProfile profile_service.Profile
 	}
 
 	// file storage
-	storeImages := file_storage.NewPostImageFilesCreator(static_store2.NewStaticFileCreatorImpl())
-	deleteFiles := file_storage.NewPostFilesDeleter(static_store2.NewStaticDirDeleterImpl())
+	storeImages := file_storage.NewPostImageFilesCreator()
+	deleteFiles := file_storage.NewPostFilesDeleter(static_store2.NewStaticDirDeleterImpl(cfg.Static.Dir))
+	storeEditedImages := file_storage.NewEditedPostImageFilesCreator()
 
 	// store
-	storeCreatePost := store.NewStorePostCreator(sqlDB.CreatePost, storeImages, sqlDB.AddPostImages, deletablePost.ForceDelete, deleteFiles)
-	storeDeletePost := store.NewStorePostDeleter(deletablePost.ForceDelete, deleteFiles)
-	storeGetPosts := store.NewStorePostsGetter(sqlDB.GetPosts, likeablePost.GetLikesCount)
+	toURL := static_store2.NewPathToURLConverter(cfg.Static.Host)
+	runInUnit := unit_of_work.NewRunnerImpl(db, cfg.Static.Dir)
+	storeCreatePost := store.NewStorePostCreator(runInUnit, sqlDB.CreatePost, storeImages, sqlDB.AddPostImages, mentionablePost.AddMentions, sqlDB.AddPostTags)
+	storeDeletePost := store.NewStorePostDeleter(runInUnit, deletablePost.ForceDelete, deleteFiles)
+	storeGetPosts := store.NewStorePostsGetter(sqlDB.GetPosts, likeablePost.GetReactionCountsBatch, getCommentCounts, mentionablePost.GetMentionsBatch, toURL)
+	storeGetPost := store.NewStorePostGetter(sqlDB.GetPost, likeablePost.GetReactionCountsBatch, getCommentCounts, mentionablePost.GetMentionsBatch, toURL)
+	storeUpdatePost := store.NewStorePostUpdater(runInUnit, storeEditedImages, sqlDB.UpdatePost, mentionablePost.ReplaceMentions, sqlDB.UpdatePostTags, static_store2.NewStaticFileDeleterImpl(cfg.Static.Dir))
 
 	// service
-	validatePost := validators.NewPostValidator(image_decoder.ImageDecoderImpl)
+	validatePost := validators.NewPostValidator(cfg.Limits.MaxPostTextLength, image_decoder.ImageDecoderImpl)
+	resolveMentions := mentionable.NewMentionsResolver(resolveUsernames)
 
 	// contexters
-	addContext := contexters.NewPostListContextAdder(contexters.NewPostContextAdder(getContextedProfile, likeable_contexters.NewOwnLikeContextGetter(likeablePost.IsLiked)))
+	addPostContext := contexters.NewPostContextAdder(getContextedProfile, likeable_contexters.NewOwnLikeContextGetter(likeablePost.GetReaction), getCommentPreviews)
+	addContext := contexters.NewPostListContextAdder(getProfiles, likeable_contexters.NewOwnLikeContextsGetter(likeablePost.GetReactionsBatch), getCommentPreviews)
 
-	createPost := service.NewPostCreator(validatePost, storeCreatePost)
+	createPost := service.NewPostCreator(validatePost, resolveMentions, storeCreatePost, publish)
 	deletePost := service.NewPostDeleter(ownablePost.GetOwner, storeDeletePost)
 	getPosts := service.NewPostsGetter(storeGetPosts, addContext)
+	getPost := service.NewPostGetter(storeGetPost, addPostContext)
+	updatePost := service.NewPostUpdater(ownablePost.GetOwner, validatePost, storeGetPost, resolveMentions, storeUpdatePost, getPost, publish)
 	toggleLike := service.NewPostLikeToggler(ownableLikeablePost.SafeToggleLike)
+	like := service.NewPostLiker(ownableLikeablePost.SafeLike)
+	unlike := service.NewPostUnliker(ownableLikeablePost.SafeUnlike)
+	setReaction := service.NewPostReactionSetter(ownableLikeablePost.SafeSetReaction)
+	removeReaction := service.NewPostReactionRemover(ownableLikeablePost.SafeUnlike)
+	getLikers := service.NewPostLikersGetter(ownablePost.GetOwner, profile_service.NewLikersGetter(likeablePost.GetLikers, getContextedProfile))
+	disableComments := service.NewCommentsDisabler(ownablePost.GetOwner, sqlDB.SetCommentsDisabled)
 
 	// handlers
 	createPostHandler := handlers.NewCreateHandler(createPost)
 	deletePostHandler := handlers.NewDeleteHandler(deletePost)
 	getPostsHandler := handlers.NewGetListByIdHandler(getPosts)
+	getPostHandler := handlers.NewGetHandler(getPost)
+	updatePostHandler := handlers.NewUpdateHandler(updatePost)
 	toggleLikeHandler := handlers.NewToggleLikeHandler(toggleLike)
+	likeHandler := handlers.NewLikeHandler(like)
+	unlikeHandler := handlers.NewUnlikeHandler(unlike)
+	setReactionHandler := handlers.NewSetReactionHandler(setReaction)
+	removeReactionHandler := handlers.NewRemoveReactionHandler(removeReaction)
+	getLikersHandler := handlers.NewGetLikersHandler(getLikers)
+	setCommentsDisabledHandler := handlers.NewSetCommentsDisabledHandler(disableComments)
 
-	return router.NewPostsRouter(createPostHandler, getPostsHandler, deletePostHandler, toggleLikeHandler)
+	return router.NewPostsRouter(createPostHandler, getPostsHandler, getPostHandler, updatePostHandler, deletePostHandler, toggleLikeHandler, likeHandler, unlikeHandler, setReactionHandler, removeReactionHandler, getLikersHandler, setCommentsDisabledHandler)
 }
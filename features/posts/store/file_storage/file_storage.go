@@ -6,6 +6,7 @@ import (
 	"github.com/k0marov/go-socnet/core/general/static_store"
 	"path/filepath"
 	"strconv"
+	"time"
 
 	"github.com/k0marov/go-socnet/features/posts/domain/values"
 
@@ -15,22 +16,36 @@ import (
 const PostPrefix = "post_"
 const ImagePrefix = "image_"
 
-type PostImageFilesCreator = func(values.PostId, core_values.UserId, []values.PostImageFile) ([]core_values.StaticPath, error)
+// PostImageFilesCreator stores the images of a post using the provided file creator, e.g. the one of a unit of work
+type PostImageFilesCreator = func(static_store.StaticFileCreator, values.PostId, core_values.UserId, []values.PostImageFile) ([]core_values.StaticPath, error)
+type EditedPostImageFilesCreator = func(static_store.StaticFileCreator, values.PostId, core_values.UserId, []values.PostImageFile, time.Time) ([]core_values.StaticPath, error)
 type PostFilesDeleter = func(values.PostId, core_values.UserId) error
 
-func NewPostImageFilesCreator(createFile static_store.StaticFileCreator) PostImageFilesCreator {
-	return func(post values.PostId, author core_values.UserId, images []values.PostImageFile) (paths []core_values.StaticPath, err error) {
-		dir := filepath.Join(profiles.ProfilePrefix+author, PostPrefix+post)
-		for _, image := range images {
-			filename := ImagePrefix + strconv.Itoa(image.Index)
-			path, err := createFile(image.File, dir, filename)
-			if err != nil {
-				return paths, core_err.Rethrow("storing a file", err)
-			}
-			paths = append(paths, path)
+func NewPostImageFilesCreator() PostImageFilesCreator {
+	return func(createFile static_store.StaticFileCreator, post values.PostId, author core_values.UserId, images []values.PostImageFile) ([]core_values.StaticPath, error) {
+		return storeImages(createFile, post, author, images, "")
+	}
+}
+
+// NewEditedPostImageFilesCreator stores the images added while editing a post.
+// Their filenames include the edit time so that they never overwrite the images that the post already has.
+func NewEditedPostImageFilesCreator() EditedPostImageFilesCreator {
+	return func(createFile static_store.StaticFileCreator, post values.PostId, author core_values.UserId, images []values.PostImageFile, editedAt time.Time) ([]core_values.StaticPath, error) {
+		return storeImages(createFile, post, author, images, "_"+strconv.FormatInt(editedAt.UnixNano(), 10))
+	}
+}
+
+func storeImages(createFile static_store.StaticFileCreator, post values.PostId, author core_values.UserId, images []values.PostImageFile, filenameSuffix string) (paths []core_values.StaticPath, err error) {
+	dir := filepath.Join(profiles.ProfilePrefix+author, PostPrefix+post)
+	for _, image := range images {
+		filename := ImagePrefix + strconv.Itoa(image.Index) + filenameSuffix
+		path, err := createFile(image.File, dir, filename)
+		if err != nil {
+			return paths, core_err.Rethrow("storing a file", err)
 		}
-		return
+		paths = append(paths, path)
 	}
+	return
 }
 
 func NewPostFilesDeleter(deleteDir static_store.StaticDirDeleter) PostFilesDeleter {
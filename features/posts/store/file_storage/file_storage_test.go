@@ -29,7 +29,7 @@ func TestPostImageFilesCreator(t *testing.T) {
 			}
 			panic("unexpected args")
 		}
-		gotPaths, err := file_storage.NewPostImageFilesCreator(createFile)(post, author, images)
+		gotPaths, err := file_storage.NewPostImageFilesCreator()(createFile, post, author, images)
 		AssertNoError(t, err)
 		Assert(t, gotPaths, paths, "returned paths")
 		Assert(t, filesStored, len(images), "number of stored files")
@@ -38,7 +38,35 @@ func TestPostImageFilesCreator(t *testing.T) {
 		createFile := func(core_values.FileData, string, string) (core_values.StaticPath, error) {
 			return "", RandomError()
 		}
-		_, err := file_storage.NewPostImageFilesCreator(createFile)(post, author, images)
+		_, err := file_storage.NewPostImageFilesCreator()(createFile, post, author, images)
+		AssertSomeError(t, err)
+	})
+}
+
+func TestEditedPostImageFilesCreator(t *testing.T) {
+	post := RandomString()
+	author := RandomString()
+	editedAt := RandomTime()
+	images := []values.PostImageFile{{File: RandomFileData(), Index: 2}}
+	path := RandomString()
+	t.Run("happy case", func(t *testing.T) {
+		wantDir := filepath.Join(profiles.ProfilePrefix+author, file_storage.PostPrefix+post)
+		wantFilename := file_storage.ImagePrefix + "2_" + strconv.FormatInt(editedAt.UnixNano(), 10)
+		createFile := func(file core_values.FileData, dir string, filename string) (core_values.StaticPath, error) {
+			if filename == wantFilename && dir == wantDir && reflect.DeepEqual(file, images[0].File) {
+				return path, nil
+			}
+			panic("unexpected args")
+		}
+		gotPaths, err := file_storage.NewEditedPostImageFilesCreator()(createFile, post, author, images, editedAt)
+		AssertNoError(t, err)
+		Assert(t, gotPaths, []core_values.StaticPath{path}, "returned paths")
+	})
+	t.Run("error case", func(t *testing.T) {
+		createFile := func(core_values.FileData, string, string) (core_values.StaticPath, error) {
+			return "", RandomError()
+		}
+		_, err := file_storage.NewEditedPostImageFilesCreator()(createFile, post, author, images, editedAt)
 		AssertSomeError(t, err)
 	})
 }
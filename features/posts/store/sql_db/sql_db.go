@@ -1,98 +1,282 @@
 package sql_db
 
 import (
+	"context"
+	"database/sql"
 	"fmt"
 	"github.com/jmoiron/sqlx"
 	"github.com/k0marov/go-socnet/core/abstract/table_name"
 	"github.com/k0marov/go-socnet/core/general/core_err"
 	"github.com/k0marov/go-socnet/core/general/core_values"
+	"github.com/k0marov/go-socnet/core/general/pagination"
+	"github.com/k0marov/go-socnet/core/general/unit_of_work"
 	"github.com/k0marov/go-socnet/features/posts/domain/models"
 	"github.com/k0marov/go-socnet/features/posts/domain/values"
+	"time"
 )
 
+const postColumns = "id, owner_id, textContent, createdAt, editedAt, commentsDisabled"
+
 type SqlDB struct {
 	sql       *sqlx.DB
 	TableName table_name.TableName
 }
 
+// NewSqlDB expects the schema to be already migrated with core/general/migrations
 func NewSqlDB(sql *sqlx.DB) (*SqlDB, error) {
-	err := initSQL(sql)
-	if err != nil {
-		return nil, err
-	}
 	return &SqlDB{sql: sql, TableName: table_name.NewTableName("Post")}, nil
 }
 
-func initSQL(sql *sqlx.DB) error {
-	_, err := sql.Exec(`
-		CREATE TABLE IF NOT EXISTS Post(
-		    id INTEGER PRIMARY KEY, 
-			owner_id INT NOT NULL, 
-			textContent TEXT NOT NULL, 
-			createdAt INT NOT NULL, 
-			FOREIGN KEY(owner_id) REFERENCES Profile(id) ON DELETE CASCADE
-		)
-	`)
+func (db *SqlDB) GetPost(id values.PostId) (models.PostModel, error) {
+	var post models.PostModel
+	err := db.sql.Get(&post, db.sql.Rebind(`
+		SELECT `+postColumns+`
+		FROM Post
+		WHERE id = ?
+	`), id)
+	if err == sql.ErrNoRows {
+		return models.PostModel{}, core_err.ErrNotFound
+	}
 	if err != nil {
-		return core_err.Rethrow("creating Post table", err)
+		return models.PostModel{}, core_err.Rethrow("getting a post from db", err)
 	}
-	_, err = sql.Exec(`
-		CREATE TABLE IF NOT EXISTS PostImage(
-		    post_id INT NOT NULL, 
-		    path VARCHAR(255), 
-		    ind INT,
-		    FOREIGN KEY(post_id) REFERENCES Post(id) ON DELETE CASCADE
-		)	
-	`)
+	post.Images, err = db.getImages(post.Id)
 	if err != nil {
-		return core_err.Rethrow("creating PostImage table", err)
+		return models.PostModel{}, err
 	}
-	return nil
+	return post, nil
 }
 
-func (db *SqlDB) GetPosts(author core_values.UserId) (posts []models.PostModel, err error) {
-	rows, err := db.sql.Query(`
-		SELECT id, owner_id, textContent, createdAt
+func (db *SqlDB) GetPosts(author core_values.UserId, page pagination.Page) (posts []models.PostModel, err error) {
+	cond, condArgs := page.Condition("createdAt", "id")
+	args := append([]any{author}, condArgs...)
+	err = db.sql.Select(&posts, db.sql.Rebind(`
+		SELECT `+postColumns+`
 		FROM Post 
-		WHERE owner_id = ?
-		ORDER BY createdAt DESC
-	`, author)
+		WHERE owner_id = ? AND `+cond+`
+		ORDER BY createdAt DESC, id DESC
+		LIMIT ?
+	`), append(args, page.Limit)...)
 	if err != nil {
 		return []models.PostModel{}, core_err.Rethrow("getting posts from db", err)
 	}
-	defer rows.Close()
-	for rows.Next() {
-		post := models.PostModel{}
-		err = rows.Scan(&post.Id, &post.AuthorId, &post.Text, &post.CreatedAt)
-		if err != nil {
-			return []models.PostModel{}, core_err.Rethrow("scanning a post", err)
-		}
-		post.Images, err = db.getImages(post.Id)
-		if err != nil {
-			return []models.PostModel{}, err
+	err = db.addImages(posts)
+	if err != nil {
+		return []models.PostModel{}, err
+	}
+	return posts, nil
+}
+
+func (db *SqlDB) GetPostsByAuthors(authors []core_values.UserId, page pagination.Page) (posts []models.PostModel, err error) {
+	if len(authors) == 0 {
+		return []models.PostModel{}, nil
+	}
+	cond, condArgs := page.Condition("createdAt", "id")
+	args := append([]any{authors}, condArgs...)
+	args = append(args, page.Limit)
+	query, args, err := sqlx.In(`
+		SELECT `+postColumns+`
+		FROM Post
+		WHERE owner_id IN (?) AND `+cond+`
+		ORDER BY createdAt DESC, id DESC
+		LIMIT ?
+	`, args...)
+	if err != nil {
+		return []models.PostModel{}, core_err.Rethrow("building the query for posts of authors", err)
+	}
+	err = db.sql.Select(&posts, db.sql.Rebind(query), args...)
+	if err != nil {
+		return []models.PostModel{}, core_err.Rethrow("getting posts of authors from db", err)
+	}
+	err = db.addImages(posts)
+	if err != nil {
+		return []models.PostModel{}, err
+	}
+	return posts, nil
+}
+
+// GetPostsByIds returns the posts in the order of provided ids, skipping the ones that don't exist
+func (db *SqlDB) GetPostsByIds(ids []values.PostId) ([]models.PostModel, error) {
+	if len(ids) == 0 {
+		return []models.PostModel{}, nil
+	}
+	query, args, err := sqlx.In(`
+		SELECT `+postColumns+`
+		FROM Post
+		WHERE id IN (?)
+	`, ids)
+	if err != nil {
+		return []models.PostModel{}, core_err.Rethrow("building the query for posts by ids", err)
+	}
+	var found []models.PostModel
+	err = db.sql.Select(&found, db.sql.Rebind(query), args...)
+	if err != nil {
+		return []models.PostModel{}, core_err.Rethrow("getting posts by ids from db", err)
+	}
+	foundById := map[values.PostId]models.PostModel{}
+	for _, post := range found {
+		foundById[post.Id] = post
+	}
+	posts := []models.PostModel{}
+	for _, id := range ids {
+		post, ok := foundById[id]
+		if !ok {
+			continue
 		}
 		posts = append(posts, post)
 	}
+	err = db.addImages(posts)
+	if err != nil {
+		return []models.PostModel{}, err
+	}
 	return posts, nil
 }
 
-func (db *SqlDB) CreatePost(newPost models.PostToCreate) (values.PostId, error) {
-	res, err := db.sql.Exec(`
-		INSERT INTO Post(owner_id, textContent, createdAt) VALUES (?, ?, ?)
-	`, newPost.Author, newPost.Text, newPost.CreatedAt.Unix())
+func (db *SqlDB) CreatePost(ex unit_of_work.Executor, newPost models.PostToCreate) (values.PostId, error) {
+	var id int64
+	err := ex.QueryRowx(ex.Rebind(`
+		INSERT INTO Post(owner_id, textContent, createdAt) VALUES (?, ?, ?) RETURNING id
+	`), newPost.Author, newPost.Text, newPost.CreatedAt.Unix()).Scan(&id)
 	if err != nil {
 		return "", core_err.Rethrow("inserting a post", err)
 	}
-	id, err := res.LastInsertId()
+	return fmt.Sprintf("%d", id), nil
+}
+
+// UpdatePost replaces the text and the whole list of images of a post
+func (db *SqlDB) UpdatePost(ex unit_of_work.Executor, id values.PostId, upd models.PostToUpdate) error {
+	_, err := ex.Exec(ex.Rebind(`
+		UPDATE Post SET textContent = ?, editedAt = ? WHERE id = ?
+	`), upd.Text, upd.EditedAt.Unix(), id)
+	if err != nil {
+		return core_err.Rethrow("updating a post", err)
+	}
+	_, err = ex.Exec(ex.Rebind(`
+		DELETE FROM PostImage WHERE post_id = ?
+	`), id)
 	if err != nil {
-		return "", core_err.Rethrow("getting the inserted post id", err)
+		return core_err.Rethrow("deleting the old post images", err)
 	}
-	return fmt.Sprintf("%d", id), nil
+	return db.AddPostImages(ex, id, upd.Images)
+}
+
+func (db *SqlDB) SetCommentsDisabled(id values.PostId, disabled bool) error {
+	_, err := db.sql.Exec(db.sql.Rebind(`
+		UPDATE Post SET commentsDisabled = ? WHERE id = ?
+	`), disabled, id)
+	if err != nil {
+		return core_err.Rethrow("updating commentsDisabled of a post", err)
+	}
+	return nil
 }
 
-func (db *SqlDB) AddPostImages(post values.PostId, images []models.PostImageModel) error {
+// GetPostsByTag returns the posts with the normalized tag, newest first
+func (db *SqlDB) GetPostsByTag(tag values.Tag, page pagination.Page) (posts []models.PostModel, err error) {
+	cond, condArgs := page.Condition("createdAt", "id")
+	args := append([]any{tag}, condArgs...)
+	err = db.sql.Select(&posts, db.sql.Rebind(`
+		SELECT `+postColumns+`
+		FROM Post
+		WHERE id IN (
+			SELECT post_id FROM PostTag JOIN Tag ON Tag.id = PostTag.tag_id WHERE Tag.name = ?
+		) AND `+cond+`
+		ORDER BY createdAt DESC, id DESC
+		LIMIT ?
+	`), append(args, page.Limit)...)
+	if err != nil {
+		return []models.PostModel{}, core_err.Rethrow("getting posts with a tag from db", err)
+	}
+	err = db.addImages(posts)
+	if err != nil {
+		return []models.PostModel{}, err
+	}
+	return posts, nil
+}
+
+// AddPostTags adds the normalized tags to a post, creating the ones that weren't used before.
+// The tags that the post already has are left untouched, so they keep their usedAt.
+func (db *SqlDB) AddPostTags(ex unit_of_work.Executor, post values.PostId, tags []values.Tag, usedAt time.Time) error {
+	for _, tag := range tags {
+		_, err := ex.Exec(ex.Rebind(`
+			INSERT INTO Tag(name) VALUES (?) ON CONFLICT(name) DO NOTHING
+		`), tag)
+		if err != nil {
+			return core_err.Rethrow("inserting a tag", err)
+		}
+		_, err = ex.Exec(ex.Rebind(`
+			INSERT INTO PostTag(post_id, tag_id, usedAt)
+			SELECT ?, id, ? FROM Tag WHERE name = ?
+			ON CONFLICT(post_id, tag_id) DO NOTHING
+		`), post, usedAt.Unix(), tag)
+		if err != nil {
+			return core_err.Rethrow("inserting a tag of a post", err)
+		}
+	}
+	return nil
+}
+
+// UpdatePostTags removes the tags that are no longer in the post and adds the new ones
+func (db *SqlDB) UpdatePostTags(ex unit_of_work.Executor, post values.PostId, tags []values.Tag, usedAt time.Time) error {
+	var err error
+	query, args := `DELETE FROM PostTag WHERE post_id = ?`, []any{post}
+	if len(tags) > 0 {
+		query, args, err = sqlx.In(query+` AND tag_id NOT IN (SELECT id FROM Tag WHERE name IN (?))`, post, tags)
+		if err != nil {
+			return core_err.Rethrow("building the query for removed tags of a post", err)
+		}
+	}
+	_, err = ex.Exec(ex.Rebind(query), args...)
+	if err != nil {
+		return core_err.Rethrow("deleting the removed tags of a post", err)
+	}
+	return db.AddPostTags(ex, post, tags, usedAt)
+}
+
+func (db *SqlDB) GetTrendingTags() ([]values.TrendingTag, error) {
+	tags := []values.TrendingTag{}
+	err := db.sql.Select(&tags, `
+		SELECT Tag.name, TrendingTag.uses
+		FROM TrendingTag JOIN Tag ON Tag.id = TrendingTag.tag_id
+		ORDER BY TrendingTag.uses DESC, Tag.name
+	`)
+	if err != nil {
+		return []values.TrendingTag{}, core_err.Rethrow("getting trending tags from db", err)
+	}
+	return tags, nil
+}
+
+// UpdateTrendingTags replaces the trending tags with the count most used tags since the provided time
+func (db *SqlDB) UpdateTrendingTags(ctx context.Context, since time.Time, count int) error {
+	tx, err := db.sql.BeginTxx(ctx, nil)
+	if err != nil {
+		return core_err.Rethrow("starting a transaction", err)
+	}
+	defer tx.Rollback()
+	_, err = tx.ExecContext(ctx, `DELETE FROM TrendingTag`)
+	if err != nil {
+		return core_err.Rethrow("deleting the old trending tags", err)
+	}
+	_, err = tx.ExecContext(ctx, tx.Rebind(`
+		INSERT INTO TrendingTag(tag_id, uses)
+		SELECT tag_id, COUNT(*) FROM PostTag
+		WHERE usedAt >= ?
+		GROUP BY tag_id
+		ORDER BY COUNT(*) DESC, tag_id
+		LIMIT ?
+	`), since.Unix(), count)
+	if err != nil {
+		return core_err.Rethrow("inserting the new trending tags", err)
+	}
+	err = tx.Commit()
+	if err != nil {
+		return core_err.Rethrow("committing the trending tags", err)
+	}
+	return nil
+}
+
+func (db *SqlDB) AddPostImages(ex unit_of_work.Executor, post values.PostId, images []models.PostImageModel) error {
 	for _, image := range images {
-		err := db.addImage(post, image)
+		err := db.addImage(ex, post, image)
 		if err != nil {
 			return err
 		}
@@ -100,10 +284,10 @@ func (db *SqlDB) AddPostImages(post values.PostId, images []models.PostImageMode
 	return nil
 }
 
-func (db *SqlDB) addImage(post values.PostId, image models.PostImageModel) error {
-	_, err := db.sql.Exec(`
+func (db *SqlDB) addImage(ex unit_of_work.Executor, post values.PostId, image models.PostImageModel) error {
+	_, err := ex.Exec(ex.Rebind(`
 		INSERT INTO PostImage(post_id, path, ind) VALUES (?, ?, ?)
-   `, post, image.Path, image.Index)
+   `), post, image.Path, image.Index)
 	if err != nil {
 		return core_err.Rethrow("inserting a post image", err)
 	}
@@ -111,11 +295,44 @@ func (db *SqlDB) addImage(post values.PostId, image models.PostImageModel) error
 }
 
 func (db *SqlDB) getImages(post values.PostId) (images []models.PostImageModel, err error) {
-	err = db.sql.Select(&images, `
-		SELECT path, ind FROM PostImage WHERE post_id = ?
-    `, post)
+	err = db.sql.Select(&images, db.sql.Rebind(`
+		SELECT path, ind FROM PostImage WHERE post_id = ? ORDER BY ind
+    `), post)
 	if err != nil {
 		return []models.PostImageModel{}, core_err.Rethrow("SELECTing post images", err)
 	}
 	return images, nil
 }
+
+// addImages fills in the images of all posts with a single query
+func (db *SqlDB) addImages(posts []models.PostModel) error {
+	if len(posts) == 0 {
+		return nil
+	}
+	ids := make([]values.PostId, len(posts))
+	for i, post := range posts {
+		ids[i] = post.Id
+	}
+	query, args, err := sqlx.In(`
+		SELECT post_id, path, ind FROM PostImage WHERE post_id IN (?) ORDER BY post_id, ind
+	`, ids)
+	if err != nil {
+		return core_err.Rethrow("building the query for images of posts", err)
+	}
+	var images []struct {
+		PostId values.PostId `db:"post_id"`
+		models.PostImageModel
+	}
+	err = db.sql.Select(&images, db.sql.Rebind(query), args...)
+	if err != nil {
+		return core_err.Rethrow("SELECTing images of posts", err)
+	}
+	imagesByPost := map[values.PostId][]models.PostImageModel{}
+	for _, image := range images {
+		imagesByPost[image.PostId] = append(imagesByPost[image.PostId], image.PostImageModel)
+	}
+	for i := range posts {
+		posts[i].Images = imagesByPost[posts[i].Id]
+	}
+	return nil
+}
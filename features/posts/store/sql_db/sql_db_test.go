@@ -1,44 +1,89 @@
 package sql_db_test
 
 import (
+	"context"
+	"github.com/k0marov/go-socnet/core/general/core_err"
 	"github.com/k0marov/go-socnet/core/general/core_values"
+	"github.com/k0marov/go-socnet/core/general/pagination"
+	"github.com/k0marov/go-socnet/core/general/unit_of_work"
 	. "github.com/k0marov/go-socnet/core/helpers/test_helpers"
 	"testing"
 	"time"
 
 	"github.com/k0marov/go-socnet/features/posts/domain/models"
+	"github.com/k0marov/go-socnet/features/posts/domain/values"
 	"github.com/k0marov/go-socnet/features/posts/store/sql_db"
 	profiles_db "github.com/k0marov/go-socnet/features/profiles/store/sql_db"
 	_ "github.com/mattn/go-sqlite3"
 )
 
 func TestSqlDB_ErrorHandling(t *testing.T) {
-	db := OpenSqliteDB(t)
+	db := OpenTestDB(t)
 	sut, err := sql_db.NewSqlDB(db)
 	AssertNoError(t, err)
 	db.Close() // this will force all calls to throw errors
 	t.Run("GetPosts", func(t *testing.T) {
-		_, err := sut.GetPosts(RandomString())
+		_, err := sut.GetPosts(RandomString(), pagination.Page{Limit: 10})
+		AssertSomeError(t, err)
+	})
+	t.Run("GetPost", func(t *testing.T) {
+		_, err := sut.GetPost(RandomId())
+		AssertSomeError(t, err)
+	})
+	t.Run("UpdatePost", func(t *testing.T) {
+		err := sut.UpdatePost(db, RandomId(), models.PostToUpdate{})
+		AssertSomeError(t, err)
+	})
+	t.Run("GetPostsByAuthors", func(t *testing.T) {
+		_, err := sut.GetPostsByAuthors([]core_values.UserId{RandomId()}, pagination.Page{Limit: 10})
+		AssertSomeError(t, err)
+	})
+	t.Run("GetPostsByIds", func(t *testing.T) {
+		_, err := sut.GetPostsByIds([]values.PostId{RandomId()})
 		AssertSomeError(t, err)
 	})
 	t.Run("CreatePost", func(t *testing.T) {
-		_, err := sut.CreatePost(models.PostToCreate{})
+		_, err := sut.CreatePost(db, models.PostToCreate{})
 		AssertSomeError(t, err)
 	})
 	t.Run("AddPostImages", func(t *testing.T) {
-		err := sut.AddPostImages(RandomString(), RandomPostImageModels())
+		err := sut.AddPostImages(db, RandomString(), RandomPostImageModels())
+		AssertSomeError(t, err)
+	})
+	t.Run("SetCommentsDisabled", func(t *testing.T) {
+		err := sut.SetCommentsDisabled(RandomId(), true)
+		AssertSomeError(t, err)
+	})
+	t.Run("GetPostsByTag", func(t *testing.T) {
+		_, err := sut.GetPostsByTag(RandomString(), pagination.Page{Limit: 10})
+		AssertSomeError(t, err)
+	})
+	t.Run("AddPostTags", func(t *testing.T) {
+		err := sut.AddPostTags(db, RandomId(), []values.Tag{RandomString()}, time.Now())
+		AssertSomeError(t, err)
+	})
+	t.Run("UpdatePostTags", func(t *testing.T) {
+		err := sut.UpdatePostTags(db, RandomId(), []values.Tag{RandomString()}, time.Now())
+		AssertSomeError(t, err)
+	})
+	t.Run("GetTrendingTags", func(t *testing.T) {
+		_, err := sut.GetTrendingTags()
+		AssertSomeError(t, err)
+	})
+	t.Run("UpdateTrendingTags", func(t *testing.T) {
+		err := sut.UpdateTrendingTags(context.Background(), time.Now(), 10)
 		AssertSomeError(t, err)
 	})
 }
 
 func TestSqlDB(t *testing.T) {
-	createRandomPostWithTime := func(t testing.TB, sut *sql_db.SqlDB, author core_values.UserId, createdAt time.Time) models.PostModel {
+	createRandomPostWithTime := func(t testing.TB, ex unit_of_work.Executor, sut *sql_db.SqlDB, author core_values.UserId, createdAt time.Time) models.PostModel {
 		post := models.PostToCreate{
 			Author:    author,
 			Text:      RandomString(),
 			CreatedAt: createdAt,
 		}
-		post1Id, err := sut.CreatePost(post)
+		post1Id, err := sut.CreatePost(ex, post)
 		AssertNoError(t, err)
 		return models.PostModel{
 			Id:        post1Id,
@@ -48,17 +93,17 @@ func TestSqlDB(t *testing.T) {
 			Images:    nil,
 		}
 	}
-	createRandomPost := func(t testing.TB, sut *sql_db.SqlDB, author core_values.UserId) models.PostModel {
-		return createRandomPostWithTime(t, sut, author, RandomTime())
+	createRandomPost := func(t testing.TB, ex unit_of_work.Executor, sut *sql_db.SqlDB, author core_values.UserId) models.PostModel {
+		return createRandomPostWithTime(t, ex, sut, author, RandomTime())
 	}
 	assertPosts := func(t testing.TB, sut *sql_db.SqlDB, author core_values.UserId, posts []models.PostModel) {
 		t.Helper()
-		gotPosts, err := sut.GetPosts(author)
+		gotPosts, err := sut.GetPosts(author, pagination.Page{Limit: pagination.MaxLimit})
 		AssertNoError(t, err)
 		Assert(t, gotPosts, posts, "the stored posts")
 	}
 	t.Run("creating, reading and deleting posts", func(t *testing.T) {
-		driver := OpenSqliteDB(t)
+		driver := OpenTestDB(t)
 
 		sut, err := sql_db.NewSqlDB(driver)
 		AssertNoError(t, err)
@@ -72,22 +117,21 @@ func TestSqlDB(t *testing.T) {
 		profiles.CreateProfile(user2)
 
 		// create a post for the first profile
-		wantPost1 := createRandomPost(t, sut, user1.Id)
+		wantPost1 := createRandomPost(t, driver, sut, user1.Id)
 		assertPosts(t, sut, user1.Id, []models.PostModel{wantPost1})
 		// add images to that post
 		wantPost1.Images = RandomPostImageModels()
-		err = sut.AddPostImages(wantPost1.Id, wantPost1.Images)
+		err = sut.AddPostImages(driver, wantPost1.Id, wantPost1.Images)
 		AssertNoError(t, err)
 		assertPosts(t, sut, user1.Id, []models.PostModel{wantPost1})
 		// create two posts for the second profile
-		user2Posts := []models.PostModel{
-			createRandomPost(t, sut, user2.Id),
-			createRandomPost(t, sut, user2.Id),
-		}
-		assertPosts(t, sut, user2.Id, user2Posts)
+		createdAt := RandomTime()
+		olderPost := createRandomPostWithTime(t, driver, sut, user2.Id, createdAt)
+		newerPost := createRandomPostWithTime(t, driver, sut, user2.Id, createdAt)
+		assertPosts(t, sut, user2.Id, []models.PostModel{newerPost, olderPost})
 	})
 	t.Run("returning posts ordered by createdAt", func(t *testing.T) {
-		driver := OpenSqliteDB(t)
+		driver := OpenTestDB(t)
 
 		sut, err := sql_db.NewSqlDB(driver)
 		AssertNoError(t, err)
@@ -101,10 +145,245 @@ func TestSqlDB(t *testing.T) {
 		timeInYear := func(year int) time.Time {
 			return time.Date(year, 1, 1, 1, 1, 1, 0, time.UTC)
 		}
-		oldest := createRandomPostWithTime(t, sut, profile.Id, timeInYear(1998))
-		newest := createRandomPostWithTime(t, sut, profile.Id, timeInYear(2022))
-		middle := createRandomPostWithTime(t, sut, profile.Id, timeInYear(2006))
+		oldest := createRandomPostWithTime(t, driver, sut, profile.Id, timeInYear(1998))
+		newest := createRandomPostWithTime(t, driver, sut, profile.Id, timeInYear(2022))
+		middle := createRandomPostWithTime(t, driver, sut, profile.Id, timeInYear(2006))
 		// assert they are returned in the right order
 		assertPosts(t, sut, profile.Id, []models.PostModel{newest, middle, oldest})
 	})
+	t.Run("getting paginated posts of an author", func(t *testing.T) {
+		driver := OpenTestDB(t)
+
+		sut, err := sql_db.NewSqlDB(driver)
+		AssertNoError(t, err)
+		profiles, err := profiles_db.NewSqlDB(driver)
+		AssertNoError(t, err)
+
+		author := RandomProfileModel()
+		profiles.CreateProfile(author)
+
+		timeInYear := func(year int) time.Time {
+			return time.Date(year, 1, 1, 1, 1, 1, 0, time.UTC)
+		}
+		oldest := createRandomPostWithTime(t, driver, sut, author.Id, timeInYear(2001))
+		middle := createRandomPostWithTime(t, driver, sut, author.Id, timeInYear(2002))
+		sameTime := createRandomPostWithTime(t, driver, sut, author.Id, timeInYear(2002))
+
+		page := pagination.Page{Limit: 2}
+		gotPosts, err := sut.GetPosts(author.Id, page)
+		AssertNoError(t, err)
+		Assert(t, gotPosts, []models.PostModel{sameTime, middle}, "the first page")
+
+		page.After = pagination.Cursor{CreatedAt: middle.CreatedAt, Id: middle.Id}
+		gotPosts, err = sut.GetPosts(author.Id, page)
+		AssertNoError(t, err)
+		Assert(t, gotPosts, []models.PostModel{oldest}, "the second page")
+	})
+	t.Run("getting paginated posts of many authors", func(t *testing.T) {
+		driver := OpenTestDB(t)
+
+		sut, err := sql_db.NewSqlDB(driver)
+		AssertNoError(t, err)
+		profiles, err := profiles_db.NewSqlDB(driver)
+		AssertNoError(t, err)
+
+		author1 := RandomProfileModel()
+		author2 := RandomProfileModel()
+		other := RandomProfileModel()
+		profiles.CreateProfile(author1)
+		profiles.CreateProfile(author2)
+		profiles.CreateProfile(other)
+
+		timeInYear := func(year int) time.Time {
+			return time.Date(year, 1, 1, 1, 1, 1, 0, time.UTC)
+		}
+		post1 := createRandomPostWithTime(t, driver, sut, author1.Id, timeInYear(2001))
+		post2 := createRandomPostWithTime(t, driver, sut, author2.Id, timeInYear(2002))
+		createRandomPostWithTime(t, driver, sut, other.Id, timeInYear(2003))
+		post3 := createRandomPostWithTime(t, driver, sut, author1.Id, timeInYear(2004))
+		post4 := createRandomPostWithTime(t, driver, sut, author2.Id, timeInYear(2004))
+
+		authors := []core_values.UserId{author1.Id, author2.Id}
+		page := pagination.Page{Limit: 3}
+		gotPosts, err := sut.GetPostsByAuthors(authors, page)
+		AssertNoError(t, err)
+		Assert(t, gotPosts, []models.PostModel{post4, post3, post2}, "the first page")
+
+		page.After = pagination.Cursor{CreatedAt: post2.CreatedAt, Id: post2.Id}
+		gotPosts, err = sut.GetPostsByAuthors(authors, page)
+		AssertNoError(t, err)
+		Assert(t, gotPosts, []models.PostModel{post1}, "the second page")
+
+		gotPosts, err = sut.GetPostsByAuthors([]core_values.UserId{}, page)
+		AssertNoError(t, err)
+		Assert(t, len(gotPosts), 0, "number of posts of no authors")
+	})
+	t.Run("getting posts by ids", func(t *testing.T) {
+		driver := OpenTestDB(t)
+
+		sut, err := sql_db.NewSqlDB(driver)
+		AssertNoError(t, err)
+		profiles, err := profiles_db.NewSqlDB(driver)
+		AssertNoError(t, err)
+
+		author := RandomProfileModel()
+		profiles.CreateProfile(author)
+
+		post1 := createRandomPost(t, driver, sut, author.Id)
+		post2 := createRandomPost(t, driver, sut, author.Id)
+		post2.Images = RandomPostImageModels()
+		err = sut.AddPostImages(driver, post2.Id, post2.Images)
+		AssertNoError(t, err)
+
+		gotPosts, err := sut.GetPostsByIds([]values.PostId{post2.Id, "9999999", post1.Id})
+		AssertNoError(t, err)
+		Assert(t, gotPosts, []models.PostModel{post2, post1}, "the found posts in the order of ids")
+	})
+	t.Run("getting and updating a post", func(t *testing.T) {
+		driver := OpenTestDB(t)
+
+		sut, err := sql_db.NewSqlDB(driver)
+		AssertNoError(t, err)
+		profiles, err := profiles_db.NewSqlDB(driver)
+		AssertNoError(t, err)
+
+		author := RandomProfileModel()
+		profiles.CreateProfile(author)
+
+		_, err = sut.GetPost("9999999")
+		AssertError(t, err, core_err.ErrNotFound)
+
+		post := createRandomPost(t, driver, sut, author.Id)
+		post.Images = RandomPostImageModels()
+		err = sut.AddPostImages(driver, post.Id, post.Images)
+		AssertNoError(t, err)
+		gotPost, err := sut.GetPost(post.Id)
+		AssertNoError(t, err)
+		Assert(t, gotPost, post, "the created post")
+
+		upd := models.PostToUpdate{
+			Text:     RandomString(),
+			EditedAt: RandomTime(),
+			Images: []models.PostImageModel{
+				{Path: post.Images[2].Path, Index: 1},
+				{Path: RandomString(), Index: 2},
+			},
+		}
+		err = sut.UpdatePost(driver, post.Id, upd)
+		AssertNoError(t, err)
+
+		post.Text = upd.Text
+		post.EditedAt = upd.EditedAt.Unix()
+		post.Images = upd.Images
+		gotPost, err = sut.GetPost(post.Id)
+		AssertNoError(t, err)
+		Assert(t, gotPost, post, "the updated post")
+	})
+	t.Run("disabling and enabling comments of a post", func(t *testing.T) {
+		driver := OpenTestDB(t)
+
+		sut, err := sql_db.NewSqlDB(driver)
+		AssertNoError(t, err)
+		profiles, err := profiles_db.NewSqlDB(driver)
+		AssertNoError(t, err)
+
+		author := RandomProfileModel()
+		profiles.CreateProfile(author)
+		post := createRandomPostWithTime(t, driver, sut, author.Id, time.Unix(2000, 0))
+		other := createRandomPostWithTime(t, driver, sut, author.Id, time.Unix(1000, 0))
+
+		err = sut.SetCommentsDisabled(post.Id, true)
+		AssertNoError(t, err)
+		post.CommentsDisabled = true
+		assertPosts(t, sut, author.Id, []models.PostModel{post, other})
+
+		err = sut.SetCommentsDisabled(post.Id, false)
+		AssertNoError(t, err)
+		post.CommentsDisabled = false
+		gotPost, err := sut.GetPost(post.Id)
+		AssertNoError(t, err)
+		Assert(t, gotPost, post, "the post with enabled comments")
+	})
+	t.Run("tagging posts and getting posts by tag", func(t *testing.T) {
+		driver := OpenTestDB(t)
+
+		sut, err := sql_db.NewSqlDB(driver)
+		AssertNoError(t, err)
+		profiles, err := profiles_db.NewSqlDB(driver)
+		AssertNoError(t, err)
+
+		author := RandomProfileModel()
+		profiles.CreateProfile(author)
+		tag, otherTag := "tag_"+RandomId(), "other_"+RandomId()
+		older := createRandomPostWithTime(t, driver, sut, author.Id, time.Unix(1000, 0))
+		newer := createRandomPostWithTime(t, driver, sut, author.Id, time.Unix(2000, 0))
+		untagged := createRandomPostWithTime(t, driver, sut, author.Id, time.Unix(3000, 0))
+		AssertNoError(t, sut.AddPostTags(driver, older.Id, []values.Tag{tag}, time.Now()))
+		AssertNoError(t, sut.AddPostTags(driver, newer.Id, []values.Tag{tag, otherTag}, time.Now()))
+
+		// the posts with a tag are paginated, newest first
+		gotPosts, err := sut.GetPostsByTag(tag, pagination.Page{Limit: 1})
+		AssertNoError(t, err)
+		Assert(t, gotPosts, []models.PostModel{newer}, "the first page of posts with the tag")
+		nextPage := pagination.Page{After: pagination.Cursor{CreatedAt: newer.CreatedAt, Id: newer.Id}, Limit: 1}
+		gotPosts, err = sut.GetPostsByTag(tag, nextPage)
+		AssertNoError(t, err)
+		Assert(t, gotPosts, []models.PostModel{older}, "the second page of posts with the tag")
+
+		// editing the tags of a post removes the ones it no longer has
+		err = sut.UpdatePostTags(driver, newer.Id, []values.Tag{otherTag}, time.Now())
+		AssertNoError(t, err)
+		err = sut.UpdatePostTags(driver, untagged.Id, []values.Tag{}, time.Now())
+		AssertNoError(t, err)
+		gotPosts, err = sut.GetPostsByTag(tag, pagination.Page{Limit: 10})
+		AssertNoError(t, err)
+		Assert(t, gotPosts, []models.PostModel{older}, "posts with the tag after edit")
+		gotPosts, err = sut.GetPostsByTag(otherTag, pagination.Page{Limit: 10})
+		AssertNoError(t, err)
+		Assert(t, gotPosts, []models.PostModel{newer}, "posts with the other tag after edit")
+
+		// removing all tags of a post
+		err = sut.UpdatePostTags(driver, newer.Id, nil, time.Now())
+		AssertNoError(t, err)
+		gotPosts, err = sut.GetPostsByTag(otherTag, pagination.Page{Limit: 10})
+		AssertNoError(t, err)
+		Assert(t, len(gotPosts), 0, "number of posts with the other tag after its removal")
+	})
+	t.Run("computing trending tags", func(t *testing.T) {
+		driver := OpenTestDB(t)
+
+		sut, err := sql_db.NewSqlDB(driver)
+		AssertNoError(t, err)
+		profiles, err := profiles_db.NewSqlDB(driver)
+		AssertNoError(t, err)
+
+		author := RandomProfileModel()
+		profiles.CreateProfile(author)
+		// the times are far in the future, so that the tags used by other tests are outside of the window
+		windowStart := time.Unix(5000000000, 0)
+		inWindow, beforeWindow := windowStart.Add(time.Hour), windowStart.Add(-time.Hour)
+		tagPost := func(usedAt time.Time, tags ...values.Tag) {
+			post := createRandomPost(t, driver, sut, author.Id)
+			AssertNoError(t, sut.AddPostTags(driver, post.Id, tags, usedAt))
+		}
+		tagPost(inWindow, "trending_a", "trending_b", "trending_c")
+		tagPost(inWindow, "trending_a", "trending_b")
+		tagPost(inWindow, "trending_a")
+		tagPost(beforeWindow, "trending_c", "not_trending")
+		tagPost(beforeWindow, "trending_c")
+
+		err = sut.UpdateTrendingTags(context.Background(), windowStart, 2)
+		AssertNoError(t, err)
+		got, err := sut.GetTrendingTags()
+		AssertNoError(t, err)
+		want := []values.TrendingTag{{Tag: "trending_a", Uses: 3}, {Tag: "trending_b", Uses: 2}}
+		Assert(t, got, want, "trending tags")
+
+		// the previous trending tags are replaced
+		err = sut.UpdateTrendingTags(context.Background(), windowStart.Add(2*time.Hour), 2)
+		AssertNoError(t, err)
+		got, err = sut.GetTrendingTags()
+		AssertNoError(t, err)
+		Assert(t, got, []values.TrendingTag{}, "trending tags when no tags were used")
+	})
 }
@@ -4,8 +4,13 @@ import (
 	"fmt"
 	"github.com/k0marov/go-socnet/core/abstract/deletable"
 	"github.com/k0marov/go-socnet/core/abstract/likeable"
+	"github.com/k0marov/go-socnet/core/abstract/mentionable"
+	mentionable_values "github.com/k0marov/go-socnet/core/abstract/mentionable/values"
 	"github.com/k0marov/go-socnet/core/general/core_err"
 	"github.com/k0marov/go-socnet/core/general/core_values"
+	"github.com/k0marov/go-socnet/core/general/pagination"
+	"github.com/k0marov/go-socnet/core/general/static_store"
+	"github.com/k0marov/go-socnet/core/general/unit_of_work"
 	"time"
 
 	"github.com/k0marov/go-socnet/features/posts/domain/entities"
@@ -16,80 +21,232 @@ import (
 )
 
 type (
-	DBPostsGetter func(core_values.UserId) ([]models.PostModel, error)
+	DBPostGetter         func(values.PostId) (models.PostModel, error)
+	DBPostsGetter        func(core_values.UserId, pagination.Page) ([]models.PostModel, error)
+	DBAuthorsPostsGetter func([]core_values.UserId, pagination.Page) ([]models.PostModel, error)
+	DBPostsByIdsGetter   func([]values.PostId) ([]models.PostModel, error)
+	DBTagPostsGetter     func(values.Tag, pagination.Page) ([]models.PostModel, error)
 
-	DBPostCreator     func(newPost models.PostToCreate) (values.PostId, error)
-	DBPostImagesAdder func(values.PostId, []models.PostImageModel) error
+	DBPostCreator     func(ex unit_of_work.Executor, newPost models.PostToCreate) (values.PostId, error)
+	DBPostImagesAdder func(unit_of_work.Executor, values.PostId, []models.PostImageModel) error
+	DBPostUpdater     func(unit_of_work.Executor, values.PostId, models.PostToUpdate) error
+	DBPostTagsAdder   func(ex unit_of_work.Executor, post values.PostId, tags []values.Tag, usedAt time.Time) error
+	DBPostTagsUpdater func(ex unit_of_work.Executor, post values.PostId, tags []values.Tag, usedAt time.Time) error
 )
 
-// TODO: get rid of complexity by removing the "deleting on failure" logic by using transactions ?
-func NewStorePostCreator(
-	createPost DBPostCreator, storeImages file_storage.PostImageFilesCreator, addImages DBPostImagesAdder,
-	deletePost deletable.ForceDeleter, deleteImages file_storage.PostFilesDeleter) store.PostCreator {
-	return func(post values.NewPostData, createdAt time.Time) error {
-		postToCreate := models.PostToCreate{
-			Author:    post.Author,
-			Text:      post.Text,
-			CreatedAt: createdAt,
-		}
-		postId, err := createPost(postToCreate)
+// NewStorePostCreator creates the post together with its images in a single unit of work,
+// so a failure at any step leaves neither the post nor its image files behind
+func NewStorePostCreator(runInUnit unit_of_work.Runner, createPost DBPostCreator, storeImages file_storage.PostImageFilesCreator, addImages DBPostImagesAdder, addMentions mentionable.MentionsAdder, addTags DBPostTagsAdder) store.PostCreator {
+	return func(post values.NewPostData, mentions []mentionable_values.Mention, tags []values.Tag, createdAt time.Time) (values.PostId, error) {
+		var id values.PostId
+		err := runInUnit(func(uow unit_of_work.UnitOfWork) error {
+			postToCreate := models.PostToCreate{
+				Author:    post.Author,
+				Text:      post.Text,
+				CreatedAt: createdAt,
+			}
+			postId, err := createPost(uow.Tx, postToCreate)
+			if err != nil {
+				return core_err.Rethrow("creating a post in db", err)
+			}
+			imagePaths, err := storeImages(uow.Files.CreateFile, postId, post.Author, post.Images)
+			if err != nil {
+				return core_err.Rethrow("storing image files", err)
+			}
+			var postImages []models.PostImageModel
+			for i, path := range imagePaths {
+				postImages = append(postImages, models.PostImageModel{
+					Path:  path,
+					Index: post.Images[i].Index,
+				})
+			}
+			err = addImages(uow.Tx, postId, postImages)
+			if err != nil {
+				return core_err.Rethrow("adding image paths to db", err)
+			}
+			err = addMentions(uow.Tx, postId, mentions)
+			if err != nil {
+				return core_err.Rethrow("adding mentions to db", err)
+			}
+			err = addTags(uow.Tx, postId, tags, createdAt)
+			if err != nil {
+				return core_err.Rethrow("adding tags to db", err)
+			}
+			id = postId
+			return nil
+		})
 		if err != nil {
-			return core_err.Rethrow("creating a post in db", err)
+			return "", err
 		}
-		imagePaths, err := storeImages(postId, post.Author, post.Images)
-		if err != nil || len(imagePaths) != len(post.Images) {
-			deletePost(postId)
-			return core_err.Rethrow("storing image files", err)
+		return id, nil
+	}
+}
+
+// NewStorePostDeleter deletes the post in a unit of work and its files only after it is committed,
+// so a failure leaves the post with all of its images. Files which fail to be deleted are just left behind.
+func NewStorePostDeleter(runInUnit unit_of_work.Runner, deletePost deletable.ForceDeleter, deleteFiles file_storage.PostFilesDeleter) store.PostDeleter {
+	return func(post values.PostId, author core_values.UserId) error {
+		err := runInUnit(func(uow unit_of_work.UnitOfWork) error {
+			err := deletePost(uow.Tx, post)
+			if err != nil {
+				return core_err.Rethrow("deleting post from db", err)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
 		}
-		var postImages []models.PostImageModel
-		for i, path := range imagePaths {
-			postImages = append(postImages, models.PostImageModel{
-				Path:  path,
-				Index: post.Images[i].Index,
-			})
+		deleteFiles(post, author)
+		return nil
+	}
+}
+
+// NewStorePostUpdater updates the post together with its new images, mentions and tags in a single unit of work.
+// The files of the removed images are deleted only after it is committed, so a failure leaves the post as it was.
+func NewStorePostUpdater(runInUnit unit_of_work.Runner, storeImages file_storage.EditedPostImageFilesCreator, updatePost DBPostUpdater, replaceMentions mentionable.MentionsReplacer, updateTags DBPostTagsUpdater, deleteFile static_store.StaticFileDeleter) store.PostUpdater {
+	return func(oldPost entities.Post, upd values.PostUpdateData, mentions []mentionable_values.Mention, tags []values.Tag, editedAt time.Time) error {
+		post, author := oldPost.Id, oldPost.AuthorId
+		oldPaths := map[int]core_values.StaticPath{}
+		for _, image := range oldPost.PostModel.Images {
+			oldPaths[image.Index] = image.Path
 		}
-		err = addImages(postId, postImages)
+
+		err := runInUnit(func(uow unit_of_work.UnitOfWork) error {
+			newImages := upd.NewImages()
+			newPaths, err := storeImages(uow.Files.CreateFile, post, author, newImages, editedAt)
+			if err != nil {
+				return core_err.Rethrow("storing new image files", err)
+			}
+
+			var images []models.PostImageModel
+			newPathsUsed := 0
+			for _, image := range upd.Images {
+				var path core_values.StaticPath
+				if image.IsNew() {
+					path = newPaths[newPathsUsed]
+					newPathsUsed++
+				} else {
+					path = oldPaths[image.OldIndex]
+					delete(oldPaths, image.OldIndex)
+				}
+				images = append(images, models.PostImageModel{Path: path, Index: image.Index})
+			}
+
+			postToUpdate := models.PostToUpdate{
+				Text:     upd.Text,
+				EditedAt: editedAt,
+				Images:   images,
+			}
+			err = updatePost(uow.Tx, post, postToUpdate)
+			if err != nil {
+				return core_err.Rethrow("updating a post in db", err)
+			}
+			err = replaceMentions(uow.Tx, post, mentions)
+			if err != nil {
+				return core_err.Rethrow("replacing the mentions of an updated post", err)
+			}
+			err = updateTags(uow.Tx, post, tags, editedAt)
+			if err != nil {
+				return core_err.Rethrow("updating the tags of an updated post", err)
+			}
+			return nil
+		})
 		if err != nil {
-			deletePost(postId)
-			deleteImages(postId, post.Author)
-			return core_err.Rethrow("adding image paths to db", err)
+			return err
+		}
+
+		// the images that are left in oldPaths were removed from the post
+		for _, path := range oldPaths {
+			deleteFile(path)
 		}
 		return nil
 	}
 }
 
-func NewStorePostDeleter(deletePost deletable.ForceDeleter, deleteFiles file_storage.PostFilesDeleter) store.PostDeleter {
-	return func(post values.PostId, author core_values.UserId) error {
-		err := deletePost(post)
+func NewStorePostGetter(getter DBPostGetter, getReactions likeable.ReactionCountsBatchGetter, getCommentCounts store.CommentCountsGetter, getMentions mentionable.MentionsBatchGetter, toURL static_store.PathToURLConverter) store.PostGetter {
+	return func(post values.PostId) (entities.Post, error) {
+		model, err := getter(post)
 		if err != nil {
-			return fmt.Errorf("error while deleting post from db: %w", err)
+			return entities.Post{}, core_err.Rethrow("getting a post from db", err)
 		}
-		err = deleteFiles(post, author)
+		posts, err := modelsToPosts([]models.PostModel{model}, getReactions, getCommentCounts, getMentions, toURL)
 		if err != nil {
-			return fmt.Errorf("error while deleting post files: %w", err)
+			return entities.Post{}, err
 		}
-		return nil
+		return posts[0], nil
 	}
 }
 
-func NewStorePostsGetter(getter DBPostsGetter, likesGetter likeable.LikesCountGetter) store.PostsGetter {
-	return func(authorId core_values.UserId) (posts []entities.Post, err error) {
-		models, err := getter(authorId)
+func NewStorePostsGetter(getter DBPostsGetter, getReactions likeable.ReactionCountsBatchGetter, getCommentCounts store.CommentCountsGetter, getMentions mentionable.MentionsBatchGetter, toURL static_store.PathToURLConverter) store.PostsGetter {
+	return func(authorId core_values.UserId, page pagination.Page) (posts []entities.Post, err error) {
+		models, err := getter(authorId, page)
 		if err != nil {
 			return []entities.Post{}, core_err.Rethrow("getting posts from db", err)
 		}
-		for _, model := range models {
-			likes, err := likesGetter(model.Id)
-			if err != nil {
-				return []entities.Post{}, fmt.Errorf("error while getting likes count of a post: %w", err)
-			}
-			post := entities.Post{
-				PostModel: model,
-				Images:    entities.ImagePathsToUrls(model.Images),
-				Likes:     likes,
-			}
-			posts = append(posts, post)
+		return modelsToPosts(models, getReactions, getCommentCounts, getMentions, toURL)
+	}
+}
+
+func NewStoreAuthorsPostsGetter(getter DBAuthorsPostsGetter, getReactions likeable.ReactionCountsBatchGetter, getCommentCounts store.CommentCountsGetter, getMentions mentionable.MentionsBatchGetter, toURL static_store.PathToURLConverter) store.AuthorsPostsGetter {
+	return func(authors []core_values.UserId, page pagination.Page) ([]entities.Post, error) {
+		models, err := getter(authors, page)
+		if err != nil {
+			return []entities.Post{}, core_err.Rethrow("getting posts of authors from db", err)
 		}
+		return modelsToPosts(models, getReactions, getCommentCounts, getMentions, toURL)
+	}
+}
+
+func NewStorePostsByIdsGetter(getter DBPostsByIdsGetter, getReactions likeable.ReactionCountsBatchGetter, getCommentCounts store.CommentCountsGetter, getMentions mentionable.MentionsBatchGetter, toURL static_store.PathToURLConverter) store.PostsByIdsGetter {
+	return func(ids []values.PostId) ([]entities.Post, error) {
+		models, err := getter(ids)
+		if err != nil {
+			return []entities.Post{}, core_err.Rethrow("getting posts by ids from db", err)
+		}
+		return modelsToPosts(models, getReactions, getCommentCounts, getMentions, toURL)
+	}
+}
+
+func NewStoreTagPostsGetter(getter DBTagPostsGetter, getReactions likeable.ReactionCountsBatchGetter, getCommentCounts store.CommentCountsGetter, getMentions mentionable.MentionsBatchGetter, toURL static_store.PathToURLConverter) store.TagPostsGetter {
+	return func(tag values.Tag, page pagination.Page) ([]entities.Post, error) {
+		models, err := getter(tag, page)
+		if err != nil {
+			return []entities.Post{}, core_err.Rethrow("getting posts with a tag from db", err)
+		}
+		return modelsToPosts(models, getReactions, getCommentCounts, getMentions, toURL)
+	}
+}
+
+func modelsToPosts(models []models.PostModel, getReactions likeable.ReactionCountsBatchGetter, getCommentCounts store.CommentCountsGetter, getMentions mentionable.MentionsBatchGetter, toURL static_store.PathToURLConverter) (posts []entities.Post, err error) {
+	if len(models) == 0 {
 		return
 	}
+	ids := make([]values.PostId, len(models))
+	for i, model := range models {
+		ids[i] = model.Id
+	}
+	reactions, err := getReactions(ids)
+	if err != nil {
+		return []entities.Post{}, fmt.Errorf("error while getting reaction counts of posts: %w", err)
+	}
+	commentCounts, err := getCommentCounts(ids)
+	if err != nil {
+		return []entities.Post{}, fmt.Errorf("error while getting comment counts of posts: %w", err)
+	}
+	mentions, err := getMentions(ids)
+	if err != nil {
+		return []entities.Post{}, fmt.Errorf("error while getting mentions of posts: %w", err)
+	}
+	for _, model := range models {
+		post := entities.Post{
+			PostModel:     model,
+			Images:        entities.ImagePathsToUrls(model.Images, toURL),
+			Likes:         reactions[model.Id].Total(),
+			Reactions:     reactions[model.Id],
+			CommentsCount: commentCounts[model.Id],
+			Mentions:      mentions[model.Id],
+		}
+		posts = append(posts, post)
+	}
+	return
 }
@@ -1,7 +1,12 @@
 package store_test
 
 import (
+	likeable_values "github.com/k0marov/go-socnet/core/abstract/likeable/values"
+	mentionable_values "github.com/k0marov/go-socnet/core/abstract/mentionable/values"
 	"github.com/k0marov/go-socnet/core/general/core_values"
+	"github.com/k0marov/go-socnet/core/general/pagination"
+	"github.com/k0marov/go-socnet/core/general/static_store"
+	"github.com/k0marov/go-socnet/core/general/unit_of_work"
 	. "github.com/k0marov/go-socnet/core/helpers/test_helpers"
 	"reflect"
 	"testing"
@@ -15,6 +20,8 @@ import (
 
 func TestStorePostCreator(t *testing.T) {
 	tNewPost := RandomNewPostData()
+	mentions := RandomMentions()
+	tags := []values.Tag{RandomString(), RandomString()}
 	postId := RandomString()
 	createdAt := time.Now()
 	var imagePaths []core_values.StaticPath
@@ -25,155 +32,578 @@ func TestStorePostCreator(t *testing.T) {
 		wantPostImages = append(wantPostImages, models.PostImageModel{Path: path, Index: img.Index})
 	}
 
-	createPost := func(newPost models.PostToCreate) (values.PostId, error) {
+	runInUnit := func(work unit_of_work.Work) error {
+		return work(unit_of_work.UnitOfWork{})
+	}
+	t.Run("error case - running the unit of work returns an error", func(t *testing.T) {
+		tErr := RandomError()
+		runInUnit := func(unit_of_work.Work) error {
+			return tErr
+		}
+		_, err := store.NewStorePostCreator(runInUnit, nil, nil, nil, nil, nil)(tNewPost, mentions, tags, createdAt)
+		AssertError(t, err, tErr)
+	})
+	createPost := func(ex unit_of_work.Executor, newPost models.PostToCreate) (values.PostId, error) {
 		if newPost.Author == tNewPost.Author && newPost.Text == tNewPost.Text && TimeAlmostEqual(newPost.CreatedAt, createdAt) {
 			return postId, nil
 		}
 		panic("unexpected args")
 	}
 	t.Run("error case - createPost returns an error", func(t *testing.T) {
-		createPost := func(models.PostToCreate) (values.PostId, error) {
+		createPost := func(unit_of_work.Executor, models.PostToCreate) (values.PostId, error) {
 			return "", RandomError()
 		}
-		sut := store.NewStorePostCreator(createPost, nil, nil, nil, nil)
-		err := sut(tNewPost, createdAt)
+		sut := store.NewStorePostCreator(runInUnit, createPost, nil, nil, nil, nil)
+		_, err := sut(tNewPost, mentions, tags, createdAt)
 		AssertSomeError(t, err)
 	})
-	storeImages := func(post values.PostId, author core_values.UserId, images []values.PostImageFile) ([]core_values.StaticPath, error) {
+	storeImages := func(createFile static_store.StaticFileCreator, post values.PostId, author core_values.UserId, images []values.PostImageFile) ([]core_values.StaticPath, error) {
 		if post == postId && author == tNewPost.Author && reflect.DeepEqual(images, tNewPost.Images) {
 			return imagePaths, nil
 		}
 		panic("unexpected args")
 	}
 	t.Run("error case - storeImages returns an error", func(t *testing.T) {
-		storeImages := func(values.PostId, core_values.UserId, []values.PostImageFile) ([]core_values.StaticPath, error) {
+		storeImages := func(static_store.StaticFileCreator, values.PostId, core_values.UserId, []values.PostImageFile) ([]core_values.StaticPath, error) {
 			return []core_values.StaticPath{}, RandomError()
 		}
-		postDeleted := false
-		deletePost := func(post values.PostId) error {
-			if post == postId {
-				postDeleted = true
-				return nil
-			}
-			panic("unexpected args")
-		}
-		sut := store.NewStorePostCreator(createPost, storeImages, nil, deletePost, nil)
-		err := sut(tNewPost, createdAt)
+		sut := store.NewStorePostCreator(runInUnit, createPost, storeImages, nil, nil, nil)
+		_, err := sut(tNewPost, mentions, tags, createdAt)
 		AssertSomeError(t, err)
-		Assert(t, postDeleted, true, "post was deleted")
 	})
-	addImages := func(post values.PostId, images []models.PostImageModel) error {
+	addImages := func(ex unit_of_work.Executor, post values.PostId, images []models.PostImageModel) error {
 		if post == postId && reflect.DeepEqual(images, wantPostImages) {
 			return nil
 		}
-		panic("unimplemented")
+		panic("unexpected args")
 	}
 	t.Run("error case - addImages returns an error", func(t *testing.T) {
-		addImages := func(values.PostId, []models.PostImageModel) error {
+		addImages := func(unit_of_work.Executor, values.PostId, []models.PostImageModel) error {
 			return RandomError()
 		}
-		postDeleted := false
-		imagesDeleted := false
-		deletePost := func(post values.PostId) error {
-			if post == postId {
-				postDeleted = true
-				return nil
-			}
-			panic("unexpected args")
+		sut := store.NewStorePostCreator(runInUnit, createPost, storeImages, addImages, nil, nil)
+		_, err := sut(tNewPost, mentions, tags, createdAt)
+		AssertSomeError(t, err)
+	})
+	addMentions := func(ex unit_of_work.Executor, target string, gotMentions []mentionable_values.Mention) error {
+		if target == postId && reflect.DeepEqual(gotMentions, mentions) {
+			return nil
 		}
-		deleteImages := func(post values.PostId, author core_values.UserId) error {
-			if post == postId && author == tNewPost.Author {
-				imagesDeleted = true
-				return nil
-			}
-			panic("unexpected args")
+		panic("unexpected args")
+	}
+	t.Run("error case - addMentions returns an error", func(t *testing.T) {
+		addMentions := func(unit_of_work.Executor, string, []mentionable_values.Mention) error {
+			return RandomError()
+		}
+		sut := store.NewStorePostCreator(runInUnit, createPost, storeImages, addImages, addMentions, nil)
+		_, err := sut(tNewPost, mentions, tags, createdAt)
+		AssertSomeError(t, err)
+	})
+	addTags := func(ex unit_of_work.Executor, post values.PostId, gotTags []values.Tag, usedAt time.Time) error {
+		if post == postId && reflect.DeepEqual(gotTags, tags) && usedAt == createdAt {
+			return nil
 		}
-		sut := store.NewStorePostCreator(createPost, storeImages, addImages, deletePost, deleteImages)
-		err := sut(tNewPost, createdAt)
+		panic("unexpected args")
+	}
+	t.Run("error case - addTags returns an error", func(t *testing.T) {
+		addTags := func(unit_of_work.Executor, values.PostId, []values.Tag, time.Time) error {
+			return RandomError()
+		}
+		sut := store.NewStorePostCreator(runInUnit, createPost, storeImages, addImages, addMentions, addTags)
+		_, err := sut(tNewPost, mentions, tags, createdAt)
 		AssertSomeError(t, err)
-		Assert(t, postDeleted, true, "post was deleted")
-		Assert(t, imagesDeleted, true, "images were deleted")
 	})
 	t.Run("happy case", func(t *testing.T) {
-		sut := store.NewStorePostCreator(createPost, storeImages, addImages, nil, nil)
-		err := sut(tNewPost, createdAt)
+		sut := store.NewStorePostCreator(runInUnit, createPost, storeImages, addImages, addMentions, addTags)
+		id, err := sut(tNewPost, mentions, tags, createdAt)
 		AssertNoError(t, err)
+		Assert(t, id, postId, "returned post id")
 	})
 }
 
 func TestStorePostDeleter(t *testing.T) {
 	post := RandomString()
 	author := RandomString()
-	deletePost := func(postId values.PostId) error {
+	runInUnit := func(work unit_of_work.Work) error {
+		return work(unit_of_work.UnitOfWork{})
+	}
+	// no files are deleted if deleting the post fails
+	deleteFiles := func(values.PostId, core_values.UserId) error {
+		panic("no files should be deleted")
+	}
+	t.Run("error case - running the unit of work returns an error", func(t *testing.T) {
+		tErr := RandomError()
+		runInUnit := func(unit_of_work.Work) error {
+			return tErr
+		}
+		err := store.NewStorePostDeleter(runInUnit, nil, deleteFiles)(post, author)
+		AssertError(t, err, tErr)
+	})
+	deletePost := func(ex unit_of_work.Executor, postId values.PostId) error {
 		if postId == post {
 			return nil
 		}
 		panic("unexpected args")
 	}
 	t.Run("error case - delete post returns an error", func(t *testing.T) {
-		deletePost := func(values.PostId) error {
+		deletePost := func(unit_of_work.Executor, values.PostId) error {
 			return RandomError()
 		}
-		sut := store.NewStorePostDeleter(deletePost, nil)
-		err := sut(post, author)
+		err := store.NewStorePostDeleter(runInUnit, deletePost, deleteFiles)(post, author)
 		AssertSomeError(t, err)
 	})
-	deleteFiles := func(postId values.PostId, userId core_values.UserId) error {
-		if postId == post && userId == author {
-			return nil
+	t.Run("happy case", func(t *testing.T) {
+		filesDeleted := false
+		deleteFiles := func(postId values.PostId, userId core_values.UserId) error {
+			if postId == post && userId == author {
+				filesDeleted = true
+				return nil
+			}
+			panic("unexpected args")
 		}
-		panic("unexpected args")
-	}
-	t.Run("error case - delete files returns an error", func(t *testing.T) {
+		err := store.NewStorePostDeleter(runInUnit, deletePost, deleteFiles)(post, author)
+		AssertNoError(t, err)
+		Assert(t, filesDeleted, true, "post files were deleted")
+	})
+	t.Run("happy case - deleting files fails after the post is deleted", func(t *testing.T) {
 		deleteFiles := func(values.PostId, core_values.UserId) error {
 			return RandomError()
 		}
-		sut := store.NewStorePostDeleter(deletePost, deleteFiles)
-		err := sut(post, author)
+		err := store.NewStorePostDeleter(runInUnit, deletePost, deleteFiles)(post, author)
+		AssertNoError(t, err)
+	})
+}
+
+func TestStorePostsGetter(t *testing.T) {
+	toURL := static_store.NewPathToURLConverter(RandomString())
+	author := RandomId()
+	postModels := []models.PostModel{RandomPostModel()}
+	reactions := RandomReactionCounts()
+	commentsCount := RandomInt()
+	mentions := RandomMentions()
+	page := pagination.Page{Limit: RandomInt()}
+	dbGetter := func(authorId core_values.UserId, gotPage pagination.Page) ([]models.PostModel, error) {
+		if authorId == author && gotPage == page {
+			return postModels, nil
+		}
+		panic("unexpected args")
+	}
+	t.Run("error case - getting posts from db throws", func(t *testing.T) {
+		dbGetter := func(core_values.UserId, pagination.Page) ([]models.PostModel, error) {
+			return nil, RandomError()
+		}
+		_, err := store.NewStorePostsGetter(dbGetter, nil, nil, nil, toURL)(author, page)
+		AssertSomeError(t, err)
+	})
+	reactionsGetter := func(targetIds []string) (map[string]likeable_values.ReactionCounts, error) {
+		if reflect.DeepEqual(targetIds, []string{postModels[0].Id}) {
+			return map[string]likeable_values.ReactionCounts{postModels[0].Id: reactions}, nil
+		}
+		panic("unexpected args")
+	}
+	t.Run("error case - getting reaction counts throws", func(t *testing.T) {
+		reactionsGetter := func([]string) (map[string]likeable_values.ReactionCounts, error) {
+			return nil, RandomError()
+		}
+		_, err := store.NewStorePostsGetter(dbGetter, reactionsGetter, nil, nil, toURL)(author, page)
 		AssertSomeError(t, err)
 	})
+	commentCountsGetter := func(postIds []values.PostId) (map[values.PostId]int, error) {
+		if reflect.DeepEqual(postIds, []values.PostId{postModels[0].Id}) {
+			return map[values.PostId]int{postModels[0].Id: commentsCount}, nil
+		}
+		panic("unexpected args")
+	}
+	t.Run("error case - getting comment counts throws", func(t *testing.T) {
+		commentCountsGetter := func([]values.PostId) (map[values.PostId]int, error) {
+			return nil, RandomError()
+		}
+		_, err := store.NewStorePostsGetter(dbGetter, reactionsGetter, commentCountsGetter, nil, toURL)(author, page)
+		AssertSomeError(t, err)
+	})
+	mentionsGetter := func(targetIds []string) (map[string][]mentionable_values.Mention, error) {
+		if reflect.DeepEqual(targetIds, []string{postModels[0].Id}) {
+			return map[string][]mentionable_values.Mention{postModels[0].Id: mentions}, nil
+		}
+		panic("unexpected args")
+	}
+	t.Run("error case - getting mentions throws", func(t *testing.T) {
+		mentionsGetter := func([]string) (map[string][]mentionable_values.Mention, error) {
+			return nil, RandomError()
+		}
+		_, err := store.NewStorePostsGetter(dbGetter, reactionsGetter, commentCountsGetter, mentionsGetter, toURL)(author, page)
+		AssertSomeError(t, err)
+	})
+	gotPosts, err := store.NewStorePostsGetter(dbGetter, reactionsGetter, commentCountsGetter, mentionsGetter, toURL)(author, page)
+	AssertNoError(t, err)
+	wantPosts := []entities.Post{{
+		PostModel:     postModels[0],
+		Images:        entities.ImagePathsToUrls(postModels[0].Images, toURL),
+		Likes:         reactions.Total(),
+		Reactions:     reactions,
+		CommentsCount: commentsCount,
+		Mentions:      mentions,
+	}}
+	Assert(t, gotPosts, wantPosts, "returned posts")
+}
 
-	sut := store.NewStorePostDeleter(deletePost, deleteFiles)
-	err := sut(post, author)
+func TestStoreAuthorsPostsGetter(t *testing.T) {
+	toURL := static_store.NewPathToURLConverter(RandomString())
+	authors := []core_values.UserId{RandomId(), RandomId()}
+	page := pagination.Page{After: pagination.Cursor{CreatedAt: RandomTime().Unix(), Id: RandomId()}, Limit: RandomInt()}
+	postModels := []models.PostModel{RandomPostModel()}
+	reactions := RandomReactionCounts()
+	commentsCount := RandomInt()
+	mentions := RandomMentions()
+	dbGetter := func(authorIds []core_values.UserId, gotPage pagination.Page) ([]models.PostModel, error) {
+		if reflect.DeepEqual(authorIds, authors) && gotPage == page {
+			return postModels, nil
+		}
+		panic("unexpected args")
+	}
+	t.Run("error case - getting posts from db throws", func(t *testing.T) {
+		dbGetter := func([]core_values.UserId, pagination.Page) ([]models.PostModel, error) {
+			return nil, RandomError()
+		}
+		_, err := store.NewStoreAuthorsPostsGetter(dbGetter, nil, nil, nil, toURL)(authors, page)
+		AssertSomeError(t, err)
+	})
+	reactionsGetter := func(targetIds []string) (map[string]likeable_values.ReactionCounts, error) {
+		if reflect.DeepEqual(targetIds, []string{postModels[0].Id}) {
+			return map[string]likeable_values.ReactionCounts{postModels[0].Id: reactions}, nil
+		}
+		panic("unexpected args")
+	}
+	t.Run("error case - getting reaction counts throws", func(t *testing.T) {
+		reactionsGetter := func([]string) (map[string]likeable_values.ReactionCounts, error) {
+			return nil, RandomError()
+		}
+		_, err := store.NewStoreAuthorsPostsGetter(dbGetter, reactionsGetter, nil, nil, toURL)(authors, page)
+		AssertSomeError(t, err)
+	})
+	commentCountsGetter := func(postIds []values.PostId) (map[values.PostId]int, error) {
+		if reflect.DeepEqual(postIds, []values.PostId{postModels[0].Id}) {
+			return map[values.PostId]int{postModels[0].Id: commentsCount}, nil
+		}
+		panic("unexpected args")
+	}
+	t.Run("error case - getting comment counts throws", func(t *testing.T) {
+		commentCountsGetter := func([]values.PostId) (map[values.PostId]int, error) {
+			return nil, RandomError()
+		}
+		_, err := store.NewStoreAuthorsPostsGetter(dbGetter, reactionsGetter, commentCountsGetter, nil, toURL)(authors, page)
+		AssertSomeError(t, err)
+	})
+	mentionsGetter := func(targetIds []string) (map[string][]mentionable_values.Mention, error) {
+		if reflect.DeepEqual(targetIds, []string{postModels[0].Id}) {
+			return map[string][]mentionable_values.Mention{postModels[0].Id: mentions}, nil
+		}
+		panic("unexpected args")
+	}
+	t.Run("error case - getting mentions throws", func(t *testing.T) {
+		mentionsGetter := func([]string) (map[string][]mentionable_values.Mention, error) {
+			return nil, RandomError()
+		}
+		_, err := store.NewStoreAuthorsPostsGetter(dbGetter, reactionsGetter, commentCountsGetter, mentionsGetter, toURL)(authors, page)
+		AssertSomeError(t, err)
+	})
+	gotPosts, err := store.NewStoreAuthorsPostsGetter(dbGetter, reactionsGetter, commentCountsGetter, mentionsGetter, toURL)(authors, page)
 	AssertNoError(t, err)
+	wantPosts := []entities.Post{{
+		PostModel:     postModels[0],
+		Images:        entities.ImagePathsToUrls(postModels[0].Images, toURL),
+		Likes:         reactions.Total(),
+		Reactions:     reactions,
+		CommentsCount: commentsCount,
+		Mentions:      mentions,
+	}}
+	Assert(t, gotPosts, wantPosts, "returned posts")
 }
 
-func TestStorePostsGetter(t *testing.T) {
-	author := RandomId()
+func TestStorePostsByIdsGetter(t *testing.T) {
+	toURL := static_store.NewPathToURLConverter(RandomString())
+	ids := []values.PostId{RandomId(), RandomId()}
 	postModels := []models.PostModel{RandomPostModel()}
-	likes := RandomInt()
-	dbGetter := func(authorId core_values.UserId) ([]models.PostModel, error) {
-		if authorId == author {
+	reactions := RandomReactionCounts()
+	commentsCount := RandomInt()
+	mentions := RandomMentions()
+	dbGetter := func(postIds []values.PostId) ([]models.PostModel, error) {
+		if reflect.DeepEqual(postIds, ids) {
 			return postModels, nil
 		}
 		panic("unexpected args")
 	}
 	t.Run("error case - getting posts from db throws", func(t *testing.T) {
-		dbGetter := func(core_values.UserId) ([]models.PostModel, error) {
+		dbGetter := func([]values.PostId) ([]models.PostModel, error) {
+			return nil, RandomError()
+		}
+		_, err := store.NewStorePostsByIdsGetter(dbGetter, nil, nil, nil, toURL)(ids)
+		AssertSomeError(t, err)
+	})
+	reactionsGetter := func(targetIds []string) (map[string]likeable_values.ReactionCounts, error) {
+		if reflect.DeepEqual(targetIds, []string{postModels[0].Id}) {
+			return map[string]likeable_values.ReactionCounts{postModels[0].Id: reactions}, nil
+		}
+		panic("unexpected args")
+	}
+	t.Run("error case - getting reaction counts throws", func(t *testing.T) {
+		reactionsGetter := func([]string) (map[string]likeable_values.ReactionCounts, error) {
+			return nil, RandomError()
+		}
+		_, err := store.NewStorePostsByIdsGetter(dbGetter, reactionsGetter, nil, nil, toURL)(ids)
+		AssertSomeError(t, err)
+	})
+	commentCountsGetter := func(postIds []values.PostId) (map[values.PostId]int, error) {
+		if reflect.DeepEqual(postIds, []values.PostId{postModels[0].Id}) {
+			return map[values.PostId]int{postModels[0].Id: commentsCount}, nil
+		}
+		panic("unexpected args")
+	}
+	t.Run("error case - getting comment counts throws", func(t *testing.T) {
+		commentCountsGetter := func([]values.PostId) (map[values.PostId]int, error) {
+			return nil, RandomError()
+		}
+		_, err := store.NewStorePostsByIdsGetter(dbGetter, reactionsGetter, commentCountsGetter, nil, toURL)(ids)
+		AssertSomeError(t, err)
+	})
+	mentionsGetter := func(targetIds []string) (map[string][]mentionable_values.Mention, error) {
+		if reflect.DeepEqual(targetIds, []string{postModels[0].Id}) {
+			return map[string][]mentionable_values.Mention{postModels[0].Id: mentions}, nil
+		}
+		panic("unexpected args")
+	}
+	t.Run("error case - getting mentions throws", func(t *testing.T) {
+		mentionsGetter := func([]string) (map[string][]mentionable_values.Mention, error) {
+			return nil, RandomError()
+		}
+		_, err := store.NewStorePostsByIdsGetter(dbGetter, reactionsGetter, commentCountsGetter, mentionsGetter, toURL)(ids)
+		AssertSomeError(t, err)
+	})
+	gotPosts, err := store.NewStorePostsByIdsGetter(dbGetter, reactionsGetter, commentCountsGetter, mentionsGetter, toURL)(ids)
+	AssertNoError(t, err)
+	wantPosts := []entities.Post{{
+		PostModel:     postModels[0],
+		Images:        entities.ImagePathsToUrls(postModels[0].Images, toURL),
+		Likes:         reactions.Total(),
+		Reactions:     reactions,
+		CommentsCount: commentsCount,
+		Mentions:      mentions,
+	}}
+	Assert(t, gotPosts, wantPosts, "returned posts")
+}
+
+func TestStorePostUpdater(t *testing.T) {
+	editedAt := RandomTime()
+	mentions := RandomMentions()
+	tags := []values.Tag{RandomString()}
+	oldModel := RandomPostModel() // has images with indices 1, 2 and 3
+	oldPost := entities.Post{PostModel: oldModel}
+	post := oldModel.Id
+	author := oldModel.AuthorId
+	newImage := RandomFileData()
+	upd := values.PostUpdateData{
+		Text: RandomString(),
+		Images: []values.PostImageUpdate{
+			{Index: 1, File: newImage},
+			{Index: 2, OldIndex: 3},
+			{Index: 3, OldIndex: 1},
+		},
+	}
+	newPath := RandomString()
+	wantImages := []models.PostImageModel{
+		{Path: newPath, Index: 1},
+		{Path: oldModel.Images[2].Path, Index: 2},
+		{Path: oldModel.Images[0].Path, Index: 3},
+	}
+
+	runInUnit := func(work unit_of_work.Work) error {
+		return work(unit_of_work.UnitOfWork{})
+	}
+	// no files are deleted if the update fails
+	deleteFile := func(core_values.StaticPath) error {
+		panic("no files should be deleted")
+	}
+	t.Run("error case - running the unit of work returns an error", func(t *testing.T) {
+		tErr := RandomError()
+		runInUnit := func(unit_of_work.Work) error {
+			return tErr
+		}
+		err := store.NewStorePostUpdater(runInUnit, nil, nil, nil, nil, deleteFile)(oldPost, upd, mentions, tags, editedAt)
+		AssertError(t, err, tErr)
+	})
+	storeImages := func(createFile static_store.StaticFileCreator, postId values.PostId, authorId core_values.UserId, images []values.PostImageFile, gotEditedAt time.Time) ([]core_values.StaticPath, error) {
+		wantNewImages := []values.PostImageFile{{File: newImage, Index: 1}}
+		if postId == post && authorId == author && reflect.DeepEqual(images, wantNewImages) && gotEditedAt == editedAt {
+			return []core_values.StaticPath{newPath}, nil
+		}
+		panic("unexpected args")
+	}
+	t.Run("error case - storing new images throws", func(t *testing.T) {
+		storeImages := func(static_store.StaticFileCreator, values.PostId, core_values.UserId, []values.PostImageFile, time.Time) ([]core_values.StaticPath, error) {
+			return []core_values.StaticPath{}, RandomError()
+		}
+		err := store.NewStorePostUpdater(runInUnit, storeImages, nil, nil, nil, deleteFile)(oldPost, upd, mentions, tags, editedAt)
+		AssertSomeError(t, err)
+	})
+	updatePost := func(ex unit_of_work.Executor, postId values.PostId, postToUpdate models.PostToUpdate) error {
+		wantToUpdate := models.PostToUpdate{Text: upd.Text, EditedAt: editedAt, Images: wantImages}
+		if postId == post && reflect.DeepEqual(postToUpdate, wantToUpdate) {
+			return nil
+		}
+		panic("unexpected args")
+	}
+	t.Run("error case - updating the post in db throws", func(t *testing.T) {
+		updatePost := func(unit_of_work.Executor, values.PostId, models.PostToUpdate) error {
+			return RandomError()
+		}
+		err := store.NewStorePostUpdater(runInUnit, storeImages, updatePost, nil, nil, deleteFile)(oldPost, upd, mentions, tags, editedAt)
+		AssertSomeError(t, err)
+	})
+	replaceMentions := func(ex unit_of_work.Executor, target string, gotMentions []mentionable_values.Mention) error {
+		if target == post && reflect.DeepEqual(gotMentions, mentions) {
+			return nil
+		}
+		panic("unexpected args")
+	}
+	t.Run("error case - replacing mentions throws", func(t *testing.T) {
+		replaceMentions := func(unit_of_work.Executor, string, []mentionable_values.Mention) error {
+			return RandomError()
+		}
+		err := store.NewStorePostUpdater(runInUnit, storeImages, updatePost, replaceMentions, nil, deleteFile)(oldPost, upd, mentions, tags, editedAt)
+		AssertSomeError(t, err)
+	})
+	updateTags := func(ex unit_of_work.Executor, postId values.PostId, gotTags []values.Tag, usedAt time.Time) error {
+		if postId == post && reflect.DeepEqual(gotTags, tags) && usedAt == editedAt {
+			return nil
+		}
+		panic("unexpected args")
+	}
+	t.Run("error case - updating tags throws", func(t *testing.T) {
+		updateTags := func(unit_of_work.Executor, values.PostId, []values.Tag, time.Time) error {
+			return RandomError()
+		}
+		err := store.NewStorePostUpdater(runInUnit, storeImages, updatePost, replaceMentions, updateTags, deleteFile)(oldPost, upd, mentions, tags, editedAt)
+		AssertSomeError(t, err)
+	})
+	t.Run("happy case", func(t *testing.T) {
+		var deleted []core_values.StaticPath
+		deleteFile := func(path core_values.StaticPath) error {
+			deleted = append(deleted, path)
+			return nil
+		}
+		err := store.NewStorePostUpdater(runInUnit, storeImages, updatePost, replaceMentions, updateTags, deleteFile)(oldPost, upd, mentions, tags, editedAt)
+		AssertNoError(t, err)
+		Assert(t, deleted, []core_values.StaticPath{oldModel.Images[1].Path}, "deleted files of removed images")
+	})
+}
+
+func TestStorePostGetter(t *testing.T) {
+	toURL := static_store.NewPathToURLConverter(RandomString())
+	post := RandomId()
+	postModel := RandomPostModel()
+	reactions := RandomReactionCounts()
+	commentsCount := RandomInt()
+	mentions := RandomMentions()
+	dbGetter := func(postId values.PostId) (models.PostModel, error) {
+		if postId == post {
+			return postModel, nil
+		}
+		panic("unexpected args")
+	}
+	t.Run("error case - getting post from db throws", func(t *testing.T) {
+		dbGetter := func(values.PostId) (models.PostModel, error) {
+			return models.PostModel{}, RandomError()
+		}
+		_, err := store.NewStorePostGetter(dbGetter, nil, nil, nil, toURL)(post)
+		AssertSomeError(t, err)
+	})
+	reactionsGetter := func(targetIds []string) (map[string]likeable_values.ReactionCounts, error) {
+		if reflect.DeepEqual(targetIds, []string{postModel.Id}) {
+			return map[string]likeable_values.ReactionCounts{postModel.Id: reactions}, nil
+		}
+		panic("unexpected args")
+	}
+	t.Run("error case - getting reaction counts throws", func(t *testing.T) {
+		reactionsGetter := func([]string) (map[string]likeable_values.ReactionCounts, error) {
+			return nil, RandomError()
+		}
+		_, err := store.NewStorePostGetter(dbGetter, reactionsGetter, nil, nil, toURL)(post)
+		AssertSomeError(t, err)
+	})
+	commentCountsGetter := func(postIds []values.PostId) (map[values.PostId]int, error) {
+		if reflect.DeepEqual(postIds, []values.PostId{postModel.Id}) {
+			return map[values.PostId]int{postModel.Id: commentsCount}, nil
+		}
+		panic("unexpected args")
+	}
+	t.Run("error case - getting comment counts throws", func(t *testing.T) {
+		commentCountsGetter := func([]values.PostId) (map[values.PostId]int, error) {
+			return nil, RandomError()
+		}
+		_, err := store.NewStorePostGetter(dbGetter, reactionsGetter, commentCountsGetter, nil, toURL)(post)
+		AssertSomeError(t, err)
+	})
+	mentionsGetter := func(targetIds []string) (map[string][]mentionable_values.Mention, error) {
+		if reflect.DeepEqual(targetIds, []string{postModel.Id}) {
+			return map[string][]mentionable_values.Mention{postModel.Id: mentions}, nil
+		}
+		panic("unexpected args")
+	}
+	t.Run("error case - getting mentions throws", func(t *testing.T) {
+		mentionsGetter := func([]string) (map[string][]mentionable_values.Mention, error) {
 			return nil, RandomError()
 		}
-		_, err := store.NewStorePostsGetter(dbGetter, nil)(author)
+		_, err := store.NewStorePostGetter(dbGetter, reactionsGetter, commentCountsGetter, mentionsGetter, toURL)(post)
 		AssertSomeError(t, err)
 	})
-	likesGetter := func(targetId string) (int, error) {
-		if targetId == postModels[0].Id {
-			return likes, nil
+	gotPost, err := store.NewStorePostGetter(dbGetter, reactionsGetter, commentCountsGetter, mentionsGetter, toURL)(post)
+	AssertNoError(t, err)
+	wantPost := entities.Post{
+		PostModel:     postModel,
+		Images:        entities.ImagePathsToUrls(postModel.Images, toURL),
+		Likes:         reactions.Total(),
+		Reactions:     reactions,
+		CommentsCount: commentsCount,
+		Mentions:      mentions,
+	}
+	Assert(t, gotPost, wantPost, "returned post")
+}
+
+func TestStoreTagPostsGetter(t *testing.T) {
+	toURL := static_store.NewPathToURLConverter(RandomString())
+	tag := RandomString()
+	page := pagination.Page{Limit: RandomInt()}
+	postModels := []models.PostModel{RandomPostModel()}
+	reactions := RandomReactionCounts()
+	dbGetter := func(gotTag values.Tag, gotPage pagination.Page) ([]models.PostModel, error) {
+		if gotTag == tag && gotPage == page {
+			return postModels, nil
 		}
 		panic("unexpected args")
 	}
-	t.Run("error case - getting likes throws", func(t *testing.T) {
-		likesGetter := func(string) (int, error) {
-			return 0, RandomError()
+	t.Run("error case - getting posts from db throws", func(t *testing.T) {
+		dbGetter := func(values.Tag, pagination.Page) ([]models.PostModel, error) {
+			return nil, RandomError()
 		}
-		_, err := store.NewStorePostsGetter(dbGetter, likesGetter)(author)
+		_, err := store.NewStoreTagPostsGetter(dbGetter, nil, nil, nil, toURL)(tag, page)
 		AssertSomeError(t, err)
 	})
-	gotPosts, err := store.NewStorePostsGetter(dbGetter, likesGetter)(author)
+	reactionsGetter := func([]string) (map[string]likeable_values.ReactionCounts, error) {
+		return map[string]likeable_values.ReactionCounts{postModels[0].Id: reactions}, nil
+	}
+	commentCountsGetter := func([]values.PostId) (map[values.PostId]int, error) {
+		return map[values.PostId]int{}, nil
+	}
+	mentionsGetter := func([]string) (map[string][]mentionable_values.Mention, error) {
+		return map[string][]mentionable_values.Mention{}, nil
+	}
+	gotPosts, err := store.NewStoreTagPostsGetter(dbGetter, reactionsGetter, commentCountsGetter, mentionsGetter, toURL)(tag, page)
 	AssertNoError(t, err)
 	wantPosts := []entities.Post{{
 		PostModel: postModels[0],
-		Images:    entities.ImagePathsToUrls(postModels[0].Images),
-		Likes:     likes,
+		Images:    entities.ImagePathsToUrls(postModels[0].Images, toURL),
+		Likes:     reactions.Total(),
+		Reactions: reactions,
 	}}
 	Assert(t, gotPosts, wantPosts, "returned posts")
 }
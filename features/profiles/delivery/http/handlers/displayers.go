@@ -58,11 +58,39 @@ func NewGetFollowsHandler(followsGetter service.FollowsGetter) http.HandlerFunc
 			helpers.ThrowClientError(w, client_errors.IdNotProvided)
 			return
 		}
-		follows, err := followsGetter(id, caller.Id)
+		page, ok := helpers.GetPageOrThrowClientError(w, r)
+		if !ok {
+			return
+		}
+		follows, next, err := followsGetter(id, caller.Id, page)
+		if err != nil {
+			helpers.HandleServiceError(w, err)
+			return
+		}
+		helpers.WriteJson(w, responses.NewProfilesResponse(follows, next))
+	})
+}
+
+func NewGetFollowersHandler(getFollowers service.LikersGetter) http.HandlerFunc {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		caller, ok := helpers.GetUserOrAddUnauthorized(w, r)
+		if !ok {
+			return
+		}
+		id := chi.URLParam(r, "id")
+		if id == "" {
+			helpers.ThrowClientError(w, client_errors.IdNotProvided)
+			return
+		}
+		page, ok := helpers.GetPageOrThrowClientError(w, r)
+		if !ok {
+			return
+		}
+		followers, next, err := getFollowers(id, caller.Id, page)
 		if err != nil {
 			helpers.HandleServiceError(w, err)
 			return
 		}
-		helpers.WriteJson(w, responses.NewProfilesResponse(follows))
+		helpers.WriteJson(w, responses.NewProfilesResponse(followers, next))
 	})
 }
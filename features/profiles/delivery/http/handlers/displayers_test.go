@@ -6,6 +6,7 @@ import (
 	"github.com/k0marov/go-socnet/core/general/client_errors"
 	"github.com/k0marov/go-socnet/core/general/core_entities"
 	"github.com/k0marov/go-socnet/core/general/core_values"
+	"github.com/k0marov/go-socnet/core/general/pagination"
 	helpers "github.com/k0marov/go-socnet/core/helpers/http_test_helpers"
 	. "github.com/k0marov/go-socnet/core/helpers/test_helpers"
 	"net/http"
@@ -96,19 +97,31 @@ func TestFollowsHandler(t *testing.T) {
 	t.Run("should return 200 and a list of profiles if profile with given id exists", func(t *testing.T) {
 		randomId := RandomString()
 		randomProfiles := []entities.ContextedProfile{RandomContextedProfile(), RandomContextedProfile()}
-		followsGetter := func(userId, callerId core_values.UserId) ([]entities.ContextedProfile, error) {
-			if userId == randomId && callerId == caller.Id {
-				return randomProfiles, nil
+		wantPage := pagination.Page{After: pagination.Cursor{Id: RandomId()}, Limit: 2}
+		next := pagination.Cursor{CreatedAt: int64(RandomInt()), Id: RandomId()}
+		followsGetter := func(userId, callerId core_values.UserId, page pagination.Page) ([]entities.ContextedProfile, pagination.Cursor, error) {
+			if userId == randomId && callerId == caller.Id && page == wantPage {
+				return randomProfiles, next, nil
 			}
 			panic("called with unexpected arguments")
 		}
 
-		request := helpers.AddAuthDataToRequest(createRequestWithId(randomId), caller)
+		request := createRequestWithId(randomId)
+		request.URL.RawQuery = "limit=2&cursor=" + wantPage.After.Encode()
+		request = helpers.AddAuthDataToRequest(request, caller)
 		response := httptest.NewRecorder()
 
 		handlers.NewGetFollowsHandler(followsGetter).ServeHTTP(response, request)
 
-		AssertJSONData(t, response, responses.NewProfilesResponse(randomProfiles))
+		AssertJSONData(t, response, responses.NewProfilesResponse(randomProfiles, next))
+	})
+	t.Run("error case - limit is invalid", func(t *testing.T) {
+		request := createRequestWithId(RandomString())
+		request.URL.RawQuery = "limit=-1"
+		request = helpers.AddAuthDataToRequest(request, caller)
+		response := httptest.NewRecorder()
+		handlers.NewGetFollowsHandler(nil).ServeHTTP(response, request)
+		AssertClientError(t, response, client_errors.InvalidLimit)
 	})
 	t.Run("error case - id is not provided", func(t *testing.T) {
 		response := httptest.NewRecorder()
@@ -117,10 +130,50 @@ func TestFollowsHandler(t *testing.T) {
 		AssertClientError(t, response, client_errors.IdNotProvided)
 	})
 	helpers.BaseTestServiceErrorHandling(t, func(err error, rr *httptest.ResponseRecorder) {
-		getter := func(userId, callerId core_values.UserId) ([]entities.ContextedProfile, error) {
-			return nil, err
+		getter := func(userId, callerId core_values.UserId, page pagination.Page) ([]entities.ContextedProfile, pagination.Cursor, error) {
+			return nil, pagination.Cursor{}, err
 		}
 		request := helpers.AddAuthDataToRequest(createRequestWithId("42"), RandomAuthUser())
 		handlers.NewGetFollowsHandler(getter).ServeHTTP(rr, request)
 	})
 }
+
+func TestFollowersHandler(t *testing.T) {
+	caller := RandomAuthUser()
+	helpers.BaseTest401(t, handlers.NewGetFollowersHandler(nil))
+	t.Run("happy case", func(t *testing.T) {
+		target := RandomId()
+		followers := []entities.ContextedProfile{RandomContextedProfile(), RandomContextedProfile()}
+		wantPage := pagination.Page{After: pagination.Cursor{Id: RandomId()}, Limit: 2}
+		next := pagination.Cursor{CreatedAt: int64(RandomInt()), Id: RandomId()}
+		getFollowers := func(targetId string, callerId core_values.UserId, page pagination.Page) ([]entities.ContextedProfile, pagination.Cursor, error) {
+			if targetId == target && callerId == caller.Id && page == wantPage {
+				return followers, next, nil
+			}
+			panic("unexpected args")
+		}
+		request := createRequestWithId(target)
+		request.URL.RawQuery = "limit=2&cursor=" + wantPage.After.Encode()
+		response := httptest.NewRecorder()
+		handlers.NewGetFollowersHandler(getFollowers).ServeHTTP(response, helpers.AddAuthDataToRequest(request, caller))
+		AssertJSONData(t, response, responses.NewProfilesResponse(followers, next))
+	})
+	t.Run("error case - id is not provided", func(t *testing.T) {
+		response := httptest.NewRecorder()
+		handlers.NewGetFollowersHandler(nil).ServeHTTP(response, helpers.AddAuthDataToRequest(helpers.CreateRequest(nil), caller))
+		AssertClientError(t, response, client_errors.IdNotProvided)
+	})
+	t.Run("error case - limit is too big", func(t *testing.T) {
+		request := createRequestWithId(RandomId())
+		request.URL.RawQuery = "limit=1000"
+		response := httptest.NewRecorder()
+		handlers.NewGetFollowersHandler(nil).ServeHTTP(response, helpers.AddAuthDataToRequest(request, caller))
+		AssertClientError(t, response, client_errors.TooBigLimit)
+	})
+	helpers.BaseTestServiceErrorHandling(t, func(err error, rr *httptest.ResponseRecorder) {
+		getFollowers := func(string, core_values.UserId, pagination.Page) ([]entities.ContextedProfile, pagination.Cursor, error) {
+			return nil, pagination.Cursor{}, err
+		}
+		handlers.NewGetFollowersHandler(getFollowers).ServeHTTP(rr, helpers.AddAuthDataToRequest(createRequestWithId(RandomId()), caller))
+	})
+}
@@ -3,6 +3,7 @@ package handlers
 import (
 	"encoding/json"
 	"github.com/k0marov/go-socnet/core/general/client_errors"
+	"github.com/k0marov/go-socnet/core/general/core_values"
 	helpers "github.com/k0marov/go-socnet/core/helpers/http_helpers"
 	"github.com/k0marov/go-socnet/features/profiles/delivery/http/responses"
 	"net/http"
@@ -38,6 +39,18 @@ func NewUpdateMeHandler(profileUpdater service.ProfileUpdater) http.HandlerFunc
 }
 
 func NewToggleFollowHandler(followToggler service.FollowToggler) http.HandlerFunc {
+	return newFollowActionHandler(followToggler)
+}
+
+func NewFollowHandler(follow service.FollowAdder) http.HandlerFunc {
+	return newFollowActionHandler(follow)
+}
+
+func NewUnfollowHandler(unfollow service.FollowRemover) http.HandlerFunc {
+	return newFollowActionHandler(unfollow)
+}
+
+func newFollowActionHandler(action func(target, follower core_values.UserId) error) http.HandlerFunc {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		follower, ok := helpers.GetUserOrAddUnauthorized(w, r)
 		if !ok {
@@ -50,7 +63,7 @@ func NewToggleFollowHandler(followToggler service.FollowToggler) http.HandlerFun
 			return
 		}
 
-		err := followToggler(targetId, follower.Id)
+		err := action(targetId, follower.Id)
 		if err != nil {
 			helpers.HandleServiceError(w, err)
 			return
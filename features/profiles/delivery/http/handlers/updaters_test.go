@@ -98,6 +98,47 @@ func TestToggleFollowHandler(t *testing.T) {
 	})
 }
 
+func TestFollowAndUnfollowHandlers(t *testing.T) {
+	type action = func(target, follower core_values.UserId) error
+	newHandlers := map[string]func(action) http.HandlerFunc{
+		"follow":   func(follow action) http.HandlerFunc { return handlers.NewFollowHandler(follow) },
+		"unfollow": func(unfollow action) http.HandlerFunc { return handlers.NewUnfollowHandler(unfollow) },
+	}
+	for name, newHandler := range newHandlers {
+		t.Run(name, func(t *testing.T) {
+			helpers.BaseTest401(t, newHandler(nil))
+			t.Run("happy case", func(t *testing.T) {
+				targetId := RandomString()
+				followerAuth := RandomAuthUser()
+				called := false
+				act := func(target, follower core_values.UserId) error {
+					if follower == followerAuth.Id && target == targetId {
+						called = true
+						return nil
+					}
+					panic("called with unexpected args")
+				}
+				request := helpers.AddAuthDataToRequest(createRequestWithId(targetId), followerAuth)
+				response := httptest.NewRecorder()
+				newHandler(act).ServeHTTP(response, request)
+				AssertStatusCode(t, response, http.StatusOK)
+				Assert(t, called, true, "service called")
+			})
+			t.Run("error case - id is not provided", func(t *testing.T) {
+				response := httptest.NewRecorder()
+				newHandler(nil).ServeHTTP(response, helpers.AddAuthDataToRequest(helpers.CreateRequest(nil), RandomAuthUser()))
+				AssertClientError(t, response, client_errors.IdNotProvided)
+			})
+			helpers.BaseTestServiceErrorHandling(t, func(err error, w *httptest.ResponseRecorder) {
+				act := func(target, follower core_values.UserId) error {
+					return err
+				}
+				newHandler(act).ServeHTTP(w, helpers.AddAuthDataToRequest(createRequestWithId("42"), RandomAuthUser()))
+			})
+		})
+	}
+}
+
 func TestUpdateAvatarHandler(t *testing.T) {
 	authUser := RandomAuthUser()
 	user := core_entities.UserFromAuth(authUser)
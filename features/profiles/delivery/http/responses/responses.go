@@ -1,6 +1,7 @@
 package responses
 
 import (
+	"github.com/k0marov/go-socnet/core/general/pagination"
 	"github.com/k0marov/go-socnet/core/helpers"
 	"github.com/k0marov/go-socnet/features/profiles/domain/entities"
 )
@@ -21,7 +22,8 @@ type ProfileResponse struct {
 }
 
 type ProfilesResponse struct {
-	Profiles []ProfileResponse `json:"profiles"`
+	Profiles   []ProfileResponse `json:"profiles"`
+	NextCursor string            `json:"next_cursor"`
 }
 
 func NewProfileResponse(profile entities.ContextedProfile) ProfileResponse {
@@ -37,8 +39,9 @@ func NewProfileResponse(profile entities.ContextedProfile) ProfileResponse {
 	}
 }
 
-func NewProfilesResponse(profiles []entities.ContextedProfile) ProfilesResponse {
+func NewProfilesResponse(profiles []entities.ContextedProfile, next pagination.Cursor) ProfilesResponse {
 	return ProfilesResponse{
-		Profiles: helpers.MapForEach(profiles, NewProfileResponse),
+		Profiles:   helpers.MapForEach(profiles, NewProfileResponse),
+		NextCursor: next.Encode(),
 	}
 }
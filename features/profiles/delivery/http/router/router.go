@@ -6,7 +6,7 @@ import (
 	"github.com/go-chi/chi/v5"
 )
 
-func NewProfilesRouter(updateMe, updateAvatar, getMe, getById, getFollowsById, toggleFollow http.HandlerFunc) func(r chi.Router) {
+func NewProfilesRouter(updateMe, updateAvatar, getMe, getById, getFollowsById, getFollowersById, toggleFollow, follow, unfollow http.HandlerFunc) func(r chi.Router) {
 	return func(r chi.Router) {
 		r.Get("/me", getMe)
 		r.Put("/me", updateMe)
@@ -14,6 +14,9 @@ func NewProfilesRouter(updateMe, updateAvatar, getMe, getById, getFollowsById, t
 
 		r.Get("/{id}", getById)
 		r.Get("/{id}/follows", getFollowsById)
+		r.Get("/{id}/followers", getFollowersById)
 		r.Post("/{id}/toggle-follow", toggleFollow)
+		r.Put("/{id}/follow", follow)
+		r.Delete("/{id}/follow", unfollow)
 	}
 }
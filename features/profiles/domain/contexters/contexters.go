@@ -9,6 +9,7 @@ import (
 )
 
 type ProfileContextAdder func(profile entities.Profile, caller core_values.UserId) (entities.ContextedProfile, error)
+type ProfilesContextAdder func(profiles map[core_values.UserId]entities.Profile, caller core_values.UserId) (map[core_values.UserId]entities.ContextedProfile, error)
 
 func NewProfileContextAdder(getContext likeable_contexters.OwnLikeContextGetter) ProfileContextAdder {
 	return func(profile entities.Profile, caller core_values.UserId) (entities.ContextedProfile, error) {
@@ -23,3 +24,24 @@ func NewProfileContextAdder(getContext likeable_contexters.OwnLikeContextGetter)
 		return contextedProfile, nil
 	}
 }
+
+func NewProfilesContextAdder(getContexts likeable_contexters.OwnLikeContextsGetter) ProfilesContextAdder {
+	return func(profiles map[core_values.UserId]entities.Profile, caller core_values.UserId) (map[core_values.UserId]entities.ContextedProfile, error) {
+		owners := map[string]core_values.UserId{}
+		for id := range profiles {
+			owners[id] = id
+		}
+		contexts, err := getContexts(owners, caller)
+		if err != nil {
+			return nil, core_err.Rethrow("getting contexts for profiles", err)
+		}
+		contextedProfiles := map[core_values.UserId]entities.ContextedProfile{}
+		for id, profile := range profiles {
+			contextedProfiles[id] = entities.ContextedProfile{
+				Profile:        profile,
+				OwnLikeContext: contexts[id],
+			}
+		}
+		return contextedProfiles, nil
+	}
+}
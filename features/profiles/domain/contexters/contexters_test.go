@@ -4,6 +4,7 @@ import (
 	likeable_contexters "github.com/k0marov/go-socnet/core/abstract/ownable_likeable/contexters"
 	"github.com/k0marov/go-socnet/core/general/core_values"
 	. "github.com/k0marov/go-socnet/core/helpers/test_helpers"
+	"reflect"
 	"testing"
 
 	"github.com/k0marov/go-socnet/features/profiles/domain/contexters"
@@ -38,3 +39,33 @@ func TestProfileContextAdder(t *testing.T) {
 	})
 
 }
+
+func TestProfilesContextAdder(t *testing.T) {
+	profile1, profile2 := RandomProfile(), RandomProfile()
+	profiles := map[core_values.UserId]entities.Profile{profile1.Id: profile1, profile2.Id: profile2}
+	caller := RandomId()
+	t.Run("happy case", func(t *testing.T) {
+		contexts := map[string]likeable_contexters.OwnLikeContext{profile1.Id: RandomLikeableContext(), profile2.Id: RandomLikeableContext()}
+		getContexts := func(owners map[string]core_values.UserId, callerId core_values.UserId) (map[string]likeable_contexters.OwnLikeContext, error) {
+			wantOwners := map[string]core_values.UserId{profile1.Id: profile1.Id, profile2.Id: profile2.Id}
+			if reflect.DeepEqual(owners, wantOwners) && callerId == caller {
+				return contexts, nil
+			}
+			panic("unexpected args")
+		}
+		gotProfiles, err := contexters.NewProfilesContextAdder(getContexts)(profiles, caller)
+		AssertNoError(t, err)
+		wantProfiles := map[core_values.UserId]entities.ContextedProfile{
+			profile1.Id: {Profile: profile1, OwnLikeContext: contexts[profile1.Id]},
+			profile2.Id: {Profile: profile2, OwnLikeContext: contexts[profile2.Id]},
+		}
+		Assert(t, gotProfiles, wantProfiles, "returned profiles")
+	})
+	t.Run("error case - getting contexts throws", func(t *testing.T) {
+		getContexts := func(map[string]core_values.UserId, core_values.UserId) (map[string]likeable_contexters.OwnLikeContext, error) {
+			return nil, RandomError()
+		}
+		_, err := contexters.NewProfilesContextAdder(getContexts)(profiles, caller)
+		AssertSomeError(t, err)
+	})
+}
@@ -1,14 +1,19 @@
 package service
 
 import (
+	"errors"
 	"fmt"
 	"github.com/k0marov/go-socnet/core/abstract/likeable"
+	likeable_values "github.com/k0marov/go-socnet/core/abstract/likeable/values"
 	"github.com/k0marov/go-socnet/core/general/client_errors"
 	"github.com/k0marov/go-socnet/core/general/core_entities"
 	"github.com/k0marov/go-socnet/core/general/core_err"
 	"github.com/k0marov/go-socnet/core/general/core_values"
+	"github.com/k0marov/go-socnet/core/general/events"
+	"github.com/k0marov/go-socnet/core/general/pagination"
 	"github.com/k0marov/go-socnet/core/general/static_store"
 	"github.com/k0marov/go-socnet/core/helpers"
+	"time"
 
 	"github.com/k0marov/go-socnet/features/profiles/domain/contexters"
 	"github.com/k0marov/go-socnet/features/profiles/domain/models"
@@ -21,18 +26,24 @@ import (
 
 type (
 	ProfileGetter  func(id, caller core_values.UserId) (entities.ContextedProfile, error)
+	ProfilesGetter func(ids []core_values.UserId, caller core_values.UserId) (map[core_values.UserId]entities.ContextedProfile, error)
 	ProfileUpdater func(core_entities.User, values.ProfileUpdateData) (entities.ContextedProfile, error)
 	AvatarUpdater  func(core_entities.User, values.AvatarData) (core_values.FileURL, error)
 	ProfileCreator func(core_entities.User) (entities.Profile, error)
 	FollowToggler  func(target, follower core_values.UserId) error
-	FollowsGetter  func(target, caller core_values.UserId) ([]entities.ContextedProfile, error)
+	FollowAdder    func(target, follower core_values.UserId) error
+	FollowRemover  func(target, follower core_values.UserId) error
+	// FollowsGetter returns the profiles followed by target, most recently followed first, and the cursor of the next page
+	FollowsGetter func(target, caller core_values.UserId, page pagination.Page) ([]entities.ContextedProfile, pagination.Cursor, error)
+	// LikersGetter returns the profiles which liked a target, newest likes first, and the cursor of the next page
+	LikersGetter func(target string, caller core_values.UserId, page pagination.Page) ([]entities.ContextedProfile, pagination.Cursor, error)
 )
 
 func NewProfileGetter(getProfile store.StoreProfileGetter, addContext contexters.ProfileContextAdder) ProfileGetter {
 	return func(id core_values.UserId, caller core_values.UserId) (entities.ContextedProfile, error) {
 		profile, err := getProfile(id)
 		if err != nil {
-			if err == core_err.ErrNotFound {
+			if errors.Is(err, core_err.ErrNotFound) {
 				return entities.ContextedProfile{}, client_errors.NotFound
 			}
 			return entities.ContextedProfile{}, core_err.Rethrow("getting profile in a service", err)
@@ -45,23 +56,94 @@ func NewProfileGetter(getProfile store.StoreProfileGetter, addContext contexters
 	}
 }
 
-func NewFollowToggler(toggleLike likeable.LikeToggler) FollowToggler {
-	return FollowToggler(toggleLike)
+// NewProfilesGetter returns a getter of profiles keyed by their ids, the ones that don't exist are missing from the map
+func NewProfilesGetter(getProfiles store.StoreProfilesGetter, addContexts contexters.ProfilesContextAdder) ProfilesGetter {
+	return func(ids []core_values.UserId, caller core_values.UserId) (map[core_values.UserId]entities.ContextedProfile, error) {
+		profiles, err := getProfiles(ids)
+		if err != nil {
+			return nil, core_err.Rethrow("getting profiles in a service", err)
+		}
+		contextedProfiles, err := addContexts(profiles, caller)
+		if err != nil {
+			return nil, core_err.Rethrow("adding context to profiles", err)
+		}
+		return contextedProfiles, nil
+	}
+}
+
+// NewFollowToggler publishes a Followed event when the target becomes followed, so that it is notified
+func NewFollowToggler(toggleLike likeable.LikeToggler, publish events.Publisher) FollowToggler {
+	return func(target, follower core_values.UserId) error {
+		isFollowed, err := toggleLike(target, follower)
+		if err != nil {
+			return core_err.Rethrow("toggling a follow", err)
+		}
+		if isFollowed {
+			publishFollowed(publish, target, follower)
+		}
+		return nil
+	}
+}
+
+// NewFollowAdder publishes a Followed event only when the follow is inserted, i.e. the target wasn't followed before
+func NewFollowAdder(like likeable.Liker, publish events.Publisher) FollowAdder {
+	return func(target, follower core_values.UserId) error {
+		inserted, err := like(target, follower)
+		if err != nil {
+			return core_err.Rethrow("adding a follow", err)
+		}
+		if inserted {
+			publishFollowed(publish, target, follower)
+		}
+		return nil
+	}
+}
+
+func publishFollowed(publish events.Publisher, target, follower core_values.UserId) {
+	publish(events.Event{
+		Kind:       events.Followed,
+		Actor:      follower,
+		Recipient:  target,
+		TargetType: events.Profile,
+		TargetId:   target,
+		CreatedAt:  time.Now(),
+	})
+}
+
+func NewFollowRemover(unlike likeable.Unliker) FollowRemover {
+	return FollowRemover(unlike)
+}
+
+func NewFollowsGetter(getUserLikes likeable.UserLikesPageGetter, getProfile ProfileGetter) FollowsGetter {
+	return func(target, caller core_values.UserId, page pagination.Page) ([]entities.ContextedProfile, pagination.Cursor, error) {
+		follows, err := getUserLikes(target, page)
+		if err != nil {
+			return []entities.ContextedProfile{}, pagination.Cursor{}, core_err.Rethrow("getting a page of profiles that target follows", err)
+		}
+		profiles, err := helpers.MapForEachWithErr(follows, func(follow likeable_values.Like) (entities.ContextedProfile, error) {
+			return getProfile(follow.Target, caller)
+		})
+		if err != nil {
+			return []entities.ContextedProfile{}, pagination.Cursor{}, core_err.Rethrow("getting profiles of follows", err)
+		}
+		return profiles, pagination.NextCursor(follows, page, likeable_values.Like.Cursor), nil
+	}
 }
 
-func NewFollowsGetter(getUserLikes likeable.UserLikesGetter, getProfile ProfileGetter) FollowsGetter {
-	return func(target, caller core_values.UserId) ([]entities.ContextedProfile, error) {
-		followIds, err := getUserLikes(target)
+// NewLikersGetter is used by other features to list the profiles which liked their Likeables
+func NewLikersGetter(getLikers likeable.LikersPageGetter, getProfile ProfileGetter) LikersGetter {
+	return func(target string, caller core_values.UserId, page pagination.Page) ([]entities.ContextedProfile, pagination.Cursor, error) {
+		likes, err := getLikers(target, page)
 		if err != nil {
-			return []entities.ContextedProfile{}, core_err.Rethrow("getting a list of profile ids that target follows", err)
+			return []entities.ContextedProfile{}, pagination.Cursor{}, core_err.Rethrow("getting a page of likes of target", err)
 		}
-		follows, err := helpers.MapForEachWithErr(followIds, func(followId string) (entities.ContextedProfile, error) {
-			return getProfile(followId, caller)
+		likers, err := helpers.MapForEachWithErr(likes, func(like likeable_values.Like) (entities.ContextedProfile, error) {
+			return getProfile(like.Liker, caller)
 		})
 		if err != nil {
-			return []entities.ContextedProfile{}, core_err.Rethrow("getting profiles of follows", err)
+			return []entities.ContextedProfile{}, pagination.Cursor{}, core_err.Rethrow("getting profiles of likers", err)
 		}
-		return follows, nil
+		return likers, pagination.NextCursor(likes, page, likeable_values.Like.Cursor), nil
 	}
 }
 
@@ -107,7 +189,7 @@ func NewProfileCreator(storeProfileCreator store.StoreProfileCreator) ProfileCre
 	}
 }
 
-func NewAvatarUpdater(validator validators.AvatarValidator, storeAvatar store.StoreAvatarUpdater) AvatarUpdater {
+func NewAvatarUpdater(validator validators.AvatarValidator, storeAvatar store.StoreAvatarUpdater, toURL static_store.PathToURLConverter) AvatarUpdater {
 	return func(user core_entities.User, avatar values.AvatarData) (core_values.FileURL, error) {
 		if clientError, ok := validator(avatar); !ok {
 			return "", clientError
@@ -118,6 +200,6 @@ func NewAvatarUpdater(validator validators.AvatarValidator, storeAvatar store.St
 			return "", fmt.Errorf("got an error while storing updated avatar: %w", err)
 		}
 
-		return static_store.PathToURL(avatarPath), nil
+		return toURL(avatarPath), nil
 	}
 }
@@ -2,13 +2,18 @@ package service_test
 
 import (
 	"fmt"
+	likeable_values "github.com/k0marov/go-socnet/core/abstract/likeable/values"
 	"github.com/k0marov/go-socnet/core/general/client_errors"
 	"github.com/k0marov/go-socnet/core/general/core_err"
 	"github.com/k0marov/go-socnet/core/general/core_values"
 	"github.com/k0marov/go-socnet/core/general/core_values/ref"
+	"github.com/k0marov/go-socnet/core/general/events"
+	"github.com/k0marov/go-socnet/core/general/pagination"
 	"github.com/k0marov/go-socnet/core/general/static_store"
 	. "github.com/k0marov/go-socnet/core/helpers/test_helpers"
+	"reflect"
 	"testing"
+	"time"
 
 	"github.com/k0marov/go-socnet/features/profiles/domain/models"
 
@@ -36,6 +41,13 @@ func TestProfileGetter(t *testing.T) {
 		_, err := sut(target, caller)
 		AssertError(t, err, client_errors.NotFound)
 	})
+	t.Run("error case - store returns a wrapped NotFoundErr", func(t *testing.T) {
+		getProfile := func(core_values.UserId) (entities.Profile, error) {
+			return entities.Profile{}, core_err.Rethrow("getting the profile model from db", core_err.ErrNotFound)
+		}
+		_, err := service.NewProfileGetter(getProfile, nil)(target, caller)
+		AssertError(t, err, client_errors.NotFound)
+	})
 	t.Run("error case - store returns some other error", func(t *testing.T) {
 		getProfile := func(core_values.UserId) (entities.Profile, error) {
 			return entities.Profile{}, RandomError()
@@ -64,29 +76,150 @@ func TestProfileGetter(t *testing.T) {
 	})
 }
 
+func TestProfilesGetter(t *testing.T) {
+	ids := []core_values.UserId{RandomId(), RandomId()}
+	caller := RandomId()
+	profiles := map[core_values.UserId]entities.Profile{ids[0]: RandomProfile(), ids[1]: RandomProfile()}
+	contextedProfiles := map[core_values.UserId]entities.ContextedProfile{ids[0]: RandomContextedProfile(), ids[1]: RandomContextedProfile()}
+	getProfiles := func(gotIds []core_values.UserId) (map[core_values.UserId]entities.Profile, error) {
+		if reflect.DeepEqual(gotIds, ids) {
+			return profiles, nil
+		}
+		panic("unexpected args")
+	}
+	t.Run("error case - store throws", func(t *testing.T) {
+		getProfiles := func([]core_values.UserId) (map[core_values.UserId]entities.Profile, error) {
+			return nil, RandomError()
+		}
+		_, err := service.NewProfilesGetter(getProfiles, nil)(ids, caller)
+		AssertSomeError(t, err)
+	})
+	addContexts := func(gotProfiles map[core_values.UserId]entities.Profile, callerId core_values.UserId) (map[core_values.UserId]entities.ContextedProfile, error) {
+		if reflect.DeepEqual(gotProfiles, profiles) && callerId == caller {
+			return contextedProfiles, nil
+		}
+		panic("unexpected args")
+	}
+	t.Run("error case - adding contexts throws", func(t *testing.T) {
+		addContexts := func(map[core_values.UserId]entities.Profile, core_values.UserId) (map[core_values.UserId]entities.ContextedProfile, error) {
+			return nil, RandomError()
+		}
+		_, err := service.NewProfilesGetter(getProfiles, addContexts)(ids, caller)
+		AssertSomeError(t, err)
+	})
+	t.Run("happy case", func(t *testing.T) {
+		gotProfiles, err := service.NewProfilesGetter(getProfiles, addContexts)(ids, caller)
+		AssertNoError(t, err)
+		Assert(t, gotProfiles, contextedProfiles, "returned profiles")
+	})
+}
+
+func TestFollowToggler(t *testing.T) {
+	target := RandomId()
+	follower := RandomId()
+	noPublishing := func(events.Event) {
+		panic("unexpected publishing")
+	}
+	t.Run("error case - toggling throws", func(t *testing.T) {
+		toggleLike := func(string, core_values.UserId) (bool, error) {
+			return false, RandomError()
+		}
+		err := service.NewFollowToggler(toggleLike, noPublishing)(target, follower)
+		AssertSomeError(t, err)
+	})
+	t.Run("happy case - following publishes an event", func(t *testing.T) {
+		toggleLike := func(targetId string, liker core_values.UserId) (bool, error) {
+			if targetId == target && liker == follower {
+				return true, nil
+			}
+			panic("unexpected args")
+		}
+		var published []events.Event
+		publish := func(event events.Event) {
+			published = append(published, event)
+		}
+		err := service.NewFollowToggler(toggleLike, publish)(target, follower)
+		AssertNoError(t, err)
+		AssertFatal(t, len(published), 1, "number of published events")
+		Assert(t, TimeAlmostNow(published[0].CreatedAt), true, "event time is almost now")
+		published[0].CreatedAt = time.Time{}
+		want := events.Event{Kind: events.Followed, Actor: follower, Recipient: target, TargetType: events.Profile, TargetId: target}
+		Assert(t, published[0], want, "published event")
+	})
+	t.Run("happy case - unfollowing doesn't publish anything", func(t *testing.T) {
+		toggleLike := func(string, core_values.UserId) (bool, error) {
+			return false, nil
+		}
+		err := service.NewFollowToggler(toggleLike, noPublishing)(target, follower)
+		AssertNoError(t, err)
+	})
+}
+
+func TestFollowAdder(t *testing.T) {
+	target := RandomId()
+	follower := RandomId()
+	noPublishing := func(events.Event) {
+		panic("unexpected publishing")
+	}
+	newLike := func(inserted bool) func(string, core_values.UserId) (bool, error) {
+		return func(targetId string, liker core_values.UserId) (bool, error) {
+			if targetId == target && liker == follower {
+				return inserted, nil
+			}
+			panic("unexpected args")
+		}
+	}
+	t.Run("error case - following throws", func(t *testing.T) {
+		like := func(string, core_values.UserId) (bool, error) {
+			return false, RandomError()
+		}
+		err := service.NewFollowAdder(like, noPublishing)(target, follower)
+		AssertSomeError(t, err)
+	})
+	t.Run("happy case - following publishes an event", func(t *testing.T) {
+		var published []events.Event
+		publish := func(event events.Event) {
+			published = append(published, event)
+		}
+		err := service.NewFollowAdder(newLike(true), publish)(target, follower)
+		AssertNoError(t, err)
+		AssertFatal(t, len(published), 1, "number of published events")
+		published[0].CreatedAt = time.Time{}
+		want := events.Event{Kind: events.Followed, Actor: follower, Recipient: target, TargetType: events.Profile, TargetId: target}
+		Assert(t, published[0], want, "published event")
+	})
+	t.Run("happy case - following an already followed profile doesn't publish anything", func(t *testing.T) {
+		err := service.NewFollowAdder(newLike(false), noPublishing)(target, follower)
+		AssertNoError(t, err)
+	})
+}
+
 func TestFollowsGetter(t *testing.T) {
 	target := RandomId()
 	caller := RandomId()
-	follows := []core_values.UserId{RandomId()}
-	wantFollows := []entities.ContextedProfile{RandomContextedProfile()}
+	follows := []likeable_values.Like{{Id: RandomId(), Target: RandomId()}, {Id: RandomId(), Target: RandomId()}}
+	wantFollows := []entities.ContextedProfile{RandomContextedProfile(), RandomContextedProfile()}
+	page := pagination.Page{Limit: 2}
 
-	getFollows := func(id core_values.UserId) ([]core_values.UserId, error) {
-		if id == target {
+	getFollows := func(id core_values.UserId, gotPage pagination.Page) ([]likeable_values.Like, error) {
+		if id == target && gotPage == page {
 			return follows, nil
 		}
 		panic("unexpected args")
 	}
 	t.Run("error case - getting follows throws", func(t *testing.T) {
-		getFollows := func(core_values.UserId) ([]core_values.UserId, error) {
+		getFollows := func(core_values.UserId, pagination.Page) ([]likeable_values.Like, error) {
 			return nil, RandomError()
 		}
-		_, err := service.NewFollowsGetter(getFollows, nil)(target, caller)
+		_, _, err := service.NewFollowsGetter(getFollows, nil)(target, caller, page)
 		AssertSomeError(t, err)
 
 	})
 	getProfile := func(targetId, callerId core_values.UserId) (entities.ContextedProfile, error) {
-		if targetId == follows[0] && callerId == caller {
-			return wantFollows[0], nil
+		for i, follow := range follows {
+			if targetId == follow.Target && callerId == caller {
+				return wantFollows[i], nil
+			}
 		}
 		panic("unexpected args")
 	}
@@ -94,15 +227,59 @@ func TestFollowsGetter(t *testing.T) {
 		getProfile := func(target, caller core_values.UserId) (entities.ContextedProfile, error) {
 			return entities.ContextedProfile{}, RandomError()
 		}
-		_, err := service.NewFollowsGetter(getFollows, getProfile)(target, caller)
+		_, _, err := service.NewFollowsGetter(getFollows, getProfile)(target, caller, page)
 		AssertSomeError(t, err)
 	})
 
 	t.Run("happy case", func(t *testing.T) {
 		sut := service.NewFollowsGetter(getFollows, getProfile)
-		gotFollows, err := sut(target, caller)
+		gotFollows, next, err := sut(target, caller, page)
 		AssertNoError(t, err)
 		Assert(t, gotFollows, wantFollows, "returned follows")
+		Assert(t, next, follows[1].Cursor(), "cursor of the next page")
+	})
+}
+
+func TestLikersGetter(t *testing.T) {
+	target := RandomId()
+	caller := RandomId()
+	likes := []likeable_values.Like{{Id: RandomId(), Liker: RandomId()}, {Id: RandomId(), Liker: RandomId()}}
+	wantLikers := []entities.ContextedProfile{RandomContextedProfile(), RandomContextedProfile()}
+	page := pagination.Page{Limit: 2}
+
+	getLikers := func(targetId string, gotPage pagination.Page) ([]likeable_values.Like, error) {
+		if targetId == target && gotPage == page {
+			return likes, nil
+		}
+		panic("unexpected args")
+	}
+	t.Run("error case - getting likes throws", func(t *testing.T) {
+		getLikers := func(string, pagination.Page) ([]likeable_values.Like, error) {
+			return nil, RandomError()
+		}
+		_, _, err := service.NewLikersGetter(getLikers, nil)(target, caller, page)
+		AssertSomeError(t, err)
+	})
+	getProfile := func(id, callerId core_values.UserId) (entities.ContextedProfile, error) {
+		for i, like := range likes {
+			if id == like.Liker && callerId == caller {
+				return wantLikers[i], nil
+			}
+		}
+		panic("unexpected args")
+	}
+	t.Run("error case - getting profile throws", func(t *testing.T) {
+		getProfile := func(core_values.UserId, core_values.UserId) (entities.ContextedProfile, error) {
+			return entities.ContextedProfile{}, RandomError()
+		}
+		_, _, err := service.NewLikersGetter(getLikers, getProfile)(target, caller, page)
+		AssertSomeError(t, err)
+	})
+	t.Run("happy case", func(t *testing.T) {
+		gotLikers, next, err := service.NewLikersGetter(getLikers, getProfile)(target, caller, page)
+		AssertNoError(t, err)
+		Assert(t, gotLikers, wantLikers, "returned likers")
+		Assert(t, next, likes[1].Cursor(), "cursor of the next page")
 	})
 }
 
@@ -203,6 +380,7 @@ func TestProfileUpdater(t *testing.T) {
 }
 
 func TestAvatarUpdater(t *testing.T) {
+	toURL := static_store.NewPathToURLConverter(RandomString())
 	user := RandomUser()
 	data := []byte(RandomString())
 	dataRef, _ := ref.NewRef(&data)
@@ -216,14 +394,14 @@ func TestAvatarUpdater(t *testing.T) {
 
 	t.Run("happy case", func(t *testing.T) {
 		path := RandomString()
-		wantURL := static_store.PathToURL(path)
+		wantURL := toURL(path)
 		storeAvatar := func(userId string, avatarData values.AvatarData) (core_values.FileURL, error) {
 			if userId == user.Id && avatarData == testAvatarData {
 				return path, nil
 			}
 			panic(fmt.Sprintf("StoreAvatar called with unexpected arguments: userId=%v and avatarData=%v", userId, avatarData))
 		}
-		sut := service.NewAvatarUpdater(silentValidator, storeAvatar)
+		sut := service.NewAvatarUpdater(silentValidator, storeAvatar, toURL)
 
 		gotURL, err := sut(user, testAvatarData)
 		AssertNoError(t, err)
@@ -237,7 +415,7 @@ func TestAvatarUpdater(t *testing.T) {
 			}
 			panic(fmt.Sprintf("validator called with unexpected args, avatar=%v", avatar))
 		}
-		sut := service.NewAvatarUpdater(validator, nil) // storeAvatar is nil, since it shouldn't be called
+		sut := service.NewAvatarUpdater(validator, nil, nil) // storeAvatar is nil, since it shouldn't be called
 
 		_, err := sut(user, testAvatarData)
 		AssertError(t, err, clientError)
@@ -246,7 +424,7 @@ func TestAvatarUpdater(t *testing.T) {
 		storeAvatar := func(string, values.AvatarData) (core_values.FileURL, error) {
 			return "", RandomError()
 		}
-		sut := service.NewAvatarUpdater(silentValidator, storeAvatar)
+		sut := service.NewAvatarUpdater(silentValidator, storeAvatar, toURL)
 
 		_, err := sut(user, testAvatarData)
 		AssertSomeError(t, err)
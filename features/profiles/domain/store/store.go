@@ -9,7 +9,10 @@ import (
 
 type (
 	StoreProfileGetter  func(id core_values.UserId) (entities.Profile, error)
-	StoreProfileUpdater func(id core_values.UserId, upd values.ProfileUpdateData) error
-	StoreProfileCreator func(model models.ProfileModel) error
-	StoreAvatarUpdater  func(userId core_values.UserId, avatar values.AvatarData) (core_values.FileURL, error)
+	StoreProfilesGetter func(ids []core_values.UserId) (map[core_values.UserId]entities.Profile, error)
+	// StoreIdsByUsernamesGetter returns the ids of profiles with provided usernames, skipping the ones that don't exist
+	StoreIdsByUsernamesGetter func(usernames []string) (map[string]core_values.UserId, error)
+	StoreProfileUpdater       func(id core_values.UserId, upd values.ProfileUpdateData) error
+	StoreProfileCreator       func(model models.ProfileModel) error
+	StoreAvatarUpdater        func(userId core_values.UserId, avatar values.AvatarData) (core_values.FileURL, error)
 )
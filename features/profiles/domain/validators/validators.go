@@ -9,11 +9,9 @@ import (
 type ProfileUpdateValidator func(values.ProfileUpdateData) (client_errors.ClientError, bool)
 type AvatarValidator func(values.AvatarData) (client_errors.ClientError, bool)
 
-const MaxAboutLength = 255
-
-func NewProfileUpdateValidator() ProfileUpdateValidator {
+func NewProfileUpdateValidator(maxAboutLength int) ProfileUpdateValidator {
 	return func(profileUpdate values.ProfileUpdateData) (client_errors.ClientError, bool) {
-		if len(profileUpdate.About) > MaxAboutLength {
+		if len(profileUpdate.About) > maxAboutLength {
 			return client_errors.AboutTooLong, false
 		}
 		return client_errors.ClientError{}, true
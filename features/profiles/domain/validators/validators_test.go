@@ -15,6 +15,7 @@ import (
 )
 
 func TestProfileUpdateValidator(t *testing.T) {
+	const maxAboutLength = 255
 	cases := []struct {
 		profileUpdate values.ProfileUpdateData
 		ok            bool
@@ -22,9 +23,10 @@ func TestProfileUpdateValidator(t *testing.T) {
 	}{
 		{values.ProfileUpdateData{About: "abcdfeg"}, true, client_errors.ClientError{}},
 		{values.ProfileUpdateData{About: ""}, true, client_errors.ClientError{}},
-		{values.ProfileUpdateData{About: strings.Repeat("abc", 100)}, false, client_errors.AboutTooLong},
+		{values.ProfileUpdateData{About: strings.Repeat("a", maxAboutLength)}, true, client_errors.ClientError{}},
+		{values.ProfileUpdateData{About: strings.Repeat("a", maxAboutLength+1)}, false, client_errors.AboutTooLong},
 	}
-	sut := validators.NewProfileUpdateValidator()
+	sut := validators.NewProfileUpdateValidator(maxAboutLength)
 	for _, c := range cases {
 		t.Run(c.profileUpdate.About, func(t *testing.T) {
 			gotErr, gotOk := sut(c.profileUpdate)
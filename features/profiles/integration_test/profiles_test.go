@@ -4,10 +4,9 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
-	"github.com/jmoiron/sqlx"
 	"github.com/k0marov/go-socnet/core/general/core_entities"
 	"github.com/k0marov/go-socnet/core/general/core_values"
-	"github.com/k0marov/go-socnet/core/general/static_store"
+	"github.com/k0marov/go-socnet/core/general/events"
 	"github.com/k0marov/go-socnet/core/helpers"
 	. "github.com/k0marov/go-socnet/core/helpers/test_helpers"
 	"io"
@@ -32,22 +31,12 @@ import (
 )
 
 func TestProfiles(t *testing.T) {
-	// working directory
-	os.Mkdir("tmp_test", 0777)
-	os.Chdir("tmp_test")
-	defer func() {
-		os.Chdir("..")
-		os.RemoveAll("tmp_test")
-	}()
-
 	// profiles setup
-	sql, err := sqlx.Open("sqlite3", "file::memory:?cache=shared")
-	if err != nil {
-		t.Fatalf("error while opening in-memory database: %v", err)
-	}
+	cfg := TestConfig(t)
+	sql := OpenTestDB(t)
 
 	r := chi.NewRouter()
-	r.Route("/profiles", profiles.NewProfilesRouterImpl(sql))
+	r.Route("/profiles", profiles.NewProfilesRouterImpl(cfg, sql, events.NewPublisher()))
 
 	// fake auth setup
 	fakeRegisterRequest := func(newUser core_entities.User) { // mock registering a new user
@@ -114,7 +103,7 @@ func TestProfiles(t *testing.T) {
 
 		// update avatar for first user
 		wantAvatarPath := filepath.Join("profile_"+user1.Id, "avatar")
-		wantAvatarURL := static_store.StaticHost + "/" + wantAvatarPath
+		wantAvatarURL := cfg.Static.Host + "/" + wantAvatarPath
 		avatar := readFixture(t, "test_avatar.jpg")
 
 		body, contentType := createMultipartBody(avatar)
@@ -138,7 +127,7 @@ func TestProfiles(t *testing.T) {
 		checkProfileFromServer(t, wantUpdatedProfile1)
 
 		// assert avatar was stored
-		Assert(t, readFile(t, filepath.Join(static_store.StaticDir, wantAvatarPath)), avatar, "the stored avatar file")
+		Assert(t, readFile(t, filepath.Join(cfg.Static.Dir, wantAvatarPath)), avatar, "the stored avatar file")
 
 		// update profile for second user
 		upd := values.ProfileUpdateData{About: RandomString()}
@@ -163,20 +152,35 @@ func TestProfiles(t *testing.T) {
 		checkProfileFromServer(t, wantUpdatedProfile2)
 
 	})
+	checkProfileList := func(t testing.TB, path string, wantIds []core_values.UserId) {
+		t.Helper()
+		request := addAuthToReq(httptest.NewRequest(http.MethodGet, path, nil), RandomUser())
+		response := httptest.NewRecorder()
+		r.ServeHTTP(response, request)
+		var gotProfiles responses.ProfilesResponse
+		json.NewDecoder(response.Body).Decode(&gotProfiles)
+		gotProfileIds := helpers.MapForEach(
+			gotProfiles.Profiles,
+			func(profile responses.ProfileResponse) core_values.UserId { return profile.Id },
+		)
+		Assert(t, gotProfileIds, wantIds, "returned profiles' ids")
+	}
+	checkFollows := func(t testing.TB, id core_values.UserId, wantFollows []core_values.UserId) {
+		t.Helper()
+		checkProfileList(t, "/profiles/"+id+"/follows", wantFollows)
+	}
+	checkFollowers := func(t testing.TB, id core_values.UserId, wantFollowers []core_values.UserId) {
+		t.Helper()
+		checkProfileList(t, "/profiles/"+id+"/followers", wantFollowers)
+	}
+	toggleFollow := func(t testing.TB, target core_values.UserId, caller core_entities.User) {
+		t.Helper()
+		request := addAuthToReq(httptest.NewRequest(http.MethodPost, "/profiles/"+target+"/toggle-follow", nil), caller)
+		response := httptest.NewRecorder()
+		r.ServeHTTP(response, request)
+		AssertStatusCode(t, response, http.StatusOK)
+	}
 	t.Run("following", func(t *testing.T) {
-		checkFollows := func(t testing.TB, id core_values.UserId, wantFollows []core_values.UserId) {
-			t.Helper()
-			request := addAuthToReq(httptest.NewRequest(http.MethodGet, "/profiles/"+id+"/follows", nil), RandomUser())
-			response := httptest.NewRecorder()
-			r.ServeHTTP(response, request)
-			var gotProfiles responses.ProfilesResponse
-			json.NewDecoder(response.Body).Decode(&gotProfiles)
-			gotProfileIds := helpers.MapForEach(
-				gotProfiles.Profiles,
-				func(profile responses.ProfileResponse) core_values.UserId { return profile.Id },
-			)
-			Assert(t, gotProfileIds, wantFollows, "returned follows' ids")
-		}
 		assertIsFollowed := func(t testing.TB, target core_values.UserId, caller core_entities.User, isFollowed bool) {
 			t.Helper()
 			request := addAuthToReq(httptest.NewRequest(http.MethodGet, "/profiles/"+target, nil), caller)
@@ -213,6 +217,7 @@ func TestProfiles(t *testing.T) {
 
 		wantFollows := []core_values.UserId{wantProfile1.Id}
 		checkFollows(t, user2.Id, wantFollows)
+		checkFollowers(t, user1.Id, []core_values.UserId{user2.Id})
 		wantProfile2 := entities.Profile{
 			ProfileModel: models.ProfileModel{
 				Id:       user2.Id,
@@ -234,15 +239,29 @@ func TestProfiles(t *testing.T) {
 		wantProfile1.Followers = 0
 		checkProfileFromServer(t, wantProfile1)
 		checkFollows(t, user2.Id, nil)
+		checkFollowers(t, user1.Id, nil)
 		wantProfile2.Follows = 0
 		checkProfileFromServer(t, wantProfile2)
 	})
+	t.Run("follows and followers are listed newest first", func(t *testing.T) {
+		user1, user2, user3 := RandomUser(), RandomUser(), RandomUser()
+		fakeRegisterRequest(user1)
+		fakeRegisterRequest(user2)
+		fakeRegisterRequest(user3)
+
+		toggleFollow(t, user2.Id, user1)
+		toggleFollow(t, user3.Id, user1)
+		toggleFollow(t, user3.Id, user2)
+
+		checkFollows(t, user1.Id, []core_values.UserId{user3.Id, user2.Id})
+		checkFollowers(t, user3.Id, []core_values.UserId{user2.Id, user1.Id})
+	})
 
 }
 
 func readFixture(t testing.TB, filename string) []byte {
 	t.Helper()
-	return readFile(t, filepath.Join("..", "testdata", "test_avatar.jpg")) // ".." since we change the working directory to tmp_test
+	return readFile(t, filepath.Join("testdata", "test_avatar.jpg"))
 }
 
 func readFile(t testing.TB, filepath string) []byte {
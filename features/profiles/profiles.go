@@ -3,8 +3,11 @@ package profiles
 import (
 	"github.com/jmoiron/sqlx"
 	"github.com/k0marov/go-socnet/core/abstract/likeable"
+	"github.com/k0marov/go-socnet/core/abstract/mentionable"
 	likeable_contexters "github.com/k0marov/go-socnet/core/abstract/ownable_likeable/contexters"
+	"github.com/k0marov/go-socnet/core/general/config"
 	"github.com/k0marov/go-socnet/core/general/core_entities"
+	"github.com/k0marov/go-socnet/core/general/events"
 	"github.com/k0marov/go-socnet/core/general/image_decoder"
 	"github.com/k0marov/go-socnet/core/general/static_store"
 	"log"
@@ -23,6 +26,9 @@ import (
 	auth "github.com/k0marov/golang-auth"
 )
 
+// FollowedTableName is the target table of the Profile likeable, i.e. following a profile is liking it
+var FollowedTableName = sql_db.ProfileTableName
+
 func NewRegisterCallback(db *sqlx.DB) func(auth.User) {
 	// db
 	sqlDB, err := sql_db.NewSqlDB(db)
@@ -38,7 +44,7 @@ func NewRegisterCallback(db *sqlx.DB) func(auth.User) {
 	}
 }
 
-func NewProfileGetterImpl(db *sqlx.DB) service.ProfileGetter {
+func NewProfileGetterImpl(cfg config.Config, db *sqlx.DB) service.ProfileGetter {
 	sqlDB, err := sql_db.NewSqlDB(db)
 	if err != nil {
 		log.Fatalf("Error while opening sql db as a db for profiles: %v", err)
@@ -48,13 +54,66 @@ func NewProfileGetterImpl(db *sqlx.DB) service.ProfileGetter {
 		log.Fatalf("Error while creating a likeable Profile: %v", err)
 	}
 
-	addContext := contexters.NewProfileContextAdder(likeable_contexters.NewOwnLikeContextGetter(likeableProfile.IsLiked))
+	addContext := contexters.NewProfileContextAdder(likeable_contexters.NewOwnLikeContextGetter(likeableProfile.GetReaction))
 
-	getProfile := store.NewStoreProfileGetter(sqlDB.GetProfile, likeableProfile.GetLikesCount, likeableProfile.GetUserLikesCount)
+	getProfile := store.NewStoreProfileGetter(sqlDB.GetProfile, likeableProfile.GetLikesCount, likeableProfile.GetUserLikesCount, static_store.NewPathToURLConverter(cfg.Static.Host))
 	return service.NewProfileGetter(getProfile, addContext)
 }
 
-func NewProfilesRouterImpl(db *sqlx.DB) func(chi.Router) {
+// NewProfilesGetterImpl is used by other features that list many profiles at once
+func NewProfilesGetterImpl(cfg config.Config, db *sqlx.DB) service.ProfilesGetter {
+	sqlDB, err := sql_db.NewSqlDB(db)
+	if err != nil {
+		log.Fatalf("Error while opening sql db as a db for profiles: %v", err)
+	}
+	likeableProfile, err := likeable.NewLikeable(db, sqlDB.TableName)
+	if err != nil {
+		log.Fatalf("Error while creating a likeable Profile: %v", err)
+	}
+
+	addContexts := contexters.NewProfilesContextAdder(likeable_contexters.NewOwnLikeContextsGetter(likeableProfile.GetReactionsBatch))
+
+	getProfiles := store.NewStoreProfilesGetter(sqlDB.GetProfiles, likeableProfile.GetLikesCountBatch, likeableProfile.GetUserLikesCountBatch, static_store.NewPathToURLConverter(cfg.Static.Host))
+	return service.NewProfilesGetter(getProfiles, addContexts)
+}
+
+// NewFollowIdsGetterImpl returns a getter of ids of the profiles that the provided profile follows
+func NewFollowIdsGetterImpl(db *sqlx.DB) likeable.UserLikesGetter {
+	sqlDB, err := sql_db.NewSqlDB(db)
+	if err != nil {
+		log.Fatalf("Error while opening sql db as a db for profiles: %v", err)
+	}
+	likeableProfile, err := likeable.NewLikeable(db, sqlDB.TableName)
+	if err != nil {
+		log.Fatalf("Error while creating a likeable Profile: %v", err)
+	}
+	return likeableProfile.GetUserLikes
+}
+
+// NewUsernamesResolverImpl is used by other features to resolve the mentions of profiles by their usernames
+func NewUsernamesResolverImpl(db *sqlx.DB) mentionable.UsernamesResolver {
+	sqlDB, err := sql_db.NewSqlDB(db)
+	if err != nil {
+		log.Fatalf("Error while opening sql db as a db for profiles: %v", err)
+	}
+	return mentionable.UsernamesResolver(store.NewStoreIdsByUsernamesGetter(sqlDB.GetIdsByUsernames))
+}
+
+// NewLikeCountsRebuilderImpl returns a rebuilder of the stored followers and follows counters
+func NewLikeCountsRebuilderImpl(db *sqlx.DB) likeable.CountsRebuilder {
+	sqlDB, err := sql_db.NewSqlDB(db)
+	if err != nil {
+		log.Fatalf("Error while opening sql db as a db for profiles: %v", err)
+	}
+	likeableProfile, err := likeable.NewLikeable(db, sqlDB.TableName)
+	if err != nil {
+		log.Fatalf("Error while creating a likeable Profile: %v", err)
+	}
+	return likeableProfile.RebuildCounts
+}
+
+// NewProfilesRouterImpl publishes the events of following profiles
+func NewProfilesRouterImpl(cfg config.Config, db *sqlx.DB, publish events.Publisher) func(chi.Router) {
 	// db
 	sqlDB, err := sql_db.NewSqlDB(db)
 	if err != nil {
@@ -67,32 +126,39 @@ func NewProfilesRouterImpl(db *sqlx.DB) func(chi.Router) {
 	}
 
 	// file storage
-	avatarFileCreator := file_storage.NewAvatarFileCreator(static_store.NewStaticFileCreatorImpl())
+	avatarFileCreator := file_storage.NewAvatarFileCreator(static_store.NewStaticFileCreatorImpl(cfg.Static.Dir))
 
 	// store
-	storeProfileGetter := store.NewStoreProfileGetter(sqlDB.GetProfile, likeableProfile.GetLikesCount, likeableProfile.GetUserLikesCount)
+	toURL := static_store.NewPathToURLConverter(cfg.Static.Host)
+	storeProfileGetter := store.NewStoreProfileGetter(sqlDB.GetProfile, likeableProfile.GetLikesCount, likeableProfile.GetUserLikesCount, toURL)
 	storeProfileUpdater := store.NewStoreProfileUpdater(sqlDB.UpdateProfile)
 	storeAvatarUpdater := store.NewStoreAvatarUpdater(avatarFileCreator, sqlDB.UpdateProfile)
 
 	// domain
-	profileUpdateValidator := validators.NewProfileUpdateValidator()
+	profileUpdateValidator := validators.NewProfileUpdateValidator(cfg.Limits.MaxAboutLength)
 	avatarValidator := validators.NewAvatarValidator(image_decoder.ImageDecoderImpl)
 
-	addContext := contexters.NewProfileContextAdder(likeable_contexters.NewOwnLikeContextGetter(likeableProfile.IsLiked))
+	addContext := contexters.NewProfileContextAdder(likeable_contexters.NewOwnLikeContextGetter(likeableProfile.GetReaction))
 
 	profileGetter := service.NewProfileGetter(storeProfileGetter, addContext)
 	profileUpdater := service.NewProfileUpdater(profileUpdateValidator, storeProfileUpdater, profileGetter)
-	avatarUpdater := service.NewAvatarUpdater(avatarValidator, storeAvatarUpdater)
-	followToggler := service.NewFollowToggler(likeableProfile.ToggleLike)
-	followsGetter := service.NewFollowsGetter(likeableProfile.GetUserLikes, profileGetter)
+	avatarUpdater := service.NewAvatarUpdater(avatarValidator, storeAvatarUpdater, toURL)
+	followToggler := service.NewFollowToggler(likeableProfile.ToggleLike, publish)
+	followAdder := service.NewFollowAdder(likeableProfile.Like, publish)
+	followRemover := service.NewFollowRemover(likeableProfile.Unlike)
+	followsGetter := service.NewFollowsGetter(likeableProfile.GetUserLikesPage, profileGetter)
+	followersGetter := service.NewLikersGetter(likeableProfile.GetLikers, profileGetter)
 
 	// handlers
 	getMe := handlers.NewGetMeHandler(profileGetter)
 	updateMe := handlers.NewUpdateMeHandler(profileUpdater)
 	updateAvatar := handlers.NewUpdateAvatarHandler(avatarUpdater)
 	getFollows := handlers.NewGetFollowsHandler(followsGetter)
+	getFollowers := handlers.NewGetFollowersHandler(followersGetter)
 	getById := handlers.NewGetByIdHandler(profileGetter)
 	toggleFollow := handlers.NewToggleFollowHandler(followToggler)
+	follow := handlers.NewFollowHandler(followAdder)
+	unfollow := handlers.NewUnfollowHandler(followRemover)
 
-	return router.NewProfilesRouter(updateMe, updateAvatar, getMe, getById, getFollows, toggleFollow)
+	return router.NewProfilesRouter(updateMe, updateAvatar, getMe, getById, getFollows, getFollowers, toggleFollow, follow, unfollow)
 }
@@ -18,31 +18,18 @@ type SqlDB struct {
 	TableName table_name.TableName
 }
 
-func NewSqlDB(sql *sqlx.DB) (*SqlDB, error) {
-	err := initSQL(sql)
-	if err != nil {
-		return nil, err
-	}
-	return &SqlDB{sql: sql, TableName: table_name.NewTableName("Profile")}, nil
-}
+// ProfileTableName is the name of the Profile table, which is also the target table of the Profile likeable
+var ProfileTableName = table_name.NewTableName("Profile")
 
-func initSQL(sql *sqlx.DB) error {
-	_, err := sql.Exec(`CREATE TABLE IF NOT EXISTS Profile(
-		id INTEGER PRIMARY KEY,
-		username VARCHAR(255) NOT NULL,
-		about TEXT NOT NULL,
-		avatarPath VARCHAR(255) NOT NULL
-	);`)
-	if err != nil {
-		return core_err.Rethrow("creating Profile table", err)
-	}
-	return nil
+// NewSqlDB expects the schema to be already migrated with core/general/migrations
+func NewSqlDB(sql *sqlx.DB) (*SqlDB, error) {
+	return &SqlDB{sql: sql, TableName: ProfileTableName}, nil
 }
 
 func (db *SqlDB) CreateProfile(newProfile models.ProfileModel) error {
-	_, err := db.sql.Exec(`INSERT INTO Profile(id, username, about, avatarPath) values(
+	_, err := db.sql.Exec(db.sql.Rebind(`INSERT INTO Profile(id, username, about, avatarPath) values(
 		?, ?, ?, ?
-	)`, newProfile.Id, newProfile.Username, newProfile.About, newProfile.AvatarPath)
+	)`), newProfile.Id, newProfile.Username, newProfile.About, newProfile.AvatarPath)
 	if err != nil {
 		return fmt.Errorf("while inserting into Profile table: %v", err)
 	}
@@ -51,10 +38,10 @@ func (db *SqlDB) CreateProfile(newProfile models.ProfileModel) error {
 
 func (db *SqlDB) GetProfile(profileId core_values.UserId) (models.ProfileModel, error) {
 	var profile models.ProfileModel
-	err := db.sql.Get(&profile, `
+	err := db.sql.Get(&profile, db.sql.Rebind(`
 		SELECT id, username, about, avatarPath
 		FROM Profile
-		WHERE id = ?`,
+		WHERE id = ?`),
 		profileId,
 	)
 	if err == sql.ErrNoRows {
@@ -67,12 +54,56 @@ func (db *SqlDB) GetProfile(profileId core_values.UserId) (models.ProfileModel,
 	return profile, nil
 }
 
+// GetProfiles returns the profiles with provided ids in no particular order, skipping the ones that don't exist
+func (db *SqlDB) GetProfiles(ids []core_values.UserId) ([]models.ProfileModel, error) {
+	if len(ids) == 0 {
+		return []models.ProfileModel{}, nil
+	}
+	query, args, err := sqlx.In(`
+		SELECT id, username, about, avatarPath
+		FROM Profile
+		WHERE id IN (?)
+	`, ids)
+	if err != nil {
+		return []models.ProfileModel{}, core_err.Rethrow("building the query for profiles by ids", err)
+	}
+	profiles := []models.ProfileModel{}
+	err = db.sql.Select(&profiles, db.sql.Rebind(query), args...)
+	if err != nil {
+		return []models.ProfileModel{}, core_err.Rethrow("getting profiles from profile table", err)
+	}
+	return profiles, nil
+}
+
+// GetIdsByUsernames returns the ids of profiles with provided usernames, skipping the ones that don't exist
+func (db *SqlDB) GetIdsByUsernames(usernames []string) (map[string]core_values.UserId, error) {
+	ids := map[string]core_values.UserId{}
+	if len(usernames) == 0 {
+		return ids, nil
+	}
+	query, args, err := sqlx.In(`
+		SELECT id, username FROM Profile WHERE username IN (?)
+	`, usernames)
+	if err != nil {
+		return nil, core_err.Rethrow("building the query for profiles by usernames", err)
+	}
+	var profiles []models.ProfileModel
+	err = db.sql.Select(&profiles, db.sql.Rebind(query), args...)
+	if err != nil {
+		return nil, core_err.Rethrow("getting profiles by usernames from profile table", err)
+	}
+	for _, profile := range profiles {
+		ids[profile.Username] = profile.Id
+	}
+	return ids, nil
+}
+
 func (db *SqlDB) UpdateProfile(userId core_values.UserId, upd store.DBUpdateData) error {
-	_, err := db.sql.Exec(`
+	_, err := db.sql.Exec(db.sql.Rebind(`
 	UPDATE Profile SET 
-		avatarPath = CASE WHEN ?1 = "" THEN avatarPath ELSE ?1 END,
-		about = 	 CASE WHEN ?2 = "" THEN about ELSE ?2 END 
-	WHERE id = ?`, upd.AvatarPath, upd.About, userId)
+		avatarPath = CASE WHEN ? = '' THEN avatarPath ELSE ? END,
+		about = 	 CASE WHEN ? = '' THEN about ELSE ? END 
+	WHERE id = ?`), upd.AvatarPath, upd.AvatarPath, upd.About, upd.About, userId)
 	if err != nil {
 		return core_err.Rethrow("updating avatarPath in db", err)
 	}
@@ -13,7 +13,7 @@ import (
 )
 
 func TestSqlDB_ErrorHandling(t *testing.T) {
-	sql := OpenSqliteDB(t)
+	sql := OpenTestDB(t)
 	sut, err := sql_db.NewSqlDB(sql)
 	AssertNoError(t, err)
 	sql.Close() // this will force all calls to throw errors
@@ -21,6 +21,14 @@ func TestSqlDB_ErrorHandling(t *testing.T) {
 		_, err := sut.GetProfile(RandomString())
 		AssertSomeError(t, err)
 	})
+	t.Run("GetProfiles", func(t *testing.T) {
+		_, err := sut.GetProfiles([]string{RandomString()})
+		AssertSomeError(t, err)
+	})
+	t.Run("GetIdsByUsernames", func(t *testing.T) {
+		_, err := sut.GetIdsByUsernames([]string{RandomString()})
+		AssertSomeError(t, err)
+	})
 	t.Run("CreateProfile", func(t *testing.T) {
 		err := sut.CreateProfile(RandomProfileModel())
 		AssertSomeError(t, err)
@@ -35,7 +43,7 @@ func TestSqlDB(t *testing.T) {
 	t.Run("creating and reading profiles", func(t *testing.T) {
 		profileCount := 10
 
-		db, err := sql_db.NewSqlDB(OpenSqliteDB(t))
+		db, err := sql_db.NewSqlDB(OpenTestDB(t))
 		AssertNoError(t, err)
 
 		// create 10 random profiles
@@ -65,12 +73,26 @@ func TestSqlDB(t *testing.T) {
 		// assert querying for unexisting profile returns ErrNotFound
 		_, err = db.GetProfile("9999")
 		AssertError(t, err, core_err.ErrNotFound)
+
+		// assert they can be found all at once, skipping the unexisting one
+		gotProfiles, err := db.GetProfiles([]string{profiles[0].Id, "9999", profiles[1].Id})
+		AssertNoError(t, err)
+		Assert(t, len(gotProfiles), 2, "number of found profiles")
+		for _, got := range gotProfiles {
+			Assert(t, got == profiles[0] || got == profiles[1], true, "found profile is one of the requested")
+		}
+
+		// assert their ids can be found by usernames, skipping the unexisting one
+		gotIds, err := db.GetIdsByUsernames([]string{profiles[0].Username, "nobody_" + RandomString(), profiles[1].Username})
+		AssertNoError(t, err)
+		wantIds := map[string]string{profiles[0].Username: profiles[0].Id, profiles[1].Username: profiles[1].Id}
+		Assert(t, gotIds, wantIds, "ids found by usernames")
 	})
 	t.Run("updating profile", func(t *testing.T) {
 		newProfile1 := RandomProfileModel()
 		newProfile2 := RandomProfileModel()
 
-		db, err := sql_db.NewSqlDB(OpenSqliteDB(t))
+		db, err := sql_db.NewSqlDB(OpenTestDB(t))
 		AssertNoError(t, err)
 
 		// insert both profiles into database
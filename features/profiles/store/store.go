@@ -17,9 +17,11 @@ import (
 type (
 	AvatarFileCreator func(data ref.Ref[[]byte], belongsToUser core_values.UserId) (string, error)
 
-	DBProfileGetter  func(id core_values.UserId) (models.ProfileModel, error)
-	DBProfileCreator func(models.ProfileModel) error
-	DBProfileUpdater func(id core_values.UserId, updData DBUpdateData) error
+	DBProfileGetter        func(id core_values.UserId) (models.ProfileModel, error)
+	DBProfilesGetter       func(ids []core_values.UserId) ([]models.ProfileModel, error)
+	DBIdsByUsernamesGetter func(usernames []string) (map[string]core_values.UserId, error)
+	DBProfileCreator       func(models.ProfileModel) error
+	DBProfileUpdater       func(id core_values.UserId, updData DBUpdateData) error
 
 	DBFollowsGetter func(id core_values.UserId) ([]core_values.UserId, error)
 	DBFollowChecker func(target, follower core_values.UserId) (bool, error)
@@ -63,7 +65,7 @@ func NewStoreProfileCreator(createDBProfile DBProfileCreator) store.StoreProfile
 	return store.StoreProfileCreator(createDBProfile)
 }
 
-func NewStoreProfileGetter(getDBProfile DBProfileGetter, getFollowers likeable.LikesCountGetter, getFollows likeable.UserLikesCountGetter) store.StoreProfileGetter {
+func NewStoreProfileGetter(getDBProfile DBProfileGetter, getFollowers likeable.LikesCountGetter, getFollows likeable.UserLikesCountGetter, toURL static_store.PathToURLConverter) store.StoreProfileGetter {
 	return func(id core_values.UserId) (entities.Profile, error) {
 		profileModel, err := getDBProfile(id)
 		if err != nil {
@@ -79,10 +81,42 @@ func NewStoreProfileGetter(getDBProfile DBProfileGetter, getFollowers likeable.L
 		}
 		profile := entities.Profile{
 			ProfileModel: profileModel,
-			AvatarURL:    static_store.PathToURL(profileModel.AvatarPath),
+			AvatarURL:    toURL(profileModel.AvatarPath),
 			Follows:      follows,
 			Followers:    followers,
 		}
 		return profile, nil
 	}
 }
+
+// NewStoreProfilesGetter returns a getter of profiles keyed by their ids, the ones that don't exist are missing from the map
+func NewStoreIdsByUsernamesGetter(getIds DBIdsByUsernamesGetter) store.StoreIdsByUsernamesGetter {
+	return store.StoreIdsByUsernamesGetter(getIds)
+}
+
+func NewStoreProfilesGetter(getDBProfiles DBProfilesGetter, getFollowers likeable.LikesCountBatchGetter, getFollows likeable.UserLikesCountBatchGetter, toURL static_store.PathToURLConverter) store.StoreProfilesGetter {
+	return func(ids []core_values.UserId) (map[core_values.UserId]entities.Profile, error) {
+		profileModels, err := getDBProfiles(ids)
+		if err != nil {
+			return nil, core_err.Rethrow("getting the profile models from db", err)
+		}
+		followers, err := getFollowers(ids)
+		if err != nil {
+			return nil, core_err.Rethrow("getting the followers counts", err)
+		}
+		follows, err := getFollows(ids)
+		if err != nil {
+			return nil, core_err.Rethrow("getting the follows counts", err)
+		}
+		profiles := map[core_values.UserId]entities.Profile{}
+		for _, model := range profileModels {
+			profiles[model.Id] = entities.Profile{
+				ProfileModel: model,
+				AvatarURL:    toURL(model.AvatarPath),
+				Follows:      follows[model.Id],
+				Followers:    followers[model.Id],
+			}
+		}
+		return profiles, nil
+	}
+}
@@ -101,6 +101,7 @@ func TestStoreAvatarUpdater(t *testing.T) {
 }
 
 func TestStoreProfileGetter(t *testing.T) {
+	toURL := static_store.NewPathToURLConverter(RandomString())
 	profileId := RandomId()
 	model := RandomProfileModel()
 	follows := RandomInt()
@@ -116,7 +117,7 @@ func TestStoreProfileGetter(t *testing.T) {
 		dbGetter := func(core_values.UserId) (models.ProfileModel, error) {
 			return models.ProfileModel{}, RandomError()
 		}
-		_, err := store.NewStoreProfileGetter(dbGetter, nil, nil)(profileId)
+		_, err := store.NewStoreProfileGetter(dbGetter, nil, nil, toURL)(profileId)
 		AssertSomeError(t, err)
 	})
 	followersGetter := func(targetId core_values.UserId) (int, error) {
@@ -130,7 +131,7 @@ func TestStoreProfileGetter(t *testing.T) {
 		followersGetter := func(core_values.UserId) (int, error) {
 			return 0, wantErr
 		}
-		_, err := store.NewStoreProfileGetter(dbGetter, followersGetter, nil)(profileId)
+		_, err := store.NewStoreProfileGetter(dbGetter, followersGetter, nil, toURL)(profileId)
 		AssertError(t, err, wantErr)
 	})
 	followsGetter := func(targetId core_values.UserId) (int, error) {
@@ -144,18 +145,79 @@ func TestStoreProfileGetter(t *testing.T) {
 		followsGetter := func(id core_values.UserId) (int, error) {
 			return 0, wantErr
 		}
-		_, err := store.NewStoreProfileGetter(dbGetter, followersGetter, followsGetter)(profileId)
+		_, err := store.NewStoreProfileGetter(dbGetter, followersGetter, followsGetter, toURL)(profileId)
 		AssertError(t, err, wantErr)
 	})
 
-	sut := store.NewStoreProfileGetter(dbGetter, followersGetter, followsGetter)
+	sut := store.NewStoreProfileGetter(dbGetter, followersGetter, followsGetter, toURL)
 	gotProfile, err := sut(profileId)
 	AssertNoError(t, err)
 	wantProfile := entities.Profile{
 		ProfileModel: model,
-		AvatarURL:    static_store.PathToURL(model.AvatarPath),
+		AvatarURL:    toURL(model.AvatarPath),
 		Follows:      follows,
 		Followers:    followers,
 	}
 	Assert(t, gotProfile, wantProfile, "returned profile entity")
 }
+
+func TestStoreProfilesGetter(t *testing.T) {
+	toURL := static_store.NewPathToURLConverter(RandomString())
+	model1, model2 := RandomProfileModel(), RandomProfileModel()
+	ids := []core_values.UserId{model1.Id, model2.Id, RandomId()}
+	followers := map[core_values.UserId]int{model1.Id: RandomInt(), model2.Id: RandomInt()}
+	follows := map[core_values.UserId]int{model1.Id: RandomInt(), model2.Id: RandomInt()}
+
+	dbGetter := func(gotIds []core_values.UserId) ([]models.ProfileModel, error) {
+		if reflect.DeepEqual(gotIds, ids) {
+			return []models.ProfileModel{model1, model2}, nil
+		}
+		panic("unexpected args")
+	}
+	t.Run("error case - getting models from db throws", func(t *testing.T) {
+		dbGetter := func([]core_values.UserId) ([]models.ProfileModel, error) {
+			return nil, RandomError()
+		}
+		_, err := store.NewStoreProfilesGetter(dbGetter, nil, nil, toURL)(ids)
+		AssertSomeError(t, err)
+	})
+	followersGetter := func(gotIds []core_values.UserId) (map[core_values.UserId]int, error) {
+		if reflect.DeepEqual(gotIds, ids) {
+			return followers, nil
+		}
+		panic("unexpected args")
+	}
+	t.Run("error case - getting followers throws", func(t *testing.T) {
+		followersGetter := func([]core_values.UserId) (map[core_values.UserId]int, error) {
+			return nil, RandomError()
+		}
+		_, err := store.NewStoreProfilesGetter(dbGetter, followersGetter, nil, toURL)(ids)
+		AssertSomeError(t, err)
+	})
+	followsGetter := func(gotIds []core_values.UserId) (map[core_values.UserId]int, error) {
+		if reflect.DeepEqual(gotIds, ids) {
+			return follows, nil
+		}
+		panic("unexpected args")
+	}
+	t.Run("error case - getting follows throws", func(t *testing.T) {
+		followsGetter := func([]core_values.UserId) (map[core_values.UserId]int, error) {
+			return nil, RandomError()
+		}
+		_, err := store.NewStoreProfilesGetter(dbGetter, followersGetter, followsGetter, toURL)(ids)
+		AssertSomeError(t, err)
+	})
+
+	gotProfiles, err := store.NewStoreProfilesGetter(dbGetter, followersGetter, followsGetter, toURL)(ids)
+	AssertNoError(t, err)
+	wantProfiles := map[core_values.UserId]entities.Profile{}
+	for _, model := range []models.ProfileModel{model1, model2} {
+		wantProfiles[model.Id] = entities.Profile{
+			ProfileModel: model,
+			AvatarURL:    toURL(model.AvatarPath),
+			Follows:      follows[model.Id],
+			Followers:    followers[model.Id],
+		}
+	}
+	Assert(t, gotProfiles, wantProfiles, "returned profile entities")
+}
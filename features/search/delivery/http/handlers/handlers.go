@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"github.com/k0marov/go-socnet/core/general/client_errors"
+	"github.com/k0marov/go-socnet/core/helpers/http_helpers"
+	"github.com/k0marov/go-socnet/features/search/delivery/http/responses"
+	"github.com/k0marov/go-socnet/features/search/domain/service"
+	"github.com/k0marov/go-socnet/features/search/domain/values"
+	"net/http"
+
+	profile_responses "github.com/k0marov/go-socnet/features/profiles/delivery/http/responses"
+)
+
+// NewSearchHandler searches the entities of the "type" query argument (posts by default) by the "q" query argument
+func NewSearchHandler(searchPosts service.PostsSearcher, searchProfiles service.ProfilesSearcher, searchComments service.CommentsSearcher) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		caller, ok := http_helpers.GetUserOrAddUnauthorized(w, r)
+		if !ok {
+			return
+		}
+		page, ok := http_helpers.GetPageOrThrowClientError(w, r)
+		if !ok {
+			return
+		}
+		query := r.URL.Query().Get("q")
+		target := values.Target(r.URL.Query().Get("type"))
+		if target == "" {
+			target = values.Posts
+		}
+		switch target {
+		case values.Posts:
+			posts, next, err := searchPosts(query, caller.Id, page)
+			if err != nil {
+				http_helpers.HandleServiceError(w, err)
+				return
+			}
+			http_helpers.WriteJson(w, responses.NewPostsResponse(posts, next))
+		case values.Profiles:
+			profiles, next, err := searchProfiles(query, caller.Id, page)
+			if err != nil {
+				http_helpers.HandleServiceError(w, err)
+				return
+			}
+			http_helpers.WriteJson(w, profile_responses.NewProfilesResponse(profiles, next))
+		case values.Comments:
+			comments, next, err := searchComments(query, caller.Id, page)
+			if err != nil {
+				http_helpers.HandleServiceError(w, err)
+				return
+			}
+			http_helpers.WriteJson(w, responses.NewCommentsResponse(comments, next))
+		default:
+			http_helpers.ThrowClientError(w, client_errors.InvalidSearchType)
+		}
+	}
+}
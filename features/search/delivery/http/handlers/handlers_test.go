@@ -0,0 +1,87 @@
+package handlers_test
+
+import (
+	"github.com/k0marov/go-socnet/core/general/client_errors"
+	"github.com/k0marov/go-socnet/core/general/core_values"
+	"github.com/k0marov/go-socnet/core/general/pagination"
+	helpers "github.com/k0marov/go-socnet/core/helpers/http_test_helpers"
+	. "github.com/k0marov/go-socnet/core/helpers/test_helpers"
+	"github.com/k0marov/go-socnet/features/search/delivery/http/handlers"
+	"github.com/k0marov/go-socnet/features/search/delivery/http/responses"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	comment_entities "github.com/k0marov/go-socnet/features/comments/domain/entities"
+	post_entities "github.com/k0marov/go-socnet/features/posts/domain/entities"
+	profile_responses "github.com/k0marov/go-socnet/features/profiles/delivery/http/responses"
+	profile_entities "github.com/k0marov/go-socnet/features/profiles/domain/entities"
+)
+
+func TestSearchHandler(t *testing.T) {
+	caller := RandomAuthUser()
+	query := "some query"
+	wantPage := pagination.Page{Limit: 5}
+	next := pagination.Cursor{CreatedAt: int64(RandomInt()), Id: RandomId()}
+	createRequest := func(params string) *http.Request {
+		return helpers.AddAuthDataToRequest(httptest.NewRequest(http.MethodGet, "/handler-should-not-care?q=some+query&limit=5"+params, nil), caller)
+	}
+
+	helpers.BaseTest401(t, handlers.NewSearchHandler(nil, nil, nil))
+	t.Run("happy case - posts are searched by default", func(t *testing.T) {
+		posts := []post_entities.ContextedPost{RandomContextedPost()}
+		searchPosts := func(gotQuery string, callerId core_values.UserId, page pagination.Page) ([]post_entities.ContextedPost, pagination.Cursor, error) {
+			if gotQuery == query && callerId == caller.Id && page == wantPage {
+				return posts, next, nil
+			}
+			panic("unexpected args")
+		}
+		response := httptest.NewRecorder()
+		handlers.NewSearchHandler(searchPosts, nil, nil).ServeHTTP(response, createRequest(""))
+		AssertJSONData(t, response, responses.NewPostsResponse(posts, next))
+
+		response = httptest.NewRecorder()
+		handlers.NewSearchHandler(searchPosts, nil, nil).ServeHTTP(response, createRequest("&type=posts"))
+		AssertJSONData(t, response, responses.NewPostsResponse(posts, next))
+	})
+	t.Run("happy case - profiles", func(t *testing.T) {
+		profiles := []profile_entities.ContextedProfile{RandomContextedProfile()}
+		searchProfiles := func(gotQuery string, callerId core_values.UserId, page pagination.Page) ([]profile_entities.ContextedProfile, pagination.Cursor, error) {
+			if gotQuery == query && callerId == caller.Id && page == wantPage {
+				return profiles, next, nil
+			}
+			panic("unexpected args")
+		}
+		response := httptest.NewRecorder()
+		handlers.NewSearchHandler(nil, searchProfiles, nil).ServeHTTP(response, createRequest("&type=profiles"))
+		AssertJSONData(t, response, profile_responses.NewProfilesResponse(profiles, next))
+	})
+	t.Run("happy case - comments", func(t *testing.T) {
+		comments := []comment_entities.ContextedComment{RandomContextedComment()}
+		searchComments := func(gotQuery string, callerId core_values.UserId, page pagination.Page) ([]comment_entities.ContextedComment, pagination.Cursor, error) {
+			if gotQuery == query && callerId == caller.Id && page == wantPage {
+				return comments, next, nil
+			}
+			panic("unexpected args")
+		}
+		response := httptest.NewRecorder()
+		handlers.NewSearchHandler(nil, nil, searchComments).ServeHTTP(response, createRequest("&type=comments"))
+		AssertJSONData(t, response, responses.NewCommentsResponse(comments, next))
+	})
+	t.Run("error case - type is invalid", func(t *testing.T) {
+		response := httptest.NewRecorder()
+		handlers.NewSearchHandler(nil, nil, nil).ServeHTTP(response, createRequest("&type=tags"))
+		AssertClientError(t, response, client_errors.InvalidSearchType)
+	})
+	t.Run("error case - cursor is invalid", func(t *testing.T) {
+		response := httptest.NewRecorder()
+		handlers.NewSearchHandler(nil, nil, nil).ServeHTTP(response, createRequest("&cursor=!!!"))
+		AssertClientError(t, response, client_errors.InvalidCursor)
+	})
+	helpers.BaseTestServiceErrorHandling(t, func(err error, rr *httptest.ResponseRecorder) {
+		searchProfiles := func(string, core_values.UserId, pagination.Page) ([]profile_entities.ContextedProfile, pagination.Cursor, error) {
+			return nil, pagination.Cursor{}, err
+		}
+		handlers.NewSearchHandler(nil, searchProfiles, nil).ServeHTTP(rr, createRequest("&type=profiles"))
+	})
+}
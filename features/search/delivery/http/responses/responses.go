@@ -0,0 +1,30 @@
+package responses
+
+import (
+	"github.com/k0marov/go-socnet/core/general/pagination"
+
+	comment_responses "github.com/k0marov/go-socnet/features/comments/delivery/http/responses"
+	comment_entities "github.com/k0marov/go-socnet/features/comments/domain/entities"
+	post_responses "github.com/k0marov/go-socnet/features/posts/delivery/http/responses"
+	post_entities "github.com/k0marov/go-socnet/features/posts/domain/entities"
+)
+
+// the found entities are returned in the same shape as the other listings of them
+
+func NewPostsResponse(posts []post_entities.ContextedPost, next pagination.Cursor) post_responses.PostsResponse {
+	return post_responses.PostsResponse{
+		Posts:      post_responses.NewPostResponses(posts),
+		NextCursor: next.Encode(),
+	}
+}
+
+func NewCommentsResponse(comments []comment_entities.ContextedComment, next pagination.Cursor) comment_responses.CommentsResponse {
+	commentsResp := make([]comment_responses.CommentResponse, 0)
+	for _, comment := range comments {
+		commentsResp = append(commentsResp, comment_responses.NewCommentResponse(comment))
+	}
+	return comment_responses.CommentsResponse{
+		Comments:   commentsResp,
+		NextCursor: next.Encode(),
+	}
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"github.com/k0marov/go-socnet/core/general/client_errors"
+	"github.com/k0marov/go-socnet/core/general/core_err"
+	"github.com/k0marov/go-socnet/core/general/core_values"
+	"github.com/k0marov/go-socnet/core/general/pagination"
+	"github.com/k0marov/go-socnet/features/search/domain/store"
+	"github.com/k0marov/go-socnet/features/search/domain/values"
+
+	comment_contexters "github.com/k0marov/go-socnet/features/comments/domain/contexters"
+	comment_entities "github.com/k0marov/go-socnet/features/comments/domain/entities"
+	comment_store "github.com/k0marov/go-socnet/features/comments/domain/store"
+	post_contexters "github.com/k0marov/go-socnet/features/posts/domain/contexters"
+	post_entities "github.com/k0marov/go-socnet/features/posts/domain/entities"
+	post_store "github.com/k0marov/go-socnet/features/posts/domain/store"
+	profile_entities "github.com/k0marov/go-socnet/features/profiles/domain/entities"
+	profile_service "github.com/k0marov/go-socnet/features/profiles/domain/service"
+)
+
+type (
+	PostsSearcher    func(query string, caller core_values.UserId, page pagination.Page) ([]post_entities.ContextedPost, pagination.Cursor, error)
+	ProfilesSearcher func(query string, caller core_values.UserId, page pagination.Page) ([]profile_entities.ContextedProfile, pagination.Cursor, error)
+	CommentsSearcher func(query string, caller core_values.UserId, page pagination.Page) ([]comment_entities.ContextedComment, pagination.Cursor, error)
+)
+
+// searchIds returns the ids of a page of hits and the cursor of the next page.
+// The cursor is computed from the hits, since the entities of some hits may be deleted by the time they are fetched.
+func searchIds(search store.Searcher, target values.Target, query string, page pagination.Page) ([]string, pagination.Cursor, error) {
+	terms := values.Terms(query)
+	if len(terms) == 0 || len(terms) > values.MaxTerms {
+		return []string{}, pagination.Cursor{}, client_errors.InvalidSearchQuery
+	}
+	hits, err := search(target, terms, page)
+	if err != nil {
+		return []string{}, pagination.Cursor{}, core_err.Rethrow("searching", err)
+	}
+	ids := make([]string, len(hits))
+	for i, hit := range hits {
+		ids[i] = hit.Id
+	}
+	return ids, pagination.NextCursor(hits, page, values.Hit.Cursor), nil
+}
+
+func NewPostsSearcher(search store.Searcher, getPosts post_store.PostsByIdsGetter, addContext post_contexters.PostListContextAdder) PostsSearcher {
+	return func(query string, caller core_values.UserId, page pagination.Page) ([]post_entities.ContextedPost, pagination.Cursor, error) {
+		ids, next, err := searchIds(search, values.Posts, query, page)
+		if err != nil {
+			return []post_entities.ContextedPost{}, pagination.Cursor{}, err
+		}
+		posts, err := getPosts(ids)
+		if err != nil {
+			return []post_entities.ContextedPost{}, pagination.Cursor{}, core_err.Rethrow("getting found posts", err)
+		}
+		ctxPosts, err := addContext(posts, caller)
+		if err != nil {
+			return []post_entities.ContextedPost{}, pagination.Cursor{}, core_err.Rethrow("adding context to found posts", err)
+		}
+		return ctxPosts, next, nil
+	}
+}
+
+func NewProfilesSearcher(search store.Searcher, getProfiles profile_service.ProfilesGetter) ProfilesSearcher {
+	return func(query string, caller core_values.UserId, page pagination.Page) ([]profile_entities.ContextedProfile, pagination.Cursor, error) {
+		ids, next, err := searchIds(search, values.Profiles, query, page)
+		if err != nil {
+			return []profile_entities.ContextedProfile{}, pagination.Cursor{}, err
+		}
+		profilesById, err := getProfiles(ids, caller)
+		if err != nil {
+			return []profile_entities.ContextedProfile{}, pagination.Cursor{}, core_err.Rethrow("getting found profiles", err)
+		}
+		profiles := []profile_entities.ContextedProfile{}
+		for _, id := range ids {
+			if profile, ok := profilesById[id]; ok {
+				profiles = append(profiles, profile)
+			}
+		}
+		return profiles, next, nil
+	}
+}
+
+func NewCommentsSearcher(search store.Searcher, getComments comment_store.CommentsByIdsGetter, addContext comment_contexters.CommentListContextAdder) CommentsSearcher {
+	return func(query string, caller core_values.UserId, page pagination.Page) ([]comment_entities.ContextedComment, pagination.Cursor, error) {
+		ids, next, err := searchIds(search, values.Comments, query, page)
+		if err != nil {
+			return []comment_entities.ContextedComment{}, pagination.Cursor{}, err
+		}
+		comments, err := getComments(ids)
+		if err != nil {
+			return []comment_entities.ContextedComment{}, pagination.Cursor{}, core_err.Rethrow("getting found comments", err)
+		}
+		ctxComments, err := addContext(comments, caller)
+		if err != nil {
+			return []comment_entities.ContextedComment{}, pagination.Cursor{}, core_err.Rethrow("adding context to found comments", err)
+		}
+		return ctxComments, next, nil
+	}
+}
@@ -0,0 +1,183 @@
+package service_test
+
+import (
+	"github.com/k0marov/go-socnet/core/general/client_errors"
+	"github.com/k0marov/go-socnet/core/general/core_values"
+	"github.com/k0marov/go-socnet/core/general/pagination"
+	. "github.com/k0marov/go-socnet/core/helpers/test_helpers"
+	"github.com/k0marov/go-socnet/features/search/domain/service"
+	"github.com/k0marov/go-socnet/features/search/domain/values"
+	"reflect"
+	"strings"
+	"testing"
+
+	comment_entities "github.com/k0marov/go-socnet/features/comments/domain/entities"
+	post_entities "github.com/k0marov/go-socnet/features/posts/domain/entities"
+	profile_entities "github.com/k0marov/go-socnet/features/profiles/domain/entities"
+)
+
+// newSearcher returns a fake search engine which expects to be called with the target and the terms of "Some Query"
+func newSearcher(wantTarget values.Target, page pagination.Page, hits []values.Hit) func(values.Target, []string, pagination.Page) ([]values.Hit, error) {
+	return func(target values.Target, terms []string, gotPage pagination.Page) ([]values.Hit, error) {
+		if target == wantTarget && reflect.DeepEqual(terms, []string{"some", "query"}) && gotPage == page {
+			return hits, nil
+		}
+		panic("unexpected args")
+	}
+}
+
+func TestPostsSearcher(t *testing.T) {
+	caller := RandomId()
+	query := "Some Query"
+	page := pagination.Page{Limit: 2}
+	hits := []values.Hit{{Id: "3", Rank: 5}, {Id: "1", Rank: 2}}
+	posts := []post_entities.Post{RandomPost()}
+	ctxPosts := []post_entities.ContextedPost{RandomContextedPost()}
+
+	search := newSearcher(values.Posts, page, hits)
+	t.Run("error case - query has no words", func(t *testing.T) {
+		_, _, err := service.NewPostsSearcher(nil, nil, nil)(" ?! ", caller, page)
+		AssertError(t, err, client_errors.InvalidSearchQuery)
+	})
+	t.Run("error case - query has too many words", func(t *testing.T) {
+		tooLong := strings.Repeat("word ", values.MaxTerms+1)
+		_, _, err := service.NewPostsSearcher(nil, nil, nil)(tooLong, caller, page)
+		AssertError(t, err, client_errors.InvalidSearchQuery)
+	})
+	t.Run("error case - searching throws", func(t *testing.T) {
+		search := func(values.Target, []string, pagination.Page) ([]values.Hit, error) {
+			return nil, RandomError()
+		}
+		_, _, err := service.NewPostsSearcher(search, nil, nil)(query, caller, page)
+		AssertSomeError(t, err)
+	})
+	getPosts := func(ids []string) ([]post_entities.Post, error) {
+		if reflect.DeepEqual(ids, []string{"3", "1"}) {
+			return posts, nil
+		}
+		panic("unexpected args")
+	}
+	t.Run("error case - getting posts throws", func(t *testing.T) {
+		getPosts := func([]string) ([]post_entities.Post, error) {
+			return nil, RandomError()
+		}
+		_, _, err := service.NewPostsSearcher(search, getPosts, nil)(query, caller, page)
+		AssertSomeError(t, err)
+	})
+	addContext := func(gotPosts []post_entities.Post, callerId core_values.UserId) ([]post_entities.ContextedPost, error) {
+		if reflect.DeepEqual(gotPosts, posts) && callerId == caller {
+			return ctxPosts, nil
+		}
+		panic("unexpected args")
+	}
+	t.Run("error case - adding context throws", func(t *testing.T) {
+		addContext := func([]post_entities.Post, core_values.UserId) ([]post_entities.ContextedPost, error) {
+			return nil, RandomError()
+		}
+		_, _, err := service.NewPostsSearcher(search, getPosts, addContext)(query, caller, page)
+		AssertSomeError(t, err)
+	})
+	t.Run("happy case", func(t *testing.T) {
+		// the next cursor points at the last hit, even though some of the found posts could have been deleted
+		gotPosts, next, err := service.NewPostsSearcher(search, getPosts, addContext)(query, caller, page)
+		AssertNoError(t, err)
+		Assert(t, gotPosts, ctxPosts, "returned posts")
+		Assert(t, next, pagination.Cursor{CreatedAt: 2, Id: "1"}, "next cursor")
+	})
+	t.Run("happy case - last page", func(t *testing.T) {
+		search := newSearcher(values.Posts, page, hits[:1])
+		getPosts := func([]string) ([]post_entities.Post, error) {
+			return posts, nil
+		}
+		_, next, err := service.NewPostsSearcher(search, getPosts, addContext)(query, caller, page)
+		AssertNoError(t, err)
+		Assert(t, next, pagination.Cursor{}, "next cursor")
+	})
+}
+
+func TestProfilesSearcher(t *testing.T) {
+	caller := RandomId()
+	query := "Some Query"
+	page := pagination.Page{Limit: 3}
+	hits := []values.Hit{{Id: "3", Rank: 5}, {Id: "42", Rank: 4}, {Id: "1", Rank: 2}}
+	profile3, profile1 := RandomContextedProfile(), RandomContextedProfile()
+
+	search := newSearcher(values.Profiles, page, hits)
+	t.Run("error case - searching throws", func(t *testing.T) {
+		search := func(values.Target, []string, pagination.Page) ([]values.Hit, error) {
+			return nil, RandomError()
+		}
+		_, _, err := service.NewProfilesSearcher(search, nil)(query, caller, page)
+		AssertSomeError(t, err)
+	})
+	getProfiles := func(ids []core_values.UserId, callerId core_values.UserId) (map[core_values.UserId]profile_entities.ContextedProfile, error) {
+		if reflect.DeepEqual(ids, []string{"3", "42", "1"}) && callerId == caller {
+			return map[core_values.UserId]profile_entities.ContextedProfile{"1": profile1, "3": profile3}, nil
+		}
+		panic("unexpected args")
+	}
+	t.Run("error case - getting profiles throws", func(t *testing.T) {
+		getProfiles := func([]core_values.UserId, core_values.UserId) (map[core_values.UserId]profile_entities.ContextedProfile, error) {
+			return nil, RandomError()
+		}
+		_, _, err := service.NewProfilesSearcher(search, getProfiles)(query, caller, page)
+		AssertSomeError(t, err)
+	})
+	t.Run("happy case", func(t *testing.T) {
+		// the profiles are returned in the order of hits, skipping the ones that don't exist anymore
+		gotProfiles, next, err := service.NewProfilesSearcher(search, getProfiles)(query, caller, page)
+		AssertNoError(t, err)
+		Assert(t, gotProfiles, []profile_entities.ContextedProfile{profile3, profile1}, "returned profiles")
+		Assert(t, next, pagination.Cursor{CreatedAt: 2, Id: "1"}, "next cursor")
+	})
+}
+
+func TestCommentsSearcher(t *testing.T) {
+	caller := RandomId()
+	query := "Some Query"
+	page := pagination.Page{Limit: 10}
+	hits := []values.Hit{{Id: "3", Rank: 5}}
+	comments := []comment_entities.Comment{RandomComment()}
+	ctxComments := []comment_entities.ContextedComment{RandomContextedComment()}
+
+	search := newSearcher(values.Comments, page, hits)
+	t.Run("error case - searching throws", func(t *testing.T) {
+		search := func(values.Target, []string, pagination.Page) ([]values.Hit, error) {
+			return nil, RandomError()
+		}
+		_, _, err := service.NewCommentsSearcher(search, nil, nil)(query, caller, page)
+		AssertSomeError(t, err)
+	})
+	getComments := func(ids []string) ([]comment_entities.Comment, error) {
+		if reflect.DeepEqual(ids, []string{"3"}) {
+			return comments, nil
+		}
+		panic("unexpected args")
+	}
+	t.Run("error case - getting comments throws", func(t *testing.T) {
+		getComments := func([]string) ([]comment_entities.Comment, error) {
+			return nil, RandomError()
+		}
+		_, _, err := service.NewCommentsSearcher(search, getComments, nil)(query, caller, page)
+		AssertSomeError(t, err)
+	})
+	addContext := func(gotComments []comment_entities.Comment, callerId core_values.UserId) ([]comment_entities.ContextedComment, error) {
+		if reflect.DeepEqual(gotComments, comments) && callerId == caller {
+			return ctxComments, nil
+		}
+		panic("unexpected args")
+	}
+	t.Run("error case - adding context throws", func(t *testing.T) {
+		addContext := func([]comment_entities.Comment, core_values.UserId) ([]comment_entities.ContextedComment, error) {
+			return nil, RandomError()
+		}
+		_, _, err := service.NewCommentsSearcher(search, getComments, addContext)(query, caller, page)
+		AssertSomeError(t, err)
+	})
+	t.Run("happy case", func(t *testing.T) {
+		gotComments, next, err := service.NewCommentsSearcher(search, getComments, addContext)(query, caller, page)
+		AssertNoError(t, err)
+		Assert(t, gotComments, ctxComments, "returned comments")
+		Assert(t, next, pagination.Cursor{}, "next cursor")
+	})
+}
@@ -0,0 +1,16 @@
+package store
+
+import (
+	"github.com/k0marov/go-socnet/core/general/pagination"
+	"github.com/k0marov/go-socnet/features/search/domain/values"
+)
+
+// Engine is a full-text search engine over the posts, profiles and comments.
+// It is an interface so that the engine (and the way the texts are indexed) can be swapped without touching the rest of the feature.
+type Engine interface {
+	// Search returns the hits matching every term, the most relevant ones first.
+	// A term also matches the words it is a prefix of.
+	Search(target values.Target, terms []string, page pagination.Page) ([]values.Hit, error)
+}
+
+type Searcher func(target values.Target, terms []string, page pagination.Page) ([]values.Hit, error)
@@ -0,0 +1,42 @@
+package values
+
+import (
+	"github.com/k0marov/go-socnet/core/general/pagination"
+	"strings"
+	"unicode"
+)
+
+// Target is the kind of entities being searched
+type Target string
+
+const (
+	Posts    Target = "posts"
+	Profiles Target = "profiles"
+	Comments Target = "comments"
+)
+
+func (t Target) IsValid() bool {
+	return t == Posts || t == Profiles || t == Comments
+}
+
+// Hit is an entity matching a search query.
+// Rank is an integer which is greater for more relevant hits, so that it can be used in place of the createdAt of a pagination.Cursor.
+type Hit struct {
+	Id   string `db:"id"`
+	Rank int64  `db:"rank"`
+}
+
+func (h Hit) Cursor() pagination.Cursor {
+	return pagination.Cursor{CreatedAt: h.Rank, Id: h.Id}
+}
+
+// MaxTerms limits the number of words in a search query, since every one of them makes the query slower
+const MaxTerms = 10
+
+// Terms splits a search query into lowercase words of letters and digits.
+// Everything else is dropped, so the terms never contain the operators of a search engine's query syntax.
+func Terms(query string) []string {
+	return strings.FieldsFunc(strings.ToLower(query), func(char rune) bool {
+		return !unicode.IsLetter(char) && !unicode.IsDigit(char)
+	})
+}
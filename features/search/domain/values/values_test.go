@@ -0,0 +1,31 @@
+package values_test
+
+import (
+	. "github.com/k0marov/go-socnet/core/helpers/test_helpers"
+	"github.com/k0marov/go-socnet/features/search/domain/values"
+	"testing"
+)
+
+func TestTerms(t *testing.T) {
+	cases := []struct {
+		query string
+		want  []string
+	}{
+		{"", []string{}},
+		{"  ,.!  ", []string{}},
+		{"golang", []string{"golang"}},
+		{"Learning GoLang", []string{"learning", "golang"}},
+		{`"go*" OR -rust NEAR/2 c++`, []string{"go", "or", "rust", "near", "2", "c"}},
+		{"hello_world #go @john", []string{"hello", "world", "go", "john"}},
+		{"Привет, мир", []string{"привет", "мир"}},
+	}
+	for _, c := range cases {
+		t.Run(c.query, func(t *testing.T) {
+			got := values.Terms(c.query)
+			Assert(t, len(got), len(c.want), "number of terms")
+			for i := range c.want {
+				Assert(t, got[i], c.want[i], "term")
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package integration_test
+
+import (
+	"encoding/json"
+	"github.com/k0marov/go-socnet/core/general/client_errors"
+	"github.com/k0marov/go-socnet/core/general/core_values"
+	"github.com/k0marov/go-socnet/core/general/pagination"
+	helpers "github.com/k0marov/go-socnet/core/helpers/http_test_helpers"
+	. "github.com/k0marov/go-socnet/core/helpers/test_helpers"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/k0marov/go-socnet/features/comments"
+	comment_responses "github.com/k0marov/go-socnet/features/comments/delivery/http/responses"
+	comment_values "github.com/k0marov/go-socnet/features/comments/domain/values"
+	comments_db "github.com/k0marov/go-socnet/features/comments/store/sql_db"
+	"github.com/k0marov/go-socnet/features/posts"
+	post_responses "github.com/k0marov/go-socnet/features/posts/delivery/http/responses"
+	post_models "github.com/k0marov/go-socnet/features/posts/domain/models"
+	posts_db "github.com/k0marov/go-socnet/features/posts/store/sql_db"
+	"github.com/k0marov/go-socnet/features/profiles"
+	profile_responses "github.com/k0marov/go-socnet/features/profiles/delivery/http/responses"
+	"github.com/k0marov/go-socnet/features/search"
+	auth "github.com/k0marov/golang-auth"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestSearch(t *testing.T) {
+	// db
+	cfg := TestConfig(t)
+	sql := OpenTestDB(t)
+	// profiles
+	fakeRegisterProfile := profiles.NewRegisterCallback(sql)
+	getProfile := profiles.NewProfileGetterImpl(cfg, sql)
+	getProfiles := profiles.NewProfilesGetterImpl(cfg, sql)
+	// posts
+	postsDB, _ := posts_db.NewSqlDB(sql)
+	createPost := func(t testing.TB, author core_values.UserId, text string) string {
+		t.Helper()
+		id, err := postsDB.CreatePost(sql, post_models.PostToCreate{Author: author, Text: text, CreatedAt: time.Now()})
+		AssertNoError(t, err)
+		return id
+	}
+	getCommentCounts := comments.NewCommentCountsGetterImpl(sql)
+	postListing := posts.NewPostListingImpl(cfg, sql, getProfiles, getCommentCounts, comments.NewCommentPreviewsGetterImpl(sql, getProfiles))
+	// comments
+	commentsDB, _ := comments_db.NewSqlDB(sql)
+	createComment := func(t testing.TB, post string, author core_values.UserId, text string) string {
+		t.Helper()
+		id, err := commentsDB.Create(sql, comment_values.NewCommentValue{Author: author, Post: post, Text: text}, time.Now())
+		AssertNoError(t, err)
+		return id
+	}
+	// search
+	r := chi.NewRouter()
+	r.Route("/search", search.NewSearchRouterImpl(sql, postListing, getProfiles, comments.NewCommentListingImpl(sql, getProfile)))
+
+	doSearch := func(t testing.TB, caller auth.User, params url.Values) *httptest.ResponseRecorder {
+		t.Helper()
+		request := helpers.AddAuthDataToRequest(httptest.NewRequest(http.MethodGet, "/search/?"+params.Encode(), nil), caller)
+		response := httptest.NewRecorder()
+		r.ServeHTTP(response, request)
+		return response
+	}
+
+	author := auth.User{Id: RandomId(), Username: "gopher"}
+	reader := auth.User{Id: RandomId(), Username: "reader"}
+	fakeRegisterProfile(author)
+	fakeRegisterProfile(reader)
+	firstPost := createPost(t, author.Id, "My first post about Golang")
+	secondPost := createPost(t, author.Id, "Golang generics, golang channels")
+	createPost(t, author.Id, "Something else entirely")
+	comment := createComment(t, firstPost, reader.Id, "Golang is great!")
+
+	t.Run("searching posts", func(t *testing.T) {
+		response := doSearch(t, reader, url.Values{"q": {"golang"}, "limit": {"1"}})
+		AssertStatusCode(t, response, http.StatusOK)
+		var firstPage post_responses.PostsResponse
+		json.NewDecoder(response.Body).Decode(&firstPage)
+		AssertFatal(t, len(firstPage.Posts), 1, "number of posts on the first page")
+		Assert(t, firstPage.Posts[0].Id, secondPost, "the most relevant post")
+		Assert(t, firstPage.Posts[0].Author.Username, author.Username, "author of the found post")
+		Assert(t, firstPage.Posts[0].IsMine, false, "found post is mine")
+
+		response = doSearch(t, reader, url.Values{"q": {"golang"}, "limit": {"1"}, "cursor": {firstPage.NextCursor}})
+		AssertStatusCode(t, response, http.StatusOK)
+		var secondPage post_responses.PostsResponse
+		json.NewDecoder(response.Body).Decode(&secondPage)
+		AssertFatal(t, len(secondPage.Posts), 1, "number of posts on the second page")
+		Assert(t, secondPage.Posts[0].Id, firstPost, "the less relevant post")
+		Assert(t, secondPage.Posts[0].CommentsCount, 1, "comments count of the found post")
+	})
+	t.Run("searching profiles", func(t *testing.T) {
+		response := doSearch(t, reader, url.Values{"q": {"goph"}, "type": {"profiles"}})
+		AssertStatusCode(t, response, http.StatusOK)
+		var found profile_responses.ProfilesResponse
+		json.NewDecoder(response.Body).Decode(&found)
+		AssertFatal(t, len(found.Profiles), 1, "number of found profiles")
+		Assert(t, found.Profiles[0].Id, author.Id, "found profile")
+		Assert(t, found.NextCursor, pagination.Cursor{}.Encode(), "next cursor")
+	})
+	t.Run("searching comments", func(t *testing.T) {
+		response := doSearch(t, reader, url.Values{"q": {"GREAT golang"}, "type": {"comments"}})
+		AssertStatusCode(t, response, http.StatusOK)
+		var found comment_responses.CommentsResponse
+		json.NewDecoder(response.Body).Decode(&found)
+		AssertFatal(t, len(found.Comments), 1, "number of found comments")
+		Assert(t, found.Comments[0].Id, comment, "found comment")
+		Assert(t, found.Comments[0].IsMine, true, "found comment is mine")
+	})
+	t.Run("error cases", func(t *testing.T) {
+		response := doSearch(t, reader, url.Values{"q": {" !? "}})
+		AssertClientError(t, response, client_errors.InvalidSearchQuery)
+		response = doSearch(t, reader, url.Values{"q": {"golang"}, "type": {"tags"}})
+		AssertClientError(t, response, client_errors.InvalidSearchType)
+	})
+}
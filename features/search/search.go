@@ -0,0 +1,30 @@
+package search
+
+import (
+	"github.com/go-chi/chi/v5"
+	"github.com/jmoiron/sqlx"
+	"github.com/k0marov/go-socnet/features/comments"
+	"github.com/k0marov/go-socnet/features/posts"
+	profile_service "github.com/k0marov/go-socnet/features/profiles/domain/service"
+	"github.com/k0marov/go-socnet/features/search/delivery/http/handlers"
+	"github.com/k0marov/go-socnet/features/search/delivery/http/router"
+	"github.com/k0marov/go-socnet/features/search/domain/service"
+	"github.com/k0marov/go-socnet/features/search/store/fts"
+	"log"
+)
+
+// NewSearchRouterImpl searches with the full-text search engine of the db and gets the found entities from the other features
+func NewSearchRouterImpl(db *sqlx.DB, postListing posts.PostListing, getProfiles profile_service.ProfilesGetter, commentListing comments.CommentListing) func(chi.Router) {
+	engine, err := fts.NewEngine(db)
+	if err != nil {
+		log.Fatalf("error while creating the search engine: %v", err)
+	}
+	// service
+	searchPosts := service.NewPostsSearcher(engine.Search, postListing.GetByIds, postListing.AddContext)
+	searchProfiles := service.NewProfilesSearcher(engine.Search, getProfiles)
+	searchComments := service.NewCommentsSearcher(engine.Search, commentListing.GetByIds, commentListing.AddContext)
+	// handlers
+	searchHandler := handlers.NewSearchHandler(searchPosts, searchProfiles, searchComments)
+
+	return router.NewSearchRouter(searchHandler)
+}
@@ -0,0 +1,51 @@
+package fts
+
+import (
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"github.com/k0marov/go-socnet/core/general/core_err"
+	"github.com/k0marov/go-socnet/core/general/database"
+	"github.com/k0marov/go-socnet/core/general/pagination"
+	"github.com/k0marov/go-socnet/features/search/domain/store"
+	"github.com/k0marov/go-socnet/features/search/domain/values"
+)
+
+// NewEngine returns the full-text search engine built into the database,
+// which uses the indexes created by the "search" migration in core/general/migrations.
+// On SQLite without FTS5 that migration is skipped, so it returns an engine which scans the tables with LIKE.
+func NewEngine(db *sqlx.DB) (store.Engine, error) {
+	switch db.DriverName() {
+	case database.SQLite:
+		indexed, err := sqliteIndexed(db)
+		if err != nil {
+			return nil, core_err.Rethrow("checking for the FTS5 search tables", err)
+		}
+		if !indexed {
+			return &SQLiteLikeEngine{sql: db}, nil
+		}
+		return &SQLiteEngine{sql: db}, nil
+	case database.Postgres:
+		return &PostgresEngine{sql: db}, nil
+	}
+	return nil, fmt.Errorf("%w: %v", database.ErrUnsupportedDriver, db.DriverName())
+}
+
+// rankScale turns the floating point ranks of the engines into integers, keeping enough precision to order the hits
+const rankScale = 1000000
+
+// selectHits pages through the rows of a subquery which returns the id and rank of every hit
+func selectHits(db *sqlx.DB, hitsQuery string, args []any, page pagination.Page) ([]values.Hit, error) {
+	cond, condArgs := page.Condition("rank", "id")
+	args = append(append(args, condArgs...), page.Limit)
+	hits := []values.Hit{}
+	err := db.Select(&hits, db.Rebind(`
+		SELECT id, rank FROM (`+hitsQuery+`) AS Hit
+		WHERE `+cond+`
+		ORDER BY rank DESC, id DESC
+		LIMIT ?
+	`), args...)
+	if err != nil {
+		return []values.Hit{}, core_err.Rethrow("SELECTing search hits", err)
+	}
+	return hits, nil
+}
@@ -0,0 +1,133 @@
+package fts_test
+
+import (
+	"github.com/k0marov/go-socnet/core/general/pagination"
+	. "github.com/k0marov/go-socnet/core/helpers/test_helpers"
+	"testing"
+	"time"
+
+	comment_values "github.com/k0marov/go-socnet/features/comments/domain/values"
+	comments_db "github.com/k0marov/go-socnet/features/comments/store/sql_db"
+	post_models "github.com/k0marov/go-socnet/features/posts/domain/models"
+	posts_db "github.com/k0marov/go-socnet/features/posts/store/sql_db"
+	profile_models "github.com/k0marov/go-socnet/features/profiles/domain/models"
+	profile_store "github.com/k0marov/go-socnet/features/profiles/store"
+	profiles_db "github.com/k0marov/go-socnet/features/profiles/store/sql_db"
+	"github.com/k0marov/go-socnet/features/search/domain/values"
+	"github.com/k0marov/go-socnet/features/search/store/fts"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestEngine_ErrorHandling(t *testing.T) {
+	db := OpenTestDB(t)
+	engine, err := fts.NewEngine(db)
+	AssertNoError(t, err)
+	db.Close() // this will make all calls to db throw
+	for _, target := range []values.Target{values.Posts, values.Profiles, values.Comments} {
+		_, err := engine.Search(target, []string{"word"}, pagination.Page{Limit: 10})
+		AssertSomeError(t, err)
+	}
+}
+
+func TestEngine(t *testing.T) {
+	db := OpenTestDB(t)
+	engine, err := fts.NewEngine(db)
+	AssertNoError(t, err)
+	profilesDB, err := profiles_db.NewSqlDB(db)
+	AssertNoError(t, err)
+	postsDB, err := posts_db.NewSqlDB(db)
+	AssertNoError(t, err)
+	commentsDB, err := comments_db.NewSqlDB(db)
+	AssertNoError(t, err)
+
+	createProfile := func(t testing.TB, username, about string) string {
+		t.Helper()
+		profile := profile_models.ProfileModel{Id: RandomId(), Username: username, About: about}
+		AssertNoError(t, profilesDB.CreateProfile(profile))
+		return profile.Id
+	}
+	createPost := func(t testing.TB, author, text string) string {
+		t.Helper()
+		id, err := postsDB.CreatePost(db, post_models.PostToCreate{Author: author, Text: text, CreatedAt: time.Now()})
+		AssertNoError(t, err)
+		return id
+	}
+	createComment := func(t testing.TB, post, parent, text string) string {
+		t.Helper()
+		newComment := comment_values.NewCommentValue{Author: RandomId(), Post: post, Parent: parent, Text: text}
+		profile := profile_models.ProfileModel{Id: newComment.Author, Username: RandomString()}
+		AssertNoError(t, profilesDB.CreateProfile(profile))
+		id, err := commentsDB.Create(db, newComment, time.Now())
+		AssertNoError(t, err)
+		return id
+	}
+	searchIds := func(t testing.TB, target values.Target, terms []string, page pagination.Page) []string {
+		t.Helper()
+		hits, err := engine.Search(target, terms, page)
+		AssertNoError(t, err)
+		ids := []string{}
+		for _, hit := range hits {
+			ids = append(ids, hit.Id)
+		}
+		return ids
+	}
+	firstPage := pagination.Page{Limit: 10}
+
+	t.Run("searching posts", func(t *testing.T) {
+		author := createProfile(t, "poster", "")
+		once := createPost(t, author, "Learning Golang today")
+		twice := createPost(t, author, "golang, golang and generics")
+		createPost(t, author, "Rust is fine too")
+
+		// the posts mentioning the term more times are ranked higher
+		Assert(t, searchIds(t, values.Posts, []string{"golang"}, firstPage), []string{twice, once}, "found posts")
+		// the terms match the words they are prefixes of
+		Assert(t, searchIds(t, values.Posts, []string{"gola"}, firstPage), []string{twice, once}, "found posts")
+		// all of the terms should be matched
+		Assert(t, searchIds(t, values.Posts, []string{"golang", "generics"}, firstPage), []string{twice}, "found posts")
+		Assert(t, searchIds(t, values.Posts, []string{"python"}, firstPage), []string{}, "found posts")
+
+		// paginating
+		hits, err := engine.Search(values.Posts, []string{"golang"}, pagination.Page{Limit: 1})
+		AssertNoError(t, err)
+		Assert(t, len(hits), 1, "number of hits on the first page")
+		Assert(t, hits[0].Id, twice, "hit on the first page")
+		nextPage := pagination.Page{After: hits[0].Cursor(), Limit: 1}
+		Assert(t, searchIds(t, values.Posts, []string{"golang"}, nextPage), []string{once}, "hits on the second page")
+
+		// the index is kept in sync with edits
+		err = postsDB.UpdatePost(db, once, post_models.PostToUpdate{Text: "Learning Rust today", EditedAt: time.Now()})
+		AssertNoError(t, err)
+		Assert(t, searchIds(t, values.Posts, []string{"golang"}, firstPage), []string{twice}, "found posts after an edit")
+		Assert(t, searchIds(t, values.Posts, []string{"learning", "rust"}, firstPage), []string{once}, "found posts after an edit")
+	})
+	t.Run("searching profiles", func(t *testing.T) {
+		gopher := createProfile(t, "gopher42", "I write services")
+		writer := createProfile(t, "writer", "I write books")
+
+		Assert(t, searchIds(t, values.Profiles, []string{"gopher"}, firstPage), []string{gopher}, "profiles found by username")
+		found := searchIds(t, values.Profiles, []string{"write"}, firstPage)
+		Assert(t, len(found), 2, "number of profiles found by about")
+
+		err := profilesDB.UpdateProfile(writer, profile_store.DBUpdateData{About: "I write poems"})
+		AssertNoError(t, err)
+		Assert(t, searchIds(t, values.Profiles, []string{"books"}, firstPage), []string{}, "profiles found by the old about")
+		Assert(t, searchIds(t, values.Profiles, []string{"poems"}, firstPage), []string{writer}, "profiles found by the new about")
+	})
+	t.Run("searching comments", func(t *testing.T) {
+		post := createPost(t, createProfile(t, "commented", ""), "some post")
+		parent := createComment(t, post, "", "Nice picture of the mountains")
+		reply := createComment(t, post, parent, "these mountains are in Norway")
+		Assert(t, searchIds(t, values.Comments, []string{"mountains"}, firstPage), []string{reply, parent}, "found comments")
+
+		// a deleted comment becomes a tombstone without text, since it has replies
+		err := commentsDB.Delete(db, parent, time.Now())
+		AssertNoError(t, err)
+		Assert(t, searchIds(t, values.Comments, []string{"mountains"}, firstPage), []string{reply}, "found comments after a deletion")
+
+		// deleting the post also deletes its comments
+		_, err = db.Exec(db.Rebind("DELETE FROM Post WHERE id = ?"), post)
+		AssertNoError(t, err)
+		Assert(t, searchIds(t, values.Comments, []string{"mountains"}, firstPage), []string{}, "found comments after deleting the post")
+	})
+}
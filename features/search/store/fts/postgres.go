@@ -0,0 +1,44 @@
+package fts
+
+import (
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"github.com/k0marov/go-socnet/core/general/pagination"
+	"github.com/k0marov/go-socnet/features/search/domain/values"
+	"strings"
+)
+
+// PostgresEngine searches the tsvectors of the searched columns, which are indexed by GIN indexes
+type PostgresEngine struct {
+	sql *sqlx.DB
+}
+
+// postgresDocuments are the tables and the searched documents of every target.
+// The documents should be the same as the expressions of the indexes, otherwise the indexes aren't used.
+var postgresDocuments = map[values.Target]struct{ table, document string }{
+	values.Posts:    {"Post", "to_tsvector('simple', textContent)"},
+	values.Profiles: {"Profile", "to_tsvector('simple', username || ' ' || about)"},
+	values.Comments: {"Comment", "to_tsvector('simple', textContent)"},
+}
+
+func (e *PostgresEngine) Search(target values.Target, terms []string, page pagination.Page) ([]values.Hit, error) {
+	doc, ok := postgresDocuments[target]
+	if !ok {
+		return []values.Hit{}, fmt.Errorf("unknown search target: %v", target)
+	}
+	hitsQuery := fmt.Sprintf(`
+		SELECT id, CAST(ts_rank(%[2]s, query) * %[3]d AS BIGINT) AS rank
+		FROM %[1]s, to_tsquery('simple', ?) AS query
+		WHERE %[2]s @@ query
+	`, doc.table, doc.document, rankScale)
+	return selectHits(e.sql, hitsQuery, []any{postgresTSQuery(terms)}, page)
+}
+
+// postgresTSQuery matches the documents containing all of the terms, each one as a prefix of a word
+func postgresTSQuery(terms []string) string {
+	prefixes := make([]string, len(terms))
+	for i, term := range terms {
+		prefixes[i] = term + ":*"
+	}
+	return strings.Join(prefixes, " & ")
+}
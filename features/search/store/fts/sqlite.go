@@ -0,0 +1,54 @@
+package fts
+
+import (
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"github.com/k0marov/go-socnet/core/general/database"
+	"github.com/k0marov/go-socnet/core/general/pagination"
+	"github.com/k0marov/go-socnet/features/search/domain/values"
+	"strings"
+)
+
+// SQLiteEngine searches the FTS5 tables, which are kept in sync with the searched tables by triggers
+type SQLiteEngine struct {
+	sql *sqlx.DB
+}
+
+var sqliteIndexes = map[values.Target]string{
+	values.Posts:    "PostSearch",
+	values.Profiles: "ProfileSearch",
+	values.Comments: "CommentSearch",
+}
+
+// sqliteIndexed returns whether the FTS5 tables exist and can be queried
+func sqliteIndexed(db *sqlx.DB) (bool, error) {
+	if !database.SQLiteFTS5 {
+		return false, nil
+	}
+	var exists bool
+	err := db.Get(&exists, `SELECT EXISTS(SELECT 1 FROM sqlite_master WHERE type = 'table' AND name = ?)`, sqliteIndexes[values.Posts])
+	return exists, err
+}
+
+// Search ranks the hits with bm25(), which is lower for more relevant hits, so it is negated
+func (e *SQLiteEngine) Search(target values.Target, terms []string, page pagination.Page) ([]values.Hit, error) {
+	index, ok := sqliteIndexes[target]
+	if !ok {
+		return []values.Hit{}, fmt.Errorf("unknown search target: %v", target)
+	}
+	hitsQuery := fmt.Sprintf(`
+		SELECT rowid AS id, CAST(-bm25(%[1]s) * %[2]d AS INTEGER) AS rank
+		FROM %[1]s
+		WHERE %[1]s MATCH ?
+	`, index, rankScale)
+	return selectHits(e.sql, hitsQuery, []any{sqliteMatchQuery(terms)}, page)
+}
+
+// sqliteMatchQuery matches the rows containing all of the terms, each one as a prefix of a word
+func sqliteMatchQuery(terms []string) string {
+	prefixes := make([]string, len(terms))
+	for i, term := range terms {
+		prefixes[i] = `"` + term + `"*`
+	}
+	return strings.Join(prefixes, " ")
+}
@@ -0,0 +1,46 @@
+package fts
+
+import (
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"github.com/k0marov/go-socnet/core/general/pagination"
+	"github.com/k0marov/go-socnet/features/search/domain/values"
+	"strings"
+)
+
+// SQLiteLikeEngine scans the searched columns with LIKE.
+// It is used when go-sqlite3 is built without FTS5, so there are no FTS5 tables to search.
+type SQLiteLikeEngine struct {
+	sql *sqlx.DB
+}
+
+// sqliteLikeDocuments are the tables and the lower case searched text of every target
+var sqliteLikeDocuments = map[values.Target]struct{ table, document string }{
+	values.Posts:    {"Post", "lower(textContent)"},
+	values.Profiles: {"Profile", "lower(username || ' ' || about)"},
+	values.Comments: {"Comment", "lower(textContent)"},
+}
+
+// Search ranks the hits by the number of occurrences of the terms.
+// The terms have only lower case letters and digits, so they need no escaping in the LIKE patterns.
+func (e *SQLiteLikeEngine) Search(target values.Target, terms []string, page pagination.Page) ([]values.Hit, error) {
+	doc, ok := sqliteLikeDocuments[target]
+	if !ok {
+		return []values.Hit{}, fmt.Errorf("unknown search target: %v", target)
+	}
+	occurrences := make([]string, len(terms))
+	conds := make([]string, len(terms))
+	var occurrencesArgs, condArgs []any
+	for i, term := range terms {
+		occurrences[i] = fmt.Sprintf("(length(%[1]s) - length(replace(%[1]s, ?, ''))) / length(?)", doc.document)
+		occurrencesArgs = append(occurrencesArgs, term, term)
+		conds[i] = doc.document + " LIKE ?"
+		condArgs = append(condArgs, "%"+term+"%")
+	}
+	hitsQuery := fmt.Sprintf(`
+		SELECT id, %[2]s AS rank
+		FROM %[1]s
+		WHERE %[3]s
+	`, doc.table, strings.Join(occurrences, " + "), strings.Join(conds, " AND "))
+	return selectHits(e.sql, hitsQuery, append(occurrencesArgs, condArgs...), page)
+}
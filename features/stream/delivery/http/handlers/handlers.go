@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/k0marov/go-socnet/core/helpers/http_helpers"
+	"github.com/k0marov/go-socnet/features/stream/delivery/http/responses"
+	"github.com/k0marov/go-socnet/features/stream/domain/service"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// NewStreamHandler holds a Server-Sent Events connection, pushing the messages of the stream opened for the caller.
+// The ids of the posts the client is viewing are provided in the comma-separated "posts" query argument.
+// A comment line is sent every heartbeatPeriod, so that the proxies don't close an idle connection.
+// The connection is closed when the client disconnects or falls behind, in which case it should reconnect and refetch.
+func NewStreamHandler(openStream service.StreamOpener, heartbeatPeriod time.Duration) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		caller, ok := http_helpers.GetUserOrAddUnauthorized(w, r)
+		if !ok {
+			return
+		}
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			http_helpers.HandleServiceError(w, errors.New("the response writer doesn't support streaming"))
+			return
+		}
+		var viewedPosts []string
+		if posts := r.URL.Query().Get("posts"); posts != "" {
+			viewedPosts = strings.Split(posts, ",")
+		}
+		stream, err := openStream(caller.Id, viewedPosts)
+		if err != nil {
+			http_helpers.HandleServiceError(w, err)
+			return
+		}
+		defer stream.Close()
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("X-Accel-Buffering", "no")
+		w.WriteHeader(http.StatusOK)
+		flusher.Flush()
+
+		heartbeat := time.NewTicker(heartbeatPeriod)
+		defer heartbeat.Stop()
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case msg, ok := <-stream.Messages():
+				if !ok {
+					return
+				}
+				data, err := json.Marshal(responses.NewMessageResponse(msg))
+				if err != nil {
+					return
+				}
+				_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", msg.Event, data)
+				if err != nil {
+					return
+				}
+			case <-heartbeat.C:
+				_, err := fmt.Fprint(w, ": heartbeat\n\n")
+				if err != nil {
+					return
+				}
+			}
+			flusher.Flush()
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package handlers_test
+
+import (
+	"context"
+	"github.com/k0marov/go-socnet/core/general/core_values"
+	"github.com/k0marov/go-socnet/core/general/events"
+	"github.com/k0marov/go-socnet/core/general/pubsub"
+	helpers "github.com/k0marov/go-socnet/core/helpers/http_test_helpers"
+	. "github.com/k0marov/go-socnet/core/helpers/test_helpers"
+	"github.com/k0marov/go-socnet/features/stream/delivery/http/handlers"
+	"github.com/k0marov/go-socnet/features/stream/domain/values"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStreamHandler(t *testing.T) {
+	caller := RandomAuthUser()
+	createRequest := func(params string) *http.Request {
+		return helpers.AddAuthDataToRequest(httptest.NewRequest(http.MethodGet, "/handler-should-not-care"+params, nil), caller)
+	}
+
+	helpers.BaseTest401(t, handlers.NewStreamHandler(nil, time.Minute))
+	t.Run("happy case - messages are pushed until the stream is disconnected", func(t *testing.T) {
+		hub := pubsub.NewHub(10)
+		openStream := func(callerId core_values.UserId, posts []string) (*pubsub.Subscription, error) {
+			if callerId == caller.Id && reflect.DeepEqual(posts, []string{"1", "2"}) {
+				return hub.Subscribe("topic"), nil
+			}
+			panic("unexpected args")
+		}
+		event := events.Event{Kind: events.CommentCreated, Actor: "42", TargetType: events.Post, TargetId: "1", Comment: "3", CreatedAt: time.Unix(100, 0)}
+		go func() {
+			for !hub.HasSubscribers("topic") {
+				time.Sleep(time.Millisecond)
+			}
+			hub.Publish("topic", pubsub.Message{Event: values.CommentMessage, Data: event})
+			hub.Close()
+		}()
+		response := httptest.NewRecorder()
+		handlers.NewStreamHandler(openStream, time.Minute).ServeHTTP(response, createRequest("?posts=1,2"))
+
+		AssertStatusCode(t, response, http.StatusOK)
+		Assert(t, response.Header().Get("Content-Type"), "text/event-stream", "content type")
+		want := "event: comment\ndata: {\"post_id\":\"1\",\"comment_id\":\"3\",\"author_id\":\"42\",\"created_at\":100}\n\n"
+		Assert(t, response.Body.String(), want, "response body")
+	})
+	t.Run("happy case - heartbeats are sent while there are no messages", func(t *testing.T) {
+		hub := pubsub.NewHub(10)
+		openStream := func(core_values.UserId, []string) (*pubsub.Subscription, error) {
+			return hub.Subscribe("topic"), nil
+		}
+		go func() {
+			time.Sleep(50 * time.Millisecond)
+			hub.Close()
+		}()
+		response := httptest.NewRecorder()
+		handlers.NewStreamHandler(openStream, 10*time.Millisecond).ServeHTTP(response, createRequest(""))
+		Assert(t, strings.HasPrefix(response.Body.String(), ": heartbeat\n\n"), true, "heartbeat is sent")
+	})
+	t.Run("happy case - the stream is closed when the client disconnects", func(t *testing.T) {
+		hub := pubsub.NewHub(10)
+		openStream := func(core_values.UserId, []string) (*pubsub.Subscription, error) {
+			return hub.Subscribe("topic"), nil
+		}
+		ctx, disconnect := context.WithCancel(context.Background())
+		go func() {
+			for !hub.HasSubscribers("topic") {
+				time.Sleep(time.Millisecond)
+			}
+			disconnect()
+		}()
+		request := helpers.AddAuthDataToRequest(httptest.NewRequest(http.MethodGet, "/handler-should-not-care", nil).WithContext(ctx), caller)
+		response := httptest.NewRecorder()
+		handlers.NewStreamHandler(openStream, time.Minute).ServeHTTP(response, request)
+		AssertStatusCode(t, response, http.StatusOK)
+		Assert(t, hub.HasSubscribers("topic"), false, "stream is subscribed")
+	})
+	helpers.BaseTestServiceErrorHandling(t, func(err error, response *httptest.ResponseRecorder) {
+		openStream := func(core_values.UserId, []string) (*pubsub.Subscription, error) {
+			return nil, err
+		}
+		handlers.NewStreamHandler(openStream, time.Minute).ServeHTTP(response, createRequest(""))
+	})
+}
@@ -0,0 +1,72 @@
+package responses
+
+import (
+	likeable_values "github.com/k0marov/go-socnet/core/abstract/likeable/values"
+	"github.com/k0marov/go-socnet/core/general/events"
+	"github.com/k0marov/go-socnet/core/general/pubsub"
+	notification_models "github.com/k0marov/go-socnet/features/notifications/domain/models"
+	"github.com/k0marov/go-socnet/features/stream/domain/values"
+)
+
+type TargetResponse struct {
+	Type events.TargetType `json:"type"`
+	Id   string            `json:"id"`
+}
+
+// NotificationResponse is a new or updated notification, a grouped notification replaces the one with the same id on the client
+type NotificationResponse struct {
+	Id          string         `json:"id"`
+	Kind        events.Kind    `json:"kind"`
+	LastActorId string         `json:"last_actor_id"`
+	Target      TargetResponse `json:"target"`
+	CommentId   string         `json:"comment_id,omitempty"`
+	ActorsCount int            `json:"actors_count"`
+	CreatedAt   int64          `json:"created_at"`
+	UpdatedAt   int64          `json:"updated_at"`
+}
+
+// CommentResponse tells the client that a comment was added to a viewed post, the comment can be fetched by its id
+type CommentResponse struct {
+	PostId    string `json:"post_id"`
+	CommentId string `json:"comment_id"`
+	AuthorId  string `json:"author_id"`
+	CreatedAt int64  `json:"created_at"`
+}
+
+type LikesResponse struct {
+	Target    TargetResponse                 `json:"target"`
+	Likes     int                            `json:"likes"`
+	Reactions likeable_values.ReactionCounts `json:"reactions"`
+}
+
+// NewMessageResponse returns the data of a message which is sent to the client
+func NewMessageResponse(msg pubsub.Message) any {
+	switch data := msg.Data.(type) {
+	case events.Event:
+		return CommentResponse{
+			PostId:    data.TargetId,
+			CommentId: data.Comment,
+			AuthorId:  data.Actor,
+			CreatedAt: data.CreatedAt.Unix(),
+		}
+	case notification_models.NotificationModel:
+		return NotificationResponse{
+			Id:          data.Id,
+			Kind:        data.Kind,
+			LastActorId: data.LastActorId,
+			Target:      TargetResponse{Type: data.TargetType, Id: data.TargetId},
+			CommentId:   data.CommentId,
+			ActorsCount: data.Actors,
+			CreatedAt:   data.CreatedAt,
+			UpdatedAt:   data.UpdatedAt,
+		}
+	case values.LikesChange:
+		return LikesResponse{
+			Target:    TargetResponse{Type: data.TargetType, Id: data.TargetId},
+			Likes:     data.Reactions.Total(),
+			Reactions: data.Reactions,
+		}
+	default:
+		return data
+	}
+}
@@ -0,0 +1,12 @@
+package router
+
+import (
+	"github.com/go-chi/chi/v5"
+	"net/http"
+)
+
+func NewStreamRouter(stream http.HandlerFunc) func(chi.Router) {
+	return func(r chi.Router) {
+		r.Get("/", stream)
+	}
+}
@@ -0,0 +1,68 @@
+package service
+
+import (
+	"github.com/k0marov/go-socnet/core/abstract/likeable"
+	"github.com/k0marov/go-socnet/core/general/client_errors"
+	"github.com/k0marov/go-socnet/core/general/core_err"
+	"github.com/k0marov/go-socnet/core/general/core_values"
+	"github.com/k0marov/go-socnet/core/general/events"
+	"github.com/k0marov/go-socnet/core/general/pubsub"
+	notification_models "github.com/k0marov/go-socnet/features/notifications/domain/models"
+	notification_service "github.com/k0marov/go-socnet/features/notifications/domain/service"
+	"github.com/k0marov/go-socnet/features/stream/domain/values"
+)
+
+type (
+	TopicsSubscriber   func(topics ...string) *pubsub.Subscription
+	MessagePublisher   func(topic string, msg pubsub.Message)
+	SubscribersChecker func(topic string) bool
+)
+
+type (
+	// StreamOpener subscribes to the notifications of caller and to the updates of the posts caller is viewing.
+	// The returned subscription must be closed when the client disconnects.
+	StreamOpener func(caller core_values.UserId, viewedPosts []string) (*pubsub.Subscription, error)
+)
+
+func NewStreamOpener(subscribe TopicsSubscriber) StreamOpener {
+	return func(caller core_values.UserId, viewedPosts []string) (*pubsub.Subscription, error) {
+		if len(viewedPosts) > values.MaxViewedPosts {
+			return nil, client_errors.TooManyViewedPosts
+		}
+		topics := []string{values.UserTopic(caller)}
+		for _, post := range viewedPosts {
+			topics = append(topics, values.PostTopic(post))
+		}
+		return subscribe(topics...), nil
+	}
+}
+
+// NewNotificationPusher pushes the notifications to their recipients once the notifications feature has stored them
+func NewNotificationPusher(publish MessagePublisher) notification_service.NotificationPusher {
+	return func(recipient core_values.UserId, notification notification_models.NotificationModel) {
+		publish(values.UserTopic(recipient), pubsub.Message{Event: values.NotificationMessage, Data: notification})
+	}
+}
+
+// NewEventStreamer pushes the updates of the posts to their viewers, the notifications are pushed by NewNotificationPusher.
+// The likes of a post are pushed only if someone is viewing it, so that nobody viewing it costs no queries.
+func NewEventStreamer(publish MessagePublisher, hasSubscribers SubscribersChecker, getPostReactions likeable.ReactionCountsGetter) events.Subscriber {
+	return func(event events.Event) error {
+		switch {
+		case event.Kind == events.CommentCreated:
+			publish(values.PostTopic(event.TargetId), pubsub.Message{Event: values.CommentMessage, Data: event})
+		case event.Kind == events.LikesChanged && event.TargetType == events.Post:
+			topic := values.PostTopic(event.TargetId)
+			if !hasSubscribers(topic) {
+				return nil
+			}
+			reactions, err := getPostReactions(event.TargetId)
+			if err != nil {
+				return core_err.Rethrow("getting the reactions of a viewed post", err)
+			}
+			change := values.LikesChange{TargetType: event.TargetType, TargetId: event.TargetId, Reactions: reactions}
+			publish(topic, pubsub.Message{Event: values.LikesMessage, Data: change})
+		}
+		return nil
+	}
+}
@@ -0,0 +1,127 @@
+package service_test
+
+import (
+	likeable_values "github.com/k0marov/go-socnet/core/abstract/likeable/values"
+	"github.com/k0marov/go-socnet/core/general/client_errors"
+	"github.com/k0marov/go-socnet/core/general/events"
+	"github.com/k0marov/go-socnet/core/general/pubsub"
+	. "github.com/k0marov/go-socnet/core/helpers/test_helpers"
+	notification_models "github.com/k0marov/go-socnet/features/notifications/domain/models"
+	"github.com/k0marov/go-socnet/features/stream/domain/service"
+	"github.com/k0marov/go-socnet/features/stream/domain/values"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestStreamOpener(t *testing.T) {
+	caller := RandomId()
+	t.Run("happy case", func(t *testing.T) {
+		posts := []string{"1", "2"}
+		stream := &pubsub.Subscription{}
+		subscribe := func(topics ...string) *pubsub.Subscription {
+			if reflect.DeepEqual(topics, []string{values.UserTopic(caller), values.PostTopic("1"), values.PostTopic("2")}) {
+				return stream
+			}
+			panic("unexpected args")
+		}
+		got, err := service.NewStreamOpener(subscribe)(caller, posts)
+		AssertNoError(t, err)
+		Assert(t, got, stream, "returned subscription")
+	})
+	t.Run("error case - too many viewed posts", func(t *testing.T) {
+		var posts []string
+		for i := 0; i <= values.MaxViewedPosts; i++ {
+			posts = append(posts, strconv.Itoa(i))
+		}
+		_, err := service.NewStreamOpener(nil)(caller, posts)
+		AssertError(t, err, client_errors.TooManyViewedPosts)
+	})
+}
+
+func TestNotificationPusher(t *testing.T) {
+	var published []publishedMessage
+	publish := func(topic string, msg pubsub.Message) {
+		published = append(published, publishedMessage{topic, msg})
+	}
+	recipient := RandomId()
+	notification := notification_models.NotificationModel{Id: RandomId(), Kind: events.Liked, LastActorId: RandomId(), Actors: 1 + RandomInt()}
+	service.NewNotificationPusher(publish)(recipient, notification)
+	want := []publishedMessage{{values.UserTopic(recipient), pubsub.Message{Event: values.NotificationMessage, Data: notification}}}
+	Assert(t, published, want, "published messages")
+}
+
+type publishedMessage struct {
+	topic string
+	msg   pubsub.Message
+}
+
+func TestEventStreamer(t *testing.T) {
+	var published []publishedMessage
+	publish := func(topic string, msg pubsub.Message) {
+		published = append(published, publishedMessage{topic, msg})
+	}
+	noViewers := func(string) bool {
+		return false
+	}
+	t.Run("notifications are not pushed, the notifier pushes them once they are stored", func(t *testing.T) {
+		published = nil
+		event := events.Event{Kind: events.Replied, Actor: RandomId(), Recipient: RandomId(), TargetType: events.Comment, TargetId: RandomId(), CreatedAt: RandomTime()}
+		err := service.NewEventStreamer(publish, noViewers, nil)(event)
+		AssertNoError(t, err)
+		Assert(t, len(published), 0, "number of published messages")
+	})
+	t.Run("new comments are pushed to the viewers of the post", func(t *testing.T) {
+		published = nil
+		event := events.Event{Kind: events.CommentCreated, Actor: RandomId(), TargetType: events.Post, TargetId: RandomId(), Comment: RandomId()}
+		err := service.NewEventStreamer(publish, noViewers, nil)(event)
+		AssertNoError(t, err)
+		want := []publishedMessage{{values.PostTopic(event.TargetId), pubsub.Message{Event: values.CommentMessage, Data: event}}}
+		Assert(t, published, want, "published messages")
+	})
+	t.Run("likes of posts", func(t *testing.T) {
+		post := RandomId()
+		event := events.Event{Kind: events.LikesChanged, Actor: RandomId(), TargetType: events.Post, TargetId: post}
+		hasViewers := func(topic string) bool {
+			if topic == values.PostTopic(post) {
+				return true
+			}
+			panic("unexpected args")
+		}
+		t.Run("happy case", func(t *testing.T) {
+			published = nil
+			reactions := likeable_values.ReactionCounts{likeable_values.ReactionLike: 2, likeable_values.ReactionLove: 1}
+			getReactions := func(target string) (likeable_values.ReactionCounts, error) {
+				if target == post {
+					return reactions, nil
+				}
+				panic("unexpected args")
+			}
+			err := service.NewEventStreamer(publish, hasViewers, getReactions)(event)
+			AssertNoError(t, err)
+			change := values.LikesChange{TargetType: events.Post, TargetId: post, Reactions: reactions}
+			want := []publishedMessage{{values.PostTopic(post), pubsub.Message{Event: values.LikesMessage, Data: change}}}
+			Assert(t, published, want, "published messages")
+		})
+		t.Run("nothing is done if nobody views the post", func(t *testing.T) {
+			published = nil
+			err := service.NewEventStreamer(publish, noViewers, nil)(event)
+			AssertNoError(t, err)
+			Assert(t, len(published), 0, "number of published messages")
+		})
+		t.Run("error case - getting reactions throws", func(t *testing.T) {
+			getReactions := func(string) (likeable_values.ReactionCounts, error) {
+				return nil, RandomError()
+			}
+			err := service.NewEventStreamer(publish, hasViewers, getReactions)(event)
+			AssertSomeError(t, err)
+		})
+	})
+	t.Run("likes of other targets are not pushed", func(t *testing.T) {
+		published = nil
+		event := events.Event{Kind: events.LikesChanged, Actor: RandomId(), TargetType: events.Comment, TargetId: RandomId()}
+		err := service.NewEventStreamer(publish, nil, nil)(event)
+		AssertNoError(t, err)
+		Assert(t, len(published), 0, "number of published messages")
+	})
+}
@@ -0,0 +1,35 @@
+package values
+
+import (
+	likeable_values "github.com/k0marov/go-socnet/core/abstract/likeable/values"
+	"github.com/k0marov/go-socnet/core/general/core_values"
+	"github.com/k0marov/go-socnet/core/general/events"
+)
+
+// MaxViewedPosts limits the number of posts whose updates are pushed to one stream
+const MaxViewedPosts = 50
+
+// The events of the pushed messages.
+// The data of notification messages is the stored notification, the data of comment messages is the events.Event which caused them
+// and the data of likes messages is LikesChange.
+const (
+	NotificationMessage = "notification"
+	CommentMessage      = "comment"
+	LikesMessage        = "likes"
+)
+
+// UserTopic receives the notifications of a user
+func UserTopic(user core_values.UserId) string {
+	return "user:" + user
+}
+
+// PostTopic receives the new comments and the likes of a post
+func PostTopic(post string) string {
+	return "post:" + post
+}
+
+type LikesChange struct {
+	TargetType events.TargetType
+	TargetId   string
+	Reactions  likeable_values.ReactionCounts
+}
@@ -0,0 +1,190 @@
+package integration_test
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"github.com/k0marov/go-socnet/core/general/events"
+	"github.com/k0marov/go-socnet/core/general/pubsub"
+	helpers "github.com/k0marov/go-socnet/core/helpers/http_test_helpers"
+	. "github.com/k0marov/go-socnet/core/helpers/test_helpers"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/k0marov/go-socnet/features/comments"
+	comment_handlers "github.com/k0marov/go-socnet/features/comments/delivery/http/handlers"
+	comment_responses "github.com/k0marov/go-socnet/features/comments/delivery/http/responses"
+	"github.com/k0marov/go-socnet/features/notifications"
+	"github.com/k0marov/go-socnet/features/posts"
+	post_models "github.com/k0marov/go-socnet/features/posts/domain/models"
+	posts_db "github.com/k0marov/go-socnet/features/posts/store/sql_db"
+	"github.com/k0marov/go-socnet/features/profiles"
+	"github.com/k0marov/go-socnet/features/stream"
+	"github.com/k0marov/go-socnet/features/stream/delivery/http/responses"
+	auth "github.com/k0marov/golang-auth"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+type pushed struct {
+	event string
+	data  string
+}
+
+// openStream connects to the stream of caller and returns the channel of the pushed messages, skipping the heartbeats
+func openStream(t testing.TB, server *httptest.Server, caller auth.User, params string) <-chan pushed {
+	t.Helper()
+	request, err := http.NewRequest(http.MethodGet, server.URL+"/stream/"+params, nil)
+	AssertNoError(t, err)
+	request.Header.Set("X-Test-User", caller.Id)
+	response, err := server.Client().Do(request)
+	AssertNoError(t, err)
+	t.Cleanup(func() { response.Body.Close() })
+	AssertFatal(t, response.StatusCode, http.StatusOK, "status code")
+
+	messages := make(chan pushed, 10)
+	go func() {
+		defer close(messages)
+		scanner := bufio.NewScanner(response.Body)
+		var msg pushed
+		for scanner.Scan() {
+			line := scanner.Text()
+			switch {
+			case strings.HasPrefix(line, "event: "):
+				msg.event = strings.TrimPrefix(line, "event: ")
+			case strings.HasPrefix(line, "data: "):
+				msg.data = strings.TrimPrefix(line, "data: ")
+			case line == "" && msg.event != "":
+				messages <- msg
+				msg = pushed{}
+			}
+		}
+	}()
+	return messages
+}
+
+func receive(t testing.TB, messages <-chan pushed, wantEvent string, data any) {
+	t.Helper()
+	select {
+	case msg, ok := <-messages:
+		AssertFatal(t, ok, true, "stream is open")
+		Assert(t, msg.event, wantEvent, "event of the pushed message")
+		AssertNoError(t, json.Unmarshal([]byte(msg.data), data))
+	case <-time.After(5 * time.Second):
+		t.Fatalf("no message was pushed, want %q", wantEvent)
+	}
+}
+
+func TestStream(t *testing.T) {
+	// db
+	cfg := TestConfig(t)
+	sql := OpenTestDB(t)
+	// events
+	hub := pubsub.NewHub(10)
+	publish := events.NewPublisher(
+		notifications.NewEventNotifierImpl(sql, time.Hour, stream.NewNotificationPusherImpl(hub)),
+		stream.NewEventStreamerImpl(hub, posts.NewReactionCountsGetterImpl(sql)),
+	)
+	// profiles
+	fakeRegisterProfile := profiles.NewRegisterCallback(sql)
+	getProfile := profiles.NewProfileGetterImpl(cfg, sql)
+	getProfiles := profiles.NewProfilesGetterImpl(cfg, sql)
+	resolveUsernames := profiles.NewUsernamesResolverImpl(sql)
+	// posts
+	postsDB, _ := posts_db.NewSqlDB(sql)
+	getCommentCounts := comments.NewCommentCountsGetterImpl(sql)
+	getCommentPreviews := comments.NewCommentPreviewsGetterImpl(sql, getProfiles)
+
+	r := chi.NewRouter()
+	r.Route("/posts", posts.NewPostsRouterImpl(cfg, sql, getProfile, getProfiles, getCommentCounts, getCommentPreviews, resolveUsernames, publish))
+	r.Route("/comments", comments.NewCommentsRouterImpl(cfg, sql, getProfile, posts.NewPostModelGetterImpl(sql), resolveUsernames, publish))
+	r.Route("/stream", stream.NewStreamRouterImpl(hub, time.Minute))
+
+	author := auth.User{Id: RandomId(), Username: "author"}
+	viewer := auth.User{Id: RandomId(), Username: "viewer"}
+	fan := auth.User{Id: RandomId(), Username: "fan"}
+	users := map[string]auth.User{}
+	for _, user := range []auth.User{author, viewer, fan} {
+		fakeRegisterProfile(user)
+		users[user.Id] = user
+	}
+	// the streaming needs a real server, the users are authenticated by a header instead of the auth middleware
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		r.ServeHTTP(w, helpers.AddAuthDataToRequest(req, users[req.Header.Get("X-Test-User")]))
+	}))
+	defer server.Close()
+	defer hub.Close()
+	do := func(t testing.TB, request *http.Request, caller auth.User) *httptest.ResponseRecorder {
+		t.Helper()
+		response := httptest.NewRecorder()
+		r.ServeHTTP(response, helpers.AddAuthDataToRequest(request, caller))
+		return response
+	}
+
+	post, err := postsDB.CreatePost(sql, post_models.PostToCreate{Author: author.Id, Text: RandomString(), CreatedAt: time.Now()})
+	AssertNoError(t, err)
+	authorStream := openStream(t, server, author, "")
+	viewerStream := openStream(t, server, viewer, "?posts="+post)
+
+	t.Run("liking a viewed post", func(t *testing.T) {
+		response := do(t, httptest.NewRequest(http.MethodPut, "/posts/"+post+"/like", nil), fan)
+		AssertStatusCode(t, response, http.StatusOK)
+
+		var likes responses.LikesResponse
+		receive(t, viewerStream, "likes", &likes)
+		Assert(t, likes.Target, responses.TargetResponse{Type: events.Post, Id: post}, "target of the likes")
+		Assert(t, likes.Likes, 1, "number of likes")
+
+		var notification responses.NotificationResponse
+		receive(t, authorStream, "notification", &notification)
+		Assert(t, notification.Kind, events.Liked, "kind of the notification")
+		Assert(t, notification.LastActorId, fan.Id, "liker")
+		Assert(t, notification.ActorsCount, 1, "number of likers")
+		Assert(t, notification.Id != "", true, "the notification has an id")
+
+		// the second like is grouped into the same notification
+		response = do(t, httptest.NewRequest(http.MethodPut, "/posts/"+post+"/like", nil), viewer)
+		AssertStatusCode(t, response, http.StatusOK)
+		receive(t, viewerStream, "likes", &likes)
+		Assert(t, likes.Likes, 2, "number of likes")
+
+		var grouped responses.NotificationResponse
+		receive(t, authorStream, "notification", &grouped)
+		Assert(t, grouped.Id, notification.Id, "id of the grouped notification")
+		Assert(t, grouped.LastActorId, viewer.Id, "last liker")
+		Assert(t, grouped.ActorsCount, 2, "number of likers")
+	})
+	t.Run("commenting a viewed post", func(t *testing.T) {
+		body := bytes.NewBuffer(nil)
+		json.NewEncoder(body).Encode(comment_handlers.NewCommentRequest{Text: RandomString()})
+		response := do(t, httptest.NewRequest(http.MethodPost, "/comments/?post_id="+post, body), fan)
+		AssertStatusCode(t, response, http.StatusOK)
+		var created comment_responses.CommentResponse
+		json.NewDecoder(response.Body).Decode(&created)
+
+		var comment responses.CommentResponse
+		receive(t, viewerStream, "comment", &comment)
+		Assert(t, comment.CommentId, created.Id, "pushed comment")
+		Assert(t, comment.PostId, post, "post of the pushed comment")
+		Assert(t, comment.AuthorId, fan.Id, "author of the pushed comment")
+
+		var notification responses.NotificationResponse
+		receive(t, authorStream, "notification", &notification)
+		Assert(t, notification.Kind, events.Commented, "kind of the notification")
+		Assert(t, notification.CommentId, created.Id, "comment of the notification")
+	})
+	t.Run("closing the hub ends the streams", func(t *testing.T) {
+		hub.Close()
+		for _, stream := range []<-chan pushed{authorStream, viewerStream} {
+			select {
+			case _, ok := <-stream:
+				Assert(t, ok, false, "stream is open")
+			case <-time.After(5 * time.Second):
+				t.Fatal("the stream was not closed")
+			}
+		}
+	})
+}
@@ -0,0 +1,33 @@
+package stream
+
+import (
+	"github.com/go-chi/chi/v5"
+	"github.com/k0marov/go-socnet/core/abstract/likeable"
+	"github.com/k0marov/go-socnet/core/general/events"
+	"github.com/k0marov/go-socnet/core/general/pubsub"
+	notification_service "github.com/k0marov/go-socnet/features/notifications/domain/service"
+	"github.com/k0marov/go-socnet/features/stream/delivery/http/handlers"
+	"github.com/k0marov/go-socnet/features/stream/delivery/http/router"
+	"github.com/k0marov/go-socnet/features/stream/domain/service"
+	"time"
+)
+
+// NewEventStreamerImpl returns the subscriber which pushes the updates of the viewed posts published by other features through hub.
+// It gets the reactions of posts from the posts feature.
+func NewEventStreamerImpl(hub *pubsub.Hub, getPostReactions likeable.ReactionCountsGetter) events.Subscriber {
+	return service.NewEventStreamer(hub.Publish, hub.HasSubscribers, getPostReactions)
+}
+
+// NewNotificationPusherImpl returns the pusher which the notifications feature uses to push the stored notifications through hub
+func NewNotificationPusherImpl(hub *pubsub.Hub) notification_service.NotificationPusher {
+	return service.NewNotificationPusher(hub.Publish)
+}
+
+func NewStreamRouterImpl(hub *pubsub.Hub, heartbeatPeriod time.Duration) func(chi.Router) {
+	// service
+	openStream := service.NewStreamOpener(hub.Subscribe)
+	// handlers
+	streamHandler := handlers.NewStreamHandler(openStream, heartbeatPeriod)
+
+	return router.NewStreamRouter(streamHandler)
+}
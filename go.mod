@@ -6,10 +6,9 @@ require (
 	github.com/go-chi/chi/v5 v5.0.7
 	github.com/jmoiron/sqlx v1.3.5
 	github.com/k0marov/golang-auth v0.0.0-20220627132844-9407c17d3bf2
+	github.com/lib/pq v1.10.9
 	github.com/mattn/go-sqlite3 v1.14.14
+	golang.org/x/crypto v0.0.0-20220622213112-05595931fe9d
 )
 
-require (
-	github.com/google/uuid v1.3.0 // indirect
-	golang.org/x/crypto v0.0.0-20220622213112-05595931fe9d // indirect
-)
+require github.com/google/uuid v1.3.0 // indirect